@@ -46,6 +46,7 @@ func init() {
 	RegisterType[content_id.ShaderPipeline]()
 	RegisterType[content_id.Shader]()
 	RegisterType[content_id.Sound]()
+	RegisterType[content_id.StringTable]()
 	RegisterType[content_id.TableOfContents]()
 	RegisterType[content_id.Template]()
 	RegisterType[content_id.Terrain]()
@@ -0,0 +1,283 @@
+/******************************************************************************/
+/* editor_cli.go                                                              */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package editor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"kaijuengine.com/build"
+	"kaijuengine.com/editor/editor_embedded_content"
+	"kaijuengine.com/editor/project"
+	"kaijuengine.com/editor/project/project_database/content_database"
+	"kaijuengine.com/editor/project/project_file_system"
+	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering/texcompress"
+)
+
+// The exit codes of [RunPipelineFromCLI]
+const (
+	CLIExitSuccess = iota
+	// CLIExitStepFailed is returned when one of the pipeline steps failed, the
+	// steps after it are not run
+	CLIExitStepFailed
+	// CLIExitUsage is returned when the pipeline steps or their arguments are
+	// not valid, nothing is run
+	CLIExitUsage
+	// CLIExitProjectError is returned when the project couldn't be opened
+	CLIExitProjectError
+)
+
+const cliStepNames = "import, validate, package, build, test"
+
+type cliPipeline struct {
+	project project.Project
+	content *editor_embedded_content.EditorContent
+}
+
+type cliStep struct {
+	name string
+	run  func(p *cliPipeline) (any, error)
+}
+
+// CLIPipelineReport is what is written as JSON once the pipeline has finished
+type CLIPipelineReport struct {
+	Project string          `json:"project"`
+	Success bool            `json:"success"`
+	Error   string          `json:"error,omitempty"`
+	Steps   []CLIStepReport `json:"steps"`
+}
+
+type CLIStepReport struct {
+	Step    string  `json:"step"`
+	Success bool    `json:"success"`
+	Error   string  `json:"error,omitempty"`
+	Seconds float64 `json:"seconds"`
+	Result  any     `json:"result,omitempty"`
+}
+
+type cliImportResult struct {
+	Reimported int                               `json:"reimported"`
+	Skipped    int                               `json:"skipped"`
+	Failed     int                               `json:"failed"`
+	Content    []content_database.ReimportResult `json:"content"`
+}
+
+type cliPackageResult struct {
+	Path     string `json:"path"`
+	Platform string `json:"platform"`
+}
+
+type cliBuildResult struct {
+	Release bool     `json:"release"`
+	Tags    []string `json:"tags"`
+	Output  string   `json:"output"`
+}
+
+// RunPipelineFromCLI opens the project at the path and runs the pipeline steps
+// in args, in order, without the editor UI. Each step is a name followed by
+// its own flags:
+//
+//	import [-changed]                re-import content from its source files
+//	validate                         check stages and templates for missing content
+//	package [-platform desktop]      create the content archive for a platform
+//	build [-release] [-tags a,b]     compile the game, debug unless -release
+//	test [-headless] [-timeout 10m]  run the editor autotest
+//
+// The report of all the steps is written to out as JSON and the returned value
+// is the exit code for the process.
+func RunPipelineFromCLI(path string, args []string, out io.Writer) int {
+	defer tracing.NewRegion("editor.RunPipelineFromCLI").End()
+	report := CLIPipelineReport{Project: path, Steps: []CLIStepReport{}}
+	code := runCLIPipeline(path, args, &report)
+	report.Success = code == CLIExitSuccess
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "\t")
+	if err := enc.Encode(report); err != nil {
+		slog.Error("failed to write the pipeline report", "error", err)
+	}
+	return code
+}
+
+func runCLIPipeline(path string, args []string, report *CLIPipelineReport) int {
+	steps, err := parseCLISteps(args)
+	if err != nil {
+		report.Error = err.Error()
+		return CLIExitUsage
+	}
+	p := &cliPipeline{}
+	if err := p.project.Open(path); err != nil {
+		report.Error = err.Error()
+		return CLIExitProjectError
+	}
+	if v := p.project.Settings.EditorVersion; v != EditorVersion {
+		slog.Warn("the project is for a different version of the editor",
+			"project", v, "editor", EditorVersion)
+	}
+	p.project.ReadSourceCode()
+	p.content = &editor_embedded_content.EditorContent{Pfs: p.project.FileSystem()}
+	p.content.SetProjectContentIndex(p.project.CacheDatabase().List())
+	for i := range steps {
+		slog.Info("running pipeline step", "step", steps[i].name)
+		start := time.Now()
+		result, err := steps[i].run(p)
+		step := CLIStepReport{
+			Step:    steps[i].name,
+			Success: err == nil,
+			Seconds: time.Since(start).Seconds(),
+			Result:  result,
+		}
+		if err != nil {
+			step.Error = err.Error()
+		}
+		report.Steps = append(report.Steps, step)
+		if err != nil {
+			report.Error = fmt.Sprintf("the %s step failed", steps[i].name)
+			return CLIExitStepFailed
+		}
+	}
+	return CLIExitSuccess
+}
+
+// parseCLISteps reads the step names and their flags, the flags of a step end
+// at the first argument that isn't a flag, which is the name of the next step
+func parseCLISteps(args []string) ([]cliStep, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("no pipeline steps were given, expected one or more of: %s", cliStepNames)
+	}
+	steps := []cliStep{}
+	for len(args) > 0 {
+		name := args[0]
+		set := flag.NewFlagSet(name, flag.ContinueOnError)
+		set.SetOutput(io.Discard)
+		step := cliStep{name: name}
+		switch name {
+		case "import":
+			changed := set.Bool("changed", false, "Only re-import content that is out of date and the content that depends on it")
+			step.run = func(p *cliPipeline) (any, error) { return p.reimport(*changed) }
+		case "validate":
+			step.run = (*cliPipeline).validate
+		case "package":
+			platform := set.String("platform", texcompress.ProfileDesktop.Name, "The platform (desktop or android) to compress textures for")
+			step.run = func(p *cliPipeline) (any, error) { return p.pack(*platform) }
+		case "build":
+			release := set.Bool("release", false, "Compile a release build instead of a debug build")
+			tags := set.String("tags", "", "Comma separated build tags to compile with")
+			step.run = func(p *cliPipeline) (any, error) { return p.compile(*release, *tags) }
+		case "test":
+			headless := set.Bool("headless", false, "Run the autotest without a window or GPU")
+			timeout := set.Duration("timeout", 10*time.Minute, "How long the autotest can run before it fails")
+			step.run = func(*cliPipeline) (any, error) { return nil, runCLIAutoTest(*headless, *timeout) }
+		default:
+			return nil, fmt.Errorf("unknown pipeline step '%s', expected one of: %s", name, cliStepNames)
+		}
+		if err := set.Parse(args[1:]); err != nil {
+			return nil, fmt.Errorf("invalid arguments for the %s step: %w", name, err)
+		}
+		args = set.Args()
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+func (p *cliPipeline) reimport(changedOnly bool) (any, error) {
+	content, err := p.project.ReimportContent(changedOnly)
+	if err != nil {
+		return nil, err
+	}
+	res := cliImportResult{Content: content}
+	for i := range res.Content {
+		switch {
+		case res.Content[i].Error != "":
+			res.Failed++
+		case res.Content[i].Skipped:
+			res.Skipped++
+		default:
+			res.Reimported++
+		}
+	}
+	p.content.SetProjectContentIndex(p.project.CacheDatabase().List())
+	if res.Failed > 0 {
+		return res, fmt.Errorf("%d of the content failed to re-import", res.Failed)
+	}
+	return res, nil
+}
+
+func (p *cliPipeline) validate() (any, error) {
+	missing, err := p.project.ValidateReferences(p.content.Exists)
+	if err != nil {
+		return missing, err
+	}
+	if len(missing) > 0 {
+		return missing, fmt.Errorf("found %d references to missing content", len(missing))
+	}
+	return missing, nil
+}
+
+func (p *cliPipeline) pack(platform string) (any, error) {
+	profile, ok := texcompress.ProfileByName(platform)
+	if !ok {
+		return nil, fmt.Errorf("unknown platform '%s', expected desktop or android", platform)
+	}
+	err := p.project.PackageFor(p.content, profile)
+	return cliPackageResult{Path: p.project.PackagePath(), Platform: profile.Name}, err
+}
+
+func (p *cliPipeline) compile(release bool, tags string) (any, error) {
+	res := cliBuildResult{
+		Release: release,
+		Tags:    []string{},
+		Output:  p.project.FileSystem().FullPath(project_file_system.ProjectBuildFolder),
+	}
+	if !release {
+		res.Tags = append(res.Tags, "debug")
+	}
+	for _, t := range strings.Split(tags, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			res.Tags = append(res.Tags, t)
+		}
+	}
+	return res, p.project.Compile(res.Tags...)
+}
+
+// runCLIAutoTest runs this editor again with the autotest flag, the autotest
+// drives the editor UI so it needs its own process
+func runCLIAutoTest(headless bool, timeout time.Duration) error {
+	if !build.Debug {
+		return errors.New("the test step requires a debug build of the editor")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	args := []string{"-autotest"}
+	if headless {
+		args = append(args, "-headless")
+	}
+	cmd := exec.CommandContext(ctx, exe, args...)
+	// Standard out is reserved for the pipeline report
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	err = cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("the autotest did not finish within %s", timeout)
+	}
+	if err != nil {
+		return fmt.Errorf("the autotest failed: %w", err)
+	}
+	return nil
+}
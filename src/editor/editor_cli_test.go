@@ -0,0 +1,64 @@
+/******************************************************************************/
+/* editor_cli_test.go                                                         */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package editor
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseCLISteps(t *testing.T) {
+	args := []string{"import", "-changed", "validate", "package", "-platform", "android",
+		"build", "-release", "-tags", "steam,demo", "test", "-timeout", "1m"}
+	steps, err := parseCLISteps(args)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"import", "validate", "package", "build", "test"}
+	if len(steps) != len(want) {
+		t.Fatalf("expected %d steps, got %d", len(want), len(steps))
+	}
+	for i := range want {
+		if steps[i].name != want[i] {
+			t.Errorf("expected step %d to be %s, got %s", i, want[i], steps[i].name)
+		}
+	}
+}
+
+func TestParseCLIStepsUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"deploy"},
+		{"build", "-optimize"},
+		{"test", "-timeout", "soon"},
+	} {
+		if _, err := parseCLISteps(args); err == nil {
+			t.Errorf("expected an error for %q", args)
+		}
+	}
+}
+
+func TestRunPipelineFromCLIReport(t *testing.T) {
+	var out bytes.Buffer
+	missing := filepath.Join(t.TempDir(), "missing")
+	if code := RunPipelineFromCLI(missing, []string{"validate"}, &out); code != CLIExitProjectError {
+		t.Errorf("expected the project error exit code, got %d", code)
+	}
+	var report CLIPipelineReport
+	if err := json.Unmarshal(out.Bytes(), &report); err != nil {
+		t.Fatalf("expected the report to be JSON: %v", err)
+	}
+	if report.Success || report.Error == "" || report.Project != missing {
+		t.Errorf("unexpected report %+v", report)
+	}
+	out.Reset()
+	if code := RunPipelineFromCLI(missing, []string{"deploy"}, &out); code != CLIExitUsage {
+		t.Errorf("expected the usage exit code, got %d", code)
+	}
+}
@@ -4,6 +4,7 @@
 
 #define SAMPLER_COUNT   4 // color, normal, metallicRoughness, emissive
 #define SHADOW_SAMPLERS
+#define LIGHT_CLUSTERS
 
 #define LAYOUT_FRAG_COLOR 0
 #define LAYOUT_FRAG_FLAGS 1
@@ -14,8 +15,6 @@
 #define LAYOUT_FRAG_ROUGHNESS 6
 #define LAYOUT_FRAG_EMISSIVE 7
 
-#define LAYOUT_ALL_LIGHT_REQUIREMENTS 8
-
 #include "kaiju.glsl"
 #include "pbr_lighting.glsl"
 
@@ -74,9 +73,11 @@ void main() {
 	vec3 Lo = vec3(0.0);
 	vec3 ambient = vec3(PBR_DEFAULT_AMBIENT_STRENGTH) * albedo * occlusion;
 
-	for (int i = 0; i < fragLightCount; ++i) {
-		int lightIdx = fragLightIndexes[i];
-		pbrAccumulateLight(lightIdx, fragPosLightSpace[i], albedo, N, V,
+	ivec3 lights = pbrClusterLights(fragPos);
+	for (int i = 0; i < lights.z; ++i) {
+		int lightIdx = pbrClusterLight(lights, i);
+		vec4 lightSpace = vertLights[lightIdx].matrix[0] * vec4(fragPos, 1.0);
+		pbrAccumulateLight(lightIdx, lightSpace, albedo, N, V,
 			metallic, roughness, occlusion, Lo, ambient);
 	}
 
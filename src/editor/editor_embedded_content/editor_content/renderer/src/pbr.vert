@@ -4,7 +4,6 @@
 #define LAYOUT_VERT_COLOR 0
 #define LAYOUT_VERT_METALLIC_ROUGHNESS_EMISSIVE_ALBEDO 1
 #define LAYOUT_VERT_FLAGS 2
-#define LAYOUT_VERT_LIGHT_IDS 3
 
 #define LAYOUT_FRAG_COLOR 0
 #define LAYOUT_FRAG_FLAGS 1
@@ -15,8 +14,6 @@
 #define LAYOUT_FRAG_ROUGHNESS 6
 #define LAYOUT_FRAG_EMISSIVE 7
 
-#define LAYOUT_ALL_LIGHT_REQUIREMENTS 8
-
 #include "kaiju.glsl"
 
 void main() {
@@ -28,5 +25,6 @@ void main() {
 	fragColor = color * Color;
 	fragPos = vec3(model * vec4(Position, 1.0));
 	gl_Position = projection * view * worldPosition();
-	calcVertexLightInformation();
+	// The lights are looked up for each fragment from the light clusters
+	fragNormal = normalize(transpose(inverse(mat3(model))) * Normal);
 }
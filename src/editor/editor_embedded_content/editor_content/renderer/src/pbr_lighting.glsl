@@ -106,6 +106,64 @@ void pbrAccumulateLight(int lightIndex, vec4 lightSpace, vec3 albedo, vec3 norma
 	directLighting += (diffuseFactor * albedo / PI + specular) * radiance * nDotL * visibility;
 }
 
+#ifdef LIGHT_CLUSTERS
+#define LIGHT_CLUSTER_COUNT_X 16
+#define LIGHT_CLUSTER_COUNT_Y 9
+#define LIGHT_CLUSTER_COUNT_Z 24
+#define LIGHT_CLUSTER_COUNT 3456 // X * Y * Z
+#define LIGHT_CLUSTER_COUNT_BITS 8
+
+// Written for each view by rendering.LightClusters. lightClusterDepth is the
+// near and far depth, the sign of the view axis and 1 for exponential slices.
+// Directional lights are the first lightClusterInfo.x entries of lightInfos,
+// each of lightClusterCells is (offset << 8 | count) of the cluster's local
+// lights in lightClusterIndices. When lightClusterInfo.y is 0 the clusters
+// don't match the view and all lightClusterInfo.z lights are used instead.
+layout(set = 0, binding = 5) readonly buffer LightClusterBuffer {
+	vec4 lightClusterDepth;
+	ivec4 lightClusterInfo;
+	uint lightClusterCells[LIGHT_CLUSTER_COUNT];
+	int lightClusterIndices[];
+};
+
+// pbrClusterLights returns the lights that reach the world position as
+// (directional count, offset into lightClusterIndices, total count), the
+// lights are then read with pbrClusterLight for 0 <= i < total count.
+ivec3 pbrClusterLights(vec3 worldPos) {
+	if (lightClusterInfo.y == 0) {
+		int count = min(lightClusterInfo.z, MAX_LIGHTS);
+		return ivec3(count, 0, count);
+	}
+	float near = lightClusterDepth.x;
+	float far = lightClusterDepth.y;
+	float depth = (view * vec4(worldPos, 1.0)).z * lightClusterDepth.z;
+	float slice;
+	if (lightClusterDepth.w > 0.5) {
+		slice = log(max(depth, near) / near) / log(far / near);
+	} else {
+		slice = (depth - near) / max(far - near, 0.0001);
+	}
+	vec4 clip = projection * view * vec4(worldPos, 1.0);
+	vec2 uv = clamp((clip.xy / clip.w + 1.0) * 0.5, 0.0, 1.0);
+	ivec3 cell = ivec3(
+		min(int(uv.x * LIGHT_CLUSTER_COUNT_X), LIGHT_CLUSTER_COUNT_X - 1),
+		min(int(uv.y * LIGHT_CLUSTER_COUNT_Y), LIGHT_CLUSTER_COUNT_Y - 1),
+		clamp(int(slice * LIGHT_CLUSTER_COUNT_Z), 0, LIGHT_CLUSTER_COUNT_Z - 1));
+	uint packed = lightClusterCells[cell.x + cell.y * LIGHT_CLUSTER_COUNT_X +
+		cell.z * LIGHT_CLUSTER_COUNT_X * LIGHT_CLUSTER_COUNT_Y];
+	int count = int(packed & ((1u << LIGHT_CLUSTER_COUNT_BITS) - 1u));
+	int offset = int(packed >> LIGHT_CLUSTER_COUNT_BITS);
+	return ivec3(lightClusterInfo.x, offset, lightClusterInfo.x + count);
+}
+
+int pbrClusterLight(ivec3 lights, int i) {
+	if (i < lights.x) {
+		return i;
+	}
+	return lightClusterIndices[lights.y + i - lights.x];
+}
+#endif
+
 vec3 pbrFinalColor(vec3 ambientLighting, vec3 directLighting, vec3 emission) {
 	return pbrLinearToSrgb(pbrAcesTonemap(ambientLighting + directLighting + emission));
 }
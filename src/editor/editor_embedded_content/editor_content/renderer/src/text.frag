@@ -5,6 +5,9 @@ layout(location = 1) in vec4 fragBGColor;
 layout(location = 2) in vec2 fragTexCoord;
 layout(location = 3) in vec2 fragPxRange;
 layout(location = 4) in vec2 fragTexRange;
+layout(location = 5) flat in vec4 fragShadow;
+layout(location = 6) flat in vec4 fragShadowColor;
+layout(location = 7) flat in vec4 fragGlyphRect;
 
 layout(binding = 1) uniform sampler2D texSampler;
 
@@ -24,22 +27,47 @@ float screenPxRange() {
 	return max(0.5 * dot(unitRange, screenTexSize), 1.0);
 }
 
+// shadowOpacity samples the glyph again, moved by the text-shadow offset (in
+// screen pixels). The blur radius softens the edge of the distance field. The
+// sample stays within the glyph's cell of the atlas, so a shadow can only
+// reach as far as the padding around the glyph.
+float shadowOpacity(float pxRange) {
+	vec2 uv = fragTexCoord - fragShadow.x * dFdx(fragTexCoord) - fragShadow.y * dFdy(fragTexCoord);
+	uv = clamp(uv, fragGlyphRect.xy, fragGlyphRect.zw);
+	vec3 msdfColor = texture(texSampler, uv).rgb;
+	float dist = median(msdfColor.r, msdfColor.g, msdfColor.b) - 0.5;
+	return clamp(dist * pxRange / max(1.0, fragShadow.z) + 0.5, 0.0, 1.0);
+}
+
 void main() {
 	vec3 msdfColor = texture(texSampler, fragTexCoord).rgb;
 	float dist = median(msdfColor.r, msdfColor.g, msdfColor.b) - 0.5;
-	float opacity = clamp(dist * screenPxRange() + 0.5, 0.0, 1.0);
+	float pxRange = screenPxRange();
+	float opacity = clamp(dist * pxRange + 0.5, 0.0, 1.0);
+	float shadow = 0.0;
+	if (fragShadowColor.a > 0.0) {
+		shadow = shadowOpacity(pxRange) * fragShadowColor.a;
+	}
 
 	// A negative background alpha marks opaque cutout text. Discard the quad
 	// outside the MSDF glyph boundary and write a fully opaque foreground pixel
 	// inside it. This keeps text opaque even when its parent is transparent.
 	if (fragBGColor.a < 0.0) {
 		if (opacity < 0.5) {
-			discard;
+			if (shadow < 0.5) {
+				discard;
+			}
+			outColor = vec4(fragShadowColor.rgb, 1.0);
+			return;
 		}
 		outColor = vec4(fragColor.rgb, 1.0);
 		return;
 	}
 
-	vec4 unWeightedColor = mix(fragBGColor, fragColor, opacity);
+	vec4 background = fragBGColor;
+	if (shadow > 0.0) {
+		background = mix(background, vec4(fragShadowColor.rgb, max(background.a, fragShadowColor.a)), shadow);
+	}
+	vec4 unWeightedColor = mix(background, fragColor, opacity);
 #include "inc_fragment_oit_block.inl"
 }
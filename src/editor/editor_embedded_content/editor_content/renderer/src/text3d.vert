@@ -7,12 +7,17 @@ layout(location = LOCATION_START+1) in vec4 fgColor;
 layout(location = LOCATION_START+2) in vec4 bgColor;
 layout(location = LOCATION_START+3) in vec4 scissor;
 layout(location = LOCATION_START+4) in vec2 pxRange;
+layout(location = LOCATION_START+5) in vec4 shadow;
+layout(location = LOCATION_START+6) in vec4 shadowColor;
 
 layout(location = 0) out vec4 fragColor;
 layout(location = 1) out vec4 fragBGColor;
 layout(location = 2) out vec2 fragTexCoord;
 layout(location = 3) out vec2 fragPxRange;
 layout(location = 4) out vec2 fragTexRange;
+layout(location = 5) flat out vec4 fragShadow;
+layout(location = 6) flat out vec4 fragShadowColor;
+layout(location = 7) flat out vec4 fragGlyphRect;
 
 void main() {
     vec2 uv = UV0;
@@ -24,4 +29,8 @@ void main() {
 	fragBGColor = bgColor;
 	gl_Position = projection * view * model * vec4(Position, 1.0);
 	fragPxRange = pxRange;
+	fragShadow = shadow;
+	fragShadowColor = shadowColor;
+	fragGlyphRect.xy = vec2(uvs.x, (1.0 - uvs.w) - uvs.y);
+	fragGlyphRect.zw = fragGlyphRect.xy + uvs.zw;
 }
\ No newline at end of file
@@ -25,11 +25,15 @@ layout(location = 10) out vec2 fragBorderLen;
 layout(location = 11) out vec4 fragUvs;
 layout(location = 12) out vec4 fragOutlineColor;
 layout(location = 13) out vec2 fragOutlineSize;
+layout(location = 14) flat out int fragInstance;
+
+#include "ui_effects.glsl"
 
 void main() {
 	vec4 vPos = model * vec4(Position, 1.0);
-	float outlineOutset = max(0.0, outlineSize.x + outlineSize.y);
-	vPos.xy += sign(Position.xy) * outlineOutset;
+	float outset = uiQuadOutset(outlineSize, uiEffectShadow(gl_InstanceIndex),
+		uiEffectField(gl_InstanceIndex, UI_EFFECT_EFFECTS).x, uiEffectFlags(gl_InstanceIndex));
+	vPos.xy += sign(Position.xy) * outset;
 	vPos.x = round(vPos.x);
 	vPos.y = round(vPos.y);
 	gl_Position = uiProjection * uiView * vPos;
@@ -50,6 +54,7 @@ void main() {
 	fragUvs.y = v;
 	fragOutlineColor = outlineColor;
 	fragOutlineSize = outlineSize;
+	fragInstance = gl_InstanceIndex;
 
 	gl_ClipDistance[0] = vPos.x - scissor.x;
 	gl_ClipDistance[1] = vPos.y - scissor.y;
@@ -0,0 +1,79 @@
+#version 450
+
+// The backdrop-filter of a UI panel. It is drawn with ui.vert over the quad of
+// the panel, underneath the opaque UI, and samples the color of the scene
+// (opaque.color) behind the panel. The backdrop is blurred and then color adjusted,
+// backdrop filters are limited to the color functions that can be written as
+// alpha * color + beta * luminance(color) + offset (brightness, contrast,
+// grayscale, saturate and invert) so they fit in the effect data.
+
+layout(location = 0) in vec4 fragColor;
+layout(location = 2) in vec4 fragSize2D;
+layout(location = 3) in vec4 fragBorderRadius;
+layout(location = 9) in vec2 fragTexCoord;
+layout(location = 11) in vec4 fragUvs;
+layout(location = 13) in vec2 fragOutlineSize;
+layout(location = 14) flat in int fragInstance;
+
+layout(binding = 1) uniform sampler2D backdrop;
+
+layout(location = 0) out vec4 outColor;
+
+#include "ui_effects.glsl"
+
+// Taps on each side of the center, the kernel is spread over 3 standard
+// deviations so large blurs skip texels rather than get more expensive
+#define BLUR_TAPS 12
+
+float roundedBoxSDF(vec2 centerPosition, vec2 size, vec4 radius) {
+	radius.xy = (centerPosition.x > 0.0) ? radius.xw : radius.yz;
+	radius.x  = (centerPosition.y < 0.0) ? radius.x  : radius.y;
+	vec2 q = abs(centerPosition) - size + radius.x;
+	return min(max(q.x,q.y),0.0) + length(max(q,0.0)) - radius.x;
+}
+
+vec3 blurredBackdrop(vec2 uv, float sigma) {
+	vec2 texel = 1.0 / vec2(textureSize(backdrop, 0));
+	if (sigma < 0.5) {
+		return texture(backdrop, uv).rgb;
+	}
+	float stride = max(1.0, sigma * 3.0 / float(BLUR_TAPS));
+	vec3 sum = vec3(0.0);
+	float weights = 0.0;
+	for (int y = -BLUR_TAPS; y <= BLUR_TAPS; y++) {
+		for (int x = -BLUR_TAPS; x <= BLUR_TAPS; x++) {
+			vec2 o = vec2(x, y) * stride;
+			float w = exp(-dot(o, o) / (2.0 * sigma * sigma));
+			sum += texture(backdrop, uv + o * texel).rgb * w;
+			weights += w;
+		}
+	}
+	return sum / weights;
+}
+
+void main(void) {
+	vec4 shadow = uiEffectShadow(fragInstance);
+	uvec4 effects = uiEffectField(fragInstance, UI_EFFECT_EFFECTS);
+	uint effectFlags = uiEffectFlags(fragInstance);
+	if ((effectFlags & UI_EFFECT_BACKDROP) == 0u) {
+		discard;
+	}
+	vec2 normUV = (fragTexCoord - fragUvs.xy) / fragUvs.zw;
+	float outset = uiQuadOutset(fragOutlineSize, shadow, effects.x, effectFlags);
+	vec2 dimensions = fragSize2D.xy;
+	vec2 pixPos = normUV * (dimensions + vec2(outset * 2.0)) - vec2(outset);
+	float pixelScale = max(max(fwidth(pixPos.x), fwidth(pixPos.y)), 0.001);
+	vec2 size = dimensions / 2.0;
+	float dist = roundedBoxSDF(size - pixPos, size, fragBorderRadius);
+	float shape = 1.0 - smoothstep(-pixelScale * 0.4, pixelScale * 0.4, dist);
+	if (shape <= 0.0) {
+		discard;
+	}
+	vec2 blurAlpha = unpackHalf2x16(effects.z);
+	vec2 betaOffset = unpackHalf2x16(effects.w);
+	vec2 screenUV = gl_FragCoord.xy / vec2(textureSize(backdrop, 0));
+	vec3 color = blurredBackdrop(screenUV, blurAlpha.x);
+	float luma = dot(color, vec3(0.2126, 0.7152, 0.0722));
+	color = clamp(blurAlpha.y * color + vec3(betaOffset.x * luma + betaOffset.y), 0.0, 1.0);
+	outColor = vec4(color, shape);
+}
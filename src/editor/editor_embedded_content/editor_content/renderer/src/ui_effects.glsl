@@ -0,0 +1,148 @@
+// Gradients, box shadows and color filters of the UI panels. The effects are
+// packed tightly (see ui.ShaderEffectData) into a storage buffer with an entry
+// for each instance, the UI shader is already at the limit of vertex
+// attributes. This must be kept in sync with the CPU reference in
+// rendering/software_rasterizer_ui_effects.go
+
+#define UI_GRADIENT_NONE      0
+#define UI_GRADIENT_LINEAR    1
+#define UI_GRADIENT_RADIAL    2
+#define UI_GRADIENT_CONIC     3
+#define UI_GRADIENT_REPEATING 4
+
+#define UI_EFFECT_FILTER       1u
+#define UI_EFFECT_INSET_SHADOW 2u
+#define UI_EFFECT_BACKDROP     4u
+
+#define UI_EFFECT_GRADIENT_COLORS 0
+#define UI_EFFECT_GRADIENT        1
+#define UI_EFFECT_SHADOW          2
+#define UI_EFFECT_EFFECTS         3
+#define UI_EFFECT_FILTER_MATRIX   4
+#define UI_EFFECT_FILTER_PARAMS   5
+#define UI_EFFECT_FIELD_COUNT     6
+
+layout(set = 0, binding = 2) readonly buffer UIEffectBuffer {
+	uvec4 uiEffectData[][UI_EFFECT_FIELD_COUNT];
+};
+
+const float UI_PI = 3.14159265359;
+
+uvec4 uiEffectField(int instance, int field) {
+	return uiEffectData[instance][field];
+}
+
+// The shadow is the only field that isn't packed, it is 4 floats
+vec4 uiEffectShadow(int instance) {
+	return uintBitsToFloat(uiEffectData[instance][UI_EFFECT_SHADOW]);
+}
+
+uint uiEffectFlags(int instance) {
+	return uiEffectData[instance][UI_EFFECT_FILTER_PARAMS].w;
+}
+
+bool uiHasOuterShadow(uint shadowColor, uint flags) {
+	return (shadowColor >> 24) != 0u && (flags & UI_EFFECT_INSET_SHADOW) == 0u;
+}
+
+// uiQuadOutset is how far the quad of a panel grows past its bounds so that
+// the outline and the outer shadow have somewhere to be drawn
+float uiQuadOutset(vec2 outlineSize, vec4 shadow, uint shadowColor, uint flags) {
+	float outset = max(0.0, outlineSize.x) + max(0.0, outlineSize.y);
+	if (uiHasOuterShadow(shadowColor, flags)) {
+		outset = max(outset, max(abs(shadow.x), abs(shadow.y)) + shadow.z + max(shadow.w, 0.0));
+	}
+	return outset;
+}
+
+// Approximation of the error function (Abramowitz and Stegun 7.1.27)
+float uiErf(float x) {
+	float s = sign(x);
+	float a = abs(x);
+	x = 1.0 + (0.278393 + (0.230389 + 0.078108 * (a * a)) * a) * a;
+	x *= x;
+	return s - s / (x * x);
+}
+
+// uiShadowCoverage is how much a shape blurred with the CSS blur radius
+// (twice the standard deviation) covers a pixel dist away from its edge
+float uiShadowCoverage(float dist, float blur, float pixelScale) {
+	float sigma = blur * 0.5;
+	if (sigma < 0.001) {
+		float aa = pixelScale * 0.4;
+		return 1.0 - smoothstep(-aa, aa, dist);
+	}
+	return 0.5 - 0.5 * uiErf(dist / (sigma * 1.41421356));
+}
+
+vec4 uiBlendOver(vec4 top, vec4 bottom) {
+	float a = top.a + bottom.a * (1.0 - top.a);
+	if (a <= 0.0) {
+		return vec4(0.0);
+	}
+	return vec4((top.rgb * top.a + bottom.rgb * bottom.a * (1.0 - top.a)) / a, a);
+}
+
+// uiGradientColor returns the color of the gradient at the pixel, it is clear
+// for panels without a gradient. Stops are interpolated with premultiplied
+// alpha like CSS does.
+vec4 uiGradientColor(vec2 pixPos, vec2 dimensions, uvec4 colors, uvec4 gradient, uint radii) {
+	vec2 angleKind = unpackHalf2x16(gradient.z);
+	int kind = int(angleKind.y + 0.5);
+	int type = kind & 3;
+	if (type == UI_GRADIENT_NONE) {
+		return vec4(0.0);
+	}
+	float angle = angleKind.x;
+	vec2 p = pixPos - unpackHalf2x16(gradient.w) * dimensions;
+	float t = 0.0;
+	if (type == UI_GRADIENT_LINEAR) {
+		vec2 dir = vec2(sin(angle), -cos(angle));
+		float len = abs(dimensions.x * dir.x) + abs(dimensions.y * dir.y);
+		t = dot(p, dir) / max(len, 0.0001) + 0.5;
+	} else if (type == UI_GRADIENT_RADIAL) {
+		t = length(p / max(unpackHalf2x16(radii), vec2(0.0001)));
+	} else {
+		t = fract((atan(p.x, -p.y) - angle) / (2.0 * UI_PI));
+	}
+	vec2 s01 = unpackHalf2x16(gradient.x);
+	vec2 s23 = unpackHalf2x16(gradient.y);
+	vec4 stops = vec4(s01, s23);
+	if ((kind & UI_GRADIENT_REPEATING) != 0) {
+		float period = stops.w - stops.x;
+		if (period > 0.0001) {
+			t = stops.x + mod(t - stops.x, period);
+		}
+	}
+	vec4 c[4] = vec4[4](
+		unpackUnorm4x8(colors.x), unpackUnorm4x8(colors.y),
+		unpackUnorm4x8(colors.z), unpackUnorm4x8(colors.w));
+	vec4 color = vec4(c[0].rgb * c[0].a, c[0].a);
+	for (int i = 1; i < 4; i++) {
+		float w = stops[i] - stops[i-1];
+		float f = w > 0.0001 ? clamp((t - stops[i-1]) / w, 0.0, 1.0) : step(stops[i], t);
+		color = mix(color, vec4(c[i].rgb * c[i].a, c[i].a), f);
+	}
+	if (color.a <= 0.0) {
+		return vec4(0.0);
+	}
+	return vec4(color.rgb / color.a, color.a);
+}
+
+// uiApplyFilter multiplies the color by the 3x3 color matrix of a CSS filter,
+// adds its offset and scales the alpha for opacity()
+vec4 uiApplyFilter(vec4 color, uvec4 m, uvec4 params) {
+	vec2 a = unpackHalf2x16(m.x);
+	vec2 b = unpackHalf2x16(m.y);
+	vec2 c = unpackHalf2x16(m.z);
+	vec2 d = unpackHalf2x16(m.w);
+	vec2 e = unpackHalf2x16(params.x);
+	vec2 f = unpackHalf2x16(params.y);
+	vec2 g = unpackHalf2x16(params.z);
+	mat3 filterMat = mat3(
+		a.x, b.y, d.x,
+		a.y, c.x, d.y,
+		b.x, c.y, e.x);
+	vec3 rgb = clamp(filterMat * color.rgb + vec3(e.y, f.x, f.y), 0.0, 1.0);
+	return vec4(rgb, clamp(color.a * g.x, 0.0, 1.0));
+}
@@ -11,6 +11,7 @@ layout(location = 10) in vec2 fragNineSliceEdgeLen;
 layout(location = 11) in vec4 fragUvs;
 layout(location = 12) in vec4 fragOutlineColor;
 layout(location = 13) in vec2 fragOutlineSize;
+layout(location = 14) flat in int fragInstance;
 
 layout(binding = 1) uniform sampler2D texSampler;
 
@@ -19,7 +20,7 @@ layout(location = 0) out vec4 outColor;
 layout(location = 1) out float reveal;
 #endif
 
-
+#include "ui_effects.glsl"
 
 float processAxis(float coord, float border, float ratio) {
 	float len = border * ratio;
@@ -65,13 +66,24 @@ void main(void) {
 	vec2 normUV = (fragTexCoord - fragUvs.xy) / fragUvs.zw;
 	float outlineWidth = max(0.0, fragOutlineSize.x);
 	float outlineOffset = max(0.0, fragOutlineSize.y);
-	float outlineOutset = outlineWidth + outlineOffset;
+	uvec4 gradientColors = uiEffectField(fragInstance, UI_EFFECT_GRADIENT_COLORS);
+	uvec4 gradient = uiEffectField(fragInstance, UI_EFFECT_GRADIENT);
+	vec4 shadow = uiEffectShadow(fragInstance);
+	uvec4 effects = uiEffectField(fragInstance, UI_EFFECT_EFFECTS);
+	uvec4 filterMatrix = uiEffectField(fragInstance, UI_EFFECT_FILTER_MATRIX);
+	uvec4 filterParams = uiEffectField(fragInstance, UI_EFFECT_FILTER_PARAMS);
+	uint effectFlags = filterParams.w;
+	float outset = uiQuadOutset(fragOutlineSize, shadow, effects.x, effectFlags);
 	vec2 dimensions = fragSize2D.xy;
-	vec2 expandedDimensions = dimensions + vec2(outlineOutset * 2.0);
+	vec2 expandedDimensions = dimensions + vec2(outset * 2.0);
 	vec2 expandedPixPos = normUV * expandedDimensions;
-	vec2 pixPos = expandedPixPos - vec2(outlineOutset);
+	vec2 pixPos = expandedPixPos - vec2(outset);
 	float pixelScale = max(max(fwidth(pixPos.x), fwidth(pixPos.y)), 0.001);
 	vec4 unWeightedColor = vec4(0.0);
+	vec4 shadowColor = unpackUnorm4x8(effects.x);
+	vec2 size = dimensions / 2.0;
+	vec2 centerPixPos = size-pixPos;
+	float shapeAlpha = 0.0;
 	bool insidePanel = pixPos.x >= 0.0 && pixPos.y >= 0.0 && pixPos.x <= dimensions.x && pixPos.y <= dimensions.y;
 	if (insidePanel) {
 		vec2 panelUV = pixPos / dimensions;
@@ -81,9 +93,11 @@ void main(void) {
 		);
 		vec2 newUV = fragUvs.xy + scaledNormUV * fragUvs.zw;
 		unWeightedColor = texture(texSampler, newUV) * fragColor;
+		// Background gradient, drawn over the background color (or image)
+		vec4 gradientColor = uiGradientColor(pixPos, dimensions,
+			gradientColors, gradient, effects.y);
+		unWeightedColor = uiBlendOver(gradientColor, unWeightedColor);
 		// Border
-		vec2 size = dimensions / 2.0;
-		vec2 centerPixPos = size-pixPos;
 
 		int sideIdx = 0;
 		float closestSide = edgeProximity(pixPos.x, fragBorderSize.x);
@@ -137,9 +151,21 @@ void main(void) {
 			}
 		}
 
+		// Inset shadow, drawn over the background and under the border. It is
+		// the padding box minus the (offset and spread) shape of the shadow.
+		if ((effectFlags & UI_EFFECT_INSET_SHADOW) != 0u && shadowColor.a > 0.0) {
+			float spread = shadow.w;
+			float insetDist = roundedBoxSDF(innerCenterPixPos + shadow.xy,
+				max(innerSize - vec2(spread), vec2(0.0)),
+				max(innerBorderRadius - vec4(spread), vec4(0.0)));
+			float insetAlpha = 1.0 - uiShadowCoverage(insetDist, shadow.z, pixelScale);
+			unWeightedColor = uiBlendOver(vec4(shadowColor.rgb, shadowColor.a * insetAlpha), unWeightedColor);
+		}
+
 		// Border color
 		unWeightedColor = mix(unWeightedColor, borderColor, smoothedBorderAlpha);
 		unWeightedColor.a = smoothedAlpha * unWeightedColor.a;
+		shapeAlpha = smoothedAlpha;
 	} else if (outlineWidth > 0.0 && fragOutlineColor.a > 0.0) {
 		vec2 outside = max(max(-pixPos, pixPos - dimensions), vec2(0.0));
 		float outsideDistance = max(outside.x, outside.y);
@@ -148,5 +174,26 @@ void main(void) {
 		unWeightedColor = fragOutlineColor;
 		unWeightedColor.a *= outerAlpha * innerAlpha;
 	}
+	// The outer shadow is the panel shape moved, grown by the spread and
+	// blurred. Like CSS it is never drawn under the panel itself.
+	if (uiHasOuterShadow(effects.x, effectFlags)) {
+		float spread = shadow.w;
+		vec4 shadowRadius = fragBorderRadius;
+		for (int i = 0; i < 4; i++) {
+			if (shadowRadius[i] > 0.0) {
+				shadowRadius[i] = max(shadowRadius[i] + spread, 0.0);
+			}
+		}
+		float shadowDist = roundedBoxSDF(centerPixPos + shadow.xy,
+			max(size + vec2(spread), vec2(0.0)), shadowRadius);
+		float coverage = uiShadowCoverage(shadowDist, shadow.z, pixelScale);
+		unWeightedColor = uiBlendOver(unWeightedColor,
+			vec4(shadowColor.rgb, shadowColor.a * coverage * (1.0 - shapeAlpha)));
+	}
+	// backdrop-filter (UI_EFFECT_BACKDROP) needs the frame behind the panel,
+	// it is drawn by ui_backdrop_blur.frag before the panel is
+	if ((effectFlags & UI_EFFECT_FILTER) != 0u) {
+		unWeightedColor = uiApplyFilter(unWeightedColor, filterMatrix, filterParams);
+	}
 #include "inc_fragment_oit_block.inl"
 }
@@ -1234,6 +1234,8 @@ func (w *ContentWorkspace) openInEditor(cc content_database.CachedContent) {
 	case content_database.Html{}.TypeName():
 		fallthrough
 	case content_database.Css{}.TypeName():
+		fallthrough
+	case content_database.StringTable{}.TypeName():
 		ed = w.editor.Settings().CodeEditor
 	case content_database.Mesh{}.TypeName():
 		ed = w.editor.Settings().MeshEditor
@@ -31,8 +31,10 @@ import (
 	"kaijuengine.com/engine_entity_data/engine_entity_data_particles"
 	"kaijuengine.com/engine_entity_data/engine_entity_data_physics"
 	"kaijuengine.com/engine_entity_data/engine_entity_data_terrain"
+	"kaijuengine.com/localization"
 	"kaijuengine.com/platform/filesystem"
 	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering/texcompress"
 )
 
 type GameBuildMode int
@@ -220,6 +222,13 @@ func (p *Project) CompileGame(buildMode GameBuildMode) {
 // details.
 func (p *Project) CompileWithTags(tags ...string) {
 	defer tracing.NewRegion("Project.CompileWithTags").End()
+	p.Compile(tags...)
+}
+
+// Compile is the same as [Project.CompileWithTags] but will also return a
+// [CompileError] with the output of the compiler if the build fails.
+func (p *Project) Compile(tags ...string) error {
+	defer tracing.NewRegion("Project.Compile").End()
 
 	for !p.isCompiling.CompareAndSwap(false, true) {
 		time.Sleep(time.Millisecond)
@@ -253,12 +262,15 @@ func (p *Project) CompileWithTags(tags ...string) {
 	if err := cmd.Run(); err != nil {
 		slog.Error("project executable failed to compile!", "error", err,
 			"log", stdout.String(), "errlog", stderr.String())
-	} else {
-		slog.Info("project executable successfully compiled")
+		return CompileError{Err: err, Log: stderr.String()}
 	}
+	slog.Info("project executable successfully compiled")
+	return nil
 }
 
-func (p *Project) packagePath() string {
+// PackagePath is the path to the content archive that [Project.Package]
+// creates
+func (p *Project) PackagePath() string {
 	return filepath.Join(p.fileSystem.FullPath(project_file_system.ProjectBuildFolder), "game.dat")
 }
 
@@ -268,6 +280,7 @@ func (p *Project) PackageDebug() {
 			Key:     stages.EntryPointAssetKey,
 			RawData: []byte(p.Settings.EntryPointStage),
 		},
+		stringTablesAsset(p.cacheDatabase.List()),
 	}
 	for i := range files {
 		path := filepath.Join(project_file_system.DebugFolder, files[i].Key)
@@ -275,13 +288,22 @@ func (p *Project) PackageDebug() {
 	}
 }
 
+// Package creates the content archive for desktop platforms, see
+// [Project.PackageFor]
 func (p *Project) Package(reader content_archive.FileReader) error {
-	defer tracing.NewRegion("Project.Package").End()
-	outPath := p.packagePath()
+	return p.PackageFor(reader, texcompress.ProfileDesktop)
+}
+
+// PackageFor creates the content archive with the textures compressed into
+// the formats of the platform profile
+func (p *Project) PackageFor(reader content_archive.FileReader, profile texcompress.Profile) error {
+	defer tracing.NewRegion("Project.PackageFor").End()
+	outPath := p.PackagePath()
 	// TODO:  Needs to use a reference graph to determine all of the content
 	// needed rather than just dumping all content in here
 	list := p.cacheDatabase.List()
 	allReferencedContent := p.FindAllReferencedContentFromCache(list)
+	textures := p.readTexturePackaging(allReferencedContent)
 	files := make([]content_archive.SourceContent, 0, len(allReferencedContent))
 	for i := range allReferencedContent {
 		relPath := content_database.ToContentPath(allReferencedContent[i].Path)
@@ -291,6 +313,10 @@ func (p *Project) Package(reader content_archive.FileReader) error {
 		}
 		if s, ok := p.contentSerializers[allReferencedContent[i].Config.Type]; ok {
 			sc.CustomSerializer = s
+		} else if allReferencedContent[i].Config.Type == (content_database.Texture{}).TypeName() {
+			if settings, ok := textures.textureSettings(&allReferencedContent[i]); ok {
+				sc.CustomSerializer = p.textureArchiveSerializer(settings, profile)
+			}
 		}
 		files = append(files, sc)
 	}
@@ -312,7 +338,7 @@ func (p *Project) Package(reader content_archive.FileReader) error {
 	files = append(files, content_archive.SourceContent{
 		Key:     stages.EntryPointAssetKey,
 		RawData: []byte(p.Settings.EntryPointStage),
-	})
+	}, stringTablesAsset(allReferencedContent))
 	err = content_archive.CreateArchiveFromFiles(reader, outPath,
 		files, []byte(p.Settings.ArchiveEncryptionKey))
 	if err != nil {
@@ -330,6 +356,23 @@ func (p *Project) Package(reader content_archive.FileReader) error {
 	return err
 }
 
+// stringTablesAsset lists the string tables in the content for the host to
+// load at startup, see [localization.StringTablesAssetKey]
+func stringTablesAsset(content []content_database.CachedContent) content_archive.SourceContent {
+	ids := []string{}
+	for i := range content {
+		if content[i].Config.Type == (content_database.StringTable{}).TypeName() {
+			ids = append(ids, content[i].Id())
+		}
+	}
+	// Ending with a newline keeps the data from being empty, which the
+	// archive would read from a file and debug packaging would skip
+	return content_archive.SourceContent{
+		Key:     localization.StringTablesAssetKey,
+		RawData: []byte(strings.Join(ids, "\n") + "\n"),
+	}
+}
+
 func (p *Project) Run(args ...string) {
 	defer tracing.NewRegion("Project.Run").End()
 	if len(args) > 0 {
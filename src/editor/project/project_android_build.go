@@ -26,6 +26,7 @@ import (
 	"kaijuengine.com/engine/assets/content_archive"
 	"kaijuengine.com/platform/filesystem"
 	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering/texcompress"
 )
 
 func (p *Project) BuildRunAndroid(reader content_archive.FileReader, ndkHome, javaHome string, tags []string) error {
@@ -42,7 +43,7 @@ func (p *Project) BuildRunAndroid(reader content_archive.FileReader, ndkHome, ja
 func (p *Project) BuildAndroid(reader content_archive.FileReader, ndkHome, javaHome string, tags []string) error {
 	defer tracing.NewRegion("Project.BuildAndroid").End()
 	sdkHome := filepath.Join(ndkHome, "../../")
-	if err := p.Package(reader); err != nil {
+	if err := p.PackageFor(reader, texcompress.ProfileAndroid); err != nil {
 		return err
 	}
 	if err := p.copyAndroidProjectTemplate(); err != nil {
@@ -214,7 +215,7 @@ func (p *Project) buildKaijuAndroidLibrary(sdkHome, ndkHome string, tags []strin
 func (p *Project) copyAndroidContentToAssets() error {
 	defer tracing.NewRegion("Project.copyAndroidContentToAssets").End()
 	slog.Info("copying content to android assets")
-	from := p.packagePath()
+	from := p.PackagePath()
 	toDir := filepath.Join(
 		p.fileSystem.FullPath(project_file_system.ProjectBuildAndroidFolder),
 		"app/src/main/assets")
@@ -79,6 +79,10 @@ func Import(path string, fs *project_file_system.FileSystem, cache *Cache, linke
 	if err != nil {
 		return res, err
 	}
+	srcHash, err := hashImportSource(path, fs)
+	if err != nil {
+		return res, err
+	}
 	useLinkedId := linkedId != "" || len(proc.Variants) > 1 ||
 		len(proc.Dependencies) > 0
 	res = klib.SliceSetLen(res, len(proc.Variants))
@@ -154,6 +158,9 @@ func Import(path string, fs *project_file_system.FileSystem, cache *Cache, linke
 		if err != nil {
 			return res, err
 		}
+		if err := writeImportRecord(res[i].Id, srcHash, fs, cache); err != nil {
+			slog.Warn("failed to write the import record", "id", res[i].Id, "error", err)
+		}
 	}
 	return res, err
 }
@@ -200,7 +207,12 @@ func Reimport(id string, fs *project_file_system.FileSystem, cache *Cache) (Impo
 		return res, err
 	}
 	if post, ok := cat.(postReimportProcessor); ok {
-		err = post.PostReimportProcessing(proc, &res, fs, cache)
+		if err = post.PostReimportProcessing(proc, &res, fs, cache); err != nil {
+			return res, err
+		}
+	}
+	if err := writeImportRecord(id, "", fs, cache); err != nil {
+		slog.Warn("failed to write the import record", "id", id, "error", err)
 	}
 	return res, nil
 }
@@ -225,5 +237,6 @@ func Delete(id string, fs *project_file_system.FileSystem, cache *Cache) error {
 		return err
 	}
 	cache.Remove(id)
+	removeImportRecord(id, fs)
 	return nil
 }
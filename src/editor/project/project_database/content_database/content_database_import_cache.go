@@ -0,0 +1,435 @@
+/******************************************************************************/
+/* content_database_import_cache.go                                           */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package content_database
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"kaijuengine.com/editor/project/project_file_system"
+	"kaijuengine.com/platform/concurrent"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// defaultImporterVersion is the version of the importer for categories that
+// don't implement [versionedImporter]
+const defaultImporterVersion = 1
+
+// contentIdPattern matches the id of content that was generated on import,
+// the stored extension that may follow it is checked separately
+var contentIdPattern = regexp.MustCompile(`[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// versionedImporter can be implemented by a [ContentCategory] to have all of
+// the content of that category re-imported when the importer changes. The
+// version should be incremented any time the output of the importer changes
+// for the same source file.
+type versionedImporter interface {
+	ImporterVersion() int
+}
+
+// ImportRecord is what a piece of content was imported from. It is stored in
+// the editor cache and is compared against the source file, the importer, and
+// the import settings to know if the content is out of date.
+type ImportRecord struct {
+	// SourceHash is the SHA-256 of the source file the content was imported
+	// from. The size and modified time are kept so that the source file only
+	// needs to be hashed again when one of them changes.
+	SourceHash    string
+	SourceSize    int64
+	SourceModTime time.Time
+
+	// ImporterVersion is the version of the category's importer that was used
+	ImporterVersion int
+
+	// SettingsHash is the hash of the category specific config of the content
+	SettingsHash string
+
+	// Dependencies are the ids of the other content that this content uses,
+	// such as the textures and shader of a material. When any of them are
+	// re-imported, this content is re-imported too.
+	Dependencies []string `json:",omitempty"`
+}
+
+// ReimportResult is the outcome of re-importing a single piece of content from
+// its source file with [ReimportContent]
+type ReimportResult struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Skipped is true if the content has no source file to re-import from
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+func importRecordPath(id string) string {
+	return filepath.Join(project_file_system.EditorCacheImports, id+".json")
+}
+
+// ReadImportRecords reads all of the import records in the editor cache, keyed
+// by the id of the content they are for
+func ReadImportRecords(fs *project_file_system.FileSystem) (map[string]ImportRecord, error) {
+	defer tracing.NewRegion("content_database.ReadImportRecords").End()
+	records := map[string]ImportRecord{}
+	entries, err := fs.ReadDir(project_file_system.EditorCacheImports)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return records, nil
+		}
+		return records, err
+	}
+	for i := range entries {
+		name := entries[i].Name()
+		if entries[i].IsDir() || name[0] == '.' || filepath.Ext(name) != ".json" {
+			continue
+		}
+		data, err := fs.ReadFile(filepath.Join(project_file_system.EditorCacheImports, name))
+		if err != nil {
+			return records, err
+		}
+		var rec ImportRecord
+		// A record that can't be read is treated as missing, which will cause
+		// the content to be re-imported and the record to be written again
+		if json.Unmarshal(data, &rec) == nil {
+			records[strings.TrimSuffix(name, ".json")] = rec
+		}
+	}
+	return records, nil
+}
+
+// writeImportRecord creates the import record for the content from the current
+// state of its source file, config, and content file. The source hash can be
+// supplied when it is already known, otherwise the source file is hashed.
+func writeImportRecord(id, srcHash string, fs *project_file_system.FileSystem, cache *Cache) error {
+	defer tracing.NewRegion("content_database.writeImportRecord").End()
+	cc, err := cache.Read(id)
+	if err != nil {
+		return err
+	}
+	src, err := os.Stat(importSourcePath(cc.Config.SrcPath, fs))
+	if err != nil {
+		// Content without a source file can't be re-imported, so there is
+		// nothing to record for it
+		return nil
+	}
+	if srcHash == "" {
+		if srcHash, err = hashImportSource(cc.Config.SrcPath, fs); err != nil {
+			return err
+		}
+	}
+	rec := ImportRecord{
+		SourceHash:      srcHash,
+		SourceSize:      src.Size(),
+		SourceModTime:   src.ModTime(),
+		ImporterVersion: importerVersion(cc.Config.Type),
+		SettingsHash:    importSettingsHash(cc.Config),
+	}
+	if rec.Dependencies, err = ContentReferences(&cc, fs, cache); err != nil {
+		return err
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if err = fs.MkdirAll(project_file_system.EditorCacheImports, os.ModePerm); err != nil {
+		return err
+	}
+	return fs.WriteFile(importRecordPath(id), data, os.ModePerm)
+}
+
+func removeImportRecord(id string, fs *project_file_system.FileSystem) {
+	fs.Remove(importRecordPath(id))
+}
+
+// importSourcePath is the path to use for opening the source file, source files
+// within the project are stored relative to it
+func importSourcePath(srcPath string, fs *project_file_system.FileSystem) string {
+	if fs.Exists(srcPath) {
+		return fs.FullPath(srcPath)
+	}
+	return srcPath
+}
+
+func hashImportSource(srcPath string, fs *project_file_system.FileSystem) (string, error) {
+	defer tracing.NewRegion("content_database.hashImportSource").End()
+	f, err := os.Open(importSourcePath(srcPath, fs))
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func importerVersion(typeName string) int {
+	cat, ok := CategoryFromTypeName(typeName)
+	if !ok {
+		return 0
+	}
+	if v, ok := cat.(versionedImporter); ok {
+		return v.ImporterVersion()
+	}
+	return defaultImporterVersion
+}
+
+// importSettingsHash hashes the parts of the config that change the output of
+// the import. The developer facing name and tags are left out on purpose.
+func importSettingsHash(cfg ContentConfig) string {
+	cfg.Name = ""
+	cfg.Tags = nil
+	data, _ := json.Marshal(cfg)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ContentReferences finds the ids of other content in the cache that are
+// referenced by the content file or the config of the content
+func ContentReferences(cc *CachedContent, fs *project_file_system.FileSystem, cache *Cache) ([]string, error) {
+	defer tracing.NewRegion("content_database.ContentReferences").End()
+	data, err := fs.ReadFile(cc.ContentPath())
+	if err != nil {
+		return nil, err
+	}
+	self := cc.Id()
+	deps := []string{}
+	add := func(text []byte) {
+		for _, loc := range contentIdPattern.FindAllIndex(text, -1) {
+			id := string(text[loc[0]:loc[1]])
+			// Most ids have the extension of the file they were imported from
+			// after them, try to find the id with it first
+			if end := loc[1]; end < len(text) && text[end] == '.' {
+				ext := end + 1
+				for ext < len(text) && isContentIdExtByte(text[ext]) {
+					ext++
+				}
+				if _, err := cache.Read(string(text[loc[0]:ext])); err == nil {
+					id = string(text[loc[0]:ext])
+				}
+			}
+			if id == self || slices.Contains(deps, id) {
+				continue
+			}
+			if _, err := cache.Read(id); err == nil {
+				deps = append(deps, id)
+			}
+		}
+	}
+	add(data)
+	cfg, _ := json.Marshal(cc.Config)
+	add(cfg)
+	slices.Sort(deps)
+	return deps, nil
+}
+
+func isContentIdExtByte(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// importRecordOutdated returns true if the content needs to be re-imported to
+// match its import record. If only the modified time of the source file has
+// changed, but not its contents, the record is updated instead.
+func importRecordOutdated(cc *CachedContent, rec ImportRecord, fs *project_file_system.FileSystem) bool {
+	if rec.ImporterVersion != importerVersion(cc.Config.Type) ||
+		rec.SettingsHash != importSettingsHash(cc.Config) ||
+		!fs.FileExists(cc.ContentPath()) {
+		return true
+	}
+	src, err := os.Stat(importSourcePath(cc.Config.SrcPath, fs))
+	if err != nil {
+		return true
+	}
+	if src.Size() == rec.SourceSize && src.ModTime().Equal(rec.SourceModTime) {
+		return false
+	}
+	hash, err := hashImportSource(cc.Config.SrcPath, fs)
+	if err != nil || hash != rec.SourceHash {
+		return true
+	}
+	rec.SourceSize = src.Size()
+	rec.SourceModTime = src.ModTime()
+	if data, err := json.Marshal(rec); err == nil {
+		fs.WriteFile(importRecordPath(cc.Id()), data, os.ModePerm)
+	}
+	return false
+}
+
+// OutdatedContent returns the ids of the content that needs to be re-imported.
+// This is all of the content whose source file, importer, or import settings
+// have changed since it was last imported, or that has never been recorded,
+// along with all of the content that depends on it. The ids are sorted.
+func OutdatedContent(fs *project_file_system.FileSystem, cache *Cache) ([]string, error) {
+	defer tracing.NewRegion("content_database.OutdatedContent").End()
+	records, err := ReadImportRecords(fs)
+	if err != nil {
+		return nil, err
+	}
+	list := cache.List()
+	outdated := map[string]bool{}
+	queue := []string{}
+	for i := range list {
+		cc := &list[i]
+		if cc.Config.SrcPath == "" {
+			continue
+		}
+		id := cc.Id()
+		if rec, ok := records[id]; !ok || importRecordOutdated(cc, rec, fs) {
+			outdated[id] = true
+			queue = append(queue, id)
+		}
+	}
+	dependents := map[string][]string{}
+	for id, rec := range records {
+		for _, dep := range rec.Dependencies {
+			dependents[dep] = append(dependents[dep], id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		for _, dep := range dependents[id] {
+			if !outdated[dep] {
+				if _, err := cache.Read(dep); err == nil {
+					outdated[dep] = true
+					queue = append(queue, dep)
+				}
+			}
+		}
+	}
+	ids := make([]string, 0, len(outdated))
+	for id := range outdated {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
+
+// importWaves splits the ids into groups that can be imported at the same time.
+// Content is placed into a wave after all of the content in the ids that it
+// depends on. If the dependencies form a cycle, the content in the cycle is
+// placed together in the last wave.
+func importWaves(ids []string, records map[string]ImportRecord) [][]string {
+	pending := map[string]bool{}
+	for _, id := range ids {
+		pending[id] = true
+	}
+	waves := [][]string{}
+	for len(pending) > 0 {
+		wave := []string{}
+		for _, id := range ids {
+			if !pending[id] {
+				continue
+			}
+			ready := true
+			for _, dep := range records[id].Dependencies {
+				if dep != id && pending[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				wave = append(wave, id)
+			}
+		}
+		if len(wave) == 0 {
+			for _, id := range ids {
+				if pending[id] {
+					wave = append(wave, id)
+				}
+			}
+		}
+		for _, id := range wave {
+			delete(pending, id)
+		}
+		waves = append(waves, wave)
+	}
+	return waves
+}
+
+// ReimportContent re-imports the content with the given ids from their source
+// files. Content that doesn't depend on each other is re-imported in parallel
+// on the threads, content that shares a source file is always re-imported on
+// the same thread. If threads is nil, threads are started for the duration of
+// the call. The results are in the same order as the ids.
+func ReimportContent(ids []string, fs *project_file_system.FileSystem, cache *Cache, threads *concurrent.Threads) []ReimportResult {
+	defer tracing.NewRegion("content_database.ReimportContent").End()
+	results := make([]ReimportResult, len(ids))
+	index := make(map[string]int, len(ids))
+	toImport := make([]string, 0, len(ids))
+	for i, id := range ids {
+		index[id] = i
+		results[i].Id = id
+		cc, err := cache.Read(id)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Name = cc.Config.Name
+		results[i].Type = cc.Config.Type
+		if cc.Config.SrcPath == "" {
+			results[i].Skipped = true
+			continue
+		}
+		toImport = append(toImport, id)
+	}
+	if len(toImport) == 0 {
+		return results
+	}
+	records, err := ReadImportRecords(fs)
+	if err != nil {
+		for _, id := range toImport {
+			results[index[id]].Error = err.Error()
+		}
+		return results
+	}
+	if threads == nil {
+		threads = &concurrent.Threads{}
+		threads.Initialize()
+		threads.Start()
+		defer threads.Stop()
+	}
+	for _, wave := range importWaves(toImport, records) {
+		groups := map[string][]string{}
+		order := []string{}
+		for _, id := range wave {
+			cc, _ := cache.Read(id)
+			src := fs.NormalizePath(cc.Config.SrcPath)
+			if _, ok := groups[src]; !ok {
+				order = append(order, src)
+			}
+			groups[src] = append(groups[src], id)
+		}
+		wg := sync.WaitGroup{}
+		wg.Add(len(order))
+		work := make([]func(threadId int), len(order))
+		for i := range order {
+			group := groups[order[i]]
+			work[i] = func(int) {
+				defer wg.Done()
+				for _, id := range group {
+					// Each result is only written by the thread of its group
+					if _, err := Reimport(id, fs, cache); err != nil {
+						results[index[id]].Error = err.Error()
+					}
+				}
+			}
+		}
+		threads.AddWork(work)
+		wg.Wait()
+	}
+	return results
+}
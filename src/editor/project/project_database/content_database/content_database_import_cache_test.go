@@ -0,0 +1,132 @@
+/******************************************************************************/
+/* content_database_import_cache_test.go                                      */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package content_database
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestOutdatedContentFollowsDependents(t *testing.T) {
+	pfs, root := newConfigFS(t)
+	cache := New()
+	cssPath := filepath.Join(root, "src", "style.css")
+	htmlPath := filepath.Join(root, "src", "page.html")
+	writeFile(t, root, "src/style.css", []byte("body { color: red; }"))
+	css, err := Import(cssPath, pfs, &cache, "")
+	if err != nil {
+		t.Fatalf("Import(style.css) returned error: %v", err)
+	}
+	cssId := css[0].Id
+	writeFile(t, root, "src/page.html", []byte(`<link rel="stylesheet" href="`+cssId+`">`))
+	html, err := Import(htmlPath, pfs, &cache, "")
+	if err != nil {
+		t.Fatalf("Import(page.html) returned error: %v", err)
+	}
+	htmlId := html[0].Id
+	records, err := ReadImportRecords(pfs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps := records[htmlId].Dependencies; !slices.Equal(deps, []string{cssId}) {
+		t.Fatalf("html dependencies = %q, want [%s]", deps, cssId)
+	}
+	if ids, _ := OutdatedContent(pfs, &cache); len(ids) != 0 {
+		t.Fatalf("expected nothing to be outdated after import, got %q", ids)
+	}
+	// Touching the source without changing it only updates the record
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(cssPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	if ids, _ := OutdatedContent(pfs, &cache); len(ids) != 0 {
+		t.Fatalf("expected an unchanged source to not be outdated, got %q", ids)
+	}
+	if records, _ = ReadImportRecords(pfs); !records[cssId].SourceModTime.Equal(later) {
+		t.Error("expected the record to have the new modified time of the source")
+	}
+	writeFile(t, root, "src/style.css", []byte("body { color: blue; }"))
+	ids, err := OutdatedContent(pfs, &cache)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{cssId, htmlId}
+	slices.Sort(want)
+	if !slices.Equal(ids, want) {
+		t.Fatalf("outdated = %q, want %q", ids, want)
+	}
+	for _, res := range ReimportContent(ids, pfs, &cache, nil) {
+		if res.Error != "" || res.Skipped {
+			t.Errorf("unexpected re-import result %+v", res)
+		}
+	}
+	data, err := pfs.ReadFile(css[0].ContentPath().String())
+	if err != nil || string(data) != "body { color: blue; }" {
+		t.Errorf("expected the css content to be re-imported, got %q (%v)", data, err)
+	}
+	if ids, _ := OutdatedContent(pfs, &cache); len(ids) != 0 {
+		t.Fatalf("expected nothing to be outdated after re-import, got %q", ids)
+	}
+}
+
+func TestOutdatedContentSettingsAndMissingRecords(t *testing.T) {
+	pfs, root := newConfigFS(t)
+	cache := New()
+	writeFile(t, root, "src/style.css", []byte("body {}"))
+	res, err := Import(filepath.Join(root, "src", "style.css"), pfs, &cache, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := res[0].Id
+	cc, _ := cache.Read(id)
+	cc.Config.Name = "renamed"
+	cache.IndexCachedContent(cc)
+	if ids, _ := OutdatedContent(pfs, &cache); len(ids) != 0 {
+		t.Fatalf("expected a rename to not change the import settings, got %q", ids)
+	}
+	srcName := cc.Config.SrcName
+	cc.Config.SrcName = "other"
+	cache.IndexCachedContent(cc)
+	if ids, _ := OutdatedContent(pfs, &cache); !slices.Equal(ids, []string{id}) {
+		t.Fatalf("expected changed settings to be outdated, got %q", ids)
+	}
+	cc.Config.SrcName = srcName
+	cache.IndexCachedContent(cc)
+	removeImportRecord(id, pfs)
+	if ids, _ := OutdatedContent(pfs, &cache); !slices.Equal(ids, []string{id}) {
+		t.Fatalf("expected content without a record to be outdated, got %q", ids)
+	}
+}
+
+func TestImportWavesOrdersDependencies(t *testing.T) {
+	records := map[string]ImportRecord{
+		"mesh":     {Dependencies: []string{"material"}},
+		"material": {Dependencies: []string{"texture", "shader"}},
+		"sound":    {},
+		"a":        {Dependencies: []string{"b"}},
+		"b":        {Dependencies: []string{"a"}},
+	}
+	ids := []string{"mesh", "material", "texture", "shader", "sound", "a", "b"}
+	waves := importWaves(ids, records)
+	want := [][]string{
+		{"texture", "shader", "sound"},
+		{"material"},
+		{"mesh"},
+		{"a", "b"},
+	}
+	if len(waves) != len(want) {
+		t.Fatalf("waves = %q, want %q", waves, want)
+	}
+	for i := range want {
+		if !slices.Equal(waves[i], want[i]) {
+			t.Errorf("wave %d = %q, want %q", i, waves[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,59 @@
+/******************************************************************************/
+/* content_database_string_table.go                                           */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package content_database
+
+import (
+	"kaijuengine.com/editor/project/project_file_system"
+	"kaijuengine.com/localization"
+	"kaijuengine.com/platform/filesystem"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+func init() { addCategory(StringTable{}) }
+
+// StringTable is a [ContentCategory] represented by a file with a ".csv",
+// ".po" or ".json" extension. It holds the localized strings of one or more
+// languages, see [localization.ParseStringTables] for the formats. No matter
+// the source format, the tables are stored as JSON so the game only has to
+// read one format.
+type StringTable struct{}
+
+// See the documentation for the interface [ContentCategory] to learn more about
+// the following functions
+
+func (StringTable) Path() string                { return project_file_system.ContentStringTableFolder }
+func (StringTable) TypeName() string            { return "StringTable" }
+func (StringTable) ExtNames() []string          { return []string{".csv", ".po", ".json"} }
+func (StringTable) StoredExtName(string) string { return ".json" }
+
+func (StringTable) Import(src string, _ *project_file_system.FileSystem) (ProcessedImport, error) {
+	defer tracing.NewRegion("StringTable.Import").End()
+	p := ProcessedImport{}
+	data, err := filesystem.ReadFile(src)
+	if err != nil {
+		return p, err
+	}
+	tables, err := localization.ParseStringTables(src, data)
+	if err != nil {
+		return p, err
+	}
+	stored, err := localization.MarshalStringTables(tables)
+	if err != nil {
+		return p, err
+	}
+	p.Variants = append(p.Variants, ImportVariant{Name: fileNameNoExt(src), Data: stored})
+	return p, nil
+}
+
+func (c StringTable) Reimport(id string, cache *Cache, fs *project_file_system.FileSystem) (ProcessedImport, error) {
+	defer tracing.NewRegion("StringTable.Reimport").End()
+	return reimportByNameMatching(c, id, cache, fs)
+}
+
+func (StringTable) PostImportProcessing(proc ProcessedImport, res *ImportResult, fs *project_file_system.FileSystem, cache *Cache, linkedId string) error {
+	return nil
+}
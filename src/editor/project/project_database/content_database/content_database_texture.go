@@ -19,6 +19,7 @@ import (
 
 	"kaijuengine.com/editor/project/project_file_system"
 	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering/texcompress"
 
 	"golang.org/x/image/bmp"
 	"golang.org/x/image/webp"
@@ -29,7 +30,24 @@ func init() { addCategory(Texture{}) }
 // Texture is a [ContentCategory] represented by a file with a ".png", ".jpg",
 // ".jpeg" or ".webp extension. Textures are as they seem.
 type Texture struct{}
-type TextureConfig struct{}
+
+// TextureConfig holds the options for how the texture is prepared when the
+// game content is packaged, the content itself is always kept as an image.
+type TextureConfig struct {
+	// Usage is what the texels of the texture are, it changes how the mip
+	// levels are filtered and which format the platform compresses to. When
+	// it is empty, the usage is taken from the material slots the texture is
+	// used in, or color if it isn't used by a PBR material.
+	Usage texcompress.Usage `json:",omitempty"`
+
+	// Compression overrides the format the platform would pick, "none" keeps
+	// the texture uncompressed. Normal maps compressed as "bc5" only keep X
+	// and Y, so they need a shader that rebuilds Z.
+	Compression texcompress.Format `json:",omitempty"`
+
+	// NoMipmaps skips generating the mip levels of the texture
+	NoMipmaps bool `json:",omitempty"`
+}
 
 // See the documentation for the interface [ContentCategory] to learn more about
 // the following functions
@@ -31,3 +31,12 @@ func (e ProjectOpenError) Error() string {
 		return fmt.Sprintf("the path specified is not a Kaiju project: %s", e.Path)
 	}
 }
+
+type CompileError struct {
+	Err error
+	Log string
+}
+
+func (e CompileError) Error() string {
+	return fmt.Sprintf("the project failed to compile: %v\n%s", e.Err, e.Log)
+}
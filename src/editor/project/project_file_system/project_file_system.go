@@ -49,6 +49,7 @@ var (
 		ContentCssFolder,
 		ContentTableFolder,
 		ContentTableOfContentsFolder,
+		ContentStringTableFolder,
 		ContentRenderFolder,
 		ContentRenderGraphFolder,
 		ContentMaterialFolder,
@@ -27,6 +27,8 @@ const (
 
 const (
 	EditorCacheContentPreviews = EditorCache + "/previews"
+	EditorCacheImports         = EditorCache + "/imports"
+	EditorCacheTextures        = EditorCache + "/textures"
 )
 
 const (
@@ -57,6 +59,7 @@ const (
 	ContentTextureFolder         = "texture"
 	ContentTableFolder           = "table"
 	ContentTableOfContentsFolder = ContentTableFolder + "/content"
+	ContentStringTableFolder     = ContentTableFolder + "/strings"
 	ContentUiFolder              = "ui"
 	ContentHtmlFolder            = ContentUiFolder + "/html"
 	ContentCssFolder             = ContentUiFolder + "/css"
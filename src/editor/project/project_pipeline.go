@@ -0,0 +1,157 @@
+/******************************************************************************/
+/* project_pipeline.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"kaijuengine.com/editor/project/project_database/content_database"
+	"kaijuengine.com/editor/project/project_file_system"
+	"kaijuengine.com/engine/stages"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// MissingReference is a reference to content, from a stage, template, or the
+// project settings, that doesn't exist
+type MissingReference struct {
+	// Id is the id of the content that is missing
+	Id string `json:"id"`
+	// Source is the id of the content the reference was found in
+	Source string `json:"source"`
+	// Entity is the id of the entity within the source that has the reference
+	Entity string `json:"entity,omitempty"`
+	// Field is where on the entity the reference is, such as "Material" or
+	// the name of a data binding field
+	Field string `json:"field"`
+}
+
+// ReimportContent re-imports all of the content in the project that was
+// imported from a source file. When changedOnly is true, only the content that
+// is out of date with its import record, and the content that depends on it,
+// is re-imported. See [content_database.OutdatedContent] for more details.
+func (p *Project) ReimportContent(changedOnly bool) ([]content_database.ReimportResult, error) {
+	defer tracing.NewRegion("Project.ReimportContent").End()
+	var ids []string
+	if changedOnly {
+		var err error
+		if ids, err = content_database.OutdatedContent(&p.fileSystem, &p.cacheDatabase); err != nil {
+			return nil, err
+		}
+	} else {
+		list := p.cacheDatabase.List()
+		ids = make([]string, 0, len(list))
+		for i := range list {
+			if list[i].Config.SrcPath != "" {
+				ids = append(ids, list[i].Id())
+			}
+		}
+	}
+	return content_database.ReimportContent(ids, &p.fileSystem, &p.cacheDatabase, nil), nil
+}
+
+// ValidateReferences looks through all of the stages, templates, and the
+// project settings for references to content that doesn't exist. The exists
+// func reports if content that isn't in the project's content database (such
+// as the engine's stock content) exists.
+func (p *Project) ValidateReferences(exists func(id string) bool) ([]MissingReference, error) {
+	defer tracing.NewRegion("Project.ValidateReferences").End()
+	found := func(id string) bool {
+		if _, err := p.cacheDatabase.Read(id); err == nil {
+			return true
+		}
+		return exists(id)
+	}
+	missing := []MissingReference{}
+	if id := p.Settings.EntryPointStage; id != "" && !found(id) {
+		missing = append(missing, MissingReference{
+			Id:     id,
+			Source: "ProjectSettings",
+			Field:  "EntryPointStage",
+		})
+	}
+	folders := []string{
+		project_file_system.ContentStageFolder,
+		project_file_system.ContentTemplateFolder,
+	}
+	for _, folder := range folders {
+		dir := filepath.Join(project_file_system.ContentFolder, folder)
+		entries, err := p.fileSystem.ReadDir(dir)
+		if err != nil {
+			return missing, err
+		}
+		for i := range entries {
+			name := entries[i].Name()
+			if entries[i].IsDir() || name[0] == '.' {
+				continue
+			}
+			data, err := p.fileSystem.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return missing, err
+			}
+			var entities []stages.EntityDescription
+			if folder == project_file_system.ContentStageFolder {
+				var ss stages.StageJson
+				if err := json.Unmarshal(data, &ss); err != nil {
+					return missing, err
+				}
+				s := stages.Stage{}
+				s.FromMinimized(ss)
+				entities = s.Entities
+			} else {
+				var desc stages.EntityDescription
+				if err := json.Unmarshal(data, &desc); err != nil {
+					return missing, err
+				}
+				entities = []stages.EntityDescription{desc}
+			}
+			for j := range entities {
+				missing = append(missing, p.missingEntityRefs(&entities[j], name, found)...)
+			}
+		}
+	}
+	return missing, nil
+}
+
+func (p *Project) missingEntityRefs(e *stages.EntityDescription, source string, found func(id string) bool) []MissingReference {
+	var missing []MissingReference
+	check := func(id, field string) {
+		if id != "" && !found(id) {
+			missing = append(missing, MissingReference{
+				Id:     id,
+				Source: source,
+				Entity: e.Id,
+				Field:  field,
+			})
+		}
+	}
+	check(e.Material, "Material")
+	check(e.Mesh, "Mesh")
+	check(e.TemplateId, "TemplateId")
+	for i := range e.Textures {
+		check(e.Textures[i], "Textures")
+	}
+	for i := range e.DataBinding {
+		// Fields of bindings that aren't known can't be told apart from plain
+		// strings, so they are not validated
+		if _, ok := p.EntityDataBinding(e.DataBinding[i].RegistraionKey); !ok {
+			continue
+		}
+		for k, v := range e.DataBinding[i].Fields {
+			if !p.isContentIdDataBindingField(&e.DataBinding[i], k) {
+				continue
+			}
+			if s, ok := dataBindingReferenceString(v); ok {
+				check(s, k)
+			}
+		}
+	}
+	for i := range e.Children {
+		missing = append(missing, p.missingEntityRefs(&e.Children[i], source, found)...)
+	}
+	return missing
+}
@@ -0,0 +1,39 @@
+/******************************************************************************/
+/* project_pipeline_test.go                                                   */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package project
+
+import (
+	"testing"
+
+	"kaijuengine.com/engine/stages"
+)
+
+func TestMissingEntityRefsReportsMissingContent(t *testing.T) {
+	p := Project{}
+	desc := stages.EntityDescription{
+		Id:       "parent",
+		Material: "present",
+		Mesh:     "gone-mesh",
+		Children: []stages.EntityDescription{{
+			Id:       "child",
+			Textures: []string{"present", "gone-texture"},
+		}},
+	}
+	found := func(id string) bool { return id == "present" }
+
+	missing := p.missingEntityRefs(&desc, "stage", found)
+
+	if len(missing) != 2 {
+		t.Fatalf("expected two missing references, got %+v", missing)
+	}
+	if missing[0] != (MissingReference{Id: "gone-mesh", Source: "stage", Entity: "parent", Field: "Mesh"}) {
+		t.Errorf("unexpected missing mesh reference %+v", missing[0])
+	}
+	if missing[1] != (MissingReference{Id: "gone-texture", Source: "stage", Entity: "child", Field: "Textures"}) {
+		t.Errorf("unexpected missing texture reference %+v", missing[1])
+	}
+}
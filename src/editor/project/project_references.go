@@ -224,7 +224,12 @@ func (p *Project) findReferencesCode(id string, onFound func(ref ContentReferenc
 }
 
 func (p *Project) findReferencesSettings(id string, onFound func(ref ContentReference)) error {
-	if p.Settings.EntryPointStage == id {
+	// The host loads every string table at startup, see [stringTablesAsset]
+	isStringTable := false
+	if cc, err := p.cacheDatabase.Read(id); err == nil {
+		isStringTable = cc.Config.Type == (content_database.StringTable{}).TypeName()
+	}
+	if p.Settings.EntryPointStage == id || isStringTable {
 		onFound(ContentReference{
 			Id:     "ProjectSettings",
 			Name:   "ProjectSettings",
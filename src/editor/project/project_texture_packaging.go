@@ -0,0 +1,153 @@
+/******************************************************************************/
+/* project_texture_packaging.go                                               */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"kaijuengine.com/editor/project/project_database/content_database"
+	"kaijuengine.com/editor/project/project_file_system"
+	"kaijuengine.com/engine/assets/content_archive"
+	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering"
+	"kaijuengine.com/rendering/texcompress"
+)
+
+// textureCompressionVersion is part of the key for the compressed textures in
+// the editor cache, it should be changed whenever the encoders change output
+const textureCompressionVersion = 1
+
+// pbrTextureSlotUsages are the usages of the texture slots in the PBR shaders,
+// in the order the mesh importer writes them to materials
+var pbrTextureSlotUsages = []texcompress.Usage{
+	texcompress.UsageColor,  // baseColor
+	texcompress.UsageNormal, // normal
+	texcompress.UsageLinear, // metallicRoughness
+	texcompress.UsageColor,  // emissive
+}
+
+// textureUsagePriority is used when a texture fills different slots, a
+// texture that is used as a normal map anywhere is treated as one
+var textureUsagePriority = map[texcompress.Usage]int{
+	texcompress.UsageColor:  0,
+	texcompress.UsageLinear: 1,
+	texcompress.UsageNormal: 2,
+}
+
+// texturePackaging is what is known about each texture before packaging
+type texturePackaging struct {
+	usages map[string]texcompress.Usage
+	// cpuRead are the textures the game reads the pixels of, those are kept as
+	// images rather than being compressed
+	cpuRead map[string]bool
+}
+
+func (p *Project) readTexturePackaging(content []content_database.CachedContent) texturePackaging {
+	defer tracing.NewRegion("Project.readTexturePackaging").End()
+	tp := texturePackaging{
+		usages:  make(map[string]texcompress.Usage),
+		cpuRead: make(map[string]bool),
+	}
+	for i := range content {
+		switch content[i].Config.Type {
+		case (content_database.Material{}).TypeName():
+			data, err := p.fileSystem.ReadFile(content[i].ContentPath())
+			if err != nil {
+				continue
+			}
+			var mat rendering.MaterialData
+			if err := json.Unmarshal(data, &mat); err != nil {
+				continue
+			}
+			shader := path.Base(filepath.ToSlash(mat.Shader))
+			if !strings.HasPrefix(shader, "pbr") {
+				continue
+			}
+			for j := range min(len(mat.Textures), len(pbrTextureSlotUsages)) {
+				id, usage := mat.Textures[j].Texture, pbrTextureSlotUsages[j]
+				if current, ok := tp.usages[id]; !ok || textureUsagePriority[usage] > textureUsagePriority[current] {
+					tp.usages[id] = usage
+				}
+			}
+		case (content_database.Terrain{}).TypeName():
+			// Terrain builds its material atlases from the texture pixels
+			refs, err := content_database.ContentReferences(&content[i], &p.fileSystem, &p.cacheDatabase)
+			if err != nil {
+				continue
+			}
+			for _, id := range refs {
+				tp.cpuRead[id] = true
+			}
+		}
+	}
+	return tp
+}
+
+// textureSettings returns the compression settings for the texture, the second
+// return value is false if the texture should be packaged as it is
+func (tp *texturePackaging) textureSettings(cc *content_database.CachedContent) (texcompress.Settings, bool) {
+	if tp.cpuRead[cc.Id()] || strings.EqualFold(filepath.Ext(cc.Config.SrcPath), ".cube") {
+		return texcompress.Settings{}, false
+	}
+	settings := texcompress.Settings{Usage: tp.usages[cc.Id()]}
+	if cfg := cc.Config.Texture; cfg != nil {
+		if cfg.Usage != "" {
+			settings.Usage = cfg.Usage
+		}
+		settings.Format = cfg.Compression
+		settings.NoMipmaps = cfg.NoMipmaps
+	}
+	return settings, true
+}
+
+// textureArchiveSerializer returns the serializer that compresses the texture
+// image into a KTX2 file for the profile. The compressed files are kept in the
+// editor cache, keyed by the image, settings, and profile, so that packaging
+// again only compresses the textures that have changed.
+func (p *Project) textureArchiveSerializer(settings texcompress.Settings, profile texcompress.Profile) func(content_archive.FileReader, []byte) ([]byte, error) {
+	return func(_ content_archive.FileReader, rawData []byte) ([]byte, error) {
+		defer tracing.NewRegion("Project.textureArchiveSerializer").End()
+		key, _ := json.Marshal(struct {
+			Version  int
+			Settings texcompress.Settings
+			Profile  texcompress.Profile
+		}{textureCompressionVersion, settings, profile})
+		hash := sha256.New()
+		hash.Write(key)
+		hash.Write(rawData)
+		cachePath := filepath.Join(project_file_system.EditorCacheTextures,
+			hex.EncodeToString(hash.Sum(nil))+".ktx2")
+		if data, err := p.fileSystem.ReadFile(cachePath); err == nil && rendering.IsKTX2(data) {
+			return data, nil
+		}
+		img, _, err := image.Decode(bytes.NewReader(rawData))
+		if err != nil {
+			return rawData, err
+		}
+		data, err := texcompress.Compress(img, settings, profile)
+		if err != nil {
+			return rawData, err
+		}
+		if err := p.fileSystem.MkdirAll(project_file_system.EditorCacheTextures, os.ModePerm); err != nil {
+			slog.Warn("failed to create the compressed texture cache folder", "error", err)
+		} else if err := p.fileSystem.WriteFile(cachePath, data, os.ModePerm); err != nil {
+			slog.Warn("failed to cache the compressed texture", "error", err)
+		}
+		return data, nil
+	}
+}
@@ -0,0 +1,138 @@
+/******************************************************************************/
+/* project_texture_packaging_test.go                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kaijuengine.com/editor/project/project_database/content_database"
+	"kaijuengine.com/editor/project/project_file_system"
+	"kaijuengine.com/rendering"
+	"kaijuengine.com/rendering/texcompress"
+)
+
+const (
+	testAlbedoId  = "0b6f2a1e-8c1d-4c4e-9a51-2f1d9f6c1a01"
+	testNormalId  = "0b6f2a1e-8c1d-4c4e-9a51-2f1d9f6c1a02"
+	testTerrainId = "0b6f2a1e-8c1d-4c4e-9a51-2f1d9f6c1a03"
+	testLayerId   = "0b6f2a1e-8c1d-4c4e-9a51-2f1d9f6c1a04"
+	testLUTId     = "0b6f2a1e-8c1d-4c4e-9a51-2f1d9f6c1a05"
+)
+
+func writeTestContent(t *testing.T, pfs *project_file_system.FileSystem, folder, id string, cfg content_database.ContentConfig, data []byte) {
+	t.Helper()
+	cfgDir := filepath.Join(project_file_system.ContentConfigFolder, folder)
+	contentDir := filepath.Join(project_file_system.ContentFolder, folder)
+	for _, dir := range []string{cfgDir, contentDir} {
+		if err := pfs.MkdirAll(dir, os.ModePerm); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := content_database.WriteConfig(filepath.Join(cfgDir, id+".json"), cfg, pfs); err != nil {
+		t.Fatal(err)
+	}
+	if err := pfs.WriteFile(filepath.Join(contentDir, id), data, os.ModePerm); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testTexturePNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, 8, 8))
+	for i := range 64 {
+		img.Set(i%8, i/8, color.NRGBA{byte(i * 4), 128, byte(255 - i*4), 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestTexturePackagingSettings(t *testing.T) {
+	p := Project{cacheDatabase: content_database.New()}
+	var err error
+	if p.fileSystem, err = project_file_system.New(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	pfs := &p.fileSystem
+	t.Cleanup(func() { pfs.Close() })
+	texType := (content_database.Texture{}).TypeName()
+	pngData := testTexturePNG(t)
+	for _, id := range []string{testAlbedoId, testNormalId, testLayerId} {
+		writeTestContent(t, pfs, project_file_system.ContentTextureFolder, id,
+			content_database.ContentConfig{Name: id, Type: texType, SrcPath: "src/" + id + ".png"}, pngData)
+	}
+	writeTestContent(t, pfs, project_file_system.ContentTextureFolder, testLUTId,
+		content_database.ContentConfig{Name: "grade", Type: texType, SrcPath: "src/grade.cube"}, pngData)
+	mat, _ := json.Marshal(rendering.MaterialData{
+		Shader: "pbr.shader",
+		Textures: []rendering.MaterialTextureData{
+			{Texture: testAlbedoId}, {Texture: testNormalId}, {Texture: testAlbedoId},
+		},
+	})
+	writeTestContent(t, pfs, project_file_system.ContentMaterialFolder, "mat",
+		content_database.ContentConfig{Name: "mat", Type: (content_database.Material{}).TypeName()}, mat)
+	writeTestContent(t, pfs, project_file_system.ContentTerrainFolder, testTerrainId,
+		content_database.ContentConfig{Name: "terrain", Type: (content_database.Terrain{}).TypeName()},
+		[]byte(`{"Layers":[{"TextureContentID":"`+testLayerId+`"}]}`))
+	if err = p.cacheDatabase.Build(pfs); err != nil {
+		t.Fatal(err)
+	}
+	tp := p.readTexturePackaging(p.cacheDatabase.List())
+	read := func(id string) content_database.CachedContent {
+		cc, err := p.cacheDatabase.Read(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cc
+	}
+	albedo := read(testAlbedoId)
+	// Used as both base color and metallic/roughness, the linear slot wins
+	if s, ok := tp.textureSettings(&albedo); !ok || s.Usage != texcompress.UsageLinear {
+		t.Errorf("unexpected albedo settings %+v (%t)", s, ok)
+	}
+	normal := read(testNormalId)
+	if s, ok := tp.textureSettings(&normal); !ok || s.Usage != texcompress.UsageNormal {
+		t.Errorf("unexpected normal settings %+v (%t)", s, ok)
+	}
+	normal.Config.Texture = &content_database.TextureConfig{
+		Usage: texcompress.UsageColor, Compression: texcompress.FormatNone, NoMipmaps: true}
+	want := texcompress.Settings{Usage: texcompress.UsageColor, Format: texcompress.FormatNone, NoMipmaps: true}
+	if s, _ := tp.textureSettings(&normal); s != want {
+		t.Errorf("expected the texture config to override the settings, got %+v", s)
+	}
+	for _, id := range []string{testLayerId, testLUTId} {
+		cc := read(id)
+		if _, ok := tp.textureSettings(&cc); ok {
+			t.Errorf("expected %s to be packaged uncompressed", cc.Config.Name)
+		}
+	}
+	serialize := p.textureArchiveSerializer(texcompress.Settings{}, texcompress.ProfileDesktop)
+	data, err := serialize(nil, pngData)
+	if err != nil {
+		t.Fatalf("the texture serializer returned error: %v", err)
+	}
+	tex, err := rendering.DecodeKTX2(data)
+	if err != nil || tex.Format != rendering.GPUFormatBc1RgbUnormBlock || len(tex.Levels) != 4 {
+		t.Fatalf("unexpected packaged texture %d with %d levels (%v)", tex.Format, len(tex.Levels), err)
+	}
+	cached, err := pfs.ReadDir(project_file_system.EditorCacheTextures)
+	if err != nil || len(cached) != 1 {
+		t.Fatalf("expected the compressed texture to be cached, found %d (%v)", len(cached), err)
+	}
+	if again, _ := serialize(nil, pngData); !bytes.Equal(again, data) {
+		t.Error("expected the cached texture to be used when packaging again")
+	}
+}
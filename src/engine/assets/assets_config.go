@@ -35,6 +35,7 @@ const (
 	MaterialDefinitionComposite           = "composite.material"
 	MaterialDefinitionUI                  = "ui.material"
 	MaterialDefinitionUITransparent       = "ui_transparent.material"
+	MaterialDefinitionUIBackdrop          = "ui_backdrop.material"
 	MaterialDefinitionSprite              = "sprite.material"
 	MaterialDefinitionSpriteTransparent   = "sprite_transparent.material"
 	MaterialDefinitionLightDepth          = "light_depth.material"
@@ -6,7 +6,14 @@
 
 package engine
 
+import "kaijuengine.com/rendering"
+
 const (
 	DefaultWindowWidth  = 1280
 	DefaultWindowHeight = 720
 )
+
+// DefaultGPUBackend is the backend a new host renders with. Dedicated servers
+// and tests can set it to [rendering.GPUBackendNull] before creating the host,
+// the -gpu and -headless launch parameters select it from the command line.
+var DefaultGPUBackend = rendering.GPUBackendVulkan
@@ -404,6 +404,7 @@ func (s *CollisionSolver) solveVelocity(manifold *ContactManifold) {
 		normalImpulseMagnitude := -(1 + s.Restitution) * normalVelocity / denominator
 		normalImpulseMagnitude /= matrix.Float(manifold.Count)
 		normalImpulse := normal.Scale(normalImpulseMagnitude)
+		manifold.Contacts[i].NormalImpulse += normalImpulseMagnitude
 		applyImpulse(bodyA, normalImpulse.Negative(), ra)
 		applyImpulse(bodyB, normalImpulse, rb)
 
@@ -429,6 +430,7 @@ func (s *CollisionSolver) solveVelocity(manifold *ContactManifold) {
 				normalImpulseMagnitude*s.DynamicFriction)
 			tangentImpulse = tangent.Scale(dynamicMagnitude)
 		}
+		manifold.Contacts[i].TangentImpulse.AddAssign(tangentImpulse)
 		applyImpulse(bodyA, tangentImpulse.Negative(), ra)
 		applyImpulse(bodyB, tangentImpulse, rb)
 	}
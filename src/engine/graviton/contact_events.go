@@ -0,0 +1,200 @@
+/******************************************************************************/
+/* contact_events.go                                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package graviton
+
+import (
+	"slices"
+
+	"kaijuengine.com/klib"
+	"kaijuengine.com/matrix"
+)
+
+type ContactEventType uint8
+
+const (
+	ContactEventBegin ContactEventType = iota
+	ContactEventPersist
+	ContactEventEnd
+)
+
+// ContactEvent describes a change in the touching state of a body pair between
+// two steps. Begin and Persist events carry the manifold generated during the
+// step, End events only carry the bodies and the last known normal. Normal
+// always points from BodyA toward BodyB.
+type ContactEvent struct {
+	Type      ContactEventType
+	BodyA     *RigidBody
+	BodyB     *RigidBody
+	Normal    matrix.Vec3
+	Contacts  [maxManifoldContacts]Contact
+	Count     int
+	IsTrigger bool
+}
+
+type contactPairKey struct {
+	a *RigidBody
+	b *RigidBody
+}
+
+type contactPairState struct {
+	key    contactPairKey
+	normal matrix.Vec3
+	step   uint64
+}
+
+// contactTracker diffs the manifolds of consecutive steps to produce begin,
+// persist and end events. Pairs are kept in a slice (with a lookup map) so end
+// events are emitted deterministically rather than in map iteration order.
+type contactTracker struct {
+	pairs   []contactPairState
+	lookup  map[contactPairKey]int
+	events  []ContactEvent
+	ended   []ContactEvent
+	stepIdx uint64
+}
+
+func (t ContactEventType) String() string {
+	switch t {
+	case ContactEventBegin:
+		return "Begin"
+	case ContactEventPersist:
+		return "Persist"
+	case ContactEventEnd:
+		return "End"
+	}
+	return "Unknown"
+}
+
+// NormalImpulse returns the total normal impulse applied by the solver across
+// all of the contact points in this event. Trigger and end events report zero.
+func (e *ContactEvent) NormalImpulse() matrix.Float {
+	total := matrix.Float(0)
+	for i := range e.Count {
+		total += e.Contacts[i].NormalImpulse
+	}
+	return total
+}
+
+// Involves returns true if the supplied body is either side of the event
+func (e *ContactEvent) Involves(body *RigidBody) bool {
+	return body != nil && (e.BodyA == body || e.BodyB == body)
+}
+
+func newContactPairKey(a, b *RigidBody) contactPairKey {
+	if a.poolLocation() > b.poolLocation() {
+		a, b = b, a
+	}
+	return contactPairKey{a, b}
+}
+
+func (t *contactTracker) record(manifolds []ContactManifold) {
+	if t.lookup == nil {
+		t.lookup = make(map[contactPairKey]int, 64)
+	}
+	t.stepIdx++
+	t.events = t.events[:0]
+	for i := range manifolds {
+		m := &manifolds[i]
+		if m.Count == 0 || m.BodyA == nil || m.BodyB == nil {
+			continue
+		}
+		key := newContactPairKey(m.BodyA, m.BodyB)
+		evt := ContactEvent{
+			Type:      ContactEventBegin,
+			BodyA:     m.BodyA,
+			BodyB:     m.BodyB,
+			Normal:    m.Normal,
+			Contacts:  m.Contacts,
+			Count:     m.Count,
+			IsTrigger: m.BodyA.Collision.IsTrigger || m.BodyB.Collision.IsTrigger,
+		}
+		if idx, ok := t.lookup[key]; ok {
+			if t.pairs[idx].step == t.stepIdx {
+				continue
+			}
+			evt.Type = ContactEventPersist
+			t.pairs[idx].normal = m.Normal
+			t.pairs[idx].step = t.stepIdx
+		} else {
+			t.lookup[key] = len(t.pairs)
+			t.pairs = append(t.pairs, contactPairState{
+				key:    key,
+				normal: m.Normal,
+				step:   t.stepIdx,
+			})
+		}
+		t.events = append(t.events, evt)
+	}
+	for i := 0; i < len(t.pairs); {
+		if t.pairs[i].step == t.stepIdx {
+			i++
+			continue
+		}
+		t.events = append(t.events, t.pairs[i].endEvent())
+		t.removeAt(i)
+	}
+}
+
+// endBody creates end events for every pair the body is currently touching and
+// stops tracking them.
+func (t *contactTracker) endBody(body *RigidBody) []ContactEvent {
+	t.ended = t.ended[:0]
+	for i := 0; i < len(t.pairs); {
+		if t.pairs[i].key.a != body && t.pairs[i].key.b != body {
+			i++
+			continue
+		}
+		t.ended = append(t.ended, t.pairs[i].endEvent())
+		t.removeAt(i)
+	}
+	return t.ended
+}
+
+// forgetBody drops every pair and pending event that references the body
+// without producing any new events, this is used when the body is released and
+// the pointer is about to become invalid.
+func (t *contactTracker) forgetBody(body *RigidBody) {
+	for i := 0; i < len(t.pairs); {
+		if t.pairs[i].key.a == body || t.pairs[i].key.b == body {
+			t.removeAt(i)
+		} else {
+			i++
+		}
+	}
+	t.events = slices.DeleteFunc(t.events, func(e ContactEvent) bool {
+		return e.Involves(body)
+	})
+}
+
+func (t *contactTracker) removeAt(idx int) {
+	delete(t.lookup, t.pairs[idx].key)
+	last := len(t.pairs) - 1
+	if idx != last {
+		t.pairs[idx] = t.pairs[last]
+		t.lookup[t.pairs[idx].key] = idx
+	}
+	t.pairs = t.pairs[:last]
+}
+
+func (t *contactTracker) reset() {
+	t.pairs = klib.WipeSlice(t.pairs)
+	t.events = klib.WipeSlice(t.events)
+	t.ended = klib.WipeSlice(t.ended)
+	for k := range t.lookup {
+		delete(t.lookup, k)
+	}
+}
+
+func (p *contactPairState) endEvent() ContactEvent {
+	return ContactEvent{
+		Type:      ContactEventEnd,
+		BodyA:     p.key.a,
+		BodyB:     p.key.b,
+		Normal:    p.normal,
+		IsTrigger: p.key.a.Collision.IsTrigger || p.key.b.Collision.IsTrigger,
+	}
+}
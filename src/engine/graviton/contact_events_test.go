@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* contact_events_test.go                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package graviton
+
+import (
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+func TestSystemContactEventsBeginPersistEnd(t *testing.T) {
+	system := System{}
+	system.Initialize()
+	system.SetGravity(matrix.Vec3Zero())
+	dynamic := addSystemSphere(&system, matrix.Vec3Zero(), RigidBodyTypeDynamic)
+	addSystemSphere(&system, matrix.Vec3{1.5, 0, 0}, RigidBodyTypeStatic)
+	workGroup, threads, cleanup := testStepWorkers(t)
+	defer cleanup()
+	system.Step(workGroup, threads, 0)
+	evts := system.ContactEvents()
+	if len(evts) != 1 || evts[0].Type != ContactEventBegin {
+		t.Fatalf("expected a single begin event, got %v", evts)
+	}
+	if evts[0].Count == 0 || !evts[0].Involves(dynamic) {
+		t.Fatal("expected begin event to carry the manifold for the dynamic body")
+	}
+	system.Step(workGroup, threads, 0)
+	evts = system.ContactEvents()
+	if len(evts) != 1 || evts[0].Type != ContactEventPersist {
+		t.Fatalf("expected a single persist event, got %v", evts)
+	}
+	dynamic.Transform.SetPosition(matrix.Vec3{-10, 0, 0})
+	system.Step(workGroup, threads, 0)
+	evts = system.ContactEvents()
+	if len(evts) != 1 || evts[0].Type != ContactEventEnd {
+		t.Fatalf("expected a single end event, got %v", evts)
+	}
+	system.Step(workGroup, threads, 0)
+	if len(system.ContactEvents()) != 0 {
+		t.Fatalf("expected no events once separated, got %d", len(system.ContactEvents()))
+	}
+}
+
+func TestSystemContactEventsReportImpulse(t *testing.T) {
+	system := System{}
+	system.Initialize()
+	system.SetGravity(matrix.Vec3Zero())
+	dynamic := addSystemSphere(&system, matrix.Vec3Zero(), RigidBodyTypeDynamic)
+	dynamic.MotionState.LinearVelocity = matrix.Vec3{5, 0, 0}
+	addSystemSphere(&system, matrix.Vec3{1.9, 0, 0}, RigidBodyTypeStatic)
+	workGroup, threads, cleanup := testStepWorkers(t)
+	defer cleanup()
+	system.Step(workGroup, threads, 1.0/60.0)
+	evts := system.ContactEvents()
+	if len(evts) != 1 {
+		t.Fatalf("expected a single contact event, got %d", len(evts))
+	}
+	if evts[0].NormalImpulse() <= 0 {
+		t.Fatalf("expected a positive normal impulse, got %f", evts[0].NormalImpulse())
+	}
+}
+
+func TestSystemTriggerContactEvents(t *testing.T) {
+	system := System{}
+	system.Initialize()
+	system.SetGravity(matrix.Vec3Zero())
+	dynamic := addSystemSphere(&system, matrix.Vec3Zero(), RigidBodyTypeDynamic)
+	dynamic.MotionState.LinearVelocity = matrix.Vec3{5, 0, 0}
+	trigger := addSystemSphere(&system, matrix.Vec3{1.5, 0, 0}, RigidBodyTypeStatic)
+	trigger.Collision.IsTrigger = true
+	workGroup, threads, cleanup := testStepWorkers(t)
+	defer cleanup()
+	system.Step(workGroup, threads, 1.0/60.0)
+	evts := system.ContactEvents()
+	if len(evts) != 1 || !evts[0].IsTrigger || evts[0].Type != ContactEventBegin {
+		t.Fatalf("expected a trigger begin event, got %v", evts)
+	}
+	if evts[0].NormalImpulse() != 0 {
+		t.Fatalf("expected triggers to not be resolved, got impulse %f", evts[0].NormalImpulse())
+	}
+	if dynamic.MotionState.LinearVelocity.X() != 5 {
+		t.Fatalf("expected trigger to not change velocity, got %v", dynamic.MotionState.LinearVelocity)
+	}
+}
+
+func TestSystemEndBodyContacts(t *testing.T) {
+	system := System{}
+	system.Initialize()
+	system.SetGravity(matrix.Vec3Zero())
+	dynamic := addSystemSphere(&system, matrix.Vec3Zero(), RigidBodyTypeDynamic)
+	addSystemSphere(&system, matrix.Vec3{1.5, 0, 0}, RigidBodyTypeStatic)
+	workGroup, threads, cleanup := testStepWorkers(t)
+	defer cleanup()
+	system.Step(workGroup, threads, 0)
+	ended := system.EndBodyContacts(dynamic)
+	if len(ended) != 1 || ended[0].Type != ContactEventEnd || !ended[0].Involves(dynamic) {
+		t.Fatalf("expected an end event for the dynamic body, got %v", ended)
+	}
+	system.RemoveBody(dynamic)
+	if len(system.ContactEvents()) != 0 {
+		t.Fatalf("expected removed body events to be discarded, got %d", len(system.ContactEvents()))
+	}
+	system.Step(workGroup, threads, 0)
+	if len(system.ContactEvents()) != 0 {
+		t.Fatalf("expected no events after removing the body, got %d", len(system.ContactEvents()))
+	}
+}
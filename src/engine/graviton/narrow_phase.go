@@ -21,15 +21,18 @@ const (
 )
 
 // Contact contains one world-space contact point generated by the narrow phase.
-// Normal always points from BodyA toward BodyB.
+// Normal always points from BodyA toward BodyB. The impulse fields are filled
+// in by the solver and accumulate across velocity iterations of a single step.
 type Contact struct {
-	BodyA       *RigidBody
-	BodyB       *RigidBody
-	Point       matrix.Vec3
-	PointA      matrix.Vec3
-	PointB      matrix.Vec3
-	Normal      matrix.Vec3
-	Penetration matrix.Float
+	BodyA          *RigidBody
+	BodyB          *RigidBody
+	Point          matrix.Vec3
+	PointA         matrix.Vec3
+	PointB         matrix.Vec3
+	Normal         matrix.Vec3
+	Penetration    matrix.Float
+	NormalImpulse  matrix.Float
+	TangentImpulse matrix.Vec3
 }
 
 // ContactManifold groups contacts for a colliding body pair.
@@ -30,6 +30,7 @@ type System struct {
 	broadPhase                   SweepPrune
 	narrowPhase                  NarrowPhase
 	solver                       CollisionSolver
+	contacts                     contactTracker
 	constraintScratch            []*Constraint
 }
 
@@ -363,6 +364,7 @@ func (s *System) RemoveBody(body *RigidBody) {
 			constraint.detachBody(body)
 		}
 	})
+	s.contacts.forgetBody(body)
 	poolId := body.poolId
 	id := body.id
 	body.Active = false
@@ -382,6 +384,7 @@ func (s *System) Clear() {
 	s.broadPhase.Rebuild(&s.bodies)
 	s.narrowPhase.Reset()
 	s.solver.Reset()
+	s.contacts.reset()
 	s.constraintScratch = s.constraintScratch[:0]
 }
 
@@ -417,6 +420,7 @@ func (s *System) Step(workGroup *concurrent.WorkGroup, threads *concurrent.Threa
 	// Contacts and constraints are solved as one island problem so linked
 	// bodies share the same velocity and position iteration stream.
 	s.solver.SolveWithConstraints(manifolds, constraints, threads)
+	s.contacts.record(manifolds)
 	s.updateSleepState(dt)
 }
 
@@ -426,6 +430,25 @@ func (s *System) Contacts() []ContactManifold {
 	return s.narrowPhase.Manifolds()
 }
 
+// ContactEvents returns the begin, persist and end events produced by the most
+// recent Step. The returned slice is owned by the System and is reused on the
+// next Step.
+func (s *System) ContactEvents() []ContactEvent {
+	return s.contacts.events
+}
+
+// EndBodyContacts stops tracking every body pair the given body is currently
+// touching and returns an end event for each of them. This is useful before
+// removing a body so listeners are told the contact ended while the body is
+// still valid; RemoveBody on its own silently discards the body's contacts.
+// The returned slice is owned by the System and is reused on the next call.
+func (s *System) EndBodyContacts(body *RigidBody) []ContactEvent {
+	if body == nil {
+		return nil
+	}
+	return s.contacts.endBody(body)
+}
+
 // Constraints returns the constraints currently stored in the System. The
 // returned slice is owned by the System and is reused on the next constraints
 // query or Step.
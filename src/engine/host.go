@@ -92,6 +92,8 @@ type Host struct {
 	Cameras           hostCameras
 	collisionManager  collision_system.Manager
 	audio             *audio.Audio
+	audioListener     matrix.Vec3
+	hasAudioListener  bool
 	shaderCache       rendering.ShaderCache
 	textureCache      rendering.TextureCache
 	meshCache         rendering.MeshCache
@@ -101,6 +103,9 @@ type Host struct {
 	RenderTargets     rendering.RenderTargetManager
 	RenderViews       rendering.RenderViewManager
 	Localization      localization.Localization
+	// Strings are the localized strings of the game, the language is the
+	// -language launch parameter or the user's language when it isn't set
+	Strings           *localization.Catalog
 	frame             FrameId
 	frameTime         float64
 	Closing           bool
@@ -118,6 +123,9 @@ type Host struct {
 	swapChainClear    matrix.Color
 	hasSwapChainClear bool
 	renderThread      *RenderThread
+	gpuBackend        rendering.GPUBackend
+	nullDevice        *rendering.GPUDevice
+	software          softwareRender
 }
 
 // NewHost creates a new host with the given name and log stream. The log stream
@@ -135,6 +143,7 @@ func NewHost(name string, logStream *logging.LogStream, assetDb assets.Database)
 		frameTime:     0,
 		Closing:       false,
 		assetDatabase: assetDb,
+		gpuBackend:    configuredGPUBackend(),
 		Drawings:      rendering.NewDrawings(),
 		RenderTargets: rendering.NewRenderTargetManager(),
 		RenderViews: rendering.NewRenderViewManager(rendering.RenderViewOptions{
@@ -144,6 +153,7 @@ func NewHost(name string, logStream *logging.LogStream, assetDb assets.Database)
 			Clear:     true,
 		}),
 		Localization: localization.Select(),
+		Strings:      localization.NewCatalog(LaunchParams.Language),
 		entitiesById: make(map[EntityId]*Entity),
 		CloseSignal:  make(chan struct{}, 1),
 		LogStream:    logStream,
@@ -179,7 +189,8 @@ func (host *Host) SetGame(game any) { host.game = game }
 
 // Initializes the various systems and caches that are mediated through the
 // host. This includes the window, the shader cache, the texture cache, the mesh
-// cache, and the font cache, and the camera systems.
+// cache, and the font cache, and the camera systems. The string tables of the
+// game are loaded into [Host.Strings].
 func (host *Host) Initialize(width, height, x, y int, platformState any) error {
 	if width <= 0 {
 		width = DefaultWindowWidth
@@ -187,12 +198,16 @@ func (host *Host) Initialize(width, height, x, y int, platformState any) error {
 	if height <= 0 {
 		height = DefaultWindowHeight
 	}
-	win, err := windowing.New(host.name, width, height,
-		x, y, host.assetDatabase, platformState)
-	if err != nil {
-		return err
+	if host.gpuBackend == rendering.GPUBackendNull {
+		host.Window = windowing.NewHeadless(host.name, width, height)
+	} else {
+		win, err := windowing.New(host.name, width, height,
+			x, y, host.assetDatabase, platformState)
+		if err != nil {
+			return err
+		}
+		host.Window = win
 	}
-	host.Window = win
 	host.entitiesById = make(map[EntityId]*Entity)
 	host.threads.Start()
 	host.updateThreads.Start()
@@ -201,6 +216,9 @@ func (host *Host) Initialize(width, height, x, y int, platformState any) error {
 	host.Cameras.UI.Camera.ViewportChanged(float32(width), float32(height))
 	w := weak.Make(host)
 	host.Window.OnResize.Add(func() { w.Value().resized() })
+	if err := host.Strings.LoadListed(host.assetDatabase); err != nil {
+		slog.Error("failed to load the string tables", "error", err)
+	}
 	slog.Info("Host.Initialize", "window count", host.Window.MonitorCount())
 	return nil
 }
@@ -209,11 +227,15 @@ func (host *Host) StartPhysics() {
 	if !build.Editor {
 		if !host.physics.IsActive() {
 			host.physics.Start()
+			host.physics.OnContact.Add(host.invokePluginContact)
 		}
 	}
 }
 
 func (host *Host) InitializeRenderer() error {
+	if host.gpuBackend == rendering.GPUBackendNull {
+		return host.initializeNullRenderer()
+	}
 	if err := host.Window.InitializeGPU(host.assetDatabase); err != nil {
 		slog.Error("failed to initialize the GPU", "error", err)
 		return err
@@ -227,6 +249,7 @@ func (host *Host) InitializeRenderer() error {
 	host.materialCache = rendering.NewMaterialCache(gpuDevice, host.assetDatabase)
 	if host.renderThread != nil {
 		host.materialCache.SetDeviceCall(host.RunOnRenderThread)
+		host.fontCache.SetDeviceCall(host.RunOnRenderThread)
 	}
 	w, h := int32(host.Window.Width()), int32(host.Window.Height())
 	if err := host.Window.GpuInstance.SetupCaches(host, w, h); err != nil {
@@ -258,7 +281,43 @@ func (host *Host) InitializeRenderer() error {
 
 func (host *Host) InitializeAudio() (err error) {
 	host.audio, err = audio.New()
-	return err
+	if err != nil {
+		return err
+	}
+	if host.assetDatabase.Exists(audio.MixerAssetKey) {
+		if err := host.audio.LoadMixer(host.assetDatabase, audio.MixerAssetKey); err != nil {
+			slog.Error("failed to load the audio mixer, using the default buses", "error", err)
+		}
+	}
+	return nil
+}
+
+// updateAudio moves the 3D audio listener to the primary camera and then
+// updates the mixer. The mixer is updated even when there is no camera to
+// listen from.
+func (host *Host) updateAudio(deltaTime float64) {
+	if host.audio == nil {
+		return
+	}
+	defer tracing.NewRegion("Host.updateAudio").End()
+	if host.Cameras.Primary.Camera != nil {
+		host.updateAudioListener(deltaTime)
+	}
+	host.audio.Update(deltaTime)
+}
+
+// updateAudioListener moves the 3D audio listener to the primary camera, the
+// velocity of the camera is used for doppler
+func (host *Host) updateAudioListener(deltaTime float64) {
+	cam := host.PrimaryCamera()
+	pos := cam.Position()
+	velocity := matrix.Vec3Zero()
+	if host.hasAudioListener && deltaTime > 0 {
+		velocity = pos.Subtract(host.audioListener).Scale(matrix.Float(1 / deltaTime))
+	}
+	host.audioListener = pos
+	host.hasAudioListener = true
+	host.audio.SetListener(pos, cam.Forward(), cam.Up(), velocity)
 }
 
 // WorkGroup returns the work group for this instance of host
@@ -437,6 +496,7 @@ func (host *Host) Update(deltaTime float64) {
 	}
 	host.LateUpdater.Update(deltaTime)
 	host.collisionManager.Update(deltaTime)
+	host.updateAudio(deltaTime)
 	if host.Window.IsClosed() || host.Window.IsCrashed() {
 		host.Closing = true
 	}
@@ -38,7 +38,8 @@ func (c *Container) Run(width, height, x, y int, platformState any) error {
 		c.Host.Close()
 		return err
 	}
-	useRenderThread := engine.LaunchParams.RenderThread && runtime.GOOS == "windows"
+	useRenderThread := engine.LaunchParams.RenderThread && runtime.GOOS == "windows" &&
+		!c.Host.IsHeadless()
 	if useRenderThread {
 		if err := c.Host.StartRenderThread(); err != nil {
 			slog.Error("Failed to initialize the render thread", "error", err)
@@ -19,6 +19,7 @@ type LaunchParameters struct {
 	Generate        string
 	NewProject      string
 	UpgradeProject  string
+	Project         string
 	ProjectName     string
 	ProjectTemplate string
 	IntegrationTest string
@@ -27,12 +28,16 @@ type LaunchParameters struct {
 	RecordPGO       bool
 	AutoTest        bool
 	RenderThread    bool
+	Headless        bool
+	GPUBackend      string
+	Language        string
 }
 
 func LoadLaunchParams() {
 	flag.StringVar(&LaunchParams.Generate, "generate", "", "The generator to run: 'pluginapi'")
 	flag.StringVar(&LaunchParams.NewProject, "newproject", "", "Create a new blank project at the specified path")
 	flag.StringVar(&LaunchParams.UpgradeProject, "upgradeproject", "", "Upgrade the engine code at the specified path")
+	flag.StringVar(&LaunchParams.Project, "project", "", "Open the project at the specified path without the editor UI and run the pipeline steps that follow (import, validate, package, build, test)")
 	flag.StringVar(&LaunchParams.ProjectName, "projectname", "", "Name of the project to create (used with -newproject)")
 	flag.StringVar(&LaunchParams.ProjectTemplate, "projecttemplate", "", "Path to a template zip to use (used with -newproject)")
 	if build.Debug {
@@ -43,5 +48,8 @@ func LoadLaunchParams() {
 	}
 	flag.BoolVar(&LaunchParams.RecordPGO, "record_pgo", false, "If supplied, a default.pgo will be captured for this run")
 	flag.BoolVar(&LaunchParams.RenderThread, "renderthread", runtime.GOOS == "windows", "Run GPU rendering on a dedicated render thread when supported")
+	flag.BoolVar(&LaunchParams.Headless, "headless", false, "Run without a window or GPU, resources and draws are tracked but not rendered")
+	flag.StringVar(&LaunchParams.GPUBackend, "gpu", "", "The GPU backend to render with: 'vulkan' or 'null' (the same as -headless)")
+	flag.StringVar(&LaunchParams.Language, "language", "", "The language of the localized strings, such as 'fr-CA', instead of the user's language")
 	flag.Parse()
 }
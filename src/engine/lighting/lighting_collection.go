@@ -85,6 +85,14 @@ func (c *LightCollection) ConsumeFrameDirty() bool {
 	return dirty
 }
 
+// AppendAll appends every light in the collection to the supplied slice
+func (c *LightCollection) AppendAll(lights []rendering.Light) []rendering.Light {
+	c.pools.Each(func(elm *LightEntry) {
+		lights = append(lights, elm.Light)
+	})
+	return lights
+}
+
 func (c *LightCollection) UpdateCache(point matrix.Vec3) []rendering.Light {
 	defer tracing.NewRegion("Collection[T].UpdateCache").End()
 	if len(c.Cache) > 0 {
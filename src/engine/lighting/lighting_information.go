@@ -7,12 +7,21 @@
 package lighting
 
 import (
-	"kaijuengine.com/matrix"
+	"kaijuengine.com/engine/cameras"
+	"kaijuengine.com/platform/profiler/tracing"
 	"kaijuengine.com/rendering"
 )
 
+// lightClusterFrames is the number of cluster buffers that are rotated between
+// frames. One is being captured, one can be queued for the render thread and
+// one can be in use by the render thread.
+const lightClusterFrames = 3
+
 type LightingInformation struct {
-	Lights LightCollection
+	Lights       LightCollection
+	clusters     [lightClusterFrames]rendering.LightClusters
+	all          []rendering.Light
+	clusterFrame int
 }
 
 func NewLightingInformation(lightCacheCapacity int) LightingInformation {
@@ -23,6 +32,18 @@ func NewLightingInformation(lightCacheCapacity int) LightingInformation {
 	}
 }
 
-func (l *LightingInformation) Update(point matrix.Vec3) {
-	l.Lights.UpdateCache(point)
+// Update assigns every light in the collection to the clusters of the
+// camera's view frustum and returns the clusters for the frame. The returned
+// clusters remain untouched for the next few frames so they can be read by
+// the render thread. The second return is true if the set of lights selected
+// for the GPU differs from the previous frame, in which case draw instances
+// that hold light indexes need to select their lights again.
+func (l *LightingInformation) Update(camera cameras.Camera) (*rendering.LightClusters, bool) {
+	defer tracing.NewRegion("LightingInformation.Update").End()
+	l.all = l.Lights.AppendAll(l.all[:0])
+	last := &l.clusters[l.clusterFrame]
+	l.clusterFrame = (l.clusterFrame + 1) % lightClusterFrames
+	clusters := &l.clusters[l.clusterFrame]
+	clusters.Build(camera, l.all)
+	return clusters, !clusters.SameSelection(last)
 }
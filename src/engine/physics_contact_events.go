@@ -0,0 +1,209 @@
+/******************************************************************************/
+/* physics_contact_events.go                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package engine
+
+import (
+	"kaijuengine.com/engine/graviton"
+	"kaijuengine.com/engine/systems/events"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// PhysicsContact is the entity level view of a [graviton.ContactEvent]. It is
+// always described from the point of view of Entity, so Normal points from
+// Entity toward Other. Other may be nil when the other body was not added
+// through [StagePhysics]. The Contacts slice is only valid for the duration of
+// the callback it was delivered to.
+type PhysicsContact struct {
+	Type      graviton.ContactEventType
+	Entity    *Entity
+	Other     *Entity
+	Body      *graviton.RigidBody
+	OtherBody *graviton.RigidBody
+	Normal    matrix.Vec3
+	Contacts  []graviton.Contact
+	Impulse   matrix.Float
+	IsTrigger bool
+}
+
+// PhysicsContactEvents holds the contact and trigger notifications for a single
+// entity. OnContact is executed for every event before the more specific
+// event for the contact type is executed.
+type PhysicsContactEvents struct {
+	OnContact          events.EventWithArg[PhysicsContact]
+	OnCollisionBegin   events.EventWithArg[PhysicsContact]
+	OnCollisionPersist events.EventWithArg[PhysicsContact]
+	OnCollisionEnd     events.EventWithArg[PhysicsContact]
+	OnTriggerBegin     events.EventWithArg[PhysicsContact]
+	OnTriggerPersist   events.EventWithArg[PhysicsContact]
+	OnTriggerEnd       events.EventWithArg[PhysicsContact]
+}
+
+// PhysicsContactReceiver can be implemented by [EntityData] to have every
+// contact and trigger event of the entity it was bound to routed to it.
+type PhysicsContactReceiver interface {
+	OnPhysicsContact(host *Host, contact PhysicsContact)
+}
+
+const (
+	luaCollisionBegin   = "on_collision_begin"
+	luaCollisionPersist = "on_collision_persist"
+	luaCollisionEnd     = "on_collision_end"
+	luaTriggerBegin     = "on_trigger_begin"
+	luaTriggerPersist   = "on_trigger_persist"
+	luaTriggerEnd       = "on_trigger_end"
+)
+
+// BindPhysicsContactReceiver subscribes the data to the contact events of the
+// entity if it implements [PhysicsContactReceiver]
+func BindPhysicsContactReceiver(entity *Entity, host *Host, data EntityData) {
+	receiver, ok := data.(PhysicsContactReceiver)
+	if !ok || entity == nil || host == nil {
+		return
+	}
+	host.Physics().ContactEvents(entity).OnContact.Add(func(c PhysicsContact) {
+		receiver.OnPhysicsContact(host, c)
+	})
+}
+
+// ContactEvents returns the contact events for the given entity, creating them
+// if they don't yet exist. It is safe to subscribe before the entity's body has
+// been added to the physics system. The events are released when the entity is
+// destroyed.
+func (p *StagePhysics) ContactEvents(entity *Entity) *PhysicsContactEvents {
+	if entity == nil {
+		return nil
+	}
+	if p.contactEvents == nil {
+		p.contactEvents = make(map[*Entity]*PhysicsContactEvents)
+	}
+	if evts, ok := p.contactEvents[entity]; ok {
+		return evts
+	}
+	evts := &PhysicsContactEvents{}
+	p.contactEvents[entity] = evts
+	entity.OnDestroy.Add(func() { delete(p.contactEvents, entity) })
+	return evts
+}
+
+func (e *PhysicsContactEvents) execute(c PhysicsContact) {
+	e.OnContact.Execute(c)
+	var evt *events.EventWithArg[PhysicsContact]
+	switch c.Type {
+	case graviton.ContactEventBegin:
+		evt = &e.OnCollisionBegin
+		if c.IsTrigger {
+			evt = &e.OnTriggerBegin
+		}
+	case graviton.ContactEventPersist:
+		evt = &e.OnCollisionPersist
+		if c.IsTrigger {
+			evt = &e.OnTriggerPersist
+		}
+	case graviton.ContactEventEnd:
+		evt = &e.OnCollisionEnd
+		if c.IsTrigger {
+			evt = &e.OnTriggerEnd
+		}
+	}
+	if evt != nil {
+		evt.Execute(c)
+	}
+}
+
+func (c PhysicsContact) flipped() PhysicsContact {
+	c.Entity, c.Other = c.Other, c.Entity
+	c.Body, c.OtherBody = c.OtherBody, c.Body
+	c.Normal = c.Normal.Negative()
+	return c
+}
+
+func (c PhysicsContact) luaFunctionName() string {
+	switch c.Type {
+	case graviton.ContactEventBegin:
+		if c.IsTrigger {
+			return luaTriggerBegin
+		}
+		return luaCollisionBegin
+	case graviton.ContactEventPersist:
+		if c.IsTrigger {
+			return luaTriggerPersist
+		}
+		return luaCollisionPersist
+	default:
+		if c.IsTrigger {
+			return luaTriggerEnd
+		}
+		return luaCollisionEnd
+	}
+}
+
+// collectContactEvents copies the events of the most recent world step so they
+// can be delivered once all of the sub steps for the frame have completed.
+func (p *StagePhysics) collectContactEvents() {
+	p.pendingContacts = append(p.pendingContacts, p.world.ContactEvents()...)
+}
+
+// dispatchContactEvents delivers the pending contact events to the global and
+// per-entity listeners. This is called on the main thread from Update after
+// the bodies have been synchronized back to their entities.
+func (p *StagePhysics) dispatchContactEvents() {
+	defer tracing.NewRegion("StagePhysics.dispatchContactEvents").End()
+	for i := range p.pendingContacts {
+		p.dispatchContactEvent(&p.pendingContacts[i])
+	}
+	p.pendingContacts = p.pendingContacts[:0]
+}
+
+func (p *StagePhysics) dispatchContactEvent(evt *graviton.ContactEvent) {
+	c := PhysicsContact{
+		Type:      evt.Type,
+		Entity:    p.bodyEntities[evt.BodyA],
+		Other:     p.bodyEntities[evt.BodyB],
+		Body:      evt.BodyA,
+		OtherBody: evt.BodyB,
+		Normal:    evt.Normal,
+		Contacts:  evt.Contacts[:evt.Count],
+		Impulse:   evt.NormalImpulse(),
+		IsTrigger: evt.IsTrigger,
+	}
+	if c.Entity == nil && c.Other == nil {
+		return
+	}
+	p.OnContact.Execute(c)
+	if c.Entity != nil {
+		if evts, ok := p.contactEvents[c.Entity]; ok {
+			evts.execute(c)
+		}
+	}
+	if c.Other != nil {
+		if evts, ok := p.contactEvents[c.Other]; ok {
+			evts.execute(c.flipped())
+		}
+	}
+}
+
+func (host *Host) invokePluginContact(c PhysicsContact) {
+	if len(host.plugins) == 0 {
+		return
+	}
+	var entityId, otherId EntityId
+	if c.Entity != nil {
+		entityId = c.Entity.Id()
+	}
+	if c.Other != nil {
+		otherId = c.Other.Id()
+	}
+	point := matrix.Vec3Zero()
+	if len(c.Contacts) > 0 {
+		point = c.Contacts[0].Point
+	}
+	name := c.luaFunctionName()
+	for _, vm := range host.plugins {
+		vm.InvokeGlobalFunctionArgs(name, entityId, otherId, point, c.Normal, c.Impulse)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"log/slog"
 
 	"kaijuengine.com/engine/graviton"
+	"kaijuengine.com/engine/systems/events"
 	"kaijuengine.com/klib"
 	"kaijuengine.com/matrix"
 	"kaijuengine.com/platform/concurrent"
@@ -29,9 +30,15 @@ type stagePhysicsConstraintEntry struct {
 }
 
 type StagePhysics struct {
+	// OnContact is executed for every contact event of the frame, from the
+	// point of view of the first body in the pair
+	OnContact          events.EventWithArg[PhysicsContact]
 	world              graviton.System
 	entities           []StagePhysicsEntry
 	constraints        []stagePhysicsConstraintEntry
+	bodyEntities       map[*graviton.RigidBody]*Entity
+	contactEvents      map[*Entity]*PhysicsContactEvents
+	pendingContacts    []graviton.ContactEvent
 	accumulatedTime    float64
 	fixedTimeStep      float64
 	maxAccumulatedTime float64
@@ -168,6 +175,10 @@ func (p *StagePhysics) Destroy() {
 	}
 	p.entities = klib.WipeSlice(p.entities)
 	p.constraints = klib.WipeSlice(p.constraints)
+	p.pendingContacts = klib.WipeSlice(p.pendingContacts)
+	clear(p.bodyEntities)
+	clear(p.contactEvents)
+	p.OnContact.Clear()
 	p.accumulatedTime = 0
 	p.active = false
 }
@@ -194,7 +205,15 @@ func (p *StagePhysics) AddEntity(entity *Entity, body *graviton.RigidBody) {
 		Entity: entity,
 		Body:   stageBody,
 	})
+	if p.bodyEntities == nil {
+		p.bodyEntities = make(map[*graviton.RigidBody]*Entity)
+	}
+	p.bodyEntities[stageBody] = entity
 	entity.OnDestroy.Add(func() {
+		if _, ok := p.bodyEntities[stageBody]; ok {
+			p.endBodyContacts(stageBody)
+			delete(p.bodyEntities, stageBody)
+		}
 		cIdx := -1
 		for i := range p.entities {
 			if p.entities[i].Entity == entity {
@@ -382,6 +401,7 @@ func (p *StagePhysics) Update(workGroup *concurrent.WorkGroup, threads *concurre
 	}
 	if deltaTime <= 0 {
 		p.world.Step(workGroup, threads, 0)
+		p.collectContactEvents()
 	} else {
 		p.accumulatedTime += deltaTime
 		if p.accumulatedTime > p.maxAccumulatedTime {
@@ -390,6 +410,7 @@ func (p *StagePhysics) Update(workGroup *concurrent.WorkGroup, threads *concurre
 		steps := 0
 		for p.accumulatedTime >= p.fixedTimeStep && steps < p.maxSubSteps {
 			p.world.Step(workGroup, threads, p.fixedTimeStep)
+			p.collectContactEvents()
 			p.accumulatedTime -= p.fixedTimeStep
 			steps++
 		}
@@ -399,6 +420,15 @@ func (p *StagePhysics) Update(workGroup *concurrent.WorkGroup, threads *concurre
 			p.entities[i].syncBodyToEntity()
 		}
 	}
+	p.dispatchContactEvents()
+}
+
+// endBodyContacts immediately delivers end events for everything the body is
+// touching, this is used when the body's entity is being destroyed so that the
+// listeners are notified while both entities can still be resolved.
+func (p *StagePhysics) endBodyContacts(body *graviton.RigidBody) {
+	p.pendingContacts = append(p.pendingContacts, p.world.EndBodyContacts(body)...)
+	p.dispatchContactEvents()
 }
 
 func (p *StagePhysics) constraintBodies(entityA, entityB *Entity) (*graviton.RigidBody, *graviton.RigidBody, bool) {
@@ -524,6 +524,82 @@ func addTestStageBody(workGroup *concurrent.WorkGroup, physics *StagePhysics, po
 	return entity
 }
 
+func TestStagePhysicsDispatchesEntityContactEvents(t *testing.T) {
+	workGroup, threads, cleanup := testStagePhysicsWorkers(t)
+	defer cleanup()
+
+	physics := StagePhysics{}
+	physics.Start()
+	defer physics.Destroy()
+	physics.World().SetGravity(matrix.Vec3Zero())
+
+	falling := NewEntity(workGroup)
+	ground := NewEntity(workGroup)
+	ground.Transform.SetPosition(matrix.NewVec3(1.5, 0, 0))
+	var begins, persists, ends []PhysicsContact
+	groundEvents := physics.ContactEvents(ground)
+	groundEvents.OnCollisionBegin.Add(func(c PhysicsContact) { begins = append(begins, c) })
+	groundEvents.OnCollisionPersist.Add(func(c PhysicsContact) { persists = append(persists, c) })
+	groundEvents.OnCollisionEnd.Add(func(c PhysicsContact) { ends = append(ends, c) })
+	globalCount := 0
+	physics.OnContact.Add(func(PhysicsContact) { globalCount++ })
+
+	physics.AddEntity(falling, newTestStageBody(falling, graviton.RigidBodyTypeDynamic))
+	physics.AddEntity(ground, newTestStageBody(ground, graviton.RigidBodyTypeStatic))
+
+	physics.Update(workGroup, threads, 0)
+	if len(begins) != 1 {
+		t.Fatalf("expected one begin event for the ground, got %d", len(begins))
+	}
+	if begins[0].Entity != ground || begins[0].Other != falling {
+		t.Fatal("expected the begin event to be from the ground's point of view")
+	}
+	if begins[0].Normal.X() >= 0 {
+		t.Fatalf("expected normal to point from the ground toward the other entity, got %v", begins[0].Normal)
+	}
+	physics.Update(workGroup, threads, 0)
+	if len(persists) != 1 {
+		t.Fatalf("expected one persist event, got %d", len(persists))
+	}
+	falling.OnDestroy.Execute()
+	if len(ends) != 1 || ends[0].Other != falling {
+		t.Fatalf("expected destroying the other entity to end the contact, got %d end events", len(ends))
+	}
+	if globalCount != 3 {
+		t.Fatalf("expected the global contact event to see 3 events, got %d", globalCount)
+	}
+}
+
+func TestStagePhysicsDispatchesTriggerEvents(t *testing.T) {
+	workGroup, threads, cleanup := testStagePhysicsWorkers(t)
+	defer cleanup()
+
+	physics := StagePhysics{}
+	physics.Start()
+	defer physics.Destroy()
+	physics.World().SetGravity(matrix.Vec3Zero())
+
+	visitor := NewEntity(workGroup)
+	zone := NewEntity(workGroup)
+	zone.Transform.SetPosition(matrix.NewVec3(1.5, 0, 0))
+	zoneBody := newTestStageBody(zone, graviton.RigidBodyTypeStatic)
+	zoneBody.Collision.IsTrigger = true
+	collisions, triggers := 0, 0
+	visitorEvents := physics.ContactEvents(visitor)
+	visitorEvents.OnCollisionBegin.Add(func(PhysicsContact) { collisions++ })
+	visitorEvents.OnTriggerBegin.Add(func(c PhysicsContact) {
+		if c.Other == zone && c.IsTrigger {
+			triggers++
+		}
+	})
+	physics.AddEntity(visitor, newTestStageBody(visitor, graviton.RigidBodyTypeDynamic))
+	physics.AddEntity(zone, zoneBody)
+	physics.Update(workGroup, threads, 0)
+	if triggers != 1 || collisions != 0 {
+		t.Fatalf("expected a single trigger begin and no collisions, got %d triggers and %d collisions", triggers, collisions)
+	}
+}
+
 func newTestStageBody(entity *Entity, bodyType graviton.RigidBodyType) *graviton.RigidBody {
 	body := &graviton.RigidBody{}
 	body.Transform.SetupRawTransform()
@@ -70,16 +70,17 @@ func (host *Host) captureRenderFrame() RenderFrame {
 		Width:         width,
 		Height:        height,
 	}
-	host.lighting.Update(primaryCamera.Position())
-	frame.Lights = host.captureRenderLights()
+	frame.Lights = host.captureRenderLights(primaryCamera)
 	frame.Views = host.captureRenderViews(primaryCamera)
 	return frame
 }
 
-func (host *Host) captureRenderLights() rendering.LightsForRender {
+func (host *Host) captureRenderLights(camera cameras.Camera) rendering.LightsForRender {
+	clusters, selectionChanged := host.lighting.Update(camera)
 	lights := rendering.LightsForRender{
-		Lights:     append([]rendering.Light(nil), host.lighting.Lights.Cache...),
-		HasChanges: host.lighting.Lights.HasChanges(),
+		Lights:     append([]rendering.Light(nil), clusters.Lights...),
+		HasChanges: host.lighting.Lights.HasChanges() || selectionChanged,
+		Clusters:   clusters,
 	}
 	if host.lighting.Lights.ConsumeFrameDirty() {
 		lights.HasChanges = true
@@ -103,6 +104,10 @@ func (host *Host) captureRenderViews(primaryCamera cameras.Camera) []rendering.R
 
 func (host *Host) ProcessPendingRenderResources() {
 	defer tracing.NewRegion("Host.ProcessPendingRenderResources").End()
+	if host.nullDevice != nil {
+		host.processPendingNullResources()
+		return
+	}
 	if !host.hasValidRenderer() {
 		return
 	}
@@ -116,6 +121,11 @@ func (host *Host) ProcessPendingRenderResources() {
 
 func (host *Host) renderCapturedFrame(frame RenderFrame) {
 	defer tracing.NewRegion("RenderThread.RenderFrame").End()
+	if host.nullDevice != nil {
+		host.nullDevice.SubmitNullFrame(&host.Drawings, frame.Lights, frame.Views)
+		host.rasterizeNullFrame(frame)
+		return
+	}
 	if !host.hasValidRenderer() || !host.Drawings.HasDrawings() {
 		return
 	}
@@ -145,6 +155,10 @@ func (host *Host) renderCapturedFrame(frame RenderFrame) {
 
 func (host *Host) TeardownRenderer() {
 	defer tracing.NewRegion("Host.TeardownRenderer").End()
+	if host.nullDevice != nil {
+		host.teardownNullRenderer()
+		return
+	}
 	if !host.hasValidRenderer() {
 		return
 	}
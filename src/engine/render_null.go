@@ -0,0 +1,98 @@
+/******************************************************************************/
+/* render_null.go                                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package engine
+
+import (
+	"log/slog"
+
+	"kaijuengine.com/debug"
+	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering"
+)
+
+// configuredGPUBackend is the backend selected by the -headless or -gpu launch
+// parameters, or [DefaultGPUBackend] when neither were supplied
+func configuredGPUBackend() rendering.GPUBackend {
+	if LaunchParams.Headless {
+		return rendering.GPUBackendNull
+	}
+	if LaunchParams.GPUBackend != "" {
+		backend, err := rendering.ParseGPUBackend(LaunchParams.GPUBackend)
+		if err == nil {
+			return backend
+		}
+		slog.Error("invalid -gpu launch parameter, using the default backend",
+			"error", err, "backend", DefaultGPUBackend)
+	}
+	return DefaultGPUBackend
+}
+
+// GPUBackend returns the backend that this host renders with
+func (host *Host) GPUBackend() rendering.GPUBackend { return host.gpuBackend }
+
+// SetGPUBackend selects the backend this host renders with. This must be
+// called before [Host.Initialize], the window and the renderer are created
+// for the selected backend.
+func (host *Host) SetGPUBackend(backend rendering.GPUBackend) {
+	debug.Assert(host.Window == nil, "the GPU backend must be selected before the host is initialized")
+	host.gpuBackend = backend
+}
+
+// IsHeadless will return true if the host is running on the null backend, in
+// which case there is no native window and nothing is drawn
+func (host *Host) IsHeadless() bool { return host.gpuBackend == rendering.GPUBackendNull }
+
+// RenderDevice returns the device that the render caches were created with.
+// This will be nil until the renderer has been initialized.
+func (host *Host) RenderDevice() *rendering.GPUDevice {
+	if host.nullDevice != nil {
+		return host.nullDevice
+	}
+	if host.hasValidRenderer() {
+		return host.Window.GpuInstance.PrimaryDevice()
+	}
+	return nil
+}
+
+func (host *Host) initializeNullRenderer() error {
+	defer tracing.NewRegion("Host.initializeNullRenderer").End()
+	device := rendering.NewNullGPUDevice()
+	host.nullDevice = device
+	host.shaderCache = rendering.NewShaderCache(device, host.assetDatabase)
+	host.textureCache = rendering.NewTextureCache(device, host.assetDatabase)
+	host.meshCache = rendering.NewMeshCache(device, host.assetDatabase)
+	host.fontCache = rendering.NewFontCache(device, host.assetDatabase)
+	host.materialCache = rendering.NewMaterialCache(device, host.assetDatabase)
+	if err := device.SetupCaches(host); err != nil {
+		slog.Error("failed to initialize the null renderer", "error", err)
+		return err
+	}
+	if err := host.FontCache().Init(host); err != nil {
+		slog.Error("failed to initialize the font cache", "error", err)
+		return err
+	}
+	if err := rendering.SetupLightMaterials(host.MaterialCache()); err != nil {
+		slog.Error("failed to setup the light materials", "error", err)
+		return err
+	}
+	return nil
+}
+
+func (host *Host) processPendingNullResources() {
+	host.shaderCache.CreatePending()
+	host.textureCache.ProcessPending()
+	host.meshCache.ProcessPending()
+}
+
+func (host *Host) teardownNullRenderer() {
+	host.textureCache.Destroy()
+	host.meshCache.Destroy()
+	host.shaderCache.Destroy()
+	host.fontCache.Destroy()
+	host.materialCache.Destroy()
+	host.nullDevice = nil
+}
@@ -0,0 +1,188 @@
+/******************************************************************************/
+/* render_null_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package engine
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/registry/shader_data_registry"
+	"kaijuengine.com/rendering"
+)
+
+// headlessTestAssets flattens the built in renderer content the same way the
+// embedded database does so the standard materials can be loaded
+func headlessTestAssets(t *testing.T) assets.Database {
+	t.Helper()
+	files := map[string][]byte{}
+	root := filepath.FromSlash("../editor/editor_embedded_content/editor_content")
+	for _, dir := range []string{"renderer", "textures"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			files[filepath.Base(path)] = data
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return assets.NewMockDB(files)
+}
+
+func newHeadlessTestHost(t *testing.T) *Host {
+	t.Helper()
+	host := NewHost("headless", nil, headlessTestAssets(t))
+	host.SetGPUBackend(rendering.GPUBackendNull)
+	if err := host.Initialize(320, 240, 0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.InitializeRenderer(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(host.Teardown)
+	return host
+}
+
+func TestHeadlessHostRunsWithoutGPU(t *testing.T) {
+	host := newHeadlessTestHost(t)
+	if !host.IsHeadless() || !host.Window.IsHeadless() {
+		t.Fatal("host should be running headless")
+	}
+	device := host.RenderDevice()
+	if !device.IsNull() {
+		t.Fatal("headless host should render with the null device")
+	}
+	material, err := host.MaterialCache().Material(assets.MaterialDefinitionBasic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	texture, err := host.TextureCache().Texture(assets.TextureSquare, rendering.TextureFilterLinear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	material = material.CreateInstance([]*rendering.Texture{texture})
+	mesh := rendering.NewMeshQuad(host.MeshCache())
+	entity := NewEntity(host.WorkGroup())
+	sd := &rendering.ShaderDataCombine{
+		ShaderDataBase: rendering.NewShaderDataBase(),
+		Color:          matrix.ColorWhite(),
+	}
+	host.Drawings.AddDrawing(rendering.Drawing{
+		Material:   material,
+		Mesh:       mesh,
+		ShaderData: sd,
+		Transform:  &entity.Transform,
+	})
+	for range 2 {
+		host.Update(1.0 / 60.0)
+		host.Render()
+	}
+	if host.Closing {
+		t.Fatal("a headless window should never close on its own")
+	}
+	stats := device.NullStats()
+	if stats.Frames != 2 {
+		t.Fatalf("frames = %d, want 2", stats.Frames)
+	}
+	if stats.Meshes == 0 || stats.Textures == 0 || stats.Shaders == 0 {
+		t.Fatalf("resources were not tracked: %+v", stats)
+	}
+	if stats.DrawCalls != 1 || stats.Instances != 1 {
+		t.Fatalf("draws = %d calls with %d instances, want 1 with 1", stats.DrawCalls, stats.Instances)
+	}
+	sd.Destroy()
+	host.Update(1.0 / 60.0)
+	host.Render()
+	if stats = device.NullStats(); stats.Instances != 0 {
+		t.Fatalf("destroyed instance was still drawn: %+v", stats)
+	}
+}
+
+func TestHeadlessWindowResizeUpdatesCameras(t *testing.T) {
+	host := newHeadlessTestHost(t)
+	host.Window.SetSize(640, 480)
+	if host.Window.Width() != 640 || host.Window.Height() != 480 {
+		t.Fatalf("window size = %dx%d, want 640x480", host.Window.Width(), host.Window.Height())
+	}
+	if got := host.Cameras.Primary.Camera.Width(); got != 640 {
+		t.Fatalf("primary camera width = %v, want 640", got)
+	}
+	host.Window.CopyToClipboard("kaiju")
+	if host.Window.ClipboardContents() != "kaiju" {
+		t.Fatal("headless clipboard did not keep the copied text")
+	}
+}
+
+func TestHeadlessSoftwareRasterizerDrawsFrame(t *testing.T) {
+	host := newHeadlessTestHost(t)
+	host.EnableSoftwareRasterizer()
+	if host.RasterizedFrame() != nil {
+		t.Fatal("no frame should be available before rendering")
+	}
+	material, err := host.MaterialCache().Material(assets.MaterialDefinitionUnlit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entity := NewEntity(host.WorkGroup())
+	entity.Transform.SetScale(matrix.Vec3{4, 4, 1})
+	host.Cameras.Primary.Camera.SetPositionAndLookAt(matrix.Vec3{0, 0, 5}, matrix.Vec3Zero())
+	host.Drawings.AddDrawing(rendering.Drawing{
+		Material: material,
+		Mesh:     rendering.NewMeshQuad(host.MeshCache()),
+		ShaderData: &shader_data_registry.ShaderDataUnlit{
+			ShaderDataBase: rendering.NewShaderDataBase(),
+			Color:          matrix.ColorRed(),
+			UVs:            matrix.Vec4{0, 0, 1, 1},
+		},
+		Transform: &entity.Transform,
+	})
+	host.Update(1.0 / 60.0)
+	host.Render()
+	frame := host.RasterizedFrame()
+	if frame == nil {
+		t.Fatal("the software rasterizer did not produce a frame")
+	}
+	if w, h := frame.Bounds().Dx(), frame.Bounds().Dy(); w != 320 || h != 240 {
+		t.Fatalf("frame size = %dx%d, want 320x240", w, h)
+	}
+	if c := frame.RGBAAt(160, 120); c.R != 255 || c.G != 0 || c.B != 0 {
+		t.Fatalf("center pixel = %v, want red", c)
+	}
+	if c := frame.RGBAAt(0, 0); c.R != 0 {
+		t.Fatalf("corner pixel = %v, want the clear color", c)
+	}
+}
+
+func TestConfiguredGPUBackendReadsLaunchParams(t *testing.T) {
+	restore := LaunchParams
+	t.Cleanup(func() { LaunchParams = restore })
+	tests := []struct {
+		headless bool
+		gpu      string
+		want     rendering.GPUBackend
+	}{
+		{false, "", DefaultGPUBackend},
+		{false, "null", rendering.GPUBackendNull},
+		{false, "vulkan", rendering.GPUBackendVulkan},
+		{true, "vulkan", rendering.GPUBackendNull},
+		{false, "metal", DefaultGPUBackend},
+	}
+	for _, test := range tests {
+		LaunchParams.Headless = test.headless
+		LaunchParams.GPUBackend = test.gpu
+		if got := configuredGPUBackend(); got != test.want {
+			t.Errorf("headless %v, gpu %q = %v, want %v", test.headless, test.gpu, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+/******************************************************************************/
+/* render_software.go                                                         */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package engine
+
+import (
+	"image"
+	"sync"
+
+	"kaijuengine.com/debug"
+	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering"
+)
+
+type softwareRender struct {
+	rasterizer *rendering.SoftwareRasterizer
+	frame      *image.RGBA
+	mutex      sync.Mutex
+}
+
+// EnableSoftwareRasterizer will draw every frame of a headless host with a
+// [rendering.SoftwareRasterizer] so that it can be read back through
+// [Host.RasterizedFrame]. This is meant for golden image tests, it is much
+// slower than the GPU and only supports the built in shaders.
+func (host *Host) EnableSoftwareRasterizer() {
+	debug.Assert(host.IsHeadless(), "the software rasterizer requires the null GPU backend")
+	host.software.mutex.Lock()
+	defer host.software.mutex.Unlock()
+	if host.software.rasterizer == nil {
+		host.software.rasterizer = rendering.NewSoftwareRasterizer(
+			host.Window.Width(), host.Window.Height())
+	}
+}
+
+// RasterizedFrame returns the last frame drawn by the software rasterizer,
+// this will be nil if [Host.EnableSoftwareRasterizer] was not called or nothing
+// has been rendered since. Each frame is a new image, so it is safe to keep.
+func (host *Host) RasterizedFrame() *image.RGBA {
+	host.software.mutex.Lock()
+	defer host.software.mutex.Unlock()
+	return host.software.frame
+}
+
+func (host *Host) rasterizeNullFrame(frame RenderFrame) {
+	host.software.mutex.Lock()
+	defer host.software.mutex.Unlock()
+	r := host.software.rasterizer
+	if r == nil {
+		return
+	}
+	defer tracing.NewRegion("Host.rasterizeNullFrame").End()
+	if r.Width() != int(frame.Width) || r.Height() != int(frame.Height) {
+		r.Resize(int(frame.Width), int(frame.Height))
+	}
+	host.software.frame = r.Render(host.nullDevice, &host.Drawings,
+		frame.PrimaryCamera, frame.UICamera, frame.Lights, frame.Views)
+}
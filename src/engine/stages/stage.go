@@ -253,6 +253,7 @@ func (s *Stage) Load(host *engine.Host) LoadResult {
 			phase: engine.EntityDataInitPhase(data),
 			init: func() {
 				data.Init(entity, host)
+				engine.BindPhysicsContactReceiver(entity, host, data)
 			},
 		})
 	}
@@ -0,0 +1,149 @@
+/******************************************************************************/
+/* binding.go                                                                 */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package input_actions
+
+import (
+	"math"
+	"slices"
+
+	"kaijuengine.com/matrix"
+)
+
+// Interaction is how an input has to be used for a binding to trigger
+type Interaction uint8
+
+const (
+	// InteractionPress triggers for as long as the input is held
+	InteractionPress Interaction = iota
+	// InteractionHold triggers once the input has been held for the hold time
+	// of the action and stays triggered until it is let go
+	InteractionHold
+	// InteractionTap triggers for a single frame when the input is let go
+	// within the tap time of the action
+	InteractionTap
+	// InteractionDoubleTap triggers for a single frame on the second of two
+	// taps within the double tap time of the action
+	InteractionDoubleTap
+)
+
+var interactionNames = []string{"press", "hold", "tap", "double_tap"}
+
+func (i Interaction) String() string { return enumName(interactionNames, i) }
+
+func (i Interaction) MarshalText() ([]byte, error) { return []byte(i.String()), nil }
+
+func (i *Interaction) UnmarshalText(text []byte) error {
+	return enumParse(interactionNames, string(text), i, "interaction")
+}
+
+// Component is the axis of a 2D axis action that a binding drives
+type Component uint8
+
+const (
+	ComponentX Component = iota
+	ComponentY
+)
+
+// Binding maps an input to an action. The modifiers and every input of the
+// chord have to be held for the binding to trigger. A binding that needs
+// modifiers or a chord takes priority over one on the same input that
+// doesn't, so Ctrl+S doesn't also trigger an action bound to S.
+type Binding struct {
+	Input       Input       `json:"input"`
+	Modifiers   Modifier    `json:"modifiers,omitempty"`
+	Chord       []Input     `json:"chord,omitempty"`
+	Interaction Interaction `json:"interaction,omitempty"`
+	// Scale multiplies the value of the input, a key bound to an axis with a
+	// scale of -1 pushes the axis the other way. 0 is the same as 1.
+	Scale     matrix.Float `json:"scale,omitempty"`
+	Component Component    `json:"component,omitempty"`
+}
+
+func (b Binding) scale() matrix.Float {
+	if b.Scale == 0 {
+		return 1
+	}
+	return b.Scale
+}
+
+func (b Binding) isQualified() bool {
+	return b.Modifiers != 0 || len(b.Chord) > 0
+}
+
+// sameTrigger returns true if both bindings are triggered by the same inputs,
+// the order of the chord doesn't matter
+func (b Binding) sameTrigger(other Binding) bool {
+	if b.Input != other.Input || b.Modifiers != other.Modifiers || len(b.Chord) != len(other.Chord) {
+		return false
+	}
+	for _, in := range b.Chord {
+		if !slices.Contains(other.Chord, in) {
+			return false
+		}
+	}
+	return true
+}
+
+// conflictsWith returns true if both bindings would trigger from the same
+// use of the same inputs. A tap and a hold on the same key don't conflict.
+func (b Binding) conflictsWith(other Binding) bool {
+	if !b.sameTrigger(other) {
+		return false
+	}
+	return b.Interaction == other.Interaction ||
+		b.Interaction == InteractionPress || other.Interaction == InteractionPress
+}
+
+func (b Binding) Clone() Binding {
+	b.Chord = slices.Clone(b.Chord)
+	return b
+}
+
+// bindingState is the state of a binding between frames that is needed for
+// the interactions
+type bindingState struct {
+	down    bool
+	heldFor float64
+	lastTap float64
+}
+
+func newBindingState() bindingState {
+	return bindingState{lastTap: math.Inf(-1)}
+}
+
+// interact steps the interaction of a binding by a frame. down is if the
+// binding inputs are held this frame and now is the time of the frame. It
+// returns true if the binding is triggered this frame.
+func (s *bindingState) interact(interaction Interaction, timing Timing, down bool, deltaTime, now float64) bool {
+	wasDown := s.down
+	s.down = down
+	if down {
+		if wasDown {
+			s.heldFor += deltaTime
+		} else {
+			s.heldFor = 0
+		}
+	}
+	switch interaction {
+	case InteractionHold:
+		return down && s.heldFor >= timing.hold()
+	case InteractionTap:
+		return wasDown && !down && s.heldFor <= timing.tap()
+	case InteractionDoubleTap:
+		if !wasDown || down || s.heldFor > timing.tap() {
+			return false
+		}
+		if now-s.lastTap <= timing.doubleTap() {
+			s.lastTap = math.Inf(-1)
+			return true
+		}
+		s.lastTap = now
+		return false
+	default:
+		return down
+	}
+}
@@ -0,0 +1,242 @@
+/******************************************************************************/
+/* input.go                                                                   */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package input_actions
+
+import (
+	"fmt"
+	"slices"
+
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/hid"
+)
+
+// Device is the kind of physical input a binding reads from
+type Device uint8
+
+const (
+	DeviceKeyboard Device = iota
+	DeviceMouseButton
+	DeviceMouseAxis
+	DeviceControllerButton
+	DeviceControllerAxis
+)
+
+var deviceNames = []string{"keyboard", "mouse", "mouse_axis", "controller", "controller_axis"}
+
+func (d Device) String() string { return enumName(deviceNames, d) }
+
+func (d Device) MarshalText() ([]byte, error) { return []byte(d.String()), nil }
+
+func (d *Device) UnmarshalText(text []byte) error {
+	return enumParse(deviceNames, string(text), d, "device")
+}
+
+// The mouse axes that can be bound with [DeviceMouseAxis]. The X and Y axes
+// are the distance the mouse moved this frame in pixels, with Y going up.
+const (
+	MouseAxisX = iota
+	MouseAxisY
+	MouseAxisScrollX
+	MouseAxisScrollY
+)
+
+// Modifier is a set of keyboard modifiers that have to be held for a binding
+type Modifier uint8
+
+const (
+	ModifierCtrl Modifier = 1 << iota
+	ModifierShift
+	ModifierAlt
+	ModifierMeta
+)
+
+// Input is a single key, button or axis. Sign picks one half of an axis, so
+// that a stick can be bound like a button, 0 uses the whole axis.
+type Input struct {
+	Device Device `json:"device"`
+	Code   int    `json:"code"`
+	Sign   int8   `json:"sign,omitempty"`
+}
+
+func Key(key hid.KeyboardKey) Input { return Input{Device: DeviceKeyboard, Code: key} }
+func MouseButton(button int) Input  { return Input{Device: DeviceMouseButton, Code: button} }
+func MouseAxis(axis int) Input      { return Input{Device: DeviceMouseAxis, Code: axis} }
+
+func ControllerButton(button hid.ControllerButton) Input {
+	return Input{Device: DeviceControllerButton, Code: int(button)}
+}
+
+func ControllerAxis(axis int) Input {
+	return Input{Device: DeviceControllerAxis, Code: axis}
+}
+
+// Positive returns the positive half of an axis input
+func (in Input) Positive() Input { in.Sign = 1; return in }
+
+// Negative returns the negative half of an axis input, it reads as a positive
+// value when the axis is pushed below 0
+func (in Input) Negative() Input { in.Sign = -1; return in }
+
+func (in Input) isAxis() bool {
+	return in.Device == DeviceMouseAxis || in.Device == DeviceControllerAxis
+}
+
+func (in Input) isModifierKey() bool {
+	if in.Device != DeviceKeyboard {
+		return false
+	}
+	switch in.Code {
+	case hid.KeyboardKeyLeftCtrl, hid.KeyboardKeyRightCtrl,
+		hid.KeyboardKeyLeftShift, hid.KeyboardKeyRightShift,
+		hid.KeyboardKeyLeftAlt, hid.KeyboardKeyRightAlt,
+		hid.KeyboardKeyLeftMeta, hid.KeyboardKeyRightMeta:
+		return true
+	}
+	return false
+}
+
+func (in Input) String() string {
+	out := fmt.Sprintf("%s:%d", in.Device, in.Code)
+	if in.Sign > 0 {
+		out += "+"
+	} else if in.Sign < 0 {
+		out += "-"
+	}
+	return out
+}
+
+// Devices are the devices that are read by [Map.Update], typically the ones
+// on the host window. Any of them can be nil.
+type Devices struct {
+	Keyboard   *hid.Keyboard
+	Mouse      *hid.Mouse
+	Controller *hid.Controller
+}
+
+// pressThreshold is how far an axis has to be pushed to count as pressed
+const pressThreshold = 0.5
+
+// deviceReader reads the raw value of inputs for a single frame
+type deviceReader struct {
+	devices    Devices
+	mouseDelta matrix.Vec2
+	// controller is the controller to read, or -1 to read all of them
+	controller int
+}
+
+func (r *deviceReader) controllers(each func(id int)) {
+	pad := r.devices.Controller
+	if pad == nil {
+		return
+	}
+	if r.controller >= 0 {
+		if pad.Available(r.controller) {
+			each(r.controller)
+		}
+		return
+	}
+	for id := range hid.ControllerMaxDevices {
+		if pad.Available(id) {
+			each(id)
+		}
+	}
+}
+
+// value reads the input, buttons are 0 or 1 and axes are their raw value
+func (r *deviceReader) value(in Input) matrix.Float {
+	var v matrix.Float
+	switch in.Device {
+	case DeviceKeyboard:
+		if kb := r.devices.Keyboard; kb != nil && in.Code >= 0 && in.Code < hid.KeyboardKeyMaximum && kb.KeyHeld(in.Code) {
+			v = 1
+		}
+	case DeviceMouseButton:
+		if m := r.devices.Mouse; m != nil && in.Code >= 0 && in.Code < hid.MouseButtonLast &&
+			(m.Pressed(in.Code) || m.Held(in.Code)) {
+			v = 1
+		}
+	case DeviceMouseAxis:
+		if m := r.devices.Mouse; m != nil {
+			switch in.Code {
+			case MouseAxisX:
+				v = r.mouseDelta.X()
+			case MouseAxisY:
+				v = r.mouseDelta.Y()
+			case MouseAxisScrollX:
+				v = matrix.Float(m.ScrollX)
+			case MouseAxisScrollY:
+				v = matrix.Float(m.ScrollY)
+			}
+		}
+	case DeviceControllerButton:
+		if in.Code >= 0 && in.Code < int(hid.ControllerButtonMax) {
+			r.controllers(func(id int) {
+				pad := r.devices.Controller
+				b := hid.ControllerButton(in.Code)
+				if pad.IsButtonDown(hid.ControllerButton(id), b) || pad.IsButtonHeld(hid.ControllerButton(id), b) {
+					v = 1
+				}
+			})
+		}
+	case DeviceControllerAxis:
+		if in.Code >= 0 && in.Code < hid.ControllerAxisMax {
+			// The controller pushed the furthest wins
+			r.controllers(func(id int) {
+				if a := matrix.Float(r.devices.Controller.Axis(id, in.Code)); matrix.Abs(a) > matrix.Abs(v) {
+					v = a
+				}
+			})
+		}
+	}
+	switch {
+	case in.Sign > 0:
+		return max(0, v)
+	case in.Sign < 0:
+		return max(0, -v)
+	}
+	return v
+}
+
+func (r *deviceReader) down(in Input) bool {
+	return matrix.Abs(r.value(in)) >= pressThreshold
+}
+
+func (r *deviceReader) modifiers() Modifier {
+	kb := r.devices.Keyboard
+	if kb == nil {
+		return 0
+	}
+	var m Modifier
+	if kb.HasCtrl() {
+		m |= ModifierCtrl
+	}
+	if kb.HasShift() {
+		m |= ModifierShift
+	}
+	if kb.HasAlt() {
+		m |= ModifierAlt
+	}
+	if kb.HasMeta() {
+		m |= ModifierMeta
+	}
+	return m
+}
+
+func enumName[T ~uint8](names []string, v T) string {
+	if int(v) < len(names) {
+		return names[v]
+	}
+	return fmt.Sprintf("%d", v)
+}
+
+func enumParse[T ~uint8](names []string, text string, out *T, kind string) error {
+	if i := slices.Index(names, text); i >= 0 {
+		*out = T(i)
+		return nil
+	}
+	return fmt.Errorf("unknown %s %q", kind, text)
+}
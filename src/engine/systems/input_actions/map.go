@@ -0,0 +1,327 @@
+/******************************************************************************/
+/* map.go                                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package input_actions
+
+import (
+	"kaijuengine.com/engine/systems/events"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// ActionState is the state of an action for the current frame. For button
+// and axis actions only the X of the value is used.
+type ActionState struct {
+	Value    matrix.Vec2
+	Held     bool
+	Pressed  bool
+	Released bool
+	// HeldTime is how long the action has been held in seconds
+	HeldTime float64
+}
+
+// compiledBinding is a binding of the profile flattened for evaluation
+type compiledBinding struct {
+	binding Binding
+	action  int
+	scheme  int
+	state   bindingState
+	// consumed is set when a binding with modifiers or a chord on the same
+	// input is triggered, so this one doesn't trigger with it
+	consumed bool
+}
+
+// Map reads the devices each frame and updates the state of the actions of a
+// [Profile]. Call [Map.Update] once a frame, before gameplay reads the
+// actions, typically from the host updater:
+//
+//	actions := input_actions.New(defaultProfile)
+//	host.Updater.AddUpdate(func(dt float64) {
+//		w := host.Window
+//		actions.Update(dt, input_actions.Devices{
+//			Keyboard: &w.Keyboard, Mouse: &w.Mouse, Controller: &w.Controller})
+//	})
+type Map struct {
+	// OnSchemeChanged is called when the player starts using a different
+	// scheme, such as picking up a controller, so prompts can be updated
+	OnSchemeChanged events.Event
+	defaults        Profile
+	profile         Profile
+	states          []ActionState
+	actions         map[string]int
+	compiled        []compiledBinding
+	reader          deviceReader
+	capture         *captureRequest
+	lastMouse       matrix.Vec2
+	hasMouse        bool
+	time            float64
+	activeScheme    int
+}
+
+// New creates a map for the given default profile, the player's saved
+// changes can be loaded on top of it with [Map.Load]
+func New(defaults Profile) *Map {
+	m := &Map{
+		defaults: defaults.Clone(),
+		reader:   deviceReader{controller: -1},
+	}
+	m.setProfile(defaults.Clone())
+	return m
+}
+
+func (m *Map) setProfile(p Profile) {
+	m.profile = p
+	m.actions = make(map[string]int, len(p.Actions))
+	for i := range p.Actions {
+		m.actions[p.Actions[i].Name] = i
+	}
+	m.states = make([]ActionState, len(p.Actions))
+	m.activeScheme = min(m.activeScheme, max(0, len(p.Schemes)-1))
+	m.compile()
+}
+
+func (m *Map) compile() {
+	m.compiled = m.compiled[:0]
+	for s := range m.profile.Schemes {
+		scheme := &m.profile.Schemes[s]
+		// Bindings are compiled in the order of the actions so evaluation
+		// doesn't depend on map order
+		for a := range m.profile.Actions {
+			for _, b := range scheme.Bindings[m.profile.Actions[a].Name] {
+				m.compiled = append(m.compiled, compiledBinding{
+					binding: b,
+					action:  a,
+					scheme:  s,
+					state:   newBindingState(),
+				})
+			}
+		}
+	}
+}
+
+// Profile returns a copy of the current profile, including any rebinding
+func (m *Map) Profile() Profile { return m.profile.Clone() }
+
+// SetProfile replaces the current profile, the defaults are not changed
+func (m *Map) SetProfile(p Profile) error {
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	m.setProfile(p.Clone())
+	return nil
+}
+
+// ResetToDefaults throws away all rebinding
+func (m *Map) ResetToDefaults() { m.setProfile(m.defaults.Clone()) }
+
+// Save writes the current profile to the given path, see [ProfilePath]
+func (m *Map) Save(path string) error { return m.profile.Save(path) }
+
+// Load reads a profile saved with [Map.Save] and applies it over the
+// defaults
+func (m *Map) Load(path string) error {
+	saved, err := LoadProfile(path)
+	if err != nil {
+		return err
+	}
+	m.setProfile(m.defaults.Merge(saved))
+	return nil
+}
+
+// SetController limits the controller inputs to the controller with the
+// given id, such as for local multiplayer. -1 reads every controller.
+func (m *Map) SetController(id int) { m.reader.controller = id }
+
+// ActiveScheme is the name of the scheme that was last used
+func (m *Map) ActiveScheme() string {
+	if m.activeScheme < len(m.profile.Schemes) {
+		return m.profile.Schemes[m.activeScheme].Name
+	}
+	return ""
+}
+
+// State returns the state of the action this frame, unknown actions are
+// never held
+func (m *Map) State(action string) ActionState {
+	if i, ok := m.actions[action]; ok {
+		return m.states[i]
+	}
+	return ActionState{}
+}
+
+// Pressed returns true on the frame the action started being held
+func (m *Map) Pressed(action string) bool { return m.State(action).Pressed }
+
+// Released returns true on the frame the action stopped being held
+func (m *Map) Released(action string) bool { return m.State(action).Released }
+
+// Held returns true for as long as the action is held
+func (m *Map) Held(action string) bool { return m.State(action).Held }
+
+// Value returns the value of a button or axis action
+func (m *Map) Value(action string) matrix.Float { return m.State(action).Value.X() }
+
+// Axis2D returns the value of a 2D axis action
+func (m *Map) Axis2D(action string) matrix.Vec2 { return m.State(action).Value }
+
+// Update reads the devices and updates the state of every action, it should
+// be called once a frame
+func (m *Map) Update(deltaTime float64, devices Devices) {
+	defer tracing.NewRegion("input_actions.Map.Update").End()
+	m.time += deltaTime
+	m.reader.devices = devices
+	m.reader.mouseDelta = matrix.Vec2Zero()
+	if devices.Mouse != nil {
+		pos := devices.Mouse.Position()
+		if m.hasMouse {
+			m.reader.mouseDelta = pos.Subtract(m.lastMouse)
+		}
+		m.lastMouse = pos
+		m.hasMouse = true
+	}
+	values := make([]matrix.Vec2, len(m.states))
+	held := make([]bool, len(m.states))
+	if m.capture != nil {
+		m.updateCapture()
+	} else {
+		m.evaluate(deltaTime, values, held)
+	}
+	for i := range m.states {
+		m.states[i] = m.nextState(&m.profile.Actions[i], m.states[i], values[i], held[i], deltaTime)
+	}
+}
+
+func (m *Map) evaluate(deltaTime float64, values []matrix.Vec2, held []bool) {
+	mods := m.reader.modifiers()
+	qualifiedDown := func(b *compiledBinding) bool {
+		if mods&b.binding.Modifiers != b.binding.Modifiers {
+			return false
+		}
+		for _, in := range b.binding.Chord {
+			if !m.reader.down(in) {
+				return false
+			}
+		}
+		return true
+	}
+	// Bindings with modifiers or a chord take their input from the plain
+	// bindings on the same input
+	for i := range m.compiled {
+		m.compiled[i].consumed = false
+	}
+	for i := range m.compiled {
+		b := &m.compiled[i]
+		if !b.binding.isQualified() || !qualifiedDown(b) || !m.reader.down(b.binding.Input) {
+			continue
+		}
+		for j := range m.compiled {
+			other := &m.compiled[j]
+			if !other.binding.isQualified() && other.binding.Input == b.binding.Input {
+				other.consumed = true
+			}
+		}
+	}
+	scheme := m.activeScheme
+	for i := range m.compiled {
+		b := &m.compiled[i]
+		action := &m.profile.Actions[b.action]
+		gated := !b.consumed && qualifiedDown(b)
+		value := m.reader.value(b.binding.Input) * b.binding.scale()
+		down := gated && matrix.Abs(value) >= pressThreshold
+		wasDown := b.state.down
+		triggered := b.state.interact(b.binding.Interaction, action.Timing, down, deltaTime, m.time)
+		if down && !wasDown {
+			scheme = b.scheme
+		}
+		if action.Type == ActionTypeButton {
+			if triggered {
+				held[b.action] = true
+				values[b.action][0] = max(values[b.action][0], matrix.Abs(value), 1)
+			}
+			continue
+		}
+		// Analog values come through while the binding is only pushed a little
+		// as long as it is a press, other interactions use the full value once
+		// they trigger
+		if b.binding.Interaction == InteractionPress {
+			if !gated {
+				continue
+			}
+			if value != 0 && !b.binding.Input.isAxis() {
+				scheme = b.scheme
+			}
+		} else if !triggered {
+			continue
+		}
+		if action.Type == ActionTypeAxis2D && b.binding.Component == ComponentY {
+			values[b.action][1] += value
+		} else {
+			values[b.action][0] += value
+		}
+	}
+	if scheme != m.activeScheme {
+		m.activeScheme = scheme
+		m.OnSchemeChanged.Execute()
+	}
+}
+
+// nextState turns the summed value of an action's bindings into its state
+func (m *Map) nextState(action *Action, prev ActionState, value matrix.Vec2, held bool, deltaTime float64) ActionState {
+	switch action.Type {
+	case ActionTypeAxis:
+		value[1] = 0
+		if !action.Raw {
+			value[0] = applyDeadZone(matrix.Clamp(value[0], -1, 1), action.DeadZone)
+		}
+		held = value[0] != 0
+	case ActionTypeAxis2D:
+		if !action.Raw {
+			value = applyRadialDeadZone(value, action.DeadZone)
+		}
+		held = value[0] != 0 || value[1] != 0
+	default:
+		if !held {
+			value = matrix.Vec2Zero()
+		}
+		value[0] = min(value[0], 1)
+	}
+	next := ActionState{
+		Value:    value,
+		Held:     held,
+		Pressed:  held && !prev.Held,
+		Released: !held && prev.Held,
+	}
+	if held && prev.Held {
+		next.HeldTime = prev.HeldTime + deltaTime
+	}
+	return next
+}
+
+func applyDeadZone(v, deadZone matrix.Float) matrix.Float {
+	a := matrix.Abs(v)
+	if deadZone <= 0 {
+		return v
+	}
+	if a <= deadZone || deadZone >= 1 {
+		return 0
+	}
+	scaled := (a - deadZone) / (1 - deadZone)
+	if v < 0 {
+		return -scaled
+	}
+	return scaled
+}
+
+// applyRadialDeadZone dead zones a 2D axis by its length so that diagonals
+// aren't cut off, the result is never longer than 1
+func applyRadialDeadZone(v matrix.Vec2, deadZone matrix.Float) matrix.Vec2 {
+	length := v.Length()
+	if length == 0 {
+		return v
+	}
+	scaled := applyDeadZone(min(length, 1), deadZone)
+	return v.Scale(scaled / length)
+}
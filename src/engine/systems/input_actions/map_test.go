@@ -0,0 +1,377 @@
+/******************************************************************************/
+/* map_test.go                                                                */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package input_actions
+
+import (
+	"errors"
+	"testing"
+
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/hid"
+)
+
+const testFrame = 1.0 / 60.0
+
+type testDevices struct {
+	keyboard   hid.Keyboard
+	mouse      hid.Mouse
+	controller hid.Controller
+}
+
+func newTestDevices() *testDevices {
+	d := &testDevices{
+		keyboard:   hid.NewKeyboard(),
+		mouse:      hid.NewMouse(),
+		controller: hid.NewController(),
+	}
+	d.controller.Connected(0)
+	return d
+}
+
+// frame updates the map with the current device state and then moves the
+// devices on to the next frame like the window does
+func (d *testDevices) frame(m *Map, deltaTime float64) {
+	m.Update(deltaTime, Devices{Keyboard: &d.keyboard, Mouse: &d.mouse, Controller: &d.controller})
+	d.keyboard.EndUpdate()
+	d.mouse.EndUpdate()
+	d.controller.EndUpdate()
+}
+
+func testProfile() Profile {
+	return Profile{
+		Name: "default",
+		Actions: []Action{
+			{Name: "jump", Type: ActionTypeButton},
+			{Name: "save", Type: ActionTypeButton},
+			{Name: "dash", Type: ActionTypeButton},
+			{Name: "interact", Type: ActionTypeButton},
+			{Name: "roll", Type: ActionTypeButton},
+			{Name: "steer", Type: ActionTypeAxis, DeadZone: 0.2},
+			{Name: "move", Type: ActionTypeAxis2D, DeadZone: 0.2},
+		},
+		Schemes: []Scheme{
+			{
+				Name:    "keyboard",
+				Devices: []Device{DeviceKeyboard, DeviceMouseButton},
+				Bindings: map[string][]Binding{
+					"jump":     {{Input: Key(hid.KeyboardKeySpace)}},
+					"save":     {{Input: Key(hid.KeyboardKeyS), Modifiers: ModifierCtrl}},
+					"dash":     {{Input: Key(hid.KeyboardKeyE), Interaction: InteractionHold}},
+					"interact": {{Input: Key(hid.KeyboardKeyE), Interaction: InteractionTap}},
+					"roll":     {{Input: Key(hid.KeyboardKeyQ), Interaction: InteractionDoubleTap}},
+					"steer": {
+						{Input: Key(hid.KeyboardKeyA), Scale: -1},
+						{Input: Key(hid.KeyboardKeyD)},
+					},
+					"move": {
+						{Input: Key(hid.KeyboardKeyW), Component: ComponentY},
+						{Input: Key(hid.KeyboardKeyS), Component: ComponentY, Scale: -1},
+						{Input: Key(hid.KeyboardKeyA), Scale: -1},
+						{Input: Key(hid.KeyboardKeyD)},
+					},
+				},
+			},
+			{
+				Name:    "controller",
+				Devices: []Device{DeviceControllerButton, DeviceControllerAxis},
+				Bindings: map[string][]Binding{
+					"jump": {{Input: ControllerButton(hid.ControllerButtonA)}},
+					"steer": {{
+						Input: ControllerAxis(hid.ControllerAxisLeftHorizontal),
+					}},
+					"move": {
+						{Input: ControllerAxis(hid.ControllerAxisLeftHorizontal)},
+						{Input: ControllerAxis(hid.ControllerAxisLeftVertical), Component: ComponentY},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMapButtonStates(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	d.keyboard.SetKeyDown(hid.KeyboardKeySpace)
+	d.frame(m, testFrame)
+	if !m.Pressed("jump") || !m.Held("jump") || m.Value("jump") != 1 {
+		t.Fatalf("jump on the first frame = %+v", m.State("jump"))
+	}
+	d.frame(m, testFrame)
+	if m.Pressed("jump") || !m.Held("jump") {
+		t.Fatalf("jump on the second frame = %+v", m.State("jump"))
+	}
+	if got := m.State("jump").HeldTime; got != testFrame {
+		t.Fatalf("held time = %v, want %v", got, testFrame)
+	}
+	d.keyboard.SetKeyUp(hid.KeyboardKeySpace)
+	d.frame(m, testFrame)
+	if !m.Released("jump") || m.Held("jump") {
+		t.Fatalf("jump after letting go = %+v", m.State("jump"))
+	}
+	if m.Held("unknown") {
+		t.Fatal("an unknown action should never be held")
+	}
+}
+
+func TestMapModifiersTakePriority(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	d.keyboard.SetKeyDown(hid.KeyboardKeyLeftCtrl)
+	d.keyboard.SetKeyDown(hid.KeyboardKeyS)
+	d.frame(m, testFrame)
+	if !m.Pressed("save") {
+		t.Fatal("ctrl+s should trigger save")
+	}
+	if m.Axis2D("move").Y() != 0 {
+		t.Fatalf("ctrl+s should not also move, got %v", m.Axis2D("move"))
+	}
+	d.keyboard.SetKeyUp(hid.KeyboardKeyLeftCtrl)
+	d.frame(m, testFrame)
+	if m.Held("save") {
+		t.Fatal("save should stop once ctrl is let go")
+	}
+	if m.Axis2D("move").Y() >= 0 {
+		t.Fatalf("s on its own should move back, got %v", m.Axis2D("move"))
+	}
+}
+
+func TestMapChord(t *testing.T) {
+	p := testProfile()
+	p.Schemes[1].Bindings["roll"] = []Binding{{
+		Input: ControllerButton(hid.ControllerButtonA),
+		Chord: []Input{ControllerButton(hid.ControllerButtonLeftBumper)},
+	}}
+	m := New(p)
+	d := newTestDevices()
+	d.controller.SetButtonDown(0, hid.ControllerButtonLeftBumper)
+	d.controller.SetButtonDown(0, hid.ControllerButtonA)
+	d.frame(m, testFrame)
+	if !m.Pressed("roll") {
+		t.Fatal("the chord should trigger roll")
+	}
+	if m.Held("jump") {
+		t.Fatal("the chord should take A from jump")
+	}
+	if m.ActiveScheme() != "controller" {
+		t.Fatalf("active scheme = %q", m.ActiveScheme())
+	}
+}
+
+func TestMapHoldAndTap(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	// A quick press is a tap
+	d.keyboard.SetKeyDown(hid.KeyboardKeyE)
+	d.frame(m, testFrame)
+	d.keyboard.SetKeyUp(hid.KeyboardKeyE)
+	d.frame(m, testFrame)
+	if !m.Pressed("interact") || m.Held("dash") {
+		t.Fatalf("tap: interact = %+v, dash = %+v", m.State("interact"), m.State("dash"))
+	}
+	d.frame(m, testFrame)
+	if m.Held("interact") {
+		t.Fatal("a tap should only trigger for a single frame")
+	}
+	// Holding past the hold time is a hold and not a tap
+	d.keyboard.SetKeyDown(hid.KeyboardKeyE)
+	d.frame(m, testFrame)
+	if m.Held("dash") {
+		t.Fatal("dash should wait for the hold time")
+	}
+	d.frame(m, defaultHoldTime)
+	if !m.Pressed("dash") {
+		t.Fatal("dash should trigger after the hold time")
+	}
+	d.keyboard.SetKeyUp(hid.KeyboardKeyE)
+	d.frame(m, testFrame)
+	if !m.Released("dash") || m.Held("interact") {
+		t.Fatalf("after hold: interact = %+v, dash = %+v", m.State("interact"), m.State("dash"))
+	}
+}
+
+func TestMapDoubleTap(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	tap := func() {
+		d.keyboard.SetKeyDown(hid.KeyboardKeyQ)
+		d.frame(m, testFrame)
+		d.keyboard.SetKeyUp(hid.KeyboardKeyQ)
+		d.frame(m, testFrame)
+	}
+	tap()
+	if m.Held("roll") {
+		t.Fatal("a single tap should not roll")
+	}
+	tap()
+	if !m.Pressed("roll") {
+		t.Fatal("a double tap should roll")
+	}
+	// Taps too far apart are not a double tap
+	tap()
+	d.frame(m, 1)
+	tap()
+	if m.Held("roll") {
+		t.Fatal("slow taps should not roll")
+	}
+}
+
+func TestMapAxisDeadZone(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	d.controller.SetAxis(0, hid.ControllerAxisLeftHorizontal, 0.1)
+	d.frame(m, testFrame)
+	if m.Held("steer") || m.Value("steer") != 0 {
+		t.Fatalf("inside the dead zone = %+v", m.State("steer"))
+	}
+	d.controller.SetAxis(0, hid.ControllerAxisLeftHorizontal, -0.6)
+	d.frame(m, testFrame)
+	if got := m.Value("steer"); !matrix.ApproxTo(got, -0.5, 0.0001) {
+		t.Fatalf("rescaled steer = %v, want -0.5", got)
+	}
+	// Keys and the stick add up and are clamped
+	d.keyboard.SetKeyDown(hid.KeyboardKeyA)
+	d.frame(m, testFrame)
+	if got := m.Value("steer"); got != -1 {
+		t.Fatalf("clamped steer = %v, want -1", got)
+	}
+}
+
+func TestMapAxis2D(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	d.keyboard.SetKeyDown(hid.KeyboardKeyW)
+	d.keyboard.SetKeyDown(hid.KeyboardKeyD)
+	d.frame(m, testFrame)
+	move := m.Axis2D("move")
+	if !matrix.ApproxTo(move.Length(), 1, 0.0001) || move.X() != move.Y() {
+		t.Fatalf("diagonal move = %v, want a length of 1", move)
+	}
+	if m.ActiveScheme() != "keyboard" {
+		t.Fatalf("active scheme = %q", m.ActiveScheme())
+	}
+	d.keyboard.SetKeyUp(hid.KeyboardKeyW)
+	d.keyboard.SetKeyUp(hid.KeyboardKeyD)
+	changed := false
+	m.OnSchemeChanged.Add(func() { changed = true })
+	d.controller.SetAxis(0, hid.ControllerAxisLeftVertical, 1)
+	d.frame(m, testFrame)
+	if move := m.Axis2D("move"); move.X() != 0 || move.Y() != 1 {
+		t.Fatalf("stick move = %v", move)
+	}
+	if !changed || m.ActiveScheme() != "controller" {
+		t.Fatalf("pushing the stick should change the scheme, got %q", m.ActiveScheme())
+	}
+}
+
+func TestMapSetController(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	d.controller.Connected(1)
+	m.SetController(1)
+	d.controller.SetButtonDown(0, hid.ControllerButtonA)
+	d.frame(m, testFrame)
+	if m.Held("jump") {
+		t.Fatal("controller 0 should be ignored")
+	}
+	d.controller.SetButtonDown(1, hid.ControllerButtonA)
+	d.frame(m, testFrame)
+	if !m.Held("jump") {
+		t.Fatal("controller 1 should jump")
+	}
+}
+
+func TestMapRebindConflicts(t *testing.T) {
+	m := New(testProfile())
+	err := m.Rebind("keyboard", "jump", 0, Binding{Input: Key(hid.KeyboardKeyQ)})
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a conflict, got %v", err)
+	}
+	if len(conflict.Conflicts) != 1 || conflict.Conflicts[0].Action != "roll" {
+		t.Fatalf("conflicts = %+v", conflict.Conflicts)
+	}
+	if m.Profile().Schemes[0].Bindings["jump"][0].Input != Key(hid.KeyboardKeySpace) {
+		t.Fatal("a conflicting rebind should not change the binding")
+	}
+	// A tap on a key that is used for a hold is fine
+	if err := m.Rebind("keyboard", "jump", 1, Binding{Input: Key(hid.KeyboardKeyE), Interaction: InteractionTap, Modifiers: ModifierShift}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.ForceRebind("keyboard", "jump", 0, Binding{Input: Key(hid.KeyboardKeyQ)}); err != nil {
+		t.Fatal(err)
+	}
+	p := m.Profile()
+	if len(p.Schemes[0].Bindings["roll"]) != 0 || p.Schemes[0].Bindings["jump"][0].Input != Key(hid.KeyboardKeyQ) {
+		t.Fatalf("force rebind = %+v", p.Schemes[0].Bindings)
+	}
+	if err := m.Rebind("keyboard", "jump", 5, Binding{}); err == nil {
+		t.Fatal("an index past the end should fail")
+	}
+	if err := m.RemoveBinding("keyboard", "jump", 1); err != nil {
+		t.Fatal(err)
+	}
+	m.ResetToDefaults()
+	if m.Profile().Schemes[0].Bindings["jump"][0].Input != Key(hid.KeyboardKeySpace) {
+		t.Fatal("reset should restore the defaults")
+	}
+}
+
+func TestMapCapture(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	// Space is already held when the capture starts, it shouldn't be captured
+	d.keyboard.SetKeyDown(hid.KeyboardKeySpace)
+	d.frame(m, testFrame)
+	var got Binding
+	var gotErr error
+	done := false
+	if err := m.Capture("keyboard", "jump", 0, func(b Binding, err error) {
+		got, gotErr, done = b, err, true
+	}); err != nil {
+		t.Fatal(err)
+	}
+	d.frame(m, testFrame)
+	// The controller isn't a device of the keyboard scheme
+	d.controller.SetButtonDown(0, hid.ControllerButtonB)
+	d.frame(m, testFrame)
+	if done {
+		t.Fatal("nothing should be captured yet")
+	}
+	d.keyboard.SetKeyDown(hid.KeyboardKeyLeftShift)
+	d.frame(m, testFrame)
+	d.keyboard.SetKeyDown(hid.KeyboardKeyJ)
+	d.frame(m, testFrame)
+	if !done || gotErr != nil {
+		t.Fatalf("capture done = %v, err = %v", done, gotErr)
+	}
+	if got.Input != Key(hid.KeyboardKeyJ) || got.Modifiers != ModifierShift {
+		t.Fatalf("captured %+v", got)
+	}
+	if m.IsCapturing() || m.Profile().Schemes[0].Bindings["jump"][0].Input != Key(hid.KeyboardKeyJ) {
+		t.Fatal("the captured binding should be set")
+	}
+	m.Capture("keyboard", "jump", 0, func(b Binding, err error) { gotErr = err })
+	d.keyboard.SetKeyDown(hid.KeyboardKeyEscape)
+	d.frame(m, testFrame)
+	if !errors.Is(gotErr, ErrCaptureCanceled) || m.IsCapturing() {
+		t.Fatalf("escape should cancel, got %v", gotErr)
+	}
+}
+
+func TestMapCaptureStickForAxis(t *testing.T) {
+	m := New(testProfile())
+	d := newTestDevices()
+	m.Capture("controller", "steer", 0, func(Binding, error) {})
+	d.frame(m, testFrame)
+	d.controller.SetAxis(0, hid.ControllerAxisRightHorizontal, -0.9)
+	d.frame(m, testFrame)
+	b := m.Profile().Schemes[1].Bindings["steer"][0]
+	if b.Input != ControllerAxis(hid.ControllerAxisRightHorizontal) || b.Scale != -1 {
+		t.Fatalf("captured %+v, want the whole axis flipped", b)
+	}
+}
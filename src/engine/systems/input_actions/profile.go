@@ -0,0 +1,235 @@
+/******************************************************************************/
+/* profile.go                                                                 */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package input_actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/filesystem"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+const profileFolder = "input"
+
+// ActionType is the kind of value an action has
+type ActionType uint8
+
+const (
+	// ActionTypeButton is on or off, analog inputs are on once they are
+	// pushed half way
+	ActionTypeButton ActionType = iota
+	// ActionTypeAxis is a value from -1 to 1
+	ActionTypeAxis
+	// ActionTypeAxis2D is a vector with a length up to 1, such as movement
+	ActionTypeAxis2D
+)
+
+var actionTypeNames = []string{"button", "axis", "axis2d"}
+
+func (t ActionType) String() string { return enumName(actionTypeNames, t) }
+
+func (t ActionType) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+func (t *ActionType) UnmarshalText(text []byte) error {
+	return enumParse(actionTypeNames, string(text), t, "action type")
+}
+
+const (
+	defaultHoldTime      = 0.4
+	defaultTapTime       = 0.25
+	defaultDoubleTapTime = 0.3
+)
+
+// Timing holds the times in seconds used by the hold, tap and double tap
+// interactions, 0 uses the default time
+type Timing struct {
+	Hold      float64 `json:"hold,omitempty"`
+	Tap       float64 `json:"tap,omitempty"`
+	DoubleTap float64 `json:"doubleTap,omitempty"`
+}
+
+func timingOr(value, fallback float64) float64 {
+	if value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+func (t Timing) hold() float64      { return timingOr(t.Hold, defaultHoldTime) }
+func (t Timing) tap() float64       { return timingOr(t.Tap, defaultTapTime) }
+func (t Timing) doubleTap() float64 { return timingOr(t.DoubleTap, defaultDoubleTapTime) }
+
+// Action is a named thing the player can do, such as "jump" or "move"
+type Action struct {
+	Name string     `json:"name"`
+	Type ActionType `json:"type"`
+	// DeadZone is how much of an axis is ignored around its center, the rest
+	// of the axis is rescaled to start at 0
+	DeadZone matrix.Float `json:"deadZone,omitempty"`
+	// Raw actions are not clamped to a length of 1 or dead zoned, which is
+	// what mouse look wants
+	Raw    bool   `json:"raw,omitempty"`
+	Timing Timing `json:"timing,omitempty"`
+}
+
+// Scheme is a set of bindings for a way of playing, such as keyboard and mouse
+// or a controller. All of the schemes of a profile are active at once, the
+// scheme last used is reported by [Map.ActiveScheme].
+type Scheme struct {
+	Name string `json:"name"`
+	// Devices limits which devices are captured when rebinding, empty allows
+	// any device
+	Devices  []Device             `json:"devices,omitempty"`
+	Bindings map[string][]Binding `json:"bindings"`
+}
+
+// Profile is all of the actions of a game and their bindings for each scheme.
+// The game creates the default profile and the player's changes are saved
+// and loaded on top of it.
+type Profile struct {
+	Name    string   `json:"name"`
+	Actions []Action `json:"actions"`
+	Schemes []Scheme `json:"schemes"`
+}
+
+func (p *Profile) action(name string) (*Action, bool) {
+	for i := range p.Actions {
+		if p.Actions[i].Name == name {
+			return &p.Actions[i], true
+		}
+	}
+	return nil, false
+}
+
+func (p *Profile) scheme(name string) (*Scheme, bool) {
+	for i := range p.Schemes {
+		if p.Schemes[i].Name == name {
+			return &p.Schemes[i], true
+		}
+	}
+	return nil, false
+}
+
+// Validate checks that the actions and schemes have unique names and that
+// the bindings are for known actions
+func (p *Profile) Validate() error {
+	actions := map[string]struct{}{}
+	for _, a := range p.Actions {
+		if a.Name == "" {
+			return fmt.Errorf("profile %q has an action without a name", p.Name)
+		}
+		if _, ok := actions[a.Name]; ok {
+			return fmt.Errorf("profile %q has the action %q more than once", p.Name, a.Name)
+		}
+		actions[a.Name] = struct{}{}
+	}
+	schemes := map[string]struct{}{}
+	for _, s := range p.Schemes {
+		if _, ok := schemes[s.Name]; ok {
+			return fmt.Errorf("profile %q has the scheme %q more than once", p.Name, s.Name)
+		}
+		schemes[s.Name] = struct{}{}
+		for action := range s.Bindings {
+			if _, ok := actions[action]; !ok {
+				return fmt.Errorf("scheme %q binds the unknown action %q", s.Name, action)
+			}
+		}
+	}
+	return nil
+}
+
+func (p Profile) Clone() Profile {
+	out := Profile{
+		Name:    p.Name,
+		Actions: make([]Action, len(p.Actions)),
+		Schemes: make([]Scheme, len(p.Schemes)),
+	}
+	copy(out.Actions, p.Actions)
+	for i, s := range p.Schemes {
+		out.Schemes[i] = Scheme{
+			Name:     s.Name,
+			Devices:  append([]Device(nil), s.Devices...),
+			Bindings: make(map[string][]Binding, len(s.Bindings)),
+		}
+		for action, bindings := range s.Bindings {
+			cpy := make([]Binding, len(bindings))
+			for j := range bindings {
+				cpy[j] = bindings[j].Clone()
+			}
+			out.Schemes[i].Bindings[action] = cpy
+		}
+	}
+	return out
+}
+
+// Merge returns the profile with the bindings of saved applied over it. Only
+// the bindings of actions and schemes that exist in this profile are used, so
+// a profile saved by an older version of a game still loads after actions are
+// added or removed. Actions missing from saved keep their bindings.
+func (p Profile) Merge(saved Profile) Profile {
+	out := p.Clone()
+	out.Name = saved.Name
+	for _, s := range saved.Schemes {
+		scheme, ok := out.scheme(s.Name)
+		if !ok {
+			continue
+		}
+		for action, bindings := range s.Bindings {
+			if _, ok := out.action(action); !ok {
+				continue
+			}
+			cpy := make([]Binding, len(bindings))
+			for i := range bindings {
+				cpy[i] = bindings[i].Clone()
+			}
+			scheme.Bindings[action] = cpy
+		}
+	}
+	return out
+}
+
+// ProfilePath is where the profile with the given name is saved in the game
+// directory
+func ProfilePath(name string) (string, error) {
+	dir, err := filesystem.GameDirectory()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, profileFolder, name+".json"), nil
+}
+
+// Save writes the profile to the given path as JSON, creating the folder if
+// needed
+func (p *Profile) Save(path string) error {
+	defer tracing.NewRegion("Profile.Save").End()
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// LoadProfile reads a profile that was written with [Profile.Save]
+func LoadProfile(path string) (Profile, error) {
+	defer tracing.NewRegion("input_actions.LoadProfile").End()
+	p := Profile{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return p, fmt.Errorf("failed to read the input profile %q: %w", path, err)
+	}
+	return p, p.Validate()
+}
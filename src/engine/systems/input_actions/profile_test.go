@@ -0,0 +1,112 @@
+/******************************************************************************/
+/* profile_test.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package input_actions
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kaijuengine.com/platform/hid"
+)
+
+func TestProfileValidate(t *testing.T) {
+	p := testProfile()
+	if err := p.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	p.Schemes[0].Bindings["fly"] = []Binding{{Input: Key(hid.KeyboardKeyF)}}
+	if err := p.Validate(); err == nil {
+		t.Fatal("a binding for an unknown action should fail")
+	}
+	p = testProfile()
+	p.Actions = append(p.Actions, Action{Name: "jump"})
+	if err := p.Validate(); err == nil {
+		t.Fatal("a duplicate action should fail")
+	}
+}
+
+func TestProfileJSONUsesNames(t *testing.T) {
+	p := testProfile()
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"axis2d"`, `"double_tap"`, `"controller_axis"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("the saved profile is missing %s", want)
+		}
+	}
+	var back Profile
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Schemes[0].Bindings["roll"][0].Interaction != InteractionDoubleTap {
+		t.Fatalf("round trip lost the interaction: %+v", back.Schemes[0].Bindings["roll"])
+	}
+	if err := json.Unmarshal([]byte(`{"name":"x","actions":[{"name":"a","type":"lever"}]}`), &back); err == nil {
+		t.Fatal("an unknown action type should fail")
+	}
+}
+
+func TestProfileMerge(t *testing.T) {
+	defaults := testProfile()
+	saved := Profile{
+		Name:    "player",
+		Actions: []Action{{Name: "jump"}, {Name: "removed"}},
+		Schemes: []Scheme{
+			{Name: "keyboard", Bindings: map[string][]Binding{
+				"jump":    {{Input: Key(hid.KeyboardKeyJ)}},
+				"removed": {{Input: Key(hid.KeyboardKeyR)}},
+			}},
+			{Name: "touch", Bindings: map[string][]Binding{
+				"jump": {{Input: MouseButton(hid.MouseButtonLeft)}},
+			}},
+		},
+	}
+	merged := defaults.Merge(saved)
+	if merged.Name != "player" || len(merged.Schemes) != 2 {
+		t.Fatalf("merged = %+v", merged)
+	}
+	if merged.Schemes[0].Bindings["jump"][0].Input != Key(hid.KeyboardKeyJ) {
+		t.Fatal("the saved binding should be used")
+	}
+	if _, ok := merged.Schemes[0].Bindings["removed"]; ok {
+		t.Fatal("bindings of removed actions should be dropped")
+	}
+	if len(merged.Schemes[0].Bindings["steer"]) != 2 {
+		t.Fatal("actions missing from the save should keep their defaults")
+	}
+	if defaults.Schemes[0].Bindings["jump"][0].Input != Key(hid.KeyboardKeySpace) {
+		t.Fatal("merging should not change the defaults")
+	}
+}
+
+func TestMapSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "input", "player.json")
+	m := New(testProfile())
+	if err := m.Rebind("keyboard", "jump", 0, Binding{Input: Key(hid.KeyboardKeyJ)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := New(testProfile())
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	d := newTestDevices()
+	d.keyboard.SetKeyDown(hid.KeyboardKeyJ)
+	d.frame(loaded, testFrame)
+	if !loaded.Pressed("jump") {
+		t.Fatal("the loaded binding should jump")
+	}
+	if err := loaded.Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("loading a missing profile should fail")
+	}
+}
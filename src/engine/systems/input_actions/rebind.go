@@ -0,0 +1,277 @@
+/******************************************************************************/
+/* rebind.go                                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package input_actions
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"kaijuengine.com/platform/hid"
+)
+
+// ErrCaptureCanceled is given to the capture callback when the player pressed
+// escape or the capture was canceled with [Map.CancelCapture]
+var ErrCaptureCanceled = errors.New("input capture was canceled")
+
+// Conflict is an existing binding that would trigger from the same inputs as
+// a new binding
+type Conflict struct {
+	Scheme string
+	Action string
+	Index  int
+}
+
+// ConflictError is returned by [Map.Rebind] when the new binding conflicts
+// with existing bindings, the binding is not changed
+type ConflictError struct {
+	Binding   Binding
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	actions := make([]string, len(e.Conflicts))
+	for i := range e.Conflicts {
+		actions[i] = e.Conflicts[i].Action
+	}
+	return fmt.Sprintf("the binding %s is already used by %s",
+		e.Binding.Input, strings.Join(actions, ", "))
+}
+
+func (m *Map) bindingSlot(scheme, action string, index int, appending bool) (*Scheme, error) {
+	s, ok := m.profile.scheme(scheme)
+	if !ok {
+		return nil, fmt.Errorf("unknown input scheme %q", scheme)
+	}
+	if _, ok := m.profile.action(action); !ok {
+		return nil, fmt.Errorf("unknown input action %q", action)
+	}
+	count := len(s.Bindings[action])
+	if appending {
+		count++
+	}
+	if index < 0 || index >= count {
+		return nil, fmt.Errorf("binding %d of the action %q is out of range", index, action)
+	}
+	return s, nil
+}
+
+// Conflicts returns the bindings of the scheme that would conflict with
+// binding if it were set as the binding at index of the action
+func (m *Map) Conflicts(scheme, action string, index int, binding Binding) []Conflict {
+	s, ok := m.profile.scheme(scheme)
+	if !ok {
+		return nil
+	}
+	var conflicts []Conflict
+	// Walk the actions in order so the result doesn't depend on map order
+	for i := range m.profile.Actions {
+		name := m.profile.Actions[i].Name
+		for j, b := range s.Bindings[name] {
+			if name == action && j == index {
+				continue
+			}
+			if b.conflictsWith(binding) {
+				conflicts = append(conflicts, Conflict{Scheme: scheme, Action: name, Index: j})
+			}
+		}
+	}
+	return conflicts
+}
+
+// Rebind sets the binding at index of the action in the scheme, an index
+// equal to the number of bindings adds a new binding. If the binding
+// conflicts with other bindings a [*ConflictError] is returned and nothing is
+// changed, [Map.ForceRebind] can be used once the player confirms.
+func (m *Map) Rebind(scheme, action string, index int, binding Binding) error {
+	if _, err := m.bindingSlot(scheme, action, index, true); err != nil {
+		return err
+	}
+	if conflicts := m.Conflicts(scheme, action, index, binding); len(conflicts) > 0 {
+		return &ConflictError{Binding: binding, Conflicts: conflicts}
+	}
+	return m.setBinding(scheme, action, index, binding)
+}
+
+// ForceRebind sets the binding like [Map.Rebind] and removes any bindings
+// that conflict with it
+func (m *Map) ForceRebind(scheme, action string, index int, binding Binding) error {
+	s, err := m.bindingSlot(scheme, action, index, true)
+	if err != nil {
+		return err
+	}
+	conflicts := m.Conflicts(scheme, action, index, binding)
+	// Remove from the back so the indexes of the other conflicts stay valid
+	for i := len(conflicts) - 1; i >= 0; i-- {
+		c := conflicts[i]
+		s.Bindings[c.Action] = slices.Delete(s.Bindings[c.Action], c.Index, c.Index+1)
+		if c.Action == action && c.Index < index {
+			index--
+		}
+	}
+	return m.setBinding(scheme, action, index, binding)
+}
+
+func (m *Map) setBinding(scheme, action string, index int, binding Binding) error {
+	s, err := m.bindingSlot(scheme, action, index, true)
+	if err != nil {
+		return err
+	}
+	if index == len(s.Bindings[action]) {
+		s.Bindings[action] = append(s.Bindings[action], binding.Clone())
+	} else {
+		s.Bindings[action][index] = binding.Clone()
+	}
+	m.compile()
+	return nil
+}
+
+// RemoveBinding removes the binding at index of the action in the scheme
+func (m *Map) RemoveBinding(scheme, action string, index int) error {
+	s, err := m.bindingSlot(scheme, action, index, false)
+	if err != nil {
+		return err
+	}
+	s.Bindings[action] = slices.Delete(s.Bindings[action], index, index+1)
+	m.compile()
+	return nil
+}
+
+// captureRequest is a pending [Map.Capture]
+type captureRequest struct {
+	scheme  string
+	action  string
+	index   int
+	devices []Device
+	done    func(Binding, error)
+	// wasDown are the inputs that were down last frame, an input has to be
+	// newly pressed to be captured
+	wasDown map[Input]bool
+	started bool
+}
+
+// Capture waits for the player to press a key, button or push a stick and
+// sets it as the binding at index of the action. Only the devices of the
+// scheme are captured, and modifiers held with a key are captured with it.
+// The interaction, scale and component of an existing binding are kept.
+// done is called with the new binding, a [*ConflictError] or
+// [ErrCaptureCanceled]. Actions are not updated while capturing.
+func (m *Map) Capture(scheme, action string, index int, done func(Binding, error)) error {
+	s, err := m.bindingSlot(scheme, action, index, true)
+	if err != nil {
+		return err
+	}
+	m.CancelCapture()
+	m.capture = &captureRequest{
+		scheme:  scheme,
+		action:  action,
+		index:   index,
+		devices: slices.Clone(s.Devices),
+		done:    done,
+		wasDown: map[Input]bool{},
+	}
+	return nil
+}
+
+// IsCapturing returns true while waiting on [Map.Capture]
+func (m *Map) IsCapturing() bool { return m.capture != nil }
+
+// CancelCapture stops a pending capture without changing the binding
+func (m *Map) CancelCapture() {
+	if c := m.capture; c != nil {
+		m.capture = nil
+		if c.done != nil {
+			c.done(Binding{}, ErrCaptureCanceled)
+		}
+	}
+}
+
+func (c *captureRequest) allows(d Device) bool {
+	return len(c.devices) == 0 || slices.Contains(c.devices, d)
+}
+
+// candidates are the inputs that can be captured, mouse movement is
+// left out as it is almost always moving while picking a binding
+func (c *captureRequest) candidates() []Input {
+	var out []Input
+	if c.allows(DeviceKeyboard) {
+		for k := range hid.KeyboardKeyMaximum {
+			if in := Key(k); !in.isModifierKey() {
+				out = append(out, in)
+			}
+		}
+	}
+	if c.allows(DeviceMouseButton) {
+		for b := range hid.MouseButtonLast {
+			out = append(out, MouseButton(b))
+		}
+	}
+	if c.allows(DeviceMouseAxis) {
+		out = append(out,
+			MouseAxis(MouseAxisScrollY).Positive(), MouseAxis(MouseAxisScrollY).Negative(),
+			MouseAxis(MouseAxisScrollX).Positive(), MouseAxis(MouseAxisScrollX).Negative())
+	}
+	if c.allows(DeviceControllerButton) {
+		for b := range hid.ControllerButtonMax {
+			out = append(out, ControllerButton(b))
+		}
+	}
+	if c.allows(DeviceControllerAxis) {
+		for a := range hid.ControllerAxisMax {
+			out = append(out, ControllerAxis(a).Positive(), ControllerAxis(a).Negative())
+		}
+	}
+	return out
+}
+
+func (m *Map) updateCapture() {
+	c := m.capture
+	action, _ := m.profile.action(c.action)
+	wholeAxis := action.Type != ActionTypeButton
+	if kb := m.reader.devices.Keyboard; kb != nil && kb.KeyDown(hid.KeyboardKeyEscape) {
+		m.CancelCapture()
+		return
+	}
+	var captured *Input
+	for _, in := range c.candidates() {
+		down := m.reader.down(in)
+		if down && !c.wasDown[in] && c.started && captured == nil {
+			captured = &in
+		}
+		c.wasDown[in] = down
+	}
+	// The first frame only records what is already held, such as the button
+	// that opened the rebinding menu
+	c.started = true
+	if captured == nil {
+		return
+	}
+	m.capture = nil
+	binding := Binding{Input: *captured}
+	if s, ok := m.profile.scheme(c.scheme); ok && c.index < len(s.Bindings[c.action]) {
+		prev := s.Bindings[c.action][c.index]
+		binding.Interaction = prev.Interaction
+		binding.Scale = prev.Scale
+		binding.Component = prev.Component
+	}
+	if binding.Input.isAxis() && wholeAxis {
+		// A stick bound to an axis reads both ways, the direction it was pushed
+		// drives the action the way the old binding did
+		if binding.Input.Sign < 0 {
+			binding.Scale = -binding.scale()
+		}
+		binding.Input.Sign = 0
+	}
+	if binding.Input.Device == DeviceKeyboard {
+		binding.Modifiers = m.reader.modifiers()
+	}
+	err := m.Rebind(c.scheme, c.action, c.index, binding)
+	if c.done != nil {
+		c.done(binding, err)
+	}
+}
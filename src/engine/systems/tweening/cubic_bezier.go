@@ -0,0 +1,74 @@
+/******************************************************************************/
+/* cubic_bezier.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package tweening
+
+import "kaijuengine.com/matrix"
+
+// CubicBezier is a timing curve that starts at (0,0) and ends at (1,1) with
+// the two control points (X1,Y1) and (X2,Y2), the same as the CSS
+// cubic-bezier() timing function. X1 and X2 should be within 0 and 1 so the
+// curve is a function of time.
+type CubicBezier struct {
+	X1, Y1, X2, Y2 float32
+}
+
+const (
+	cubicBezierNewtonIterations = 8
+	cubicBezierBisectIterations = 32
+	cubicBezierEpsilon          = 1e-6
+)
+
+func cubicBezierAxis(a1, a2, t float32) float32 {
+	// B(t) = 3(1-t)^2*t*a1 + 3(1-t)*t^2*a2 + t^3
+	it := 1 - t
+	return 3*it*it*t*a1 + 3*it*t*t*a2 + t*t*t
+}
+
+func cubicBezierAxisSlope(a1, a2, t float32) float32 {
+	it := 1 - t
+	return 3*it*it*a1 + 6*it*t*(a2-a1) + 3*t*t*(1-a2)
+}
+
+// Apply will return the progress of the curve at the given time, time is
+// clamped between 0 and 1
+func (c CubicBezier) Apply(t float32) float32 {
+	if t <= 0 {
+		return 0
+	}
+	if t >= 1 {
+		return 1
+	}
+	// Solve x(u) = t for u with Newton's method, falling back to bisection
+	// for flat parts of the curve where the slope is too small to converge
+	u := t
+	for range cubicBezierNewtonIterations {
+		x := cubicBezierAxis(c.X1, c.X2, u) - t
+		if matrix.Abs(x) < cubicBezierEpsilon {
+			return cubicBezierAxis(c.Y1, c.Y2, u)
+		}
+		d := cubicBezierAxisSlope(c.X1, c.X2, u)
+		if matrix.Abs(d) < cubicBezierEpsilon {
+			break
+		}
+		u -= x / d
+	}
+	lo, hi := float32(0), float32(1)
+	u = t
+	for range cubicBezierBisectIterations {
+		x := cubicBezierAxis(c.X1, c.X2, u)
+		if matrix.Abs(x-t) < cubicBezierEpsilon {
+			break
+		}
+		if x < t {
+			lo = u
+		} else {
+			hi = u
+		}
+		u = (lo + hi) * 0.5
+	}
+	return cubicBezierAxis(c.Y1, c.Y2, u)
+}
@@ -0,0 +1,82 @@
+/******************************************************************************/
+/* animator.go                                                                */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"slices"
+
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// Animator is something that changes UI over time, like a markup element that
+// is running a CSS animation or transition. Animators are advanced by the
+// [Manager] at the start of each UI update, before the UI is cleaned, so any
+// style they change is picked up by the same update.
+type Animator interface {
+	// Animate advances the animator by the delta time (in seconds). Returning
+	// false will remove the animator from the manager.
+	Animate(deltaTime float64) bool
+}
+
+// AddAnimator registers the animator to be advanced each UI update until its
+// Animate function returns false. Adding the same animator more than once is
+// ignored. This is safe to call while the UI is being cleaned on the UI
+// threads.
+func (man *Manager) AddAnimator(animator Animator) {
+	man.animatorMutex.Lock()
+	defer man.animatorMutex.Unlock()
+	if !slices.Contains(man.animators, animator) {
+		man.animators = append(man.animators, animator)
+	}
+}
+
+// RemoveAnimator stops the animator from being advanced by the manager
+func (man *Manager) RemoveAnimator(animator Animator) {
+	man.animatorMutex.Lock()
+	defer man.animatorMutex.Unlock()
+	if idx := slices.Index(man.animators, animator); idx >= 0 {
+		man.animators = slices.Delete(man.animators, idx, idx+1)
+	}
+}
+
+// AnimatorCount returns how many animators are currently being advanced
+func (man *Manager) AnimatorCount() int {
+	man.animatorMutex.Lock()
+	defer man.animatorMutex.Unlock()
+	return len(man.animators)
+}
+
+func (man *Manager) animate(deltaTime float64) {
+	defer tracing.NewRegion("ui.Manager.animate").End()
+	man.animatorMutex.Lock()
+	man.itrAnimators = append(man.itrAnimators[:0], man.animators...)
+	man.animatorMutex.Unlock()
+	if len(man.itrAnimators) == 0 {
+		return
+	}
+	finished := make([]Animator, 0)
+	for _, a := range man.itrAnimators {
+		if !a.Animate(deltaTime) {
+			finished = append(finished, a)
+		}
+	}
+	clear(man.itrAnimators)
+	for _, a := range finished {
+		man.RemoveAnimator(a)
+	}
+}
+
+// AddAnimator registers the animator with the manager that owns this UI, it
+// will return false if the UI no longer has a manager
+func (ui *UI) AddAnimator(animator Animator) bool {
+	man := ui.man.Value()
+	if man == nil {
+		return false
+	}
+	man.AddAnimator(animator)
+	return true
+}
@@ -0,0 +1,67 @@
+/******************************************************************************/
+/* animator_test.go                                                           */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import "testing"
+
+type countingTestAnimator struct {
+	remaining int
+	elapsed   float64
+}
+
+func (a *countingTestAnimator) Animate(deltaTime float64) bool {
+	a.elapsed += deltaTime
+	a.remaining--
+	return a.remaining > 0
+}
+
+func TestManagerAnimateRemovesFinishedAnimators(t *testing.T) {
+	man := Manager{}
+	short := &countingTestAnimator{remaining: 1}
+	long := &countingTestAnimator{remaining: 3}
+	man.AddAnimator(short)
+	man.AddAnimator(long)
+	man.AddAnimator(long)
+	if man.AnimatorCount() != 2 {
+		t.Fatalf("expected duplicate animators to be ignored, got %d", man.AnimatorCount())
+	}
+	man.animate(0.25)
+	if man.AnimatorCount() != 1 {
+		t.Fatalf("expected the finished animator to be removed, got %d", man.AnimatorCount())
+	}
+	man.animate(0.25)
+	man.animate(0.25)
+	if man.AnimatorCount() != 0 {
+		t.Fatalf("expected all animators to finish, got %d", man.AnimatorCount())
+	}
+	if short.elapsed != 0.25 || long.elapsed != 0.75 {
+		t.Fatalf("unexpected elapsed time, short %f long %f", short.elapsed, long.elapsed)
+	}
+}
+
+type addingTestAnimator struct {
+	man   *Manager
+	added *countingTestAnimator
+}
+
+func (a *addingTestAnimator) Animate(float64) bool {
+	a.man.AddAnimator(a.added)
+	return false
+}
+
+func TestManagerAnimatorCanAddWhileAnimating(t *testing.T) {
+	man := Manager{}
+	added := &countingTestAnimator{remaining: 2}
+	man.AddAnimator(&addingTestAnimator{man: &man, added: added})
+	man.animate(0.1)
+	if man.AnimatorCount() != 1 {
+		t.Fatalf("expected the animator added during animate to remain, got %d", man.AnimatorCount())
+	}
+	if added.elapsed != 0 {
+		t.Fatal("an animator added during animate should wait for the next update")
+	}
+}
@@ -0,0 +1,664 @@
+/******************************************************************************/
+/* focus_navigation.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"math"
+	"slices"
+	"weak"
+
+	"kaijuengine.com/engine/systems/events"
+	"kaijuengine.com/klib"
+	"kaijuengine.com/platform/hid"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// NavDirection is a direction that focus can be moved in with the keyboard or
+// a controller
+type NavDirection uint8
+
+const (
+	NavDirectionUp NavDirection = iota
+	NavDirectionDown
+	NavDirectionLeft
+	NavDirectionRight
+	NavDirectionNext
+	NavDirectionPrevious
+	navDirectionCount
+	navDirectionNone = navDirectionCount
+)
+
+const (
+	navRepeatDelay    = 0.4
+	navRepeatRate     = 0.12
+	navStickThreshold = 0.5
+	navSliderStep     = 0.05
+	// Candidates that are off to the side of the direction of travel are
+	// penalized this much more than ones that are straight ahead
+	navOrthogonalWeight = 2
+)
+
+type navFocusable uint8
+
+const (
+	navFocusableAuto = navFocusable(iota)
+	navFocusableYes
+	navFocusableNo
+)
+
+// panelNavigation holds the focus navigation settings of a panel, it is only
+// allocated for panels that change the defaults
+type panelNavigation struct {
+	targets   [navDirectionCount]weak.Pointer[UI]
+	tabIndex  int
+	focusable navFocusable
+}
+
+// FocusChange is the argument for [FocusNavigation.OnFocusChanged]. Visible is
+// true when the focus was moved by the keyboard or a controller, which is
+// when a focus ring should be shown (:focus-visible).
+type FocusChange struct {
+	Previous *UI
+	Current  *UI
+	Visible  bool
+}
+
+// FocusNavigation moves focus between the focusable elements of a [Manager]
+// with the keyboard and controllers. The arrow keys, d-pad and left stick move
+// to the nearest focusable element in that direction, tab and shift+tab move
+// in tab order. Enter, space and the controller A button activate the focused
+// element, escape and the controller B button go back.
+//
+// Navigation is disabled by default, as the editor and tools are mouse driven
+// and use the arrow keys for other things.
+type FocusNavigation struct {
+	OnFocusChanged events.EventWithArg[FocusChange]
+	OnBack         events.Event
+	man            weak.Pointer[Manager]
+	current        *UI
+	candidates     []*UI
+	rects          []navRect
+	held           NavDirection
+	repeatTimer    float64
+	enabled        bool
+	visible        bool
+}
+
+func (p *panelData) ensureNavigation() *panelNavigation {
+	if p.navigation == nil {
+		p.navigation = &panelNavigation{}
+	}
+	return p.navigation
+}
+
+// SetFocusable overrides if the panel can be focused by focus navigation. By
+// default only buttons, checkboxes, inputs, selects, sliders and text areas
+// are focusable.
+func (p *Panel) SetFocusable(focusable bool) {
+	nav := p.PanelData().ensureNavigation()
+	if focusable {
+		nav.focusable = navFocusableYes
+	} else {
+		nav.focusable = navFocusableNo
+	}
+}
+
+// IsFocusable returns true if focus navigation can move to this panel
+func (p *Panel) IsFocusable() bool {
+	if nav := p.PanelData().navigation; nav != nil && nav.focusable != navFocusableAuto {
+		return nav.focusable == navFocusableYes
+	}
+	switch p.elmType {
+	case ElementTypeButton, ElementTypeCheckbox, ElementTypeInput,
+		ElementTypeSelect, ElementTypeSlider, ElementTypeTextArea:
+		return true
+	}
+	return false
+}
+
+// SetTabIndex works like the HTML tabindex attribute. A negative index removes
+// the panel from navigation, 0 makes it focusable in reading order and
+// positive indexes are visited first, in ascending order.
+func (p *Panel) SetTabIndex(index int) {
+	p.SetFocusable(index >= 0)
+	p.PanelData().navigation.tabIndex = max(0, index)
+}
+
+func (p *Panel) TabIndex() int {
+	if nav := p.PanelData().navigation; nav != nil {
+		return nav.tabIndex
+	}
+	return 0
+}
+
+// SetNavigationTarget explicitly sets the element that focus moves to from
+// this panel in the given direction, instead of the nearest one. Passing nil
+// clears the override.
+func (p *Panel) SetNavigationTarget(dir NavDirection, target *UI) {
+	if dir >= navDirectionCount {
+		return
+	}
+	nav := p.PanelData().ensureNavigation()
+	if target == nil {
+		nav.targets[dir] = weak.Pointer[UI]{}
+	} else {
+		nav.targets[dir] = weak.Make(target)
+	}
+}
+
+func (p *Panel) NavigationTarget(dir NavDirection) *UI {
+	if nav := p.PanelData().navigation; nav != nil && dir < navDirectionCount {
+		return nav.targets[dir].Value()
+	}
+	return nil
+}
+
+func (ui *UI) isNavigable() bool {
+	return ui.IsValid() && !ui.IsType(ElementTypeLabel) && !ui.entity.IsDestroyed() &&
+		ui.entity.IsActive() && !ui.IsDisabled() && ui.ToPanel().IsFocusable()
+}
+
+func (ui *UI) isEditable() bool {
+	return ui.IsType(ElementTypeInput) || ui.IsType(ElementTypeTextArea)
+}
+
+func (nav *FocusNavigation) init(man *Manager) {
+	nav.man = weak.Make(man)
+	nav.held = navDirectionNone
+}
+
+func (nav *FocusNavigation) Enable()         { nav.enabled = true }
+func (nav *FocusNavigation) IsEnabled() bool { return nav.enabled }
+
+func (nav *FocusNavigation) Disable() {
+	nav.enabled = false
+	nav.Clear()
+}
+
+// Focused returns the element that has navigation focus, or nil
+func (nav *FocusNavigation) Focused() *UI { return nav.current }
+
+// IsFocusVisible returns true if the focused element should show its focus
+// ring, which is when the keyboard or a controller was last used
+func (nav *FocusNavigation) IsFocusVisible() bool {
+	return nav.current != nil && nav.visible
+}
+
+// Focus moves the navigation focus to the target element
+func (nav *FocusNavigation) Focus(target *UI) {
+	if target != nil && !target.isNavigable() {
+		return
+	}
+	nav.setCurrent(target, nav.visible)
+}
+
+// Clear removes the navigation focus
+func (nav *FocusNavigation) Clear() { nav.setCurrent(nil, nav.visible) }
+
+func (nav *FocusNavigation) setCurrent(target *UI, visible bool) {
+	prev := nav.current
+	if prev == target && nav.visible == visible {
+		return
+	}
+	nav.current = target
+	nav.visible = visible
+	if prev != target {
+		// Inputs and text areas raise their own focus events when they
+		// start and stop editing
+		if prev != nil && prev.IsValid() && !prev.isEditable() {
+			prev.requestEvent(EventTypeBlur)
+		}
+		if target != nil && !target.isEditable() {
+			target.requestEvent(EventTypeFocus)
+		}
+	}
+	nav.OnFocusChanged.Execute(FocusChange{
+		Previous: prev,
+		Current:  target,
+		Visible:  visible,
+	})
+}
+
+// Move moves the focus in the given direction, it returns false if there was
+// nothing to move to. When nothing is focused, the first element in tab
+// order is focused.
+func (nav *FocusNavigation) Move(dir NavDirection) bool {
+	defer tracing.NewRegion("FocusNavigation.Move").End()
+	if dir >= navDirectionCount {
+		return false
+	}
+	nav.collectCandidates()
+	if len(nav.candidates) == 0 {
+		return false
+	}
+	var target *UI
+	if nav.current == nil {
+		order := navTabOrder(nav.candidates, nav.rects)
+		target = nav.candidates[order[0]]
+	} else if t := nav.current.ToPanel().NavigationTarget(dir); t != nil && t.isNavigable() {
+		target = t
+	} else {
+		from := slices.Index(nav.candidates, nav.current)
+		switch dir {
+		case NavDirectionNext, NavDirectionPrevious:
+			if from < 0 {
+				return false
+			}
+			order := navTabOrder(nav.candidates, nav.rects)
+			at := slices.Index(order, from)
+			if dir == NavDirectionNext {
+				at = (at + 1) % len(order)
+			} else {
+				at = (at - 1 + len(order)) % len(order)
+			}
+			target = nav.candidates[order[at]]
+		default:
+			rect := navRectOf(nav.current)
+			if idx := navNearestInDirection(rect, nav.rects, dir, from); idx >= 0 {
+				target = nav.candidates[idx]
+			}
+		}
+	}
+	if target == nil || target == nav.current {
+		return false
+	}
+	if nav.current != nil && nav.current.isEditable() {
+		stopEditing(nav.current)
+	}
+	nav.setCurrent(target, true)
+	return true
+}
+
+// Activate does what a click would do to the focused element. Buttons are
+// clicked, checkboxes toggled, selects opened or closed and inputs start
+// editing.
+func (nav *FocusNavigation) Activate() {
+	defer tracing.NewRegion("FocusNavigation.Activate").End()
+	target := nav.current
+	if target == nil || !target.isNavigable() {
+		return
+	}
+	switch target.Type() {
+	case ElementTypeInput, ElementTypeTextArea:
+		focusEditableElement(target)
+	case ElementTypeSlider:
+		target.ToSlider().submit()
+	default:
+		target.requestEvent(EventTypeClick)
+	}
+}
+
+// Back closes whatever the focused element has open (an open select list or
+// an input being edited). If there is nothing to close, OnBack is executed.
+func (nav *FocusNavigation) Back() {
+	defer tracing.NewRegion("FocusNavigation.Back").End()
+	if target := nav.current; target != nil && target.IsValid() {
+		switch target.Type() {
+		case ElementTypeSelect:
+			if s := target.ToSelect(); s.SelectData().isOpen {
+				s.collapse()
+				return
+			}
+		case ElementTypeInput:
+			if input := target.ToInput(); input.IsFocused() {
+				input.SetTextWithoutEvent(input.InputData().textOnFocus)
+				input.RemoveFocus()
+				return
+			}
+		case ElementTypeTextArea:
+			if textarea := target.ToTextArea(); textarea.IsFocused() {
+				textarea.RemoveFocus()
+				return
+			}
+		}
+	}
+	nav.OnBack.Execute()
+}
+
+func stopEditing(target *UI) {
+	switch target.Type() {
+	case ElementTypeInput:
+		target.ToInput().RemoveFocus()
+	case ElementTypeTextArea:
+		target.ToTextArea().RemoveFocus()
+	}
+}
+
+// step handles a direction for the focused element itself, sliders are moved
+// left and right and the options of an open select are moved up and down
+func (nav *FocusNavigation) step(dir NavDirection) bool {
+	target := nav.current
+	if target == nil || !target.isNavigable() {
+		return false
+	}
+	switch target.Type() {
+	case ElementTypeSlider:
+		slider := target.ToSlider()
+		switch dir {
+		case NavDirectionLeft:
+			slider.SetValue(slider.Value() - navSliderStep)
+			return true
+		case NavDirectionRight:
+			slider.SetValue(slider.Value() + navSliderStep)
+			return true
+		}
+	case ElementTypeSelect:
+		s := target.ToSelect()
+		if !s.SelectData().isOpen {
+			return false
+		}
+		switch dir {
+		case NavDirectionUp:
+			s.stepOption(-1)
+			return true
+		case NavDirectionDown:
+			s.stepOption(1)
+			return true
+		}
+	}
+	return false
+}
+
+func (nav *FocusNavigation) navigate(dir NavDirection) {
+	if !nav.step(dir) {
+		nav.Move(dir)
+	}
+}
+
+func (nav *FocusNavigation) collectCandidates() {
+	nav.candidates = klib.WipeSlice(nav.candidates)
+	nav.rects = nav.rects[:0]
+	man := nav.man.Value()
+	if man == nil {
+		return
+	}
+	man.pools.Each(func(elm *UI) {
+		if elm.isNavigable() {
+			nav.candidates = append(nav.candidates, elm)
+			nav.rects = append(nav.rects, navRectOf(elm))
+		}
+	})
+}
+
+// focusableFrom returns the focusable element that was clicked, which is
+// either the hovered element or the closest focusable parent of it
+func focusableFrom(hovered []*UI) *UI {
+	var top *UI
+	for _, h := range hovered {
+		if top == nil || h.IsInFrontOf(top) {
+			top = h
+		}
+	}
+	for e := top; e != nil; {
+		if e.isNavigable() {
+			return e
+		}
+		if e.entity.Parent == nil {
+			break
+		}
+		e = FirstOnEntity(e.entity.Parent)
+	}
+	return nil
+}
+
+func (nav *FocusNavigation) update(deltaTime float64) {
+	defer tracing.NewRegion("FocusNavigation.update").End()
+	man := nav.man.Value()
+	if man == nil || man.Host == nil {
+		return
+	}
+	if nav.current != nil && !nav.current.isNavigable() {
+		nav.setCurrent(nil, nav.visible)
+	}
+	win := man.Host.Window
+	if win.Cursor.Pressed() {
+		nav.setCurrent(focusableFrom(man.Hovered()), false)
+	}
+	// Inputs can be focused directly, such as when tabbing between them
+	if f := man.Group.focus; f != nil && f != nav.current && f.isNavigable() {
+		nav.setCurrent(f, nav.visible)
+	}
+	kb := &win.Keyboard
+	dir := navDirectionNone
+	activate, back := false, false
+	if kb.KeyDown(hid.KeyboardKeyTab) && man.Group.IsFocusedOnInput() {
+		// The input moves the focus itself, this only shows the focus ring
+		nav.visible = true
+	} else if !man.Group.IsFocusedOnInput() {
+		switch {
+		case kb.KeyDown(hid.KeyboardKeyTab):
+			if kb.HasShift() {
+				nav.Move(NavDirectionPrevious)
+			} else {
+				nav.Move(NavDirectionNext)
+			}
+		case kb.KeyDown(hid.KeyboardKeyReturn), kb.KeyDown(hid.KeyboardKeyEnter),
+			kb.KeyDown(hid.KeyboardKeySpace):
+			activate = true
+		case kb.KeyDown(hid.KeyboardKeyEscape):
+			back = true
+		}
+		dir = navKeyboardDirection(kb)
+	}
+	pad := &win.Controller
+	for id := range hid.ControllerMaxDevices {
+		if !pad.Available(id) {
+			continue
+		}
+		if dir == navDirectionNone {
+			dir = navControllerDirection(pad, id)
+		}
+		activate = activate || pad.IsButtonDown(hid.ControllerButton(id), hid.ControllerButtonA)
+		back = back || pad.IsButtonDown(hid.ControllerButton(id), hid.ControllerButtonB)
+	}
+	if activate || back || dir != navDirectionNone {
+		if !nav.visible {
+			nav.setCurrent(nav.current, true)
+		}
+	}
+	nav.repeat(dir, deltaTime)
+	if activate {
+		nav.Activate()
+	}
+	if back {
+		nav.Back()
+	}
+}
+
+// repeat moves in the held direction right away and then repeatedly after a
+// delay for as long as the direction is held
+func (nav *FocusNavigation) repeat(dir NavDirection, deltaTime float64) {
+	if dir == navDirectionNone {
+		nav.held = navDirectionNone
+		return
+	}
+	if dir != nav.held {
+		nav.held = dir
+		nav.repeatTimer = navRepeatDelay
+		nav.navigate(dir)
+		return
+	}
+	nav.repeatTimer -= deltaTime
+	if nav.repeatTimer <= 0 {
+		nav.repeatTimer = navRepeatRate
+		nav.navigate(dir)
+	}
+}
+
+func navKeyboardDirection(kb *hid.Keyboard) NavDirection {
+	pressed := func(key hid.KeyboardKey) bool { return kb.KeyDown(key) || kb.KeyHeld(key) }
+	switch {
+	case pressed(hid.KeyboardKeyUp):
+		return NavDirectionUp
+	case pressed(hid.KeyboardKeyDown):
+		return NavDirectionDown
+	case pressed(hid.KeyboardKeyLeft):
+		return NavDirectionLeft
+	case pressed(hid.KeyboardKeyRight):
+		return NavDirectionRight
+	}
+	return navDirectionNone
+}
+
+func navControllerDirection(pad *hid.Controller, id int) NavDirection {
+	pressed := func(button hid.ControllerButton) bool {
+		return pad.IsButtonDown(hid.ControllerButton(id), button) || pad.IsButtonHeld(hid.ControllerButton(id), button)
+	}
+	switch {
+	case pressed(hid.ControllerButtonUp):
+		return NavDirectionUp
+	case pressed(hid.ControllerButtonDown):
+		return NavDirectionDown
+	case pressed(hid.ControllerButtonLeft):
+		return NavDirectionLeft
+	case pressed(hid.ControllerButtonRight):
+		return NavDirectionRight
+	}
+	return navStickDirection(
+		pad.Axis(id, hid.ControllerAxisLeftHorizontal),
+		pad.Axis(id, hid.ControllerAxisLeftVertical))
+}
+
+// navStickDirection picks the direction of the stick, the vertical axis is
+// positive when pushed up
+func navStickDirection(x, y float32) NavDirection {
+	ax, ay := math.Abs(float64(x)), math.Abs(float64(y))
+	if max(ax, ay) < navStickThreshold {
+		return navDirectionNone
+	}
+	if ax > ay {
+		if x < 0 {
+			return NavDirectionLeft
+		}
+		return NavDirectionRight
+	}
+	if y > 0 {
+		return NavDirectionUp
+	}
+	return NavDirectionDown
+}
+
+// navRect is the screen area of an element with y going down
+type navRect struct {
+	left, top, right, bottom float32
+}
+
+func navRectOf(ui *UI) navRect {
+	p, _, s := ui.entity.Transform.WorldTransform()
+	return navRect{
+		left:   p.X() - s.X()*0.5,
+		top:    -(p.Y() + s.Y()*0.5),
+		right:  p.X() + s.X()*0.5,
+		bottom: -(p.Y() - s.Y()*0.5),
+	}
+}
+
+func (r navRect) centerX() float32 { return (r.left + r.right) * 0.5 }
+func (r navRect) centerY() float32 { return (r.top + r.bottom) * 0.5 }
+
+// navGap is the distance between two ranges, 0 if they overlap
+func navGap(minA, maxA, minB, maxB float32) float32 {
+	if maxA < minB {
+		return minB - maxA
+	} else if maxB < minA {
+		return minA - maxB
+	}
+	return 0
+}
+
+// navNearestInDirection returns the index of the candidate that is nearest to
+// from in the given direction, or -1. Candidates have to be further along in
+// the direction than from is, the distance is the gap along the direction
+// plus a weighted gap to the side of it. The index skip is ignored, which is
+// where from itself is in the candidates.
+func navNearestInDirection(from navRect, candidates []navRect, dir NavDirection, skip int) int {
+	best := -1
+	var bestScore, bestCenter float32
+	for i, c := range candidates {
+		if i == skip {
+			continue
+		}
+		var primary, side float32
+		switch dir {
+		case NavDirectionUp:
+			if c.centerY() >= from.centerY() || c.top >= from.top {
+				continue
+			}
+			primary = max(0, from.top-c.bottom)
+			side = navGap(from.left, from.right, c.left, c.right)
+		case NavDirectionDown:
+			if c.centerY() <= from.centerY() || c.bottom <= from.bottom {
+				continue
+			}
+			primary = max(0, c.top-from.bottom)
+			side = navGap(from.left, from.right, c.left, c.right)
+		case NavDirectionLeft:
+			if c.centerX() >= from.centerX() || c.left >= from.left {
+				continue
+			}
+			primary = max(0, from.left-c.right)
+			side = navGap(from.top, from.bottom, c.top, c.bottom)
+		case NavDirectionRight:
+			if c.centerX() <= from.centerX() || c.right <= from.right {
+				continue
+			}
+			primary = max(0, c.left-from.right)
+			side = navGap(from.top, from.bottom, c.top, c.bottom)
+		default:
+			return -1
+		}
+		score := primary + side*navOrthogonalWeight
+		dx, dy := c.centerX()-from.centerX(), c.centerY()-from.centerY()
+		center := dx*dx + dy*dy
+		if best < 0 || score < bestScore || (score == bestScore && center < bestCenter) {
+			best, bestScore, bestCenter = i, score, center
+		}
+	}
+	return best
+}
+
+// navTabOrder returns the candidate indexes in tab order, positive tab
+// indexes come first and the rest are in reading order (top to bottom, then
+// left to right)
+func navTabOrder(candidates []*UI, rects []navRect) []int {
+	order := make([]int, len(candidates))
+	tabs := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+		tabs[i] = candidates[i].ToPanel().TabIndex()
+	}
+	return navSortTabOrder(order, tabs, rects)
+}
+
+func navSortTabOrder(order, tabs []int, rects []navRect) []int {
+	slices.SortStableFunc(order, func(a, b int) int {
+		ta, tb := tabs[a], tabs[b]
+		if ta != tb {
+			switch {
+			case ta == 0:
+				return 1
+			case tb == 0:
+				return -1
+			}
+			return ta - tb
+		}
+		ra, rb := rects[a], rects[b]
+		if ra.top != rb.top {
+			if ra.top < rb.top {
+				return -1
+			}
+			return 1
+		}
+		if ra.left < rb.left {
+			return -1
+		} else if ra.left > rb.left {
+			return 1
+		}
+		return 0
+	})
+	return order
+}
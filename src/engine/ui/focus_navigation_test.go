@@ -0,0 +1,104 @@
+/******************************************************************************/
+/* focus_navigation_test.go                                                   */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"slices"
+	"testing"
+)
+
+func navTestRect(x, y, w, h float32) navRect {
+	return navRect{left: x, top: y, right: x + w, bottom: y + h}
+}
+
+// navTestMenu is a 3x2 grid of buttons with a wide button under it
+//
+//	[0] [1] [2]
+//	[3] [4] [5]
+//	[    6    ]
+func navTestMenu() []navRect {
+	return []navRect{
+		navTestRect(0, 0, 100, 40), navTestRect(120, 0, 100, 40), navTestRect(240, 0, 100, 40),
+		navTestRect(0, 60, 100, 40), navTestRect(120, 60, 100, 40), navTestRect(240, 60, 100, 40),
+		navTestRect(0, 120, 340, 40),
+	}
+}
+
+func TestNavNearestInDirection(t *testing.T) {
+	menu := navTestMenu()
+	tests := []struct {
+		from int
+		dir  NavDirection
+		want int
+	}{
+		{0, NavDirectionRight, 1},
+		{1, NavDirectionRight, 2},
+		{2, NavDirectionRight, -1},
+		{1, NavDirectionDown, 4},
+		{4, NavDirectionUp, 1},
+		{4, NavDirectionLeft, 3},
+		{5, NavDirectionDown, 6},
+		{6, NavDirectionDown, -1},
+		{0, NavDirectionUp, -1},
+	}
+	for _, test := range tests {
+		got := navNearestInDirection(menu[test.from], menu, test.dir, test.from)
+		if got != test.want {
+			t.Errorf("from %d in direction %d = %d, want %d", test.from, test.dir, got, test.want)
+		}
+	}
+	// Going up from the wide button picks the aligned one closest to its center
+	if got := navNearestInDirection(menu[6], menu, NavDirectionUp, 6); got != 4 {
+		t.Errorf("up from the wide button = %d, want 4", got)
+	}
+}
+
+func TestNavNearestPrefersAligned(t *testing.T) {
+	from := navTestRect(0, 0, 100, 40)
+	candidates := []navRect{
+		// Closer, but far off to the side
+		navTestRect(110, 200, 100, 40),
+		// Further, but straight ahead
+		navTestRect(300, 0, 100, 40),
+	}
+	if got := navNearestInDirection(from, candidates, NavDirectionRight, -1); got != 1 {
+		t.Fatalf("got %d, want the aligned candidate", got)
+	}
+}
+
+func TestNavSortTabOrder(t *testing.T) {
+	menu := navTestMenu()
+	order := []int{6, 5, 4, 3, 2, 1, 0}
+	tabs := make([]int, len(menu))
+	if got := navSortTabOrder(slices.Clone(order), tabs, menu); !slices.Equal(got, []int{0, 1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("reading order = %v", got)
+	}
+	tabs[5] = 1
+	tabs[2] = 2
+	if got := navSortTabOrder(slices.Clone(order), tabs, menu); !slices.Equal(got, []int{5, 2, 0, 1, 3, 4, 6}) {
+		t.Fatalf("tab index order = %v", got)
+	}
+}
+
+func TestNavStickDirection(t *testing.T) {
+	tests := []struct {
+		x, y float32
+		want NavDirection
+	}{
+		{0, 0, navDirectionNone},
+		{0.3, -0.2, navDirectionNone},
+		{0, 1, NavDirectionUp},
+		{0, -0.8, NavDirectionDown},
+		{-0.9, 0.4, NavDirectionLeft},
+		{0.7, 0.6, NavDirectionRight},
+	}
+	for _, test := range tests {
+		if got := navStickDirection(test.x, test.y); got != test.want {
+			t.Errorf("navStickDirection(%v, %v) = %d, want %d", test.x, test.y, got, test.want)
+		}
+	}
+}
@@ -68,6 +68,9 @@ type inputData struct {
 	textOnFocus                       string
 	lastClickTime                     time.Time
 	lastDownTime                      time.Time
+	composition                       string
+	compositionCaret                  int
+	textInputRect                     textInputRect
 }
 
 func (i *inputData) innerPanelData() *panelData { return &i.panelData }
@@ -162,8 +165,10 @@ func (input *Input) Init(placeholderText string) {
 	input.SetFGColor(matrix.ColorBlack())
 	input.SetBGColor(matrix.ColorWhite())
 	id := host.Window.Keyboard.AddKeyCallback(input.keyPressed)
+	textId := host.Window.Keyboard.AddTextCallback(input.textTyped)
 	base.AddEvent(EventTypeDestroy, func() {
 		host.Window.Keyboard.RemoveKeyCallback(id)
+		host.Window.Keyboard.RemoveTextCallback(textId)
 	})
 	input.entity.OnDeactivate.Add(input.deactivated)
 	input.entity.OnActivate.Add(input.activated)
@@ -339,6 +344,7 @@ func (input *Input) setText(text string, skipEvent bool) {
 	data := input.InputData()
 	wasValid := input.IsValid()
 	data.text = input.sanitizeText(text)
+	data.composition, data.compositionCaret = "", 0
 	data.label.SetText(input.displayText(data.text))
 	// Setting the select here fixes a delayed mem stomping bug with colors and text
 	data.selectStart = 0
@@ -520,6 +526,7 @@ func (input *Input) update(deltaTime float64) {
 			}
 			data.cursorBlink = cursorBlinkRate
 		}
+		reportTextInputRect(input.man.Value().Host.Window, data.cursor, &data.textInputRect)
 		if input.flags.drag() {
 			offset := input.pointerPosWithin()
 			if data.selectStart == data.selectEnd {
@@ -548,7 +555,7 @@ func (input *Input) cursorWindow() (float32, float32) {
 
 func (input *Input) updateCursorPosition() {
 	data := input.InputData()
-	x := input.charX(data.cursorOffset)
+	x := input.charX(data.cursorOffset + data.compositionCaret)
 	left, right := input.cursorWindow()
 	if right > left {
 		if x < left {
@@ -911,6 +918,7 @@ func (input *Input) removeFocusWithoutEvents() {
 		return
 	}
 	data.isActive = false
+	input.setComposition("", 0)
 	input.resetSelect()
 	input.hideCursor()
 	input.hideHighlight()
@@ -928,6 +936,7 @@ func (input *Input) RemoveFocus() {
 	data := input.InputData()
 	if data.isActive {
 		data.isActive = false
+		input.setComposition("", 0)
 		input.resetSelect()
 		input.hideCursor()
 		txt := input.Text()
@@ -944,6 +953,51 @@ func (input *Input) RemoveFocus() {
 	}
 }
 
+// textTyped receives the text typed on the keyboard and the composition of
+// the input method while the input has focus
+func (input *Input) textTyped(evt hid.TextEvent) {
+	if input.IsDisabled() {
+		return
+	}
+	man := input.man.Value()
+	if man == nil || input.entity.IsDestroyed() {
+		return
+	}
+	data := input.InputData()
+	if !input.entity.IsActive() || !data.isActive {
+		return
+	}
+	switch evt.Type {
+	case hid.TextEventCommit:
+		input.setComposition("", 0)
+		input.InsertText(evt.Text)
+		if input.events[EventTypeKeyDown].IsEmpty() {
+			man.Group.triggerRequestStartState()
+		}
+	case hid.TextEventComposition:
+		input.setComposition(evt.Text, evt.Caret)
+	}
+}
+
+// setComposition shows the pre-edit text of the input method at the cursor,
+// the text of the input doesn't change until the composition is committed
+func (input *Input) setComposition(text string, caret int) {
+	data := input.InputData()
+	if data.composition == text && data.compositionCaret == caret {
+		return
+	}
+	data.composition = text
+	data.compositionCaret = editableTextClampOffset(text, caret)
+	shown := editableTextComposed(data.text, data.cursorOffset, text)
+	data.label.SetText(input.displayText(shown))
+	if text != "" {
+		data.placeholder.Hide()
+	} else {
+		input.updatePlaceholderVisibility()
+	}
+	input.showCursor()
+}
+
 func (input *Input) SetFontSize(fontSize float32) {
 	data := input.InputData()
 	data.label.SetFontSize(fontSize)
@@ -991,19 +1045,28 @@ func (input *Input) keyPressed(keyId int, keyState hid.KeyState) {
 	data := input.InputData()
 	if input.entity.IsActive() && data.isActive {
 		if keyState == hid.KeyStateDown || keyState == hid.KeyStatePressedAndReleased {
+			kb := &host.Window.Keyboard
+			if kb.IsComposing() {
+				// The keys are editing the input method composition
+				return
+			}
 			if keyId == hid.KeyboardKeyEscape {
 				input.SetTextWithoutEvent(data.textOnFocus)
 				input.RemoveFocus()
 				return
 			}
-			kb := &host.Window.Keyboard
 			c := host.Localization.KeyToRune(kb, keyId)
 			if c != 0 {
 				if !kb.HasCtrlOrMeta() {
-					if kb.IsToggleKeyOn(hid.KeyboardKeyCapsLock) {
-						input.InsertText(string(unicode.ToUpper(c)))
-					} else {
-						input.InsertText(string(c))
+					// Platforms that report typed text send it to textTyped with
+					// the keyboard layout applied, key codes only map to the
+					// American English layout
+					if !kb.TextEventsEnabled() {
+						if kb.IsToggleKeyOn(hid.KeyboardKeyCapsLock) {
+							input.InsertText(string(unicode.ToUpper(c)))
+						} else {
+							input.InsertText(string(c))
+						}
 					}
 				} else {
 					switch c {
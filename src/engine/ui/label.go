@@ -34,8 +34,11 @@ type labelData struct {
 	overrideMaxWidth     float32
 	fgColor              matrix.Color
 	bgColor              matrix.Color
+	shadow               matrix.Vec4
+	shadowColor          matrix.Color
 	justify              rendering.FontJustify
 	baseline             rendering.FontBaseline
+	direction            rendering.TextDirection
 	diffScore            int
 	runeShaderData       []*rendering.TextShaderData
 	runeDrawings         []rendering.Drawing
@@ -239,7 +242,8 @@ func (label *Label) renderText() {
 			host, renderText, 0, 0, 0, ld.fontSize,
 			renderMaxWidth, fg, bg, ld.justify,
 			ld.baseline, label.entity.Transform.WorldScale(),
-			true, false, ld.fontFace, ld.lineHeight, ld.letterSpacing, &host.Cameras.UI)
+			true, false, ld.fontFace, ld.lineHeight, ld.letterSpacing, ld.direction,
+			&host.Cameras.UI)
 		ld.runeShaderData = make([]*rendering.TextShaderData, len(ld.runeDrawings))
 		for i := range ld.runeDrawings {
 			rd := &ld.runeDrawings[i]
@@ -251,6 +255,8 @@ func (label *Label) renderText() {
 				rd.Material = host.FontCache().OpaqueMaterial(rd.Material)
 			}
 			ld.runeShaderData[i] = rd.ShaderData.(*rendering.TextShaderData)
+			ld.runeShaderData[i].Shadow = ld.shadow
+			ld.runeShaderData[i].ShadowColor = ld.shadowColor
 		}
 		for i := 0; i < len(ld.colorRanges); i++ {
 			label.colorRange(ld.colorRanges[i])
@@ -337,6 +343,8 @@ func (label *Label) updateColors() {
 	for i := range ld.runeShaderData {
 		ld.runeShaderData[i].FgColor = fg
 		ld.runeShaderData[i].BgColor = bg
+		ld.runeShaderData[i].Shadow = ld.shadow
+		ld.runeShaderData[i].ShadowColor = ld.shadowColor
 	}
 }
 
@@ -468,6 +476,30 @@ func (label *Label) SetBGColor(newColor matrix.Color) {
 	label.Base().SetDirty(DirtyTypeColorChange)
 }
 
+func (label *Label) TextShadow() (offset matrix.Vec2, blur float32, color matrix.Color) {
+	ld := label.LabelData()
+	return matrix.NewVec2(ld.shadow.X(), ld.shadow.Y()), ld.shadow.Z(), ld.shadowColor
+}
+
+// SetTextShadow draws a shadow behind the glyphs of the label. The shadow is
+// sampled from the glyph atlas, so the offset and blur can't reach further
+// than the padding around each glyph in the atlas.
+func (label *Label) SetTextShadow(offset matrix.Vec2, blur float32, color matrix.Color) {
+	ld := label.LabelData()
+	shadow := matrix.NewVec4(offset.X(), offset.Y(), max(0, blur), 0)
+	if ld.shadow.Equals(shadow) && ld.shadowColor.Equals(color) {
+		return
+	}
+	ld.shadow = shadow
+	ld.shadowColor = color
+	label.updateColors()
+	label.Base().SetDirty(DirtyTypeColorChange)
+}
+
+func (label *Label) ClearTextShadow() {
+	label.SetTextShadow(matrix.Vec2Zero(), 0, matrix.Color{})
+}
+
 // SetTransparentBackground enables opaque cutout text: pixels outside glyphs
 // are discarded while glyph pixels are written through the opaque text pass.
 // By default, transparent backgrounds are instead resolved to the parent's
@@ -493,6 +525,20 @@ func (label *Label) SetJustify(justify rendering.FontJustify) {
 
 func (label *Label) Justify() rendering.FontJustify { return label.LabelData().justify }
 
+// SetDirection sets the base direction the text is laid out in, by default it
+// is picked for each paragraph from the text itself
+func (label *Label) SetDirection(direction rendering.TextDirection) {
+	ld := label.LabelData()
+	if ld.direction == direction {
+		return
+	}
+	ld.direction = direction
+	ld.renderRequired = true
+	label.Base().SetDirty(DirtyTypeGenerated)
+}
+
+func (label *Label) Direction() rendering.TextDirection { return label.LabelData().direction }
+
 func (label *Label) SetBaseline(baseline rendering.FontBaseline) {
 	ld := label.LabelData()
 	if ld.baseline == baseline {
@@ -691,6 +737,7 @@ func (label *Label) Clone(to *Label) {
 	to.SetTransparentBackground(ld.transparentBG)
 	to.SetJustify(ld.justify)
 	to.SetBaseline(ld.baseline)
+	to.SetDirection(ld.direction)
 	// TODO:  Set font face?
 	to.SetWrap(ld.wordWrap)
 }
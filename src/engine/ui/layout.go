@@ -33,6 +33,23 @@ const (
 	FlexAlignStretch
 )
 
+// GridLine is one edge of a grid item's placement (grid-row-start and
+// friends). The zero value is auto.
+type GridLine struct {
+	// Line is the 1-based grid line to use, negative values count back from
+	// the last line of the explicit grid. With a Name it is the nth line with
+	// that name instead, and with Span it is the number of tracks to cover.
+	Line int
+	// Name is a named line, or a named area when used without a Line, in
+	// which case the implicit <name>-start or <name>-end line is used
+	Name string
+	// Span makes this edge relative to the opposite edge of the placement
+	Span bool
+}
+
+// IsAuto reports if this line is left to the auto-placement algorithm
+func (g GridLine) IsAuto() bool { return g.Line == 0 && g.Name == "" }
+
 type Layout struct {
 	offset           matrix.Vec2
 	rowLayoutOffset  matrix.Vec2
@@ -43,10 +60,10 @@ type Layout struct {
 	border           matrix.Vec4
 	padding          matrix.Vec4
 	margin           matrix.Vec4
-	gridRowStart     int
-	gridRowEnd       int
-	gridColumnStart  int
-	gridColumnEnd    int
+	gridRowStart     GridLine
+	gridRowEnd       GridLine
+	gridColumnStart  GridLine
+	gridColumnEnd    GridLine
 	flexGrow         float32
 	flexShrink       float32
 	flexBasis        float32
@@ -54,6 +71,7 @@ type Layout struct {
 	flexBasisPercent bool
 	flexOrder        int
 	alignSelf        FlexAlign
+	justifySelf      FlexAlign
 	positioning      Positioning
 	Stylizer         LayoutStylizer
 	runningStylizer  bool
@@ -78,10 +96,10 @@ func (l *Layout) ClearStyles() {
 	l.border = matrix.Vec4{}
 	l.padding = matrix.Vec4{}
 	l.margin = matrix.Vec4{}
-	l.gridRowStart = 0
-	l.gridRowEnd = 0
-	l.gridColumnStart = 0
-	l.gridColumnEnd = 0
+	l.gridRowStart = GridLine{}
+	l.gridRowEnd = GridLine{}
+	l.gridColumnStart = GridLine{}
+	l.gridColumnEnd = GridLine{}
 	l.flexGrow = 0
 	l.flexShrink = 1
 	l.flexBasis = 0
@@ -89,6 +107,7 @@ func (l *Layout) ClearStyles() {
 	l.flexBasisPercent = false
 	l.flexOrder = 0
 	l.alignSelf = FlexAlignAuto
+	l.justifySelf = FlexAlignAuto
 	l.positioning = PositioningStatic
 }
 
@@ -259,10 +278,6 @@ func (l *Layout) Border() matrix.Vec4      { return l.border }
 func (l *Layout) Padding() matrix.Vec4     { return l.padding }
 func (l *Layout) Margin() matrix.Vec4      { return l.margin }
 func (l *Layout) Offset() matrix.Vec2      { return matrix.Vec2{l.offset.X(), l.offset.Y()} }
-func (l *Layout) GridRowStart() int        { return l.gridRowStart }
-func (l *Layout) GridRowEnd() int          { return l.gridRowEnd }
-func (l *Layout) GridColumnStart() int     { return l.gridColumnStart }
-func (l *Layout) GridColumnEnd() int       { return l.gridColumnEnd }
 func (l *Layout) FlexGrow() float32        { return l.flexGrow }
 func (l *Layout) FlexShrink() float32      { return l.flexShrink }
 func (l *Layout) FlexBasis() float32       { return l.flexBasis }
@@ -270,6 +285,12 @@ func (l *Layout) FlexBasisAuto() bool      { return l.flexBasisAuto }
 func (l *Layout) FlexBasisPercent() bool   { return l.flexBasisPercent }
 func (l *Layout) FlexOrder() int           { return l.flexOrder }
 func (l *Layout) AlignSelf() FlexAlign     { return l.alignSelf }
+func (l *Layout) JustifySelf() FlexAlign   { return l.justifySelf }
+
+func (l *Layout) GridRowStart() GridLine    { return l.gridRowStart }
+func (l *Layout) GridRowEnd() GridLine      { return l.gridRowEnd }
+func (l *Layout) GridColumnStart() GridLine { return l.gridColumnStart }
+func (l *Layout) GridColumnEnd() GridLine   { return l.gridColumnEnd }
 
 func (l *Layout) SetFlexGrow(grow float32) {
 	if grow < 0 {
@@ -332,13 +353,27 @@ func (l *Layout) SetAlignSelf(align FlexAlign) {
 	l.ui.layoutChanged(DirtyTypeLayout)
 }
 
-func (l *Layout) SetGridRow(start, end int) {
-	if start < 0 {
-		start = 0
-	}
-	if end < 0 {
-		end = 0
+func (l *Layout) SetJustifySelf(align FlexAlign) {
+	if l.justifySelf == align {
+		return
 	}
+	l.justifySelf = align
+	l.ui.layoutChanged(DirtyTypeLayout)
+}
+
+// SetGridRow places the element between two numbered grid row lines, zero
+// for either line leaves that edge to auto placement
+func (l *Layout) SetGridRow(start, end int) {
+	l.SetGridRowLines(GridLine{Line: max(0, start)}, GridLine{Line: max(0, end)})
+}
+
+// SetGridColumn places the element between two numbered grid column lines,
+// zero for either line leaves that edge to auto placement
+func (l *Layout) SetGridColumn(start, end int) {
+	l.SetGridColumnLines(GridLine{Line: max(0, start)}, GridLine{Line: max(0, end)})
+}
+
+func (l *Layout) SetGridRowLines(start, end GridLine) {
 	if l.gridRowStart == start && l.gridRowEnd == end {
 		return
 	}
@@ -347,13 +382,7 @@ func (l *Layout) SetGridRow(start, end int) {
 	l.ui.layoutChanged(DirtyTypeLayout)
 }
 
-func (l *Layout) SetGridColumn(start, end int) {
-	if start < 0 {
-		start = 0
-	}
-	if end < 0 {
-		end = 0
-	}
+func (l *Layout) SetGridColumnLines(start, end GridLine) {
 	if l.gridColumnStart == start && l.gridColumnEnd == end {
 		return
 	}
@@ -540,6 +569,7 @@ func (l *Layout) initialize(ui *UI) {
 	l.flexShrink = 1
 	l.flexBasisAuto = true
 	l.alignSelf = FlexAlignAuto
+	l.justifySelf = FlexAlignAuto
 	//l.prepare()
 	//l.update()
 }
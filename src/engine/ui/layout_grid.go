@@ -0,0 +1,476 @@
+/******************************************************************************/
+/* layout_grid.go                                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"slices"
+)
+
+type GridAutoFlow = int
+
+const (
+	GridAutoFlowRow = GridAutoFlow(iota)
+	GridAutoFlowColumn
+)
+
+// defaultGridColumns is used by display:grid when nothing else describes
+// the columns of the explicit grid
+const defaultGridColumns = 3
+
+type gridLayoutItem struct {
+	ui          *UI
+	row         int
+	col         int
+	rowSpan     int
+	colSpan     int
+	rowDefinite bool
+	colDefinite bool
+}
+
+// gridAxis describes the explicit tracks along one axis of a grid container
+type gridAxis struct {
+	// tracks are the explicit track sizes, positive values are fixed pixel
+	// sizes, negative values are fr units and 0 sizes the track to fit the
+	// items placed in it
+	tracks []float32
+	// lineNames holds the names of every explicit line, index 0 is line 1
+	lineNames [][]string
+	// autoSize is the size of implicit tracks, using the same encoding
+	autoSize float32
+}
+
+type gridTrackItem struct {
+	start int
+	span  int
+	size  float32
+}
+
+// gridTemplateAreaColumns returns the number of columns described by the
+// grid-template-areas of the panel
+func (pd *panelData) gridTemplateAreaColumns() int {
+	if len(pd.gridTemplateAreas) == 0 {
+		return 0
+	}
+	return len(pd.gridTemplateAreas[0])
+}
+
+// explicitGridColumns is the number of columns in the explicit grid, which
+// is the larger of the template columns and template areas
+func (pd *panelData) explicitGridColumns() int {
+	cols := max(len(pd.gridTemplateColumns), pd.gridTemplateAreaColumns(), pd.gridColumns)
+	if cols <= 0 {
+		cols = defaultGridColumns
+	}
+	return cols
+}
+
+func (pd *panelData) gridAxes() (columns, rows gridAxis) {
+	colCount := pd.explicitGridColumns()
+	columns.tracks = make([]float32, colCount)
+	for i := range columns.tracks {
+		switch {
+		case i < len(pd.gridTemplateColumns):
+			columns.tracks[i] = pd.gridTemplateColumns[i]
+		case len(pd.gridTemplateColumns) == 0:
+			// Without a column template the explicit columns share the
+			// available width evenly, as if each were 1fr
+			columns.tracks[i] = -1
+		default:
+			columns.tracks[i] = pd.gridAutoColumns
+		}
+	}
+	rowCount := max(len(pd.gridTemplateRows), len(pd.gridTemplateAreas))
+	rows.tracks = make([]float32, rowCount)
+	for i := range rows.tracks {
+		if i < len(pd.gridTemplateRows) {
+			rows.tracks[i] = pd.gridTemplateRows[i]
+		} else {
+			rows.tracks[i] = pd.gridAutoRows
+		}
+	}
+	columns.lineNames = gridLineNames(pd.gridColumnLineNames, colCount)
+	rows.lineNames = gridLineNames(pd.gridRowLineNames, rowCount)
+	// Every named area implicitly names the lines around it
+	for y := range pd.gridTemplateAreas {
+		for x, name := range pd.gridTemplateAreas[y] {
+			if name == "" {
+				continue
+			}
+			if x == 0 || pd.gridTemplateAreas[y][x-1] != name {
+				columns.addLineName(x, name+"-start")
+			}
+			if x == len(pd.gridTemplateAreas[y])-1 || pd.gridTemplateAreas[y][x+1] != name {
+				columns.addLineName(x+1, name+"-end")
+			}
+			if y == 0 || pd.gridTemplateAreas[y-1][x] != name {
+				rows.addLineName(y, name+"-start")
+			}
+			if y == len(pd.gridTemplateAreas)-1 || pd.gridTemplateAreas[y+1][x] != name {
+				rows.addLineName(y+1, name+"-end")
+			}
+		}
+	}
+	columns.autoSize = pd.gridAutoColumns
+	rows.autoSize = pd.gridAutoRows
+	return columns, rows
+}
+
+func gridLineNames(names [][]string, trackCount int) [][]string {
+	out := make([][]string, trackCount+1)
+	for i := range out {
+		if i < len(names) {
+			out[i] = slices.Clone(names[i])
+		}
+	}
+	return out
+}
+
+func (a *gridAxis) addLineName(line int, name string) {
+	if !slices.Contains(a.lineNames[line], name) {
+		a.lineNames[line] = append(a.lineNames[line], name)
+	}
+}
+
+// namedLine finds the 0-based index of the nth line (counting from the end
+// when n is negative) with the given name. Like CSS, when there are not
+// enough named lines the implicit lines past the explicit grid are all
+// assumed to have the name.
+func (a *gridAxis) namedLine(name string, n int) int {
+	if n == 0 {
+		n = 1
+	}
+	if n > 0 {
+		found := 0
+		for i := range a.lineNames {
+			if slices.Contains(a.lineNames[i], name) {
+				if found++; found == n {
+					return i
+				}
+			}
+		}
+		return len(a.tracks) + n - found
+	}
+	found := 0
+	for i := len(a.lineNames) - 1; i >= 0; i-- {
+		if slices.Contains(a.lineNames[i], name) {
+			if found--; found == n {
+				return i
+			}
+		}
+	}
+	// Lines before the start of the explicit grid are clamped to it
+	return 0
+}
+
+func (a *gridAxis) hasLineName(name string) bool {
+	for i := range a.lineNames {
+		if slices.Contains(a.lineNames[i], name) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineIndex resolves a non-span grid line to a 0-based line index. The
+// suffix is "-start" or "-end" depending on which edge is being resolved.
+func (a *gridAxis) lineIndex(line GridLine, suffix string) (int, bool) {
+	if line.Span || line.IsAuto() {
+		return 0, false
+	}
+	if line.Name != "" {
+		if line.Line == 0 && a.hasLineName(line.Name+suffix) {
+			return a.namedLine(line.Name+suffix, 1), true
+		}
+		return a.namedLine(line.Name, line.Line), true
+	}
+	if line.Line > 0 {
+		return line.Line - 1, true
+	}
+	// Negative lines count back from the last explicit line and anything
+	// before the first line is clamped to it
+	return max(0, len(a.tracks)+1+line.Line), true
+}
+
+// spanCount returns the number of tracks covered by a span line starting
+// at the given line index and moving forward (dir 1) or backward (dir -1)
+func (a *gridAxis) spanCount(line GridLine, from, dir int) int {
+	if line.Name == "" {
+		return max(1, line.Line)
+	}
+	n := max(1, line.Line)
+	found := 0
+	for i := from + dir; i >= 0 && i < len(a.lineNames); i += dir {
+		if slices.Contains(a.lineNames[i], line.Name) {
+			if found++; found == n {
+				return max(1, (i-from)*dir)
+			}
+		}
+	}
+	if dir < 0 {
+		return max(1, from)
+	}
+	return max(1, len(a.tracks)+n-found-from)
+}
+
+// resolve turns the start and end lines of an item into a 0-based track and
+// a span. When definite is false the track is chosen by auto-placement.
+func (a *gridAxis) resolve(start, end GridLine) (track, span int, definite bool) {
+	s, startOk := a.lineIndex(start, "-start")
+	e, endOk := a.lineIndex(end, "-end")
+	switch {
+	case startOk && endOk:
+		if e < s {
+			s, e = e, s
+		}
+		return s, max(1, e-s), true
+	case startOk:
+		if end.Span {
+			return s, a.spanCount(end, s, 1), true
+		}
+		return s, 1, true
+	case endOk:
+		span := 1
+		if start.Span {
+			span = a.spanCount(start, e, -1)
+		}
+		s = max(0, e-span)
+		return s, max(1, e-s), true
+	}
+	// A named span without a definite line to count from covers one track
+	span = 1
+	if start.Span && start.Name == "" {
+		span = max(1, start.Line)
+	} else if end.Span && end.Name == "" {
+		span = max(1, end.Line)
+	}
+	return 0, span, false
+}
+
+// gridOccupancy tracks which cells of the grid are taken while placing
+// items. Rows are the axis that grows with the auto-placement flow.
+type gridOccupancy struct {
+	columns int
+	cells   [][]bool
+}
+
+func (g *gridOccupancy) isFree(row, col, rowSpan, colSpan int) bool {
+	if col < 0 || col+colSpan > g.columns {
+		return false
+	}
+	for y := row; y < row+rowSpan && y < len(g.cells); y++ {
+		for x := col; x < col+colSpan; x++ {
+			if g.cells[y][x] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (g *gridOccupancy) occupy(row, col, rowSpan, colSpan int) {
+	for len(g.cells) < row+rowSpan {
+		g.cells = append(g.cells, make([]bool, g.columns))
+	}
+	for y := row; y < row+rowSpan; y++ {
+		for x := col; x < col+colSpan; x++ {
+			g.cells[y][x] = true
+		}
+	}
+}
+
+func (g *gridOccupancy) rows() int { return len(g.cells) }
+
+// placeGridItems runs the CSS grid auto-placement algorithm for a row flow
+// and returns the number of rows and columns of the resulting grid. Column
+// flows are placed by transposing the items before and after.
+func placeGridItems(items []gridLayoutItem, columns int, dense bool) (int, int) {
+	for i := range items {
+		it := &items[i]
+		if it.colDefinite {
+			columns = max(columns, it.col+it.colSpan)
+		} else {
+			columns = max(columns, it.colSpan)
+		}
+	}
+	grid := gridOccupancy{columns: max(1, columns)}
+	// Items that are locked to both a row and a column go first
+	for i := range items {
+		if it := &items[i]; it.rowDefinite && it.colDefinite {
+			grid.occupy(it.row, it.col, it.rowSpan, it.colSpan)
+		}
+	}
+	// Then items locked to a row, which only search along that row
+	rowCursors := map[int]int{}
+	for i := range items {
+		it := &items[i]
+		if !it.rowDefinite || it.colDefinite {
+			continue
+		}
+		col := 0
+		if !dense {
+			col = rowCursors[it.row]
+		}
+		for !grid.isFree(it.row, col, it.rowSpan, it.colSpan) {
+			if col+it.colSpan >= grid.columns {
+				// The row is full, overflow into new implicit columns
+				col = grid.columns
+				grid.columns += it.colSpan
+				for y := range grid.cells {
+					grid.cells[y] = append(grid.cells[y], make([]bool, it.colSpan)...)
+				}
+				break
+			}
+			col++
+		}
+		it.col = col
+		it.colDefinite = true
+		rowCursors[it.row] = col + it.colSpan
+		grid.occupy(it.row, it.col, it.rowSpan, it.colSpan)
+	}
+	// Finally everything else follows the auto-placement cursor
+	cursorRow, cursorCol := 0, 0
+	for i := range items {
+		it := &items[i]
+		if it.rowDefinite {
+			continue
+		}
+		if dense {
+			cursorRow, cursorCol = 0, 0
+		}
+		if it.colDefinite {
+			if it.col < cursorCol {
+				cursorRow++
+			}
+			if dense {
+				cursorRow = 0
+			}
+			for !grid.isFree(cursorRow, it.col, it.rowSpan, it.colSpan) {
+				cursorRow++
+			}
+			cursorCol = it.col
+		} else {
+			for {
+				if cursorCol+it.colSpan > grid.columns {
+					cursorRow++
+					cursorCol = 0
+				}
+				if grid.isFree(cursorRow, cursorCol, it.rowSpan, it.colSpan) {
+					break
+				}
+				cursorCol++
+			}
+			it.col = cursorCol
+		}
+		it.row = cursorRow
+		it.rowDefinite = true
+		it.colDefinite = true
+		grid.occupy(it.row, it.col, it.rowSpan, it.colSpan)
+		if !dense {
+			cursorCol = it.col + it.colSpan
+		}
+	}
+	return grid.rows(), grid.columns
+}
+
+func transposeGridItems(items []gridLayoutItem) {
+	for i := range items {
+		it := &items[i]
+		it.row, it.col = it.col, it.row
+		it.rowSpan, it.colSpan = it.colSpan, it.rowSpan
+		it.rowDefinite, it.colDefinite = it.colDefinite, it.rowDefinite
+	}
+}
+
+// sizeGridTracks resolves the pixel size of every track along an axis.
+// Fixed tracks keep their size, auto tracks grow to fit the items placed in
+// them and fr tracks share whatever space is left. When the available space
+// is not definite the fr tracks are sized like auto tracks.
+func sizeGridTracks(axis gridAxis, count int, available, gap float32, definite bool, items []gridTrackItem) []float32 {
+	sizes := make([]float32, count)
+	fr := make([]float32, count)
+	fixed := make([]bool, count)
+	totalFr := float32(0)
+	for i := range sizes {
+		v := axis.autoSize
+		if i < len(axis.tracks) {
+			v = axis.tracks[i]
+		}
+		switch {
+		case v < 0 && definite:
+			fr[i] = -v
+			totalFr += fr[i]
+		case v > 0:
+			sizes[i] = v
+			fixed[i] = true
+		}
+	}
+	growable := func(i int) bool { return !fixed[i] && fr[i] == 0 }
+	for _, it := range items {
+		if it.span == 1 && it.start < count && growable(it.start) {
+			sizes[it.start] = max(sizes[it.start], it.size)
+		}
+	}
+	// Items spanning several tracks only grow the last auto track they
+	// cover, and only by what the spanned tracks are missing
+	for _, it := range items {
+		if it.span <= 1 {
+			continue
+		}
+		end := min(count, it.start+it.span)
+		need := it.size - gap*float32(end-it.start-1)
+		last := -1
+		for i := it.start; i < end; i++ {
+			need -= sizes[i]
+			if growable(i) {
+				last = i
+			}
+		}
+		if need > 0 && last >= 0 {
+			sizes[last] += need
+		}
+	}
+	if totalFr > 0 {
+		free := available - gap*float32(max(0, count-1))
+		for i := range sizes {
+			free -= sizes[i]
+		}
+		free = max(0, free)
+		for i := range sizes {
+			if fr[i] > 0 {
+				sizes[i] = free * fr[i] / totalFr
+			}
+		}
+	}
+	return sizes
+}
+
+// gridTrackOffsets returns the start of every track relative to the first
+func gridTrackOffsets(sizes []float32, gap float32) []float32 {
+	offsets := make([]float32, len(sizes)+1)
+	for i := range sizes {
+		offsets[i+1] = offsets[i] + sizes[i] + gap
+	}
+	return offsets
+}
+
+// gridSpanSize is the size of the area covering span tracks from start
+func gridSpanSize(offsets []float32, start, span int, gap float32) float32 {
+	return offsets[start+span] - offsets[start] - gap
+}
+
+// gridAlignOffset returns where an item of the given outer size starts
+// within a cell of the given size
+func gridAlignOffset(cell, size float32, align FlexAlign) float32 {
+	switch align {
+	case FlexAlignEnd:
+		return cell - size
+	case FlexAlignCenter:
+		return (cell - size) * 0.5
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,201 @@
+/******************************************************************************/
+/* layout_grid_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+// buildGrid creates a fixed size grid container with the given number of
+// fixed size children
+func buildGrid(w, h float32, kids int, kidW, kidH float32) (*UI, []*UI) {
+	grid := buildPanel(ElementTypePanel, ContentFitNone, w, h)
+	grid.ToPanel().SetGrid(0)
+	children := make([]*UI, kids)
+	for i := range children {
+		children[i] = buildPanel(ElementTypePanel, ContentFitNone, kidW, kidH)
+		grid.ToPanel().AddChild(children[i])
+	}
+	return grid, children
+}
+
+func expectGridOffset(t *testing.T, name string, u *UI, x, y float32) {
+	t.Helper()
+	if got := u.Layout().CalcOffset(); !matrix.Vec2ApproxTo(got, matrix.NewVec2(x, y), 0.01) {
+		t.Fatalf("%s offset = %v, want [%g %g]", name, got, x, y)
+	}
+}
+
+func TestGridTemplateAreas(t *testing.T) {
+	t.Parallel()
+	grid, kids := buildGrid(300, 200, 3, 10, 10)
+	panel := grid.ToPanel()
+	panel.SetGridTemplateColumns([]float32{100, -1})
+	panel.SetGridTemplateRows([]float32{40, -1})
+	panel.SetGridTemplateAreas([][]string{
+		{"head", "head"},
+		{"nav", "main"},
+	})
+	panel.SetGridAlignItems(FlexAlignStretch)
+	panel.SetGridJustifyItems(FlexAlignStretch)
+	area := func(u *UI, name string) {
+		u.Layout().SetGridRowLines(GridLine{Name: name}, GridLine{Name: name})
+		u.Layout().SetGridColumnLines(GridLine{Name: name}, GridLine{Name: name})
+	}
+	// Added in reverse so the placement can't come from document order
+	area(kids[0], "main")
+	area(kids[1], "nav")
+	area(kids[2], "head")
+	// Set last since the template setters fall back to the default gap
+	panel.SetGridGap(0, 0)
+	runLayout(grid, 3)
+	expectGridOffset(t, "main", kids[0], 100, 40)
+	expectGridOffset(t, "nav", kids[1], 0, 40)
+	expectGridOffset(t, "head", kids[2], 0, 0)
+	if got := kids[2].Layout().PixelSize(); got.X() != 300 || got.Y() != 40 {
+		t.Fatalf("head size = %v, want [300 40]", got)
+	}
+	if got := kids[0].Layout().PixelSize(); got.X() != 200 || got.Y() != 160 {
+		t.Fatalf("main size = %v, want [200 160]", got)
+	}
+}
+
+func TestGridNamedLinesAndSpans(t *testing.T) {
+	t.Parallel()
+	grid, kids := buildGrid(400, 100, 3, 10, 10)
+	panel := grid.ToPanel()
+	panel.SetGridTemplateColumns([]float32{100, 100, 100, 100})
+	panel.SetGridColumnLineNames([][]string{{"full-start"}, {"col"}, {"col"}, nil, {"full-end"}})
+	// From the second line named col to the last line
+	kids[0].Layout().SetGridColumnLines(GridLine{Name: "col", Line: 2}, GridLine{Line: -1})
+	// Span back from full-end by 3 tracks
+	kids[1].Layout().SetGridColumnLines(GridLine{Span: true, Line: 3}, GridLine{Name: "full-end"})
+	kids[1].Layout().SetGridRow(2, 0)
+	// Span up to the next line named col
+	kids[2].Layout().SetGridColumnLines(GridLine{Name: "full-start"}, GridLine{Span: true, Name: "col"})
+	// Set last since the template setters fall back to the default gap
+	panel.SetGridGap(0, 0)
+	runLayout(grid, 2)
+	expectGridOffset(t, "second col to end", kids[0], 200, 0)
+	expectGridOffset(t, "span to full-end", kids[1], 100, 10)
+	// The auto placement cursor already passed column 1 on the first row
+	expectGridOffset(t, "span to col", kids[2], 0, 10)
+	columns, _ := panel.PanelData().gridAxes()
+	if track, span, _ := columns.resolve(kids[1].Layout().GridColumnStart(), kids[1].Layout().GridColumnEnd()); track != 1 || span != 3 {
+		t.Fatalf("span 3 / full-end resolved to %d span %d, want 1 span 3", track, span)
+	}
+	if _, span, _ := columns.resolve(kids[2].Layout().GridColumnStart(), kids[2].Layout().GridColumnEnd()); span != 1 {
+		t.Fatalf("full-start / span col covered %d tracks, want 1", span)
+	}
+}
+
+func TestGridAutoFlowDense(t *testing.T) {
+	t.Parallel()
+	items := func() []gridLayoutItem {
+		return []gridLayoutItem{
+			{rowSpan: 1, colSpan: 1},
+			{rowSpan: 1, colSpan: 3},
+			{rowSpan: 1, colSpan: 1},
+		}
+	}
+	sparse := items()
+	rows, cols := placeGridItems(sparse, 3, false)
+	if rows != 3 || cols != 3 {
+		t.Fatalf("sparse grid is %dx%d, want 3x3", rows, cols)
+	}
+	if sparse[2].row != 2 || sparse[2].col != 0 {
+		t.Fatalf("sparse item placed at %d,%d, want 2,0", sparse[2].row, sparse[2].col)
+	}
+	dense := items()
+	rows, _ = placeGridItems(dense, 3, true)
+	if rows != 2 {
+		t.Fatalf("dense grid has %d rows, want 2", rows)
+	}
+	if dense[2].row != 0 || dense[2].col != 1 {
+		t.Fatalf("dense item placed at %d,%d, want 0,1", dense[2].row, dense[2].col)
+	}
+}
+
+func TestGridLockedPlacement(t *testing.T) {
+	t.Parallel()
+	items := []gridLayoutItem{
+		{rowSpan: 1, colSpan: 1},
+		{row: 0, col: 0, rowSpan: 1, colSpan: 1, rowDefinite: true, colDefinite: true},
+		{row: 1, rowSpan: 1, colSpan: 2, rowDefinite: true},
+		{col: 4, rowSpan: 1, colSpan: 1, colDefinite: true},
+	}
+	rows, cols := placeGridItems(items, 2, false)
+	if cols != 5 {
+		t.Fatalf("implicit columns = %d, want 5", cols)
+	}
+	if items[0].row != 0 || items[0].col != 1 {
+		t.Fatalf("auto item placed at %d,%d, want 0,1", items[0].row, items[0].col)
+	}
+	if items[2].row != 1 || items[2].col != 0 {
+		t.Fatalf("row locked item placed at %d,%d, want 1,0", items[2].row, items[2].col)
+	}
+	if items[3].row != 0 || items[3].col != 4 || rows != 2 {
+		t.Fatalf("column locked item placed at %d,%d with %d rows", items[3].row, items[3].col, rows)
+	}
+}
+
+func TestGridAutoFlowColumn(t *testing.T) {
+	t.Parallel()
+	grid, kids := buildGrid(300, 100, 3, 10, 10)
+	panel := grid.ToPanel()
+	panel.SetGridTemplateColumns([]float32{50, 50})
+	panel.SetGridTemplateRows([]float32{20, 20})
+	panel.SetGridAutoFlow(GridAutoFlowColumn, false)
+	// Set last since the template setters fall back to the default gap
+	panel.SetGridGap(0, 0)
+	runLayout(grid, 2)
+	expectGridOffset(t, "first", kids[0], 0, 0)
+	expectGridOffset(t, "second", kids[1], 0, 20)
+	expectGridOffset(t, "third", kids[2], 50, 0)
+}
+
+func TestGridAlignment(t *testing.T) {
+	t.Parallel()
+	grid, kids := buildGrid(200, 100, 3, 20, 10)
+	panel := grid.ToPanel()
+	panel.SetGridTemplateColumns([]float32{100, 100})
+	panel.SetGridTemplateRows([]float32{50, 50})
+	panel.SetGridJustifyItems(FlexAlignCenter)
+	kids[1].Layout().SetJustifySelf(FlexAlignEnd)
+	kids[1].Layout().SetAlignSelf(FlexAlignCenter)
+	kids[2].Layout().SetAlignSelf(FlexAlignEnd)
+	// Set last since the template setters fall back to the default gap
+	panel.SetGridGap(0, 0)
+	runLayout(grid, 2)
+	expectGridOffset(t, "centered", kids[0], 40, 0)
+	expectGridOffset(t, "end center", kids[1], 180, 20)
+	expectGridOffset(t, "center end", kids[2], 40, 90)
+}
+
+func TestGridTrackSizing(t *testing.T) {
+	t.Parallel()
+	axis := gridAxis{tracks: []float32{50, 0, -1, -3}}
+	items := []gridTrackItem{
+		{start: 1, span: 1, size: 30},
+		// Only grows the auto track by what the spanned tracks are missing
+		{start: 0, span: 2, size: 100},
+	}
+	sizes := sizeGridTracks(axis, 4, 500, 10, true, items)
+	want := []float32{50, 40, 95, 285}
+	for i := range want {
+		if !matrix.Approx(sizes[i], want[i]) {
+			t.Fatalf("track sizes = %v, want %v", sizes, want)
+		}
+	}
+	// Without a definite size the fr tracks fit their content
+	sizes = sizeGridTracks(axis, 4, 0, 10, false, []gridTrackItem{{start: 3, span: 1, size: 12}})
+	if sizes[2] != 0 || sizes[3] != 12 {
+		t.Fatalf("indefinite fr sizes = %v", sizes)
+	}
+}
@@ -0,0 +1,200 @@
+/******************************************************************************/
+/* custom_properties.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package css
+
+import (
+	"maps"
+	"slices"
+
+	"kaijuengine.com/engine/ui/markup/css/helpers"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+	"kaijuengine.com/engine/ui/markup/document"
+)
+
+type customPropertyScope = map[string][]string
+
+// customPropertyResolver computes the custom properties in scope of each
+// element. An element inherits the scope of its parent and only creates a new
+// scope if it declares custom properties itself, so most elements share the
+// scope of the document.
+type customPropertyResolver struct {
+	global customPropertyScope
+	cssMap CSSMap
+	scopes map[*document.Element]customPropertyScope
+}
+
+func newCustomPropertyResolver(s *rules.StyleSheet, doc *document.Document, cssMap CSSMap) customPropertyResolver {
+	global := s.CustomVars
+	if overrides := doc.CustomProperties(); len(overrides) > 0 {
+		global = maps.Clone(global)
+		for k, v := range overrides {
+			global[k] = []string{v}
+		}
+	}
+	return customPropertyResolver{
+		global: expandScope(global, nil),
+		cssMap: cssMap,
+		scopes: make(map[*document.Element]customPropertyScope, len(doc.Elements)),
+	}
+}
+
+// expandScope expands the var() used within the custom property values so
+// that the scope holds final values, var() within a value refers to the scope
+// that the value is declared in (falling back to the parent scope)
+func expandScope(scope, parent customPropertyScope) customPropertyScope {
+	lookup := func(name string) ([]string, bool) {
+		if v, ok := scope[name]; ok {
+			return v, true
+		}
+		v, ok := parent[name]
+		return v, ok
+	}
+	var out customPropertyScope
+	for k, v := range scope {
+		for i := range v {
+			expanded := rules.ExpandCustomProperty(k, v[i], lookup)
+			if expanded == v[i] {
+				continue
+			}
+			if out == nil {
+				out = maps.Clone(scope)
+			}
+			vals := slices.Clone(out[k])
+			vals[i] = expanded
+			out[k] = vals
+		}
+	}
+	if out == nil {
+		return scope
+	}
+	return out
+}
+
+func (r *customPropertyResolver) scope(elm *document.Element) customPropertyScope {
+	if elm == nil {
+		return r.global
+	}
+	if s, ok := r.scopes[elm]; ok {
+		return s
+	}
+	parent := r.scope(elm.Parent.Value())
+	own := customPropertyScope(nil)
+	for _, rule := range r.cssMap[elm.UI] {
+		if rule.IsCustomProperty() && rule.Invocation == rules.RuleInvokeImmediate {
+			if own == nil {
+				own = make(customPropertyScope)
+			}
+			own[rule.Property] = ruleStrings(rule)
+		}
+	}
+	scope := parent
+	if own != nil {
+		own = expandScope(own, parent)
+		scope = maps.Clone(parent)
+		maps.Copy(scope, own)
+	}
+	r.scopes[elm] = scope
+	return scope
+}
+
+func ruleStrings(rule rules.Rule) []string {
+	out := make([]string, len(rule.Values))
+	for i := range rule.Values {
+		out[i] = rule.Values[i].Str
+	}
+	return out
+}
+
+func scopeLookup(scope customPropertyScope) rules.VarLookup {
+	return func(name string) ([]string, bool) {
+		v, ok := scope[name]
+		return v, ok
+	}
+}
+
+// resolveCustomProperties resolves the var() of every rule against the custom
+// properties in scope of the element it applies to. The custom property rules
+// themselves are removed from the map as they aren't style properties.
+//
+// Custom properties declared for a state (like :hover) apply to the rules of
+// the same element, this is done by adding a copy of each rule that uses them
+// for that state.
+func resolveCustomProperties(s *rules.StyleSheet, doc *document.Document, cssMap CSSMap, window helpers.WindowDimensions) {
+	resolver := newCustomPropertyResolver(s, doc, cssMap)
+	for _, elm := range doc.Elements {
+		scope := resolver.scope(elm)
+		elm.SetComputedCustomProperties(scope)
+		list, ok := cssMap[elm.UI]
+		if !ok {
+			continue
+		}
+		stateScopes := map[rules.RuleInvoke]customPropertyScope{}
+		for _, rule := range list {
+			if rule.IsCustomProperty() && rule.Invocation != rules.RuleInvokeImmediate {
+				if stateScopes[rule.Invocation] == nil {
+					stateScopes[rule.Invocation] = make(customPropertyScope)
+				}
+				stateScopes[rule.Invocation][rule.Property] = ruleStrings(rule)
+			}
+		}
+		out := make([]rules.Rule, 0, len(list))
+		for _, rule := range list {
+			if rule.IsCustomProperty() {
+				continue
+			}
+			if rule.VarSource == nil {
+				out = append(out, rule)
+				continue
+			}
+			if rule.Invocation != rules.RuleInvokeImmediate {
+				rules.ResolveRuleVars(&rule, scopeLookup(stateScope(scope, stateScopes, rule.Invocation)), window)
+				out = append(out, rule)
+				continue
+			}
+			rules.ResolveRuleVars(&rule, scopeLookup(scope), window)
+			out = append(out, rule)
+			for _, state := range slices.Sorted(maps.Keys(stateScopes)) {
+				own := stateScopes[state]
+				if !rules.RuleReferencesVar(&rule, own) || hasStateRule(list, rule.Property, state) {
+					continue
+				}
+				stateRule := rule.Clone()
+				stateRule.Invocation = state
+				rules.ResolveRuleVars(&stateRule, scopeLookup(stateScope(scope, stateScopes, state)), window)
+				out = append(out, stateRule)
+			}
+		}
+		cssMap[elm.UI] = out
+	}
+}
+
+// stateScope overlays the custom properties declared for the given state (and
+// any state it is composed of, such as :hover within :hover:active)
+func stateScope(scope customPropertyScope, stateScopes map[rules.RuleInvoke]customPropertyScope, state rules.RuleInvoke) customPropertyScope {
+	var out customPropertyScope
+	for _, st := range slices.Sorted(maps.Keys(stateScopes)) {
+		if st.Matches(state) {
+			if out == nil {
+				out = maps.Clone(scope)
+			}
+			maps.Copy(out, expandScope(stateScopes[st], scope))
+		}
+	}
+	if out == nil {
+		return scope
+	}
+	return out
+}
+
+func hasStateRule(list []rules.Rule, property string, state rules.RuleInvoke) bool {
+	for i := range list {
+		if list[i].Invocation == state && list[i].Property == property {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+/******************************************************************************/
+/* custom_properties_test.go                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package css
+
+import (
+	"testing"
+	"weak"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+	"kaijuengine.com/engine/ui/markup/document"
+)
+
+type testCustomPropertyWindow struct{}
+
+func (testCustomPropertyWindow) DotsPerMillimeter() float64 { return 1 }
+func (testCustomPropertyWindow) Width() int                 { return 0 }
+func (testCustomPropertyWindow) Height() int                { return 0 }
+
+func testCustomPropertyDocument() (*document.Document, []*document.Element) {
+	doc := &document.Document{}
+	var parent *document.Element
+	for range 3 {
+		elm := &document.Element{UI: &ui.UI{}}
+		if parent != nil {
+			elm.Parent = weak.Make(parent)
+			parent.Children = append(parent.Children, elm)
+		}
+		doc.Elements = append(doc.Elements, elm)
+		parent = elm
+	}
+	return doc, doc.Elements
+}
+
+func testRuleValue(t *testing.T, list []rules.Rule, property string, invoke rules.RuleInvoke) string {
+	t.Helper()
+	for i := range list {
+		if list[i].Property == property && list[i].Invocation == invoke {
+			return list[i].Values[0].Str
+		}
+	}
+	t.Fatalf("expected a %s rule for invocation %d in %#v", property, invoke, list)
+	return ""
+}
+
+func TestResolveCustomPropertiesInheritance(t *testing.T) {
+	s := rules.NewStyleSheet()
+	s.Parse(`:root { --accent: red; --base: 1px; --pad: 0; }
+		.a { color: var(--accent); }`, testCustomPropertyWindow{})
+	colorRule := s.Groups[1].Rules[0]
+	inline := s.ParseInline("--accent: blue; --pad: calc(var(--base) + 2px)", testCustomPropertyWindow{})
+	doc, elms := testCustomPropertyDocument()
+	cssMap := CSSMap{}
+	cssMap.add(elms[0].UI, []rules.Rule{colorRule})
+	cssMap.add(elms[1].UI, inline.Rules)
+	cssMap.add(elms[2].UI, []rules.Rule{colorRule})
+	resolveCustomProperties(&s, doc, cssMap, testCustomPropertyWindow{})
+	if v := testRuleValue(t, cssMap[elms[0].UI], "color", rules.RuleInvokeImmediate); v != "red" {
+		t.Fatalf("expected the root element to use the :root value, got %q", v)
+	}
+	if v := testRuleValue(t, cssMap[elms[2].UI], "color", rules.RuleInvokeImmediate); v != "blue" {
+		t.Fatalf("expected the child to inherit the parent's value, got %q", v)
+	}
+	if len(cssMap[elms[1].UI]) != 0 {
+		t.Fatalf("expected the custom property rules to be removed, got %#v", cssMap[elms[1].UI])
+	}
+	if v, _ := elms[2].CustomProperty("--pad"); v != "calc(1px + 2px)" {
+		t.Fatalf("expected --pad to build on the inherited --base, got %q", v)
+	}
+	if _, ok := elms[0].CustomProperty("--missing"); ok {
+		t.Fatal("expected an unknown custom property to not be found")
+	}
+}
+
+func TestResolveCustomPropertiesDocumentOverride(t *testing.T) {
+	s := rules.NewStyleSheet()
+	s.Parse(`:root { --accent: red; }
+		.a { color: var(--accent); }
+		.a:hover { --accent: green; }`, testCustomPropertyWindow{})
+	doc, elms := testCustomPropertyDocument()
+	doc.SetCustomPropertyWithoutApply(nil, "--accent", "yellow")
+	hover := rules.CloneRules(s.Groups[2].Rules)
+	for i := range hover {
+		hover[i].Invocation = rules.RuleInvokeHover
+	}
+	cssMap := CSSMap{}
+	cssMap.add(elms[0].UI, s.Groups[1].Rules)
+	cssMap.add(elms[0].UI, hover)
+	resolveCustomProperties(&s, doc, cssMap, testCustomPropertyWindow{})
+	if v := testRuleValue(t, cssMap[elms[0].UI], "color", rules.RuleInvokeImmediate); v != "yellow" {
+		t.Fatalf("expected the document override to win over :root, got %q", v)
+	}
+	if v := testRuleValue(t, cssMap[elms[0].UI], "color", rules.RuleInvokeHover); v != "green" {
+		t.Fatalf("expected a hover color from the hover custom property, got %q", v)
+	}
+}
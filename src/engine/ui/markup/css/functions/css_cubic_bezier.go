@@ -7,13 +7,24 @@
 package functions
 
 import (
-	"errors"
+	"fmt"
+	"strings"
 
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// Process validates the control points and returns the function in a
+// normalized form, the timing itself is evaluated by the animations of the
+// element (see document.ParseTimingFunction)
 func (f CubicBezier) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	if _, err := document.ParseTimingFunction(value); err != nil {
+		return "", err
+	}
+	args := make([]string, len(value.Args))
+	for i := range value.Args {
+		args[i] = strings.TrimSpace(value.Args[i])
+	}
+	return fmt.Sprintf("%s(%s)", f.Key(), strings.Join(args, ", ")), nil
 }
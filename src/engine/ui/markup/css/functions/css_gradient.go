@@ -0,0 +1,437 @@
+/******************************************************************************/
+/* css_gradient.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package functions
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/helpers"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+	"kaijuengine.com/matrix"
+)
+
+// IsGradient returns true if the value is one of the gradient functions
+func IsGradient(value rules.PropertyValue) bool {
+	_, _, ok := gradientKind(value.Str)
+	return ok
+}
+
+func gradientKind(name string) (ui.GradientType, bool, bool) {
+	switch strings.ToLower(name) {
+	case "linear-gradient":
+		return ui.GradientTypeLinear, false, true
+	case "radial-gradient":
+		return ui.GradientTypeRadial, false, true
+	case "conic-gradient":
+		return ui.GradientTypeConic, false, true
+	case "repeating-linear-gradient":
+		return ui.GradientTypeLinear, true, true
+	case "repeating-radial-gradient":
+		return ui.GradientTypeRadial, true, true
+	case "repeating-conic-gradient":
+		return ui.GradientTypeConic, true, true
+	}
+	return ui.GradientTypeNone, false, false
+}
+
+// ParseGradient reads any of the gradient functions into a [ui.Gradient].
+// The arguments are expected to be split on their top-level commas, the way
+// the style sheet parser keeps them for gradients.
+func ParseGradient(value rules.PropertyValue, window helpers.WindowDimensions) (ui.Gradient, error) {
+	gradientType, repeating, ok := gradientKind(value.Str)
+	if !ok {
+		return ui.Gradient{}, fmt.Errorf("%s is not a gradient", value.Str)
+	}
+	g := ui.Gradient{Type: gradientType, Repeating: repeating}
+	// Arguments that came from a var() may still hold commas
+	args := splitGradientArgs(strings.Join(value.Args, ","))
+	if len(args) == 0 {
+		return g, fmt.Errorf("%s requires color stops", value.Str)
+	}
+	var err error
+	consumed := false
+	switch gradientType {
+	case ui.GradientTypeLinear:
+		g.Angle = math.Pi
+		consumed, err = parseLinearGradientLine(&g, args[0])
+	case ui.GradientTypeRadial:
+		consumed, err = parseRadialGradientShape(&g, args[0], window)
+	case ui.GradientTypeConic:
+		consumed, err = parseConicGradientStart(&g, args[0], window)
+	}
+	if err != nil {
+		return g, err
+	}
+	if consumed {
+		args = args[1:]
+	}
+	for _, arg := range args {
+		stops, err := parseGradientStop(arg, gradientType, window)
+		if err != nil {
+			return g, err
+		}
+		g.Stops = append(g.Stops, stops...)
+	}
+	if len(g.Stops) < 2 {
+		return g, fmt.Errorf("%s requires at least 2 color stops", value.Str)
+	}
+	return g, nil
+}
+
+func splitGradientArgs(text string) []string {
+	args := []string{}
+	depth, start := 0, 0
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				if a := strings.TrimSpace(text[start:i]); a != "" {
+					args = append(args, a)
+				}
+				start = i + 1
+			}
+		}
+	}
+	if a := strings.TrimSpace(text[start:]); a != "" {
+		args = append(args, a)
+	}
+	return args
+}
+
+// splitGradientFields splits an argument on the whitespace that isn't within
+// a function, "rgb(1 2 3) 10%" is 2 fields
+func splitGradientFields(text string) []string {
+	fields := []string{}
+	depth, start := 0, -1
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case depth == 0 && (c == ' ' || c == '\t' || c == '\n'):
+			if start >= 0 {
+				fields = append(fields, text[start:i])
+				start = -1
+			}
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, text[start:])
+	}
+	return fields
+}
+
+func parseLinearGradientLine(g *ui.Gradient, arg string) (bool, error) {
+	fields := splitGradientFields(arg)
+	if fields[0] != "to" {
+		angle, ok := ParseAngle(fields[0])
+		if !ok {
+			return false, nil
+		}
+		if len(fields) != 1 {
+			return true, fmt.Errorf("invalid gradient direction %q", arg)
+		}
+		g.Angle = angle
+		return true, nil
+	}
+	if len(fields) < 2 || len(fields) > 3 {
+		return true, fmt.Errorf("invalid gradient direction %q", arg)
+	}
+	var side matrix.Vec2
+	for _, f := range fields[1:] {
+		switch f {
+		case "left":
+			side.SetX(-1)
+		case "right":
+			side.SetX(1)
+		case "top":
+			side.SetY(-1)
+		case "bottom":
+			side.SetY(1)
+		default:
+			return true, fmt.Errorf("invalid gradient direction %q", arg)
+		}
+	}
+	if len(fields) == 3 && (side.X() == 0 || side.Y() == 0) {
+		return true, fmt.Errorf("invalid gradient direction %q", arg)
+	}
+	g.ToSide = side
+	return true, nil
+}
+
+func parseRadialGradientShape(g *ui.Gradient, arg string, window helpers.WindowDimensions) (bool, error) {
+	fields := splitGradientFields(arg)
+	sizes := []ui.GradientLength{}
+	recognized := false
+	for i := 0; i < len(fields); i++ {
+		switch f := fields[i]; f {
+		case "circle":
+			g.Circle = true
+		case "ellipse":
+			g.Circle = false
+		case "closest-side":
+			g.Extent = ui.RadialExtentClosestSide
+		case "closest-corner":
+			g.Extent = ui.RadialExtentClosestCorner
+		case "farthest-side":
+			g.Extent = ui.RadialExtentFarthestSide
+		case "farthest-corner":
+			g.Extent = ui.RadialExtentFarthestCorner
+		case "at":
+			if err := parseGradientPosition(g, fields[i+1:], window); err != nil {
+				return true, err
+			}
+			i = len(fields)
+		default:
+			l, ok := parseGradientLength(f, window)
+			if !ok {
+				if recognized {
+					return true, fmt.Errorf("invalid radial gradient shape %q", arg)
+				}
+				return false, nil
+			}
+			sizes = append(sizes, l)
+		}
+		recognized = true
+	}
+	switch len(sizes) {
+	case 0:
+	case 1:
+		if sizes[0].Pixels == false && sizes[0].Value != 0 {
+			return true, errors.New("a circle radius can not be a percentage")
+		}
+		g.Circle = true
+		g.Extent = ui.RadialExtentExplicit
+		g.Radius = [2]ui.GradientLength{sizes[0], sizes[0]}
+	case 2:
+		if g.Circle {
+			return true, errors.New("a circle only has one radius")
+		}
+		g.Extent = ui.RadialExtentExplicit
+		g.Radius = [2]ui.GradientLength{sizes[0], sizes[1]}
+	default:
+		return true, fmt.Errorf("invalid radial gradient size %q", arg)
+	}
+	return true, nil
+}
+
+func parseConicGradientStart(g *ui.Gradient, arg string, window helpers.WindowDimensions) (bool, error) {
+	fields := splitGradientFields(arg)
+	if fields[0] != "from" && fields[0] != "at" {
+		return false, nil
+	}
+	for i := 0; i < len(fields); i++ {
+		switch fields[i] {
+		case "from":
+			if i+1 >= len(fields) {
+				return true, fmt.Errorf("missing conic gradient angle in %q", arg)
+			}
+			angle, ok := ParseAngle(fields[i+1])
+			if !ok {
+				return true, fmt.Errorf("invalid conic gradient angle %q", fields[i+1])
+			}
+			g.Angle = angle
+			i++
+		case "at":
+			return true, parseGradientPosition(g, fields[i+1:], window)
+		default:
+			return true, fmt.Errorf("invalid conic gradient start %q", arg)
+		}
+	}
+	return true, nil
+}
+
+func parseGradientPosition(g *ui.Gradient, fields []string, window helpers.WindowDimensions) error {
+	if len(fields) == 0 || len(fields) > 2 {
+		return fmt.Errorf("invalid gradient position %q", strings.Join(fields, " "))
+	}
+	center := [2]ui.GradientLength{{Value: 0.5}, {Value: 0.5}}
+	// A single vertical keyword, or keywords in either order, are allowed
+	if len(fields) == 2 && (fields[0] == "top" || fields[0] == "bottom") &&
+		(fields[1] == "left" || fields[1] == "right" || fields[1] == "center") {
+		fields[0], fields[1] = fields[1], fields[0]
+	} else if len(fields) == 1 && (fields[0] == "top" || fields[0] == "bottom") {
+		fields = []string{"center", fields[0]}
+	}
+	for i, f := range fields {
+		switch f {
+		case "left", "top":
+			center[i] = ui.GradientLength{Value: 0}
+		case "center":
+			center[i] = ui.GradientLength{Value: 0.5}
+		case "right", "bottom":
+			center[i] = ui.GradientLength{Value: 1}
+		default:
+			l, ok := parseGradientLength(f, window)
+			if !ok {
+				return fmt.Errorf("invalid gradient position %q", f)
+			}
+			center[i] = l
+		}
+	}
+	g.Center = center
+	g.Positioned = true
+	return nil
+}
+
+// parseGradientStop reads a color stop, a stop with 2 positions is the same
+// as 2 stops of the same color
+func parseGradientStop(arg string, gradientType ui.GradientType, window helpers.WindowDimensions) ([]ui.GradientStop, error) {
+	fields := splitGradientFields(arg)
+	if len(fields) == 0 || len(fields) > 3 {
+		return nil, fmt.Errorf("invalid color stop %q", arg)
+	}
+	color, err := ParseColor(fields[0])
+	if err != nil {
+		// Color hints (a position without a color) are not supported
+		return nil, err
+	}
+	if len(fields) == 1 {
+		return []ui.GradientStop{{Color: color, Auto: true}}, nil
+	}
+	stops := make([]ui.GradientStop, 0, 2)
+	for _, f := range fields[1:] {
+		var pos ui.GradientLength
+		ok := false
+		if gradientType == ui.GradientTypeConic {
+			var a float32
+			if a, ok = ParseAngle(f); ok {
+				pos.Value = a / (2 * math.Pi)
+			} else if strings.HasSuffix(f, "%") {
+				pos, ok = parseGradientLength(f, window)
+			}
+		} else {
+			pos, ok = parseGradientLength(f, window)
+		}
+		if !ok {
+			return nil, fmt.Errorf("invalid color stop position %q", f)
+		}
+		stops = append(stops, ui.GradientStop{Color: color, Position: pos})
+	}
+	return stops, nil
+}
+
+// parseGradientLength reads percentages as fractions and every other length
+// as pixels
+func parseGradientLength(str string, window helpers.WindowDimensions) (ui.GradientLength, bool) {
+	if str == "0" {
+		return ui.GradientLength{}, true
+	}
+	if len(str) == 0 || !(str[0] == '-' || str[0] == '.' || (str[0] >= '0' && str[0] <= '9')) {
+		return ui.GradientLength{}, false
+	}
+	if strings.HasSuffix(str, "%") {
+		v, err := strconv.ParseFloat(str[:len(str)-1], 32)
+		return ui.GradientLength{Value: float32(v) / 100}, err == nil
+	}
+	end := len(str)
+	for end > 0 && (str[end-1] < '0' || str[end-1] > '9') && str[end-1] != '.' {
+		end--
+	}
+	if end == len(str) {
+		return ui.GradientLength{}, false
+	}
+	return ui.GradientLength{Value: helpers.NumFromLength(str, window), Pixels: true}, true
+}
+
+// ParseAngle reads a CSS angle into radians
+func ParseAngle(str string) (float32, bool) {
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"deg", math.Pi / 180},
+		{"grad", math.Pi / 200},
+		{"rad", 1},
+		{"turn", 2 * math.Pi},
+	}
+	if str == "0" {
+		return 0, true
+	}
+	for _, u := range units {
+		if num, ok := strings.CutSuffix(str, u.suffix); ok {
+			v, err := strconv.ParseFloat(num, 64)
+			if err != nil {
+				return 0, false
+			}
+			return float32(v * u.scale), true
+		}
+	}
+	return 0, false
+}
+
+// ParseColor reads a color written as text, a named color, a hex color,
+// transparent or the rgb() and rgba() functions
+func ParseColor(text string) (matrix.Color, error) {
+	text = strings.TrimSpace(text)
+	lower := strings.ToLower(text)
+	if lower == "transparent" {
+		return matrix.Color{}, nil
+	}
+	if name, body, ok := strings.Cut(lower, "("); ok && strings.HasSuffix(body, ")") {
+		if name != "rgb" && name != "rgba" {
+			return matrix.Color{}, fmt.Errorf("unsupported color function %q", name)
+		}
+		return parseRGBArgs(strings.FieldsFunc(body[:len(body)-1], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '/'
+		}))
+	}
+	if hex, ok := helpers.ColorMap[lower]; ok {
+		text = hex
+	}
+	if !strings.HasPrefix(text, "#") {
+		return matrix.Color{}, fmt.Errorf("invalid color %q", text)
+	}
+	return matrix.ColorFromHexString(text)
+}
+
+// ColorFromValue reads a color from a value of a property, which is either
+// the text of the color or the rgb() and rgba() functions with their args
+func ColorFromValue(value rules.PropertyValue) (matrix.Color, error) {
+	switch strings.ToLower(value.Str) {
+	case "rgb", "rgba":
+		return parseRGBArgs(value.Args)
+	}
+	return ParseColor(value.Str)
+}
+
+func parseRGBArgs(args []string) (matrix.Color, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return matrix.Color{}, fmt.Errorf("rgb requires 3 or 4 values but got %d", len(args))
+	}
+	c := matrix.Color{0, 0, 0, 1}
+	for i, a := range args {
+		scale := float64(255)
+		if i == 3 {
+			scale = 1
+		}
+		if num, ok := strings.CutSuffix(a, "%"); ok {
+			a, scale = num, 100
+		}
+		v, err := strconv.ParseFloat(a, 32)
+		if err != nil {
+			return matrix.Color{}, fmt.Errorf("invalid rgb value %q", a)
+		}
+		c[i] = float32(max(0, min(1, v/scale)))
+	}
+	return c, nil
+}
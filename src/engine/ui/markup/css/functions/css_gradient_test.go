@@ -0,0 +1,138 @@
+/******************************************************************************/
+/* css_gradient_test.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package functions
+
+import (
+	"math"
+	"testing"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+	"kaijuengine.com/matrix"
+)
+
+type testGradientWindow struct{}
+
+func (testGradientWindow) DotsPerMillimeter() float64 { return 1 }
+func (testGradientWindow) Width() int                 { return 800 }
+func (testGradientWindow) Height() int                { return 600 }
+
+func gradientValue(name string, args ...string) rules.PropertyValue {
+	return rules.PropertyValue{Str: name, Args: args}
+}
+
+func TestParseLinearGradient(t *testing.T) {
+	g, err := ParseGradient(gradientValue("linear-gradient",
+		"to top right", "rgb(255,0,0) 10%", "blue 20px 40px"), testGradientWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Type != ui.GradientTypeLinear || g.ToSide != matrix.NewVec2(1, -1) {
+		t.Fatalf("unexpected direction %+v", g)
+	}
+	if len(g.Stops) != 3 {
+		t.Fatalf("expected 3 stops, got %d", len(g.Stops))
+	}
+	if g.Stops[0].Color != matrix.ColorRed() || g.Stops[0].Position.Value != 0.1 || g.Stops[0].Position.Pixels {
+		t.Fatalf("unexpected first stop %+v", g.Stops[0])
+	}
+	if g.Stops[2].Position.Value != 40 || !g.Stops[2].Position.Pixels {
+		t.Fatalf("the double position stop should end at 40px, got %+v", g.Stops[2])
+	}
+	g, err = ParseGradient(gradientValue("repeating-linear-gradient",
+		"0.25turn", "red", "transparent 25%"), testGradientWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Repeating || matrix.Abs(g.Angle-math.Pi/2) > 0.0001 {
+		t.Fatalf("unexpected repeating gradient %+v", g)
+	}
+	if g.Stops[1].Color != (matrix.Color{}) {
+		t.Fatal("transparent should have no alpha")
+	}
+}
+
+func TestParseRadialAndConicGradient(t *testing.T) {
+	g, err := ParseGradient(gradientValue("radial-gradient",
+		"circle closest-side at left 25%", "white", "black"), testGradientWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Circle || g.Extent != ui.RadialExtentClosestSide || !g.Positioned {
+		t.Fatalf("unexpected radial shape %+v", g)
+	}
+	if g.Center[0].Value != 0 || g.Center[1].Value != 0.25 {
+		t.Fatalf("unexpected center %+v", g.Center)
+	}
+	g, err = ParseGradient(gradientValue("radial-gradient", "10px 20px", "white", "black"), testGradientWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Extent != ui.RadialExtentExplicit || g.Radius[1].Value != 20 {
+		t.Fatalf("unexpected explicit radii %+v", g.Radius)
+	}
+	g, err = ParseGradient(gradientValue("conic-gradient",
+		"from 90deg at bottom", "red", "blue 180deg"), testGradientWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if matrix.Abs(g.Angle-math.Pi/2) > 0.0001 || g.Center[1].Value != 1 || g.Center[0].Value != 0.5 {
+		t.Fatalf("unexpected conic start %+v", g)
+	}
+	if matrix.Abs(g.Stops[1].Position.Value-0.5) > 0.0001 {
+		t.Fatalf("180deg should be half way, got %f", g.Stops[1].Position.Value)
+	}
+}
+
+func TestParseGradientKeepsEveryStop(t *testing.T) {
+	// The panel only draws the first stops that fit, it is up to it to report
+	// the others
+	g, err := ParseGradient(gradientValue("linear-gradient",
+		"red", "green", "blue", "white", "black"), testGradientWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Stops) != 5 {
+		t.Fatalf("expected 5 stops, got %d", len(g.Stops))
+	}
+}
+
+func TestParseGradientErrors(t *testing.T) {
+	bad := []rules.PropertyValue{
+		gradientValue("linear-gradient", "red"),
+		gradientValue("linear-gradient", "to middle", "red", "blue"),
+		gradientValue("linear-gradient", "red", "10%", "blue"),
+		gradientValue("radial-gradient", "circle 10px 20px", "red", "blue"),
+	}
+	for _, v := range bad {
+		if _, err := ParseGradient(v, testGradientWindow{}); err == nil {
+			t.Fatalf("expected an error for %s(%q)", v.Str, v.Args)
+		}
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	cases := map[string]matrix.Color{
+		"red":                   matrix.ColorRed(),
+		"#0000ff":               matrix.ColorBlue(),
+		"rgba(0, 0, 0, 0.5)":    {0, 0, 0, 0.5},
+		"rgb(100% 0% 0% / 25%)": {1, 0, 0, 0.25},
+		"transparent":           {},
+	}
+	for text, want := range cases {
+		got, err := ParseColor(text)
+		if err != nil {
+			t.Fatalf("%s: %v", text, err)
+		}
+		if got != want {
+			t.Fatalf("%s = %v, want %v", text, got, want)
+		}
+	}
+	if _, err := ParseColor("10px"); err == nil {
+		t.Fatal("a length is not a color")
+	}
+}
@@ -7,13 +7,12 @@
 package functions
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
 func (f RepeatingLinearGradient) Process(panel *ui.Panel, elm *document.Element, value rules.PropertyValue) (string, error) {
-	return "", errors.New("not implemented")
+	_, err := ParseGradient(value, panel.Base().Host().Window)
+	return value.Str, err
 }
@@ -24,5 +24,6 @@ func (p AlignItems) Process(panel *ui.Panel, elm *document.Element, values []rul
 		return fmt.Errorf("invalid align-items value %q", values[0].Str)
 	}
 	panel.SetFlexAlignItems(align)
+	panel.SetGridAlignItems(align)
 	return nil
 }
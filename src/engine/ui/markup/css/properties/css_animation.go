@@ -8,6 +8,7 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
@@ -15,8 +16,30 @@ import (
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// The animation properties are read together by the element stylizer when it
+// computes the element's rules, Process only validates the values so mistakes
+// are reported like any other property.
 func (p Animation) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Animation not implemented")}
+	if len(values) == 0 {
+		return errors.New("expected at least 1 value for animation")
+	}
+	_, err := document.ParseAnimationShorthand(values)
+	return err
+}
 
-	return problems[0]
+// validateEach runs the parse function for each of the (comma separated)
+// values of an animation or transition longhand
+func validateEach[T any](key string, values []rules.PropertyValue, parse func(string) (T, error)) error {
+	if len(values) == 0 {
+		return fmt.Errorf("expected at least 1 value for %s", key)
+	}
+	for i := range values {
+		if values[i].IsFunction() {
+			return fmt.Errorf("unexpected function '%s' for %s", values[i].Str, key)
+		}
+		if _, err := parse(values[i].Str); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -7,8 +7,6 @@
 package properties
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
@@ -16,7 +14,5 @@ import (
 )
 
 func (p AnimationIterationCount) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("AnimationIterationCount not implemented")}
-
-	return problems[0]
+	return validateEach(p.Key(), values, document.ParseIterationCount)
 }
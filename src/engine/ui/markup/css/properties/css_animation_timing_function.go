@@ -7,7 +7,7 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
@@ -16,7 +16,9 @@ import (
 )
 
 func (p AnimationTimingFunction) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("AnimationTimingFunction not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return fmt.Errorf("expected at least 1 value for %s", p.Key())
+	}
+	_, err := document.ParseTimingFunctionList(values)
+	return err
 }
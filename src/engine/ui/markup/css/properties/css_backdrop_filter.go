@@ -8,15 +8,38 @@ package properties
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/helpers"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
 func (p BackdropFilter) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("BackdropFilter not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return fmt.Errorf("expected at least 1 value for %s", p.Key())
+	}
+	if len(values) == 1 && values[0].Str == "none" {
+		panel.ClearBackdropFilter()
+		return nil
+	}
+	filter := ui.BackdropFilter{Color: ui.IdentityColorFilter()}
+	for i := range values {
+		if strings.EqualFold(values[i].Str, "blur") && values[i].IsFunction() {
+			if len(values[i].Args) != 1 {
+				return errors.New("blur expects exactly 1 length")
+			}
+			filter.Blur = helpers.NumFromLength(values[i].Args[0], host.Window)
+			continue
+		}
+		f, err := colorFilterFunction(values[i])
+		if err != nil {
+			return err
+		}
+		filter.Color = filter.Color.Then(f)
+	}
+	return panel.SetBackdropFilter(filter)
 }
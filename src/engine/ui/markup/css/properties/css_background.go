@@ -12,6 +12,7 @@ import (
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/functions"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
@@ -27,9 +28,11 @@ func (p Background) Process(panel *ui.Panel, elm *document.Element, values []rul
 	//    background: rgb(49, 64, 82);
 	// 2) Single image token:
 	//    background: url("panel_bg.png");
+	//    background: linear-gradient(#314052, #202733);
 	// 3) Multi-token values that include a color token:
 	//    background: no-repeat center/cover #314052;
 	//    background: fixed url("panel_bg.png") #202733;
+	//    background: radial-gradient(white, black) #202733;
 	//
 	// NOTE: this is intentionally partial shorthand support. Non-color/non-url
 	// components are not fully expanded into individual background-* properties.
@@ -40,20 +43,43 @@ func (p Background) Process(panel *ui.Panel, elm *document.Element, values []rul
 	// - repeat/attachment/origin/clip token decomposition
 	if len(values) == 1 {
 		v := values[0]
-		if strings.HasPrefix(v.Str, "url(") || (v.IsFunction() && v.Str == "url") {
+		if strings.HasPrefix(v.Str, "url(") || (v.IsFunction() && v.Str == "url") ||
+			functions.IsGradient(v) {
 			return BackgroundImage{}.Process(panel, elm, values, host)
 		}
+		panel.ClearGradient()
 		return BackgroundColor{}.Process(panel, elm, values, host)
 	}
 
+	gradient := -1
+	for i := range values {
+		if functions.IsGradient(values[i]) {
+			gradient = i
+			break
+		}
+	}
+	if gradient >= 0 {
+		if err := (BackgroundImage{}).Process(panel, elm, values[gradient:gradient+1], host); err != nil {
+			return err
+		}
+	} else {
+		panel.ClearGradient()
+	}
+
 	// CSS allows the color token to appear among other tokens.
 	// Example: background: url("panel.png") no-repeat center / cover #314052;
 	// Scan from right-to-left and apply the first parseable color.
 	for i := len(values) - 1; i >= 0; i-- {
+		if i == gradient {
+			continue
+		}
 		if err := (BackgroundColor{}).Process(panel, elm, []rules.PropertyValue{values[i]}, host); err == nil {
 			return nil
 		}
 	}
 
+	if gradient >= 0 {
+		return nil
+	}
 	return errors.New("background shorthand is only partially supported; expected a color and/or url(...)")
 }
@@ -13,6 +13,7 @@ import (
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/functions"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 	"kaijuengine.com/matrix"
@@ -24,6 +25,19 @@ func (p BackgroundImage) Process(panel *ui.Panel, elm *document.Element, values
 		return fmt.Errorf("Expected exactly 1 value but got %d", len(values))
 	}
 
+	if values[0].Str == "none" {
+		panel.ClearGradient()
+		return nil
+	}
+
+	if functions.IsGradient(values[0]) {
+		g, err := functions.ParseGradient(values[0], host.Window)
+		if err != nil {
+			return err
+		}
+		return panel.SetGradient(g)
+	}
+
 	reg := regexp.MustCompile(`url\s{0,}\(\s{0,}"(.*?)"\s{0,}\)`)
 	parts := reg.FindStringSubmatch(values[0].Str)
 	if len(parts) != 2 {
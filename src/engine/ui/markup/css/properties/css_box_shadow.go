@@ -8,15 +8,121 @@ package properties
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/functions"
+	"kaijuengine.com/engine/ui/markup/css/helpers"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
+	"kaijuengine.com/matrix"
 )
 
+type shadowLayer struct {
+	lengths []float32
+	color   matrix.Color
+	inset   bool
+}
+
+func isShadowLength(v rules.PropertyValue) bool {
+	if v.IsFunction() || len(v.Str) == 0 {
+		return false
+	}
+	c := v.Str[0]
+	return c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9')
+}
+
+// splitShadowLayers splits the values of box-shadow or text-shadow on the ","
+// values kept between the layers
+func splitShadowLayers(values []rules.PropertyValue) [][]rules.PropertyValue {
+	layers := [][]rules.PropertyValue{}
+	start := 0
+	for i := range values {
+		if values[i].Str == "," && !values[i].IsFunction() {
+			layers = append(layers, values[start:i])
+			start = i + 1
+		}
+	}
+	return append(layers, values[start:])
+}
+
+// parseShadowLayer reads the offsets, blur, spread, color and inset keyword
+// of a single shadow, which may be written in any order. The color defaults
+// to black as the current text color is not known to the panel.
+func parseShadowLayer(values []rules.PropertyValue, window helpers.WindowDimensions) (shadowLayer, error) {
+	layer := shadowLayer{color: matrix.ColorBlack()}
+	hasColor := false
+	for _, v := range values {
+		switch {
+		case strings.EqualFold(v.Str, "inset") && !v.IsFunction():
+			if layer.inset {
+				return layer, errors.New("inset may only be given once per shadow")
+			}
+			layer.inset = true
+		case isShadowLength(v):
+			layer.lengths = append(layer.lengths, helpers.NumFromLength(v.Str, window))
+		default:
+			if hasColor {
+				return layer, fmt.Errorf("unexpected shadow value %q", v.Str)
+			}
+			c, err := functions.ColorFromValue(v)
+			if err != nil {
+				return layer, err
+			}
+			layer.color = c
+			hasColor = true
+		}
+	}
+	if len(layer.lengths) < 2 {
+		return layer, errors.New("a shadow requires at least an x and y offset")
+	}
+	return layer, nil
+}
+
 func (p BoxShadow) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("BoxShadow not implemented")}
+	if len(values) == 0 {
+		return fmt.Errorf("expected at least 1 value for %s", p.Key())
+	}
+	if len(values) == 1 && values[0].Str == "none" {
+		panel.ClearBoxShadow()
+		return nil
+	}
+	layers := splitShadowLayers(values)
+	shadows := make([]ui.BoxShadow, 0, len(layers))
+	for i := range layers {
+		shadow, err := parseBoxShadowLayer(layers[i], host.Window)
+		if err != nil {
+			return err
+		}
+		shadows = append(shadows, shadow)
+	}
+	panel.SetBoxShadows(shadows)
+	return nil
+}
 
-	return problems[0]
+func parseBoxShadowLayer(values []rules.PropertyValue, window helpers.WindowDimensions) (ui.BoxShadow, error) {
+	layer, err := parseShadowLayer(values, window)
+	if err != nil {
+		return ui.BoxShadow{}, err
+	}
+	if len(layer.lengths) > 4 {
+		return ui.BoxShadow{}, fmt.Errorf("box-shadow has %d lengths, at most 4 are allowed", len(layer.lengths))
+	}
+	shadow := ui.BoxShadow{
+		Offset: matrix.NewVec2(layer.lengths[0], layer.lengths[1]),
+		Color:  layer.color,
+		Inset:  layer.inset,
+	}
+	if len(layer.lengths) > 2 {
+		if layer.lengths[2] < 0 {
+			return ui.BoxShadow{}, errors.New("box-shadow blur radius can not be negative")
+		}
+		shadow.Blur = layer.lengths[2]
+	}
+	if len(layer.lengths) > 3 {
+		shadow.Spread = layer.lengths[3]
+	}
+	return shadow, nil
 }
@@ -7,16 +7,38 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
+	"kaijuengine.com/rendering"
 )
 
+// ltr|rtl|initial|inherit
 func (p Direction) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Direction not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return fmt.Errorf("expected exactly 1 value but got %d", len(values))
+	}
+	dir := rendering.TextDirectionLeftToRight
+	switch values[0].Str {
+	case "ltr", "initial":
+	case "rtl":
+		dir = rendering.TextDirectionRightToLeft
+	case "inherit":
+		if parent := elm.Parent.Value(); parent != nil {
+			if parentLabels := childLabels(parent); len(parentLabels) > 0 &&
+				parentLabels[0].Direction().IsRightToLeft() {
+				dir = rendering.TextDirectionRightToLeft
+			}
+		}
+	default:
+		return fmt.Errorf("invalid value for direction: %s", values[0].Str)
+	}
+	// The override from unicode-bidi is kept, it only changes the direction
+	for _, l := range childLabels(elm) {
+		l.SetDirection(dir.WithOverride(l.Direction().IsOverride()))
+	}
+	return nil
 }
@@ -0,0 +1,92 @@
+/******************************************************************************/
+/* css_effects_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package properties
+
+import (
+	"testing"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+	"kaijuengine.com/matrix"
+)
+
+type testEffectsWindow struct{}
+
+func (testEffectsWindow) DotsPerMillimeter() float64 { return 1 }
+func (testEffectsWindow) Width() int                 { return 800 }
+func (testEffectsWindow) Height() int                { return 600 }
+
+func TestParseShadowLayers(t *testing.T) {
+	values := testRule("box-shadow", "inset", "2px", "4px", "8px", "red", ",", "1px", "1px").Values
+	layers := splitShadowLayers(values)
+	if len(layers) != 2 {
+		t.Fatalf("expected 2 layers, got %d", len(layers))
+	}
+	layer, err := parseShadowLayer(layers[0], testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !layer.inset || len(layer.lengths) != 3 || layer.lengths[2] != 8 || layer.color != matrix.ColorRed() {
+		t.Fatalf("unexpected layer %+v", layer)
+	}
+	layer, err = parseShadowLayer([]rules.PropertyValue{
+		{Str: "rgba", Args: []string{"0", "0", "0", "0.5"}},
+		{Str: "0"}, {Str: "-3px"},
+	}, testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if layer.color != (matrix.Color{0, 0, 0, 0.5}) || layer.lengths[1] != -3 {
+		t.Fatalf("unexpected layer %+v", layer)
+	}
+	if _, err := parseShadowLayer(testRule("", "red", "blue", "1px", "1px").Values, testEffectsWindow{}); err == nil {
+		t.Fatal("expected an error for 2 colors")
+	}
+	if _, err := parseShadowLayer(testRule("", "1px", "red").Values, testEffectsWindow{}); err == nil {
+		t.Fatal("expected an error for a missing y offset")
+	}
+}
+
+func TestParseBoxShadowLayer(t *testing.T) {
+	shadow, err := parseBoxShadowLayer(testRule("", "1px", "2px", "3px", "-4px", "red").Values, testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ui.BoxShadow{Offset: matrix.NewVec2(1, 2), Blur: 3, Spread: -4, Color: matrix.ColorRed()}
+	if shadow != want {
+		t.Fatalf("shadow = %+v, want %+v", shadow, want)
+	}
+	if _, err := parseBoxShadowLayer(testRule("", "1px", "1px", "-2px").Values, testEffectsWindow{}); err == nil {
+		t.Fatal("expected an error for a negative blur")
+	}
+	if _, err := parseBoxShadowLayer(testRule("", "1px", "1px", "1px", "1px", "1px").Values, testEffectsWindow{}); err == nil {
+		t.Fatal("expected an error for 5 lengths")
+	}
+}
+
+func TestColorFilterFunction(t *testing.T) {
+	f, err := colorFilterFunction(rules.PropertyValue{Str: "grayscale", Args: []string{"150%"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != ui.GrayscaleFilter(1) {
+		t.Fatal("grayscale should clamp to 100%")
+	}
+	f, err = colorFilterFunction(rules.PropertyValue{Str: "brightness", Args: []string{"1.5"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f != ui.BrightnessFilter(1.5) {
+		t.Fatal("brightness should not be clamped")
+	}
+	if _, err := colorFilterFunction(rules.PropertyValue{Str: "hue-rotate", Args: []string{"90"}}); err == nil {
+		t.Fatal("hue-rotate requires an angle unit")
+	}
+	if _, err := colorFilterFunction(rules.PropertyValue{Str: "invert", Args: []string{"-1"}}); err == nil {
+		t.Fatal("negative amounts are invalid")
+	}
+}
@@ -7,16 +7,106 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/functions"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
-func (p Filter) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Filter not implemented")}
+// filterAmount reads the argument of a filter function, a number or a
+// percentage, or the default when the argument is left out
+func filterAmount(v rules.PropertyValue, fallback float32) (float32, error) {
+	if len(v.Args) == 0 {
+		return fallback, nil
+	}
+	if len(v.Args) > 1 {
+		return 0, fmt.Errorf("%s expects 1 value but got %d", v.Str, len(v.Args))
+	}
+	arg, scale := v.Args[0], 1.0
+	if num, ok := strings.CutSuffix(arg, "%"); ok {
+		arg, scale = num, 100
+	}
+	f, err := strconv.ParseFloat(arg, 32)
+	if err != nil || f < 0 {
+		return 0, fmt.Errorf("invalid %s amount %q", v.Str, v.Args[0])
+	}
+	return float32(f / scale), nil
+}
 
-	return problems[0]
+// colorFilterFunction converts one of the color filter functions into the
+// matrix that does the same
+func colorFilterFunction(v rules.PropertyValue) (ui.ColorFilter, error) {
+	if !v.IsFunction() {
+		return ui.ColorFilter{}, fmt.Errorf("expected a filter function but got %q", v.Str)
+	}
+	name := strings.ToLower(v.Str)
+	if name == "hue-rotate" {
+		if len(v.Args) == 0 {
+			return ui.IdentityColorFilter(), nil
+		}
+		angle, ok := functions.ParseAngle(v.Args[0])
+		if !ok || len(v.Args) > 1 {
+			return ui.ColorFilter{}, fmt.Errorf("invalid hue-rotate angle %q", strings.Join(v.Args, " "))
+		}
+		return ui.HueRotateFilter(angle), nil
+	}
+	var build func(float32) ui.ColorFilter
+	clamp := true
+	switch name {
+	case "brightness":
+		build, clamp = ui.BrightnessFilter, false
+	case "contrast":
+		build, clamp = ui.ContrastFilter, false
+	case "saturate":
+		build, clamp = ui.SaturateFilter, false
+	case "grayscale":
+		build = ui.GrayscaleFilter
+	case "sepia":
+		build = ui.SepiaFilter
+	case "invert":
+		build = ui.InvertFilter
+	case "opacity":
+		build = ui.OpacityFilter
+	default:
+		return ui.ColorFilter{}, fmt.Errorf("the %s filter function is not supported", v.Str)
+	}
+	amount, err := filterAmount(v, 1)
+	if err != nil {
+		return ui.ColorFilter{}, err
+	}
+	if clamp {
+		amount = min(amount, 1)
+	}
+	return build(amount), nil
+}
+
+func (p Filter) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) == 0 {
+		return fmt.Errorf("expected at least 1 value for %s", p.Key())
+	}
+	if len(values) == 1 && values[0].Str == "none" {
+		panel.ClearColorFilter()
+		return nil
+	}
+	filter := ui.IdentityColorFilter()
+	for i := range values {
+		switch strings.ToLower(values[i].Str) {
+		case "blur":
+			return fmt.Errorf("%s does not support blur, use backdrop-filter", p.Key())
+		case "drop-shadow":
+			return fmt.Errorf("%s does not support drop-shadow, use box-shadow", p.Key())
+		}
+		f, err := colorFilterFunction(values[i])
+		if err != nil {
+			return err
+		}
+		filter = filter.Then(f)
+	}
+	panel.SetColorFilter(filter)
+	return nil
 }
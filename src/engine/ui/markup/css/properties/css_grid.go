@@ -7,8 +7,8 @@
 package properties
 
 import (
+	"errors"
 	"strconv"
-	"strings"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
@@ -16,6 +16,8 @@ import (
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// <grid-template> | <grid-template-rows> / [ auto-flow && dense? ] <grid-auto-columns>? |
+// [ auto-flow && dense? ] <grid-auto-rows>? / <grid-template-columns>
 func (p Grid) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
 	if len(values) == 0 {
 		return nil
@@ -23,27 +25,73 @@ func (p Grid) Process(panel *ui.Panel, elm *document.Element, values []rules.Pro
 	if values[0].Str == "initial" || values[0].Str == "none" {
 		return nil
 	}
-	// Shorthand can specify template columns/rows; for now enable grid with parsed or default columns
-	cols := 3
-	if len(values) > 0 {
-		str := values[0].Str
-		if n, err := strconv.Atoi(str); err == nil && n > 0 {
-			cols = n
-		} else if strings.Contains(str, "repeat(") {
-			// crude parse for repeat(N, ...)
-			if idx := strings.Index(str, "("); idx > 0 {
-				part := strings.TrimSpace(str[idx+1:])
-				if comma := strings.Index(part, ","); comma > 0 {
-					if n, err := strconv.Atoi(strings.TrimSpace(part[:comma])); err == nil && n > 0 {
-						cols = n
-					}
-				}
-			}
-		} else if len(values) > 1 {
-			// multiple values may indicate columns
-			cols = len(values)
+	// The shorthand also switches the panel to a grid layout
+	panel.SetGrid(0)
+	before, after := splitGridLineValues(values)
+	if after == nil {
+		// Without a "/" the value is read as a column count or column list
+		if n, err := strconv.Atoi(values[0].Str); err == nil && n > 0 && len(values) == 1 {
+			panel.SetGrid(n)
+			return nil
 		}
+		columns, err := parseGridTrackList(values, host.Window)
+		if err != nil {
+			return err
+		}
+		panel.SetGridTemplateColumns(columns.tracks)
+		panel.SetGridColumnLineNames(columns.lineNames())
+		return nil
+	}
+	denseBefore, autoBefore, isFlowBefore := gridShorthandAutoFlow(before)
+	denseAfter, autoAfter, isFlowAfter := gridShorthandAutoFlow(after)
+	switch {
+	case isFlowBefore && isFlowAfter:
+		return errors.New("grid only accepts auto-flow on one side of the /")
+	case isFlowBefore:
+		columns, err := parseGridTrackList(after, host.Window)
+		if err != nil {
+			return err
+		}
+		autoRows, err := gridAutoTrackSize(p.Key(), autoBefore, host)
+		if err != nil {
+			return err
+		}
+		setGridTemplate(panel, gridTrackList{}, columns, nil)
+		panel.SetGridAutoRows(autoRows)
+		panel.SetGridAutoFlow(ui.GridAutoFlowRow, denseBefore)
+	case isFlowAfter:
+		rows, err := parseGridTrackList(before, host.Window)
+		if err != nil {
+			return err
+		}
+		autoColumns, err := gridAutoTrackSize(p.Key(), autoAfter, host)
+		if err != nil {
+			return err
+		}
+		setGridTemplate(panel, rows, gridTrackList{}, nil)
+		panel.SetGridAutoColumns(autoColumns)
+		panel.SetGridAutoFlow(ui.GridAutoFlowColumn, denseAfter)
+	default:
+		return GridTemplate{}.Process(panel, elm, values, host)
 	}
-	panel.SetGrid(cols)
 	return nil
 }
+
+// gridShorthandAutoFlow reads the "auto-flow && dense?" side of the grid
+// shorthand, returning if it is dense, the remaining auto track size values
+// and if the side had the auto-flow keyword at all
+func gridShorthandAutoFlow(values []rules.PropertyValue) (bool, []rules.PropertyValue, bool) {
+	isFlow, dense := false, false
+	rest := []rules.PropertyValue{}
+	for i := range values {
+		switch values[i].Str {
+		case "auto-flow":
+			isFlow = true
+		case "dense":
+			dense = true
+		default:
+			rest = append(rest, values[i])
+		}
+	}
+	return dense, rest, isFlow
+}
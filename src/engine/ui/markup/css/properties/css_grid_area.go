@@ -15,8 +15,34 @@ import (
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// <grid-line> [ / <grid-line> ]{0,3}
+// The lines are row-start / column-start / row-end / column-end
 func (p GridArea) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridArea not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return nil
+	}
+	parts := splitGridSlashes(values)
+	if len(parts) > 4 {
+		return errors.New("grid-area accepts at most 4 lines")
+	}
+	var lines [4]ui.GridLine
+	for i := range parts {
+		line, err := parseGridLine(parts[i], p.Key())
+		if err != nil {
+			return err
+		}
+		lines[i] = line
+	}
+	// Omitted lines copy the named area of their opposite edge, or are auto
+	for i := len(parts); i < 4; i++ {
+		if i == 1 {
+			lines[i] = gridLineFallback(lines[0])
+		} else {
+			lines[i] = gridLineFallback(lines[i-2])
+		}
+	}
+	l := panel.Base().Layout()
+	l.SetGridRowLines(lines[0], lines[2])
+	l.SetGridColumnLines(lines[1], lines[3])
+	return nil
 }
@@ -7,7 +7,7 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
@@ -15,8 +15,36 @@ import (
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// [ row | column ] || dense
 func (p GridAutoFlow) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridAutoFlow not implemented")}
+	flow, dense, err := parseGridAutoFlow(values)
+	if err != nil {
+		return err
+	}
+	panel.SetGridAutoFlow(flow, dense)
+	return nil
+}
 
-	return problems[0]
+func parseGridAutoFlow(values []rules.PropertyValue) (ui.GridAutoFlow, bool, error) {
+	flow, dense := ui.GridAutoFlowRow, false
+	hasFlow := false
+	for i := range values {
+		switch values[i].Str {
+		case "initial", "inherit", "unset":
+			return ui.GridAutoFlowRow, false, nil
+		case "row", "column":
+			if hasFlow {
+				return flow, dense, fmt.Errorf("grid-auto-flow has more than one direction")
+			}
+			hasFlow = true
+			if values[i].Str == "column" {
+				flow = ui.GridAutoFlowColumn
+			}
+		case "dense":
+			dense = true
+		default:
+			return flow, dense, fmt.Errorf("invalid grid-auto-flow value %q", values[i].Str)
+		}
+	}
+	return flow, dense, nil
 }
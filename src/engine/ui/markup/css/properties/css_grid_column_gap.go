@@ -7,16 +7,13 @@
 package properties
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// Legacy alias of column-gap
 func (p GridColumnGap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridColumnGap not implemented")}
-
-	return problems[0]
+	return ColumnGap{}.Process(panel, elm, values, host)
 }
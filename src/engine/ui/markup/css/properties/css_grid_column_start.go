@@ -7,8 +7,6 @@
 package properties
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
@@ -16,7 +14,14 @@ import (
 )
 
 func (p GridColumnStart) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridColumnStart not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return nil
+	}
+	line, err := parseGridLine(values, p.Key())
+	if err != nil {
+		return err
+	}
+	l := panel.Base().Layout()
+	l.SetGridColumnLines(line, l.GridColumnEnd())
+	return nil
 }
@@ -0,0 +1,349 @@
+/******************************************************************************/
+/* css_grid_helpers.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package properties
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/helpers"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+)
+
+var errGridLineNames = errors.New("unterminated grid line name list")
+
+func splitGridLineValues(values []rules.PropertyValue) ([]rules.PropertyValue, []rules.PropertyValue) {
+	for i := range values {
+		if values[i].Str == "/" {
+			return values[:i], values[i+1:]
+		}
+	}
+	return values, nil
+}
+
+// splitGridSlashes splits a grid shorthand on every "/" separator
+func splitGridSlashes(values []rules.PropertyValue) [][]rules.PropertyValue {
+	parts := [][]rules.PropertyValue{}
+	for {
+		part, rest := splitGridLineValues(values)
+		parts = append(parts, part)
+		if rest == nil {
+			return parts
+		}
+		values = rest
+	}
+}
+
+// parseGridLine reads a single <grid-line> value:
+// auto | <custom-ident> | <integer> && <custom-ident>? | span && [<integer> || <custom-ident>]
+func parseGridLine(values []rules.PropertyValue, property string) (ui.GridLine, error) {
+	parts := make([]string, 0, len(values))
+	for i := range values {
+		if part := strings.TrimSpace(values[i].Str); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	line := ui.GridLine{}
+	if len(parts) == 0 {
+		return line, nil
+	}
+	if len(parts) == 1 {
+		switch parts[0] {
+		case "auto", "initial", "inherit", "unset":
+			return line, nil
+		}
+	}
+	hasNumber := false
+	for _, part := range parts {
+		if part == "span" {
+			if line.Span {
+				return ui.GridLine{}, fmt.Errorf("%s has more than one span keyword", property)
+			}
+			line.Span = true
+			continue
+		}
+		if n, err := strconv.Atoi(part); err == nil {
+			if hasNumber || n == 0 {
+				return ui.GridLine{}, fmt.Errorf("%s line value %q is invalid", property, strings.Join(parts, " "))
+			}
+			hasNumber = true
+			line.Line = n
+			continue
+		}
+		if line.Name != "" || part == "auto" {
+			return ui.GridLine{}, fmt.Errorf("unsupported %s line value %q", property, strings.Join(parts, " "))
+		}
+		line.Name = part
+	}
+	if line.Span && line.Line < 0 {
+		return ui.GridLine{}, fmt.Errorf("%s span must be a positive integer", property)
+	}
+	if line.Span && line.Line == 0 && line.Name == "" {
+		return ui.GridLine{}, fmt.Errorf("%s span requires a positive integer or a line name", property)
+	}
+	return line, nil
+}
+
+// gridLineFallback is the value of an omitted end line in the grid-row,
+// grid-column and grid-area shorthands. A line given as a lone name is
+// copied so the item covers the named area, anything else becomes auto.
+func gridLineFallback(line ui.GridLine) ui.GridLine {
+	if line.Name != "" && line.Line == 0 && !line.Span {
+		return line
+	}
+	return ui.GridLine{}
+}
+
+// parseGridPlacement reads the "<start> [/ <end>]" form of grid-row and
+// grid-column
+func parseGridPlacement(values []rules.PropertyValue, property string) (ui.GridLine, ui.GridLine, error) {
+	startValues, endValues := splitGridLineValues(values)
+	start, err := parseGridLine(startValues, property)
+	if err != nil {
+		return start, start, err
+	}
+	if endValues == nil {
+		return start, gridLineFallback(start), nil
+	}
+	end, err := parseGridLine(endValues, property)
+	return start, end, err
+}
+
+// parseGridTrackSize converts a single track size to the ui template
+// encoding: pixels are positive, fr units are negative and 0 is auto
+func parseGridTrackSize(value rules.PropertyValue, window helpers.WindowDimensions) (float32, error) {
+	s := strings.TrimSpace(value.Str)
+	switch s {
+	case "auto", "min-content", "max-content":
+		return 0, nil
+	case "minmax":
+		// The track can grow up to its maximum, so that is the size used
+		if len(value.Args) != 2 {
+			return 0, fmt.Errorf("minmax expects 2 arguments")
+		}
+		return parseGridTrackSize(rules.PropertyValue{Str: value.Args[1]}, window)
+	case "fit-content":
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "fr") {
+		f, err := strconv.ParseFloat(strings.TrimSuffix(s, "fr"), 32)
+		if err != nil || f <= 0 {
+			return 0, fmt.Errorf("invalid flexible track size %q", s)
+		}
+		return -float32(f), nil
+	}
+	if len(value.Args) > 0 {
+		return 0, fmt.Errorf("unsupported track size function %s()", s)
+	}
+	if strings.HasSuffix(s, "%") {
+		return 0, fmt.Errorf("percentage track size %q is not supported", s)
+	}
+	size := helpers.NumFromLength(s, window)
+	if size <= 0 && s != "0" && s != "0px" {
+		return 0, fmt.Errorf("invalid track size %q", s)
+	}
+	return size, nil
+}
+
+// gridTrackList collects the tracks and line names of a track list, the
+// names at index i are the names of line i+1
+type gridTrackList struct {
+	tracks []float32
+	names  [][]string
+}
+
+func (l *gridTrackList) addNames(names []string) {
+	for len(l.names) <= len(l.tracks) {
+		l.names = append(l.names, nil)
+	}
+	l.names[len(l.tracks)] = append(l.names[len(l.tracks)], names...)
+}
+
+func (l *gridTrackList) addTrack(size float32) {
+	l.addNames(nil)
+	l.tracks = append(l.tracks, size)
+}
+
+// lineNames returns the names of every line, or nil if no line was named
+func (l *gridTrackList) lineNames() [][]string {
+	for i := range l.names {
+		if len(l.names[i]) > 0 {
+			return l.names
+		}
+	}
+	return nil
+}
+
+// splitGridTrackText splits the raw text of a repeat() track list into
+// values, keeping nested functions such as minmax() whole
+func splitGridTrackText(text string) []rules.PropertyValue {
+	values := []rules.PropertyValue{}
+	for text = strings.TrimSpace(text); text != ""; text = strings.TrimSpace(text) {
+		if text[0] == '[' || text[0] == ']' {
+			values = append(values, rules.PropertyValue{Str: text[:1]})
+			text = text[1:]
+			continue
+		}
+		end := strings.IndexAny(text, " []()")
+		if end < 0 {
+			values = append(values, rules.PropertyValue{Str: text})
+			break
+		}
+		if text[end] != '(' {
+			values = append(values, rules.PropertyValue{Str: text[:end]})
+			text = text[end:]
+			continue
+		}
+		value := rules.PropertyValue{Str: text[:end]}
+		closing := strings.IndexByte(text, ')')
+		if closing < 0 {
+			closing = len(text)
+		}
+		for _, arg := range strings.Split(text[end+1:closing], ",") {
+			value.Args = append(value.Args, strings.TrimSpace(arg))
+		}
+		values = append(values, value)
+		text = text[min(closing+1, len(text)):]
+	}
+	return values
+}
+
+// parseGridTrackList reads an explicit track list such as
+// "[full-start] 100px repeat(2, [col] 1fr) [full-end] minmax(10px, 2fr)"
+func parseGridTrackList(values []rules.PropertyValue, window helpers.WindowDimensions) (gridTrackList, error) {
+	list := gridTrackList{}
+	for i := 0; i < len(values); {
+		v := values[i]
+		switch v.Str {
+		case "[":
+			names := []string{}
+			for i++; i < len(values) && values[i].Str != "]"; i++ {
+				names = append(names, values[i].Str)
+			}
+			if i == len(values) {
+				return list, errGridLineNames
+			}
+			list.addNames(names)
+		case "repeat":
+			if err := list.readRepeat(v.Args, window); err != nil {
+				return list, err
+			}
+		default:
+			size, err := parseGridTrackSize(v, window)
+			if err != nil {
+				return list, err
+			}
+			list.addTrack(size)
+		}
+		i++
+	}
+	list.addNames(nil)
+	return list, nil
+}
+
+// readRepeat expands repeat(<count>, <track-list>). The auto-fill and
+// auto-fit forms are not supported since they depend on the container size.
+func (l *gridTrackList) readRepeat(args []string, window helpers.WindowDimensions) error {
+	if len(args) != 2 {
+		return fmt.Errorf("repeat expects a count and a track list")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(args[0]))
+	if err != nil || count <= 0 {
+		return fmt.Errorf("unsupported repeat count %q", args[0])
+	}
+	inner, err := parseGridTrackList(splitGridTrackText(args[1]), window)
+	if err != nil {
+		return err
+	}
+	if len(inner.tracks) == 0 {
+		return fmt.Errorf("repeat needs at least one track")
+	}
+	for range count {
+		for t := range inner.tracks {
+			l.addNames(inner.names[t])
+			l.tracks = append(l.tracks, inner.tracks[t])
+		}
+		l.addNames(inner.names[len(inner.tracks)])
+	}
+	return nil
+}
+
+// gridAreaString returns the unquoted text of a grid-template-areas row
+func gridAreaString(value string) (string, bool) {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1], true
+	}
+	return "", false
+}
+
+// parseGridTemplateAreas reads the rows of a grid-template-areas value,
+// each a space separated list of area names where "." is an empty cell.
+// Every row must have the same number of columns and every named area must
+// form a single rectangle.
+func parseGridTemplateAreas(rows []string) ([][]string, error) {
+	areas := make([][]string, 0, len(rows))
+	for _, row := range rows {
+		cells := strings.Fields(row)
+		if len(cells) == 0 {
+			return nil, fmt.Errorf("grid-template-areas rows must not be empty")
+		}
+		for i := range cells {
+			if strings.Trim(cells[i], ".") == "" {
+				cells[i] = ""
+			}
+		}
+		if len(areas) > 0 && len(cells) != len(areas[0]) {
+			return nil, fmt.Errorf("grid-template-areas rows must all have %d columns", len(areas[0]))
+		}
+		areas = append(areas, cells)
+	}
+	type rect struct{ x0, y0, x1, y1, cells int }
+	seen := map[string]*rect{}
+	for y := range areas {
+		for x, name := range areas[y] {
+			if name == "" {
+				continue
+			}
+			r, ok := seen[name]
+			if !ok {
+				r = &rect{x0: x, y0: y, x1: x, y1: y}
+				seen[name] = r
+			}
+			r.x0, r.x1 = min(r.x0, x), max(r.x1, x)
+			r.y0, r.y1 = min(r.y0, y), max(r.y1, y)
+			r.cells++
+		}
+	}
+	for name, r := range seen {
+		if (r.x1-r.x0+1)*(r.y1-r.y0+1) != r.cells {
+			return nil, fmt.Errorf("grid area %q is not a rectangle", name)
+		}
+	}
+	return areas, nil
+}
+
+// parseGridAlign reads a justify-items or justify-self keyword. The normal
+// and legacy values keep items at the start of their grid area.
+func parseGridAlign(value string) (ui.FlexAlign, bool) {
+	switch strings.TrimSpace(value) {
+	case "auto", "normal", "legacy", "initial", "inherit", "unset":
+		return ui.FlexAlignAuto, true
+	case "stretch":
+		return ui.FlexAlignStretch, true
+	case "start", "self-start", "flex-start", "left", "baseline", "first":
+		return ui.FlexAlignStart, true
+	case "end", "self-end", "flex-end", "right", "last":
+		return ui.FlexAlignEnd, true
+	case "center":
+		return ui.FlexAlignCenter, true
+	default:
+		return ui.FlexAlignAuto, false
+	}
+}
@@ -0,0 +1,132 @@
+/******************************************************************************/
+/* css_grid_helpers_test.go                                                   */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package properties
+
+import (
+	"slices"
+	"testing"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+)
+
+func gridValues(strs ...string) []rules.PropertyValue {
+	values := make([]rules.PropertyValue, len(strs))
+	for i := range strs {
+		values[i] = rules.PropertyValue{Str: strs[i]}
+	}
+	return values
+}
+
+func TestParseGridLine(t *testing.T) {
+	tests := []struct {
+		values []string
+		want   ui.GridLine
+	}{
+		{[]string{"auto"}, ui.GridLine{}},
+		{[]string{"3"}, ui.GridLine{Line: 3}},
+		{[]string{"-1"}, ui.GridLine{Line: -1}},
+		{[]string{"header"}, ui.GridLine{Name: "header"}},
+		{[]string{"col", "2"}, ui.GridLine{Line: 2, Name: "col"}},
+		{[]string{"span", "2"}, ui.GridLine{Line: 2, Span: true}},
+		{[]string{"span", "col"}, ui.GridLine{Name: "col", Span: true}},
+	}
+	for _, test := range tests {
+		got, err := parseGridLine(gridValues(test.values...), "grid-row")
+		if err != nil || got != test.want {
+			t.Fatalf("%q = %+v, %v, want %+v", test.values, got, err, test.want)
+		}
+	}
+	bad := [][]string{{"0"}, {"span"}, {"span", "-2"}, {"1", "2"}, {"a", "b"}, {"span", "span", "1"}}
+	for _, values := range bad {
+		if _, err := parseGridLine(gridValues(values...), "grid-row"); err == nil {
+			t.Fatalf("expected an error for %q", values)
+		}
+	}
+	start, end, err := parseGridPlacement(gridValues("main"), "grid-row")
+	if err != nil || start != end || start.Name != "main" {
+		t.Fatalf("a lone name should cover the named area, got %+v / %+v", start, end)
+	}
+	if _, end, _ = parseGridPlacement(gridValues("2"), "grid-row"); !end.IsAuto() {
+		t.Fatalf("an omitted end line should be auto, got %+v", end)
+	}
+}
+
+func TestParseGridTrackList(t *testing.T) {
+	values := []rules.PropertyValue{
+		{Str: "["}, {Str: "full-start"}, {Str: "]"},
+		{Str: "100px"},
+		{Str: "repeat", Args: []string{"2", "[col] minmax(10px, 2fr)"}},
+		{Str: "["}, {Str: "full-end"}, {Str: "]"},
+		{Str: "auto"},
+	}
+	list, err := parseGridTrackList(values, testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(list.tracks, []float32{100, -2, -2, 0}) {
+		t.Fatalf("tracks = %v", list.tracks)
+	}
+	names := list.lineNames()
+	want := [][]string{{"full-start"}, {"col"}, {"col"}, {"full-end"}, nil}
+	if len(names) != len(want) {
+		t.Fatalf("line names = %q, want %q", names, want)
+	}
+	for i := range want {
+		if !slices.Equal(names[i], want[i]) {
+			t.Fatalf("line names = %q, want %q", names, want)
+		}
+	}
+	if list, _ = parseGridTrackList(gridValues("1fr", "1fr"), testEffectsWindow{}); list.lineNames() != nil {
+		t.Fatal("a track list without names should have no line names")
+	}
+	bad := [][]rules.PropertyValue{
+		gridValues("50%"),
+		gridValues("[", "open"),
+		gridValues("-1fr"),
+		{{Str: "repeat", Args: []string{"auto-fill", "100px"}}},
+	}
+	for _, v := range bad {
+		if _, err := parseGridTrackList(v, testEffectsWindow{}); err == nil {
+			t.Fatalf("expected an error for %+v", v)
+		}
+	}
+}
+
+func TestParseGridTemplateAreas(t *testing.T) {
+	areas, err := parseGridTemplateAreas([]string{"head head", "nav  main", ". main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(areas) != 3 || areas[2][0] != "" || areas[1][1] != "main" {
+		t.Fatalf("areas = %q", areas)
+	}
+	if _, err = parseGridTemplateAreas([]string{"a b", "c"}); err == nil {
+		t.Fatal("expected an error for rows of different lengths")
+	}
+	if _, err = parseGridTemplateAreas([]string{"a b", "b a"}); err == nil {
+		t.Fatal("expected an error for areas that are not rectangles")
+	}
+}
+
+func TestParseGridTemplate(t *testing.T) {
+	values := gridValues(`"head head"`, "40px", `"nav main"`, "1fr", "/", "100px", "1fr")
+	rows, columns, areas, err := parseGridTemplate(values, testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(rows.tracks, []float32{40, -1}) || !slices.Equal(columns.tracks, []float32{100, -1}) {
+		t.Fatalf("rows = %v, columns = %v", rows.tracks, columns.tracks)
+	}
+	if len(areas) != 2 || areas[0][1] != "head" {
+		t.Fatalf("areas = %q", areas)
+	}
+	rows, columns, areas, err = parseGridTemplate(gridValues("auto", "1fr", "/", "1fr", "1fr", "1fr"), testEffectsWindow{})
+	if err != nil || len(rows.tracks) != 2 || len(columns.tracks) != 3 || areas != nil {
+		t.Fatalf("rows = %v, columns = %v, areas = %q, err = %v", rows.tracks, columns.tracks, areas, err)
+	}
+}
@@ -7,16 +7,13 @@
 package properties
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// Legacy alias of row-gap
 func (p GridRowGap) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridRowGap not implemented")}
-
-	return problems[0]
+	return RowGap{}.Process(panel, elm, values, host)
 }
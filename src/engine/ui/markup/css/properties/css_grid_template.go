@@ -11,12 +11,95 @@ import (
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/helpers"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// none | <grid-template-rows> / <grid-template-columns> |
+// [ <line-names>? <string> <track-size>? <line-names>? ]+ [ / <explicit-track-list> ]?
 func (p GridTemplate) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridTemplate not implemented")}
+	if len(values) == 0 {
+		return nil
+	}
+	switch values[0].Str {
+	case "none", "initial", "inherit", "unset":
+		setGridTemplate(panel, gridTrackList{}, gridTrackList{}, nil)
+		return nil
+	}
+	rows, columns, areas, err := parseGridTemplate(values, host.Window)
+	if err != nil {
+		return err
+	}
+	setGridTemplate(panel, rows, columns, areas)
+	return nil
+}
+
+func setGridTemplate(panel *ui.Panel, rows, columns gridTrackList, areas [][]string) {
+	panel.SetGridTemplateRows(rows.tracks)
+	panel.SetGridRowLineNames(rows.lineNames())
+	panel.SetGridTemplateColumns(columns.tracks)
+	panel.SetGridColumnLineNames(columns.lineNames())
+	panel.SetGridTemplateAreas(areas)
+}
 
-	return problems[0]
+func parseGridTemplate(values []rules.PropertyValue, window helpers.WindowDimensions) (rows, columns gridTrackList, areas [][]string, err error) {
+	rowValues, columnValues := splitGridLineValues(values)
+	if columnValues != nil {
+		if columns, err = parseGridTrackList(columnValues, window); err != nil {
+			return
+		}
+	}
+	hasAreas := false
+	for i := range rowValues {
+		if _, ok := gridAreaString(rowValues[i].Str); ok {
+			hasAreas = true
+			break
+		}
+	}
+	if !hasAreas {
+		if columnValues == nil {
+			err = errors.New("grid-template expects <rows> / <columns> or area strings")
+			return
+		}
+		rows, err = parseGridTrackList(rowValues, window)
+		return
+	}
+	// Each area string is a row, optionally followed by its size and
+	// surrounded by the names of the lines on either side of it
+	areaRows := []string{}
+	for i := 0; i < len(rowValues); i++ {
+		v := rowValues[i]
+		if v.Str == "[" {
+			names := []string{}
+			for i++; i < len(rowValues) && rowValues[i].Str != "]"; i++ {
+				names = append(names, rowValues[i].Str)
+			}
+			if i == len(rowValues) {
+				err = errGridLineNames
+				return
+			}
+			rows.addNames(names)
+			continue
+		}
+		row, ok := gridAreaString(v.Str)
+		if !ok {
+			err = errors.New("grid-template row sizes must follow an area string")
+			return
+		}
+		areaRows = append(areaRows, row)
+		size := float32(0)
+		if i+1 < len(rowValues) && rowValues[i+1].Str != "[" {
+			if _, isRow := gridAreaString(rowValues[i+1].Str); !isRow {
+				i++
+				if size, err = parseGridTrackSize(rowValues[i], window); err != nil {
+					return
+				}
+			}
+		}
+		rows.addTrack(size)
+	}
+	rows.addNames(nil)
+	areas, err = parseGridTemplateAreas(areaRows)
+	return
 }
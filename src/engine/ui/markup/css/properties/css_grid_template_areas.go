@@ -7,7 +7,7 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
@@ -15,8 +15,28 @@ import (
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// none | <string>+
 func (p GridTemplateAreas) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridTemplateAreas not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return nil
+	}
+	switch values[0].Str {
+	case "none", "initial", "inherit", "unset":
+		panel.SetGridTemplateAreas(nil)
+		return nil
+	}
+	rows := make([]string, len(values))
+	for i := range values {
+		row, ok := gridAreaString(values[i].Str)
+		if !ok {
+			return fmt.Errorf("grid-template-areas expects quoted rows, got %q", values[i].Str)
+		}
+		rows[i] = row
+	}
+	areas, err := parseGridTemplateAreas(rows)
+	if err != nil {
+		return err
+	}
+	panel.SetGridTemplateAreas(areas)
+	return nil
 }
@@ -7,16 +7,28 @@
 package properties
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// none | <track-list>
 func (p GridTemplateRows) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("GridTemplateRows not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return nil
+	}
+	switch values[0].Str {
+	case "none", "initial", "inherit", "unset":
+		panel.SetGridTemplateRows(nil)
+		panel.SetGridRowLineNames(nil)
+		return nil
+	}
+	list, err := parseGridTrackList(values, host.Window)
+	if err != nil {
+		return err
+	}
+	panel.SetGridTemplateRows(list.tracks)
+	panel.SetGridRowLineNames(list.lineNames())
+	return nil
 }
@@ -7,7 +7,7 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
@@ -16,7 +16,13 @@ import (
 )
 
 func (p JustifyItems) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("JustifyItems not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return nil
+	}
+	align, ok := parseGridAlign(values[len(values)-1].Str)
+	if !ok {
+		return fmt.Errorf("invalid justify-items value %q", values[len(values)-1].Str)
+	}
+	panel.SetGridJustifyItems(align)
+	return nil
 }
@@ -7,16 +7,19 @@
 package properties
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// <align-items> <justify-items>?
 func (p PlaceItems) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("PlaceItems not implemented")}
-
-	return problems[0]
+	if len(values) == 0 {
+		return nil
+	}
+	if err := (AlignItems{}).Process(panel, elm, values[:1], host); err != nil {
+		return err
+	}
+	return JustifyItems{}.Process(panel, elm, values[len(values)-1:], host)
 }
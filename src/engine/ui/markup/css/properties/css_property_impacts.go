@@ -16,6 +16,7 @@ import (
 // These properties only alter shader/render state. Everything else inherits
 // PropertyBase's conservative layout impact.
 func (AccentColor) StyleImpact() document.StyleImpact             { return document.StyleImpactPaint }
+func (BackdropFilter) StyleImpact() document.StyleImpact          { return document.StyleImpactPaint }
 func (Background) StyleImpact() document.StyleImpact              { return document.StyleImpactPaint }
 func (BackgroundAttachment) StyleImpact() document.StyleImpact    { return document.StyleImpactPaint }
 func (BackgroundBlendMode) StyleImpact() document.StyleImpact     { return document.StyleImpactPaint }
@@ -71,10 +72,46 @@ func (TextDecorationStyle) StyleImpact() document.StyleImpact     { return docum
 func (TextShadow) StyleImpact() document.StyleImpact              { return document.StyleImpactPaint }
 func (UserSelect) StyleImpact() document.StyleImpact              { return document.StyleImpactPaint }
 
+// Transforms are drawn and hit tested without changing the layout
+func (Rotate) StyleImpact() document.StyleImpact          { return document.StyleImpactPaint }
+func (Scale) StyleImpact() document.StyleImpact           { return document.StyleImpactPaint }
+func (Transform) StyleImpact() document.StyleImpact       { return document.StyleImpactPaint }
+func (TransformOrigin) StyleImpact() document.StyleImpact { return document.StyleImpactPaint }
+func (Translate) StyleImpact() document.StyleImpact       { return document.StyleImpactPaint }
+
+// Animation and transition settings don't change how the element looks by
+// themselves, the values they animate are diffed like any other property.
+func (Animation) StyleImpact() document.StyleImpact                { return document.StyleImpactPaint }
+func (AnimationDelay) StyleImpact() document.StyleImpact           { return document.StyleImpactPaint }
+func (AnimationDirection) StyleImpact() document.StyleImpact       { return document.StyleImpactPaint }
+func (AnimationDuration) StyleImpact() document.StyleImpact        { return document.StyleImpactPaint }
+func (AnimationFillMode) StyleImpact() document.StyleImpact        { return document.StyleImpactPaint }
+func (AnimationIterationCount) StyleImpact() document.StyleImpact  { return document.StyleImpactPaint }
+func (AnimationName) StyleImpact() document.StyleImpact            { return document.StyleImpactPaint }
+func (AnimationPlayState) StyleImpact() document.StyleImpact       { return document.StyleImpactPaint }
+func (AnimationTimingFunction) StyleImpact() document.StyleImpact  { return document.StyleImpactPaint }
+func (Keyframes) StyleImpact() document.StyleImpact                { return document.StyleImpactPaint }
+func (Transition) StyleImpact() document.StyleImpact               { return document.StyleImpactPaint }
+func (TransitionDelay) StyleImpact() document.StyleImpact          { return document.StyleImpactPaint }
+func (TransitionDuration) StyleImpact() document.StyleImpact       { return document.StyleImpactPaint }
+func (TransitionProperty) StyleImpact() document.StyleImpact       { return document.StyleImpactPaint }
+func (TransitionTimingFunction) StyleImpact() document.StyleImpact { return document.StyleImpactPaint }
+
+func (BackdropFilter) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	panel.ClearBackdropFilter()
+	return nil
+}
+
 func (Background) Reset(panel *ui.Panel, elm *document.Element, host *engine.Host) error {
+	panel.ClearGradient()
 	return (BackgroundColor{}).Reset(panel, elm, host)
 }
 
+func (BackgroundImage) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	panel.ClearGradient()
+	return nil
+}
+
 func (BackgroundColor) Reset(panel *ui.Panel, elm *document.Element, _ *engine.Host) error {
 	if elm.UI.IsType(ui.ElementTypeLabel) {
 		elm.UI.ToLabel().SetBGColor(matrix.ColorTransparent())
@@ -168,6 +205,11 @@ func (BorderTopStyle) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host
 	return nil
 }
 
+func (BoxShadow) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	panel.ClearBoxShadow()
+	return nil
+}
+
 func (Color) Reset(panel *ui.Panel, elm *document.Element, _ *engine.Host) error {
 	c := matrix.ColorWhite()
 	if panel.Base().IsType(ui.ElementTypeInput) {
@@ -180,6 +222,11 @@ func (Color) Reset(panel *ui.Panel, elm *document.Element, _ *engine.Host) error
 	return nil
 }
 
+func (Filter) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	panel.ClearColorFilter()
+	return nil
+}
+
 func (Opacity) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
 	c := panel.Color()
 	c.SetA(1)
@@ -192,6 +239,36 @@ func (OutlineColor) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host)
 	return nil
 }
 
+func (Rotate) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Rotate = 0 })
+	return nil
+}
+
+func (Scale) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Scale = matrix.Vec2One() })
+	return nil
+}
+
+func (TextShadow) Reset(_ *ui.Panel, elm *document.Element, _ *engine.Host) error {
+	clearChildTextShadow(elm)
+	return nil
+}
+
+func (Transform) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Functions = nil })
+	return nil
+}
+
+func (TransformOrigin) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Origin = ui.NewTransform2D().Origin })
+	return nil
+}
+
+func (Translate) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Translate = [2]ui.TransformLength{} })
+	return nil
+}
+
 func (Display) Reset(panel *ui.Panel, _ *document.Element, _ *engine.Host) error {
 	panel.Base().SetCSSDisplayVisible(true)
 	panel.SetFlowLayout()
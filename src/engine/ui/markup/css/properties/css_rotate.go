@@ -7,7 +7,7 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
@@ -15,8 +15,23 @@ import (
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// none|angle|z angle
 func (p Rotate) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Rotate not implemented")}
-
-	return problems[0]
+	if len(values) == 1 && values[0].Str == "none" {
+		return Rotate{}.Reset(panel, elm, host)
+	}
+	switch {
+	case len(values) == 2 && values[0].Str == "z":
+		values = values[1:]
+	case len(values) == 2 && (values[0].Str == "x" || values[0].Str == "y"):
+		return errTransform3D(p.Key())
+	case len(values) != 1:
+		return fmt.Errorf("rotate expects an angle but got %d values", len(values))
+	}
+	angle, err := transformAngle(values[0].Str)
+	if err != nil {
+		return err
+	}
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Rotate = angle })
+	return nil
 }
@@ -7,16 +7,38 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
+	"kaijuengine.com/matrix"
 )
 
+// none|x [y [z]]
 func (p Scale) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Scale not implemented")}
-
-	return problems[0]
+	if len(values) == 1 && values[0].Str == "none" {
+		return Scale{}.Reset(panel, elm, host)
+	}
+	if len(values) == 0 || len(values) > 3 {
+		return fmt.Errorf("scale expects 1 to 3 values but got %d", len(values))
+	}
+	nums := make([]float32, len(values))
+	for i := range values {
+		n, err := transformNumber(values[i].Str)
+		if err != nil {
+			return err
+		}
+		nums[i] = n
+	}
+	if len(nums) == 3 && nums[2] != 1 {
+		return errTransform3D(p.Key())
+	}
+	scale := matrix.NewVec2(nums[0], nums[0])
+	if len(nums) > 1 {
+		scale.SetY(nums[1])
+	}
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Scale = scale })
+	return nil
 }
@@ -8,15 +8,62 @@ package properties
 
 import (
 	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
+	"kaijuengine.com/matrix"
 )
 
-func (p TextShadow) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("TextShadow not implemented")}
+func setChildTextShadow(elm *document.Element, offset matrix.Vec2, blur float32, color matrix.Color) {
+	for _, c := range elm.Children {
+		if c.IsText() {
+			c.UI.ToLabel().SetTextShadow(offset, blur, color)
+		}
+		setChildTextShadow(c, offset, blur, color)
+	}
+}
 
-	return problems[0]
+func clearChildTextShadow(elm *document.Element) {
+	for _, c := range elm.Children {
+		if c.IsText() {
+			c.UI.ToLabel().ClearTextShadow()
+		}
+		clearChildTextShadow(c)
+	}
+}
+
+func (p TextShadow) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) == 0 {
+		return fmt.Errorf("expected at least 1 value for %s", p.Key())
+	}
+	if len(values) == 1 && values[0].Str == "none" {
+		clearChildTextShadow(elm)
+		return nil
+	}
+	layers := splitShadowLayers(values)
+	layer, err := parseShadowLayer(layers[0], host.Window)
+	if err != nil {
+		return err
+	}
+	if layer.inset {
+		return errors.New("text-shadow can not be inset")
+	}
+	if len(layer.lengths) > 3 {
+		return fmt.Errorf("text-shadow has %d lengths, at most 3 are allowed", len(layer.lengths))
+	}
+	var blur float32
+	if len(layer.lengths) > 2 {
+		if layer.lengths[2] < 0 {
+			return errors.New("text-shadow blur radius can not be negative")
+		}
+		blur = layer.lengths[2]
+	}
+	setChildTextShadow(elm, matrix.NewVec2(layer.lengths[0], layer.lengths[1]), blur, layer.color)
+	if len(layers) > 1 {
+		return fmt.Errorf("text-shadow has %d layers, only the first is drawn", len(layers))
+	}
+	return nil
 }
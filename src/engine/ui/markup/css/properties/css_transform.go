@@ -8,89 +8,252 @@ package properties
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/functions"
 	"kaijuengine.com/engine/ui/markup/css/helpers"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
-	"kaijuengine.com/matrix"
 )
 
-func translateXYZ(str string, panel *ui.Panel, host *engine.Host, vc matrix.VectorComponent) {
-	if vc == matrix.Vz {
-		p := panel.Base().Entity().Transform.Position()
-		p[vc] += helpers.NumFromLength(str, host.Window)
-		panel.Base().Entity().Transform.SetPosition(p)
-	} else {
-		offset := panel.Base().Layout().InnerOffset()
-		p := helpers.NumFromLength(str, host.Window)
-		if vc == matrix.Vy {
-			p *= -1.0
-		}
-		if strings.HasSuffix(str, "%") {
-			l := panel.Base().Layout()
-			localInnerOffset := l.LocalInnerOffset()
-			localInnerOffset[vc] = l.PixelSize()[vc] * p
-			l.SetLocalInnerOffset(localInnerOffset.X(), localInnerOffset.Y(), localInnerOffset.Z(), localInnerOffset.W())
-		} else {
-			offset[vc] += p
-			panel.Base().Layout().SetInnerOffset(offset.X(), offset.Y(), offset.Z(), offset.W())
+// updateTransform2D changes part of the transform of the panel, the
+// transform, translate, rotate, scale and transform-origin properties each
+// own a part of it
+func updateTransform2D(panel *ui.Panel, update func(t *ui.Transform2D)) {
+	t, had := panel.Base().Transform2D()
+	update(&t)
+	if !had && t.IsIdentity() && t.Origin == ui.NewTransform2D().Origin {
+		return
+	}
+	panel.Base().SetTransform2D(t)
+}
+
+func transformLength(str string, window helpers.WindowDimensions) (ui.TransformLength, error) {
+	if str == "0" {
+		return ui.TransformLength{}, nil
+	}
+	if num, ok := strings.CutSuffix(str, "%"); ok {
+		v, err := strconv.ParseFloat(num, 32)
+		if err != nil {
+			return ui.TransformLength{}, fmt.Errorf("invalid percentage %q", str)
 		}
+		return ui.TransformLength{Value: float32(v) / 100, Fraction: true}, nil
 	}
+	if !isShadowLength(rules.PropertyValue{Str: str}) {
+		return ui.TransformLength{}, fmt.Errorf("invalid length %q", str)
+	}
+	return ui.TransformLength{Value: helpers.NumFromLength(str, window)}, nil
 }
 
-// none|transform-functions|initial|inherit
-func (p Transform) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	if len(values) != 1 {
-		return errors.New("transform expects 1 value")
+// transformNumber reads a number or a percentage as a number (50% is 0.5)
+func transformNumber(str string) (float32, error) {
+	scale := 1.0
+	if num, ok := strings.CutSuffix(str, "%"); ok {
+		str, scale = num, 100
 	}
+	v, err := strconv.ParseFloat(str, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", str)
+	}
+	return float32(v / scale), nil
+}
 
-	switch values[0].Str {
-	case "none":
-	case "initial":
-	case "inherit":
-	case "matrix":
-	case "matrix3d":
-	case "translate":
-		if len(values[0].Args) == 2 {
-			translateXYZ(values[0].Args[0], panel, host, matrix.Vx)
-			translateXYZ(values[0].Args[1], panel, host, matrix.Vy)
-		} else {
-			return errors.New("translate expects 2 values")
-		}
-	case "translate3d":
-		if len(values[0].Args) == 3 {
-			translateXYZ(values[0].Args[0], panel, host, matrix.Vx)
-			translateXYZ(values[0].Args[1], panel, host, matrix.Vy)
-			translateXYZ(values[0].Args[2], panel, host, matrix.Vz)
-		} else {
-			return errors.New("translate3d expects 3 values")
-		}
-	case "translateX":
-		translateXYZ(values[0].Args[0], panel, host, matrix.Vx)
-	case "translateY":
-		translateXYZ(values[0].Args[0], panel, host, matrix.Vy)
+func transformAngle(str string) (float32, error) {
+	angle, ok := functions.ParseAngle(str)
+	if !ok {
+		return 0, fmt.Errorf("invalid angle %q", str)
+	}
+	return angle, nil
+}
+
+func transformArgCount(v rules.PropertyValue, counts ...int) error {
+	for _, c := range counts {
+		if len(v.Args) == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s has an unexpected number of values (%d)", v.Str, len(v.Args))
+}
+
+func errTransform3D(name string) error {
+	return fmt.Errorf("%s is a 3D transform, only 2D transforms are supported", name)
+}
+
+// parseTransformFunction reads one of the 2D transform functions. The 3D
+// functions are accepted only when they don't leave the screen plane.
+func parseTransformFunction(v rules.PropertyValue, window helpers.WindowDimensions) (ui.TransformFunction, error) {
+	name := v.Str
+	args := v.Args
+	switch name {
+	case "translate", "translate3d", "translateX", "translateY":
+		counts := map[string][]int{
+			"translate":   {1, 2},
+			"translate3d": {3},
+			"translateX":  {1},
+			"translateY":  {1},
+		}[name]
+		if err := transformArgCount(v, counts...); err != nil {
+			return ui.TransformFunction{}, err
+		}
+		var xy [2]ui.TransformLength
+		start := 0
+		if name == "translateY" {
+			start = 1
+		}
+		for i := 0; i < len(args) && i < 2; i++ {
+			l, err := transformLength(args[i], window)
+			if err != nil {
+				return ui.TransformFunction{}, err
+			}
+			xy[start+i] = l
+		}
+		if name == "translate3d" && helpers.NumFromLength(args[2], window) != 0 {
+			return ui.TransformFunction{}, errTransform3D(name)
+		}
+		return ui.TranslateFunction(xy[0], xy[1]), nil
 	case "translateZ":
-		translateXYZ(values[0].Args[0], panel, host, matrix.Vz)
-	case "scale":
-	case "scale3d":
-	case "scaleX":
-	case "scaleY":
-	case "scaleZ":
-	case "rotate":
+		if err := transformArgCount(v, 1); err != nil {
+			return ui.TransformFunction{}, err
+		}
+		if helpers.NumFromLength(args[0], window) != 0 {
+			return ui.TransformFunction{}, errTransform3D(name)
+		}
+		return ui.TranslateFunction(ui.TransformLength{}, ui.TransformLength{}), nil
+	case "scale", "scale3d", "scaleX", "scaleY", "scaleZ":
+		counts := map[string][]int{
+			"scale":   {1, 2},
+			"scale3d": {3},
+			"scaleX":  {1},
+			"scaleY":  {1},
+			"scaleZ":  {1},
+		}[name]
+		if err := transformArgCount(v, counts...); err != nil {
+			return ui.TransformFunction{}, err
+		}
+		nums := make([]float32, len(args))
+		for i := range args {
+			n, err := transformNumber(args[i])
+			if err != nil {
+				return ui.TransformFunction{}, err
+			}
+			nums[i] = n
+		}
+		switch name {
+		case "scale":
+			if len(nums) == 1 {
+				return ui.ScaleFunction(nums[0], nums[0]), nil
+			}
+			return ui.ScaleFunction(nums[0], nums[1]), nil
+		case "scaleX":
+			return ui.ScaleFunction(nums[0], 1), nil
+		case "scaleY":
+			return ui.ScaleFunction(1, nums[0]), nil
+		case "scaleZ":
+			if nums[0] != 1 {
+				return ui.TransformFunction{}, errTransform3D(name)
+			}
+			return ui.ScaleFunction(1, 1), nil
+		}
+		if nums[2] != 1 {
+			return ui.TransformFunction{}, errTransform3D(name)
+		}
+		return ui.ScaleFunction(nums[0], nums[1]), nil
+	case "rotate", "rotateZ":
+		if err := transformArgCount(v, 1); err != nil {
+			return ui.TransformFunction{}, err
+		}
+		a, err := transformAngle(args[0])
+		if err != nil {
+			return ui.TransformFunction{}, err
+		}
+		return ui.RotateFunction(a), nil
 	case "rotate3d":
-	case "rotateX":
-	case "rotateY":
-	case "rotateZ":
-	case "skew":
-	case "skewX":
-	case "skewY":
-	case "perspective":
-	default:
-		return errors.New("transform has unexpected value")
+		if err := transformArgCount(v, 4); err != nil {
+			return ui.TransformFunction{}, err
+		}
+		if args[0] != "0" || args[1] != "0" {
+			return ui.TransformFunction{}, errTransform3D(name)
+		}
+		z, err := transformNumber(args[2])
+		if err != nil || z == 0 {
+			return ui.TransformFunction{}, fmt.Errorf("invalid rotate3d axis %q", strings.Join(args[:3], " "))
+		}
+		a, err := transformAngle(args[3])
+		if err != nil {
+			return ui.TransformFunction{}, err
+		}
+		if z < 0 {
+			a = -a
+		}
+		return ui.RotateFunction(a), nil
+	case "skew", "skewX", "skewY":
+		counts := []int{1}
+		if name == "skew" {
+			counts = []int{1, 2}
+		}
+		if err := transformArgCount(v, counts...); err != nil {
+			return ui.TransformFunction{}, err
+		}
+		var xy [2]float32
+		for i := range args {
+			a, err := transformAngle(args[i])
+			if err != nil {
+				return ui.TransformFunction{}, err
+			}
+			xy[i] = a
+		}
+		if name == "skewY" {
+			xy[0], xy[1] = 0, xy[0]
+		}
+		return ui.SkewFunction(xy[0], xy[1]), nil
+	case "matrix":
+		if err := transformArgCount(v, 6); err != nil {
+			return ui.TransformFunction{}, err
+		}
+		var m [6]float32
+		for i := range args {
+			f, err := strconv.ParseFloat(args[i], 32)
+			if err != nil {
+				return ui.TransformFunction{}, fmt.Errorf("invalid matrix value %q", args[i])
+			}
+			m[i] = float32(f)
+		}
+		return ui.MatrixFunction(m[0], m[1], m[2], m[3], m[4], m[5]), nil
+	case "rotateX", "rotateY", "matrix3d", "perspective":
+		return ui.TransformFunction{}, errTransform3D(name)
 	}
+	return ui.TransformFunction{}, fmt.Errorf("unknown transform function %q", name)
+}
 
+// none|transform-functions|initial|inherit
+func (p Transform) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) == 0 {
+		return errors.New("transform expects at least 1 value")
+	}
+	if len(values) == 1 && !values[0].IsFunction() {
+		switch values[0].Str {
+		case "none", "initial":
+			updateTransform2D(panel, func(t *ui.Transform2D) { t.Functions = nil })
+			return nil
+		case "inherit":
+			return nil
+		}
+	}
+	list := make([]ui.TransformFunction, 0, len(values))
+	for i := range values {
+		if !values[i].IsFunction() {
+			return fmt.Errorf("transform has unexpected value %q", values[i].Str)
+		}
+		f, err := parseTransformFunction(values[i], host.Window)
+		if err != nil {
+			return err
+		}
+		list = append(list, f)
+	}
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Functions = list })
 	return nil
 }
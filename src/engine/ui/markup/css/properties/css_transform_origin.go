@@ -7,16 +7,68 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/helpers"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
-func (p TransformOrigin) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("TransformOrigin not implemented")}
+func parseTransformOrigin(values []rules.PropertyValue, window helpers.WindowDimensions) ([2]ui.TransformLength, error) {
+	center := ui.TransformLength{Value: 0.5, Fraction: true}
+	origin := [2]ui.TransformLength{center, center}
+	if len(values) == 0 || len(values) > 3 {
+		return origin, fmt.Errorf("transform-origin expects 1 to 3 values but got %d", len(values))
+	}
+	if len(values) == 3 && helpers.NumFromLength(values[2].Str, window) != 0 {
+		return origin, errTransform3D("transform-origin")
+	}
+	strs := []string{values[0].Str}
+	if len(values) > 1 {
+		strs = append(strs, values[1].Str)
+	}
+	isVertical := func(s string) bool { return s == "top" || s == "bottom" }
+	isHorizontal := func(s string) bool { return s == "left" || s == "right" }
+	// Keywords may be given in either order, a single vertical keyword keeps
+	// the horizontal axis centered
+	if len(strs) == 1 && isVertical(strs[0]) {
+		strs = []string{"center", strs[0]}
+	} else if len(strs) == 2 && (isVertical(strs[0]) || isHorizontal(strs[1])) {
+		strs[0], strs[1] = strs[1], strs[0]
+	}
+	for i, s := range strs {
+		switch {
+		case s == "center":
+			origin[i] = center
+		case s == "left" && i == 0, s == "top" && i == 1:
+			origin[i] = ui.TransformLength{Value: 0, Fraction: true}
+		case s == "right" && i == 0, s == "bottom" && i == 1:
+			origin[i] = ui.TransformLength{Value: 1, Fraction: true}
+		default:
+			l, err := transformLength(s, window)
+			if err != nil {
+				return origin, err
+			}
+			origin[i] = l
+		}
+	}
+	return origin, nil
+}
 
-	return problems[0]
+// [x-axis] [y-axis] [z-axis]|initial|inherit
+func (p TransformOrigin) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
+	if len(values) == 1 && values[0].Str == "inherit" {
+		return nil
+	}
+	if len(values) == 1 && values[0].Str == "initial" {
+		return TransformOrigin{}.Reset(panel, elm, host)
+	}
+	origin, err := parseTransformOrigin(values, host.Window)
+	if err != nil {
+		return err
+	}
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Origin = origin })
+	return nil
 }
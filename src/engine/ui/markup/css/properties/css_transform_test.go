@@ -0,0 +1,87 @@
+/******************************************************************************/
+/* css_transform_test.go                                                      */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package properties
+
+import (
+	"math"
+	"testing"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+	"kaijuengine.com/matrix"
+)
+
+func transformFunc(name string, args ...string) rules.PropertyValue {
+	return rules.PropertyValue{Str: name, Args: args}
+}
+
+func TestParseTransformFunction(t *testing.T) {
+	f, err := parseTransformFunction(transformFunc("translate", "-50%", "10px"), testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := ui.TranslateFunction(ui.TransformLength{Value: -0.5, Fraction: true}, ui.TransformLength{Value: 10})
+	if f != want {
+		t.Fatalf("translate = %+v, want %+v", f, want)
+	}
+	if f, _ = parseTransformFunction(transformFunc("translateY", "5px"), testEffectsWindow{}); f.Offset[1].Value != 5 || f.Offset[0].Value != 0 {
+		t.Fatalf("translateY = %+v", f)
+	}
+	if f, _ = parseTransformFunction(transformFunc("scale", "150%"), testEffectsWindow{}); f != ui.ScaleFunction(1.5, 1.5) {
+		t.Fatalf("scale = %+v", f)
+	}
+	f, err = parseTransformFunction(transformFunc("rotate", "0.25turn"), testEffectsWindow{})
+	if err != nil || matrix.Abs(f.Values[0]-math.Pi/2) > 0.0001 {
+		t.Fatalf("rotate = %+v, %v", f, err)
+	}
+	if f, _ = parseTransformFunction(transformFunc("skewY", "45deg"), testEffectsWindow{}); f.Values[0] != 0 || f.Values[1] == 0 {
+		t.Fatalf("skewY = %+v", f)
+	}
+	if _, err = parseTransformFunction(transformFunc("translate3d", "0", "0", "0"), testEffectsWindow{}); err != nil {
+		t.Fatalf("a flat translate3d should be accepted: %v", err)
+	}
+	bad := []rules.PropertyValue{
+		transformFunc("rotateX", "45deg"),
+		transformFunc("translate3d", "0", "0", "5px"),
+		transformFunc("rotate", "45"),
+		transformFunc("matrix", "1", "0", "0", "1"),
+		transformFunc("wobble", "1"),
+	}
+	for _, v := range bad {
+		if _, err := parseTransformFunction(v, testEffectsWindow{}); err == nil {
+			t.Fatalf("expected an error for %s(%q)", v.Str, v.Args)
+		}
+	}
+}
+
+func TestParseTransformOrigin(t *testing.T) {
+	origin, err := parseTransformOrigin(testRule("", "top", "left").Values, testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	zero := ui.TransformLength{Fraction: true}
+	if origin[0] != zero || origin[1] != zero {
+		t.Fatalf("top left = %+v", origin)
+	}
+	origin, err = parseTransformOrigin(testRule("", "bottom").Values, testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if origin[0].Value != 0.5 || origin[1].Value != 1 {
+		t.Fatalf("bottom = %+v", origin)
+	}
+	origin, err = parseTransformOrigin(testRule("", "10px", "25%").Values, testEffectsWindow{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if origin[0] != (ui.TransformLength{Value: 10}) || origin[1] != (ui.TransformLength{Value: 0.25, Fraction: true}) {
+		t.Fatalf("10px 25%% = %+v", origin)
+	}
+	if _, err := parseTransformOrigin(testRule("", "left", "left").Values, testEffectsWindow{}); err == nil {
+		t.Fatal("expected an error for left left")
+	}
+}
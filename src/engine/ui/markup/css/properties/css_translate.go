@@ -7,16 +7,34 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/helpers"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
+// none|x [y [z]]
 func (p Translate) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("Translate not implemented")}
-
-	return problems[0]
+	if len(values) == 1 && values[0].Str == "none" {
+		return Translate{}.Reset(panel, elm, host)
+	}
+	if len(values) == 0 || len(values) > 3 {
+		return fmt.Errorf("translate expects 1 to 3 values but got %d", len(values))
+	}
+	var xy [2]ui.TransformLength
+	for i := 0; i < len(values) && i < 2; i++ {
+		l, err := transformLength(values[i].Str, host.Window)
+		if err != nil {
+			return err
+		}
+		xy[i] = l
+	}
+	if len(values) == 3 && helpers.NumFromLength(values[2].Str, host.Window) != 0 {
+		return errTransform3D(p.Key())
+	}
+	updateTransform2D(panel, func(t *ui.Transform2D) { t.Translate = xy })
+	return nil
 }
@@ -7,16 +7,49 @@
 package properties
 
 import (
-	"errors"
+	"fmt"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/ui"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
+	"kaijuengine.com/rendering"
 )
 
+// normal|embed|isolate|bidi-override|isolate-override|plaintext|initial|inherit
+//
+// Text is laid out one label at a time, so embed and isolate are the same as
+// normal, and isolate-override is the same as bidi-override
 func (p UnicodeBidi) Process(panel *ui.Panel, elm *document.Element, values []rules.PropertyValue, host *engine.Host) error {
-	problems := []error{errors.New("UnicodeBidi not implemented")}
-
-	return problems[0]
+	if len(values) != 1 {
+		return fmt.Errorf("expected exactly 1 value but got %d", len(values))
+	}
+	labels := childLabels(elm)
+	switch values[0].Str {
+	case "normal", "embed", "isolate", "initial":
+		for _, l := range labels {
+			l.SetDirection(l.Direction().WithOverride(false))
+		}
+	case "bidi-override", "isolate-override":
+		for _, l := range labels {
+			l.SetDirection(l.Direction().WithOverride(true))
+		}
+	case "plaintext":
+		for _, l := range labels {
+			l.SetDirection(rendering.TextDirectionAuto)
+		}
+	case "inherit":
+		override := false
+		if parent := elm.Parent.Value(); parent != nil {
+			if parentLabels := childLabels(parent); len(parentLabels) > 0 {
+				override = parentLabels[0].Direction().IsOverride()
+			}
+		}
+		for _, l := range labels {
+			l.SetDirection(l.Direction().WithOverride(override))
+		}
+	default:
+		return fmt.Errorf("invalid value for unicode-bidi: %s", values[0].Str)
+	}
+	return nil
 }
@@ -7,12 +7,17 @@
 package pseudos
 
 import (
-	"errors"
-
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
 )
 
 func (p FocusWithin) Process(elm *document.Element, value rules.SelectorPart) ([]*document.Element, error) {
-	return []*document.Element{elm}, errors.New("not implemented")
+	return []*document.Element{elm}, nil
+}
+
+func (p FocusWithin) AlterRules(inRules []rules.Rule) []rules.Rule {
+	for i := range inRules {
+		inRules[i].Invocation = inRules[i].Invocation.With(rules.RuleInvokeFocusWithin)
+	}
+	return inRules
 }
@@ -135,16 +135,14 @@ func (p Focus) IsFunction() bool { return false }
 // https://developer.mozilla.org/en-US/docs/Web/CSS/:focus-visible
 type FocusVisible struct{}
 
-func (p FocusVisible) Key() string                                { return "focus-visible" }
-func (p FocusVisible) IsFunction() bool                           { return false }
-func (p FocusVisible) AlterRules(rules []rules.Rule) []rules.Rule { return rules }
+func (p FocusVisible) Key() string      { return "focus-visible" }
+func (p FocusVisible) IsFunction() bool { return false }
 
 // https://developer.mozilla.org/en-US/docs/Web/CSS/:focus-within
 type FocusWithin struct{}
 
-func (p FocusWithin) Key() string                                { return "focus-within" }
-func (p FocusWithin) IsFunction() bool                           { return false }
-func (p FocusWithin) AlterRules(rules []rules.Rule) []rules.Rule { return rules }
+func (p FocusWithin) Key() string      { return "focus-within" }
+func (p FocusWithin) IsFunction() bool { return false }
 
 // https://developer.mozilla.org/en-US/docs/Web/CSS/:has
 type Has struct{}
@@ -10,7 +10,6 @@ import (
 	"slices"
 
 	"kaijuengine.com/engine/ui"
-	"kaijuengine.com/engine/ui/markup/css/helpers"
 	"kaijuengine.com/engine/ui/markup/css/pseudos"
 	"kaijuengine.com/engine/ui/markup/css/rules"
 	"kaijuengine.com/engine/ui/markup/document"
@@ -71,6 +70,22 @@ var cssShorthandLonghands = map[string]map[string]struct{}{
 		"border-bottom-color": {},
 		"border-left-color":   {},
 	},
+	"animation": {
+		"animation-name":            {},
+		"animation-duration":        {},
+		"animation-delay":           {},
+		"animation-timing-function": {},
+		"animation-iteration-count": {},
+		"animation-direction":       {},
+		"animation-fill-mode":       {},
+		"animation-play-state":      {},
+	},
+	"transition": {
+		"transition-property":        {},
+		"transition-duration":        {},
+		"transition-delay":           {},
+		"transition-timing-function": {},
+	},
 	"border-radius": {
 		"border-top-left-radius":     {},
 		"border-top-right-radius":    {},
@@ -109,10 +124,11 @@ func (m CSSMap) add(elm *ui.UI, inRules []rules.Rule) {
 	}
 }
 
-func applyMappings(doc *document.Document, cssMap map[*ui.UI][]rules.Rule) {
+func applyMappings(doc *document.Document, cssMap map[*ui.UI][]rules.Rule, keyframes map[string]rules.KeyframeSet) {
 	for _, e := range doc.Elements {
 		// TODO:  Make sure this is applying in order from parent to child
 		// Since this array is intrinsically ordered, it should be fine
+		e.Stylizer.SetKeyframes(keyframes)
 		e.Stylizer.ReplaceRules(cssMap[e.UI])
 		e.UI.Layout().Stylizer = &e.Stylizer
 	}
@@ -286,20 +302,32 @@ type Stylizer struct {
 	Window *windowing.Window
 }
 
+// MediaEnvironment returns what the @media queries of the style sheet are
+// evaluated against, this is the size and density of the window along with
+// the [document.MediaSettings]
+func (z Stylizer) MediaEnvironment() rules.MediaEnvironment {
+	settings := document.CurrentMediaSettings()
+	env := rules.MediaEnvironment{
+		Window:               z.Window,
+		Width:                float32(z.Window.Width()),
+		Height:               float32(z.Window.Height()),
+		Resolution:           1,
+		UIScale:              settings.UIScale,
+		PrefersReducedMotion: settings.PrefersReducedMotion,
+	}
+	// CSS defines 96 dots per inch as 1 dot per pixel (1dppx)
+	if dpmm := z.Window.DotsPerMillimeter(); dpmm > 0 {
+		env.Resolution = float32(dpmm * 25.4 / 96)
+	}
+	return env
+}
+
 func (z Stylizer) ApplyStyles(s rules.StyleSheet, doc *document.Document) {
 	cssMap := CSSMap(make(map[*ui.UI][]rules.Rule))
+	env := z.MediaEnvironment()
 	for _, group := range s.Groups {
-		if group.MediaQuery.IsValid() {
-			switch group.MediaQuery.Key {
-			case "screen":
-			case "max-width":
-				v := helpers.NumFromLength(group.MediaQuery.Value, z.Window)
-				if int(v) <= z.Window.Width() {
-					continue
-				}
-			default:
-				continue
-			}
+		if group.MediaQuery.IsValid() && !group.MediaQuery.Matches(env) {
+			continue
 		}
 		for _, sel := range group.Selectors {
 			if len(sel.Parts) == 1 && (sel.Parts[0].SelectType == rules.ReadingId ||
@@ -320,5 +348,6 @@ func (z Stylizer) ApplyStyles(s rules.StyleSheet, doc *document.Document) {
 		}
 	}
 	cleanMapDuplicates(cssMap)
-	applyMappings(doc, cssMap)
+	resolveCustomProperties(&s, doc, cssMap, z.Window)
+	applyMappings(doc, cssMap, s.Keyframes)
 }
@@ -0,0 +1,237 @@
+/******************************************************************************/
+/* custom_properties.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rules
+
+import (
+	"strings"
+
+	"kaijuengine.com/engine/ui/markup/css/helpers"
+)
+
+// maxVarDepth limits how deep custom properties can reference each other, it
+// mostly exists to stop reference cycles that slip past the visited check
+const maxVarDepth = 32
+
+// VarLookup finds the value of a custom property (--name) that is in scope
+type VarLookup func(name string) ([]string, bool)
+
+// varRefFallback returns the fallback that was given to a deferred var
+// reference (the part after the comma in var(--name, fallback))
+func varRefFallback(s string) (string, bool) {
+	if !strings.HasPrefix(s, varRefSentinel) {
+		return "", false
+	}
+	_, fallback, ok := strings.Cut(s[len(varRefSentinel):], "\x00")
+	return fallback, ok
+}
+
+// ResolveRuleVars substitutes the custom properties found with lookup into
+// the rule and computes the numeric forms of its values. The first time a
+// rule with var() references is resolved, the unresolved values are kept in
+// [Rule.VarSource] so that the rule can be resolved again later on with a
+// different set of custom properties in scope.
+func ResolveRuleVars(r *Rule, lookup VarLookup, window helpers.WindowDimensions) {
+	if r.VarSource == nil && ruleHasVarRefs(r) {
+		r.VarSource = CloneValues(r.Values)
+	}
+	if r.VarSource != nil {
+		r.Values = CloneValues(r.VarSource)
+	}
+	resolved := make([]PropertyValue, 0, len(r.Values))
+	for i := range r.Values {
+		v := r.Values[i]
+		if _, ok := parseVarRef(v.Str); ok {
+			for _, sub := range resolveVarRef(v.Str, lookup, nil, 0) {
+				resolved = append(resolved, PropertyValue{Str: sub})
+			}
+			continue
+		}
+		if len(v.Args) > 0 {
+			args := make([]string, 0, len(v.Args))
+			for _, a := range v.Args {
+				if _, ok := parseVarRef(a); ok {
+					args = append(args, resolveVarRef(a, lookup, nil, 0)...)
+					continue
+				}
+				// Raw text arguments (like those of gradients) can have
+				// var() anywhere within them
+				args = append(args, expandVars(a, lookup, nil, 0))
+			}
+			v.Args = args
+		}
+		resolved = append(resolved, v)
+	}
+	r.Values = resolved
+	for i := range r.Values {
+		v := &r.Values[i]
+		if len(v.Args) > 0 {
+			v.ArgNums = make([]float32, len(v.Args))
+			for j := range v.Args {
+				v.ArgNums[j] = helpers.NumFromLength(v.Args[j], window)
+			}
+		} else {
+			v.Num = helpers.NumFromLength(v.Str, window)
+		}
+	}
+}
+
+// RuleReferencesVar returns true if any var() in the rule refers to one of the
+// given custom property names
+func RuleReferencesVar(r *Rule, names map[string][]string) bool {
+	refers := func(s string) bool {
+		name, ok := parseVarRef(s)
+		if !ok {
+			return textReferencesVar(s, names)
+		}
+		_, ok = names[name]
+		return ok
+	}
+	values := r.VarSource
+	if values == nil {
+		values = r.Values
+	}
+	for i := range values {
+		if refers(values[i].Str) {
+			return true
+		}
+		for _, a := range values[i].Args {
+			if refers(a) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func ruleHasVarRefs(r *Rule) bool {
+	for i := range r.Values {
+		if strings.HasPrefix(r.Values[i].Str, varRefSentinel) {
+			return true
+		}
+		for _, a := range r.Values[i].Args {
+			if strings.HasPrefix(a, varRefSentinel) || strings.Contains(a, "var(") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// textReferencesVar returns true if a var() within the text (or within its
+// fallback) refers to one of the given custom property names
+func textReferencesVar(text string, names map[string][]string) bool {
+	for idx := strings.Index(text, "var("); idx >= 0; idx = strings.Index(text, "var(") {
+		body, rest, ok := cutFunctionBody(text[idx+len("var("):])
+		if !ok {
+			return false
+		}
+		name, fallback, _ := strings.Cut(body, ",")
+		if _, ok := names[strings.TrimSpace(name)]; ok || textReferencesVar(fallback, names) {
+			return true
+		}
+		text = rest
+	}
+	return false
+}
+
+func resolveVarRef(ref string, lookup VarLookup, visiting map[string]bool, depth int) []string {
+	name, _ := parseVarRef(ref)
+	if vals, ok := lookupVar(name, lookup, visiting, depth); ok {
+		return vals
+	}
+	if fallback, ok := varRefFallback(ref); ok {
+		return []string{expandVars(fallback, lookup, visiting, depth+1)}
+	}
+	return nil
+}
+
+func lookupVar(name string, lookup VarLookup, visiting map[string]bool, depth int) ([]string, bool) {
+	if depth > maxVarDepth || visiting[name] {
+		return nil, false
+	}
+	vals, ok := lookup(name)
+	if !ok {
+		return nil, false
+	}
+	// The value of a custom property can itself use var(), those are stored
+	// as text and are expanded here
+	needsExpand := false
+	for i := range vals {
+		needsExpand = needsExpand || strings.Contains(vals[i], "var(")
+	}
+	if !needsExpand {
+		return vals, true
+	}
+	if visiting == nil {
+		visiting = make(map[string]bool)
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+	out := make([]string, len(vals))
+	for i := range vals {
+		out[i] = expandVars(vals[i], lookup, visiting, depth+1)
+	}
+	return out, true
+}
+
+// ExpandVars replaces every var(--name, fallback) found in the text of a
+// custom property value with the value found with lookup
+func ExpandVars(text string, lookup VarLookup) string {
+	return expandVars(text, lookup, nil, 0)
+}
+
+// ExpandCustomProperty is like [ExpandVars] for the value of the custom
+// property with the given name. A reference back to the property itself is a
+// cycle and is treated as if the property wasn't declared.
+func ExpandCustomProperty(name, text string, lookup VarLookup) string {
+	return expandVars(text, lookup, map[string]bool{name: true}, 0)
+}
+
+func expandVars(text string, lookup VarLookup, visiting map[string]bool, depth int) string {
+	idx := strings.Index(text, "var(")
+	if idx < 0 {
+		return text
+	}
+	sb := strings.Builder{}
+	for idx >= 0 {
+		sb.WriteString(text[:idx])
+		body, rest, ok := cutFunctionBody(text[idx+len("var("):])
+		if !ok {
+			sb.WriteString(text[idx:])
+			return sb.String()
+		}
+		name, fallback, hasFallback := strings.Cut(body, ",")
+		name = strings.TrimSpace(name)
+		if vals, ok := lookupVar(name, lookup, visiting, depth); ok {
+			sb.WriteString(strings.Join(vals, " "))
+		} else if hasFallback {
+			sb.WriteString(expandVars(strings.TrimSpace(fallback), lookup, visiting, depth+1))
+		}
+		text = rest
+		idx = strings.Index(text, "var(")
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+// cutFunctionBody splits the text following an opening parenthesis into the
+// body of the function and the text after its closing parenthesis
+func cutFunctionBody(text string) (string, string, bool) {
+	depth := 1
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return text[:i], text[i+1:], true
+			}
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,145 @@
+/******************************************************************************/
+/* keyframes.go                                                               */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rules
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/tdewolff/parse/v2/css"
+)
+
+// Keyframe is a single step of an @keyframes block. Offset is the position of
+// the step within one iteration of the animation, from 0 (from) to 1 (to).
+type Keyframe struct {
+	Offset float32
+	Rules  []Rule
+}
+
+// KeyframeSet is a named @keyframes block, the frames are sorted by offset
+type KeyframeSet struct {
+	Name   string
+	Frames []Keyframe
+}
+
+// keyframesReader holds the @keyframes block that is currently being parsed
+type keyframesReader struct {
+	set     KeyframeSet
+	offsets []float32
+	rules   []Rule
+}
+
+func isKeyframesAtRule(name string) bool {
+	name = strings.ToLower(name)
+	return name == "@keyframes" || strings.HasSuffix(name, "-keyframes")
+}
+
+// Clone creates a deep copy of the keyframe set
+func (k *KeyframeSet) Clone() KeyframeSet {
+	out := KeyframeSet{
+		Name:   k.Name,
+		Frames: make([]Keyframe, len(k.Frames)),
+	}
+	for i := range k.Frames {
+		out.Frames[i] = Keyframe{
+			Offset: k.Frames[i].Offset,
+			Rules:  CloneRules(k.Frames[i].Rules),
+		}
+	}
+	return out
+}
+
+// Properties returns the unique list of properties that are animated by any
+// of the frames, in the order they first appear
+func (k *KeyframeSet) Properties() []string {
+	out := make([]string, 0)
+	for i := range k.Frames {
+		for j := range k.Frames[i].Rules {
+			if !slices.Contains(out, k.Frames[i].Rules[j].Property) {
+				out = append(out, k.Frames[i].Rules[j].Property)
+			}
+		}
+	}
+	return out
+}
+
+// KeyframeOffset converts a keyframe selector (from, to, or a percentage) into
+// an offset between 0 and 1
+func KeyframeOffset(selector string) (float32, bool) {
+	switch strings.ToLower(selector) {
+	case "from":
+		return 0, true
+	case "to":
+		return 1, true
+	}
+	if !strings.HasSuffix(selector, "%") {
+		return 0, false
+	}
+	v, err := strconv.ParseFloat(strings.TrimSuffix(selector, "%"), 32)
+	if err != nil || v < 0 || v > 100 {
+		return 0, false
+	}
+	return float32(v / 100), true
+}
+
+func (s *StyleSheet) beginKeyframes(cssParser *css.Parser) {
+	s.keyframes = &keyframesReader{}
+	for _, val := range cssParser.Values() {
+		switch val.TokenType {
+		case css.IdentToken, css.StringToken:
+			s.keyframes.set.Name = strings.Trim(string(val.Data), `"'`)
+		}
+	}
+}
+
+func (s *StyleSheet) readKeyframeSelector(cssParser *css.Parser) {
+	for _, val := range cssParser.Values() {
+		switch val.TokenType {
+		case css.IdentToken, css.PercentageToken:
+			if offset, ok := KeyframeOffset(string(val.Data)); ok {
+				s.keyframes.offsets = append(s.keyframes.offsets, offset)
+			}
+		}
+	}
+}
+
+func (s *StyleSheet) endKeyframeSelector() {
+	k := s.keyframes
+	for _, offset := range k.offsets {
+		k.set.Frames = append(k.set.Frames, Keyframe{
+			Offset: offset,
+			Rules:  CloneRules(k.rules),
+		})
+	}
+	k.offsets = k.offsets[:0]
+	k.rules = k.rules[:0]
+}
+
+func (s *StyleSheet) endKeyframes() {
+	k := s.keyframes
+	s.keyframes = nil
+	if k.set.Name == "" {
+		return
+	}
+	slices.SortStableFunc(k.set.Frames, func(a, b Keyframe) int {
+		switch {
+		case a.Offset < b.Offset:
+			return -1
+		case a.Offset > b.Offset:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if s.Keyframes == nil {
+		s.Keyframes = make(map[string]KeyframeSet)
+	}
+	// Like other at-rules, a later @keyframes with the same name replaces
+	// the earlier one entirely rather than merging with it
+	s.Keyframes[k.set.Name] = k.set
+}
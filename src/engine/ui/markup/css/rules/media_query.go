@@ -0,0 +1,391 @@
+/******************************************************************************/
+/* media_query.go                                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rules
+
+import (
+	"strconv"
+	"strings"
+
+	"kaijuengine.com/engine/ui/markup/css/helpers"
+)
+
+// MediaEnvironment is what @media queries are evaluated against
+type MediaEnvironment struct {
+	// Window is used to resolve the lengths used in the query (em, mm, etc.)
+	Window helpers.WindowDimensions
+	// Width and Height are the size of the viewport in pixels
+	Width  float32
+	Height float32
+	// Resolution is the pixel density in dots per CSS pixel (dppx)
+	Resolution float32
+	// UIScale is the scale the game applies to its UI, tested with the
+	// custom ui-scale media feature
+	UIScale float32
+	// PrefersReducedMotion is tested with prefers-reduced-motion
+	PrefersReducedMotion bool
+}
+
+type mediaCompare uint8
+
+const (
+	mediaCompareExists mediaCompare = iota
+	mediaCompareEqual
+	mediaCompareLess
+	mediaCompareLessEqual
+	mediaCompareGreater
+	mediaCompareGreaterEqual
+)
+
+// mediaFeature is a single test like (min-width: 600px) or (width >= 600px),
+// min- and max- prefixes are converted into comparisons when parsed
+type mediaFeature struct {
+	name    string
+	compare mediaCompare
+	value   string
+}
+
+// mediaQueryItem is one of the comma separated queries of an @media rule
+type mediaQueryItem struct {
+	not      bool
+	invalid  bool
+	features []mediaFeature
+}
+
+// MediaQuery is the query of an @media rule. A query that could not be
+// understood (or an at-rule that isn't @media) never matches, which is the
+// same as a browser treating it as "not all".
+type MediaQuery struct {
+	Query string
+	items []mediaQueryItem
+}
+
+func (m *MediaQuery) IsValid() bool { return m.Query != "" }
+
+func (m *MediaQuery) Clear() {
+	m.Query = ""
+	m.items = nil
+}
+
+// ParseMediaQuery reads the text of an @media query, for example
+// "screen and (min-width: 600px), (orientation: portrait)"
+func ParseMediaQuery(query string) MediaQuery {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		query = "all"
+	}
+	m := MediaQuery{Query: query}
+	for _, part := range splitMediaList(strings.ToLower(query)) {
+		m.items = append(m.items, parseMediaQueryItem(part))
+	}
+	return m
+}
+
+// unsupportedAtRule creates a query that never matches, it is used for the
+// at-rules (other than @media and @keyframes) that the UI does not support
+// so that their contents are skipped
+func unsupportedAtRule(name, prelude string) MediaQuery {
+	return MediaQuery{Query: strings.TrimSpace(name + " " + prelude)}
+}
+
+// splitMediaList splits the query on the commas (and the level 4 "or"
+// keyword) that are not inside of parentheses
+func splitMediaList(query string) []string {
+	out := make([]string, 0, 1)
+	depth, start := 0, 0
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				out = append(out, query[start:i])
+				start = i + 1
+			}
+		case ' ':
+			if depth == 0 && strings.HasPrefix(query[i:], " or ") {
+				out = append(out, query[start:i])
+				start = i + len(" or ")
+				i = start - 1
+			}
+		}
+	}
+	return append(out, query[start:])
+}
+
+func parseMediaQueryItem(text string) mediaQueryItem {
+	item := mediaQueryItem{}
+	words := make([]string, 0)
+	depth, start := 0, -1
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '(':
+			if depth == 0 {
+				start = i + 1
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 && start >= 0 {
+				features, ok := parseMediaFeature(text[start:i])
+				if !ok {
+					item.invalid = true
+				}
+				item.features = append(item.features, features...)
+				start = -1
+			} else if depth < 0 {
+				item.invalid = true
+			}
+		case depth == 0 && c != ' ' && c != '\t' && c != '\n':
+			end := i
+			for end < len(text) && text[end] != ' ' && text[end] != '(' {
+				end++
+			}
+			words = append(words, text[i:end])
+			i = end - 1
+		}
+	}
+	if depth != 0 {
+		item.invalid = true
+	}
+	for i, w := range words {
+		switch w {
+		case "not":
+			item.not = i == 0
+			item.invalid = item.invalid || i != 0
+		case "only", "and", "all", "screen":
+		case "print", "speech":
+			// The UI is only ever shown on a screen
+			item.features = append(item.features, mediaFeature{name: "print"})
+		default:
+			item.invalid = true
+		}
+	}
+	return item
+}
+
+func parseMediaFeature(text string) ([]mediaFeature, bool) {
+	text = strings.TrimSpace(text)
+	if name, value, ok := strings.Cut(text, ":"); ok {
+		f := mediaFeature{
+			name:    strings.TrimSpace(name),
+			compare: mediaCompareEqual,
+			value:   strings.TrimSpace(value),
+		}
+		if n, ok := strings.CutPrefix(f.name, "min-"); ok {
+			f.name, f.compare = n, mediaCompareGreaterEqual
+		} else if n, ok := strings.CutPrefix(f.name, "max-"); ok {
+			f.name, f.compare = n, mediaCompareLessEqual
+		}
+		return []mediaFeature{f}, isKnownMediaFeature(f.name)
+	}
+	parts, ops := splitMediaRange(text)
+	switch len(parts) {
+	case 1:
+		f := mediaFeature{name: parts[0], compare: mediaCompareExists}
+		return []mediaFeature{f}, isKnownMediaFeature(f.name)
+	case 2:
+		if isKnownMediaFeature(parts[0]) {
+			// (width >= 600px)
+			return []mediaFeature{{name: parts[0], compare: ops[0], value: parts[1]}},
+				ops[0] != mediaCompareExists
+		}
+		// (600px <= width)
+		f := mediaFeature{name: parts[1], compare: flipMediaCompare(ops[0]), value: parts[0]}
+		return []mediaFeature{f}, isKnownMediaFeature(f.name) && ops[0] != mediaCompareExists
+	case 3:
+		// (400px <= width <= 700px)
+		name := parts[1]
+		return []mediaFeature{
+			{name: name, compare: flipMediaCompare(ops[0]), value: parts[0]},
+			{name: name, compare: ops[1], value: parts[2]},
+		}, isKnownMediaFeature(name) && ops[0] != mediaCompareExists && ops[1] != mediaCompareExists
+	}
+	return nil, false
+}
+
+func splitMediaRange(text string) ([]string, []mediaCompare) {
+	parts := make([]string, 0, 3)
+	ops := make([]mediaCompare, 0, 2)
+	start := 0
+	for i := 0; i < len(text); i++ {
+		op := mediaCompareExists
+		size := 1
+		switch text[i] {
+		case '<':
+			op = mediaCompareLess
+		case '>':
+			op = mediaCompareGreater
+		case '=':
+			op = mediaCompareEqual
+		default:
+			continue
+		}
+		if op != mediaCompareEqual && i+1 < len(text) && text[i+1] == '=' {
+			op++
+			size = 2
+		}
+		parts = append(parts, strings.TrimSpace(text[start:i]))
+		ops = append(ops, op)
+		start = i + size
+		i = start - 1
+	}
+	return append(parts, strings.TrimSpace(text[start:])), ops
+}
+
+func flipMediaCompare(op mediaCompare) mediaCompare {
+	switch op {
+	case mediaCompareLess:
+		return mediaCompareGreater
+	case mediaCompareLessEqual:
+		return mediaCompareGreaterEqual
+	case mediaCompareGreater:
+		return mediaCompareLess
+	case mediaCompareGreaterEqual:
+		return mediaCompareLessEqual
+	default:
+		return op
+	}
+}
+
+func isKnownMediaFeature(name string) bool {
+	switch name {
+	case "width", "height", "aspect-ratio", "orientation", "resolution",
+		"prefers-reduced-motion", "ui-scale":
+		return true
+	}
+	return false
+}
+
+// Matches returns true if any of the comma separated queries match
+func (m *MediaQuery) Matches(env MediaEnvironment) bool {
+	for i := range m.items {
+		if m.items[i].matches(env) {
+			return true
+		}
+	}
+	return false
+}
+
+func (q *mediaQueryItem) matches(env MediaEnvironment) bool {
+	if q.invalid {
+		return false
+	}
+	all := true
+	for i := range q.features {
+		if !q.features[i].matches(env) {
+			all = false
+			break
+		}
+	}
+	return all != q.not
+}
+
+func (f *mediaFeature) matches(env MediaEnvironment) bool {
+	switch f.name {
+	case "print":
+		return false
+	case "width":
+		return f.compareNumber(env.Width, helpers.NumFromLength(f.value, env.Window))
+	case "height":
+		return f.compareNumber(env.Height, helpers.NumFromLength(f.value, env.Window))
+	case "aspect-ratio":
+		if env.Height <= 0 {
+			return false
+		}
+		ratio, ok := parseMediaRatio(f.value)
+		return ok && f.compareNumber(env.Width/env.Height, ratio)
+	case "orientation":
+		portrait := env.Height >= env.Width
+		switch f.value {
+		case "":
+			return true
+		case "portrait":
+			return portrait
+		case "landscape":
+			return !portrait
+		}
+		return false
+	case "resolution":
+		res, ok := parseMediaResolution(f.value)
+		return ok && f.compareNumber(env.Resolution, res)
+	case "prefers-reduced-motion":
+		switch f.value {
+		case "":
+			return env.PrefersReducedMotion
+		case "reduce":
+			return env.PrefersReducedMotion
+		case "no-preference":
+			return !env.PrefersReducedMotion
+		}
+		return false
+	case "ui-scale":
+		v, err := strconv.ParseFloat(f.value, 32)
+		return err == nil && f.compareNumber(env.UIScale, float32(v))
+	}
+	return false
+}
+
+func (f *mediaFeature) compareNumber(actual, expected float32) bool {
+	// Lengths are compared with a little tolerance so that a query written
+	// for an exact size (1280px) isn't lost to float rounding
+	const epsilon = 0.0001
+	switch f.compare {
+	case mediaCompareExists:
+		return actual != 0
+	case mediaCompareEqual:
+		return actual > expected-epsilon && actual < expected+epsilon
+	case mediaCompareLess:
+		return actual < expected-epsilon
+	case mediaCompareLessEqual:
+		return actual < expected+epsilon
+	case mediaCompareGreater:
+		return actual > expected+epsilon
+	case mediaCompareGreaterEqual:
+		return actual > expected-epsilon
+	}
+	return false
+}
+
+func parseMediaRatio(str string) (float32, bool) {
+	num, den, hasDen := strings.Cut(str, "/")
+	n, err := strconv.ParseFloat(strings.TrimSpace(num), 32)
+	if err != nil {
+		return 0, false
+	}
+	if !hasDen {
+		return float32(n), true
+	}
+	d, err := strconv.ParseFloat(strings.TrimSpace(den), 32)
+	if err != nil || d == 0 {
+		return 0, false
+	}
+	return float32(n / d), true
+}
+
+func parseMediaResolution(str string) (float32, bool) {
+	units := []struct {
+		suffix string
+		scale  float64
+	}{
+		{"dppx", 1},
+		{"dpcm", 2.54 / 96},
+		{"dpi", 1.0 / 96},
+		{"x", 1},
+	}
+	for _, u := range units {
+		if v, ok := strings.CutSuffix(str, u.suffix); ok {
+			f, err := strconv.ParseFloat(v, 32)
+			if err != nil {
+				return 0, false
+			}
+			return float32(f * u.scale), true
+		}
+	}
+	return 0, false
+}
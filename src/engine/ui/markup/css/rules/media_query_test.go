@@ -0,0 +1,119 @@
+/******************************************************************************/
+/* media_query_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rules
+
+import "testing"
+
+func testMediaEnv(width, height float32) MediaEnvironment {
+	return MediaEnvironment{
+		Window:     dummyWindow{},
+		Width:      width,
+		Height:     height,
+		Resolution: 1,
+		UIScale:    1,
+	}
+}
+
+func TestMediaQueryFeatures(t *testing.T) {
+	landscape := testMediaEnv(1280, 720)
+	portrait := testMediaEnv(600, 900)
+	tests := []struct {
+		query     string
+		landscape bool
+		portrait  bool
+	}{
+		{"screen", true, true},
+		{"print", false, false},
+		{"not print", true, true},
+		{"(min-width: 800px)", true, false},
+		{"(max-width: 600px)", false, true},
+		{"screen and (max-width: 1280px)", true, true},
+		{"(width > 600px)", true, false},
+		{"(600px < width)", true, false},
+		{"(400px <= width <= 700px)", false, true},
+		{"(height >= 800px)", false, true},
+		{"(orientation: portrait)", false, true},
+		{"(orientation: landscape)", true, false},
+		{"(aspect-ratio: 16/9)", true, false},
+		{"(min-aspect-ratio: 1/1)", true, false},
+		{"(max-aspect-ratio: 1/1)", false, true},
+		{"(min-resolution: 2dppx)", false, false},
+		{"(resolution: 96dpi)", true, true},
+		{"(prefers-reduced-motion: no-preference)", true, true},
+		{"(prefers-reduced-motion)", false, false},
+		{"(ui-scale: 1)", true, true},
+		{"(min-ui-scale: 1.5)", false, false},
+		{"(max-width: 600px), (orientation: landscape)", true, true},
+		{"(max-width: 600px) or (min-width: 1000px)", true, true},
+		{"not (orientation: portrait)", true, false},
+		{"(unknown-feature: 1)", false, false},
+		{"(min-width: 800px", false, false},
+	}
+	for _, test := range tests {
+		q := ParseMediaQuery(test.query)
+		if got := q.Matches(landscape); got != test.landscape {
+			t.Errorf("%q on landscape expected %v, got %v", test.query, test.landscape, got)
+		}
+		if got := q.Matches(portrait); got != test.portrait {
+			t.Errorf("%q on portrait expected %v, got %v", test.query, test.portrait, got)
+		}
+	}
+}
+
+func TestMediaQuerySettings(t *testing.T) {
+	env := testMediaEnv(800, 600)
+	env.UIScale = 2
+	env.PrefersReducedMotion = true
+	env.Resolution = 2
+	for _, query := range []string{
+		"(prefers-reduced-motion: reduce)",
+		"(prefers-reduced-motion)",
+		"(min-ui-scale: 1.5)",
+		"(ui-scale >= 2)",
+		"(min-resolution: 2x)",
+		"(resolution: 192dpi)",
+	} {
+		q := ParseMediaQuery(query)
+		if !q.Matches(env) {
+			t.Errorf("expected %q to match", query)
+		}
+	}
+}
+
+const testCSSMedia = `.a { width: 1px; }
+@media screen and (400px <= width <= 700px), (orientation: portrait) {
+	.b { width: 2px; }
+	.c { width: 3px; }
+}
+@supports (display: grid) { .d { width: 4px; } }
+.e { width: 5px; }`
+
+func TestParseMediaQueryGroups(t *testing.T) {
+	s := NewStyleSheet()
+	s.Parse(testCSSMedia, dummyWindow{})
+	wide := testMediaEnv(1280, 720)
+	narrow := testMediaEnv(500, 720)
+	matching := func(env MediaEnvironment) []string {
+		out := []string{}
+		for _, g := range s.Groups {
+			if len(g.Selectors) == 0 {
+				continue
+			}
+			if g.MediaQuery.IsValid() && !g.MediaQuery.Matches(env) {
+				continue
+			}
+			out = append(out, g.Selectors[0].Parts[0].Name)
+		}
+		return out
+	}
+	if got := matching(wide); len(got) != 2 || got[0] != "a" || got[1] != "e" {
+		t.Fatalf("expected only .a and .e on a wide window, got %v", got)
+	}
+	if got := matching(narrow); len(got) != 4 || got[1] != "b" || got[2] != "c" {
+		t.Fatalf("expected the media groups on a narrow window, got %v", got)
+	}
+}
@@ -20,6 +20,8 @@ import (
 type StyleSheet struct {
 	Groups         []SelectorGroup
 	CustomVars     map[string][]string
+	Keyframes      map[string]KeyframeSet
+	keyframes      *keyframesReader
 	state          RuleState
 	stateFuncDepth int
 }
@@ -34,14 +36,101 @@ const varRefSentinel = "\x00var:"
 func makeVarRef(name string) string { return varRefSentinel + name }
 
 // parseVarRef returns the custom property name and true when s is a deferred
-// var reference produced by makeVarRef.
+// var reference produced by makeVarRef. A fallback, if one was given, follows
+// the name after another NUL byte, see varRefFallback.
 func parseVarRef(s string) (string, bool) {
 	if strings.HasPrefix(s, varRefSentinel) {
-		return s[len(varRefSentinel):], true
+		name, _, _ := strings.Cut(s[len(varRefSentinel):], "\x00")
+		return name, true
 	}
 	return "", false
 }
 
+// varFallbackReader collects the raw text of the fallback of a var() while
+// the values of a property are being read
+type varFallbackReader struct {
+	// depth is the function depth of the var() that owns the fallback
+	depth int
+	// value and arg locate the deferred reference the fallback belongs to,
+	// arg is -1 when the reference is a top-level value
+	value   int
+	arg     int
+	started bool
+	text    strings.Builder
+}
+
+func (f *varFallbackReader) finish(r *Rule) {
+	if !f.started {
+		return
+	}
+	ref := &r.Values[f.value].Str
+	if f.arg >= 0 {
+		ref = &r.Values[f.value].Args[f.arg]
+	}
+	*ref += "\x00" + strings.TrimSpace(f.text.String())
+}
+
+// rawArgFunctions are the functions whose arguments are kept as text, split
+// on the top-level commas, rather than as a flat list of tokens. Their
+// arguments hold nested functions and need the commas to be understood.
+var rawArgFunctions = map[string]bool{
+	"linear-gradient":           true,
+	"radial-gradient":           true,
+	"conic-gradient":            true,
+	"repeating-linear-gradient": true,
+	"repeating-radial-gradient": true,
+	"repeating-conic-gradient":  true,
+	"repeat":                    true,
+}
+
+// commaListProperties are the properties that are a comma separated list of
+// layers, the top-level commas are kept as a "," value so the layers can be
+// told apart when the property is processed
+var commaListProperties = map[string]bool{
+	"box-shadow":  true,
+	"text-shadow": true,
+}
+
+// rawArgsReader collects the text of the arguments of one of the
+// [rawArgFunctions] while the values of a property are being read
+type rawArgsReader struct {
+	depth int
+	args  []string
+	text  strings.Builder
+}
+
+func (a *rawArgsReader) read(val css.Token) (done bool) {
+	switch val.TokenType {
+	case css.FunctionToken, css.LeftParenthesisToken:
+		a.depth++
+	case css.RightParenthesisToken:
+		a.depth--
+		if a.depth == 0 {
+			a.split()
+			return true
+		}
+	case css.CommaToken:
+		if a.depth == 1 {
+			a.split()
+			return false
+		}
+	case css.CommentToken:
+		return false
+	case css.WhitespaceToken:
+		a.text.WriteByte(' ')
+		return false
+	}
+	a.text.Write(val.Data)
+	return false
+}
+
+func (a *rawArgsReader) split() {
+	if arg := strings.TrimSpace(a.text.String()); arg != "" {
+		a.args = append(a.args, arg)
+	}
+	a.text.Reset()
+}
+
 func (s *StyleSheet) addGroup() {
 	g := SelectorGroup{
 		Selectors: make([]Selector, 0),
@@ -201,19 +290,63 @@ func (s *StyleSheet) readSelector(cssParser *css.Parser) {
 }
 
 func (s *StyleSheet) readProperty(prop string, cssParser *css.Parser, _ helpers.WindowDimensions) {
+	r := s.parseProperty(prop, cssParser)
+	if s.keyframes != nil {
+		s.keyframes.rules = append(s.keyframes.rules, r)
+	} else {
+		s.currentGroup().AddRule(r)
+	}
+}
+
+func (s *StyleSheet) parseProperty(prop string, cssParser *css.Parser) Rule {
 	r := Rule{
 		Property: prop,
 		Values:   make([]PropertyValue, 0),
 	}
+	var fallback *varFallbackReader
+	var rawArgs *rawArgsReader
 	for _, val := range cssParser.Values() {
+		if rawArgs != nil {
+			if rawArgs.read(val) {
+				r.Values[len(r.Values)-1].Args = rawArgs.args
+				rawArgs = nil
+			}
+			continue
+		}
+		if fallback != nil {
+			if val.TokenType == css.RightParenthesisToken && s.stateFuncDepth == fallback.depth {
+				fallback.finish(&r)
+				fallback = nil
+			} else {
+				switch val.TokenType {
+				case css.FunctionToken:
+					s.stateFuncDepth++
+				case css.RightParenthesisToken:
+					s.stateFuncDepth--
+				}
+				if fallback.started {
+					fallback.text.Write(val.Data)
+				} else {
+					fallback.started = val.TokenType == css.CommaToken
+				}
+				continue
+			}
+		}
 		switch val.TokenType {
 		case css.FunctionToken:
+			name := strings.TrimSuffix(string(val.Data), "(")
+			if rawArgFunctions[strings.ToLower(name)] {
+				r.Values = append(r.Values, PropertyValue{Str: name})
+				rawArgs = &rawArgsReader{depth: 1}
+				continue
+			}
 			s.stateFuncDepth++
 			s.state = ReadingPropertyFunction
-			r.Values = append(r.Values, PropertyValue{
-				Str: strings.TrimSuffix(string(val.Data), "("),
-			})
+			r.Values = append(r.Values, PropertyValue{Str: name})
 		case css.CommaToken:
+			if s.state != ReadingPropertyFunction && commaListProperties[prop] {
+				r.Values = append(r.Values, PropertyValue{Str: ","})
+			}
 		case css.CommentToken:
 		case css.WhitespaceToken:
 		case css.RightParenthesisToken:
@@ -238,12 +371,22 @@ func (s *StyleSheet) readProperty(prop string, cssParser *css.Parser, _ helpers.
 						// function's argument list, preserving argument order.
 						last = &r.Values[len(r.Values)-1]
 						last.Args = append(last.Args, makeVarRef(str))
+						fallback = &varFallbackReader{
+							depth: s.stateFuncDepth,
+							value: len(r.Values) - 1,
+							arg:   len(last.Args) - 1,
+						}
 					} else {
 						// Top-level var(): record a deferred placeholder value
 						// that will expand into zero or more values later.
 						r.Values = append(r.Values, PropertyValue{
 							Str: makeVarRef(str),
 						})
+						fallback = &varFallbackReader{
+							depth: s.stateFuncDepth,
+							value: len(r.Values) - 1,
+							arg:   -1,
+						}
 					}
 				} else {
 					last.Args = append(last.Args, str)
@@ -257,7 +400,7 @@ func (s *StyleSheet) readProperty(prop string, cssParser *css.Parser, _ helpers.
 	}
 	// Numeric resolution is intentionally deferred to resolveRuleVars (called
 	// post-parse) because deferred var references are not yet substituted here.
-	s.currentGroup().AddRule(r)
+	return r
 }
 
 // resolveVars walks every parsed rule in the sheet and substitutes the final
@@ -268,54 +411,78 @@ func (s *StyleSheet) resolveVars(window helpers.WindowDimensions) {
 	for gi := range s.Groups {
 		g := &s.Groups[gi]
 		for ri := range g.Rules {
-			s.resolveRuleVars(&g.Rules[ri], window)
+			if !g.Rules[ri].IsCustomProperty() {
+				s.resolveRuleVars(&g.Rules[ri], window)
+			}
+		}
+	}
+	for _, k := range s.Keyframes {
+		for fi := range k.Frames {
+			for ri := range k.Frames[fi].Rules {
+				s.resolveRuleVars(&k.Frames[fi].Rules[ri], window)
+			}
 		}
 	}
 }
 
-// resolveRuleVars substitutes deferred var references in a single rule and then
-// computes the numeric forms of every value. An unknown custom property resolves
+// resolveRuleVars substitutes deferred var references in a single rule using
+// the sheet's global (:root) custom properties and then computes the numeric
+// forms of every value. An unknown custom property without a fallback resolves
 // to nothing, preserving the previous eager behavior.
 func (s *StyleSheet) resolveRuleVars(r *Rule, window helpers.WindowDimensions) {
-	// Expand top-level deferred var placeholders. A custom property can expand
-	// to multiple tokens, so the value slice is rebuilt.
-	resolved := make([]PropertyValue, 0, len(r.Values))
-	for i := range r.Values {
-		v := r.Values[i]
-		if name, ok := parseVarRef(v.Str); ok {
-			for _, sub := range s.CustomVars[name] {
-				resolved = append(resolved, PropertyValue{Str: sub})
-			}
-			continue
-		}
-		// Expand deferred var references stored inside function arguments
-		// (e.g. var() nested in calc()), preserving argument order.
-		if len(v.Args) > 0 {
-			args := make([]string, 0, len(v.Args))
-			for _, a := range v.Args {
-				if name, ok := parseVarRef(a); ok {
-					args = append(args, s.CustomVars[name]...)
-					continue
-				}
-				args = append(args, a)
-			}
-			v.Args = args
-		}
-		resolved = append(resolved, v)
+	ResolveRuleVars(r, s.lookupVar, window)
+}
+
+func (s *StyleSheet) lookupVar(name string) ([]string, bool) {
+	v, ok := s.CustomVars[name]
+	return v, ok
+}
+
+// isRootScope returns true if the ruleset being read only selects :root, the
+// custom properties declared in it are global to the sheet
+func (s *StyleSheet) isRootScope(qualifiedGroupStart int) bool {
+	start := len(s.Groups) - 1
+	if qualifiedGroupStart >= 0 {
+		start = qualifiedGroupStart
 	}
-	r.Values = resolved
-
-	for i := range r.Values {
-		v := &r.Values[i]
-		if len(v.Args) > 0 {
-			v.ArgNums = make([]float32, len(v.Args))
-			for j := range v.Args {
-				v.ArgNums[j] = helpers.NumFromLength(v.Args[j], window)
+	count := 0
+	for i := start; i < len(s.Groups); i++ {
+		for _, sel := range s.Groups[i].Selectors {
+			if len(sel.Parts) != 1 || sel.Parts[0].SelectType != ReadingPseudo ||
+				sel.Parts[0].Name != "root" {
+				return false
 			}
-		} else {
-			v.Num = helpers.NumFromLength(v.Str, window)
+			count++
 		}
 	}
+	return count > 0
+}
+
+func (s *StyleSheet) readCustomProperty(name string, cssParser *css.Parser, global bool) {
+	vals := make([]string, 0)
+	for _, val := range cssParser.Values() {
+		vals = append(vals, strings.TrimSpace(string(val.Data)))
+	}
+	if global {
+		s.CustomVars[name] = vals
+		return
+	}
+	// Custom properties declared on any other selector are scoped to the
+	// matching elements (and inherited by their children), they are resolved
+	// per element when the styles are applied
+	r := Rule{Property: name, Values: make([]PropertyValue, len(vals))}
+	for i := range vals {
+		r.Values[i] = PropertyValue{Str: vals[i]}
+	}
+	s.currentGroup().AddRule(r)
+}
+
+func (s *StyleSheet) readAtRulePrelude(cssParser *css.Parser) string {
+	sb := strings.Builder{}
+	for _, val := range cssParser.Values() {
+		sb.Write(val.Data)
+	}
+	return sb.String()
 }
 
 func NewStyleSheet() StyleSheet {
@@ -323,6 +490,7 @@ func NewStyleSheet() StyleSheet {
 		Groups:     make([]SelectorGroup, 0),
 		state:      ReadingTag,
 		CustomVars: make(map[string][]string),
+		Keyframes:  make(map[string]KeyframeSet),
 	}
 }
 
@@ -339,25 +507,23 @@ func (s *StyleSheet) Parse(cssStr string, window helpers.WindowDimensions) {
 		case css.CommentGrammar:
 			// Do nothing
 		case css.BeginAtRuleGrammar:
-			q := MediaQuery{}
-			for _, val := range cssParser.Values() {
-				if val.TokenType == css.WhitespaceToken {
-					continue
-				}
-				v := string(val.Data)
-				switch v {
-				case "(", ":", ")":
-				default:
-					if q.Key == "" {
-						q.Key = v
-					} else {
-						q.Value = v
-					}
-				}
+			if isKeyframesAtRule(string(propData)) {
+				s.beginKeyframes(cssParser)
+				break
+			}
+			name := string(propData)
+			prelude := s.readAtRulePrelude(cssParser)
+			if strings.EqualFold(name, "@media") {
+				s.setGroupMediaQuery(ParseMediaQuery(prelude))
+			} else {
+				s.setGroupMediaQuery(unsupportedAtRule(name, prelude))
 			}
-			s.setGroupMediaQuery(q)
 		case css.AtRuleGrammar:
 		case css.QualifiedRuleGrammar:
+			if s.keyframes != nil {
+				s.readKeyframeSelector(cssParser)
+				break
+			}
 			if qualifiedGroupStart < 0 {
 				qualifiedGroupStart = len(s.Groups) - 1
 			}
@@ -366,14 +532,26 @@ func (s *StyleSheet) Parse(cssStr string, window helpers.WindowDimensions) {
 			}
 			s.addGroup()
 		case css.BeginRulesetGrammar:
-			s.readSelector(cssParser)
+			if s.keyframes != nil {
+				s.readKeyframeSelector(cssParser)
+			} else {
+				s.readSelector(cssParser)
+			}
 			s.state = ReadingProperty
 		case css.EndAtRuleGrammar:
 			s.state = ReadingTag
+			if s.keyframes != nil {
+				s.endKeyframes()
+				break
+			}
 			s.addGroup()
 			s.clearGroupMediaQuery()
 		case css.EndRulesetGrammar:
 			s.state = ReadingTag
+			if s.keyframes != nil {
+				s.endKeyframeSelector()
+				break
+			}
 			if qualifiedGroupStart >= 0 {
 				last := &s.Groups[len(s.Groups)-1]
 				for i := len(s.Groups) - 2; i >= qualifiedGroupStart; i-- {
@@ -389,12 +567,11 @@ func (s *StyleSheet) Parse(cssStr string, window helpers.WindowDimensions) {
 			s.readProperty(string(propData), cssParser, window)
 		case css.TokenGrammar:
 		case css.CustomPropertyGrammar:
-			name := string(propData)
-			vals := make([]string, 0)
-			for _, val := range cssParser.Values() {
-				vals = append(vals, strings.TrimSpace(string(val.Data)))
+			if s.keyframes != nil {
+				// Custom properties can't be animated, they are dropped
+				break
 			}
-			s.CustomVars[name] = vals
+			s.readCustomProperty(string(propData), cssParser, s.isRootScope(qualifiedGroupStart))
 		}
 	}
 	// All custom properties are now known with their final (last :root wins)
@@ -416,6 +593,8 @@ func (s *StyleSheet) ParseInline(cssStr string, window helpers.WindowDimensions)
 			// Do nothing
 		case css.DeclarationGrammar:
 			s.readProperty(string(propData), cssParser, window)
+		case css.CustomPropertyGrammar:
+			s.readCustomProperty(string(propData), cssParser, false)
 		}
 	}
 	// Resolve any deferred var references using whatever custom properties are
@@ -423,7 +602,9 @@ func (s *StyleSheet) ParseInline(cssStr string, window helpers.WindowDimensions)
 	// :root block) and compute numeric forms.
 	group := s.currentGroup()
 	for ri := range group.Rules {
-		s.resolveRuleVars(&group.Rules[ri], window)
+		if !group.Rules[ri].IsCustomProperty() {
+			s.resolveRuleVars(&group.Rules[ri], window)
+		}
 	}
 	s.removeLastGroup()
 	return group
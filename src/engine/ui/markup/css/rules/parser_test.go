@@ -398,3 +398,231 @@ func TestParseVariableInCalcLaterRoot(t *testing.T) {
 		}
 	}
 }
+
+const testCSSKeyframes = `:root { --half: 0.5; }
+@keyframes pulse {
+	from { opacity: 0; }
+	50%, 75% { opacity: var(--half); }
+	to { opacity: 1; background-color: red; }
+}
+.test { animation: pulse 1s infinite; }`
+
+func TestParseKeyframes(t *testing.T) {
+	s := NewStyleSheet()
+	s.Parse(testCSSKeyframes, dummyWindow{})
+	k, ok := s.Keyframes["pulse"]
+	if !ok {
+		t.Fatalf("expected the pulse keyframes to be parsed, got %#v", s.Keyframes)
+	}
+	expectedOffsets := []float32{0, 0.5, 0.75, 1}
+	if len(k.Frames) != len(expectedOffsets) {
+		t.Fatalf("expected %d frames, got %d", len(expectedOffsets), len(k.Frames))
+	}
+	for i := range expectedOffsets {
+		if k.Frames[i].Offset != expectedOffsets[i] {
+			t.Fatalf("frame %d expected offset %f, got %f", i, expectedOffsets[i], k.Frames[i].Offset)
+		}
+	}
+	if v := k.Frames[1].Rules[0].Values[0].Str; v != "0.5" {
+		t.Fatalf("expected the var in the keyframe to resolve to 0.5, got %q", v)
+	}
+	if len(k.Frames[3].Rules) != 2 {
+		t.Fatalf("expected 2 rules in the last frame, got %d", len(k.Frames[3].Rules))
+	}
+	props := k.Properties()
+	if len(props) != 2 || props[0] != "opacity" || props[1] != "background-color" {
+		t.Fatalf("unexpected animated properties %v", props)
+	}
+}
+
+func TestParseKeyframesDoesNotLeakIntoGroups(t *testing.T) {
+	s := NewStyleSheet()
+	s.Parse(testCSSKeyframes, dummyWindow{})
+	for i := range s.Groups {
+		if s.Groups[i].MediaQuery.IsValid() {
+			t.Fatalf("group %d picked up the keyframes as a media query", i)
+		}
+		for _, sel := range s.Groups[i].Selectors {
+			for _, p := range sel.Parts {
+				if p.Name == "from" || p.Name == "to" || p.Name == "50%" {
+					t.Fatalf("keyframe selector %q was read as a style selector", p.Name)
+				}
+			}
+		}
+	}
+	found := false
+	for i := range s.Groups {
+		for _, r := range s.Groups[i].Rules {
+			found = found || r.Property == "animation"
+		}
+	}
+	if !found {
+		t.Fatal("expected the rule after the keyframes to be parsed")
+	}
+}
+
+const testCSSVarFallback = `:root { --known: 3px; }
+.a { width: var(--missing, 12px); height: var(--known, 99px); }
+.b { margin: calc(10px + var(--missing, calc(1px + 2px))); }
+.c { padding: var(--missing, var(--known)); }`
+
+func TestParseVariableFallback(t *testing.T) {
+	s := NewStyleSheet()
+	s.Parse(testCSSVarFallback, dummyWindow{})
+	expect := func(g, r int, want string) {
+		t.Helper()
+		rule := s.Groups[g].Rules[r]
+		if len(rule.Values) != 1 || rule.Values[0].Str != want {
+			t.Fatalf("expected %s to be %q, got %#v", rule.Property, want, rule.Values)
+		}
+		if rule.VarSource == nil {
+			t.Fatalf("expected %s to keep its unresolved values", rule.Property)
+		}
+	}
+	expect(1, 0, "12px")
+	expect(1, 1, "3px")
+	expect(3, 0, "3px")
+	calc := s.Groups[2].Rules[0].Values[0]
+	expectedArgs := []string{"10px", "+", "calc(1px + 2px)"}
+	if calc.Str != "calc" || len(calc.Args) != len(expectedArgs) {
+		t.Fatalf("unexpected calc value %#v", calc)
+	}
+	for i := range expectedArgs {
+		if calc.Args[i] != expectedArgs[i] {
+			t.Fatalf("arg %d expected %q, got %q", i, expectedArgs[i], calc.Args[i])
+		}
+	}
+}
+
+const testCSSScopedVars = `:root { --accent: red; --size: var(--base); --base: 4px; }
+.panel { --accent: blue; color: var(--accent); }
+.panel:hover { --accent: green; }
+.x { width: var(--size); }`
+
+func TestParseScopedCustomProperties(t *testing.T) {
+	s := NewStyleSheet()
+	s.Parse(testCSSScopedVars, dummyWindow{})
+	if v := s.CustomVars["--accent"]; len(v) != 1 || v[0] != "red" {
+		t.Fatalf("expected only the :root value to be global, got %#v", v)
+	}
+	panel := s.Groups[1]
+	if len(panel.Rules) != 2 || !panel.Rules[0].IsCustomProperty() ||
+		panel.Rules[0].Values[0].Str != "blue" {
+		t.Fatalf("expected the .panel custom property to be a scoped rule, got %#v", panel.Rules)
+	}
+	// Resolved against the global scope at parse time, the element scope is
+	// applied when the styles are applied
+	if panel.Rules[1].Values[0].Str != "red" {
+		t.Fatalf("expected color to resolve against :root, got %q", panel.Rules[1].Values[0].Str)
+	}
+	scoped := map[string][]string{"--accent": {"blue"}}
+	ResolveRuleVars(&panel.Rules[1], func(name string) ([]string, bool) {
+		v, ok := scoped[name]
+		return v, ok
+	}, dummyWindow{})
+	if panel.Rules[1].Values[0].Str != "blue" {
+		t.Fatalf("expected color to resolve against the scope, got %q", panel.Rules[1].Values[0].Str)
+	}
+	if !RuleReferencesVar(&panel.Rules[1], scoped) {
+		t.Fatal("expected the color rule to reference --accent")
+	}
+	if w := s.Groups[3].Rules[0].Values[0].Str; w != "4px" {
+		t.Fatalf("expected a custom property referencing another to expand, got %q", w)
+	}
+}
+
+func TestParseInlineCustomProperty(t *testing.T) {
+	s := NewStyleSheet()
+	g := s.ParseInline("--gap: 8px; margin: var(--gap, 1px)", dummyWindow{})
+	if len(g.Rules) != 2 || g.Rules[0].Property != "--gap" || g.Rules[0].Values[0].Str != "8px" {
+		t.Fatalf("expected the inline custom property rule, got %#v", g.Rules)
+	}
+	if g.Rules[1].Values[0].Str != "1px" {
+		t.Fatalf("expected the fallback until the element scope is applied, got %q", g.Rules[1].Values[0].Str)
+	}
+}
+
+func TestExpandVarsCycle(t *testing.T) {
+	vars := map[string][]string{"--a": {"var(--b)"}, "--b": {"var(--a, 2px)"}}
+	lookup := func(name string) ([]string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+	if got := ExpandVars("var(--a)", lookup); got != "2px" {
+		t.Fatalf("expected the cycle to fall back to 2px, got %q", got)
+	}
+}
+
+const testCSSGradient = `:root { --to: blue; }
+.g { background-image: linear-gradient(to right, rgb(1, 2, 3) 10%, var(--to) 80%); }
+.r { background: repeating-radial-gradient(circle at 10px 20px, red, blue 4px) no-repeat; }`
+
+func TestParseGradientArgs(t *testing.T) {
+	s := NewStyleSheet()
+	s.Parse(testCSSGradient, dummyWindow{})
+	expect := func(v PropertyValue, name string, args ...string) {
+		t.Helper()
+		if v.Str != name || len(v.Args) != len(args) {
+			t.Fatalf("expected %s(%q), got %#v", name, args, v)
+		}
+		for i := range args {
+			if v.Args[i] != args[i] {
+				t.Fatalf("arg %d expected %q, got %q", i, args[i], v.Args[i])
+			}
+		}
+	}
+	find := func(prop string) Rule {
+		t.Helper()
+		for i := range s.Groups {
+			for _, r := range s.Groups[i].Rules {
+				if r.Property == prop {
+					return r
+				}
+			}
+		}
+		t.Fatalf("missing %s", prop)
+		return Rule{}
+	}
+	linear := find("background-image")
+	if len(linear.Values) != 1 {
+		t.Fatalf("expected a single gradient value, got %#v", linear.Values)
+	}
+	expect(linear.Values[0], "linear-gradient", "to right", "rgb(1,2,3) 10%", "blue 80%")
+	if !RuleReferencesVar(&linear, map[string][]string{"--to": nil}) {
+		t.Fatal("expected the gradient to reference --to")
+	}
+	radial := find("background")
+	if len(radial.Values) != 2 || radial.Values[1].Str != "no-repeat" {
+		t.Fatalf("expected the values after the gradient to be kept, got %#v", radial.Values)
+	}
+	expect(radial.Values[0], "repeating-radial-gradient", "circle at 10px 20px", "red", "blue 4px")
+}
+
+func TestParseShadowLayerCommas(t *testing.T) {
+	s := NewStyleSheet()
+	s.Parse(`.s { box-shadow: 1px 2px rgba(0, 0, 0, 0.5), inset 0 0 3px red; font-family: a, b; }`, dummyWindow{})
+	got := []string{}
+	fonts := 0
+	for _, r := range s.Groups[0].Rules {
+		switch r.Property {
+		case "box-shadow":
+			for _, v := range r.Values {
+				got = append(got, v.Str)
+			}
+		case "font-family":
+			fonts = len(r.Values)
+		}
+	}
+	want := []string{"1px", "2px", "rgba", ",", "inset", "0", "0", "3px", "red"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %q, got %q", want, got)
+		}
+	}
+	if fonts != 2 {
+		t.Fatalf("only the shadow properties should keep their commas, got %d font values", fonts)
+	}
+}
@@ -6,7 +6,10 @@
 
 package rules
 
-import "slices"
+import (
+	"slices"
+	"strings"
+)
 
 type RuleInvoke uint16
 
@@ -18,6 +21,8 @@ const (
 	RuleInvokeVisited
 	RuleInvokeInvalid
 	RuleInvokeValid
+	RuleInvokeFocusVisible
+	RuleInvokeFocusWithin
 )
 
 func (r RuleInvoke) Matches(state RuleInvoke) bool {
@@ -49,13 +54,24 @@ func (p PropertyValue) IsFunction() bool {
 }
 
 type Rule struct {
-	Property     string
-	Values       []PropertyValue
+	Property string
+	Values   []PropertyValue
+	// VarSource holds the values as they were written, before any var() was
+	// substituted. It is nil for rules that don't use custom properties. The
+	// values are resolved again from this source whenever the custom
+	// properties in scope of the element change.
+	VarSource    []PropertyValue
 	Invocation   RuleInvoke
 	Sort         int
 	SelfDestruct bool
 }
 
+// IsCustomProperty returns true if the rule declares a custom property
+// (--name) rather than setting a style property
+func (r *Rule) IsCustomProperty() bool {
+	return strings.HasPrefix(r.Property, "--")
+}
+
 func (r *Rule) Clone() Rule {
 	out := Rule{
 		Property:     r.Property,
@@ -67,6 +83,17 @@ func (r *Rule) Clone() Rule {
 	for i := range r.Values {
 		out.Values[i] = r.Values[i].Clone()
 	}
+	if r.VarSource != nil {
+		out.VarSource = CloneValues(r.VarSource)
+	}
+	return out
+}
+
+func CloneValues(in []PropertyValue) []PropertyValue {
+	out := make([]PropertyValue, len(in))
+	for i := range in {
+		out[i] = in[i].Clone()
+	}
 	return out
 }
 
@@ -0,0 +1,100 @@
+/******************************************************************************/
+/* html_custom_properties.go                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"maps"
+	"strings"
+)
+
+// CustomProperty returns the computed value of the custom property (--name)
+// for this element. The value is inherited from the closest ancestor (or the
+// document/:root) that declares it. This is only valid after the styles of
+// the document have been applied.
+func (e *Element) CustomProperty(name string) (string, bool) {
+	v, ok := e.customProperties[name]
+	return strings.Join(v, " "), ok
+}
+
+// SetComputedCustomProperties is called by the stylizer when the styles are
+// applied to store the custom properties that are in scope of this element.
+// The map may be shared with other elements and must not be modified.
+func (e *Element) SetComputedCustomProperties(props map[string][]string) {
+	e.customProperties = props
+}
+
+// CustomProperties returns a copy of the custom properties that were set on
+// the document through [Document.SetCustomProperty] with a nil element. These
+// override the ones declared in the :root of the style sheet.
+func (d *Document) CustomProperties() map[string]string {
+	return maps.Clone(d.customProperties)
+}
+
+// SetCustomPropertyWithoutApply sets the value of a custom property (--name)
+// without applying styles. If elm is nil, the property is set for the whole
+// document (like the :root of the style sheet), otherwise it is set on the
+// element through its inline style and inherited by its children.
+func (d *Document) SetCustomPropertyWithoutApply(elm *Element, name, value string) {
+	name = strings.TrimSpace(name)
+	if !strings.HasPrefix(name, "--") {
+		return
+	}
+	if elm != nil {
+		d.SetElementStylePropertyWithoutApply(elm, name, value)
+		return
+	}
+	if d.customProperties == nil {
+		d.customProperties = make(map[string]string)
+	}
+	d.customProperties[name] = strings.TrimSpace(value)
+}
+
+// SetCustomProperty sets the value of a custom property (--name) and applies
+// the styles so that every rule using var(--name) picks up the new value. See
+// [Document.SetCustomPropertyWithoutApply] for how elm is used.
+func (d *Document) SetCustomProperty(elm *Element, name, value string) {
+	d.SetCustomPropertyWithoutApply(elm, name, value)
+	d.ApplyStyles()
+}
+
+// RemoveCustomProperty removes a custom property that was previously set with
+// [Document.SetCustomProperty] and applies the styles
+func (d *Document) RemoveCustomProperty(elm *Element, name string) {
+	name = strings.TrimSpace(name)
+	if elm != nil {
+		next := removeInlineStyleProperty(elm.Attribute("style"), name)
+		if next == elm.Attribute("style") {
+			return
+		}
+		elm.SetAttribute("style", next)
+	} else {
+		if _, ok := d.customProperties[name]; !ok {
+			return
+		}
+		delete(d.customProperties, name)
+	}
+	d.ApplyStyles()
+}
+
+func removeInlineStyleProperty(style, property string) string {
+	declarations := splitInlineStyleDeclarations(style)
+	out := make([]string, 0, len(declarations))
+	for _, declaration := range declarations {
+		declaration = strings.TrimSpace(declaration)
+		if declaration == "" {
+			continue
+		}
+		if name, ok := inlineStyleDeclarationName(declaration); ok && name == property {
+			continue
+		}
+		out = append(out, declaration)
+	}
+	if len(out) == 0 {
+		return ""
+	}
+	return strings.Join(out, "; ") + ";"
+}
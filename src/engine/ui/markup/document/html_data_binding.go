@@ -0,0 +1,511 @@
+/******************************************************************************/
+/* html_data_binding.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"weak"
+
+	xhtml "golang.org/x/net/html"
+	"kaijuengine.com/engine"
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+const (
+	dataBindAttribute      = "data-bind"
+	dataEachAttribute      = "data-each"
+	dataIfAttribute        = "data-if"
+	dataVirtualAttribute   = "data-virtual"
+	dataRowHeightAttribute = "data-row-height"
+	// dataVirtualRowHeight is the row height of a data-virtual list that
+	// doesn't have a data-row-height attribute
+	dataVirtualRowHeight = 20
+)
+
+// DataBinding keeps the elements of a document in sync with a model, it is
+// created with [Document.Bind]. The elements are described with attributes:
+//
+//	<span data-bind="text: Player.Health; class.low: Player.IsHurt"></span>
+//	<div data-if="Player.IsDead">Game over</div>
+//	<li data-each="Inventory.Items" data-bind="text: Name"></li>
+//
+// Paths inside of a data-each element are relative to the item, see
+// [DataBinding.Refresh] for how changes are found. Adding data-virtual (and
+// data-row-height) to a data-each element shows the items in a
+// [ui.VirtualList] so only the rows on screen are created.
+type DataBinding struct {
+	doc      weak.Pointer[Document]
+	source   BindingSource
+	root     bindingGroup
+	updateId engine.UpdateId
+	// restyle is set when a change needs the document styles to be applied
+	// again, such as a class change or new data-each items
+	restyle bool
+}
+
+// bindingGroup is the bindings that share a scope. Elements inside of a
+// data-if are in a group of their own so they are skipped while hidden.
+type bindingGroup struct {
+	scope      bindingScope
+	values     []*valueBinding
+	conditions []*conditionBinding
+	lists      []*listBinding
+}
+
+type valueBinding struct {
+	elm   *Element
+	entry bindingEntry
+	last  string
+	bound bool
+}
+
+type conditionBinding struct {
+	elm     *Element
+	path    bindingPath
+	visible bool
+	bound   bool
+	group   bindingGroup
+}
+
+type listBinding struct {
+	template *Element
+	path     bindingPath
+	scope    bindingScope
+	items    []*boundItem
+	// count is the number of items the last time the list was refreshed
+	count   int
+	virtual *ui.VirtualList
+	rows    map[*ui.UI]*boundItem
+}
+
+type boundItem struct {
+	elm   *Element
+	group bindingGroup
+}
+
+// Bind connects the data binding attributes of the document to the model.
+// The model is either a [BindingSource] or any Go value (usually a pointer to
+// a struct) that is read through reflection. The elements are updated right
+// away, afterwards [DataBinding.Refresh] updates the elements whose values
+// changed. Binding again replaces the previous binding.
+func (d *Document) Bind(model any) *DataBinding {
+	defer tracing.NewRegion("Document.Bind").End()
+	if d.binding != nil {
+		d.binding.Unbind()
+	}
+	b := &DataBinding{
+		doc:    weak.Make(d),
+		source: newBindingSource(model),
+	}
+	d.binding = b
+	for _, elm := range d.TopElements {
+		b.scan(d, elm, &b.root)
+	}
+	b.restyle = true
+	b.Refresh()
+	return b
+}
+
+// Binding returns the active data binding of the document, or nil
+func (d *Document) Binding() *DataBinding { return d.binding }
+
+// Refresh reads every bound value from the model and updates the elements
+// whose value changed since the last refresh. Go values can't report that
+// they were changed, so this is to be called after changing the model (or
+// every frame, see [DataBinding.SetAutoRefresh]). Bindings inside of hidden
+// data-if elements are skipped until they are shown.
+func (b *DataBinding) Refresh() {
+	defer tracing.NewRegion("DataBinding.Refresh").End()
+	d := b.doc.Value()
+	if d == nil || b.source == nil {
+		return
+	}
+	b.refreshGroup(d, &b.root)
+	if b.restyle {
+		b.restyle = false
+		if d.stylizer != nil {
+			d.ApplyStyles()
+		}
+	}
+}
+
+// SetAutoRefresh will refresh the binding once every frame on the main thread
+// when enabled
+func (b *DataBinding) SetAutoRefresh(enabled bool) {
+	d := b.doc.Value()
+	if d == nil {
+		return
+	}
+	host := d.host.Value()
+	if host == nil {
+		return
+	}
+	if !enabled {
+		host.UIUpdater.RemoveUpdate(&b.updateId)
+		return
+	}
+	if b.updateId.IsValid() {
+		return
+	}
+	wb := weak.Make(b)
+	b.updateId = host.UIUpdater.AddUpdate(func(float64) {
+		if sb := wb.Value(); sb != nil {
+			host.RunOnMainThread(sb.Refresh)
+		}
+	})
+}
+
+// Unbind stops updating the elements, they keep their current values
+func (b *DataBinding) Unbind() {
+	b.SetAutoRefresh(false)
+	if d := b.doc.Value(); d != nil && d.binding == b {
+		d.binding = nil
+	}
+	b.source = nil
+	b.root = bindingGroup{}
+}
+
+// scan finds the binding attributes on the element and its children and
+// adds them to the group
+func (b *DataBinding) scan(d *Document, elm *Element, group *bindingGroup) {
+	if elm == nil || elm.Type != xhtml.ElementNode || elm.UI == nil {
+		return
+	}
+	if elm.HasAttribute(dataEachAttribute) {
+		b.scanList(d, elm, group)
+		return
+	}
+	if elm.HasAttribute(dataIfAttribute) {
+		path, err := parseBindingPath(elm.Attribute(dataIfAttribute))
+		if err != nil {
+			slog.Error("invalid data-if binding", "element", elm.Data, "error", err)
+		} else {
+			c := &conditionBinding{elm: elm, path: path, group: bindingGroup{scope: group.scope}}
+			group.conditions = append(group.conditions, c)
+			group = &c.group
+		}
+	}
+	if attr := elm.Attribute(dataBindAttribute); attr != "" {
+		entries, err := parseDataBind(attr)
+		if err != nil {
+			slog.Error("invalid data-bind binding", "element", elm.Data, "error", err)
+		}
+		for i := range entries {
+			if entries[i].target == bindingTargetText {
+				d.addTextLabel(elm)
+			}
+			group.values = append(group.values, &valueBinding{elm: elm, entry: entries[i]})
+		}
+	}
+	for i := range elm.Children {
+		b.scan(d, elm.Children[i], group)
+	}
+}
+
+func (b *DataBinding) scanList(d *Document, elm *Element, group *bindingGroup) {
+	path, err := parseBindingPath(elm.Attribute(dataEachAttribute))
+	if err != nil || path.index || path.negate {
+		slog.Error("invalid data-each binding", "element", elm.Data, "value", elm.Attribute(dataEachAttribute))
+		return
+	}
+	// The element is the template of the items, it stays in the document
+	// (hidden) so that it can be cloned for each item
+	elm.UI.Hide()
+	b.addTemplateTextLabels(d, elm)
+	list := &listBinding{template: elm, path: path, scope: group.scope}
+	group.lists = append(group.lists, list)
+	if elm.HasAttribute(dataVirtualAttribute) {
+		b.setupVirtualList(d, list)
+	}
+}
+
+// addTemplateTextLabels adds the labels for the text bindings of a data-each
+// template before it is cloned, so that the labels of the items are styled
+// the same as the template even before the document styles are applied again
+func (b *DataBinding) addTemplateTextLabels(d *Document, elm *Element) {
+	if elm.Type != xhtml.ElementNode || elm.UI == nil {
+		return
+	}
+	entries, _ := parseDataBind(elm.Attribute(dataBindAttribute))
+	for i := range entries {
+		if entries[i].target == bindingTargetText {
+			d.addTextLabel(elm)
+			break
+		}
+	}
+	for i := range elm.Children {
+		b.addTemplateTextLabels(d, elm.Children[i])
+	}
+}
+
+// addTextLabel gives an element without any text a label for bound or
+// localized text to write to
+func (d *Document) addTextLabel(elm *Element) {
+	if elm.UIPanel == nil || d.uiMan == nil || elm.InnerLabel() != nil ||
+		elm.UI.IsType(ui.ElementTypeInput) || elm.UI.IsType(ui.ElementTypeTextArea) {
+		return
+	}
+	text := &Element{Type: xhtml.TextNode, Parent: weak.Make(elm)}
+	text.Stylizer = ElementLayoutStylizer{element: weak.Make(text)}
+	d.createUIElement(d.uiMan, text, elm.UIPanel)
+	if text.UI == nil {
+		return
+	}
+	elm.Children = slices.Insert(elm.Children, 0, text)
+	elm.UIPanel.InsertChild(text.UI, 0)
+}
+
+// setElementLabelText writes the text to the label of the element, or to the
+// element itself when it is a text node
+func setElementLabelText(elm *Element, text string) {
+	if lbl := elm.InnerLabel(); lbl != nil {
+		lbl.SetText(text)
+	} else if lbl := elm.UI.ToLabel(); elm.IsText() && lbl != nil {
+		lbl.SetText(text)
+	}
+}
+
+func (b *DataBinding) refreshGroup(d *Document, group *bindingGroup) {
+	for _, v := range group.values {
+		b.refreshValue(d, v, group.scope)
+	}
+	for _, c := range group.conditions {
+		value, _ := c.path.read(b.source, group.scope)
+		visible := bindingTruthy(value) != c.path.negate
+		if !c.bound || visible != c.visible {
+			c.bound = true
+			c.visible = visible
+			c.elm.UI.SetVisibility(visible)
+		}
+		if visible {
+			c.group.scope = group.scope
+			b.refreshGroup(d, &c.group)
+		}
+	}
+	for _, l := range group.lists {
+		l.scope = group.scope
+		b.refreshList(d, l)
+	}
+}
+
+func (b *DataBinding) refreshValue(d *Document, v *valueBinding, scope bindingScope) {
+	value, _ := v.entry.path.read(b.source, scope)
+	var text string
+	switch v.entry.target {
+	case bindingTargetText, bindingTargetValue, bindingTargetStyle, bindingTargetAttribute:
+		text = bindingText(value)
+	default:
+		text = strconv.FormatBool(bindingTruthy(value) != v.entry.path.negate)
+	}
+	if v.bound && v.last == text {
+		return
+	}
+	v.bound = true
+	v.last = text
+	elm := v.elm
+	switch v.entry.target {
+	case bindingTargetText:
+		if elm.UI.IsType(ui.ElementTypeInput) {
+			elm.UI.ToInput().SetTextWithoutEvent(text)
+		} else if elm.UI.IsType(ui.ElementTypeTextArea) {
+			elm.UI.ToTextArea().SetTextWithoutEvent(text)
+		} else {
+			setElementLabelText(elm, text)
+		}
+	case bindingTargetValue:
+		setElementBoundValue(elm, text)
+	case bindingTargetChecked:
+		if elm.UI.IsType(ui.ElementTypeCheckbox) {
+			elm.UI.ToCheckbox().SetCheckedWithoutEvent(text == "true")
+		}
+	case bindingTargetVisible:
+		elm.UI.SetVisibility(text == "true")
+	case bindingTargetEnabled, bindingTargetDisabled:
+		disabled := (text == "true") == (v.entry.target == bindingTargetDisabled)
+		if elementSupportsDisabled(elm) && elm.HasAttribute("disabled") != disabled {
+			if disabled {
+				elm.SetAttribute("disabled", "")
+			} else {
+				elm.RemoveAttribute("disabled")
+			}
+			syncElementDisabledState(elm)
+			b.restyle = true
+		}
+	case bindingTargetClass:
+		classes := slices.DeleteFunc(elm.ClassList(), func(c string) bool {
+			return c == "" || c == v.entry.name
+		})
+		if text == "true" {
+			classes = append(classes, v.entry.name)
+		}
+		d.SetElementClassesWithoutApply(elm, classes...)
+		b.restyle = true
+	case bindingTargetStyle:
+		d.SetElementStylePropertyWithoutApply(elm, v.entry.name, text)
+		b.restyle = true
+	case bindingTargetAttribute:
+		elm.SetAttribute(v.entry.name, text)
+		b.restyle = true
+	}
+}
+
+func setElementBoundValue(elm *Element, text string) {
+	switch {
+	case elm.UI.IsType(ui.ElementTypeInput):
+		elm.UI.ToInput().SetTextWithoutEvent(text)
+	case elm.UI.IsType(ui.ElementTypeTextArea):
+		elm.UI.ToTextArea().SetTextWithoutEvent(text)
+	case elm.UI.IsType(ui.ElementTypeSlider):
+		if f, err := strconv.ParseFloat(text, 32); err == nil {
+			elm.UI.ToSlider().SetValueWithoutEvent(float32(f))
+		}
+	case elm.UI.IsType(ui.ElementTypeCheckbox):
+		elm.UI.ToCheckbox().SetCheckedWithoutEvent(bindingTruthy(text) && text != "false")
+	}
+}
+
+func (b *DataBinding) refreshList(d *Document, l *listBinding) {
+	abs := l.path.absolute(l.scope)
+	value, _ := b.source.BindingValue(abs)
+	count, ok := bindingLen(value)
+	if !ok {
+		count = 0
+	}
+	if l.virtual != nil {
+		b.refreshVirtualList(d, l, abs, count)
+		return
+	}
+	parent := l.template.Parent.Value()
+	for len(l.items) > count {
+		last := l.items[len(l.items)-1]
+		l.items = l.items[:len(l.items)-1]
+		d.RemoveElementWithoutApplyStyles(last.elm)
+		b.restyle = true
+	}
+	for len(l.items) < count && parent != nil {
+		item := &boundItem{elm: b.cloneTemplate(l.template, parent)}
+		idx := parent.IndexOfChild(l.template) + len(l.items) + 1
+		d.insertElementAtWithoutApply(item.elm, parent, idx)
+		b.scanItem(d, item)
+		l.items = append(l.items, item)
+		b.restyle = true
+	}
+	l.count = count
+	for i, item := range l.items {
+		item.group.scope = l.scope.item(abs, i)
+		b.refreshGroup(d, &item.group)
+	}
+}
+
+// cloneTemplate creates an item element from a data-each template, the clone
+// is not yet in the document
+func (b *DataBinding) cloneTemplate(template, parent *Element) *Element {
+	elm := template.Clone(nil)
+	elm.RemoveAttribute(dataEachAttribute)
+	elm.RemoveAttribute(dataVirtualAttribute)
+	elm.RemoveAttribute(dataRowHeightAttribute)
+	elm.RemoveAttribute("id")
+	elm.Parent = weak.Make(parent)
+	elm.UI.Show()
+	return elm
+}
+
+// scanItem finds the bindings of a new data-each item
+func (b *DataBinding) scanItem(d *Document, item *boundItem) {
+	item.group = bindingGroup{}
+	elm := item.elm
+	// The item element was the data-each element, so its own data-if and
+	// data-bind attributes belong to the item
+	b.scan(d, elm, &item.group)
+}
+
+func (b *DataBinding) setupVirtualList(d *Document, l *listBinding) {
+	parent := l.template.Parent.Value()
+	if parent == nil || parent.UIPanel == nil || d.uiMan == nil {
+		slog.Error("a data-virtual list needs a parent element", "element", l.template.Data)
+		return
+	}
+	height := float32(dataVirtualRowHeight)
+	if attr := l.template.Attribute(dataRowHeightAttribute); attr != "" {
+		if h, err := strconv.ParseFloat(strings.TrimSuffix(attr, "px"), 32); err == nil && h > 0 {
+			height = float32(h)
+		} else {
+			slog.Error("invalid data-row-height", "value", attr)
+		}
+	}
+	l.virtual = d.uiMan.Add().ToVirtualList()
+	l.virtual.Init()
+	l.virtual.Base().Layout().Stylizer = ui.StretchCenterStylizer{
+		BasicStylizer: ui.BasicStylizer{Parent: weak.Make(parent.UI)},
+	}
+	parent.UIPanel.AddChild(l.virtual.Base())
+	l.virtual.SetFixedRowHeight(height)
+	l.rows = map[*ui.UI]*boundItem{}
+	l.virtual.SetDelegate(&bindingListDelegate{
+		binding: weak.Make(b),
+		doc:     weak.Make(d),
+		list:    l,
+	})
+}
+
+func (b *DataBinding) refreshVirtualList(d *Document, l *listBinding, abs []string, count int) {
+	if count != l.count {
+		l.count = count
+		l.virtual.ReloadData()
+		return
+	}
+	for _, item := range l.rows {
+		if item.group.scope.index < count {
+			item.group.scope = l.scope.item(abs, item.group.scope.index)
+			b.refreshGroup(d, &item.group)
+		}
+	}
+}
+
+// bindingListDelegate shows the items of a data-virtual list, the rows are
+// clones of the template that are bound to a different item as they are
+// recycled
+type bindingListDelegate struct {
+	binding weak.Pointer[DataBinding]
+	doc     weak.Pointer[Document]
+	list    *listBinding
+}
+
+func (l *bindingListDelegate) RowCount() int { return l.list.count }
+
+func (l *bindingListDelegate) CreateRow(*ui.Manager) *ui.UI {
+	b, d := l.binding.Value(), l.doc.Value()
+	template := l.list.template
+	item := &boundItem{elm: b.cloneTemplate(template, template.Parent.Value())}
+	if d != nil {
+		// Indexed so the document styles (and events) reach the rows, they
+		// are not in the element tree since the list owns their placement
+		d.appendElement(item.elm)
+	}
+	if b != nil && d != nil {
+		b.scanItem(d, item)
+	}
+	l.list.rows[item.elm.UI] = item
+	return item.elm.UI
+}
+
+func (l *bindingListDelegate) BindRow(index int, row *ui.UI) {
+	b, d := l.binding.Value(), l.doc.Value()
+	item, ok := l.list.rows[row]
+	if b == nil || d == nil || !ok || b.source == nil {
+		return
+	}
+	abs := l.list.path.absolute(l.list.scope)
+	item.group.scope = l.list.scope.item(abs, index)
+	b.refreshGroup(d, &item.group)
+}
+
+func (l *bindingListDelegate) UnbindRow(int, *ui.UI) {}
@@ -0,0 +1,341 @@
+/******************************************************************************/
+/* html_data_binding_source.go                                                */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// BindingSource is the data a document is bound to with [Document.Bind]. The
+// path is the dot separated path from a binding attribute split into its
+// parts, list indexes are passed as 0 based integer strings. The second
+// return is false when the path doesn't exist.
+//
+// Go values don't need to implement this, they are read through reflection.
+// It exists so other data, such as a Lua table, can be bound.
+type BindingSource interface {
+	BindingValue(path []string) (any, bool)
+}
+
+// BindingList can be returned from a [BindingSource] for a value that is a
+// list, so that it can be used with data-each
+type BindingList interface {
+	Len() int
+}
+
+// reflectBindingSource reads bound values out of a Go value. Struct fields
+// (and methods without arguments) are found by name, maps by string key and
+// slices and arrays by index. Pointers and interfaces are followed.
+type reflectBindingSource struct {
+	root reflect.Value
+}
+
+func newBindingSource(model any) BindingSource {
+	if src, ok := model.(BindingSource); ok {
+		return src
+	}
+	return reflectBindingSource{root: reflect.ValueOf(model)}
+}
+
+func (s reflectBindingSource) BindingValue(path []string) (any, bool) {
+	v := s.root
+	for _, part := range path {
+		var ok bool
+		if v, ok = reflectBindingChild(v, part); !ok {
+			return nil, false
+		}
+	}
+	v = reflectBindingIndirect(v)
+	if !v.IsValid() {
+		return nil, true
+	}
+	if !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+func reflectBindingIndirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func reflectBindingChild(v reflect.Value, name string) (reflect.Value, bool) {
+	// Methods are looked up before following pointers so that pointer
+	// receivers are found too
+	if m, ok := reflectBindingMethod(v, name); ok {
+		return m, true
+	}
+	v = reflectBindingIndirect(v)
+	if !v.IsValid() {
+		return v, false
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if f := v.FieldByName(name); f.IsValid() && f.CanInterface() {
+			return f, true
+		}
+		if v.CanAddr() {
+			return reflectBindingMethod(v.Addr(), name)
+		}
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}, false
+		}
+		if e := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())); e.IsValid() {
+			return e, true
+		}
+	case reflect.Slice, reflect.Array, reflect.String:
+		if i, err := strconv.Atoi(name); err == nil && i >= 0 && i < v.Len() {
+			return v.Index(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// reflectBindingMethod calls the method with the given name if it has no
+// arguments and returns a value, the first return value is used
+func reflectBindingMethod(v reflect.Value, name string) (reflect.Value, bool) {
+	if !v.IsValid() || !token.IsExported(name) {
+		return reflect.Value{}, false
+	}
+	m := v.MethodByName(name)
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() == 0 {
+		return reflect.Value{}, false
+	}
+	return m.Call(nil)[0], true
+}
+
+// bindingLen returns the number of items in a bound list value
+func bindingLen(value any) (int, bool) {
+	if list, ok := value.(BindingList); ok {
+		return list.Len(), true
+	}
+	v := reflectBindingIndirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return 0, true
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// bindingTruthy is how a bound value is read for the boolean bindings such
+// as data-if, checked and class
+func bindingTruthy(value any) bool {
+	switch t := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case string:
+		return t != ""
+	case BindingList:
+		return t.Len() > 0
+	}
+	v := reflectBindingIndirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() != 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() != 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() != 0
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len() > 0
+	}
+	return true
+}
+
+// bindingText is how a bound value is written into text and attributes
+func bindingText(value any) string {
+	switch t := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float32:
+		return strconv.FormatFloat(float64(t), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	}
+	v := reflectBindingIndirect(reflect.ValueOf(value))
+	if !v.IsValid() {
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// bindingPath is a parsed path from a binding attribute. Paths are relative to
+// the current data-each item, $root starts from the bound model, $item is the
+// item itself and $index is the index of the item.
+type bindingPath struct {
+	parts  []string
+	root   bool
+	index  bool
+	negate bool
+}
+
+func parseBindingPath(text string) (bindingPath, error) {
+	p := bindingPath{}
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "!") {
+		p.negate = true
+		text = strings.TrimSpace(text[1:])
+	}
+	if text == "" {
+		return p, fmt.Errorf("missing binding path")
+	}
+	for part := range strings.SplitSeq(text, ".") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			return p, fmt.Errorf("invalid binding path %q", text)
+		}
+		p.parts = append(p.parts, part)
+	}
+	switch p.parts[0] {
+	case "$root":
+		p.root = true
+		p.parts = p.parts[1:]
+	case "$item":
+		p.parts = p.parts[1:]
+	case "$index":
+		if len(p.parts) > 1 {
+			return p, fmt.Errorf("$index can't have a sub path in %q", text)
+		}
+		p.index = true
+		p.parts = nil
+	}
+	return p, nil
+}
+
+// absolute returns the path from the root of the model for the given scope
+func (p bindingPath) absolute(scope bindingScope) []string {
+	if p.root || len(scope.prefix) == 0 {
+		return p.parts
+	}
+	out := make([]string, 0, len(scope.prefix)+len(p.parts))
+	out = append(out, scope.prefix...)
+	return append(out, p.parts...)
+}
+
+func (p bindingPath) read(src BindingSource, scope bindingScope) (any, bool) {
+	if p.index {
+		return scope.index, true
+	}
+	return src.BindingValue(p.absolute(scope))
+}
+
+// bindingScope is where relative paths are read from, the top level of the
+// document has an empty scope and every data-each item has its own
+type bindingScope struct {
+	prefix []string
+	index  int
+}
+
+func (s bindingScope) item(list []string, index int) bindingScope {
+	prefix := make([]string, 0, len(list)+1)
+	prefix = append(prefix, list...)
+	return bindingScope{prefix: append(prefix, strconv.Itoa(index)), index: index}
+}
+
+// bindingTarget is what a single data-bind entry writes to
+type bindingTarget uint8
+
+const (
+	bindingTargetText = bindingTarget(iota)
+	bindingTargetValue
+	bindingTargetChecked
+	bindingTargetVisible
+	bindingTargetEnabled
+	bindingTargetDisabled
+	bindingTargetClass
+	bindingTargetStyle
+	bindingTargetAttribute
+)
+
+type bindingEntry struct {
+	target bindingTarget
+	// name is the class, style property or attribute name
+	name string
+	path bindingPath
+}
+
+// parseDataBind reads a data-bind attribute, which is a list of
+// "target: path" entries separated by ";" or ",". The targets are text,
+// value, checked, visible, enabled, disabled, class.<name>, style.<property>
+// and attr.<name>.
+func parseDataBind(attr string) ([]bindingEntry, error) {
+	entries := []bindingEntry{}
+	for entry := range strings.FieldsFuncSeq(attr, func(r rune) bool { return r == ';' || r == ',' }) {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		target, path, ok := strings.Cut(entry, ":")
+		if !ok {
+			return entries, fmt.Errorf("data-bind entry %q is missing a ':'", strings.TrimSpace(entry))
+		}
+		e := bindingEntry{}
+		target = strings.TrimSpace(target)
+		kind, name, _ := strings.Cut(target, ".")
+		switch kind {
+		case "text":
+			e.target = bindingTargetText
+		case "value":
+			e.target = bindingTargetValue
+		case "checked":
+			e.target = bindingTargetChecked
+		case "visible":
+			e.target = bindingTargetVisible
+		case "enabled":
+			e.target = bindingTargetEnabled
+		case "disabled":
+			e.target = bindingTargetDisabled
+		case "class":
+			e.target = bindingTargetClass
+		case "style":
+			e.target = bindingTargetStyle
+		case "attr":
+			e.target = bindingTargetAttribute
+		default:
+			return entries, fmt.Errorf("unknown data-bind target %q", target)
+		}
+		switch e.target {
+		case bindingTargetClass, bindingTargetStyle, bindingTargetAttribute:
+			if name == "" {
+				return entries, fmt.Errorf("data-bind target %q needs a name, such as %s.name", target, kind)
+			}
+			e.name = name
+		default:
+			if name != "" {
+				return entries, fmt.Errorf("unknown data-bind target %q", target)
+			}
+		}
+		var err error
+		if e.path, err = parseBindingPath(path); err != nil {
+			return entries, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
@@ -0,0 +1,154 @@
+/******************************************************************************/
+/* html_data_binding_test.go                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"slices"
+	"testing"
+)
+
+type bindingTestItem struct {
+	Name  string
+	Count int
+}
+
+type bindingTestPlayer struct {
+	Health    float32
+	MaxHealth float32
+	Items     []bindingTestItem
+	Stats     map[string]int
+	secret    int
+}
+
+func (p *bindingTestPlayer) IsHurt() bool { return p.Health < p.MaxHealth }
+
+type bindingTestModel struct {
+	Player *bindingTestPlayer
+	Title  string
+}
+
+func TestParseDataBind(t *testing.T) {
+	entries, err := parseDataBind("text: Player.Health; class.low: !Player.IsHurt, style.width: $root.Width")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].target != bindingTargetText || !slices.Equal(entries[0].path.parts, []string{"Player", "Health"}) {
+		t.Fatalf("text entry = %+v", entries[0])
+	}
+	if entries[1].target != bindingTargetClass || entries[1].name != "low" || !entries[1].path.negate {
+		t.Fatalf("class entry = %+v", entries[1])
+	}
+	if entries[2].target != bindingTargetStyle || entries[2].name != "width" || !entries[2].path.root {
+		t.Fatalf("style entry = %+v", entries[2])
+	}
+	bad := []string{"text Player", "color: Red", "class: Low", "text.x: A", "text: A..B", "value: $index.X"}
+	for _, attr := range bad {
+		if _, err := parseDataBind(attr); err == nil {
+			t.Fatalf("expected an error for %q", attr)
+		}
+	}
+}
+
+func TestReflectBindingSource(t *testing.T) {
+	model := &bindingTestModel{
+		Title: "HUD",
+		Player: &bindingTestPlayer{
+			Health:    40,
+			MaxHealth: 100,
+			Items:     []bindingTestItem{{"Sword", 1}, {"Potion", 3}},
+			Stats:     map[string]int{"str": 7},
+			secret:    1,
+		},
+	}
+	src := newBindingSource(model)
+	tests := []struct {
+		path []string
+		want any
+	}{
+		{[]string{"Title"}, "HUD"},
+		{[]string{"Player", "Health"}, float32(40)},
+		{[]string{"Player", "IsHurt"}, true},
+		{[]string{"Player", "Items", "1", "Name"}, "Potion"},
+		{[]string{"Player", "Stats", "str"}, 7},
+	}
+	for _, test := range tests {
+		if got, ok := src.BindingValue(test.path); !ok || got != test.want {
+			t.Fatalf("%v = %v, %v, want %v", test.path, got, ok, test.want)
+		}
+	}
+	missing := [][]string{{"Nope"}, {"Player", "secret"}, {"Player", "Items", "5"}, {"Player", "Stats", "dex"}}
+	for _, path := range missing {
+		if _, ok := src.BindingValue(path); ok {
+			t.Fatalf("%v should not exist", path)
+		}
+	}
+	items, _ := src.BindingValue([]string{"Player", "Items"})
+	if n, ok := bindingLen(items); !ok || n != 2 {
+		t.Fatalf("bindingLen(items) = %d, %v", n, ok)
+	}
+	model.Player = nil
+	if v, ok := src.BindingValue([]string{"Player"}); !ok || v != nil {
+		t.Fatalf("a nil pointer should bind as nil, got %v, %v", v, ok)
+	}
+}
+
+func TestBindingScopes(t *testing.T) {
+	src := newBindingSource(&bindingTestModel{
+		Title:  "HUD",
+		Player: &bindingTestPlayer{Items: []bindingTestItem{{"Sword", 1}, {"Potion", 3}}},
+	})
+	list, _ := parseBindingPath("Player.Items")
+	scope := bindingScope{}.item(list.absolute(bindingScope{}), 1)
+	read := func(text string) any {
+		t.Helper()
+		p, err := parseBindingPath(text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, _ := p.read(src, scope)
+		return v
+	}
+	if v := read("Name"); v != "Potion" {
+		t.Fatalf("relative path = %v", v)
+	}
+	if v := read("$item.Count"); v != 3 {
+		t.Fatalf("$item path = %v", v)
+	}
+	if v := read("$index"); v != 1 {
+		t.Fatalf("$index = %v", v)
+	}
+	if v := read("$root.Title"); v != "HUD" {
+		t.Fatalf("$root path = %v", v)
+	}
+}
+
+func TestBindingValueConversion(t *testing.T) {
+	truthy := []any{true, 1, float32(0.5), "x", []int{1}, &bindingTestItem{}}
+	for _, v := range truthy {
+		if !bindingTruthy(v) {
+			t.Fatalf("%#v should be truthy", v)
+		}
+	}
+	falsy := []any{nil, false, 0, 0.0, "", []int{}, (*bindingTestItem)(nil)}
+	for _, v := range falsy {
+		if bindingTruthy(v) {
+			t.Fatalf("%#v should not be truthy", v)
+		}
+	}
+	if got := bindingText(float32(12.5)); got != "12.5" {
+		t.Fatalf("bindingText(12.5) = %q", got)
+	}
+	if got := bindingText(float64(100)); got != "100" {
+		t.Fatalf("bindingText(100.0) = %q", got)
+	}
+	if got := bindingText((*bindingTestItem)(nil)); got != "" {
+		t.Fatalf("bindingText(nil pointer) = %q", got)
+	}
+}
@@ -30,6 +30,9 @@ type Element struct {
 	Children   []*Element
 	Stylizer   ElementLayoutStylizer
 	UIEventIds [ui.EventTypeEnd][]events.Id
+	// customProperties are the computed custom properties (--name) that are
+	// in scope of this element, set each time the document styles are applied
+	customProperties map[string][]string
 }
 
 func (e *Element) ClassList() []string {
@@ -0,0 +1,825 @@
+/******************************************************************************/
+/* html_element_animation.go                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"kaijuengine.com/engine/ui/markup/css/rules"
+)
+
+type AnimationDirection uint8
+
+const (
+	AnimationDirectionNormal AnimationDirection = iota
+	AnimationDirectionReverse
+	AnimationDirectionAlternate
+	AnimationDirectionAlternateReverse
+)
+
+type AnimationFillMode uint8
+
+const (
+	AnimationFillModeNone AnimationFillMode = iota
+	AnimationFillModeForwards
+	AnimationFillModeBackwards
+	AnimationFillModeBoth
+)
+
+// AnimationSpec is a single entry of the computed animation-* properties of
+// an element. Times are in seconds.
+type AnimationSpec struct {
+	Name           string
+	Duration       float64
+	Delay          float64
+	Timing         TimingFunction
+	IterationCount float64
+	Direction      AnimationDirection
+	FillMode       AnimationFillMode
+	Paused         bool
+}
+
+// TransitionSpec is a single entry of the computed transition-* properties of
+// an element. Times are in seconds.
+type TransitionSpec struct {
+	Property string
+	Duration float64
+	Delay    float64
+	Timing   TimingFunction
+}
+
+func defaultAnimationSpec() AnimationSpec {
+	return AnimationSpec{
+		Name:           "none",
+		Timing:         TimingFunctionEase,
+		IterationCount: 1,
+	}
+}
+
+func defaultTransitionSpec() TransitionSpec {
+	return TransitionSpec{
+		Property: "all",
+		Timing:   TimingFunctionEase,
+	}
+}
+
+var animationDirections = map[string]AnimationDirection{
+	"normal":            AnimationDirectionNormal,
+	"reverse":           AnimationDirectionReverse,
+	"alternate":         AnimationDirectionAlternate,
+	"alternate-reverse": AnimationDirectionAlternateReverse,
+}
+
+var animationFillModes = map[string]AnimationFillMode{
+	"none":      AnimationFillModeNone,
+	"forwards":  AnimationFillModeForwards,
+	"backwards": AnimationFillModeBackwards,
+	"both":      AnimationFillModeBoth,
+}
+
+// ParseAnimationDirection reads an animation-direction keyword
+func ParseAnimationDirection(str string) (AnimationDirection, error) {
+	if d, ok := animationDirections[str]; ok {
+		return d, nil
+	}
+	return AnimationDirectionNormal, fmt.Errorf("invalid animation-direction '%s'", str)
+}
+
+// ParseAnimationFillMode reads an animation-fill-mode keyword
+func ParseAnimationFillMode(str string) (AnimationFillMode, error) {
+	if f, ok := animationFillModes[str]; ok {
+		return f, nil
+	}
+	return AnimationFillModeNone, fmt.Errorf("invalid animation-fill-mode '%s'", str)
+}
+
+// ParseAnimationPlayState reads an animation-play-state keyword, returning
+// true if the animation is paused
+func ParseAnimationPlayState(str string) (bool, error) {
+	switch str {
+	case "running":
+		return false, nil
+	case "paused":
+		return true, nil
+	}
+	return false, fmt.Errorf("invalid animation-play-state '%s'", str)
+}
+
+// ParseIterationCount reads an animation-iteration-count value, infinite is
+// returned as positive infinity
+func ParseIterationCount(str string) (float64, error) {
+	if str == "infinite" {
+		return math.Inf(1), nil
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil || v < 0 || math.IsNaN(v) {
+		return 0, fmt.Errorf("invalid animation-iteration-count '%s'", str)
+	}
+	return v, nil
+}
+
+// animationLists holds the comma separated lists of the animation longhands.
+// Like CSS, the number of animations is decided by the list of names and the
+// other lists are repeated to fill it.
+type animationLists struct {
+	names      []string
+	durations  []float64
+	delays     []float64
+	timings    []TimingFunction
+	iterations []float64
+	directions []AnimationDirection
+	fillModes  []AnimationFillMode
+	paused     []bool
+}
+
+func listItem[T any](list []T, idx int, fallback T) T {
+	if len(list) == 0 {
+		return fallback
+	}
+	return list[idx%len(list)]
+}
+
+// animationShorthandParts counts how many values of each kind have been read
+// for the current entry of the animation shorthand
+type animationShorthandParts struct {
+	name, times, timing, iterations, direction, fill, state int
+}
+
+// transitionShorthandParts counts how many values of each kind have been read
+// for the current entry of the transition shorthand
+type transitionShorthandParts struct {
+	property, times, timing int
+}
+
+// ParseAnimationShorthand reads the values of the animation shorthand. The
+// CSS parser does not keep the commas between the entries of a property, so a
+// new entry starts when a value would fill a part of the current entry that
+// has already been set (a second name, or a third time for example).
+func ParseAnimationShorthand(values []rules.PropertyValue) ([]AnimationSpec, error) {
+	out := make([]AnimationSpec, 0, 1)
+	var spec AnimationSpec
+	var set animationShorthandParts
+	begin := func() {
+		spec = defaultAnimationSpec()
+		set = animationShorthandParts{}
+	}
+	end := func() {
+		if set != (animationShorthandParts{}) {
+			out = append(out, spec)
+		}
+	}
+	begin()
+	for i := range values {
+		v := values[i]
+		if v.IsFunction() && !IsTimingFunction(v) {
+			return nil, fmt.Errorf("unexpected function '%s' in animation", v.Str)
+		}
+		str := v.Str
+		switch {
+		// A unitless 0 is an iteration count in the shorthand, not a time
+		case isTime(str) && str != "0":
+			if set.times >= 2 {
+				end()
+				begin()
+			}
+			t, _ := ParseTime(str)
+			if set.times == 0 {
+				spec.Duration = max(0, t)
+			} else {
+				spec.Delay = t
+			}
+			set.times++
+		case IsTimingFunction(v):
+			if set.timing > 0 {
+				end()
+				begin()
+			}
+			t, err := ParseTimingFunction(v)
+			if err != nil {
+				return nil, err
+			}
+			spec.Timing = t
+			set.timing++
+		case isIterationCount(str):
+			if set.iterations > 0 {
+				end()
+				begin()
+			}
+			spec.IterationCount, _ = ParseIterationCount(str)
+			set.iterations++
+		case isAnimationDirection(str):
+			if set.direction > 0 {
+				end()
+				begin()
+			}
+			spec.Direction, _ = ParseAnimationDirection(str)
+			set.direction++
+		case isAnimationFillMode(str) && (str != "none" || set.name > 0):
+			if set.fill > 0 {
+				end()
+				begin()
+			}
+			spec.FillMode, _ = ParseAnimationFillMode(str)
+			set.fill++
+		case str == "running" || str == "paused":
+			if set.state > 0 {
+				end()
+				begin()
+			}
+			spec.Paused = str == "paused"
+			set.state++
+		default:
+			if set.name > 0 {
+				end()
+				begin()
+			}
+			spec.Name = strings.Trim(str, `"'`)
+			set.name++
+		}
+	}
+	end()
+	return out, nil
+}
+
+// ParseTransitionShorthand reads the values of the transition shorthand, see
+// [ParseAnimationShorthand] for how the entries are separated.
+func ParseTransitionShorthand(values []rules.PropertyValue) ([]TransitionSpec, error) {
+	out := make([]TransitionSpec, 0, 1)
+	var spec TransitionSpec
+	var set transitionShorthandParts
+	begin := func() {
+		spec = defaultTransitionSpec()
+		set = transitionShorthandParts{}
+	}
+	end := func() {
+		if set != (transitionShorthandParts{}) {
+			out = append(out, spec)
+		}
+	}
+	begin()
+	for i := range values {
+		v := values[i]
+		if v.IsFunction() && !IsTimingFunction(v) {
+			return nil, fmt.Errorf("unexpected function '%s' in transition", v.Str)
+		}
+		switch {
+		case isTime(v.Str):
+			if set.times >= 2 {
+				end()
+				begin()
+			}
+			t, _ := ParseTime(v.Str)
+			if set.times == 0 {
+				spec.Duration = max(0, t)
+			} else {
+				spec.Delay = t
+			}
+			set.times++
+		case IsTimingFunction(v):
+			if set.timing > 0 {
+				end()
+				begin()
+			}
+			t, err := ParseTimingFunction(v)
+			if err != nil {
+				return nil, err
+			}
+			spec.Timing = t
+			set.timing++
+		default:
+			if set.property > 0 {
+				end()
+				begin()
+			}
+			spec.Property = v.Str
+			set.property++
+		}
+	}
+	end()
+	return out, nil
+}
+
+func isIterationCount(str string) bool {
+	_, err := ParseIterationCount(str)
+	return err == nil
+}
+
+func isAnimationDirection(str string) bool {
+	_, ok := animationDirections[str]
+	return ok
+}
+
+func isAnimationFillMode(str string) bool {
+	_, ok := animationFillModes[str]
+	return ok
+}
+
+func parseValueList[T any](values []rules.PropertyValue, parse func(string) (T, error)) ([]T, error) {
+	out := make([]T, 0, len(values))
+	for i := range values {
+		v, err := parse(values[i].Str)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// ParseTimingFunctionList reads a list of easing functions
+func ParseTimingFunctionList(values []rules.PropertyValue) ([]TimingFunction, error) {
+	out := make([]TimingFunction, 0, len(values))
+	for i := range values {
+		t, err := ParseTimingFunction(values[i])
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// ParseTimeList reads a list of times, negative times are only allowed when
+// allowNegative is true (delays)
+func ParseTimeList(values []rules.PropertyValue, allowNegative bool) ([]float64, error) {
+	return parseValueList(values, func(str string) (float64, error) {
+		t, err := ParseTime(str)
+		if err == nil && t < 0 && !allowNegative {
+			err = fmt.Errorf("time '%s' can not be negative", str)
+		}
+		return t, err
+	})
+}
+
+func (l *animationLists) readRule(r *rules.Rule) error {
+	var err error
+	switch r.Property {
+	case "animation":
+		var specs []AnimationSpec
+		if specs, err = ParseAnimationShorthand(r.Values); err != nil {
+			return err
+		}
+		*l = animationLists{}
+		for i := range specs {
+			l.names = append(l.names, specs[i].Name)
+			l.durations = append(l.durations, specs[i].Duration)
+			l.delays = append(l.delays, specs[i].Delay)
+			l.timings = append(l.timings, specs[i].Timing)
+			l.iterations = append(l.iterations, specs[i].IterationCount)
+			l.directions = append(l.directions, specs[i].Direction)
+			l.fillModes = append(l.fillModes, specs[i].FillMode)
+			l.paused = append(l.paused, specs[i].Paused)
+		}
+	case "animation-name":
+		l.names, err = parseValueList(r.Values, func(str string) (string, error) {
+			return strings.Trim(str, `"'`), nil
+		})
+	case "animation-duration":
+		l.durations, err = ParseTimeList(r.Values, false)
+	case "animation-delay":
+		l.delays, err = ParseTimeList(r.Values, true)
+	case "animation-timing-function":
+		l.timings, err = ParseTimingFunctionList(r.Values)
+	case "animation-iteration-count":
+		l.iterations, err = parseValueList(r.Values, ParseIterationCount)
+	case "animation-direction":
+		l.directions, err = parseValueList(r.Values, ParseAnimationDirection)
+	case "animation-fill-mode":
+		l.fillModes, err = parseValueList(r.Values, ParseAnimationFillMode)
+	case "animation-play-state":
+		l.paused, err = parseValueList(r.Values, ParseAnimationPlayState)
+	}
+	return err
+}
+
+func (l *animationLists) specs() []AnimationSpec {
+	def := defaultAnimationSpec()
+	out := make([]AnimationSpec, 0, len(l.names))
+	for i := range l.names {
+		out = append(out, AnimationSpec{
+			Name:           l.names[i],
+			Duration:       listItem(l.durations, i, def.Duration),
+			Delay:          listItem(l.delays, i, def.Delay),
+			Timing:         listItem(l.timings, i, def.Timing),
+			IterationCount: listItem(l.iterations, i, def.IterationCount),
+			Direction:      listItem(l.directions, i, def.Direction),
+			FillMode:       listItem(l.fillModes, i, def.FillMode),
+			Paused:         listItem(l.paused, i, def.Paused),
+		})
+	}
+	return out
+}
+
+// ComputedAnimations reads the animation shorthand and longhands out of the
+// computed rules in order, later rules override earlier ones
+func ComputedAnimations(ruleList []rules.Rule) ([]AnimationSpec, error) {
+	lists := animationLists{}
+	for i := range ruleList {
+		if err := lists.readRule(&ruleList[i]); err != nil {
+			return nil, err
+		}
+	}
+	return lists.specs(), nil
+}
+
+// ComputedTransitions reads the transition shorthand and longhands out of the
+// computed rules in order, later rules override earlier ones
+func ComputedTransitions(ruleList []rules.Rule) ([]TransitionSpec, error) {
+	var properties []string
+	var durations, delays []float64
+	var timings []TimingFunction
+	for i := range ruleList {
+		r := &ruleList[i]
+		var err error
+		switch r.Property {
+		case "transition":
+			var specs []TransitionSpec
+			if specs, err = ParseTransitionShorthand(r.Values); err != nil {
+				return nil, err
+			}
+			properties, durations, delays, timings = nil, nil, nil, nil
+			for j := range specs {
+				properties = append(properties, specs[j].Property)
+				durations = append(durations, specs[j].Duration)
+				delays = append(delays, specs[j].Delay)
+				timings = append(timings, specs[j].Timing)
+			}
+		case "transition-property":
+			properties, err = parseValueList(r.Values, func(str string) (string, error) {
+				return str, nil
+			})
+		case "transition-duration":
+			durations, err = ParseTimeList(r.Values, false)
+		case "transition-delay":
+			delays, err = ParseTimeList(r.Values, true)
+		case "transition-timing-function":
+			timings, err = ParseTimingFunctionList(r.Values)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	def := defaultTransitionSpec()
+	if properties == nil && (len(durations) > 0 || len(delays) > 0) {
+		properties = []string{def.Property}
+	}
+	out := make([]TransitionSpec, 0, len(properties))
+	for i := range properties {
+		if properties[i] == "none" {
+			continue
+		}
+		out = append(out, TransitionSpec{
+			Property: properties[i],
+			Duration: listItem(durations, i, def.Duration),
+			Delay:    listItem(delays, i, def.Delay),
+			Timing:   listItem(timings, i, def.Timing),
+		})
+	}
+	return out, nil
+}
+
+// isAnimationProperty is true for the properties that configure animations
+// and transitions, these are never animated themselves
+func isAnimationProperty(property string) bool {
+	return strings.HasPrefix(property, "animation") ||
+		strings.HasPrefix(property, "transition")
+}
+
+func transitionFor(specs []TransitionSpec, property string) (TransitionSpec, bool) {
+	// The last matching entry wins, like it does for duplicate CSS properties
+	for i := len(specs) - 1; i >= 0; i-- {
+		if specs[i].Property == property || specs[i].Property == "all" {
+			return specs[i], specs[i].Duration > 0 || specs[i].Delay > 0
+		}
+	}
+	return TransitionSpec{}, false
+}
+
+type runningTransition struct {
+	spec    TransitionSpec
+	from    []rules.PropertyValue
+	to      []rules.PropertyValue
+	elapsed float64
+}
+
+func (t *runningTransition) finished() bool {
+	return t.elapsed >= t.spec.Delay+t.spec.Duration
+}
+
+func (t *runningTransition) values() []rules.PropertyValue {
+	if t.elapsed < t.spec.Delay {
+		return cloneValues(t.from)
+	}
+	if t.spec.Duration <= 0 || t.finished() {
+		return cloneValues(t.to)
+	}
+	p := t.spec.Timing.Apply(float32((t.elapsed - t.spec.Delay) / t.spec.Duration))
+	if v, ok := InterpolateValues(t.from, t.to, p); ok {
+		return v
+	}
+	return steppedValues(t.from, t.to, p)
+}
+
+type runningAnimation struct {
+	spec    AnimationSpec
+	elapsed float64
+}
+
+func (a *runningAnimation) activeDuration() float64 {
+	if a.spec.Duration <= 0 || a.spec.IterationCount <= 0 {
+		return 0
+	}
+	return a.spec.Duration * a.spec.IterationCount
+}
+
+func (a *runningAnimation) finished() bool {
+	return a.elapsed-a.spec.Delay >= a.activeDuration()
+}
+
+// progress returns the directed progress through the current iteration, and
+// false if the animation has no effect at this time
+func (a *runningAnimation) progress() (float32, bool) {
+	active := a.elapsed - a.spec.Delay
+	fill := a.spec.FillMode
+	var iteration, local float64
+	switch {
+	case active < 0:
+		if fill != AnimationFillModeBackwards && fill != AnimationFillModeBoth {
+			return 0, false
+		}
+		iteration, local = 0, 0
+	case active >= a.activeDuration():
+		if fill != AnimationFillModeForwards && fill != AnimationFillModeBoth {
+			return 0, false
+		}
+		count := a.spec.IterationCount
+		if count <= 0 {
+			iteration, local = 0, 0
+		} else if whole := math.Floor(count); whole == count {
+			iteration, local = count-1, 1
+		} else {
+			iteration, local = whole, count-whole
+		}
+	default:
+		if a.spec.Duration <= 0 {
+			return 0, false
+		}
+		iteration = math.Floor(active / a.spec.Duration)
+		local = active/a.spec.Duration - iteration
+	}
+	reverse := false
+	switch a.spec.Direction {
+	case AnimationDirectionReverse:
+		reverse = true
+	case AnimationDirectionAlternate:
+		reverse = int64(iteration)%2 == 1
+	case AnimationDirectionAlternateReverse:
+		reverse = int64(iteration)%2 == 0
+	}
+	if reverse {
+		local = 1 - local
+	}
+	return float32(local), true
+}
+
+// keyframeValues computes the animated value of a property at the progress,
+// the underlying value is used for the implicit from and to frames
+func keyframeValues(set *rules.KeyframeSet, property string, progress float32,
+	timing TimingFunction, underlying []rules.PropertyValue) ([]rules.PropertyValue, bool) {
+	type frame struct {
+		offset float32
+		values []rules.PropertyValue
+		timing TimingFunction
+	}
+	frames := make([]frame, 0, len(set.Frames)+2)
+	for i := range set.Frames {
+		f := frame{offset: set.Frames[i].Offset, timing: timing}
+		found := false
+		for j := range set.Frames[i].Rules {
+			r := &set.Frames[i].Rules[j]
+			switch r.Property {
+			case property:
+				f.values = r.Values
+				found = true
+			case "animation-timing-function":
+				if len(r.Values) > 0 {
+					if t, err := ParseTimingFunction(r.Values[0]); err == nil {
+						f.timing = t
+					}
+				}
+			}
+		}
+		if found {
+			frames = append(frames, f)
+		}
+	}
+	if len(frames) == 0 {
+		return nil, false
+	}
+	if frames[0].offset > 0 {
+		if underlying == nil {
+			frames = append([]frame{{offset: 0, values: frames[0].values, timing: timing}}, frames...)
+		} else {
+			frames = append([]frame{{offset: 0, values: underlying, timing: timing}}, frames...)
+		}
+	}
+	if last := frames[len(frames)-1]; last.offset < 1 {
+		if underlying == nil {
+			frames = append(frames, frame{offset: 1, values: last.values, timing: timing})
+		} else {
+			frames = append(frames, frame{offset: 1, values: underlying, timing: timing})
+		}
+	}
+	idx := 0
+	for idx < len(frames)-2 && progress >= frames[idx+1].offset {
+		idx++
+	}
+	a, b := frames[idx], frames[idx+1]
+	span := b.offset - a.offset
+	if span <= 0 {
+		return cloneValues(b.values), true
+	}
+	t := a.timing.Apply(min(1, max(0, (progress-a.offset)/span)))
+	if v, ok := InterpolateValues(a.values, b.values, t); ok {
+		return v, true
+	}
+	return steppedValues(a.values, b.values, t), true
+}
+
+// elementAnimations is the CSS animation and transition state of an element
+type elementAnimations struct {
+	keyframes   map[string]rules.KeyframeSet
+	animations  []runningAnimation
+	transitions map[string]*runningTransition
+	targets     map[string][]rules.PropertyValue
+	registered  bool
+}
+
+// apply updates the running animations and transitions from the computed
+// rules and then replaces the rule values with the animated values. Calling
+// this multiple times without the rules or time changing gives the same result.
+func (a *elementAnimations) apply(all []rules.Rule) []rules.Rule {
+	// Invalid values are reported when the properties themselves are
+	// processed, here they only mean there is nothing to animate
+	transitions, _ := ComputedTransitions(all)
+	animations, _ := ComputedAnimations(all)
+	a.syncTransitions(all, transitions)
+	a.syncAnimations(animations)
+	underlying := make(map[string][]rules.PropertyValue, len(all))
+	for i := range all {
+		underlying[all[i].Property] = all[i].Values
+	}
+	overlay := func(property string, values []rules.PropertyValue) {
+		for i := range all {
+			if all[i].Property == property {
+				all[i].Values = values
+				return
+			}
+		}
+		r := rules.Rule{Property: property, Values: values}
+		if p, ok := LinkedPropertyMap[property]; ok {
+			r.Sort = p.Sort()
+		}
+		all = append(all, r)
+	}
+	for property, t := range a.transitions {
+		overlay(property, t.values())
+	}
+	for i := range a.animations {
+		anim := &a.animations[i]
+		set, ok := a.keyframes[anim.spec.Name]
+		if !ok {
+			continue
+		}
+		progress, ok := anim.progress()
+		if !ok {
+			continue
+		}
+		for _, property := range set.Properties() {
+			if isAnimationProperty(property) {
+				continue
+			}
+			if v, ok := keyframeValues(&set, property, progress,
+				anim.spec.Timing, underlying[property]); ok {
+				overlay(property, v)
+			}
+		}
+	}
+	return all
+}
+
+func (a *elementAnimations) syncTransitions(all []rules.Rule, specs []TransitionSpec) {
+	if a.targets == nil {
+		a.targets = make(map[string][]rules.PropertyValue)
+	}
+	seen := make(map[string]struct{}, len(all))
+	for i := range all {
+		property := all[i].Property
+		if isAnimationProperty(property) {
+			continue
+		}
+		seen[property] = struct{}{}
+		target := all[i].Values
+		previous, hadPrevious := a.targets[property]
+		if hadPrevious && propertyValuesEqual(previous, target) {
+			continue
+		}
+		a.targets[property] = cloneValues(target)
+		spec, transitions := transitionFor(specs, property)
+		if !hadPrevious || !transitions {
+			delete(a.transitions, property)
+			continue
+		}
+		// Start from what is currently shown so a transition that is
+		// interrupted part way through doesn't jump
+		from := previous
+		if running, ok := a.transitions[property]; ok {
+			from = running.values()
+		}
+		if _, ok := InterpolateValues(from, target, 0); !ok {
+			delete(a.transitions, property)
+			continue
+		}
+		if a.transitions == nil {
+			a.transitions = make(map[string]*runningTransition)
+		}
+		a.transitions[property] = &runningTransition{
+			spec: spec,
+			from: cloneValues(from),
+			to:   cloneValues(target),
+		}
+	}
+	for property := range a.targets {
+		if _, ok := seen[property]; !ok {
+			delete(a.targets, property)
+			delete(a.transitions, property)
+		}
+	}
+	for property, t := range a.transitions {
+		if t.finished() {
+			delete(a.transitions, property)
+		}
+	}
+}
+
+func (a *elementAnimations) syncAnimations(specs []AnimationSpec) {
+	next := make([]runningAnimation, 0, len(specs))
+	used := make([]bool, len(a.animations))
+	for i := range specs {
+		if specs[i].Name == "none" || specs[i].Name == "" {
+			continue
+		}
+		anim := runningAnimation{spec: specs[i]}
+		// An animation that is still listed keeps its time, even when the
+		// other properties of the animation change
+		for j := range a.animations {
+			if !used[j] && a.animations[j].spec.Name == specs[i].Name {
+				anim.elapsed = a.animations[j].elapsed
+				used[j] = true
+				break
+			}
+		}
+		next = append(next, anim)
+	}
+	a.animations = next
+}
+
+// advance moves time forward for the running animations and transitions
+func (a *elementAnimations) advance(deltaTime float64) {
+	for _, t := range a.transitions {
+		t.elapsed += deltaTime
+	}
+	for i := range a.animations {
+		anim := &a.animations[i]
+		if anim.spec.Paused {
+			continue
+		}
+		if _, ok := a.keyframes[anim.spec.Name]; !ok {
+			continue
+		}
+		if !anim.finished() {
+			anim.elapsed += deltaTime
+		}
+	}
+}
+
+// isRunning returns true if anything will change when time advances
+func (a *elementAnimations) isRunning() bool {
+	if len(a.transitions) > 0 {
+		return true
+	}
+	for i := range a.animations {
+		anim := &a.animations[i]
+		if _, ok := a.keyframes[anim.spec.Name]; ok && !anim.spec.Paused && !anim.finished() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,325 @@
+/******************************************************************************/
+/* html_element_animation_test.go                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"math"
+	"testing"
+
+	"kaijuengine.com/engine/ui/markup/css/rules"
+)
+
+func testValue(str string) rules.PropertyValue { return rules.PropertyValue{Str: str} }
+
+func testFunctionValue(name string, args ...string) rules.PropertyValue {
+	return rules.PropertyValue{Str: name, Args: args}
+}
+
+func testValues(strs ...string) []rules.PropertyValue {
+	out := make([]rules.PropertyValue, len(strs))
+	for i := range strs {
+		out[i] = testValue(strs[i])
+	}
+	return out
+}
+
+func ruleValue(t *testing.T, all []rules.Rule, property string) string {
+	t.Helper()
+	for i := range all {
+		if all[i].Property == property {
+			if len(all[i].Values) != 1 {
+				t.Fatalf("expected 1 value for %s, got %#v", property, all[i].Values)
+			}
+			return all[i].Values[0].Str
+		}
+	}
+	return ""
+}
+
+func near(a, b float32) bool { return math.Abs(float64(a-b)) < 0.001 }
+
+func TestTimingFunctionKeywords(t *testing.T) {
+	for _, key := range []string{"linear", "ease", "ease-in", "ease-out", "ease-in-out", "ease-out-bounce"} {
+		tf, err := ParseTimingFunction(testValue(key))
+		if err != nil {
+			t.Fatalf("%s failed to parse: %v", key, err)
+		}
+		if tf.Apply(0) != 0 || tf.Apply(1) != 1 {
+			t.Fatalf("%s should start at 0 and end at 1", key)
+		}
+	}
+	if v := TimingFunctionEaseIn.Apply(0.5); !near(v, 0.3153) {
+		t.Fatalf("ease-in at 0.5 expected 0.3153 but got %f", v)
+	}
+	if v := TimingFunctionEaseOut.Apply(0.5); !near(v, 0.6847) {
+		t.Fatalf("ease-out at 0.5 expected 0.6847 but got %f", v)
+	}
+	if _, err := ParseTimingFunction(testValue("wobble")); err == nil {
+		t.Fatal("expected an unknown timing keyword to fail")
+	}
+}
+
+func TestTimingFunctionCubicBezier(t *testing.T) {
+	tf, err := ParseTimingFunction(testFunctionValue("cubic-bezier", "0", "0", "1", "1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float32{0.1, 0.25, 0.5, 0.9} {
+		if v := tf.Apply(x); !near(v, x) {
+			t.Fatalf("a straight cubic-bezier should be linear, %f gave %f", x, v)
+		}
+	}
+	if _, err = ParseTimingFunction(testFunctionValue("cubic-bezier", "1.5", "0", "1", "1")); err == nil {
+		t.Fatal("expected x values outside of 0-1 to fail")
+	}
+	if _, err = ParseTimingFunction(testFunctionValue("cubic-bezier", "0", "0", "1")); err == nil {
+		t.Fatal("expected cubic-bezier with 3 arguments to fail")
+	}
+}
+
+func TestTimingFunctionSteps(t *testing.T) {
+	end, _ := ParseTimingFunction(testFunctionValue("steps", "4"))
+	start, _ := ParseTimingFunction(testFunctionValue("steps", "4", "jump-start"))
+	none, _ := ParseTimingFunction(testFunctionValue("steps", "5", "jump-none"))
+	cases := []struct {
+		tf   TimingFunction
+		in   float32
+		want float32
+	}{
+		{end, 0, 0}, {end, 0.3, 0.25}, {end, 0.99, 0.75}, {end, 1, 1},
+		{start, 0, 0.25}, {start, 0.3, 0.5}, {start, 0.99, 1},
+		{none, 0, 0}, {none, 0.5, 0.5}, {none, 0.99, 1},
+	}
+	for i, c := range cases {
+		if v := c.tf.Apply(c.in); !near(v, c.want) {
+			t.Fatalf("case %d: expected %f but got %f", i, c.want, v)
+		}
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	cases := map[string]float64{"1s": 1, "250ms": 0.25, "0": 0, "-0.5s": -0.5, ".5s": 0.5}
+	for in, want := range cases {
+		if v, err := ParseTime(in); err != nil || v != want {
+			t.Fatalf("%s expected %f but got %f (%v)", in, want, v, err)
+		}
+	}
+	for _, in := range []string{"1", "fast", "10px"} {
+		if _, err := ParseTime(in); err == nil {
+			t.Fatalf("expected %s to not be a time", in)
+		}
+	}
+}
+
+func TestInterpolateValues(t *testing.T) {
+	v, ok := InterpolateValues(testValues("10px", "0", "solid"), testValues("20px", "4em", "solid"), 0.5)
+	if !ok {
+		t.Fatal("expected lengths to interpolate")
+	}
+	if v[0].Str != "15px" || v[1].Str != "2em" || v[2].Str != "solid" {
+		t.Fatalf("unexpected interpolated values %#v", v)
+	}
+	if _, ok = InterpolateValues(testValues("10px"), testValues("50%"), 0.5); ok {
+		t.Fatal("lengths with different units should not interpolate")
+	}
+	if _, ok = InterpolateValues(testValues("auto"), testValues("10px"), 0.5); ok {
+		t.Fatal("keywords should not interpolate")
+	}
+	if _, ok = InterpolateValues(testValues("1px", "2px"), testValues("1px"), 0.5); ok {
+		t.Fatal("value lists of different lengths should not interpolate")
+	}
+}
+
+func TestInterpolateColors(t *testing.T) {
+	v, ok := InterpolateValues(testValues("#000000"), testValues("white"), 0.5)
+	if !ok || v[0].Str != "#7f7f7fff" {
+		t.Fatalf("expected black to white at half to be grey, got %#v", v)
+	}
+	from := []rules.PropertyValue{testFunctionValue("rgba", "255", "0", "0", "0")}
+	v, ok = InterpolateValues(from, testValues("#ff0000"), 0.5)
+	if !ok || v[0].Str != "#ff00007f" {
+		t.Fatalf("expected rgba() to blend with a hex color, got %#v", v)
+	}
+	v, ok = InterpolateValues(testValues("transparent"), testValues("#00ff00"), 0.5)
+	if !ok || v[0].Str != "#00ff007f" {
+		t.Fatalf("expected transparent to fade in without darkening, got %#v", v)
+	}
+}
+
+func TestParseAnimationShorthand(t *testing.T) {
+	values := testValues("pulse", "2s", "ease-in", "500ms", "infinite", "alternate", "both", "paused")
+	specs, err := ParseAnimationShorthand(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 1 {
+		t.Fatalf("expected 1 animation, got %d", len(specs))
+	}
+	s := specs[0]
+	if s.Name != "pulse" || s.Duration != 2 || s.Delay != 0.5 ||
+		!math.IsInf(s.IterationCount, 1) || s.Direction != AnimationDirectionAlternate ||
+		s.FillMode != AnimationFillModeBoth || !s.Paused || s.Timing != TimingFunctionEaseIn {
+		t.Fatalf("unexpected animation %#v", s)
+	}
+	specs, err = ParseAnimationShorthand(testValues("fade", "1s", "slide", "2s", "3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 || specs[0].Name != "fade" || specs[1].Name != "slide" ||
+		specs[1].Duration != 2 || specs[1].IterationCount != 3 {
+		t.Fatalf("expected the comma separated animations to be split, got %#v", specs)
+	}
+}
+
+func TestComputedTransitionsLonghandsOverrideShorthand(t *testing.T) {
+	all := []rules.Rule{
+		{Property: "transition", Values: testValues("opacity", "1s", "width", "2s", "linear")},
+		{Property: "transition-duration", Values: testValues("300ms")},
+	}
+	specs, err := ComputedTransitions(all)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(specs) != 2 {
+		t.Fatalf("expected 2 transitions, got %d", len(specs))
+	}
+	if specs[0].Property != "opacity" || specs[0].Duration != 0.3 || specs[0].Timing != TimingFunctionEase {
+		t.Fatalf("unexpected first transition %#v", specs[0])
+	}
+	if specs[1].Property != "width" || specs[1].Duration != 0.3 || specs[1].Timing != TimingFunctionLinear {
+		t.Fatalf("unexpected second transition %#v", specs[1])
+	}
+}
+
+func TestElementTransition(t *testing.T) {
+	a := elementAnimations{}
+	base := func(width string) []rules.Rule {
+		return []rules.Rule{
+			{Property: "transition", Values: testValues("width", "1s", "linear")},
+			{Property: "width", Values: testValues(width)},
+		}
+	}
+	if v := ruleValue(t, a.apply(base("10px")), "width"); v != "10px" {
+		t.Fatalf("the first style should apply without a transition, got %s", v)
+	}
+	if a.isRunning() {
+		t.Fatal("nothing should be running before a value changes")
+	}
+	if v := ruleValue(t, a.apply(base("20px")), "width"); v != "10px" {
+		t.Fatalf("a transition should start from the previous value, got %s", v)
+	}
+	if !a.isRunning() {
+		t.Fatal("expected the transition to be running")
+	}
+	a.advance(0.5)
+	if v := ruleValue(t, a.apply(base("20px")), "width"); v != "15px" {
+		t.Fatalf("expected the transition to be half way, got %s", v)
+	}
+	// Interrupting the transition starts the next one from the current value
+	if v := ruleValue(t, a.apply(base("5px")), "width"); v != "15px" {
+		t.Fatalf("an interrupted transition should not jump, got %s", v)
+	}
+	a.advance(1.5)
+	if v := ruleValue(t, a.apply(base("5px")), "width"); v != "5px" {
+		t.Fatalf("expected the transition to end at the target, got %s", v)
+	}
+	if a.isRunning() {
+		t.Fatal("the transition should have finished")
+	}
+}
+
+func TestElementTransitionIgnoresUnlistedProperties(t *testing.T) {
+	a := elementAnimations{}
+	base := func(width string) []rules.Rule {
+		return []rules.Rule{
+			{Property: "transition", Values: testValues("opacity", "1s")},
+			{Property: "width", Values: testValues(width)},
+		}
+	}
+	a.apply(base("10px"))
+	if v := ruleValue(t, a.apply(base("20px")), "width"); v != "20px" || a.isRunning() {
+		t.Fatalf("width is not transitioned so it should change right away, got %s", v)
+	}
+}
+
+func testKeyframes() map[string]rules.KeyframeSet {
+	return map[string]rules.KeyframeSet{
+		"fade": {
+			Name: "fade",
+			Frames: []rules.Keyframe{
+				{Offset: 0, Rules: []rules.Rule{{Property: "opacity", Values: testValues("0")}}},
+				{Offset: 1, Rules: []rules.Rule{{Property: "opacity", Values: testValues("1")}}},
+			},
+		},
+		"grow": {
+			Name: "grow",
+			Frames: []rules.Keyframe{
+				{Offset: 0.5, Rules: []rules.Rule{{Property: "width", Values: testValues("30px")}}},
+			},
+		},
+	}
+}
+
+func TestElementKeyframeAnimation(t *testing.T) {
+	a := elementAnimations{keyframes: testKeyframes()}
+	all := func() []rules.Rule {
+		return []rules.Rule{{Property: "animation", Values: testValues("fade", "2s", "linear", "forwards")}}
+	}
+	if v := ruleValue(t, a.apply(all()), "opacity"); v != "0" {
+		t.Fatalf("expected the animation to start at the first frame, got %s", v)
+	}
+	a.advance(0.5)
+	if v := ruleValue(t, a.apply(all()), "opacity"); v != "0.25" {
+		t.Fatalf("expected a quarter of the animation, got %s", v)
+	}
+	a.advance(5)
+	if v := ruleValue(t, a.apply(all()), "opacity"); v != "1" {
+		t.Fatalf("fill forwards should keep the last frame, got %s", v)
+	}
+	if a.isRunning() {
+		t.Fatal("the animation should have finished")
+	}
+}
+
+func TestElementKeyframeAnimationUsesUnderlyingValue(t *testing.T) {
+	a := elementAnimations{keyframes: testKeyframes()}
+	all := func() []rules.Rule {
+		return []rules.Rule{
+			{Property: "width", Values: testValues("10px")},
+			{Property: "animation", Values: testValues("grow", "1s", "linear", "infinite", "alternate")},
+		}
+	}
+	a.apply(all())
+	a.advance(0.25)
+	if v := ruleValue(t, a.apply(all()), "width"); v != "20px" {
+		t.Fatalf("expected the implicit from frame to use the element width, got %s", v)
+	}
+	a.advance(1.5)
+	// 1.75s into an alternating animation is a quarter from the end, reversed
+	if v := ruleValue(t, a.apply(all()), "width"); v != "20px" {
+		t.Fatalf("expected the alternate iteration to run in reverse, got %s", v)
+	}
+	if !a.isRunning() {
+		t.Fatal("an infinite animation should keep running")
+	}
+	noAnimation := []rules.Rule{{Property: "width", Values: testValues("10px")}}
+	if v := ruleValue(t, a.apply(noAnimation), "width"); v != "10px" || a.isRunning() {
+		t.Fatalf("removing the animation should restore the value, got %s", v)
+	}
+}
+
+func TestElementKeyframeAnimationFillNone(t *testing.T) {
+	a := elementAnimations{keyframes: testKeyframes()}
+	all := []rules.Rule{{Property: "animation", Values: testValues("fade", "1s", "1s")}}
+	if v := ruleValue(t, a.apply(rules.CloneRules(all)), "opacity"); v != "" {
+		t.Fatalf("the delay should not apply the first frame without backwards fill, got %s", v)
+	}
+	a.advance(3)
+	if v := ruleValue(t, a.apply(rules.CloneRules(all)), "opacity"); v != "" {
+		t.Fatalf("a finished animation should not apply without forwards fill, got %s", v)
+	}
+}
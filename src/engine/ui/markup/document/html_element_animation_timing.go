@@ -0,0 +1,232 @@
+/******************************************************************************/
+/* html_element_animation_timing.go                                           */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"kaijuengine.com/engine/systems/tweening"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+)
+
+type timingKind uint8
+
+const (
+	timingKindEasing timingKind = iota
+	timingKindCubicBezier
+	timingKindSteps
+)
+
+// StepPosition is the jump term of the CSS steps() timing function
+type StepPosition uint8
+
+const (
+	StepPositionJumpEnd StepPosition = iota
+	StepPositionJumpStart
+	StepPositionJumpNone
+	StepPositionJumpBoth
+)
+
+// TimingFunction is a parsed CSS <easing-function>. The CSS keywords and
+// cubic-bezier() are evaluated with [tweening.CubicBezier], the additional
+// keywords like ease-out-bounce use the matching [tweening.Easing].
+type TimingFunction struct {
+	kind   timingKind
+	easing tweening.Easing
+	bezier tweening.CubicBezier
+	steps  int
+	jump   StepPosition
+}
+
+var (
+	TimingFunctionLinear    = TimingFunction{kind: timingKindEasing, easing: tweening.EasingLinear}
+	TimingFunctionEase      = TimingFunction{kind: timingKindCubicBezier, bezier: tweening.CubicBezier{X1: 0.25, Y1: 0.1, X2: 0.25, Y2: 1}}
+	TimingFunctionEaseIn    = TimingFunction{kind: timingKindCubicBezier, bezier: tweening.CubicBezier{X1: 0.42, Y1: 0, X2: 1, Y2: 1}}
+	TimingFunctionEaseOut   = TimingFunction{kind: timingKindCubicBezier, bezier: tweening.CubicBezier{X1: 0, Y1: 0, X2: 0.58, Y2: 1}}
+	TimingFunctionEaseInOut = TimingFunction{kind: timingKindCubicBezier, bezier: tweening.CubicBezier{X1: 0.42, Y1: 0, X2: 0.58, Y2: 1}}
+)
+
+var timingKeywords = map[string]TimingFunction{
+	"linear":              TimingFunctionLinear,
+	"ease":                TimingFunctionEase,
+	"ease-in":             TimingFunctionEaseIn,
+	"ease-out":            TimingFunctionEaseOut,
+	"ease-in-out":         TimingFunctionEaseInOut,
+	"step-start":          {kind: timingKindSteps, steps: 1, jump: StepPositionJumpStart},
+	"step-end":            {kind: timingKindSteps, steps: 1, jump: StepPositionJumpEnd},
+	"ease-in-sine":        {easing: tweening.EasingInSine},
+	"ease-out-sine":       {easing: tweening.EasingOutSine},
+	"ease-in-out-sine":    {easing: tweening.EasingInAndOutSine},
+	"ease-in-quad":        {easing: tweening.EasingInQuad},
+	"ease-out-quad":       {easing: tweening.EasingOutQuad},
+	"ease-in-out-quad":    {easing: tweening.EasingInAndOutQuad},
+	"ease-in-cubic":       {easing: tweening.EasingInCubic},
+	"ease-out-cubic":      {easing: tweening.EasingOutCubic},
+	"ease-in-out-cubic":   {easing: tweening.EasingInAndOutCubic},
+	"ease-in-quart":       {easing: tweening.EasingInQuart},
+	"ease-out-quart":      {easing: tweening.EasingOutQuart},
+	"ease-in-out-quart":   {easing: tweening.EasingInAndOutQuart},
+	"ease-in-quint":       {easing: tweening.EasingInQuint},
+	"ease-out-quint":      {easing: tweening.EasingOutQuint},
+	"ease-in-out-quint":   {easing: tweening.EasingInAndOutQuint},
+	"ease-in-expo":        {easing: tweening.EasingInExpo},
+	"ease-out-expo":       {easing: tweening.EasingOutExpo},
+	"ease-in-out-expo":    {easing: tweening.EasingInAndOutExpo},
+	"ease-in-circ":        {easing: tweening.EasingInCirc},
+	"ease-out-circ":       {easing: tweening.EasingOutCirc},
+	"ease-in-out-circ":    {easing: tweening.EasingInAndOutCirc},
+	"ease-in-back":        {easing: tweening.EasingInBack},
+	"ease-out-back":       {easing: tweening.EasingOutBack},
+	"ease-in-out-back":    {easing: tweening.EasingInAndOutBack},
+	"ease-in-elastic":     {easing: tweening.EasingInElastic},
+	"ease-out-elastic":    {easing: tweening.EasingOutElastic},
+	"ease-in-out-elastic": {easing: tweening.EasingInAndOutElastic},
+	"ease-in-bounce":      {easing: tweening.EasingInBounce},
+	"ease-out-bounce":     {easing: tweening.EasingOutBounce},
+	"ease-in-out-bounce":  {easing: tweening.EasingInAndOutBounce},
+}
+
+// IsTimingFunction returns true if the value is an easing keyword or one of
+// the cubic-bezier() or steps() functions
+func IsTimingFunction(value rules.PropertyValue) bool {
+	if value.IsFunction() {
+		return value.Str == "cubic-bezier" || value.Str == "steps"
+	}
+	_, ok := timingKeywords[strings.ToLower(value.Str)]
+	return ok
+}
+
+// ParseTimingFunction reads an easing keyword, cubic-bezier(), or steps()
+func ParseTimingFunction(value rules.PropertyValue) (TimingFunction, error) {
+	switch value.Str {
+	case "cubic-bezier":
+		return parseCubicBezier(value.Args)
+	case "steps":
+		return parseSteps(value.Args)
+	}
+	if t, ok := timingKeywords[strings.ToLower(value.Str)]; ok {
+		return t, nil
+	}
+	return TimingFunction{}, fmt.Errorf("invalid timing function '%s'", value.Str)
+}
+
+func parseCubicBezier(args []string) (TimingFunction, error) {
+	if len(args) != 4 {
+		return TimingFunction{}, fmt.Errorf("cubic-bezier() expects 4 arguments but got %d", len(args))
+	}
+	var p [4]float32
+	for i := range args {
+		v, err := strconv.ParseFloat(strings.TrimSpace(args[i]), 32)
+		if err != nil {
+			return TimingFunction{}, fmt.Errorf("invalid cubic-bezier() argument '%s'", args[i])
+		}
+		p[i] = float32(v)
+	}
+	if p[0] < 0 || p[0] > 1 || p[2] < 0 || p[2] > 1 {
+		return TimingFunction{}, fmt.Errorf("cubic-bezier() x values must be between 0 and 1")
+	}
+	return TimingFunction{
+		kind:   timingKindCubicBezier,
+		bezier: tweening.CubicBezier{X1: p[0], Y1: p[1], X2: p[2], Y2: p[3]},
+	}, nil
+}
+
+func parseSteps(args []string) (TimingFunction, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return TimingFunction{}, fmt.Errorf("steps() expects 1 or 2 arguments but got %d", len(args))
+	}
+	steps, err := strconv.Atoi(strings.TrimSpace(args[0]))
+	if err != nil || steps < 1 {
+		return TimingFunction{}, fmt.Errorf("invalid steps() count '%s'", args[0])
+	}
+	t := TimingFunction{kind: timingKindSteps, steps: steps}
+	if len(args) == 2 {
+		switch strings.TrimSpace(args[1]) {
+		case "jump-end", "end":
+			t.jump = StepPositionJumpEnd
+		case "jump-start", "start":
+			t.jump = StepPositionJumpStart
+		case "jump-none":
+			t.jump = StepPositionJumpNone
+		case "jump-both":
+			t.jump = StepPositionJumpBoth
+		default:
+			return TimingFunction{}, fmt.Errorf("invalid steps() position '%s'", args[1])
+		}
+	}
+	if t.jump == StepPositionJumpNone && steps < 2 {
+		return TimingFunction{}, fmt.Errorf("steps() with jump-none requires at least 2 steps")
+	}
+	return t, nil
+}
+
+// Apply returns the eased progress for the linear progress t (0 to 1)
+func (t TimingFunction) Apply(progress float32) float32 {
+	switch t.kind {
+	case timingKindCubicBezier:
+		return t.bezier.Apply(progress)
+	case timingKindSteps:
+		return t.applySteps(progress)
+	default:
+		if progress <= 0 {
+			return 0
+		} else if progress >= 1 {
+			return 1
+		}
+		return t.easing.Apply(progress)
+	}
+}
+
+func (t TimingFunction) applySteps(progress float32) float32 {
+	if progress >= 1 {
+		return 1
+	}
+	if progress < 0 {
+		progress = 0
+	}
+	n := float32(t.steps)
+	step := float32(math.Floor(float64(progress * n)))
+	switch t.jump {
+	case StepPositionJumpStart:
+		return min(step+1, n) / n
+	case StepPositionJumpNone:
+		return min(step, n-1) / (n - 1)
+	case StepPositionJumpBoth:
+		return (step + 1) / (n + 1)
+	default:
+		return step / n
+	}
+}
+
+// ParseTime reads a CSS <time> value (such as 1.5s or 250ms) in seconds
+func ParseTime(str string) (float64, error) {
+	var v float64
+	var err error
+	switch {
+	case strings.HasSuffix(str, "ms"):
+		v, err = strconv.ParseFloat(strings.TrimSuffix(str, "ms"), 64)
+		v /= 1000
+	case strings.HasSuffix(str, "s"):
+		v, err = strconv.ParseFloat(strings.TrimSuffix(str, "s"), 64)
+	case str == "0":
+		return 0, nil
+	default:
+		return 0, fmt.Errorf("invalid time '%s'", str)
+	}
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return 0, fmt.Errorf("invalid time '%s'", str)
+	}
+	return v, nil
+}
+
+func isTime(str string) bool {
+	_, err := ParseTime(str)
+	return err == nil
+}
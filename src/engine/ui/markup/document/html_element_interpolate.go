@@ -0,0 +1,192 @@
+/******************************************************************************/
+/* html_element_interpolate.go                                                */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"strconv"
+	"strings"
+
+	"kaijuengine.com/engine/ui/markup/css/helpers"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+	"kaijuengine.com/matrix"
+)
+
+// InterpolateValues blends two lists of property values by t (0 to 1). Values
+// are blended token by token, numbers and lengths with the same unit are
+// blended numerically, colors are blended per channel, and identical tokens
+// are kept. If any pair of tokens can't be blended, the values are returned
+// unchanged along with false, the caller decides how to step between them.
+func InterpolateValues(from, to []rules.PropertyValue, t float32) ([]rules.PropertyValue, bool) {
+	if len(from) != len(to) {
+		return nil, false
+	}
+	out := make([]rules.PropertyValue, len(from))
+	for i := range from {
+		v, ok := interpolateValue(from[i], to[i], t)
+		if !ok {
+			return nil, false
+		}
+		out[i] = v
+	}
+	return out, true
+}
+
+// steppedValues is used for values that can't be interpolated, they switch
+// from one to the other half way through
+func steppedValues(from, to []rules.PropertyValue, t float32) []rules.PropertyValue {
+	if t < 0.5 {
+		return cloneValues(from)
+	}
+	return cloneValues(to)
+}
+
+func cloneValues(values []rules.PropertyValue) []rules.PropertyValue {
+	out := make([]rules.PropertyValue, len(values))
+	for i := range values {
+		out[i] = values[i].Clone()
+	}
+	return out
+}
+
+func interpolateValue(from, to rules.PropertyValue, t float32) (rules.PropertyValue, bool) {
+	if propertyValueEqual(from, to) {
+		return from.Clone(), true
+	}
+	if a, ok := valueColor(from); ok {
+		if b, ok := valueColor(to); ok {
+			// A fully transparent end has no meaningful hue, blending towards
+			// its RGB would darken (or lighten) the color on the way out
+			if a.A() <= 0 {
+				a = matrix.NewColor(b.R(), b.G(), b.B(), 0)
+			} else if b.A() <= 0 {
+				b = matrix.NewColor(a.R(), a.G(), a.B(), 0)
+			}
+			return rules.PropertyValue{Str: matrix.ColorMix(a, b, t).Hex()}, true
+		}
+		return rules.PropertyValue{}, false
+	}
+	if from.IsFunction() || to.IsFunction() {
+		if from.Str != to.Str || len(from.Args) != len(to.Args) {
+			return rules.PropertyValue{}, false
+		}
+		out := rules.PropertyValue{
+			Str:     from.Str,
+			Args:    make([]string, len(from.Args)),
+			ArgNums: make([]float32, len(from.Args)),
+		}
+		for i := range from.Args {
+			s, n, ok := interpolateLength(from.Args[i], to.Args[i], argNum(from, i), argNum(to, i), t)
+			if !ok {
+				return rules.PropertyValue{}, false
+			}
+			out.Args[i] = s
+			out.ArgNums[i] = n
+		}
+		return out, true
+	}
+	s, n, ok := interpolateLength(from.Str, to.Str, from.Num, to.Num, t)
+	if !ok {
+		return rules.PropertyValue{}, false
+	}
+	return rules.PropertyValue{Str: s, Num: n}, true
+}
+
+func argNum(v rules.PropertyValue, idx int) float32 {
+	if idx < len(v.ArgNums) {
+		return v.ArgNums[idx]
+	}
+	return 0
+}
+
+// interpolateLength blends two numeric tokens such as 10px and 20px, the
+// resolved numbers are blended alongside so that the units that depend on the
+// window (vw, vh, etc.) remain correct
+func interpolateLength(from, to string, fromNum, toNum, t float32) (string, float32, bool) {
+	if from == to {
+		return from, fromNum, true
+	}
+	a, aUnit, ok := splitNumber(from)
+	if !ok {
+		return "", 0, false
+	}
+	b, bUnit, ok := splitNumber(to)
+	if !ok {
+		return "", 0, false
+	}
+	// A unitless zero is allowed to stand in for a zero length of any unit
+	if aUnit != bUnit {
+		if aUnit == "" && a == 0 {
+			aUnit = bUnit
+		} else if bUnit == "" && b == 0 {
+			bUnit = aUnit
+		} else {
+			return "", 0, false
+		}
+	}
+	v := a + (b-a)*t
+	return strconv.FormatFloat(float64(v), 'f', -1, 32) + aUnit,
+		fromNum + (toNum-fromNum)*t, true
+}
+
+func splitNumber(str string) (float32, string, bool) {
+	end := 0
+	for end < len(str) {
+		c := str[end]
+		if (c >= '0' && c <= '9') || c == '.' || ((c == '-' || c == '+') && end == 0) {
+			end++
+		} else if (c == 'e' || c == 'E') && end > 0 && end+1 < len(str) &&
+			(str[end+1] >= '0' && str[end+1] <= '9' || str[end+1] == '-') {
+			end += 2
+		} else {
+			break
+		}
+	}
+	if end == 0 {
+		return 0, "", false
+	}
+	v, err := strconv.ParseFloat(str[:end], 32)
+	if err != nil {
+		return 0, "", false
+	}
+	return float32(v), str[end:], true
+}
+
+func valueColor(v rules.PropertyValue) (matrix.Color, bool) {
+	switch v.Str {
+	case "rgb", "rgba":
+		if len(v.Args) < 3 {
+			return matrix.Color{}, false
+		}
+		var c [4]float32
+		c[3] = 1
+		for i := 0; i < len(v.Args) && i < 4; i++ {
+			f, err := strconv.ParseFloat(strings.TrimSpace(v.Args[i]), 32)
+			if err != nil {
+				return matrix.Color{}, false
+			}
+			if i < 3 {
+				f /= 255
+			}
+			c[i] = float32(f)
+		}
+		return matrix.NewColor(c[0], c[1], c[2], c[3]), true
+	case "transparent":
+		return matrix.ColorClear(), true
+	}
+	if v.IsFunction() {
+		return matrix.Color{}, false
+	}
+	hex := v.Str
+	if named, ok := helpers.ColorMap[strings.ToLower(hex)]; ok {
+		hex = named
+	}
+	if !strings.HasPrefix(hex, "#") {
+		return matrix.Color{}, false
+	}
+	c, err := matrix.ColorFromHexString(hex)
+	return c, err == nil
+}
@@ -83,6 +83,7 @@ type ElementLayoutStylizer struct {
 	currentState     rules.RuleInvoke
 	interestedStates rules.RuleInvoke
 	appliedRules     []rules.Rule
+	animations       elementAnimations
 	pendingLayout    bool
 	pendingPaint     bool
 }
@@ -389,6 +390,10 @@ func (s *ElementLayoutStylizer) computedRules() []rules.Rule {
 			all = all[:i+len(subRules)]
 		}
 	}
+	all = s.animations.apply(all)
+	if s.animations.isRunning() && !s.animations.registered {
+		s.animations.registered = elm.UI.AddAnimator(s)
+	}
 	slices.SortStableFunc(all, func(x, y rules.Rule) int { return x.Sort - y.Sort })
 	for i := range all {
 		all[i].Invocation = rules.RuleInvokeImmediate
@@ -561,10 +566,32 @@ func propertyValueEqual(a, b rules.PropertyValue) bool {
 		slices.Equal(a.Args, b.Args) && slices.Equal(a.ArgNums, b.ArgNums)
 }
 
+// SetKeyframes sets the @keyframes that the animation-name of this element
+// can reference
+func (s *ElementLayoutStylizer) SetKeyframes(keyframes map[string]rules.KeyframeSet) {
+	s.animations.keyframes = keyframes
+}
+
+// Animate advances the CSS animations and transitions of the element and
+// queues the changed values to be applied when the UI is next cleaned. It
+// implements [ui.Animator] and returns false once nothing is left to animate.
+func (s *ElementLayoutStylizer) Animate(deltaTime float64) bool {
+	elm := s.element.Value()
+	if elm == nil || elm.UI == nil || elm.UI.Entity().IsDestroyed() {
+		s.animations.registered = false
+		return false
+	}
+	s.animations.advance(deltaTime)
+	s.queueComputedDiff(s.computedRules())
+	s.animations.registered = s.animations.isRunning()
+	return s.animations.registered
+}
+
 func (s *ElementLayoutStylizer) clone(newElm *Element) ElementLayoutStylizer {
 	out := ElementLayoutStylizer{
 		element: weak.Make(newElm),
 	}
+	out.animations.keyframes = s.animations.keyframes
 	out.ReplaceRules(s.styleRules)
 	return out
 }
@@ -0,0 +1,73 @@
+/******************************************************************************/
+/* html_localization.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	xhtml "golang.org/x/net/html"
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+const (
+	dataL10nAttribute     = "data-l10n"
+	dataL10nArgsAttribute = "data-l10n-args"
+)
+
+// Localize sets the text of the elements that have a data-l10n attribute to
+// the string of that key in the host's selected language:
+//
+//	<button data-l10n="menu.play"></button>
+//	<span data-l10n="hud.lives" data-l10n-args='{"n": 3}'></span>
+//	<input type="text" data-l10n="login.name_hint" />
+//
+// The args are a JSON object of the arguments of the message, and inputs
+// get the string as their placeholder. Documents are localized when they are
+// set up and again when the language changes, this is to be called after
+// changing the key or args of an element.
+func (d *Document) Localize() {
+	defer tracing.NewRegion("Document.Localize").End()
+	host := d.host.Value()
+	if host == nil || host.Strings == nil {
+		return
+	}
+	// Adding a label for the text adds to the elements, so the list is copied
+	for _, elm := range slices.Clone(d.Elements) {
+		if elm.Type != xhtml.ElementNode || elm.UI == nil || !elm.HasAttribute(dataL10nAttribute) {
+			continue
+		}
+		args, err := parseL10nArgs(elm.Attribute(dataL10nArgsAttribute))
+		if err != nil {
+			slog.Error("invalid data-l10n-args", "element", elm.Data, "error", err)
+		}
+		text := host.Strings.Format(elm.Attribute(dataL10nAttribute), args)
+		switch {
+		case elm.UI.IsType(ui.ElementTypeInput):
+			elm.UI.ToInput().SetPlaceholder(text)
+		case elm.UI.IsType(ui.ElementTypeTextArea):
+			elm.UI.ToTextArea().SetPlaceholder(text)
+		default:
+			d.addTextLabel(elm)
+			setElementLabelText(elm, text)
+		}
+	}
+}
+
+func parseL10nArgs(attr string) (map[string]any, error) {
+	if attr == "" {
+		return nil, nil
+	}
+	args := map[string]any{}
+	if err := json.Unmarshal([]byte(attr), &args); err != nil {
+		return nil, fmt.Errorf("the args must be a JSON object: %w", err)
+	}
+	return args, nil
+}
@@ -0,0 +1,25 @@
+/******************************************************************************/
+/* html_localization_test.go                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import "testing"
+
+func TestParseL10nArgs(t *testing.T) {
+	args, err := parseL10nArgs(`{"n": 3, "name": "Kai"}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if args["n"] != float64(3) || args["name"] != "Kai" {
+		t.Fatalf("args = %v", args)
+	}
+	if args, err := parseL10nArgs(""); err != nil || args != nil {
+		t.Fatalf("no args = %v, %v", args, err)
+	}
+	if _, err := parseL10nArgs(`[1, 2]`); err == nil {
+		t.Fatal("args that aren't an object should be an error")
+	}
+}
@@ -0,0 +1,70 @@
+/******************************************************************************/
+/* html_media_settings.go                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"sync"
+
+	"kaijuengine.com/engine/systems/events"
+)
+
+// MediaSettings are the user preferences that @media queries can test which
+// can't be read from the window. They are typically driven by the game's
+// options menu.
+type MediaSettings struct {
+	// UIScale is tested with the custom ui-scale media feature, for example
+	// @media (min-ui-scale: 1.5) { ... }
+	UIScale float32
+	// PrefersReducedMotion is tested with (prefers-reduced-motion: reduce)
+	PrefersReducedMotion bool
+}
+
+var mediaSettings = struct {
+	settings MediaSettings
+	changed  events.Event
+	mutex    sync.Mutex
+}{
+	settings: MediaSettings{UIScale: 1},
+}
+
+// CurrentMediaSettings returns the settings that @media queries are currently
+// evaluated against
+func CurrentMediaSettings() MediaSettings {
+	mediaSettings.mutex.Lock()
+	defer mediaSettings.mutex.Unlock()
+	return mediaSettings.settings
+}
+
+// SetMediaSettings changes the settings that @media queries are evaluated
+// against. Every document that has its style setup will re-apply its styles
+// (on the main thread) so that the matching media rules take effect.
+func SetMediaSettings(settings MediaSettings) {
+	if settings.UIScale <= 0 {
+		settings.UIScale = 1
+	}
+	mediaSettings.mutex.Lock()
+	defer mediaSettings.mutex.Unlock()
+	if mediaSettings.settings == settings {
+		return
+	}
+	mediaSettings.settings = settings
+	// Documents only queue their style update onto the main thread here, so
+	// it is safe to hold the lock while the listeners run
+	mediaSettings.changed.Execute()
+}
+
+func onMediaSettingsChanged(call func()) events.Id {
+	mediaSettings.mutex.Lock()
+	defer mediaSettings.mutex.Unlock()
+	return mediaSettings.changed.Add(call)
+}
+
+func removeMediaSettingsChanged(id events.Id) {
+	mediaSettings.mutex.Lock()
+	defer mediaSettings.mutex.Unlock()
+	mediaSettings.changed.Remove(id)
+}
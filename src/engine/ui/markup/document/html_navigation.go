@@ -0,0 +1,111 @@
+/******************************************************************************/
+/* html_navigation.go                                                         */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package document
+
+import (
+	"strconv"
+	"strings"
+
+	"kaijuengine.com/engine/ui"
+	"kaijuengine.com/engine/ui/markup/css/rules"
+)
+
+const tabIndexAttribute = "tabindex"
+
+// navTargetAttributes are the explicit focus navigation overrides, the value
+// is the id of the element to move to, such as nav-down="#play"
+var navTargetAttributes = [...]struct {
+	attr string
+	dir  ui.NavDirection
+}{
+	{"nav-up", ui.NavDirectionUp},
+	{"nav-down", ui.NavDirectionDown},
+	{"nav-left", ui.NavDirectionLeft},
+	{"nav-right", ui.NavDirectionRight},
+	{"nav-next", ui.NavDirectionNext},
+	{"nav-prev", ui.NavDirectionPrevious},
+}
+
+// isNavigationFocusable returns true for the elements that can be focused by
+// default, the UI types of inputs, selects and text areas already are
+func (e *Element) isNavigationFocusable() bool {
+	return e.IsButton() || (e.Data == "a" && e.HasAttribute("href"))
+}
+
+func setupElementFocus(elm *Element) {
+	if elm.UIPanel == nil {
+		return
+	}
+	if attr := strings.TrimSpace(elm.Attribute(tabIndexAttribute)); attr != "" {
+		if i, err := strconv.Atoi(attr); err == nil {
+			elm.UIPanel.SetTabIndex(i)
+			return
+		}
+	}
+	if elm.isNavigationFocusable() {
+		elm.UIPanel.SetFocusable(true)
+	}
+}
+
+// linkNavigationTargets resolves the nav-* attributes of all the elements,
+// it is done after the elements are created so targets can be anywhere in the
+// document
+func (d *Document) linkNavigationTargets() {
+	for _, elm := range d.Elements {
+		if elm.UIPanel == nil || elm.IsText() {
+			continue
+		}
+		for _, nav := range navTargetAttributes {
+			id := strings.TrimPrefix(strings.TrimSpace(elm.Attribute(nav.attr)), "#")
+			if id == "" {
+				continue
+			}
+			if target, ok := d.readId(id); ok && target.UI != nil {
+				elm.UIPanel.SetNavigationTarget(nav.dir, target.UI)
+			}
+		}
+	}
+}
+
+// Focus moves the focus navigation to the given element, this is typically
+// used to pick the element that is focused when a menu is opened
+func (d *Document) Focus(elm *Element) {
+	if d.uiMan == nil || elm == nil || elm.UI == nil {
+		return
+	}
+	d.uiMan.Navigation.Focus(elm.UI)
+}
+
+func (d *Document) elementForUI(target *ui.UI) *Element {
+	if target == nil {
+		return nil
+	}
+	for _, elm := range d.Elements {
+		if elm.UI == target {
+			return elm
+		}
+	}
+	return nil
+}
+
+// focusChanged updates the :focus, :focus-visible and :focus-within states of
+// the elements that lost and gained focus
+func (d *Document) focusChanged(change ui.FocusChange) {
+	if prev := d.elementForUI(change.Previous); prev != nil {
+		prev.Stylizer.setState(rules.RuleInvokeFocus|rules.RuleInvokeFocusVisible, false)
+		for e := prev; e != nil; e = e.Parent.Value() {
+			e.Stylizer.setState(rules.RuleInvokeFocusWithin, false)
+		}
+	}
+	if current := d.elementForUI(change.Current); current != nil {
+		current.Stylizer.setState(rules.RuleInvokeFocus, true)
+		current.Stylizer.setState(rules.RuleInvokeFocusVisible, change.Visible)
+		for e := current; e != nil; e = e.Parent.Value() {
+			e.Stylizer.setState(rules.RuleInvokeFocusWithin, true)
+		}
+	}
+}
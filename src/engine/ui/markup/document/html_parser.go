@@ -109,6 +109,9 @@ type Document struct {
 	TopElements       []*Element
 	HeadElements      []*Element
 	onWindowResizeId  events.Id
+	onMediaChangeId   events.Id
+	onLanguageId      events.Id
+	onFocusChangeId   events.Id
 	groups            map[string][]*Element
 	ids               map[string]*Element
 	idsMutex          sync.RWMutex
@@ -119,6 +122,8 @@ type Document struct {
 	firstFocusElement *ui.UI
 	lastFocusElement  *ui.UI
 	funcMap           map[string]func(*Element)
+	customProperties  map[string]string
+	binding           *DataBinding
 	//Debug      struct {
 	//	ReloadEventId events.Id
 	//}
@@ -128,25 +133,50 @@ func (d *Document) SetupStyle(style rules.StyleSheet, host *engine.Host, stylize
 	d.style = style
 	d.stylizer = stylizer
 	d.host = weak.Make(host)
+	d.Localize()
 	d.stylizer.ApplyStyles(d.style, d)
 	wd := weak.Make(d)
-	d.onWindowResizeId = host.Window.OnResize.Add(func() {
+	reapply := func() {
 		sd := wd.Value()
-		h := sd.host.Value()
-		if sd != nil && h != nil {
+		if sd == nil {
+			return
+		}
+		if h := sd.host.Value(); h != nil {
 			h.RunOnMainThread(sd.ApplyStyles)
 		}
-	})
+	}
+	d.onWindowResizeId = host.Window.OnResize.Add(reapply)
+	d.onMediaChangeId = onMediaSettingsChanged(reapply)
+	if host.Strings != nil {
+		d.onLanguageId = host.Strings.OnLanguageChanged.Add(func() {
+			sd := wd.Value()
+			if sd == nil {
+				return
+			}
+			if h := sd.host.Value(); h != nil {
+				h.RunOnMainThread(func() {
+					sd.Localize()
+					sd.ApplyStyles()
+				})
+			}
+		})
+	}
 	type documentCleanup struct {
-		host weak.Pointer[engine.Host]
-		eid  events.Id
+		host        weak.Pointer[engine.Host]
+		eid         events.Id
+		mediaEid    events.Id
+		languageEid events.Id
 	}
 	runtime.AddCleanup(d, func(dc documentCleanup) {
 		h := dc.host.Value()
 		if h != nil && h.Window != nil {
 			h.Window.OnResize.Remove(dc.eid)
 		}
-	}, documentCleanup{d.host, d.onWindowResizeId})
+		if h != nil && h.Strings != nil {
+			h.Strings.OnLanguageChanged.Remove(dc.languageEid)
+		}
+		removeMediaSettingsChanged(dc.mediaEid)
+	}, documentCleanup{d.host, d.onWindowResizeId, d.onMediaChangeId, d.onLanguageId})
 }
 
 func (h *Document) GetElementById(id string) (*Element, bool) {
@@ -415,6 +445,7 @@ func (d *Document) createUIElement(uiMan *ui.Manager, e *Element, parent *ui.Pan
 		}
 		entry := appendElement(panel.Base(), panel)
 		syncElementDisabledState(entry)
+		setupElementFocus(entry)
 		if !e.IsTextArea() {
 			for i := range e.Children {
 				d.createUIElement(uiMan, e.Children[i], panel)
@@ -569,6 +600,13 @@ func DocumentFromHTMLString(uiMan *ui.Manager, htmlStr string, withData any, fun
 	for i := range parsed.Elements {
 		setupEvents(parsed.Elements[i], parsed.funcMap)
 	}
+	parsed.linkNavigationTargets()
+	wd := weak.Make(parsed)
+	parsed.onFocusChangeId = uiMan.Navigation.OnFocusChanged.Add(func(change ui.FocusChange) {
+		if d := wd.Value(); d != nil {
+			d.focusChanged(change)
+		}
+	})
 	for _, elm := range h.Children[len(h.Children)-1].Children {
 		if elm.Data == "head" {
 			for _, child := range elm.Children {
@@ -600,6 +638,9 @@ func (d *Document) Deactivate() {
 }
 
 func (d *Document) Destroy() {
+	if d.binding != nil {
+		d.binding.Unbind()
+	}
 	for _, e := range d.TopElements {
 		if e.Parent.Value() != nil {
 			for i, c := range e.Parent.Value().Children {
@@ -616,6 +657,12 @@ func (d *Document) Destroy() {
 		for _, e := range d.Elements {
 			host.DestroyEntity(e.UI.Entity())
 		}
+		if host.Strings != nil {
+			host.Strings.OnLanguageChanged.Remove(d.onLanguageId)
+		}
+	}
+	if d.uiMan != nil {
+		d.uiMan.Navigation.OnFocusChanged.Remove(d.onFocusChangeId)
 	}
 	clear(d.funcMap)
 	*d = Document{}
@@ -1082,6 +1129,7 @@ func (d *Document) appendElement(elm *Element) {
 	}
 	addChildren(elm)
 	d.reloadElementCaches()
+	d.linkNavigationTargets()
 }
 
 func (d *Document) isElementInDocument(elm *Element) bool {
@@ -1142,6 +1190,11 @@ func (d *Document) InsertElementAfter(elm *Element, after *Element) {
 }
 
 func (d *Document) insertElementAt(elm *Element, parent *Element, index int) {
+	d.insertElementAtWithoutApply(elm, parent, index)
+	d.stylizer.ApplyStyles(d.style, d)
+}
+
+func (d *Document) insertElementAtWithoutApply(elm *Element, parent *Element, index int) {
 	fromParent := elm.Parent.Value()
 	if fromParent != nil {
 		idx := fromParent.IndexOfChild(elm)
@@ -1156,7 +1209,6 @@ func (d *Document) insertElementAt(elm *Element, parent *Element, index int) {
 	if !d.isElementInDocument(elm) {
 		d.appendElement(elm)
 	}
-	d.stylizer.ApplyStyles(d.style, d)
 }
 
 func (d *Document) setId(id string, elm *Element) {
@@ -0,0 +1,128 @@
+/******************************************************************************/
+/* html_golden_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package markup
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"kaijuengine.com/engine"
+	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/engine/ui"
+	_ "kaijuengine.com/engine/ui/markup/css/properties" // Run init functions
+	"kaijuengine.com/rendering"
+)
+
+const (
+	goldenWidth  = 160
+	goldenHeight = 96
+	// goldenNineSliceTexture is a texture with an 8 pixel red frame around a
+	// blue center, the frame should keep its width when the panel is stretched
+	goldenNineSliceTexture = "golden_nine_slice.png"
+)
+
+var goldenTolerance = rendering.GoldenTolerance{Channel: 2, Pixels: 0.002}
+
+// goldenAssets flattens the built in renderer content, textures and fonts the
+// same way the embedded database does, along with the nine slice texture
+func goldenAssets(t *testing.T) assets.Database {
+	t.Helper()
+	files := map[string][]byte{}
+	root := filepath.FromSlash("../../../editor/editor_embedded_content/editor_content")
+	for _, dir := range []string{"renderer", "textures", "fonts"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			files[filepath.Base(path)] = data
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	img := image.NewRGBA(image.Rect(0, 0, 24, 24))
+	for y := range 24 {
+		for x := range 24 {
+			c := color.RGBA{0, 0, 255, 255}
+			if x < 8 || y < 8 || x >= 16 || y >= 16 {
+				c = color.RGBA{255, 0, 0, 255}
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	files[goldenNineSliceTexture] = buf.Bytes()
+	return assets.NewMockDB(files)
+}
+
+// renderGolden lays out the document on a headless host and draws it with the
+// software rasterizer, then compares it against testdata/software/[name].png
+func renderGolden(t *testing.T, name, html string) {
+	t.Helper()
+	host := engine.NewHost("golden", nil, goldenAssets(t))
+	host.SetGPUBackend(rendering.GPUBackendNull)
+	if err := host.Initialize(goldenWidth, goldenHeight, 0, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := host.InitializeRenderer(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(host.Teardown)
+	host.EnableSoftwareRasterizer()
+	uiMan := ui.Manager{}
+	uiMan.Init(host)
+	DocumentFromHTMLString(&uiMan, html, "", nil, nil, nil)
+	// The layout settles over a few frames, the same as the integration tests
+	for range 4 {
+		host.Update(1.0 / 60.0)
+		host.Render()
+	}
+	img := host.RasterizedFrame()
+	if img == nil {
+		t.Fatal("the software rasterizer did not draw a frame")
+	}
+	path := filepath.Join("testdata", "software", name+".png")
+	if _, err := rendering.CompareGolden(path, img, goldenTolerance); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoldenBorderRadius(t *testing.T) {
+	renderGolden(t, "border_radius", `<html><body style="background-color: white">
+		<div style="position: absolute; left: 8px; top: 8px; width: 64px; height: 64px;
+			background-color: #3070e0; border-radius: 16px"></div>
+		<div style="position: absolute; left: 88px; top: 8px; width: 64px; height: 64px;
+			background-color: #e0a030; border: 4px solid #202020;
+			border-radius: 0px 24px 0px 24px"></div>
+	</body></html>`)
+}
+
+func TestGoldenText(t *testing.T) {
+	renderGolden(t, "text", `<html><body style="background-color: white">
+		<div style="position: absolute; left: 8px; top: 8px; color: black; font-size: 20px">AVATAR</div>
+		<div style="position: absolute; left: 8px; top: 48px; color: #c02020; font-size: 14px">Kaiju text</div>
+	</body></html>`)
+}
+
+func TestGoldenNineSlice(t *testing.T) {
+	renderGolden(t, "nine_slice", `<html><body style="background-color: white">
+		<div style="position: absolute; left: 8px; top: 8px; width: 144px; height: 48px;
+			background-image: url(&quot;`+goldenNineSliceTexture+`&quot;);
+			border-image-slice: 8px"></div>
+	</body></html>`)
+}
@@ -134,10 +134,20 @@ type panelData struct {
 	fitContent                ContentFit
 	gridColumns               int
 	gridGap                   matrix.Vec2
-	// Positive values are fixed pixel widths, negative values are fr units.
+	// Positive values are fixed pixel widths, negative values are fr units
+	// and 0 sizes the track to fit its content.
 	gridTemplateColumns []float32
+	// Same encoding as gridTemplateColumns
+	gridTemplateRows    []float32
+	gridColumnLineNames [][]string
+	gridRowLineNames    [][]string
+	gridTemplateAreas   [][]string
 	gridAutoColumns     float32
 	gridAutoRows        float32
+	gridAutoFlow        GridAutoFlow
+	gridAutoFlowDense   bool
+	gridAlignItems      FlexAlign
+	gridJustifyItems    FlexAlign
 	requestScrollX      requestScroll
 	requestScrollY      requestScroll
 	overflow            Overflow
@@ -153,6 +163,8 @@ type panelData struct {
 	maxSize             matrix.Vec2
 	aspectRatio         float32
 	usesBorderBox       bool
+	effects             *panelEffects
+	navigation          *panelNavigation
 }
 
 func (b panelBits) isScrolling() bool        { return b&panelBitsIsScrolling != 0 }
@@ -203,8 +215,16 @@ func (panel *Panel) Init(texture *rendering.Texture, elmType ElementType) {
 	pd.gridColumns = 0
 	pd.gridGap = matrix.Vec2Zero()
 	pd.gridTemplateColumns = nil
+	pd.gridTemplateRows = nil
+	pd.gridColumnLineNames = nil
+	pd.gridRowLineNames = nil
+	pd.gridTemplateAreas = nil
 	pd.gridAutoColumns = 0
 	pd.gridAutoRows = 0
+	pd.gridAutoFlow = GridAutoFlowRow
+	pd.gridAutoFlowDense = false
+	pd.gridAlignItems = FlexAlignAuto
+	pd.gridJustifyItems = FlexAlignAuto
 	pd.layoutMode = LayoutModeFlow
 	pd.flexDirection = FlexDirectionRow
 	pd.flexWrap = FlexWrapNoWrap
@@ -212,6 +232,7 @@ func (panel *Panel) Init(texture *rendering.Texture, elmType ElementType) {
 	pd.flexAlignItems = FlexAlignStretch
 	pd.flexAlignContent = FlexAlignContentStretch
 	pd.enforcedColorStack = make([]matrix.Color, 0)
+	pd.navigation = nil
 	panel.postLayoutUpdate = panel.panelPostLayoutUpdate
 	panel.render = panel.panelRender
 	ts := matrix.Vec2Zero()
@@ -227,9 +248,14 @@ func (panel *Panel) Init(texture *rendering.Texture, elmType ElementType) {
 	}
 	panel.entity.OnActivate.Add(func() {
 		panel.shaderData.Activate()
+		pd.effects.setLayersActive(true)
 		base.SetDirty(DirtyTypeLayout)
 	})
-	panel.entity.OnDeactivate.Add(func() { panel.shaderData.Deactivate() })
+	panel.entity.OnDeactivate.Add(func() {
+		panel.shaderData.Deactivate()
+		pd.effects.setLayersActive(false)
+	})
+	panel.entity.OnDestroy.Add(func() { pd.effects.destroyLayers() })
 }
 
 func (p *Panel) MaxScroll() matrix.Vec2  { return p.PanelData().maxScroll }
@@ -490,14 +516,6 @@ func (rb rowBuilder) Width() float32 {
 	return rb.x
 }
 
-type gridLayoutItem struct {
-	ui      *UI
-	row     int
-	col     int
-	rowSpan int
-	colSpan int
-}
-
 func (rb rowBuilder) Height() float32 {
 	return rb.height + rb.maxMarginTop + rb.maxMarginBottom
 }
@@ -541,8 +559,10 @@ func (p *Panel) updateShaderVisibility() {
 	// If the panel is completely outside the scissor, deactivate its shader data.
 	if right < scissor.X() || left > scissor.Z() || top < scissor.Y() || bottom > scissor.W() {
 		p.shaderData.Deactivate()
+		p.PanelData().effects.setLayersActive(false)
 	} else {
 		p.shaderData.Activate()
+		p.PanelData().effects.setLayersActive(true)
 	}
 }
 
@@ -578,6 +598,7 @@ func (p *Panel) panelPostLayoutUpdate() {
 	}
 	if p.PanelData().drawing.IsValid() {
 		p.shaderData.setSize2d(p.Base())
+		p.packEffects()
 	}
 	if len(p.entity.Children) == 0 {
 		return
@@ -597,7 +618,7 @@ func (p *Panel) panelPostLayoutUpdate() {
 	ps := p.layout.PixelSize()
 	maxSize := matrix.Vec2{}
 	maxRowsX := matrix.Float(0)
-	if p.IsGrid() && pd.gridColumns > 0 {
+	if p.IsGrid() {
 		maxSize = p.layoutGridChildren(pd, offsetStart, ps)
 		maxRowsX = maxSize.X()
 	} else if p.IsFlex() {
@@ -753,6 +774,7 @@ func (p *Panel) panelRender() {
 	p.events[EventTypeRender].Execute()
 	if pd.drawing.IsValid() {
 		p.shaderData.setSize2d(p.Base())
+		p.packEffects()
 	}
 	pd.requestScrollX.requested = false
 	pd.requestScrollY.requested = false
@@ -1065,8 +1087,16 @@ func (p *Panel) ClearLayoutStyles() {
 	pd.gridColumns = 0
 	pd.gridGap = matrix.Vec2Zero()
 	pd.gridTemplateColumns = nil
+	pd.gridTemplateRows = nil
+	pd.gridColumnLineNames = nil
+	pd.gridRowLineNames = nil
+	pd.gridTemplateAreas = nil
 	pd.gridAutoColumns = 0
 	pd.gridAutoRows = 0
+	pd.gridAutoFlow = GridAutoFlowRow
+	pd.gridAutoFlowDense = false
+	pd.gridAlignItems = FlexAlignAuto
+	pd.gridJustifyItems = FlexAlignAuto
 	pd.flexDirection = FlexDirectionRow
 	pd.flexWrap = FlexWrapNoWrap
 	pd.flexJustify = FlexJustifyStart
@@ -1087,7 +1117,14 @@ func (p *Panel) IsGrid() bool {
 
 func (p *Panel) IsFlex() bool { return p.PanelData().layoutMode == LayoutModeFlex }
 
-func (p *Panel) GridColumns() int { return p.PanelData().gridColumns }
+// GridColumns returns the number of columns in the explicit grid
+func (p *Panel) GridColumns() int {
+	pd := p.PanelData()
+	if pd.layoutMode != LayoutModeGrid {
+		return pd.gridColumns
+	}
+	return pd.explicitGridColumns()
+}
 
 func (p *Panel) GridGap() matrix.Vec2 { return p.PanelData().gridGap }
 
@@ -1096,26 +1133,18 @@ func (p *Panel) GridGap() matrix.Vec2 { return p.PanelData().gridGap }
 // children (e.g. div{width:100%}) fit their grid cell instead of full parent.
 func (p *Panel) GridCellWidth() float32 {
 	pd := p.PanelData()
-	if !p.IsGrid() || pd.gridColumns <= 0 {
+	if !p.IsGrid() {
 		return p.layout.PixelSize().X()
 	}
 	ps := p.layout.PixelSize()
 	innerW := ps.X() - p.layout.padding.Horizontal() - p.layout.border.Horizontal()
-	gapX := pd.gridGap.X()
-	if gapX < 0 {
-		gapX = 0
-	}
-	colW := (innerW - float32(pd.gridColumns-1)*gapX) / float32(pd.gridColumns)
-	if len(pd.gridTemplateColumns) == pd.gridColumns {
-		widths := p.computeGridColumnWidths(innerW, gapX)
-		if len(widths) > 0 {
-			sum := float32(0)
-			for i := range widths {
-				sum += widths[i]
-			}
-			colW = sum / float32(len(widths))
-		}
+	columns, _ := pd.gridAxes()
+	widths := sizeGridTracks(columns, len(columns.tracks), innerW, max(0, pd.gridGap.X()), true, nil)
+	colW := float32(0)
+	for i := range widths {
+		colW += widths[i]
 	}
+	colW /= float32(max(1, len(widths)))
 	if colW < 1 {
 		colW = 1
 	}
@@ -1126,18 +1155,22 @@ func (p *Panel) GridCellWidth() float32 {
 // Children will be placed in row-major order into the grid cells.
 // Column widths are computed by dividing available width by columns (accounting for gaps).
 // Use SetGridGap to control spacing between items. This works with the existing
-// fit content and scrolling systems.
+// fit content and scrolling systems. A column count of 0 (display: grid) takes
+// the columns from the template columns or areas, or 3 if there are none.
 func (p *Panel) SetGrid(columns int) {
 	pd := p.PanelData()
 	if columns <= 0 {
-		columns = 3 // default for display: grid or auto
+		if pd.layoutMode == LayoutModeGrid {
+			return
+		}
+		columns = 0
 	}
 	if pd.layoutMode == LayoutModeGrid && pd.gridColumns == columns {
 		return
 	}
 	pd.layoutMode = LayoutModeGrid
 	pd.gridColumns = columns
-	if len(pd.gridTemplateColumns) != columns {
+	if columns > 0 && len(pd.gridTemplateColumns) != columns {
 		pd.gridTemplateColumns = nil
 	}
 	if pd.gridGap.X() == 0 && pd.gridGap.Y() == 0 {
@@ -1147,7 +1180,8 @@ func (p *Panel) SetGrid(columns int) {
 }
 
 // SetGridTemplateColumns configures explicit grid column widths.
-// Positive values are fixed pixels, negative values are fr units.
+// Positive values are fixed pixels, negative values are fr units and 0 sizes
+// the column to fit its content.
 func (p *Panel) SetGridTemplateColumns(columns []float32) {
 	pd := p.PanelData()
 	if len(columns) == 0 {
@@ -1171,6 +1205,88 @@ func (p *Panel) SetGridTemplateColumns(columns []float32) {
 	p.Base().SetDirty(DirtyTypeLayout)
 }
 
+// SetGridTemplateRows configures explicit grid row heights using the same
+// values as SetGridTemplateColumns. Rows past the template are implicit and
+// are sized by SetGridAutoRows.
+func (p *Panel) SetGridTemplateRows(rows []float32) {
+	pd := p.PanelData()
+	if slices.Equal(pd.gridTemplateRows, rows) {
+		return
+	}
+	pd.gridTemplateRows = slices.Clone(rows)
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// SetGridColumnLineNames names the column lines of the explicit grid, the
+// first entry holds the names of line 1. Grid items can then be placed
+// against these names with Layout.SetGridColumnLines.
+func (p *Panel) SetGridColumnLineNames(names [][]string) {
+	pd := p.PanelData()
+	if slices.EqualFunc(pd.gridColumnLineNames, names, slices.Equal) {
+		return
+	}
+	pd.gridColumnLineNames = names
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// SetGridRowLineNames names the row lines of the explicit grid, the first
+// entry holds the names of line 1
+func (p *Panel) SetGridRowLineNames(names [][]string) {
+	pd := p.PanelData()
+	if slices.EqualFunc(pd.gridRowLineNames, names, slices.Equal) {
+		return
+	}
+	pd.gridRowLineNames = names
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// SetGridTemplateAreas names the cells of the explicit grid by row, empty
+// names are unnamed cells. Every row must have the same number of columns
+// and each named area is expected to be a rectangle. The lines around an
+// area are implicitly named <name>-start and <name>-end.
+func (p *Panel) SetGridTemplateAreas(areas [][]string) {
+	pd := p.PanelData()
+	if slices.EqualFunc(pd.gridTemplateAreas, areas, slices.Equal) {
+		return
+	}
+	pd.gridTemplateAreas = areas
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// SetGridAutoFlow controls how items without a definite placement fill the
+// grid. A dense flow back fills holes left by earlier items.
+func (p *Panel) SetGridAutoFlow(flow GridAutoFlow, dense bool) {
+	pd := p.PanelData()
+	if pd.gridAutoFlow == flow && pd.gridAutoFlowDense == dense {
+		return
+	}
+	pd.gridAutoFlow = flow
+	pd.gridAutoFlowDense = dense
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// SetGridAlignItems sets the default vertical alignment of items within
+// their grid area, items can override it with Layout.SetAlignSelf
+func (p *Panel) SetGridAlignItems(align FlexAlign) {
+	pd := p.PanelData()
+	if pd.gridAlignItems == align {
+		return
+	}
+	pd.gridAlignItems = align
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
+// SetGridJustifyItems sets the default horizontal alignment of items within
+// their grid area, items can override it with Layout.SetJustifySelf
+func (p *Panel) SetGridJustifyItems(align FlexAlign) {
+	pd := p.PanelData()
+	if pd.gridJustifyItems == align {
+		return
+	}
+	pd.gridJustifyItems = align
+	p.Base().SetDirty(DirtyTypeLayout)
+}
+
 func (p *Panel) SetGridAutoColumns(width float32) {
 	if width < 0 {
 		width = 0
@@ -1665,159 +1781,6 @@ func (p *Panel) layoutFlexChildren(pd *panelData, offsetStart matrix.Vec2, ps ma
 		maxMainUsed+innerTop+p.layout.padding.Bottom()+p.layout.border.Bottom())
 }
 
-func (p *Panel) computeGridColumnWidths(innerWidth, gapX float32, columns ...int) []float32 {
-	pd := p.PanelData()
-	cols := pd.gridColumns
-	if len(columns) > 0 && columns[0] > cols {
-		cols = columns[0]
-	}
-	if cols <= 0 {
-		return []float32{}
-	}
-	out := make([]float32, cols)
-	explicitCols := pd.gridColumns
-	if explicitCols <= 0 {
-		explicitCols = cols
-	}
-	if len(pd.gridTemplateColumns) != explicitCols {
-		colW := (innerWidth - float32(explicitCols-1)*gapX) / float32(explicitCols)
-		if colW < 1 {
-			colW = 1
-		}
-		for i := 0; i < cols; i++ {
-			if i < explicitCols || pd.gridAutoColumns <= 0 {
-				out[i] = colW
-			} else {
-				out[i] = pd.gridAutoColumns
-			}
-		}
-		return out
-	}
-	totalFixed := float32(0)
-	totalFr := float32(0)
-	for i := 0; i < explicitCols; i++ {
-		v := pd.gridTemplateColumns[i]
-		if v >= 0 {
-			totalFixed += v
-		} else {
-			totalFr += -v
-		}
-	}
-	remaining := innerWidth - totalFixed - float32(cols-1)*gapX
-	if remaining < 0 {
-		remaining = 0
-	}
-	for i := 0; i < cols; i++ {
-		if i >= explicitCols {
-			if pd.gridAutoColumns > 0 {
-				out[i] = pd.gridAutoColumns
-			} else {
-				out[i] = innerWidth / float32(explicitCols)
-			}
-			if out[i] < 1 {
-				out[i] = 1
-			}
-			continue
-		}
-		v := pd.gridTemplateColumns[i]
-		if v >= 0 {
-			out[i] = v
-		} else if totalFr > 0 {
-			out[i] = remaining * ((-v) / totalFr)
-		}
-		if out[i] < 1 {
-			out[i] = 1
-		}
-	}
-	return out
-}
-
-func gridTrackSpan(start, end int) int {
-	if start > 0 && end > start {
-		return end - start
-	}
-	return 1
-}
-
-func isGridAreaFree(occupied map[int]map[int]bool, columns, row, col, rowSpan, colSpan int) bool {
-	if col < 0 || col+colSpan > columns {
-		return false
-	}
-	for y := 0; y < rowSpan; y++ {
-		for x := 0; x < colSpan; x++ {
-			if occupied[row+y][col+x] {
-				return false
-			}
-		}
-	}
-	return true
-}
-
-func occupyGridArea(occupied map[int]map[int]bool, row, col, rowSpan, colSpan int) {
-	for y := 0; y < rowSpan; y++ {
-		r := row + y
-		if _, ok := occupied[r]; !ok {
-			occupied[r] = map[int]bool{}
-		}
-		for x := 0; x < colSpan; x++ {
-			occupied[r][col+x] = true
-		}
-	}
-}
-
-func nextGridCell(occupied map[int]map[int]bool, columns, row, col, rowSpan, colSpan int) (int, int) {
-	for {
-		if col >= columns || col+colSpan > columns {
-			row++
-			col = 0
-		}
-		if _, ok := occupied[row]; !ok {
-			occupied[row] = map[int]bool{}
-		}
-		if isGridAreaFree(occupied, columns, row, col, rowSpan, colSpan) {
-			return row, col
-		}
-		col++
-	}
-}
-
-func nextGridCellInColumn(occupied map[int]map[int]bool, columns, row, col, rowSpan, colSpan int) (int, int) {
-	if col+colSpan > columns {
-		return nextGridCell(occupied, columns, row, 0, rowSpan, colSpan)
-	}
-	for {
-		if _, ok := occupied[row]; !ok {
-			occupied[row] = map[int]bool{}
-		}
-		if isGridAreaFree(occupied, columns, row, col, rowSpan, colSpan) {
-			return row, col
-		}
-		row++
-	}
-}
-
-func firstGridCellInRow(occupied map[int]map[int]bool, columns, row, rowSpan, colSpan int) (int, int) {
-	for col := 0; col < columns; col++ {
-		if isGridAreaFree(occupied, columns, row, col, rowSpan, colSpan) {
-			return row, col
-		}
-	}
-	return nextGridCell(occupied, columns, row+1, 0, rowSpan, colSpan)
-}
-
-func requestedGridCell(occupied map[int]map[int]bool, columns, cursorRow, cursorCol, rowStart, colStart, rowSpan, colSpan int) (int, int) {
-	if rowStart > 0 && colStart > 0 {
-		return nextGridCellInColumn(occupied, columns, rowStart-1, colStart-1, rowSpan, colSpan)
-	}
-	if rowStart > 0 {
-		return firstGridCellInRow(occupied, columns, rowStart-1, rowSpan, colSpan)
-	}
-	if colStart > 0 {
-		return nextGridCellInColumn(occupied, columns, cursorRow, colStart-1, rowSpan, colSpan)
-	}
-	return nextGridCell(occupied, columns, cursorRow, cursorCol, rowSpan, colSpan)
-}
-
 func (p *Panel) layoutGridChildren(pd *panelData, offsetStart matrix.Vec2, ps matrix.Vec2) matrix.Vec2 {
 	defer tracing.NewRegion("Panel.layoutGridChildren").End()
 	innerLeft := p.layout.padding.Left() + p.layout.border.Left()
@@ -1828,39 +1791,11 @@ func (p *Panel) layoutGridChildren(pd *panelData, offsetStart matrix.Vec2, ps ma
 	if innerWidth < 1 {
 		innerWidth = 100
 	}
-	gapX := pd.gridGap.X()
-	if gapX < 0 {
-		gapX = 0
-	}
-	gapY := pd.gridGap.Y()
-	if gapY < 0 {
-		gapY = 0
-	}
-	effectiveColumns := pd.gridColumns
-	for _, kid := range p.entity.Children {
-		if !kid.IsActive() || kid.IsDestroyed() {
-			continue
-		}
-		kui := FirstOnEntity(kid)
-		if kui == nil {
-			continue
-		}
-		kLayout := kui.Layout()
-		switch kLayout.Positioning() {
-		case PositioningAbsolute, PositioningFixed, PositioningSticky:
-			continue
-		}
-		if start := kLayout.GridColumnStart(); start > 0 {
-			span := gridTrackSpan(start, kLayout.GridColumnEnd())
-			effectiveColumns = max(effectiveColumns, start+span-1)
-		}
-	}
-	colWidths := p.computeGridColumnWidths(innerWidth, gapX, effectiveColumns)
+	innerHeight := ps.Y() - p.layout.padding.Vertical() - p.layout.border.Vertical()
+	gapX := max(0, pd.gridGap.X())
+	gapY := max(0, pd.gridGap.Y())
+	columns, rows := pd.gridAxes()
 	items := make([]gridLayoutItem, 0, len(p.entity.Children))
-	occupied := map[int]map[int]bool{}
-	cursorRow := 0
-	cursorCol := 0
-	rowCount := 0
 	for _, kid := range p.entity.Children {
 		if !kid.IsActive() || kid.IsDestroyed() {
 			continue
@@ -1875,67 +1810,74 @@ func (p *Panel) layoutGridChildren(pd *panelData, offsetStart matrix.Vec2, ps ma
 		case PositioningAbsolute, PositioningFixed, PositioningSticky:
 			continue
 		}
-		rowSpan := gridTrackSpan(kLayout.GridRowStart(), kLayout.GridRowEnd())
-		colSpan := gridTrackSpan(kLayout.GridColumnStart(), kLayout.GridColumnEnd())
-		if colSpan > effectiveColumns {
-			colSpan = effectiveColumns
-		}
-		row, col := requestedGridCell(occupied, effectiveColumns, cursorRow, cursorCol,
-			kLayout.GridRowStart(), kLayout.GridColumnStart(), rowSpan, colSpan)
-		if kLayout.GridRowStart() == 0 && kLayout.GridColumnStart() == 0 {
-			cursorRow, cursorCol = row, col+colSpan
-		}
-		occupyGridArea(occupied, row, col, rowSpan, colSpan)
-		rowCount = max(rowCount, row+rowSpan)
-		items = append(items, gridLayoutItem{
-			ui:      kui,
-			row:     row,
-			col:     col,
-			rowSpan: rowSpan,
-			colSpan: colSpan,
-		})
+		item := gridLayoutItem{ui: kui}
+		item.row, item.rowSpan, item.rowDefinite = rows.resolve(kLayout.GridRowStart(), kLayout.GridRowEnd())
+		item.col, item.colSpan, item.colDefinite = columns.resolve(kLayout.GridColumnStart(), kLayout.GridColumnEnd())
+		items = append(items, item)
 	}
+	// Items are placed in order-modified document order
+	slices.SortStableFunc(items, func(a, b gridLayoutItem) int {
+		return a.ui.Layout().FlexOrder() - b.ui.Layout().FlexOrder()
+	})
+	var rowCount, colCount int
+	if pd.gridAutoFlow == GridAutoFlowColumn {
+		transposeGridItems(items)
+		colCount, rowCount = placeGridItems(items, max(1, len(rows.tracks)), pd.gridAutoFlowDense)
+		transposeGridItems(items)
+	} else {
+		rowCount, colCount = placeGridItems(items, len(columns.tracks), pd.gridAutoFlowDense)
+	}
+	rowCount = max(rowCount, len(rows.tracks))
+	colCount = max(colCount, len(columns.tracks))
 	if rowCount == 0 {
 		return matrix.Vec2{innerWidth, innerTop + p.layout.padding.Bottom() + p.layout.border.Bottom()}
 	}
-	rowHeights := make([]float32, rowCount)
-	if pd.gridAutoRows > 0 {
-		for i := range rowHeights {
-			rowHeights[i] = pd.gridAutoRows
-		}
-	}
+	colItems := make([]gridTrackItem, len(items))
+	rowItems := make([]gridTrackItem, len(items))
 	for i := range items {
-		kLayout := items[i].ui.Layout()
-		kSize := kLayout.PixelSize()
-		margin := kLayout.Margin()
-		rowHeights[items[i].row] = max(rowHeights[items[i].row], kSize.Y()+margin.Vertical())
-	}
-	rowOffsets := make([]float32, rowCount)
-	y := startY
-	for i := 0; i < rowCount; i++ {
-		rowOffsets[i] = y
-		y += rowHeights[i] + gapY
-	}
-	contentSize := matrix.Vec2{innerWidth, innerTop}
+		size := flexItemSize(items[i].ui)
+		margin := items[i].ui.Layout().Margin()
+		colItems[i] = gridTrackItem{items[i].col, items[i].colSpan, size.X() + margin.Horizontal()}
+		rowItems[i] = gridTrackItem{items[i].row, items[i].rowSpan, size.Y() + margin.Vertical()}
+	}
+	colWidths := sizeGridTracks(columns, colCount, innerWidth, gapX, true, colItems)
+	rowHeights := sizeGridTracks(rows, rowCount, innerHeight, gapY,
+		!p.FittingContentHeight() && innerHeight > 0, rowItems)
+	colOffsets := gridTrackOffsets(colWidths, gapX)
+	rowOffsets := gridTrackOffsets(rowHeights, gapY)
 	for i := range items {
 		kui := items[i].ui
 		kLayout := kui.Layout()
-		kSize := kLayout.PixelSize()
 		margin := kLayout.Margin()
-		cellX := startX
-		for col := 0; col < items[i].col && col < len(colWidths); col++ {
-			cellX += colWidths[col] + gapX
-		}
-		x := cellX + margin.X() // left aligned like CSS start
-		itemY := rowOffsets[items[i].row] + margin.Y()
-		kLayout.SetRowLayoutOffset(matrix.NewVec2(x, itemY))
-		right := (x - startX) + kSize.X() + margin.Z()
-		contentSize.SetX(max(contentSize.X(), right))
-		bottom := itemY - offsetStart.Y() + kSize.Y() + margin.W()
-		contentSize.SetY(max(contentSize.Y(), bottom))
-	}
-	contentSize.SetY(contentSize.Y() + p.layout.padding.Bottom() + p.layout.border.Bottom())
-	return contentSize
+		cellW := gridSpanSize(colOffsets, items[i].col, items[i].colSpan, gapX)
+		cellH := gridSpanSize(rowOffsets, items[i].row, items[i].rowSpan, gapY)
+		justify := kLayout.JustifySelf()
+		if justify == FlexAlignAuto {
+			justify = pd.gridJustifyItems
+		}
+		align := kLayout.AlignSelf()
+		if align == FlexAlignAuto {
+			align = pd.gridAlignItems
+		}
+		if !kui.IsType(ElementTypeLabel) {
+			if justify == FlexAlignStretch {
+				kLayout.ScaleWidth(max(1, cellW-margin.Horizontal()))
+			}
+			if align == FlexAlignStretch {
+				kLayout.ScaleHeight(max(1, cellH-margin.Vertical()))
+			}
+		}
+		kSize := flexItemSize(kui)
+		x := startX + colOffsets[items[i].col] + margin.Left() +
+			gridAlignOffset(cellW, kSize.X()+margin.Horizontal(), justify)
+		y := startY + rowOffsets[items[i].row] + margin.Top() +
+			gridAlignOffset(cellH, kSize.Y()+margin.Vertical(), align)
+		kLayout.SetRowLayoutOffset(matrix.NewVec2(x, y))
+	}
+	contentW := colOffsets[colCount] - gapX
+	contentH := rowOffsets[rowCount] - gapY
+	return matrix.Vec2{max(innerWidth, contentW),
+		innerTop + contentH + p.layout.padding.Bottom() + p.layout.border.Bottom()}
 }
 
 func (p *Panel) createScrollBar() *Panel {
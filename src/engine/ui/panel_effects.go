@@ -0,0 +1,866 @@
+/******************************************************************************/
+/* panel_effects.go                                                           */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"slices"
+
+	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering"
+)
+
+type GradientType uint8
+
+const (
+	GradientTypeNone GradientType = iota
+	GradientTypeLinear
+	GradientTypeRadial
+	GradientTypeConic
+)
+
+// MaxGradientStops is the number of color stops that fit in the instance
+// data of a panel
+const MaxGradientStops = 4
+
+// The luminance weights used by the grayscale and saturate filters as well as
+// the backdrop filter (these are the ones found in the CSS filter spec)
+const (
+	lumaR = 0.2126
+	lumaG = 0.7152
+	lumaB = 0.0722
+)
+
+// backdropSceneTexture is the attachment holding the color of the 3D scene
+// which the backdrop filter reads
+const backdropSceneTexture = "opaque.color"
+
+const (
+	effectFlagFilter      = 1
+	effectFlagInsetShadow = 2
+	effectFlagBackdrop    = 4
+	gradientKindRepeating = 4
+)
+
+// GradientLength is either a fraction (0 to 1) of the gradient line, or a
+// length in pixels when Pixels is true
+type GradientLength struct {
+	Value  float32
+	Pixels bool
+}
+
+type GradientStop struct {
+	Color    matrix.Color
+	Position GradientLength
+	// Auto stops have no position and are spread evenly between their
+	// neighbours, like a CSS color stop without a position
+	Auto bool
+}
+
+// RadialExtent is the ending shape size of a radial gradient
+type RadialExtent uint8
+
+const (
+	RadialExtentFarthestCorner RadialExtent = iota
+	RadialExtentClosestSide
+	RadialExtentClosestCorner
+	RadialExtentFarthestSide
+	// RadialExtentExplicit uses [Gradient.Radius]
+	RadialExtentExplicit
+)
+
+// Gradient describes a linear, radial or conic gradient which is drawn over
+// the background texture of a panel. Positions are resolved against the size
+// of the panel every time it is laid out.
+type Gradient struct {
+	Type      GradientType
+	Repeating bool
+	// Angle is in radians, for linear gradients 0 points to the top and it
+	// goes clockwise, for conic gradients it is the starting angle
+	Angle float32
+	// ToSide is the direction of a linear gradient given with "to" keywords,
+	// -1 is left/top and 1 is right/bottom, when zero Angle is used instead
+	ToSide matrix.Vec2
+	// Center of radial and conic gradients, only used when Positioned,
+	// otherwise the gradient is centered in the panel
+	Center     [2]GradientLength
+	Positioned bool
+	Circle     bool
+	Extent     RadialExtent
+	Radius     [2]GradientLength
+	Stops      []GradientStop
+}
+
+// BoxShadow is a drop shadow (or an inner shadow when Inset) of the panel
+// following its border radius, the same as a single CSS box-shadow
+type BoxShadow struct {
+	Offset matrix.Vec2
+	Blur   float32
+	Spread float32
+	Color  matrix.Color
+	Inset  bool
+}
+
+// ColorFilter is a color matrix that is applied to the color of the panel,
+// the CSS filter functions are all built from these. Matrix is a row major
+// 3x3 RGB matrix, the offset is added after the matrix and the alpha is
+// multiplied by Alpha.
+type ColorFilter struct {
+	Matrix [9]float32
+	Offset [3]float32
+	Alpha  float32
+}
+
+// BackdropFilter blurs and color adjusts what is drawn behind a panel
+type BackdropFilter struct {
+	Blur  float32
+	Color ColorFilter
+}
+
+type panelEffects struct {
+	gradient Gradient
+	shadow   BoxShadow
+	// layerShadows are the box shadow layers after the first, each is drawn
+	// by the instance at the same index in layerShaderData
+	layerShadows    []BoxShadow
+	layerShaderData []*ShaderData
+	filter          ColorFilter
+	backdrop        BackdropFilter
+	hasGradient     bool
+	hasShadow       bool
+	hasFilter       bool
+	hasBackdrop     bool
+	backdropAlpha   float32
+	backdropBeta    float32
+	backdropOffset  float32
+
+	// backdropShaderData is the instance under the panel that draws the
+	// backdrop filter, it is nil when the panel has none
+	backdropShaderData *ShaderData
+}
+
+func IdentityColorFilter() ColorFilter {
+	return ColorFilter{Matrix: [9]float32{1, 0, 0, 0, 1, 0, 0, 0, 1}, Alpha: 1}
+}
+
+func BrightnessFilter(amount float32) ColorFilter {
+	return ColorFilter{Matrix: [9]float32{amount, 0, 0, 0, amount, 0, 0, 0, amount}, Alpha: 1}
+}
+
+func ContrastFilter(amount float32) ColorFilter {
+	o := 0.5 - 0.5*amount
+	return ColorFilter{
+		Matrix: [9]float32{amount, 0, 0, 0, amount, 0, 0, 0, amount},
+		Offset: [3]float32{o, o, o},
+		Alpha:  1,
+	}
+}
+
+func GrayscaleFilter(amount float32) ColorFilter {
+	return SaturateFilter(1 - matrix.Clamp(amount, 0, 1))
+}
+
+func SaturateFilter(amount float32) ColorFilter {
+	s := amount
+	return ColorFilter{
+		Matrix: [9]float32{
+			s + lumaR*(1-s), lumaG * (1 - s), lumaB * (1 - s),
+			lumaR * (1 - s), s + lumaG*(1-s), lumaB * (1 - s),
+			lumaR * (1 - s), lumaG * (1 - s), s + lumaB*(1-s),
+		},
+		Alpha: 1,
+	}
+}
+
+func SepiaFilter(amount float32) ColorFilter {
+	a := 1 - matrix.Clamp(amount, 0, 1)
+	return ColorFilter{
+		Matrix: [9]float32{
+			0.393 + 0.607*a, 0.769 - 0.769*a, 0.189 - 0.189*a,
+			0.349 - 0.349*a, 0.686 + 0.314*a, 0.168 - 0.168*a,
+			0.272 - 0.272*a, 0.534 - 0.534*a, 0.131 + 0.869*a,
+		},
+		Alpha: 1,
+	}
+}
+
+// HueRotateFilter rotates the hue by the angle given in radians
+func HueRotateFilter(angle float32) ColorFilter {
+	c := float32(math.Cos(float64(angle)))
+	s := float32(math.Sin(float64(angle)))
+	return ColorFilter{
+		Matrix: [9]float32{
+			0.213 + c*0.787 - s*0.213, 0.715 - c*0.715 - s*0.715, 0.072 - c*0.072 + s*0.928,
+			0.213 - c*0.213 + s*0.143, 0.715 + c*0.285 + s*0.140, 0.072 - c*0.072 - s*0.283,
+			0.213 - c*0.213 - s*0.787, 0.715 - c*0.715 + s*0.715, 0.072 + c*0.928 + s*0.072,
+		},
+		Alpha: 1,
+	}
+}
+
+func InvertFilter(amount float32) ColorFilter {
+	a := matrix.Clamp(amount, 0, 1)
+	m := 1 - 2*a
+	return ColorFilter{
+		Matrix: [9]float32{m, 0, 0, 0, m, 0, 0, 0, m},
+		Offset: [3]float32{a, a, a},
+		Alpha:  1,
+	}
+}
+
+func OpacityFilter(amount float32) ColorFilter {
+	f := IdentityColorFilter()
+	f.Alpha = matrix.Clamp(amount, 0, 1)
+	return f
+}
+
+// Then returns the filter which applies f followed by next. Unlike CSS the
+// color is not clamped between the two filters.
+func (f ColorFilter) Then(next ColorFilter) ColorFilter {
+	out := ColorFilter{Alpha: f.Alpha * next.Alpha}
+	for r := range 3 {
+		for c := range 3 {
+			var v float32
+			for k := range 3 {
+				v += next.Matrix[r*3+k] * f.Matrix[k*3+c]
+			}
+			out.Matrix[r*3+c] = v
+		}
+		o := next.Offset[r]
+		for k := range 3 {
+			o += next.Matrix[r*3+k] * f.Offset[k]
+		}
+		out.Offset[r] = o
+	}
+	return out
+}
+
+func (f ColorFilter) IsIdentity() bool {
+	id := IdentityColorFilter()
+	for i := range f.Matrix {
+		if !matrix.Approx(f.Matrix[i], id.Matrix[i]) {
+			return false
+		}
+	}
+	return matrix.Approx(f.Offset[0], 0) && matrix.Approx(f.Offset[1], 0) &&
+		matrix.Approx(f.Offset[2], 0) && matrix.Approx(f.Alpha, 1)
+}
+
+// LuminanceForm reports if the filter can be written as
+// alpha * color + beta * luminance(color) + offset, which is the only form of
+// color adjustment that a backdrop filter supports
+func (f ColorFilter) LuminanceForm() (alpha, beta, offset float32, ok bool) {
+	const eps = 0.001
+	luma := [3]float32{lumaR, lumaG, lumaB}
+	beta = f.Matrix[3] / lumaR
+	alpha = f.Matrix[0] - beta*lumaR
+	for r := range 3 {
+		for c := range 3 {
+			want := beta * luma[c]
+			if r == c {
+				want += alpha
+			}
+			if matrix.Abs(f.Matrix[r*3+c]-want) > eps {
+				return 0, 0, 0, false
+			}
+		}
+	}
+	offset = f.Offset[0]
+	if matrix.Abs(f.Offset[1]-offset) > eps || matrix.Abs(f.Offset[2]-offset) > eps ||
+		matrix.Abs(f.Alpha-1) > eps {
+		return 0, 0, 0, false
+	}
+	return alpha, beta, offset, true
+}
+
+func (p *Panel) effects() *panelEffects {
+	pd := p.PanelData()
+	if pd.effects == nil {
+		pd.effects = &panelEffects{}
+	}
+	return pd.effects
+}
+
+func (p *Panel) Gradient() (Gradient, bool) {
+	if e := p.PanelData().effects; e != nil && e.hasGradient {
+		return e.gradient, true
+	}
+	return Gradient{}, false
+}
+
+// SetGradient draws the gradient over the background of the panel. Only the
+// first [MaxGradientStops] color stops are drawn, a warning is logged for a
+// gradient with more.
+func (p *Panel) SetGradient(gradient Gradient) error {
+	defer tracing.NewRegion("Panel.SetGradient").End()
+	if gradient.Type == GradientTypeNone {
+		p.ClearGradient()
+		return nil
+	}
+	if len(gradient.Stops) < 2 {
+		return errors.New("a gradient needs at least 2 color stops")
+	}
+	if len(gradient.Stops) > MaxGradientStops {
+		slog.Warn("the gradient has more color stops than can be drawn, the extra stops are dropped",
+			"stops", len(gradient.Stops), "limit", MaxGradientStops)
+	}
+	p.ensureBGExists(nil)
+	e := p.effects()
+	e.gradient = gradient
+	e.gradient.Stops = append([]GradientStop(nil), gradient.Stops...)
+	e.hasGradient = true
+	p.packEffects()
+	p.Base().SetDirty(DirtyTypeColorChange)
+	return nil
+}
+
+func (p *Panel) ClearGradient() {
+	e := p.PanelData().effects
+	if e == nil || !e.hasGradient {
+		return
+	}
+	e.hasGradient = false
+	e.gradient = Gradient{}
+	p.packEffects()
+	p.Base().SetDirty(DirtyTypeColorChange)
+}
+
+func (p *Panel) BoxShadow() (BoxShadow, bool) {
+	if e := p.PanelData().effects; e != nil && e.hasShadow {
+		return e.shadow, true
+	}
+	return BoxShadow{}, false
+}
+
+// SetBoxShadow sets the shadow of the panel, outer shadows grow the area the
+// panel draws to, so the layout is dirtied when their size changes
+func (p *Panel) SetBoxShadow(shadow BoxShadow) {
+	p.SetBoxShadows([]BoxShadow{shadow})
+}
+
+// BoxShadows returns every layer of the box shadow, the first is on top
+func (p *Panel) BoxShadows() []BoxShadow {
+	e := p.PanelData().effects
+	if e == nil || !e.hasShadow {
+		return nil
+	}
+	return append([]BoxShadow{e.shadow}, e.layerShadows...)
+}
+
+// SetBoxShadows sets every layer of the box shadow, the first is drawn on top
+// the same as CSS. The panel draws the first layer itself, each of the others
+// is drawn by an instance under the panel that only has the shadow.
+func (p *Panel) SetBoxShadows(shadows []BoxShadow) {
+	defer tracing.NewRegion("Panel.SetBoxShadows").End()
+	if len(shadows) == 0 {
+		p.ClearBoxShadow()
+		return
+	}
+	layers := make([]BoxShadow, len(shadows))
+	visible := false
+	for i := range shadows {
+		layers[i] = shadows[i]
+		layers[i].Blur = max(0, layers[i].Blur)
+		visible = visible || layers[i].Color.A() > 0
+	}
+	e := p.effects()
+	if e.hasShadow && e.shadow == layers[0] && slices.Equal(e.layerShadows, layers[1:]) {
+		return
+	}
+	if visible {
+		p.ensureBGExists(nil)
+	}
+	outset := p.DrawOutset()
+	e.shadow = layers[0]
+	e.layerShadows = layers[1:]
+	e.hasShadow = true
+	p.resizeShadowLayers(len(e.layerShadows))
+	p.packEffects()
+	if !matrix.Approx(outset, p.DrawOutset()) {
+		p.Base().SetDirty(DirtyTypeLayout)
+	} else {
+		p.Base().SetDirty(DirtyTypeColorChange)
+	}
+}
+
+func (p *Panel) ClearBoxShadow() {
+	e := p.PanelData().effects
+	if e == nil || !e.hasShadow {
+		return
+	}
+	outset := p.DrawOutset()
+	e.hasShadow = false
+	e.shadow = BoxShadow{}
+	e.layerShadows = nil
+	e.destroyShadowLayers()
+	p.packEffects()
+	if !matrix.Approx(outset, p.DrawOutset()) {
+		p.Base().SetDirty(DirtyTypeLayout)
+	} else {
+		p.Base().SetDirty(DirtyTypeColorChange)
+	}
+}
+
+// resizeShadowLayers creates or destroys the instances of the shadow layers
+// after the first so there are count of them. The layers are always drawn
+// with the transparent UI material as a shadow is never opaque.
+func (p *Panel) resizeShadowLayers(count int) {
+	e := p.effects()
+	for len(e.layerShaderData) > count {
+		last := len(e.layerShaderData) - 1
+		e.layerShaderData[last].Destroy()
+		e.layerShaderData = e.layerShaderData[:last]
+	}
+	pd := p.PanelData()
+	if len(e.layerShaderData) == count || !pd.drawing.IsValid() {
+		return
+	}
+	host := p.man.Value().Host
+	m, err := host.MaterialCache().Material(assets.MaterialDefinitionUITransparent)
+	if err != nil {
+		slog.Error("failed to load the material",
+			"material", assets.MaterialDefinitionUITransparent, "error", err)
+		return
+	}
+	for len(e.layerShaderData) < count {
+		sd := &ShaderData{}
+		host.Drawings.AddDrawing(rendering.Drawing{
+			Material:   m.CreateInstance(pd.drawing.Material.Textures),
+			Mesh:       pd.drawing.Mesh,
+			ShaderData: sd,
+			Transform:  &p.entity.Transform,
+			Layer:      rendering.RenderLayerUI,
+			ViewCuller: &host.Cameras.UI,
+		})
+		e.layerShaderData = append(e.layerShaderData, sd)
+	}
+}
+
+func (e *panelEffects) destroyShadowLayers() {
+	if e == nil {
+		return
+	}
+	for _, sd := range e.layerShaderData {
+		sd.Destroy()
+	}
+	e.layerShaderData = nil
+}
+
+func (e *panelEffects) destroyBackdropLayer() {
+	if e == nil || e.backdropShaderData == nil {
+		return
+	}
+	e.backdropShaderData.Destroy()
+	e.backdropShaderData = nil
+}
+
+// destroyLayers destroys every instance the effects draw besides the panel
+func (e *panelEffects) destroyLayers() {
+	e.destroyShadowLayers()
+	e.destroyBackdropLayer()
+}
+
+// setLayersActive activates or deactivates every instance the effects draw
+// besides the panel
+func (e *panelEffects) setLayersActive(active bool) {
+	if e == nil {
+		return
+	}
+	set := func(sd *ShaderData) {
+		if active {
+			sd.Activate()
+		} else {
+			sd.Deactivate()
+		}
+	}
+	for _, sd := range e.layerShaderData {
+		set(sd)
+	}
+	if e.backdropShaderData != nil {
+		set(e.backdropShaderData)
+	}
+}
+
+func (p *Panel) ColorFilter() (ColorFilter, bool) {
+	if e := p.PanelData().effects; e != nil && e.hasFilter {
+		return e.filter, true
+	}
+	return IdentityColorFilter(), false
+}
+
+// SetColorFilter applies the color filter to everything the panel draws. The
+// children of the panel are drawn separately and are not filtered.
+func (p *Panel) SetColorFilter(filter ColorFilter) {
+	if filter.IsIdentity() {
+		p.ClearColorFilter()
+		return
+	}
+	e := p.effects()
+	e.filter = filter
+	e.hasFilter = true
+	p.packEffects()
+	p.Base().SetDirty(DirtyTypeColorChange)
+}
+
+func (p *Panel) ClearColorFilter() {
+	e := p.PanelData().effects
+	if e == nil || !e.hasFilter {
+		return
+	}
+	e.hasFilter = false
+	e.filter = ColorFilter{}
+	p.packEffects()
+	p.Base().SetDirty(DirtyTypeColorChange)
+}
+
+func (p *Panel) BackdropFilter() (BackdropFilter, bool) {
+	if e := p.PanelData().effects; e != nil && e.hasBackdrop {
+		return e.backdrop, true
+	}
+	return BackdropFilter{Color: IdentityColorFilter()}, false
+}
+
+// SetBackdropFilter blurs and color adjusts what is behind the panel within
+// its border radius. The color adjustment must fit
+// [ColorFilter.LuminanceForm] (brightness, contrast, grayscale, saturate and
+// invert), anything else returns an error.
+func (p *Panel) SetBackdropFilter(filter BackdropFilter) error {
+	defer tracing.NewRegion("Panel.SetBackdropFilter").End()
+	alpha, beta, offset, ok := filter.Color.LuminanceForm()
+	if !ok {
+		return errors.New("the backdrop filter only supports blur, brightness, contrast, grayscale, saturate and invert")
+	}
+	filter.Blur = max(0, filter.Blur)
+	p.ensureBGExists(nil)
+	e := p.effects()
+	e.backdrop = filter
+	e.backdropAlpha = alpha
+	e.backdropBeta = beta
+	e.backdropOffset = offset
+	e.hasBackdrop = true
+	p.createBackdropLayer()
+	p.packEffects()
+	p.Base().SetDirty(DirtyTypeColorChange)
+	return nil
+}
+
+func (p *Panel) ClearBackdropFilter() {
+	e := p.PanelData().effects
+	if e == nil || !e.hasBackdrop {
+		return
+	}
+	e.hasBackdrop = false
+	e.backdrop = BackdropFilter{}
+	e.destroyBackdropLayer()
+	p.packEffects()
+	p.Base().SetDirty(DirtyTypeColorChange)
+}
+
+// createBackdropLayer adds the instance that draws the backdrop filter under
+// the panel. It samples the color of the 3D scene, so only the scene behind
+// the panel is filtered and not the UI behind it. Nothing is drawn when there
+// is no scene to filter.
+func (p *Panel) createBackdropLayer() {
+	e := p.effects()
+	pd := p.PanelData()
+	if e.backdropShaderData != nil || !pd.drawing.IsValid() {
+		return
+	}
+	host := p.man.Value().Host
+	var scene *rendering.Texture
+	host.RunOnRenderThread(func(device *rendering.GPUDevice) {
+		var ok bool
+		if scene, ok = device.RenderPassTexture(backdropSceneTexture); !ok && device.IsNull() {
+			// The null device has no attachments, the software rasterizer
+			// reads the frame it has drawn so far instead
+			scene, _ = host.TextureCache().Texture(assets.TextureSquare, rendering.TextureFilterLinear)
+		}
+	})
+	if scene == nil {
+		return
+	}
+	m, err := host.MaterialCache().Material(assets.MaterialDefinitionUIBackdrop)
+	if err != nil {
+		slog.Error("failed to load the material",
+			"material", assets.MaterialDefinitionUIBackdrop, "error", err)
+		return
+	}
+	sd := &ShaderData{}
+	host.Drawings.AddDrawing(rendering.Drawing{
+		Material:   m.CreateInstance([]*rendering.Texture{scene}),
+		Mesh:       pd.drawing.Mesh,
+		ShaderData: sd,
+		Transform:  &p.entity.Transform,
+		Layer:      rendering.RenderLayerUI,
+		ViewCuller: &host.Cameras.UI,
+	})
+	e.backdropShaderData = sd
+	if !p.entity.IsActive() {
+		sd.Deactivate()
+	}
+}
+
+// DrawOutset is how far past its bounds the panel draws, which is the largest
+// of the outline and the outer box shadow layers
+func (p *Panel) DrawOutset() float32 {
+	outset := max(0, p.shaderData.OutlineSize.X()) + max(0, p.shaderData.OutlineSize.Y())
+	for _, s := range p.BoxShadows() {
+		if s.Inset || s.Color.A() <= 0 {
+			continue
+		}
+		outset = max(outset, max(matrix.Abs(s.Offset.X()), matrix.Abs(s.Offset.Y()))+
+			s.Blur+max(s.Spread, 0))
+	}
+	return outset
+}
+
+// packEffects writes the effects into the shader data, it needs to run again
+// whenever the size of the panel changes as gradients are resolved against
+// it. The layout (matching ui_effects.glsl) is:
+//
+//	GradientColors: the 4 stop colors (unorm4x8)
+//	Gradient.x, y:  the 4 stop positions (half2 each)
+//	Gradient.z:     half2(angle, type + 4 if repeating)
+//	Gradient.w:     half2(center x, center y) as fractions of the panel
+//	Shadow:         offset x, offset y, blur, spread
+//	Effects.x:      shadow color (unorm4x8)
+//	Effects.y:      half2(radial gradient radius x, radius y) in pixels
+//	Effects.z:      half2(backdrop blur, backdrop alpha)
+//	Effects.w:      half2(backdrop beta, backdrop offset)
+//	FilterMatrix:   the color filter matrix m00 to m21 (half2 each)
+//	FilterParams.x: half2(m22, offset r)
+//	FilterParams.y: half2(offset g, offset b)
+//	FilterParams.z: half2(alpha scale, 0)
+//	FilterParams.w: flags (filter 1, inset shadow 2, backdrop 4)
+func (p *Panel) packEffects() {
+	sd := p.shaderData
+	sd.GradientColors = [4]uint32{}
+	sd.Gradient = [4]uint32{}
+	sd.Shadow = matrix.Vec4Zero()
+	sd.Effects = [4]uint32{}
+	sd.FilterMatrix = [4]uint32{}
+	sd.FilterParams = [4]uint32{}
+	e := p.PanelData().effects
+	if e == nil {
+		return
+	}
+	var flags uint32
+	if e.hasGradient {
+		size := p.entity.Transform.WorldScale().AsVec2()
+		packGradient(sd, e.gradient, size)
+	}
+	if e.hasShadow {
+		s := e.shadow
+		sd.Shadow = matrix.NewVec4(s.Offset.X(), s.Offset.Y(), s.Blur, s.Spread)
+		sd.Effects[0] = rendering.PackUnorm4x8(s.Color)
+		if s.Inset {
+			flags |= effectFlagInsetShadow
+		}
+	}
+	if e.hasBackdrop {
+		sd.Effects[2] = rendering.PackHalf2x16(e.backdrop.Blur, e.backdropAlpha)
+		sd.Effects[3] = rendering.PackHalf2x16(e.backdropBeta, e.backdropOffset)
+		flags |= effectFlagBackdrop
+	}
+	if e.hasFilter {
+		m := e.filter.Matrix
+		o := e.filter.Offset
+		sd.FilterMatrix = [4]uint32{
+			rendering.PackHalf2x16(m[0], m[1]),
+			rendering.PackHalf2x16(m[2], m[3]),
+			rendering.PackHalf2x16(m[4], m[5]),
+			rendering.PackHalf2x16(m[6], m[7]),
+		}
+		sd.FilterParams[0] = rendering.PackHalf2x16(m[8], o[0])
+		sd.FilterParams[1] = rendering.PackHalf2x16(o[1], o[2])
+		sd.FilterParams[2] = rendering.PackHalf2x16(e.filter.Alpha, 0)
+		flags |= effectFlagFilter
+	}
+	sd.FilterParams[3] = flags
+	p.packShadowLayers(e)
+	if e.backdropShaderData != nil {
+		base := e.backdropShaderData.ShaderDataBase
+		*e.backdropShaderData = *p.shaderData
+		e.backdropShaderData.ShaderDataBase = base
+	}
+}
+
+// packShadowLayers copies the instance data of the panel into the instances
+// of the other shadow layers, clearing everything but the shape, the color
+// filter and the layer's own shadow
+func (p *Panel) packShadowLayers(e *panelEffects) {
+	for i, sd := range e.layerShaderData {
+		base := sd.ShaderDataBase
+		*sd = *p.shaderData
+		sd.ShaderDataBase = base
+		sd.FgColor = matrix.ColorTransparent()
+		sd.BorderColor = [4]matrix.Color{}
+		sd.OutlineColor = matrix.ColorTransparent()
+		sd.OutlineSize = matrix.Vec2Zero()
+		sd.GradientColors = [4]uint32{}
+		sd.Gradient = [4]uint32{}
+		s := e.layerShadows[i]
+		sd.Shadow = matrix.NewVec4(s.Offset.X(), s.Offset.Y(), s.Blur, s.Spread)
+		sd.Effects = [4]uint32{rendering.PackUnorm4x8(s.Color)}
+		flags := sd.FilterParams[3] &^ (effectFlagInsetShadow | effectFlagBackdrop)
+		if s.Inset {
+			flags |= effectFlagInsetShadow
+		}
+		sd.FilterParams[3] = flags
+	}
+}
+
+func packGradient(sd *ShaderData, g Gradient, size matrix.Vec2) {
+	w, h := max(size.X(), 0.0001), max(size.Y(), 0.0001)
+	center := matrix.NewVec2(0.5, 0.5)
+	if g.Type != GradientTypeLinear && g.Positioned {
+		center = matrix.NewVec2(
+			resolveGradientLength(g.Center[0], w),
+			resolveGradientLength(g.Center[1], h))
+	}
+	angle := g.Angle
+	var length, radiusX, radiusY float32
+	switch g.Type {
+	case GradientTypeLinear:
+		if !g.ToSide.IsZero() {
+			// For corners the gradient line is perpendicular to the diagonal
+			// between the two other corners
+			dir := matrix.NewVec2(g.ToSide.X()*h, g.ToSide.Y()*w)
+			if g.ToSide.X() == 0 || g.ToSide.Y() == 0 {
+				dir = g.ToSide
+			}
+			angle = float32(math.Atan2(float64(dir.X()), float64(-dir.Y())))
+		}
+		sin, cos := math.Sincos(float64(angle))
+		length = matrix.Abs(w*float32(sin)) + matrix.Abs(h*float32(cos))
+	case GradientTypeRadial:
+		radiusX, radiusY = radialGradientRadii(g, center.Multiply(matrix.NewVec2(w, h)), w, h)
+		length = radiusX
+	case GradientTypeConic:
+		length = 1
+	}
+	positions := resolveGradientStops(g.Stops, length)
+	count := min(len(g.Stops), MaxGradientStops)
+	for i := range MaxGradientStops {
+		src := min(i, count-1)
+		sd.GradientColors[i] = rendering.PackUnorm4x8(g.Stops[src].Color)
+	}
+	positions = positions[:count]
+	for len(positions) < MaxGradientStops {
+		positions = append(positions, positions[count-1])
+	}
+	kind := float32(g.Type)
+	if g.Repeating {
+		kind += gradientKindRepeating
+	}
+	sd.Gradient = [4]uint32{
+		rendering.PackHalf2x16(positions[0], positions[1]),
+		rendering.PackHalf2x16(positions[2], positions[3]),
+		rendering.PackHalf2x16(angle, kind),
+		rendering.PackHalf2x16(center.X(), center.Y()),
+	}
+	sd.Effects[1] = rendering.PackHalf2x16(radiusX, radiusY)
+}
+
+func resolveGradientLength(l GradientLength, size float32) float32 {
+	if l.Pixels {
+		return l.Value / size
+	}
+	return l.Value
+}
+
+// resolveGradientStops fills in the auto stop positions the way CSS does, the
+// first and last default to the ends, the others are spread evenly between
+// the known positions and no stop may be before the one preceding it
+func resolveGradientStops(stops []GradientStop, length float32) []float32 {
+	out := make([]float32, len(stops))
+	known := make([]bool, len(stops))
+	last := len(stops) - 1
+	for i := range stops {
+		switch {
+		case !stops[i].Auto && stops[i].Position.Pixels:
+			out[i] = stops[i].Position.Value / max(length, 0.0001)
+			known[i] = true
+		case !stops[i].Auto:
+			out[i] = stops[i].Position.Value
+			known[i] = true
+		case i == 0:
+			out[i], known[i] = 0, true
+		case i == last:
+			out[i], known[i] = 1, true
+		}
+	}
+	for i := 1; i <= last; i++ {
+		if known[i] {
+			out[i] = max(out[i], out[i-1])
+		}
+	}
+	for i := 1; i < last; i++ {
+		if known[i] {
+			continue
+		}
+		end := i
+		for !known[end] {
+			end++
+		}
+		step := (out[end] - out[i-1]) / float32(end-i+1)
+		for j := i; j < end; j++ {
+			out[j] = out[j-1] + step
+			known[j] = true
+		}
+	}
+	return out
+}
+
+func radialGradientRadii(g Gradient, c matrix.Vec2, w, h float32) (float32, float32) {
+	left, right := matrix.Abs(c.X()), matrix.Abs(w-c.X())
+	top, bottom := matrix.Abs(c.Y()), matrix.Abs(h-c.Y())
+	var rx, ry float32
+	switch g.Extent {
+	case RadialExtentExplicit:
+		rx = g.Radius[0].Value
+		if !g.Radius[0].Pixels {
+			rx *= w
+		}
+		ry = rx
+		if !g.Circle {
+			ry = g.Radius[1].Value
+			if !g.Radius[1].Pixels {
+				ry *= h
+			}
+		}
+		return max(rx, 0.0001), max(ry, 0.0001)
+	case RadialExtentClosestSide, RadialExtentClosestCorner:
+		rx, ry = min(left, right), min(top, bottom)
+	default:
+		rx, ry = max(left, right), max(top, bottom)
+	}
+	corner := g.Extent == RadialExtentClosestCorner || g.Extent == RadialExtentFarthestCorner
+	if g.Circle {
+		if corner {
+			r := matrix.Vec2{rx, ry}.Length()
+			return r, r
+		}
+		if g.Extent == RadialExtentClosestSide {
+			r := min(rx, ry)
+			return r, r
+		}
+		r := max(rx, ry)
+		return r, r
+	}
+	if corner {
+		// An ellipse with the same aspect ratio as the side one which passes
+		// through the corner
+		rx *= math.Sqrt2
+		ry *= math.Sqrt2
+	}
+	return max(rx, 0.0001), max(ry, 0.0001)
+}
@@ -0,0 +1,183 @@
+/******************************************************************************/
+/* panel_effects_test.go                                                      */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"math"
+	"testing"
+
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/rendering"
+)
+
+func approxEffect(a, b float32) bool { return matrix.Abs(a-b) < 0.002 }
+
+func TestResolveGradientStops(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		name  string
+		stops []GradientStop
+		want  []float32
+	}{
+		{"all auto", []GradientStop{{Auto: true}, {Auto: true}, {Auto: true}}, []float32{0, 0.5, 1}},
+		{"auto between", []GradientStop{
+			{Position: GradientLength{Value: 0.2}},
+			{Auto: true}, {Auto: true},
+			{Position: GradientLength{Value: 0.8}},
+		}, []float32{0.2, 0.4, 0.6, 0.8}},
+		{"monotonic", []GradientStop{
+			{Position: GradientLength{Value: 0.6}},
+			{Position: GradientLength{Value: 0.3}},
+		}, []float32{0.6, 0.6}},
+		{"pixels", []GradientStop{
+			{Position: GradientLength{Value: 25, Pixels: true}},
+			{Auto: true},
+		}, []float32{0.25, 1}},
+	}
+	for _, c := range cases {
+		got := resolveGradientStops(c.stops, 100)
+		for i := range c.want {
+			if !approxEffect(got[i], c.want[i]) {
+				t.Fatalf("%s: stops = %v, want %v", c.name, got[:len(c.want)], c.want)
+			}
+		}
+	}
+}
+
+func TestPackGradientToCorner(t *testing.T) {
+	t.Parallel()
+	sd := &ShaderData{}
+	packGradient(sd, Gradient{
+		Type:   GradientTypeLinear,
+		ToSide: matrix.NewVec2(1, -1),
+		Stops: []GradientStop{
+			{Color: matrix.ColorRed(), Auto: true},
+			{Color: matrix.ColorBlue(), Auto: true},
+		},
+	}, matrix.NewVec2(200, 100))
+	angle, kind := rendering.UnpackHalf2x16(sd.Gradient[2])
+	// The line to the top right corner of a 2:1 box is perpendicular to the
+	// diagonal between the top left and bottom right corners
+	want := float32(math.Atan2(100, 200))
+	if !approxEffect(angle, want) || kind != float32(GradientTypeLinear) {
+		t.Fatalf("angle, kind = %f, %f, want %f, %d", angle, kind, want, GradientTypeLinear)
+	}
+	s2, s3 := rendering.UnpackHalf2x16(sd.Gradient[1])
+	if s2 != 1 || s3 != 1 {
+		t.Fatalf("unused stops should repeat the last stop, got %f, %f", s2, s3)
+	}
+	if sd.GradientColors[3] != rendering.PackUnorm4x8(matrix.ColorBlue()) {
+		t.Fatal("unused stop colors should repeat the last color")
+	}
+}
+
+func TestRadialGradientRadii(t *testing.T) {
+	t.Parallel()
+	center := matrix.NewVec2(20, 50)
+	g := Gradient{Type: GradientTypeRadial, Extent: RadialExtentClosestSide}
+	if rx, ry := radialGradientRadii(g, center, 100, 100); rx != 20 || ry != 50 {
+		t.Fatalf("closest-side = %f, %f", rx, ry)
+	}
+	g.Extent = RadialExtentFarthestSide
+	g.Circle = true
+	if rx, ry := radialGradientRadii(g, center, 100, 100); rx != 80 || ry != 80 {
+		t.Fatalf("circle farthest-side = %f, %f", rx, ry)
+	}
+	g.Extent = RadialExtentFarthestCorner
+	if rx, _ := radialGradientRadii(g, center, 100, 100); !approxEffect(rx, matrix.NewVec2(80, 50).Length()) {
+		t.Fatalf("circle farthest-corner = %f", rx)
+	}
+	g.Circle = false
+	if rx, ry := radialGradientRadii(g, center, 100, 100); !approxEffect(rx, 80*math.Sqrt2) || !approxEffect(ry, 50*math.Sqrt2) {
+		t.Fatalf("ellipse farthest-corner = %f, %f", rx, ry)
+	}
+}
+
+func TestColorFilterLuminanceForm(t *testing.T) {
+	t.Parallel()
+	f := BrightnessFilter(0.5).Then(GrayscaleFilter(1)).Then(InvertFilter(1))
+	alpha, beta, offset, ok := f.LuminanceForm()
+	if !ok {
+		t.Fatal("brightness, grayscale and invert should fit the luminance form")
+	}
+	if !approxEffect(alpha, 0) || !approxEffect(beta, -0.5) || !approxEffect(offset, 1) {
+		t.Fatalf("alpha, beta, offset = %f, %f, %f", alpha, beta, offset)
+	}
+	if _, _, _, ok := SepiaFilter(1).LuminanceForm(); ok {
+		t.Fatal("sepia should not fit the luminance form")
+	}
+	if _, _, _, ok := OpacityFilter(0.5).LuminanceForm(); ok {
+		t.Fatal("opacity should not fit the luminance form")
+	}
+}
+
+func TestColorFilterThen(t *testing.T) {
+	t.Parallel()
+	if !InvertFilter(1).Then(InvertFilter(1)).IsIdentity() {
+		t.Fatal("inverting twice should be the identity")
+	}
+	if !HueRotateFilter(0).IsIdentity() {
+		t.Fatal("a hue rotation of 0 should be the identity")
+	}
+	f := ContrastFilter(2).Then(OpacityFilter(0.5))
+	if !approxEffect(f.Offset[0], -0.5) || !approxEffect(f.Alpha, 0.5) {
+		t.Fatalf("unexpected composed filter %+v", f)
+	}
+}
+
+func TestPackGradientDropsExtraStops(t *testing.T) {
+	t.Parallel()
+	stops := make([]GradientStop, 6)
+	for i := range stops {
+		stops[i] = GradientStop{Color: matrix.ColorRed(), Auto: true}
+	}
+	stops[3].Color = matrix.ColorBlue()
+	sd := &ShaderData{}
+	packGradient(sd, Gradient{Type: GradientTypeLinear, Stops: stops}, matrix.NewVec2(100, 100))
+	if sd.GradientColors[3] != rendering.PackUnorm4x8(matrix.ColorBlue()) {
+		t.Fatal("expected the 4th stop to be packed")
+	}
+	// The positions are resolved against every stop before the extra are
+	// dropped, so the 4th stop stays at 60% rather than the end
+	_, pos := rendering.UnpackHalf2x16(sd.Gradient[1])
+	if !approxEffect(pos, 0.6) {
+		t.Fatalf("4th stop position = %f, want 0.6", pos)
+	}
+}
+
+func TestPackShadowLayers(t *testing.T) {
+	t.Parallel()
+	p := &Panel{shaderData: &ShaderData{
+		FgColor:      matrix.ColorRed(),
+		BorderRadius: matrix.NewVec4(4, 4, 4, 4),
+		OutlineSize:  matrix.NewVec2(2, 0),
+		ShaderEffectData: ShaderEffectData{
+			FilterParams: [4]uint32{3: effectFlagFilter | effectFlagBackdrop},
+		},
+	}}
+	e := &panelEffects{
+		layerShadows: []BoxShadow{{
+			Offset: matrix.NewVec2(1, 2), Blur: 3, Spread: 4,
+			Color: matrix.ColorBlue(), Inset: true,
+		}},
+		layerShaderData: []*ShaderData{{}},
+	}
+	p.packShadowLayers(e)
+	sd := e.layerShaderData[0]
+	if sd.BorderRadius != p.shaderData.BorderRadius {
+		t.Fatal("expected the layer to have the shape of the panel")
+	}
+	if sd.FgColor.A() != 0 || sd.OutlineSize.X() != 0 {
+		t.Fatal("expected the layer to only draw the shadow")
+	}
+	if sd.Shadow != matrix.NewVec4(1, 2, 3, 4) || sd.Effects[0] != rendering.PackUnorm4x8(matrix.ColorBlue()) {
+		t.Fatalf("unexpected layer shadow %v", sd.Shadow)
+	}
+	if sd.FilterParams[3] != effectFlagFilter|effectFlagInsetShadow {
+		t.Fatalf("unexpected layer flags %d", sd.FilterParams[3])
+	}
+}
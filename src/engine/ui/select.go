@@ -10,6 +10,7 @@ import (
 	"weak"
 
 	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/klib"
 	"kaijuengine.com/matrix"
 	"kaijuengine.com/platform/profiler/tracing"
 	"kaijuengine.com/rendering"
@@ -581,6 +582,20 @@ func (s *Select) optionClick(option *UI) {
 	s.PickOption(idx)
 }
 
+// stepOption picks the option delta away from the selected one, keeping the
+// list open if it was, this is how focus navigation moves through options
+func (s *Select) stepOption(delta int) {
+	data := s.SelectData()
+	if len(data.options) == 0 {
+		return
+	}
+	wasOpen := data.isOpen
+	s.PickOption(klib.Clamp(data.selected+delta, 0, len(data.options)-1))
+	if wasOpen {
+		s.expand()
+	}
+}
+
 func (s *Select) update(deltaTime float64) {
 	defer tracing.NewRegion("Select.update").End()
 	s.Base().ToPanel().update(deltaTime)
@@ -26,6 +26,22 @@ type ShaderData struct {
 	BorderLen    matrix.Vec2
 	OutlineColor matrix.Color
 	OutlineSize  matrix.Vec2
+	ShaderEffectData
+}
+
+// ShaderEffectData holds the gradient, shadow and filter effects of a panel.
+// The UI shader is at the limit of vertex attributes so they are not instance
+// data, they are bound as a storage buffer with an entry for each instance.
+// They are tightly packed with [rendering.PackUnorm4x8] and
+// [rendering.PackHalf2x16], all zeros means the effect is disabled. See
+// packEffects for the layout.
+type ShaderEffectData struct {
+	GradientColors [4]uint32
+	Gradient       [4]uint32
+	Shadow         matrix.Vec4
+	Effects        [4]uint32
+	FilterMatrix   [4]uint32
+	FilterParams   [4]uint32
 }
 
 func (ShaderData) Size() int {
@@ -43,6 +59,16 @@ func (ShaderData) Size() int {
 		unsafe.Sizeof(ShaderData{}.OutlineSize))
 }
 
+func (s *ShaderData) UpdateBoundData() bool { return true }
+
+func (s *ShaderData) InstanceBoundDataSize() int {
+	return int(unsafe.Sizeof(s.ShaderEffectData))
+}
+
+func (s *ShaderData) BoundDataPointer() unsafe.Pointer {
+	return unsafe.Pointer(&s.ShaderEffectData)
+}
+
 func (s *ShaderData) setUVSize(width, height float32) {
 	s.UVs.SetZ(width)
 	s.UVs.SetW(height)
@@ -0,0 +1,51 @@
+/******************************************************************************/
+/* text_input_method.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/windowing"
+)
+
+// textInputRect is the caret of an editable element in window pixels, it is
+// where the input method opens its candidate window
+type textInputRect struct {
+	x, y, width, height int
+}
+
+// editableTextComposed is the text that is shown while an input method is
+// composing, the pre-edit text sits at the cursor until it is committed
+func editableTextComposed(text string, offset int, composition string) string {
+	if composition == "" {
+		return text
+	}
+	return editableTextInsert(text, offset, composition)
+}
+
+// textInputRectFromWorld converts the world position and scale of a caret,
+// which are centered on the window with Y up, to window pixels
+func textInputRectFromWorld(pos, scale matrix.Vec3, windowWidth, windowHeight int) textInputRect {
+	halfWidth := matrix.Float(windowWidth) * 0.5
+	halfHeight := matrix.Float(windowHeight) * 0.5
+	return textInputRect{
+		x:      int(matrix.Round(pos.X() - scale.X()*0.5 + halfWidth)),
+		y:      int(matrix.Round(halfHeight - (pos.Y() + scale.Y()*0.5))),
+		width:  int(matrix.Round(scale.X())),
+		height: int(matrix.Round(scale.Y())),
+	}
+}
+
+// reportTextInputRect moves the input method of the window to the caret,
+// last is the rect that was reported before so it is only sent on changes
+func reportTextInputRect(win *windowing.Window, caret *Panel, last *textInputRect) {
+	t := &caret.entity.Transform
+	rect := textInputRectFromWorld(t.WorldPosition(), t.WorldScale(), win.Width(), win.Height())
+	if rect != *last {
+		*last = rect
+		win.SetTextInputRect(rect.x, rect.y, rect.width, rect.height)
+	}
+}
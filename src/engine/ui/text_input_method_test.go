@@ -0,0 +1,43 @@
+/******************************************************************************/
+/* text_input_method_test.go                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+func TestEditableTextComposed(t *testing.T) {
+	tests := []struct {
+		text, composition string
+		offset            int
+		want              string
+	}{
+		{text: "abc", composition: "", offset: 1, want: "abc"},
+		{text: "東京", composition: "に", offset: 1, want: "東に京"},
+		{text: "héllo", composition: "ü", offset: 5, want: "hélloü"},
+	}
+	for _, tt := range tests {
+		if got := editableTextComposed(tt.text, tt.offset, tt.composition); got != tt.want {
+			t.Errorf("editableTextComposed(%q, %d, %q) = %q, want %q",
+				tt.text, tt.offset, tt.composition, got, tt.want)
+		}
+	}
+}
+
+func TestTextInputRectFromWorld(t *testing.T) {
+	// A caret 2 pixels wide and 20 tall, its center 100 pixels right of and
+	// 50 pixels above the center of an 800x600 window
+	pos := matrix.NewVec3(100, 50, 0)
+	scale := matrix.NewVec3(2, 20, 1)
+	got := textInputRectFromWorld(pos, scale, 800, 600)
+	want := textInputRect{x: 499, y: 240, width: 2, height: 20}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+}
@@ -86,6 +86,10 @@ type textareaData struct {
 
 	onScroll           func()
 	lastScrollNotified float32
+
+	composition      string
+	compositionCaret int
+	textInputRect    textInputRect
 }
 
 func (t *textareaData) innerPanelData() *panelData { return &t.panelData }
@@ -209,8 +213,10 @@ func (textarea *TextArea) Init(placeholderText string) {
 	base.AddEvent(EventTypeMiss, textarea.onMiss)
 	base.AddEvent(EventTypeRebuild, textarea.onRebuild)
 	id := host.Window.Keyboard.AddKeyCallback(textarea.keyPressed)
+	textId := host.Window.Keyboard.AddTextCallback(textarea.textTyped)
 	base.AddEvent(EventTypeDestroy, func() {
 		host.Window.Keyboard.RemoveKeyCallback(id)
+		host.Window.Keyboard.RemoveTextCallback(textId)
 	})
 	textarea.entity.OnDeactivate.Add(textarea.deactivated)
 	textarea.entity.OnActivate.Add(textarea.activated)
@@ -250,7 +256,7 @@ func (d *textareaLineDelegate) BindRow(index int, row *UI) {
 	lbl := row.ToLabel()
 	d.ta.applyLabelStyle(lbl)
 	lbl.SetWrap(data.wrap)
-	lbl.SetText(data.doc.line(index))
+	lbl.SetText(d.ta.displayLine(index))
 	if spans, ok := data.lineSpans[index]; ok {
 		for _, s := range spans {
 			lbl.ColorRange(s.Start, s.End, s.FG, s.BG)
@@ -326,7 +332,7 @@ func (textarea *TextArea) measureLineHeight(index int) float32 {
 		return lh
 	}
 	host := textarea.man.Value().Host
-	text := data.doc.line(index)
+	text := textarea.displayLine(index)
 	if text == "" {
 		return lh
 	}
@@ -344,19 +350,40 @@ func (textarea *TextArea) textContentWidth() float32 {
 }
 
 func (textarea *TextArea) lineRects(line int) []matrix.Vec4 {
+	return textarea.textRects(textarea.Data().doc.line(line))
+}
+
+func (textarea *TextArea) textRects(text string) []matrix.Vec4 {
 	d := textarea.Data()
 	maxW := float32(0)
 	if d.wrap {
 		maxW = textarea.textContentWidth()
 	}
 	return textarea.man.Value().Host.FontCache().StringRectsWithinWithLetterSpacing(
-		d.fontFace, d.doc.line(line), d.effectiveFontSize(), maxW, d.lineHeight, d.letterSpacing)
+		d.fontFace, text, d.effectiveFontSize(), maxW, d.lineHeight, d.letterSpacing,
+		rendering.TextDirectionAuto)
+}
+
+// displayLine is the text shown on the line, which has the input method
+// composition at the cursor while one is in progress
+func (textarea *TextArea) displayLine(index int) string {
+	data := textarea.Data()
+	line := data.doc.line(index)
+	if pos := data.doc.cursorPos(); data.composition != "" && pos.line == index {
+		return editableTextComposed(line, pos.col, data.composition)
+	}
+	return line
 }
 
 func (textarea *TextArea) caretPixel(pos textPos) textareaCaretGeometry {
 	data := textarea.Data()
-	rects := textarea.lineRects(pos.line)
-	c := textareaCaretFromRuneRects(data.doc.line(pos.line), rects, pos.col, textarea.resolvedLineHeight())
+	text, col := data.doc.line(pos.line), pos.col
+	if data.composition != "" && pos == data.doc.cursorPos() {
+		text = textarea.displayLine(pos.line)
+		col += data.compositionCaret
+	}
+	rects := textarea.textRects(text)
+	c := textareaCaretFromRuneRects(text, rects, col, textarea.resolvedLineHeight())
 	c.y += data.list.RowOffset(pos.line)
 	c.line = pos.line
 	return c
@@ -726,6 +753,7 @@ func (textarea *TextArea) update(deltaTime float64) {
 		}
 		data.cursorBlink = cursorBlinkRate
 	}
+	reportTextInputRect(textarea.man.Value().Host.Window, data.cursor, &data.textInputRect)
 	// The scrolling list (not the TextArea panel) is the pointer hit target, so
 	// the drag flag lands on it; watch both so click-drag extends the selection.
 	if textarea.flags.drag() || data.list.Base().flags.drag() {
@@ -1146,6 +1174,7 @@ func (textarea *TextArea) SelectedText() string { return textarea.Data().doc.sel
 func (textarea *TextArea) setText(text string, skipEvent bool) {
 	data := textarea.Data()
 	wasValid := textarea.IsValid()
+	data.composition, data.compositionCaret = "", 0
 	data.doc.setText(text)
 	clear(data.lineSpans)
 	data.maxLineWidth = 0
@@ -1250,6 +1279,7 @@ func (textarea *TextArea) removeFocusWithoutEvents() {
 		return
 	}
 	data.isActive = false
+	textarea.setComposition("", 0)
 	textarea.resetSelect()
 	textarea.hideCursor()
 	textarea.hideSelection()
@@ -1269,6 +1299,7 @@ func (textarea *TextArea) RemoveFocus() {
 		return
 	}
 	data.isActive = false
+	textarea.setComposition("", 0)
 	textarea.resetSelect()
 	textarea.hideCursor()
 	txt := textarea.Text()
@@ -1616,6 +1647,10 @@ func (textarea *TextArea) keyPressed(keyId int, keyState hid.KeyState) {
 		return
 	}
 	kb := &host.Window.Keyboard
+	if kb.IsComposing() {
+		// The keys are editing the input method composition
+		return
+	}
 	switch keyState {
 	case hid.KeyStateDown, hid.KeyStatePressedAndReleased:
 		if keyId == hid.KeyboardKeyEscape {
@@ -1626,10 +1661,15 @@ func (textarea *TextArea) keyPressed(keyId int, keyState hid.KeyState) {
 		c := host.Localization.KeyToRune(kb, keyId)
 		if c != 0 {
 			if !kb.HasCtrlOrMeta() {
-				if kb.IsToggleKeyOn(hid.KeyboardKeyCapsLock) {
-					textarea.InsertText(string(unicode.ToUpper(c)))
-				} else {
-					textarea.InsertText(string(c))
+				// Platforms that report typed text send it to textTyped with the
+				// keyboard layout applied, key codes only map to the American
+				// English layout
+				if !kb.TextEventsEnabled() {
+					if kb.IsToggleKeyOn(hid.KeyboardKeyCapsLock) {
+						textarea.InsertText(string(unicode.ToUpper(c)))
+					} else {
+						textarea.InsertText(string(c))
+					}
 				}
 			} else {
 				switch c {
@@ -1717,6 +1757,50 @@ func (textarea *TextArea) keyPressed(keyId int, keyState hid.KeyState) {
 	}
 }
 
+// textTyped receives the text typed on the keyboard and the composition of
+// the input method while the text area has focus
+func (textarea *TextArea) textTyped(evt hid.TextEvent) {
+	if textarea.IsDisabled() || textarea.IsReadOnly() {
+		return
+	}
+	man := textarea.man.Value()
+	data := textarea.Data()
+	if man == nil || !textarea.entity.IsActive() || !data.isActive {
+		return
+	}
+	switch evt.Type {
+	case hid.TextEventCommit:
+		textarea.setComposition("", 0)
+		textarea.InsertText(evt.Text)
+		if textarea.events[EventTypeKeyDown].IsEmpty() {
+			man.Group.triggerRequestStartState()
+		}
+	case hid.TextEventComposition:
+		textarea.setComposition(evt.Text, evt.Caret)
+	}
+}
+
+// setComposition shows the pre-edit text of the input method at the cursor,
+// the document doesn't change until the composition is committed
+func (textarea *TextArea) setComposition(text string, caret int) {
+	data := textarea.Data()
+	if data.composition == text && data.compositionCaret == caret {
+		return
+	}
+	data.composition = text
+	data.compositionCaret = editableTextClampOffset(text, caret)
+	if data.wrap {
+		data.list.InvalidateRow(data.doc.cursorPos().line)
+	}
+	data.list.RefreshVisible()
+	if text != "" {
+		data.placeholder.Hide()
+	} else {
+		textarea.updatePlaceholderVisibility()
+	}
+	textarea.showCursor()
+}
+
 func (textarea *TextArea) heldLongEnough(kb *hid.Keyboard, keyId int) bool {
 	prev := kb.GetKeyLastClicked(keyId)
 	return time.Since(prev).Milliseconds() > holdKeyPressedDuration
@@ -159,6 +159,7 @@ type UI struct {
 	elmType          ElementType
 	dirtyType        DirtyType
 	shaderData       *ShaderData
+	transform        *uiTransform
 	textureSize      matrix.Vec2
 	lastClick        float64
 	poolId           pooling.PoolGroupId
@@ -500,6 +501,7 @@ func (ui *UI) Clean() {
 		for i := range tree {
 			if _, ok := paintTargets[tree[i]]; ok && tree[i].IsActive() {
 				tree[i].render()
+				tree[i].applyPostModel()
 			}
 		}
 		return
@@ -574,8 +576,10 @@ func (ui *UI) Clean() {
 		if !tree[i].IsActive() {
 			continue
 		}
+		tree[i].updateRenderTransform()
 		tree[i].GenerateScissor()
 		tree[i].render()
+		tree[i].applyPostModel()
 	}
 }
 
@@ -668,12 +672,13 @@ func (ui *UI) GenerateScissor() {
 		pos.Y() + size.Y()*0.5,
 	}
 	if !ui.IsType(ElementTypeLabel) {
-		outset := ui.ToPanel().OutlineOutset()
+		outset := ui.ToPanel().DrawOutset()
 		bounds.SetX(bounds.X() - outset)
 		bounds.SetY(bounds.Y() - outset)
 		bounds.SetZ(bounds.Z() + outset)
 		bounds.SetW(bounds.W() + outset)
 	}
+	bounds = ui.transformedBounds(bounds)
 	if !ui.entity.IsRoot() {
 		p := FirstPanelOnEntity(ui.entity.Parent)
 		for p.PanelData().overflow == OverflowVisible && !p.entity.IsRoot() {
@@ -867,7 +872,9 @@ func (ui *UI) cursorPos(cursor *hid.Cursor) matrix.Vec2 {
 func (ui *UI) containedCheck(cursor *hid.Cursor, entity *engine.Entity) {
 	defer tracing.NewRegion("UI.containedCheck").End()
 	cp := ui.cursorPos(cursor)
-	contained := entity.Transform.ContainsPoint2D(cp)
+	// The scissor is in screen space, the bounds are where the layout placed
+	// the element before it was transformed
+	contained := entity.Transform.ContainsPoint2D(ui.untransformPoint(cp))
 	if contained && ui.hasScissor() {
 		contained = ui.shaderData.Scissor.ScreenAreaContains(cp.X(), cp.Y())
 	}
@@ -1149,6 +1156,15 @@ func (ui *UI) Clone(parent *engine.Entity) *UI {
 		cpy.ToSlider().Init()
 	}
 	cpy.SetDisabled(ui.IsDisabled())
+	if !ui.IsType(ElementTypeLabel) {
+		if nav := ui.ToPanel().PanelData().navigation; nav != nil {
+			cpyNav := *nav
+			cpy.ToPanel().PanelData().navigation = &cpyNav
+		}
+	}
+	if t, ok := ui.Transform2D(); ok {
+		cpy.SetTransform2D(t)
+	}
 	if parent != nil {
 		panel := FirstPanelOnEntity(parent)
 		if panel != nil {
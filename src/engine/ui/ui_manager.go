@@ -23,6 +23,7 @@ import (
 type Manager struct {
 	Host            *engine.Host
 	Group           Group
+	Navigation      FocusNavigation
 	pools           pooling.PoolGroup[UI]
 	hovered         [][]*UI
 	itrRoots        []*UI
@@ -34,6 +35,11 @@ type Manager struct {
 	rootBGColor     matrix.Color
 	windowResized   bool
 	windowMinimized bool
+	animators       []Animator
+	itrAnimators    []Animator
+	animatorMutex   sync.Mutex
+	// glyphAtlasVersion is the last seen version of the runtime glyph atlas
+	glyphAtlasVersion uint64
 }
 
 // RootBackgroundColor is the opaque backdrop that the top of the UI tree
@@ -68,6 +74,8 @@ func (man *Manager) update(deltaTime float64) {
 	if man.windowMinimized || (man.skipUpdate > 0 && !man.windowResized) {
 		return
 	}
+	man.animate(deltaTime)
+	man.refreshEvictedGlyphs()
 	man.itrRoots = klib.WipeSlice(man.itrRoots)
 	man.itrChildren = klib.WipeSlice(man.itrChildren)
 	man.itrAll = klib.WipeSlice(man.itrAll)
@@ -121,6 +129,25 @@ func (man *Manager) update(deltaTime float64) {
 	threads.AddWork(work)
 	wg.Wait()
 	man.windowResized = false
+	if man.Navigation.enabled {
+		man.Navigation.update(deltaTime)
+	}
+}
+
+// refreshEvictedGlyphs renders the labels again if any glyph was evicted from
+// the runtime glyph atlas, they could be drawing a glyph that took its place
+func (man *Manager) refreshEvictedGlyphs() {
+	v := man.Host.FontCache().GlyphAtlasVersion()
+	if v == man.glyphAtlasVersion {
+		return
+	}
+	man.glyphAtlasVersion = v
+	man.pools.Each(func(elm *UI) {
+		if elm.IsValid() && elm.IsType(ElementTypeLabel) {
+			elm.ToLabel().LabelData().renderRequired = true
+			elm.SetDirty(DirtyTypeColorChange)
+		}
+	})
 }
 
 func (man *Manager) Hovered() []*UI {
@@ -149,6 +176,7 @@ func (man *Manager) Init(host *engine.Host) {
 	})
 	man.Group.Attach(man.Host)
 	man.Group.SetThreaded()
+	man.Navigation.init(man)
 	man.resizeEvtId = host.Window.OnResize.Add(func() {
 		if m := wMan.Value(); m != nil {
 			m.windowResized = true
@@ -182,6 +210,9 @@ func (man *Manager) Init(host *engine.Host) {
 func (man *Manager) Clear() {
 	defer tracing.NewRegion("ui.Manager.Clear").End()
 	man.pools.Each(func(ui *UI) { man.Host.DestroyEntity(ui.Entity()) })
+	man.animatorMutex.Lock()
+	man.animators = klib.WipeSlice(man.animators)
+	man.animatorMutex.Unlock()
 	// Clearing the pools shouldn't be needed as destroying the entities
 	// will remove the entry from the pool
 }
@@ -0,0 +1,337 @@
+/******************************************************************************/
+/* ui_transform.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"math"
+
+	"kaijuengine.com/engine"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering"
+)
+
+type TransformFunctionType uint8
+
+const (
+	TransformFunctionTranslate = TransformFunctionType(iota)
+	TransformFunctionScale
+	TransformFunctionRotate
+	TransformFunctionSkew
+	TransformFunctionMatrix
+)
+
+// TransformLength is a length in pixels, or a fraction of the size of the
+// element when Fraction is true (what a CSS percentage is resolved to)
+type TransformLength struct {
+	Value    float32
+	Fraction bool
+}
+
+func (l TransformLength) resolve(size float32) float32 {
+	if l.Fraction {
+		return l.Value * size
+	}
+	return l.Value
+}
+
+// TransformFunction is one of the CSS 2D transform functions. The values
+// depend on the type:
+//   - Translate: Offset is the x and y translation
+//   - Scale: Values[0] and Values[1] are the x and y scale
+//   - Rotate: Values[0] is the angle in radians, clockwise on screen
+//   - Skew: Values[0] and Values[1] are the x and y skew angles in radians
+//   - Matrix: Values are a, b, c, d, e, f of the CSS matrix() function
+type TransformFunction struct {
+	Type   TransformFunctionType
+	Values [6]float32
+	Offset [2]TransformLength
+}
+
+// Transform2D is the CSS transform of a UI element. The element (and all of
+// its children) are drawn and hit tested through the transform, but the
+// layout doesn't know about it, so changing it never causes a reflow. This
+// is the same as in CSS where the space the element takes is unchanged by
+// its transform.
+//
+// Translate, Rotate and Scale are the individual CSS properties of the same
+// name, they are applied in that order before Functions (the transform
+// property). Everything is applied around Origin, which starts at the
+// center of the element.
+type Transform2D struct {
+	Translate [2]TransformLength
+	Rotate    float32
+	Scale     matrix.Vec2
+	Functions []TransformFunction
+	Origin    [2]TransformLength
+}
+
+// uiTransform is the render transform state of an element, it exists when
+// the element or one of its parents is transformed
+type uiTransform struct {
+	local    Transform2D
+	hasLocal bool
+	active   bool
+	world    matrix.Mat4
+	inverse  matrix.Mat4
+}
+
+// affine2D is a CSS matrix(a, b, c, d, e, f) in screen space (y down), where
+// x' = a*x + c*y + e and y' = b*x + d*y + f
+type affine2D [6]float32
+
+// NewTransform2D returns a transform that does nothing, with the origin at
+// the center of the element
+func NewTransform2D() Transform2D {
+	return Transform2D{
+		Scale: matrix.Vec2One(),
+		Origin: [2]TransformLength{
+			{Value: 0.5, Fraction: true},
+			{Value: 0.5, Fraction: true},
+		},
+	}
+}
+
+func TranslateFunction(x, y TransformLength) TransformFunction {
+	return TransformFunction{Type: TransformFunctionTranslate, Offset: [2]TransformLength{x, y}}
+}
+
+func ScaleFunction(x, y float32) TransformFunction {
+	return TransformFunction{Type: TransformFunctionScale, Values: [6]float32{x, y}}
+}
+
+func RotateFunction(radians float32) TransformFunction {
+	return TransformFunction{Type: TransformFunctionRotate, Values: [6]float32{radians}}
+}
+
+func SkewFunction(x, y float32) TransformFunction {
+	return TransformFunction{Type: TransformFunctionSkew, Values: [6]float32{x, y}}
+}
+
+func MatrixFunction(a, b, c, d, e, f float32) TransformFunction {
+	return TransformFunction{Type: TransformFunctionMatrix, Values: [6]float32{a, b, c, d, e, f}}
+}
+
+func affineIdentity() affine2D { return affine2D{1, 0, 0, 1, 0, 0} }
+
+func (m affine2D) multiply(n affine2D) affine2D {
+	return affine2D{
+		m[0]*n[0] + m[2]*n[1],
+		m[1]*n[0] + m[3]*n[1],
+		m[0]*n[2] + m[2]*n[3],
+		m[1]*n[2] + m[3]*n[3],
+		m[0]*n[4] + m[2]*n[5] + m[4],
+		m[1]*n[4] + m[3]*n[5] + m[5],
+	}
+}
+
+func (f TransformFunction) affine(size matrix.Vec2) affine2D {
+	v := f.Values
+	switch f.Type {
+	case TransformFunctionTranslate:
+		return affine2D{1, 0, 0, 1, f.Offset[0].resolve(size.X()), f.Offset[1].resolve(size.Y())}
+	case TransformFunctionScale:
+		return affine2D{v[0], 0, 0, v[1], 0, 0}
+	case TransformFunctionRotate:
+		s, c := math.Sincos(float64(v[0]))
+		return affine2D{float32(c), float32(s), float32(-s), float32(c), 0, 0}
+	case TransformFunctionSkew:
+		return affine2D{1, float32(math.Tan(float64(v[1]))), float32(math.Tan(float64(v[0]))), 1, 0, 0}
+	case TransformFunctionMatrix:
+		return affine2D(v)
+	}
+	return affineIdentity()
+}
+
+// IsIdentity returns true if the transform doesn't move the element at all
+func (t Transform2D) IsIdentity() bool {
+	return t.affine(matrix.NewVec2(1, 1)) == affineIdentity()
+}
+
+// affine is the transform relative to the top left of the element, in pixels
+// with y down, including the origin
+func (t Transform2D) affine(size matrix.Vec2) affine2D {
+	m := TranslateFunction(t.Translate[0], t.Translate[1]).affine(size)
+	if t.Rotate != 0 {
+		m = m.multiply(RotateFunction(t.Rotate).affine(size))
+	}
+	m = m.multiply(ScaleFunction(t.Scale.X(), t.Scale.Y()).affine(size))
+	for i := range t.Functions {
+		m = m.multiply(t.Functions[i].affine(size))
+	}
+	ox, oy := t.Origin[0].resolve(size.X()), t.Origin[1].resolve(size.Y())
+	m = affine2D{1, 0, 0, 1, ox, oy}.multiply(m)
+	return m.multiply(affine2D{1, 0, 0, 1, -ox, -oy})
+}
+
+// worldMatrix converts the transform to the UI world space (y up, centered)
+// for an element with the given world position and size
+func (t Transform2D) worldMatrix(position, size matrix.Vec2) matrix.Mat4 {
+	m := t.affine(size)
+	left := position.X() - size.X()*0.5
+	top := position.Y() + size.Y()*0.5
+	w := matrix.Mat4Identity()
+	w[matrix.Mat4x0y0] = m[0]
+	w[matrix.Mat4x1y0] = -m[1]
+	w[matrix.Mat4x0y1] = -m[2]
+	w[matrix.Mat4x1y1] = m[3]
+	w[matrix.Mat4x0y3] = left - (m[0]*left - m[2]*top) + m[4]
+	w[matrix.Mat4x1y3] = top - (-m[1]*left + m[3]*top) - m[5]
+	return w
+}
+
+// Transform2D returns the CSS transform of the element, the second return is
+// false when the element has no transform of its own
+func (ui *UI) Transform2D() (Transform2D, bool) {
+	if ui.transform != nil && ui.transform.hasLocal {
+		return ui.transform.local, true
+	}
+	return NewTransform2D(), false
+}
+
+// SetTransform2D draws and hit tests the element (and its children) through
+// the transform, see [Transform2D]. The layout is not changed. The transform
+// is kept even when it does nothing, so the individual CSS properties can
+// each change their part of it.
+func (ui *UI) SetTransform2D(transform Transform2D) {
+	defer tracing.NewRegion("UI.SetTransform2D").End()
+	if ui.transform == nil {
+		ui.transform = &uiTransform{}
+	}
+	ui.transform.local = transform
+	ui.transform.local.Functions = append([]TransformFunction(nil), transform.Functions...)
+	ui.transform.hasLocal = true
+	ui.refreshRenderTransforms()
+}
+
+func (ui *UI) ClearTransform2D() {
+	if ui.transform == nil || !ui.transform.hasLocal {
+		return
+	}
+	ui.transform.hasLocal = false
+	ui.transform.local = Transform2D{}
+	ui.refreshRenderTransforms()
+}
+
+// RenderTransform is the matrix that moves the element from where the layout
+// placed it to where it is drawn, it combines the transforms of the element
+// and all of its parents
+func (ui *UI) RenderTransform() (matrix.Mat4, bool) {
+	if ui.transform != nil && ui.transform.active {
+		return ui.transform.world, true
+	}
+	return matrix.Mat4Identity(), false
+}
+
+// refreshRenderTransforms updates the render transform of the element and
+// all of its children, it is used when a transform changes outside of a
+// layout pass
+func (ui *UI) refreshRenderTransforms() {
+	ui.updateRenderTransform()
+	ui.GenerateScissor()
+	for _, child := range ui.entity.Children {
+		for _, cui := range AllOnEntity(child) {
+			cui.refreshRenderTransforms()
+		}
+	}
+}
+
+func parentRenderTransform(entity *engine.Entity) (matrix.Mat4, bool) {
+	if entity.Parent == nil {
+		return matrix.Mat4Identity(), false
+	}
+	if parent := FirstOnEntity(entity.Parent); parent != nil {
+		return parent.RenderTransform()
+	}
+	return matrix.Mat4Identity(), false
+}
+
+// updateRenderTransform combines the transform of the element with the one
+// of its parent, the parent is expected to be up to date
+func (ui *UI) updateRenderTransform() {
+	parent, hasParent := parentRenderTransform(&ui.entity)
+	t := ui.transform
+	ownActive := t != nil && t.hasLocal && !t.local.IsIdentity()
+	if !hasParent && !ownActive {
+		if t != nil && t.active {
+			t.active = false
+			ui.applyPostModel()
+		}
+		if t != nil && !t.hasLocal {
+			ui.transform = nil
+		}
+		return
+	}
+	if t == nil {
+		t = &uiTransform{}
+		ui.transform = t
+	}
+	t.world = matrix.Mat4Identity()
+	if ownActive {
+		pos := ui.entity.Transform.WorldPosition()
+		size := ui.entity.Transform.WorldScale()
+		t.world = t.local.worldMatrix(pos.AsVec2(), size.AsVec2())
+	}
+	if hasParent {
+		t.world.MultiplyAssign(parent)
+	}
+	t.inverse = t.world.Inverted()
+	t.active = true
+	ui.applyPostModel()
+}
+
+// applyPostModel pushes the render transform to the drawings of the element
+func (ui *UI) applyPostModel() {
+	set := func(sd *rendering.ShaderDataBase) {
+		if ui.transform != nil && ui.transform.active {
+			sd.SetPostModel(ui.transform.world)
+		} else {
+			sd.ClearPostModel()
+		}
+	}
+	if ui.IsType(ElementTypeLabel) {
+		ld := ui.ToLabel().LabelData()
+		for i := range ld.runeDrawings {
+			set(ld.runeDrawings[i].ShaderData.Base())
+		}
+	} else if ui.elmData != nil && ui.ToPanel().PanelData().drawing.IsValid() {
+		set(&ui.shaderData.ShaderDataBase)
+	}
+}
+
+// transformedBounds is the screen space box around the bounds (left, bottom,
+// right, top) after the render transform
+func (ui *UI) transformedBounds(bounds matrix.Vec4) matrix.Vec4 {
+	if ui.transform == nil || !ui.transform.active {
+		return bounds
+	}
+	out := matrix.Vec4{matrix.FloatMax, matrix.FloatMax, -matrix.FloatMax, -matrix.FloatMax}
+	corners := [4]matrix.Vec3{
+		{bounds.X(), bounds.Y(), 0},
+		{bounds.Z(), bounds.Y(), 0},
+		{bounds.X(), bounds.W(), 0},
+		{bounds.Z(), bounds.W(), 0},
+	}
+	for _, c := range corners {
+		p := ui.transform.world.TransformPoint(c)
+		out.SetX(min(out.X(), p.X()))
+		out.SetY(min(out.Y(), p.Y()))
+		out.SetZ(max(out.Z(), p.X()))
+		out.SetW(max(out.W(), p.Y()))
+	}
+	return out
+}
+
+// untransformPoint moves a point on screen into the space the layout placed
+// the element in, which is what hit testing is done against
+func (ui *UI) untransformPoint(point matrix.Vec2) matrix.Vec2 {
+	if ui.transform == nil || !ui.transform.active {
+		return point
+	}
+	p := ui.transform.inverse.TransformPoint(matrix.NewVec3(point.X(), point.Y(), 0))
+	return p.AsVec2()
+}
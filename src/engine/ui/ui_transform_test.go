@@ -0,0 +1,109 @@
+/******************************************************************************/
+/* ui_transform_test.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package ui
+
+import (
+	"math"
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+func testTransformUI(width, height float32) *UI {
+	target := testLayoutUI(width, height)
+	target.shaderData = &ShaderData{}
+	return target
+}
+
+func rotated90() Transform2D {
+	t := NewTransform2D()
+	t.Rotate = math.Pi / 2
+	return t
+}
+
+func TestTransform2DWorldMatrix(t *testing.T) {
+	t.Parallel()
+	// Rotating clockwise on screen around the center moves the top left
+	// corner of a 100x50 box to the top right of the rotated box
+	m := rotated90().worldMatrix(matrix.Vec2Zero(), matrix.NewVec2(100, 50))
+	got := m.TransformPoint(matrix.NewVec3(-50, 25, 0))
+	if !matrix.Vec3ApproxTo(got, matrix.NewVec3(25, 50, 0), 0.001) {
+		t.Fatalf("top left corner = %v, want [25 50 0]", got)
+	}
+	// translate(-50%, 0) then scale(2) from the top left origin
+	tr := NewTransform2D()
+	tr.Origin = [2]TransformLength{{Fraction: true}, {Fraction: true}}
+	tr.Functions = []TransformFunction{
+		TranslateFunction(TransformLength{Value: -0.5, Fraction: true}, TransformLength{}),
+		ScaleFunction(2, 2),
+	}
+	m = tr.worldMatrix(matrix.Vec2Zero(), matrix.NewVec2(100, 50))
+	got = m.TransformPoint(matrix.NewVec3(50, -25, 0))
+	if !matrix.Vec3ApproxTo(got, matrix.NewVec3(100, -75, 0), 0.001) {
+		t.Fatalf("bottom right corner = %v, want [100 -75 0]", got)
+	}
+}
+
+func TestTransform2DIdentityKeepsOrigin(t *testing.T) {
+	t.Parallel()
+	target := testTransformUI(100, 50)
+	tr := NewTransform2D()
+	tr.Origin[0] = TransformLength{}
+	if !tr.IsIdentity() {
+		t.Fatal("moving the origin alone should not transform the element")
+	}
+	target.SetTransform2D(tr)
+	if _, ok := target.RenderTransform(); ok {
+		t.Fatal("an identity transform should not be applied")
+	}
+	got, ok := target.Transform2D()
+	if !ok || got.Origin[0] != (TransformLength{}) {
+		t.Fatal("the origin should be kept for the other transform properties")
+	}
+	target.ClearTransform2D()
+	if _, ok := target.Transform2D(); ok {
+		t.Fatal("the transform should be cleared")
+	}
+}
+
+func TestTransform2DHitTestAndScissor(t *testing.T) {
+	t.Parallel()
+	target := testTransformUI(100, 50)
+	cursor := matrix.NewVec2(0, 40)
+	if target.entity.Transform.ContainsPoint2D(target.untransformPoint(cursor)) {
+		t.Fatal("the point is above the untransformed box")
+	}
+	target.SetTransform2D(rotated90())
+	if !target.entity.Transform.ContainsPoint2D(target.untransformPoint(cursor)) {
+		t.Fatal("the point is within the rotated box")
+	}
+	s := target.selfScissor()
+	if !matrix.Vec4ApproxTo(s, matrix.Vec4{-25, -50, 25, 50}, 0.001) {
+		t.Fatalf("rotated scissor = %v, want [-25 -50 25 50]", s)
+	}
+}
+
+func TestTransform2DAppliesToChildren(t *testing.T) {
+	t.Parallel()
+	parent := testTransformUI(100, 50)
+	child := testTransformUI(10, 10)
+	child.entity.SetParent(&parent.entity)
+	tr := NewTransform2D()
+	tr.Translate[0] = TransformLength{Value: 10}
+	parent.SetTransform2D(tr)
+	m, ok := child.RenderTransform()
+	if !ok {
+		t.Fatal("the child should be moved with its parent")
+	}
+	if got := m.TransformPoint(matrix.Vec3Zero()); !matrix.Vec3ApproxTo(got, matrix.NewVec3(10, 0, 0), 0.001) {
+		t.Fatalf("child translation = %v, want [10 0 0]", got)
+	}
+	parent.ClearTransform2D()
+	if _, ok := child.RenderTransform(); ok {
+		t.Fatal("the child should no longer be moved")
+	}
+}
@@ -21,6 +21,7 @@ type RenderPass string
 type ShaderPipeline string
 type Shader string
 type Sound string
+type StringTable string
 type TableOfContents string
 type Template string
 type Terrain string
@@ -39,6 +40,7 @@ func init() {
 	pod.Register(ShaderPipeline(""))
 	pod.Register(Shader(""))
 	pod.Register(Sound(""))
+	pod.Register(StringTable(""))
 	pod.Register(TableOfContents(""))
 	pod.Register(Template(""))
 	pod.Register(Terrain(""))
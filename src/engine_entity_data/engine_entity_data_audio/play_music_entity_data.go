@@ -29,49 +29,131 @@ func MusicBindingKey() string {
 	return musicBindingKey
 }
 
+type MusicSync int
+
+const (
+	MusicSyncImmediate MusicSync = iota
+	MusicSyncBeat
+	MusicSyncBar
+	MusicSyncEnd
+)
+
 type PlayMusicEntityData struct {
-	MusicId content_id.Music
-	Loop    bool
+	MusicId         content_id.Music
+	Loop            bool
+	CueName         string  `tip:"The name scripts play the cue by, blank uses the music id"`
+	FadeSeconds     float32 `tip:"Crossfade from the music that is already playing"`
+	Sync            MusicSync
+	BPM             float32          `tip:"Tempo of the track, needed for beat and bar sync"`
+	BeatsPerBar     int              `default:"4"`
+	FirstBeatOffset float32          `tip:"Seconds into the track of the first beat"`
+	LayerId1        content_id.Music `tip:"A stem played aligned with the music"`
+	LayerIntensity1 float32          `default:"1" tip:"Music intensity the layer is heard at"`
+	LayerId2        content_id.Music
+	LayerIntensity2 float32 `default:"2"`
+	LayerId3        content_id.Music
+	LayerIntensity3 float32 `default:"3"`
 }
 
+// MusicPlayer is the music cue of the entity, it is added to the entity
+// under [MusicBindingKey]
 type MusicPlayer struct {
-	host   weak.Pointer[engine.Host]
-	Clip   *audio.AudioClip
-	Handle audio.VoiceHandle
+	host       weak.Pointer[engine.Host]
+	Cue        string
+	Clip       *audio.AudioClip
+	Transition audio.Transition
+}
+
+func (s MusicSync) mode() audio.SyncMode {
+	switch s {
+	case MusicSyncBeat:
+		return audio.SyncBeat
+	case MusicSyncBar:
+		return audio.SyncBar
+	case MusicSyncEnd:
+		return audio.SyncEnd
+	default:
+		return audio.SyncImmediate
+	}
+}
+
+func (c PlayMusicEntityData) cue() audio.MusicCue {
+	cue := audio.MusicCue{
+		Name:        c.CueName,
+		Stems:       []audio.MusicStem{{Key: string(c.MusicId)}},
+		BPM:         c.BPM,
+		BeatsPerBar: c.BeatsPerBar,
+		Offset:      float64(c.FirstBeatOffset),
+		Loop:        c.Loop,
+	}
+	if cue.Name == "" {
+		cue.Name = string(c.MusicId)
+	}
+	layers := []struct {
+		id        content_id.Music
+		intensity float32
+	}{
+		{c.LayerId1, c.LayerIntensity1},
+		{c.LayerId2, c.LayerIntensity2},
+		{c.LayerId3, c.LayerIntensity3},
+	}
+	for _, l := range layers {
+		if l.id != "" {
+			cue.Stems = append(cue.Stems, audio.MusicStem{Key: string(l.id), Intensity: l.intensity})
+		}
+	}
+	return cue
 }
 
 func (c PlayMusicEntityData) Init(e *engine.Entity, host *engine.Host) {
 	adb := host.AssetDatabase()
-	if !adb.Exists(string(c.MusicId)) {
-		slog.Error("the music could not be found", "id", c.MusicId)
-		return
+	cue := c.cue()
+	for _, stem := range cue.Stems {
+		if !adb.Exists(stem.Key) {
+			slog.Error("the music could not be found", "id", stem.Key)
+			return
+		}
 	}
 	a := host.Audio()
-	clip, err := a.LoadMusic(adb, string(c.MusicId))
-	if err != nil {
-		slog.Error("failed to load the music clip", "id", c.MusicId, "error", err)
+	music := a.Music()
+	if err := music.RegisterCue(adb, cue); err != nil {
+		slog.Error("failed to load the music cue", "cue", cue.Name, "error", err)
 		return
 	}
+	clip, _ := a.MusicById(string(c.MusicId))
 	player := &MusicPlayer{
-		host:   weak.Make(host),
-		Clip:   clip,
-		Handle: a.Play(clip),
-	}
-	if c.Loop {
-		a.SetLooping(player.Handle, c.Loop)
+		host: weak.Make(host),
+		Cue:  cue.Name,
+		Clip: clip,
+		Transition: audio.Transition{
+			Fade: float64(c.FadeSeconds),
+			Sync: c.Sync.mode(),
+		},
 	}
+	player.Play()
 	e.AddNamedData(MusicBindingKey(), player)
 	e.OnDestroy.Add(player.Stop)
 }
 
-func (p *MusicPlayer) Stop() {
+// Play transitions to the cue of the player
+func (p *MusicPlayer) Play() {
 	host := p.host.Value()
 	if host == nil {
 		return
 	}
-	a := host.Audio()
-	if !a.IsValidVoiceHandle(p.Handle) {
+	if err := host.Audio().Music().Play(p.Cue, p.Transition); err != nil {
+		slog.Error("failed to play the music cue", "cue", p.Cue, "error", err)
+	}
+}
+
+// Stop fades out the cue of the player if it is the music that is playing
+func (p *MusicPlayer) Stop() {
+	host := p.host.Value()
+	if host == nil {
 		return
 	}
-	a.Stop(p.Handle)
+	music := host.Audio().Music()
+	if music.Current() == p.Cue {
+		music.Stop(p.Transition.Fade)
+	}
 }
@@ -9,14 +9,26 @@ package engine_entity_data_audio
 import (
 	"log/slog"
 	"time"
+	"weak"
 
 	"kaijuengine.com/engine"
 	"kaijuengine.com/engine/encoding/pod"
 	"kaijuengine.com/engine_entity_data/content_id"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/audio"
 )
 
 var soundBindingKey = ""
 
+type SoundAttenuation int
+
+const (
+	SoundAttenuationInverseDistance SoundAttenuation = iota
+	SoundAttenuationLinearDistance
+	SoundAttenuationExponentialDistance
+	SoundAttenuationNone
+)
+
 func init() {
 	engine.RegisterEntityData(PlaySoundEntityData{})
 }
@@ -29,8 +41,62 @@ func SoundBindingKey() string {
 }
 
 type PlaySoundEntityData struct {
-	SoundId      content_id.Sound
-	DelaySeconds float32
+	SoundId         content_id.Sound
+	DelaySeconds    float32
+	Spatial         bool    `tip:"Play from the entity's position in the world"`
+	MinDistance     float32 `default:"1" tip:"Full volume inside this distance"`
+	MaxDistance     float32 `default:"100" tip:"No more falloff past this distance"`
+	Attenuation     SoundAttenuation
+	Rolloff         float32 `default:"1"`
+	DopplerFactor   float32 `default:"1" tip:"0 = no doppler"`
+	ConeInnerAngle  float32 `default:"360" tip:"Full volume inside this angle from forward"`
+	ConeOuterAngle  float32 `default:"360"`
+	ConeOuterVolume float32 `default:"0" tip:"Volume outside of the outer angle"`
+}
+
+// SoundEmitter keeps a spatial sound at the position of its entity while it
+// plays, it is added to the entity under [SoundBindingKey]
+type SoundEmitter struct {
+	host         weak.Pointer[engine.Host]
+	entity       *engine.Entity
+	Clip         *audio.AudioClip
+	Handle       audio.VoiceHandle
+	updateId     engine.UpdateId
+	lastPosition matrix.Vec3
+}
+
+func (a SoundAttenuation) model() audio.AttenuationModel {
+	switch a {
+	case SoundAttenuationLinearDistance:
+		return audio.AttenuationLinearDistance
+	case SoundAttenuationExponentialDistance:
+		return audio.AttenuationExponentialDistance
+	case SoundAttenuationNone:
+		return audio.AttenuationNone
+	default:
+		return audio.AttenuationInverseDistance
+	}
+}
+
+func (c PlaySoundEntityData) spatial() audio.Spatial {
+	s := audio.DefaultSpatial()
+	if c.MinDistance > 0 {
+		s.MinDistance = c.MinDistance
+	}
+	if c.MaxDistance > 0 {
+		s.MaxDistance = c.MaxDistance
+	}
+	if c.Rolloff > 0 {
+		s.Rolloff = c.Rolloff
+	}
+	s.Attenuation = c.Attenuation.model()
+	s.DopplerFactor = c.DopplerFactor
+	s.Cone = audio.Cone{
+		InnerAngle:  c.ConeInnerAngle,
+		OuterAngle:  c.ConeOuterAngle,
+		OuterVolume: c.ConeOuterVolume,
+	}
+	return s
 }
 
 func (c PlaySoundEntityData) Init(e *engine.Entity, host *engine.Host) {
@@ -45,12 +111,71 @@ func (c PlaySoundEntityData) Init(e *engine.Entity, host *engine.Host) {
 		slog.Error("failed to load the sound clip", "id", c.SoundId, "error", err)
 		return
 	}
+	play := func() { a.Play(clip) }
+	if c.Spatial {
+		emitter := &SoundEmitter{host: weak.Make(host), entity: e, Clip: clip}
+		e.AddNamedData(SoundBindingKey(), emitter)
+		e.OnDestroy.Add(emitter.Stop)
+		spatial := c.spatial()
+		play = func() { emitter.play(spatial) }
+	}
 	if c.DelaySeconds <= 0 {
-		a.Play(clip)
+		play()
 	} else {
 		ms := c.DelaySeconds * 1000
-		host.RunAfterTime(time.Millisecond*time.Duration(ms), func() {
-			a.Play(clip)
-		})
+		host.RunAfterTime(time.Millisecond*time.Duration(ms), play)
+	}
+}
+
+func (s *SoundEmitter) voice(deltaTime float64) audio.Voice3D {
+	pos := s.entity.Transform.WorldPosition()
+	forward := s.entity.Transform.WorldMatrix().Forward().Normal()
+	v := audio.Voice3D{Position: pos, Forward: forward}
+	if deltaTime > 0 {
+		v.Velocity = pos.Subtract(s.lastPosition).Scale(matrix.Float(1 / deltaTime))
+	}
+	s.lastPosition = pos
+	return v
+}
+
+func (s *SoundEmitter) play(spatial audio.Spatial) {
+	host := s.host.Value()
+	if host == nil || s.entity.IsDestroyed() {
+		return
+	}
+	s.Handle = host.Audio().Play3D(s.Clip, s.voice(0), spatial)
+	s.updateId = host.LateUpdater.AddUpdate(s.update)
+}
+
+func (s *SoundEmitter) update(deltaTime float64) {
+	host := s.host.Value()
+	if host == nil {
+		return
+	}
+	a := host.Audio()
+	if !a.IsValidVoiceHandle(s.Handle) {
+		// Updates can't be removed from within the concurrent update
+		host.RunOnMainThread(s.stopUpdating)
+		return
+	}
+	a.SetVoice3D(s.Handle, s.voice(deltaTime))
+}
+
+func (s *SoundEmitter) stopUpdating() {
+	if host := s.host.Value(); host != nil && s.updateId.IsValid() {
+		host.LateUpdater.RemoveUpdate(&s.updateId)
+	}
+}
+
+// Stop stops the sound and stops following the entity
+func (s *SoundEmitter) Stop() {
+	host := s.host.Value()
+	if host == nil {
+		return
+	}
+	s.stopUpdating()
+	a := host.Audio()
+	if s.Handle != audio.InvalidVoiceHandle && a.IsValidVoiceHandle(s.Handle) {
+		a.Stop(s.Handle)
 	}
 }
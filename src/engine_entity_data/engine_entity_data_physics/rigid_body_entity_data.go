@@ -46,13 +46,14 @@ func BindingKey() string {
 }
 
 type RigidBodyEntityData struct {
-	AssetKey content_id.Mesh
-	Extent   matrix.Vec3 `default:"1,1,1"`
-	Mass     float32     `default:"1"`
-	Radius   float32     `default:"1"`
-	Height   float32     `default:"1"`
-	Shape    Shape
-	IsStatic bool
+	AssetKey  content_id.Mesh
+	Extent    matrix.Vec3 `default:"1,1,1"`
+	Mass      float32     `default:"1"`
+	Radius    float32     `default:"1"`
+	Height    float32     `default:"1"`
+	Shape     Shape
+	IsStatic  bool
+	IsTrigger bool // Reports contacts through trigger events without resolving them.
 }
 
 func (r RigidBodyEntityData) Init(e *engine.Entity, host *engine.Host) {
@@ -65,6 +66,13 @@ func (r RigidBodyEntityData) EntityDataInitPhase() engine.EntityDataPhase {
 	return engine.EntityDataPhasePhysicsBody
 }
 
+// ContactEvents returns the collision and trigger events for the entity's
+// rigid body. It can be called from any entity data Init regardless of the
+// order in which the rigid body is added to the physics system.
+func ContactEvents(e *engine.Entity, host *engine.Host) *engine.PhysicsContactEvents {
+	return host.Physics().ContactEvents(e)
+}
+
 func (r RigidBodyEntityData) gravitonRigidBody(e *engine.Entity, host *engine.Host) *graviton.RigidBody {
 	body := &graviton.RigidBody{}
 	body.Transform.SetupRawTransform()
@@ -80,6 +88,7 @@ func (r RigidBodyEntityData) gravitonRigidBody(e *engine.Entity, host *engine.Ho
 	default:
 		body.SetShape(shape)
 	}
+	body.Collision.IsTrigger = r.IsTrigger
 	// Scale is baked into the shape dimensions to match the existing behavior.
 	if r.IsStatic {
 		body.SetStatic()
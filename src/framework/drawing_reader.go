@@ -194,7 +194,6 @@ func CreateDrawingsPBR(host *engine.Host, res load_result.Result) (ModelDrawingS
 			ShaderDataBase: rendering.NewShaderDataBase(),
 			VertColors:     matrix.ColorWhite(),
 			MeRoEmAo:       matrix.NewVec4(1, 1, 0, 1),
-			LightIds:       [...]int32{-1, -1, -1, -1},
 		}
 	})
 	for i := range drawings {
@@ -18,10 +18,18 @@ import (
 	"strings"
 
 	"kaijuengine.com/klib"
+	"kaijuengine.com/rendering"
 )
 
 const binDir = "../tools/content_tools/"
 
+// binHeaderSize is the glyph count, atlas size and metrics at the start of
+// the .bin file and binGlyphSize is the size of each glyph that follows it
+const (
+	binHeaderSize = 4 + 4 + 4 + 6*4
+	binGlyphSize  = 4 + 4 + 4*4 + 4*4
+)
+
 type Rect struct {
 	Left   float32 `json:"left"`
 	Top    float32 `json:"top"`
@@ -82,6 +90,57 @@ func msdfAtlasGenPath() string {
 	return exePath
 }
 
+// sourceKerning reads the kerning between the glyphs out of the TTF file.
+// msdf-atlas-gen only reads the kern table, most fonts only have their
+// kerning in the GPOS table, so the kerning it writes is replaced with this.
+func sourceKerning(ttfFile string, unicodes []rune) []Kerning {
+	data := klib.MustReturn(os.ReadFile(ttfFile))
+	pairs := klib.MustReturn(rendering.FontSourceKerning(data, unicodes))
+	kerning := make([]Kerning, len(pairs))
+	for i := range pairs {
+		kerning[i] = Kerning{
+			Unicode1: int32(pairs[i].Left),
+			Unicode2: int32(pairs[i].Right),
+			Advance:  pairs[i].Advance,
+		}
+	}
+	return kerning
+}
+
+func writeKerning(fout *os.File, kerning []Kerning) {
+	binary.Write(fout, binary.LittleEndian, int32(len(kerning)))
+	for _, kern := range kerning {
+		binary.Write(fout, binary.LittleEndian, kern.Unicode1)
+		binary.Write(fout, binary.LittleEndian, kern.Unicode2)
+		binary.Write(fout, binary.LittleEndian, kern.Advance)
+	}
+}
+
+// rebakeKerning replaces the kerning at the end of an already baked .bin file
+// with the kerning of the TTF file, the glyphs and atlas are left untouched
+func rebakeKerning(binFile, ttfFile string) {
+	println("Re-baking the kerning of", binFile)
+	data := klib.MustReturn(os.ReadFile(binFile))
+	if len(data) < binHeaderSize {
+		panic("the font .bin file is too small to have a header")
+	}
+	count := int(binary.LittleEndian.Uint32(data))
+	end := binHeaderSize + count*binGlyphSize
+	if len(data) < end {
+		panic("the font .bin file is missing glyphs")
+	}
+	unicodes := make([]rune, count)
+	for i := range count {
+		unicodes[i] = rune(binary.LittleEndian.Uint32(data[binHeaderSize+i*binGlyphSize:]))
+	}
+	kerning := sourceKerning(ttfFile, unicodes)
+	fout := klib.MustReturn(os.Create(binFile))
+	defer fout.Close()
+	klib.MustReturn(fout.Write(data[:end]))
+	writeKerning(fout, kerning)
+	println("Wrote", len(kerning), "kerning pairs")
+}
+
 func processFile(ttfName string) {
 	println("Processing", ttfName)
 
@@ -139,6 +198,14 @@ func processFile(ttfName string) {
 		binary.Write(fout, binary.LittleEndian, glyph.AtlasBounds.Right)
 		binary.Write(fout, binary.LittleEndian, glyph.AtlasBounds.Bottom)
 	}
+	unicodes := make([]rune, len(fontData.Glyphs))
+	for i := range fontData.Glyphs {
+		unicodes[i] = rune(fontData.Glyphs[i].Unicode)
+	}
+	if kerning := sourceKerning(ttfFile, unicodes); len(kerning) > 0 {
+		fontData.Kerning = kerning
+	}
+	writeKerning(fout, fontData.Kerning)
 	os.Remove(jsonFile)
 }
 
@@ -146,6 +213,14 @@ func main() {
 	if len(os.Args) == 1 {
 		panic("Expected the first argument to be the TTF file to convert")
 	}
+	// -kerning <bin> <ttf> only re-bakes the kerning of an existing font
+	if os.Args[1] == "-kerning" {
+		if len(os.Args) != 4 {
+			panic("Expected -kerning to be followed by the .bin file and the TTF file")
+		}
+		rebakeKerning(os.Args[2], os.Args[3])
+		return
+	}
 	ttfName := os.Args[1]
 	dirName := filepath.Join(binDir, ttfName)
 	if s, err := os.Stat(dirName); err != nil {
@@ -7,6 +7,7 @@
 package integration_testing
 
 import (
+	"image"
 	"log/slog"
 	"os"
 
@@ -32,7 +33,7 @@ func IntegrationTestDirectionalShadowGate(host *engine.Host) {
 	host.RunAfterFrames(2, func() {
 		// Add the light after the PBR drawing has already rendered without one.
 		// This matches the editor workflow of importing/assigning a material and
-		// then spawning a light, and verifies that instance light IDs refresh.
+		// then spawning a light, and verifies that the new light is clustered.
 		lightEntity := engine.NewEntity(host.WorkGroup())
 		light := rendering.NewLight(host.Window.GpuInstance.PrimaryDevice(),
 			host.AssetDatabase(), host.MaterialCache(), rendering.LightTypeDirectional)
@@ -51,37 +52,47 @@ func IntegrationTestDirectionalShadowGate(host *engine.Host) {
 
 // IntegrationTestDirectionalLightBeforeDrawing matches stage reload ordering:
 // an existing light has already rendered before a deferred mesh drawing is
-// attached. The new PBR instance must select that light even though the light
-// collection's change flag has already been consumed.
+// attached. The new PBR drawing must be lit by that light even though the
+// light collection's change flag has already been consumed.
 func IntegrationTestDirectionalLightBeforeDrawing(host *engine.Host) {
 	lightEntity := engine.NewEntity(host.WorkGroup())
 	light := rendering.NewLight(host.Window.GpuInstance.PrimaryDevice(),
 		host.AssetDatabase(), host.MaterialCache(), rendering.LightTypeDirectional)
 	light.SetDirection(matrix.NewVec3(-0.5, -1, -0.5).Normal())
 	host.Lighting().Lights.Add(&lightEntity.Transform, light)
-	drawing, shaderData := newPBRTestSphereDrawing(host)
+	drawing := newPBRTestSphereDrawing(host)
 
 	host.RunAfterFrames(3, func() {
 		host.Drawings.AddDrawing(drawing)
 	})
 	host.RunAfterFrames(30, func() {
-		if shaderData.LightIds[0] != 0 {
-			takeScreenshotToFile(host, "integration_directional_light_before_drawing.png")
-			slog.Error("PBR drawing did not select the preexisting light",
-				"lightId", shaderData.LightIds[0])
+		const output = "integration_directional_light_before_drawing.png"
+		img, err := captureScreenshotImage(host)
+		if err != nil {
+			slog.Error("Failed to capture the screenshot", "error", err)
+			os.Exit(1)
+		}
+		if err = writeScreenshotImage(img, output); err != nil {
+			slog.Error("Failed to write the screenshot file", "path", output, "error", err)
+		}
+		// Only ambient light reaches an unlit sphere, which is too dark to
+		// count as saturated
+		center := img.Bounds().Size().Div(2)
+		rect := image.Rect(center.X-32, center.Y-32, center.X+32, center.Y+32)
+		if countSaturatedPixels(img, rect) == 0 {
+			slog.Error("PBR drawing was not lit by the preexisting light")
 			os.Exit(1)
 		}
-		takeScreenshotToFile(host, "integration_directional_light_before_drawing.png")
 		os.Exit(0)
 	})
 }
 
 func addPBRTestSphere(host *engine.Host) {
-	drawing, _ := newPBRTestSphereDrawing(host)
+	drawing := newPBRTestSphereDrawing(host)
 	host.Drawings.AddDrawing(drawing)
 }
 
-func newPBRTestSphereDrawing(host *engine.Host) (rendering.Drawing, *shader_data_registry.ShaderDataPBR) {
+func newPBRTestSphereDrawing(host *engine.Host) rendering.Drawing {
 	sphere := rendering.NewMeshSphere(host.MeshCache(), 1, 32, 32)
 	ball := engine.NewEntity(host.WorkGroup())
 	shaderData := shader_data_registry.Create("pbr").(*shader_data_registry.ShaderDataPBR)
@@ -109,5 +120,5 @@ func newPBRTestSphereDrawing(host *engine.Host) (rendering.Drawing, *shader_data
 		ShaderData: shaderData,
 		Transform:  &ball.Transform,
 		ViewCuller: &host.Cameras.Primary,
-	}, shaderData
+	}
 }
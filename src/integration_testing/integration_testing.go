@@ -9,18 +9,49 @@ package integration_testing
 import (
 	"fmt"
 	"reflect"
+	"slices"
 
 	"kaijuengine.com/engine"
+	"kaijuengine.com/rendering"
 )
 
 var tests = map[string]func(host *engine.Host){}
 
+// vulkanTests are the tests that can't run on the null backend, they read back
+// GPU picking, shadows, selection outlines, render targets or video frames that
+// the software rasterizer doesn't draw. Every other test runs headless unless
+// the -gpu launch parameter selects a backend.
+var vulkanTests = []string{
+	"directional-light-before-drawing",
+	"directional-shadow-gate",
+	"gpu-picking-visible-pixels",
+	"render-view-modes",
+	"render_graph_default",
+	"render_graph_target_ui",
+	"selection-outline-occluder",
+	"selection-outline-selected-pair",
+	"stage-multi-viewports",
+	"stage-viewport-ui",
+	"stage-workspace-render-targets",
+	"video",
+}
+
+// testGPUBackend is the backend the test renders with when it isn't selected
+// on the command line
+func testGPUBackend(testName string) rendering.GPUBackend {
+	if slices.Contains(vulkanTests, testName) {
+		return rendering.GPUBackendVulkan
+	}
+	return rendering.GPUBackendNull
+}
+
 type IntegrationGame struct {
 	test func(host *engine.Host)
 }
 
 func IntegrationTestGame(testName string) (*IntegrationGame, error) {
 	if test, ok := tests[testName]; ok {
+		engine.DefaultGPUBackend = testGPUBackend(testName)
 		return &IntegrationGame{test: test}, nil
 	}
 	return nil, fmt.Errorf("could not find test named %s, perhaps you forgot to build the executable", testName)
@@ -28,4 +59,10 @@ func IntegrationTestGame(testName string) (*IntegrationGame, error) {
 
 func (IntegrationGame) PluginRegistry() []reflect.Type { return []reflect.Type{} }
 
-func (g *IntegrationGame) Launch(host *engine.Host) { g.test(host) }
+func (g *IntegrationGame) Launch(host *engine.Host) {
+	// Screenshots of headless tests are drawn by the software rasterizer
+	if host.IsHeadless() {
+		host.EnableSoftwareRasterizer()
+	}
+	g.test(host)
+}
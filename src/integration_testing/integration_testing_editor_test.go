@@ -0,0 +1,20 @@
+//go:build editor
+
+/******************************************************************************/
+/* integration_testing_editor_test.go                                         */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package integration_testing
+
+import "testing"
+
+// The editor build has every test, so each of the Vulkan tests must exist
+func TestVulkanTestsAreRegistered(t *testing.T) {
+	for _, name := range vulkanTests {
+		if _, ok := tests[name]; !ok {
+			t.Errorf("%q is listed as a Vulkan test but no test has that name", name)
+		}
+	}
+}
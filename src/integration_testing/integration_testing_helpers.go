@@ -44,6 +44,13 @@ func takeScreenshotToFile(host *engine.Host, path string) {
 }
 
 func captureScreenshotImage(host *engine.Host) (*image.RGBA, error) {
+	if host.IsHeadless() {
+		img := host.RasterizedFrame()
+		if img == nil {
+			return nil, fmt.Errorf("the software rasterizer has not drawn a frame yet")
+		}
+		return img, nil
+	}
 	var pixels []byte
 	var width, height int
 	err := fmt.Errorf("cannot capture screenshot without a valid renderer")
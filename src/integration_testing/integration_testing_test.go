@@ -0,0 +1,31 @@
+/******************************************************************************/
+/* integration_testing_test.go                                                */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package integration_testing
+
+import (
+	"testing"
+
+	"kaijuengine.com/engine"
+	"kaijuengine.com/rendering"
+)
+
+func TestIntegrationTestsRunHeadless(t *testing.T) {
+	restore := engine.DefaultGPUBackend
+	t.Cleanup(func() { engine.DefaultGPUBackend = restore })
+	if _, err := IntegrationTestGame("screenshot"); err != nil {
+		t.Fatal(err)
+	}
+	if engine.DefaultGPUBackend != rendering.GPUBackendNull {
+		t.Errorf("expected the screenshot test to run on the null backend, got %v", engine.DefaultGPUBackend)
+	}
+	if _, err := IntegrationTestGame("directional-shadow-gate"); err != nil {
+		t.Fatal(err)
+	}
+	if engine.DefaultGPUBackend != rendering.GPUBackendVulkan {
+		t.Errorf("expected the shadow test to run on Vulkan, got %v", engine.DefaultGPUBackend)
+	}
+}
@@ -0,0 +1,223 @@
+/******************************************************************************/
+/* catalog.go                                                                 */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package localization
+
+import (
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/text/language"
+	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/engine/systems/events"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// StringTablesAssetKey is the asset listing the keys of the string tables
+// that [Catalog.LoadListed] loads, one key per line. The editor writes it
+// with every string table of the project when packaging the content.
+const StringTablesAssetKey = "stringTables"
+
+// Catalog holds the string tables of every language and looks up strings
+// in the selected language. A string that is missing from the language is
+// looked up through the fallback chain, which is the language's parents
+// ("pt-BR" then "pt"), the fallbacks set with [Catalog.SetFallbacks] and
+// then American English. A key that isn't found anywhere is returned as is.
+type Catalog struct {
+	// OnLanguageChanged is called after [Catalog.SetLanguage] changes the
+	// language, things showing localized text use it to update
+	OnLanguageChanged events.Event
+	tables            map[string]map[string]string
+	messages          map[messageKey]Message
+	language          string
+	fallbacks         []string
+	chain             []string
+	mutex             sync.RWMutex
+}
+
+type messageKey struct {
+	language string
+	key      string
+}
+
+// NewCatalog creates an empty catalog for the language, a blank language
+// uses the user's language, see [String]
+func NewCatalog(lang string) *Catalog {
+	if lang == "" {
+		lang = String()
+	}
+	c := &Catalog{
+		tables:   map[string]map[string]string{},
+		messages: map[messageKey]Message{},
+		language: normalizeLocalization(lang),
+	}
+	c.chain = c.buildChain()
+	return c
+}
+
+// Language is the selected language as a canonical language tag
+func (c *Catalog) Language() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.language
+}
+
+// Languages are the languages that have strings in the catalog
+func (c *Catalog) Languages() []string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return slices.Sorted(maps.Keys(c.tables))
+}
+
+// SetLanguage selects the language strings are looked up in and calls
+// [Catalog.OnLanguageChanged] if it changed
+func (c *Catalog) SetLanguage(lang string) {
+	lang = normalizeLocalization(lang)
+	c.mutex.Lock()
+	if c.language == lang {
+		c.mutex.Unlock()
+		return
+	}
+	c.language = lang
+	c.chain = c.buildChain()
+	c.mutex.Unlock()
+	c.OnLanguageChanged.Execute()
+}
+
+// SetFallbacks sets the languages that are tried, in order, when a string is
+// missing from the selected language and its parents
+func (c *Catalog) SetFallbacks(languages ...string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.fallbacks = c.fallbacks[:0]
+	for _, l := range languages {
+		c.fallbacks = append(c.fallbacks, normalizeLocalization(l))
+	}
+	c.chain = c.buildChain()
+}
+
+// AddTable adds the strings of the table to the catalog, replacing strings
+// of the same language that have the same key
+func (c *Catalog) AddTable(table StringTable) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	lang := normalizeLocalization(table.Language)
+	strs, ok := c.tables[lang]
+	if !ok {
+		strs = map[string]string{}
+		c.tables[lang] = strs
+	}
+	for k, v := range table.Strings {
+		strs[k] = v
+		delete(c.messages, messageKey{lang, k})
+	}
+}
+
+// Load reads the string tables of the asset and adds them to the catalog,
+// see [ParseStringTables] for the formats
+func (c *Catalog) Load(adb assets.Database, key string) error {
+	defer tracing.NewRegion("Catalog.Load").End()
+	data, err := adb.Read(key)
+	if err != nil {
+		return err
+	}
+	tables, err := ParseStringTables(key, data)
+	if err != nil {
+		return err
+	}
+	for i := range tables {
+		c.AddTable(tables[i])
+	}
+	return nil
+}
+
+// LoadListed loads every string table listed in [StringTablesAssetKey], it
+// does nothing if the asset database has no list. A table that fails to load
+// is logged and skipped.
+func (c *Catalog) LoadListed(adb assets.Database) error {
+	defer tracing.NewRegion("Catalog.LoadListed").End()
+	if !adb.Exists(StringTablesAssetKey) {
+		return nil
+	}
+	list, err := adb.ReadText(StringTablesAssetKey)
+	if err != nil {
+		return err
+	}
+	for _, key := range strings.Fields(list) {
+		if err := c.Load(adb, key); err != nil {
+			slog.Error("failed to load the string table", "key", key, "error", err)
+		}
+	}
+	return nil
+}
+
+// Has returns true if the key is in the selected language or one of its
+// fallbacks
+func (c *Catalog) Has(key string) bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	_, _, ok := c.lookup(key)
+	return ok
+}
+
+// Get returns the string for the key in the selected language
+func (c *Catalog) Get(key string) string {
+	return c.Format(key, nil)
+}
+
+// Format returns the string for the key in the selected language with the
+// arguments filled in, see [Message] for the syntax of the strings
+func (c *Catalog) Format(key string, args map[string]any) string {
+	c.mutex.RLock()
+	lang, text, ok := c.lookup(key)
+	msg, parsed := c.messages[messageKey{lang, key}]
+	c.mutex.RUnlock()
+	if !ok {
+		return key
+	}
+	if !parsed {
+		var err error
+		if msg, err = ParseMessage(text); err != nil {
+			slog.Error("failed to parse the localized string", "key", key, "language", lang, "error", err)
+			return text
+		}
+		c.mutex.Lock()
+		c.messages[messageKey{lang, key}] = msg
+		c.mutex.Unlock()
+	}
+	return msg.Format(lang, args)
+}
+
+// lookup finds the text of the key along the fallback chain, the returned
+// language is the one the text was found in
+func (c *Catalog) lookup(key string) (string, string, bool) {
+	for _, lang := range c.chain {
+		if text, ok := c.tables[lang][key]; ok {
+			return lang, text, true
+		}
+	}
+	return "", "", false
+}
+
+func (c *Catalog) buildChain() []string {
+	chain := []string{}
+	add := func(lang string) {
+		for tag := language.Make(lang); tag != language.Und; tag = tag.Parent() {
+			if s := tag.String(); !slices.Contains(chain, s) {
+				chain = append(chain, s)
+			}
+		}
+	}
+	add(c.language)
+	for _, l := range c.fallbacks {
+		add(l)
+	}
+	add(defaultLocalization)
+	return chain
+}
@@ -0,0 +1,96 @@
+/******************************************************************************/
+/* catalog_test.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package localization
+
+import (
+	"slices"
+	"testing"
+
+	"kaijuengine.com/engine/assets"
+)
+
+func testCatalog() *Catalog {
+	c := NewCatalog("pt-BR")
+	c.AddTable(StringTable{Language: "en-US", Strings: map[string]string{
+		"menu.play":  "Play",
+		"menu.quit":  "Quit",
+		"menu.extra": "Extras",
+		"lives":      "{n, plural, one {# life} other {# lives}}",
+	}})
+	c.AddTable(StringTable{Language: "pt", Strings: map[string]string{
+		"menu.play": "Jogar",
+		"lives":     "{n, plural, one {# vida} other {# vidas}}",
+	}})
+	c.AddTable(StringTable{Language: "pt-BR", Strings: map[string]string{
+		"menu.play": "Jogar!",
+	}})
+	c.AddTable(StringTable{Language: "es", Strings: map[string]string{
+		"menu.quit": "Salir",
+	}})
+	return c
+}
+
+func TestCatalogFallbackChain(t *testing.T) {
+	c := testCatalog()
+	if got := c.Get("menu.play"); got != "Jogar!" {
+		t.Errorf("menu.play = %q, want the pt-BR string", got)
+	}
+	if got := c.Format("lives", map[string]any{"n": 0}); got != "0 vida" {
+		t.Errorf("lives = %q, want the pt string with the pt-BR plural rule", got)
+	}
+	if got := c.Get("menu.quit"); got != "Quit" {
+		t.Errorf("menu.quit = %q, want the en-US string", got)
+	}
+	c.SetFallbacks("es")
+	if got := c.Get("menu.quit"); got != "Salir" {
+		t.Errorf("menu.quit = %q, want the es fallback", got)
+	}
+	if got := c.Get("missing.key"); got != "missing.key" || c.Has("missing.key") {
+		t.Errorf("a missing key should be returned as is, got %q", got)
+	}
+	if langs := c.Languages(); !slices.Equal(langs, []string{"en-US", "es", "pt", "pt-BR"}) {
+		t.Errorf("languages = %v", langs)
+	}
+}
+
+func TestCatalogSetLanguage(t *testing.T) {
+	c := testCatalog()
+	calls := 0
+	c.OnLanguageChanged.Add(func() { calls++ })
+	c.SetLanguage("en_US.UTF-8")
+	if c.Language() != "en-US" || calls != 1 {
+		t.Fatalf("language = %q after %d calls", c.Language(), calls)
+	}
+	c.SetLanguage("en-US")
+	if calls != 1 {
+		t.Error("setting the same language shouldn't call OnLanguageChanged")
+	}
+	if got := c.Format("lives", map[string]any{"n": 1}); got != "1 life" {
+		t.Errorf("lives = %q", got)
+	}
+	c.AddTable(StringTable{Language: "en-US", Strings: map[string]string{"lives": "{n} lives"}})
+	if got := c.Format("lives", map[string]any{"n": 1}); got != "1 lives" {
+		t.Errorf("replaced string = %q", got)
+	}
+}
+
+func TestCatalogLoadListed(t *testing.T) {
+	c := NewCatalog("fr")
+	if err := c.LoadListed(assets.NewMockDB(map[string][]byte{})); err != nil {
+		t.Fatalf("expected no error without a list, got %v", err)
+	}
+	adb := assets.NewMockDB(map[string][]byte{
+		StringTablesAssetKey: []byte("menu\nmissing\n"),
+		"menu":               []byte(`[{"language":"fr","strings":{"menu.play":"Jouer"}}]`),
+	})
+	if err := c.LoadListed(adb); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Get("menu.play"); got != "Jouer" {
+		t.Errorf("menu.play = %q, want the listed table to be loaded", got)
+	}
+}
@@ -0,0 +1,393 @@
+/******************************************************************************/
+/* message.go                                                                 */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package localization
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Message is a parsed ICU message format string, such as:
+//
+//	Hello {name}!
+//	{count, plural, =0 {No items} one {# item} other {# items}}
+//	{gender, select, female {She} male {He} other {They}} joined
+//	{score, number} points, {ratio, number, percent} done
+//
+// Plural messages use the CLDR plural rules of the language, and # inside of
+// a plural form is the number. Text in single quotes is literal, so braces
+// can be written as '{' and '}', and two single quotes in a row are one.
+type Message struct {
+	parts []messagePart
+}
+
+type messageArgKind uint8
+
+const (
+	messageArgSimple messageArgKind = iota
+	messageArgNumber
+	messageArgPlural
+	messageArgSelect
+)
+
+type messagePart struct {
+	text  string
+	arg   string
+	kind  messageArgKind
+	style string
+	// pound is the # of a plural form
+	pound   bool
+	offset  float64
+	options []messageOption
+}
+
+type messageOption struct {
+	// key is a plural category, a select value or =N for an exact number
+	key     string
+	message Message
+}
+
+// ParseMessage parses an ICU message format string
+func ParseMessage(text string) (Message, error) {
+	p := messageParser{src: []rune(text)}
+	msg, err := p.parse(0)
+	if err != nil {
+		return msg, err
+	}
+	if p.pos < len(p.src) {
+		return msg, p.errorf("unexpected '}'")
+	}
+	return msg, nil
+}
+
+type messageParser struct {
+	src []rune
+	pos int
+}
+
+func (p *messageParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("message position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *messageParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+	}
+}
+
+func (p *messageParser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		if unicode.IsSpace(r) || r == ',' || r == '{' || r == '}' {
+			break
+		}
+		p.pos++
+	}
+	return string(p.src[start:p.pos])
+}
+
+// parse reads a message until the end of the text or the '}' that closes it,
+// depth is how many plural forms the message is nested inside of
+func (p *messageParser) parse(depth int) (Message, error) {
+	msg := Message{}
+	text := strings.Builder{}
+	flush := func() {
+		if text.Len() > 0 {
+			msg.parts = append(msg.parts, messagePart{text: text.String()})
+			text.Reset()
+		}
+	}
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch {
+		case r == '\'':
+			p.pos++
+			p.quoted(&text, depth)
+		case r == '{':
+			flush()
+			part, err := p.argument(depth)
+			if err != nil {
+				return msg, err
+			}
+			msg.parts = append(msg.parts, part)
+		case r == '}':
+			flush()
+			return msg, nil
+		case r == '#' && depth > 0:
+			flush()
+			msg.parts = append(msg.parts, messagePart{pound: true})
+			p.pos++
+		default:
+			text.WriteRune(r)
+			p.pos++
+		}
+	}
+	flush()
+	return msg, nil
+}
+
+// quoted reads the text after a single quote. A quote only starts literal
+// text when it is followed by a character that has a meaning, otherwise it
+// is an apostrophe.
+func (p *messageParser) quoted(text *strings.Builder, depth int) {
+	if p.pos >= len(p.src) {
+		text.WriteRune('\'')
+		return
+	}
+	next := p.src[p.pos]
+	if next == '\'' {
+		text.WriteRune('\'')
+		p.pos++
+		return
+	}
+	if next != '{' && next != '}' && (next != '#' || depth == 0) {
+		text.WriteRune('\'')
+		return
+	}
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		p.pos++
+		if r != '\'' {
+			text.WriteRune(r)
+		} else if p.pos < len(p.src) && p.src[p.pos] == '\'' {
+			text.WriteRune('\'')
+			p.pos++
+		} else {
+			return
+		}
+	}
+}
+
+func (p *messageParser) argument(depth int) (messagePart, error) {
+	p.pos++ // {
+	part := messagePart{arg: p.word()}
+	if part.arg == "" {
+		return part, p.errorf("missing argument name")
+	}
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return part, p.errorf("unclosed argument %q", part.arg)
+	}
+	if p.src[p.pos] == '}' {
+		p.pos++
+		return part, nil
+	}
+	if p.src[p.pos] != ',' {
+		return part, p.errorf("expected ',' after the argument %q", part.arg)
+	}
+	p.pos++
+	kind := p.word()
+	p.skipSpace()
+	switch kind {
+	case "number":
+		part.kind = messageArgNumber
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			part.style = p.word()
+			p.skipSpace()
+		}
+	case "plural", "select":
+		part.kind = messageArgPlural
+		if kind == "select" {
+			part.kind = messageArgSelect
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != ',' {
+			return part, p.errorf("expected ',' after %s", kind)
+		}
+		p.pos++
+		if err := p.options(&part, depth); err != nil {
+			return part, err
+		}
+	default:
+		return part, p.errorf("unknown argument type %q", kind)
+	}
+	if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+		return part, p.errorf("unclosed argument %q", part.arg)
+	}
+	p.pos++
+	return part, nil
+}
+
+func (p *messageParser) options(part *messagePart, depth int) error {
+	if part.kind == messageArgPlural {
+		depth++
+	}
+	hasOther := false
+	for {
+		key := p.word()
+		if key == "" {
+			break
+		}
+		if part.kind == messageArgPlural && strings.HasPrefix(key, "offset:") {
+			offset, err := strconv.ParseFloat(strings.TrimPrefix(key, "offset:"), 64)
+			if err != nil {
+				return p.errorf("invalid plural offset %q", key)
+			}
+			part.offset = offset
+			continue
+		}
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != '{' {
+			return p.errorf("expected '{' after the option %q", key)
+		}
+		p.pos++
+		msg, err := p.parse(depth)
+		if err != nil {
+			return err
+		}
+		if p.pos >= len(p.src) {
+			return p.errorf("unclosed option %q", key)
+		}
+		p.pos++ // }
+		part.options = append(part.options, messageOption{key: key, message: msg})
+		hasOther = hasOther || key == "other"
+	}
+	if !hasOther {
+		return p.errorf("the argument %q needs an 'other' option", part.arg)
+	}
+	return nil
+}
+
+// Format writes the message for the language with the arguments, arguments
+// that are missing are written as {name}
+func (m Message) Format(lang string, args map[string]any) string {
+	sb := strings.Builder{}
+	f := messageFormatter{lang: lang, printer: message.NewPrinter(language.Make(lang))}
+	f.write(&sb, m, args, nil)
+	return sb.String()
+}
+
+type messageFormatter struct {
+	lang    string
+	printer *message.Printer
+}
+
+func (f *messageFormatter) number(value any, style string) string {
+	switch style {
+	case "integer":
+		return f.printer.Sprint(number.Decimal(value, number.MaxFractionDigits(0)))
+	case "percent":
+		return f.printer.Sprint(number.Percent(value))
+	}
+	return f.printer.Sprint(number.Decimal(value))
+}
+
+// write formats the message, pound is the number that # stands for inside
+// of a plural form
+func (f *messageFormatter) write(sb *strings.Builder, m Message, args map[string]any, pound any) {
+	for i := range m.parts {
+		part := &m.parts[i]
+		switch {
+		case part.pound:
+			if pound != nil {
+				sb.WriteString(f.number(pound, ""))
+			} else {
+				sb.WriteRune('#')
+			}
+			continue
+		case part.arg == "":
+			sb.WriteString(part.text)
+			continue
+		}
+		value, ok := args[part.arg]
+		if !ok {
+			sb.WriteString("{" + part.arg + "}")
+			continue
+		}
+		switch part.kind {
+		case messageArgSimple:
+			if _, isNumber := toFloat(value); isNumber {
+				if _, isString := value.(string); !isString {
+					sb.WriteString(f.number(value, ""))
+					continue
+				}
+			}
+			sb.WriteString(fmt.Sprint(value))
+		case messageArgNumber:
+			sb.WriteString(f.number(value, part.style))
+		case messageArgSelect:
+			f.write(sb, part.selectOption(fmt.Sprint(value)), args, pound)
+		case messageArgPlural:
+			n, _ := toFloat(value)
+			msg, shown := part.pluralOption(f.lang, value, n)
+			f.write(sb, msg, args, shown)
+		}
+	}
+}
+
+func (part *messagePart) selectOption(key string) Message {
+	other := Message{}
+	for i := range part.options {
+		if part.options[i].key == key {
+			return part.options[i].message
+		} else if part.options[i].key == "other" {
+			other = part.options[i].message
+		}
+	}
+	return other
+}
+
+// pluralOption picks the plural form, exact matches (=N) compare against the
+// value and the plural category is of the value less the offset. It returns
+// the number that # stands for.
+func (part *messagePart) pluralOption(lang string, value any, n float64) (Message, any) {
+	shown := value
+	if part.offset != 0 {
+		shown = n - part.offset
+	}
+	for i := range part.options {
+		key := part.options[i].key
+		if exact, ok := strings.CutPrefix(key, "="); ok {
+			if e, err := strconv.ParseFloat(exact, 64); err == nil && e == n {
+				return part.options[i].message, shown
+			}
+		}
+	}
+	category := Plural(lang, shown).String()
+	return part.selectOption(category), shown
+}
+
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,100 @@
+/******************************************************************************/
+/* message_test.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package localization
+
+import "testing"
+
+func TestPluralRules(t *testing.T) {
+	tests := []struct {
+		lang  string
+		value any
+		want  PluralCategory
+	}{
+		{"en-US", 1, PluralOne},
+		{"en-US", 0, PluralOther},
+		{"en-US", 1.5, PluralOther},
+		{"en-US", "1.0", PluralOther},
+		{"fr", 0, PluralOne},
+		{"fr", 1.5, PluralOne},
+		{"fr", 2, PluralOther},
+		{"fr", 2000000, PluralMany},
+		{"pt-BR", 0, PluralOne},
+		{"pt-PT", 0, PluralOther},
+		{"ru", 1, PluralOne},
+		{"ru", 11, PluralMany},
+		{"ru", 21, PluralOne},
+		{"ru", 3, PluralFew},
+		{"ru", 13, PluralMany},
+		{"ru", 2.5, PluralOther},
+		{"pl", 22, PluralFew},
+		{"pl", 25, PluralMany},
+		{"cs", 3, PluralFew},
+		{"cs", 1.5, PluralMany},
+		{"ar", 0, PluralZero},
+		{"ar", 2, PluralTwo},
+		{"ar", 105, PluralFew},
+		{"ar", 111, PluralMany},
+		{"ja", 1, PluralOther},
+	}
+	for _, test := range tests {
+		if got := Plural(test.lang, test.value); got != test.want {
+			t.Errorf("Plural(%q, %v) = %v, want %v", test.lang, test.value, got, test.want)
+		}
+	}
+}
+
+func TestMessageFormat(t *testing.T) {
+	tests := []struct {
+		lang string
+		msg  string
+		args map[string]any
+		want string
+	}{
+		{"en-US", "Hello {name}!", map[string]any{"name": "Kai"}, "Hello Kai!"},
+		{"en-US", "Hello {name}!", nil, "Hello {name}!"},
+		{"en-US", "{n, plural, =0 {No items} one {# item} other {# items}}", map[string]any{"n": 0}, "No items"},
+		{"en-US", "{n, plural, =0 {No items} one {# item} other {# items}}", map[string]any{"n": 1}, "1 item"},
+		{"en-US", "{n, plural, =0 {No items} one {# item} other {# items}}", map[string]any{"n": 1200}, "1,200 items"},
+		{"de", "{n, plural, one {# Punkt} other {# Punkte}}", map[string]any{"n": 1234.5}, "1.234,5 Punkte"},
+		{"ru", "{n, plural, one {# файл} few {# файла} many {# файлов} other {# файла}}", map[string]any{"n": 22}, "22 файла"},
+		{"en-US", "{g, select, female {She} male {He} other {They}} won", map[string]any{"g": "female"}, "She won"},
+		{"en-US", "{g, select, female {She} male {He} other {They}} won", map[string]any{"g": "robot"}, "They won"},
+		{"en-US", "{n, plural, offset:1 =0 {Nobody} =1 {{host}} one {{host} and # other} other {{host} and # others}}",
+			map[string]any{"n": 3, "host": "Ana"}, "Ana and 2 others"},
+		{"en-US", "{n, plural, other {{g, select, female {her #} other {their #}}}}",
+			map[string]any{"n": 4, "g": "female"}, "her 4"},
+		{"en-US", "{p, number, percent} done", map[string]any{"p": 0.5}, "50% done"},
+		{"en-US", "{p, number, integer}", map[string]any{"p": 7.2}, "7"},
+		{"en-US", "It''s '{literal}' and it's #1", nil, "It's {literal} and it's #1"},
+	}
+	for _, test := range tests {
+		m, err := ParseMessage(test.msg)
+		if err != nil {
+			t.Errorf("ParseMessage(%q) failed: %v", test.msg, err)
+			continue
+		}
+		if got := m.Format(test.lang, test.args); got != test.want {
+			t.Errorf("%q in %s = %q, want %q", test.msg, test.lang, got, test.want)
+		}
+	}
+}
+
+func TestParseMessageErrors(t *testing.T) {
+	bad := []string{
+		"{",
+		"{}",
+		"{n, plural, one {#}}",
+		"{n, date}",
+		"{n, select, other {x}",
+		"text }",
+	}
+	for _, msg := range bad {
+		if _, err := ParseMessage(msg); err == nil {
+			t.Errorf("expected an error for %q", msg)
+		}
+	}
+}
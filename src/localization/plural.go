@@ -0,0 +1,323 @@
+/******************************************************************************/
+/* plural.go                                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package localization
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// PluralCategory is a CLDR plural category, the keywords that are used to
+// select a plural form in a message
+type PluralCategory uint8
+
+const (
+	PluralZero PluralCategory = iota
+	PluralOne
+	PluralTwo
+	PluralFew
+	PluralMany
+	PluralOther
+)
+
+var pluralCategoryNames = []string{"zero", "one", "two", "few", "many", "other"}
+
+func (p PluralCategory) String() string {
+	if int(p) < len(pluralCategoryNames) {
+		return pluralCategoryNames[p]
+	}
+	return "other"
+}
+
+// pluralOperands are the CLDR plural operands of a number as it is written,
+// so 1 and 1.0 can select different forms
+type pluralOperands struct {
+	n float64 // absolute value
+	i int64   // integer digits
+	v int     // number of visible fraction digits
+}
+
+func newPluralOperands(value float64, text string) pluralOperands {
+	op := pluralOperands{n: math.Abs(value), i: int64(math.Abs(value))}
+	if dot := strings.IndexByte(text, '.'); dot >= 0 {
+		op.v = len(text) - dot - 1
+	}
+	return op
+}
+
+func operandsOf(value any) (pluralOperands, bool) {
+	switch v := value.(type) {
+	case int:
+		return newPluralOperands(float64(v), ""), true
+	case int8:
+		return newPluralOperands(float64(v), ""), true
+	case int16:
+		return newPluralOperands(float64(v), ""), true
+	case int32:
+		return newPluralOperands(float64(v), ""), true
+	case int64:
+		return newPluralOperands(float64(v), ""), true
+	case uint:
+		return newPluralOperands(float64(v), ""), true
+	case uint8:
+		return newPluralOperands(float64(v), ""), true
+	case uint16:
+		return newPluralOperands(float64(v), ""), true
+	case uint32:
+		return newPluralOperands(float64(v), ""), true
+	case uint64:
+		return newPluralOperands(float64(v), ""), true
+	case float32:
+		return newPluralOperands(float64(v), strconv.FormatFloat(float64(v), 'f', -1, 32)), true
+	case float64:
+		return newPluralOperands(v, strconv.FormatFloat(v, 'f', -1, 64)), true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return newPluralOperands(f, v), err == nil
+	}
+	return pluralOperands{}, false
+}
+
+// integer returns the value when the number has no fraction digits, CLDR
+// ranges such as "n % 10 = 2..4" only match whole numbers
+func (op pluralOperands) integer() (int64, bool) {
+	return op.i, op.v == 0 && op.n == float64(op.i)
+}
+
+func inRange(x, lo, hi int64) bool { return x >= lo && x <= hi }
+
+// millionMany is the "many" rule shared by the romance languages, which is
+// used for whole millions ("1 million de personnes")
+func (op pluralOperands) millionMany() bool {
+	return op.v == 0 && op.i != 0 && op.i%1000000 == 0
+}
+
+type pluralRule func(op pluralOperands) PluralCategory
+
+// pluralRules are the CLDR cardinal rules by base language, languages that
+// aren't listed use the English rule
+var pluralRules = map[string]pluralRule{
+	"en": ruleOneIntegerOnly,
+	"de": ruleOneIntegerOnly,
+	"nl": ruleOneIntegerOnly,
+	"sv": ruleOneIntegerOnly,
+	"fi": ruleOneIntegerOnly,
+	"et": ruleOneIntegerOnly,
+	"it": func(op pluralOperands) PluralCategory {
+		if op.i == 1 && op.v == 0 {
+			return PluralOne
+		} else if op.millionMany() {
+			return PluralMany
+		}
+		return PluralOther
+	},
+	"es": func(op pluralOperands) PluralCategory {
+		if op.n == 1 {
+			return PluralOne
+		} else if op.millionMany() {
+			return PluralMany
+		}
+		return PluralOther
+	},
+	"fr": ruleOneZeroOrOneMillionMany,
+	"pt": ruleOneZeroOrOneMillionMany,
+	"el": ruleOneExact,
+	"hu": ruleOneExact,
+	"tr": ruleOneExact,
+	"bg": ruleOneExact,
+	"nb": ruleOneExact,
+	"no": ruleOneExact,
+	"da": func(op pluralOperands) PluralCategory {
+		if op.n == 1 || (op.v != 0 && op.i <= 1) {
+			return PluralOne
+		}
+		return PluralOther
+	},
+	"hi": func(op pluralOperands) PluralCategory {
+		if op.i == 0 || op.n == 1 {
+			return PluralOne
+		}
+		return PluralOther
+	},
+	"ru": ruleEastSlavic,
+	"uk": ruleEastSlavic,
+	"be": ruleEastSlavic,
+	"pl": func(op pluralOperands) PluralCategory {
+		if op.v != 0 {
+			return PluralOther
+		}
+		i10, i100 := op.i%10, op.i%100
+		switch {
+		case op.i == 1:
+			return PluralOne
+		case inRange(i10, 2, 4) && !inRange(i100, 12, 14):
+			return PluralFew
+		default:
+			return PluralMany
+		}
+	},
+	"cs": ruleWestSlavic,
+	"sk": ruleWestSlavic,
+	"ro": func(op pluralOperands) PluralCategory {
+		n, whole := op.integer()
+		switch {
+		case op.i == 1 && op.v == 0:
+			return PluralOne
+		case op.v != 0 || n == 0 || (whole && inRange(n%100, 2, 19)):
+			return PluralFew
+		}
+		return PluralOther
+	},
+	"he": func(op pluralOperands) PluralCategory {
+		switch {
+		case (op.i == 1 && op.v == 0) || (op.i == 0 && op.v != 0):
+			return PluralOne
+		case op.i == 2 && op.v == 0:
+			return PluralTwo
+		}
+		return PluralOther
+	},
+	"ar": func(op pluralOperands) PluralCategory {
+		n, whole := op.integer()
+		switch {
+		case !whole:
+			return PluralOther
+		case n == 0:
+			return PluralZero
+		case n == 1:
+			return PluralOne
+		case n == 2:
+			return PluralTwo
+		case inRange(n%100, 3, 10):
+			return PluralFew
+		case inRange(n%100, 11, 99):
+			return PluralMany
+		}
+		return PluralOther
+	},
+	"ja": ruleOther,
+	"zh": ruleOther,
+	"ko": ruleOther,
+	"th": ruleOther,
+	"vi": ruleOther,
+	"id": ruleOther,
+	"ms": ruleOther,
+}
+
+func ruleOther(pluralOperands) PluralCategory { return PluralOther }
+
+func ruleOneIntegerOnly(op pluralOperands) PluralCategory {
+	if op.i == 1 && op.v == 0 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func ruleOneExact(op pluralOperands) PluralCategory {
+	if op.n == 1 {
+		return PluralOne
+	}
+	return PluralOther
+}
+
+func ruleOneZeroOrOneMillionMany(op pluralOperands) PluralCategory {
+	if op.i <= 1 {
+		return PluralOne
+	} else if op.millionMany() {
+		return PluralMany
+	}
+	return PluralOther
+}
+
+func ruleEastSlavic(op pluralOperands) PluralCategory {
+	if op.v != 0 {
+		return PluralOther
+	}
+	i10, i100 := op.i%10, op.i%100
+	switch {
+	case i10 == 1 && i100 != 11:
+		return PluralOne
+	case inRange(i10, 2, 4) && !inRange(i100, 12, 14):
+		return PluralFew
+	default:
+		return PluralMany
+	}
+}
+
+func ruleWestSlavic(op pluralOperands) PluralCategory {
+	switch {
+	case op.v != 0:
+		return PluralMany
+	case op.i == 1:
+		return PluralOne
+	case inRange(op.i, 2, 4):
+		return PluralFew
+	}
+	return PluralOther
+}
+
+func pluralRuleFor(lang string) pluralRule {
+	tag := language.Make(lang)
+	base, _ := tag.Base()
+	if base.String() == "pt" {
+		// European Portuguese only uses "one" for exactly 1
+		if region, _ := tag.Region(); region.String() == "PT" {
+			return func(op pluralOperands) PluralCategory {
+				if c := ruleOneIntegerOnly(op); c == PluralOne || !op.millionMany() {
+					return c
+				}
+				return PluralMany
+			}
+		}
+	}
+	if rule, ok := pluralRules[base.String()]; ok {
+		return rule
+	}
+	return ruleOneIntegerOnly
+}
+
+// Plural returns the CLDR plural category of the number for the language,
+// the value can be any Go number or a number written as a string
+func Plural(lang string, value any) PluralCategory {
+	op, ok := operandsOf(value)
+	if !ok {
+		return PluralOther
+	}
+	return pluralRuleFor(lang)(op)
+}
+
+// gettextForms are the categories of the msgstr[n] forms of a PO file by
+// base language, in the order gettext numbers them
+var gettextForms = map[string][]PluralCategory{
+	"ru": {PluralOne, PluralFew, PluralMany},
+	"uk": {PluralOne, PluralFew, PluralMany},
+	"be": {PluralOne, PluralFew, PluralMany},
+	"pl": {PluralOne, PluralFew, PluralMany},
+	"cs": {PluralOne, PluralFew, PluralOther},
+	"sk": {PluralOne, PluralFew, PluralOther},
+	"ro": {PluralOne, PluralFew, PluralOther},
+	"he": {PluralOne, PluralTwo, PluralOther},
+	"ar": {PluralZero, PluralOne, PluralTwo, PluralFew, PluralMany, PluralOther},
+	"ja": {PluralOther},
+	"zh": {PluralOther},
+	"ko": {PluralOther},
+	"th": {PluralOther},
+	"vi": {PluralOther},
+	"id": {PluralOther},
+	"ms": {PluralOther},
+}
+
+func gettextFormsFor(lang string) []PluralCategory {
+	base, _ := language.Make(lang).Base()
+	if forms, ok := gettextForms[base.String()]; ok {
+		return forms
+	}
+	return []PluralCategory{PluralOne, PluralOther}
+}
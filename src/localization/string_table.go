@@ -0,0 +1,314 @@
+/******************************************************************************/
+/* string_table.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package localization
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// StringTable is the localized strings of a single language by key. The
+// strings are ICU message format strings, see [Message].
+type StringTable struct {
+	Language string            `json:"language"`
+	Strings  map[string]string `json:"strings"`
+}
+
+// csvIgnoredColumns are the CSV columns that are notes for translators
+// rather than a language
+var csvIgnoredColumns = []string{"comment", "comments", "context", "description", "notes"}
+
+// ParseStringTables reads string tables from a file, the format is picked by
+// the extension of the name:
+//
+//	.csv  a "key" column followed by a column per language, such as
+//	      key,en-US,fr,de
+//	.po   a gettext catalog, the key is the msgctxt when there is one and the
+//	      msgid otherwise. Plural entries become a plural of the count argument.
+//	.json {"language": "fr", "strings": {"menu": {"play": "Jouer"}}} or an
+//	      array of them, nested objects are joined into keys like "menu.play"
+//
+// Anything else is read as JSON, which is how imported tables are stored.
+func ParseStringTables(name string, data []byte) ([]StringTable, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return parseCSVStringTables(data)
+	case ".po":
+		t, err := parsePOStringTable(data)
+		return []StringTable{t}, err
+	default:
+		return parseJSONStringTables(data)
+	}
+}
+
+// MarshalStringTables writes the tables as JSON that [ParseStringTables]
+// reads back
+func MarshalStringTables(tables []StringTable) ([]byte, error) {
+	return json.Marshal(tables)
+}
+
+func parseCSVStringTables(data []byte) ([]StringTable, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xEF\xBB\xBF"))))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the string table csv: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the string table csv is empty")
+	}
+	header := records[0]
+	if len(header) < 2 || !strings.EqualFold(strings.TrimSpace(header[0]), "key") {
+		return nil, errors.New(`the string table csv must start with a "key" column followed by languages`)
+	}
+	tables := []StringTable{}
+	columns := map[int]int{}
+	for i := 1; i < len(header); i++ {
+		name := strings.TrimSpace(header[i])
+		if name == "" || slices.Contains(csvIgnoredColumns, strings.ToLower(name)) {
+			continue
+		}
+		columns[i] = len(tables)
+		tables = append(tables, StringTable{Language: normalizeLocalization(name), Strings: map[string]string{}})
+	}
+	for _, row := range records[1:] {
+		if len(row) == 0 {
+			continue
+		}
+		key := strings.TrimSpace(row[0])
+		if key == "" || strings.HasPrefix(key, "#") {
+			continue
+		}
+		for col, t := range columns {
+			if col < len(row) && row[col] != "" {
+				tables[t].Strings[key] = row[col]
+			}
+		}
+	}
+	return tables, nil
+}
+
+func parseJSONStringTables(data []byte) ([]StringTable, error) {
+	type rawTable struct {
+		Language string         `json:"language"`
+		Strings  map[string]any `json:"strings"`
+	}
+	raw := []rawTable{}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '{' {
+		single := rawTable{}
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return nil, fmt.Errorf("failed to read the string table json: %w", err)
+		}
+		raw = append(raw, single)
+	} else if err := json.Unmarshal(trimmed, &raw); err != nil {
+		return nil, fmt.Errorf("failed to read the string table json: %w", err)
+	}
+	tables := make([]StringTable, 0, len(raw))
+	for _, r := range raw {
+		if r.Language == "" {
+			return nil, errors.New("the string table json is missing the language")
+		}
+		t := StringTable{Language: normalizeLocalization(r.Language), Strings: map[string]string{}}
+		if err := flattenJSONStrings(t.Strings, "", r.Strings); err != nil {
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	return tables, nil
+}
+
+func flattenJSONStrings(out map[string]string, prefix string, values map[string]any) error {
+	for k, v := range values {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch value := v.(type) {
+		case string:
+			out[key] = value
+		case map[string]any:
+			if err := flattenJSONStrings(out, key, value); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("the string %q in the string table json is not text", key)
+		}
+	}
+	return nil
+}
+
+// poEntry is a message of a gettext catalog as it is being read
+type poEntry struct {
+	context  string
+	id       string
+	plural   string
+	strs     map[int]string
+	fuzzy    bool
+	hasId    bool
+	lastWord string
+	lastIdx  int
+}
+
+func parsePOStringTable(data []byte) (StringTable, error) {
+	t := StringTable{Strings: map[string]string{}}
+	entries := []poEntry{}
+	cur := poEntry{strs: map[int]string{}}
+	finish := func() {
+		if cur.hasId {
+			entries = append(entries, cur)
+		}
+		cur = poEntry{strs: map[int]string{}}
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			finish()
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if strings.HasPrefix(line, "#,") && strings.Contains(line, "fuzzy") {
+				cur.fuzzy = true
+			}
+			continue
+		}
+		if strings.HasPrefix(line, `"`) {
+			s, err := strconv.Unquote(line)
+			if err != nil {
+				return t, fmt.Errorf("po line %d: %w", lineNum, err)
+			}
+			cur.append(s)
+			continue
+		}
+		word, rest, _ := strings.Cut(line, " ")
+		s, err := strconv.Unquote(strings.TrimSpace(rest))
+		if err != nil {
+			return t, fmt.Errorf("po line %d: %w", lineNum, err)
+		}
+		if (word == "msgctxt" || word == "msgid") && cur.hasId {
+			finish()
+		}
+		cur.lastWord = word
+		switch {
+		case word == "msgctxt":
+			cur.context = s
+		case word == "msgid":
+			cur.hasId = true
+			cur.id = s
+		case word == "msgid_plural":
+			cur.plural = s
+		case word == "msgstr":
+			cur.lastIdx = 0
+			cur.strs[0] = s
+		case strings.HasPrefix(word, "msgstr["):
+			idx, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(word, "msgstr["), "]"))
+			if err != nil {
+				return t, fmt.Errorf("po line %d: invalid plural index %q", lineNum, word)
+			}
+			cur.lastWord = "msgstr"
+			cur.lastIdx = idx
+			cur.strs[idx] = s
+		default:
+			return t, fmt.Errorf("po line %d: unknown keyword %q", lineNum, word)
+		}
+	}
+	finish()
+	for _, e := range entries {
+		if e.id == "" && e.context == "" {
+			t.Language = poHeaderLanguage(e.strs[0])
+		}
+	}
+	if t.Language == "" {
+		return t, errors.New(`the po file is missing the "Language" header`)
+	}
+	for _, e := range entries {
+		if e.fuzzy || (e.id == "" && e.context == "") {
+			continue
+		}
+		key := e.id
+		if e.context != "" {
+			key = e.context
+		}
+		if e.plural != "" {
+			if msg := poPluralMessage(t.Language, e.strs); msg != "" {
+				t.Strings[key] = msg
+			}
+		} else if e.strs[0] != "" {
+			t.Strings[key] = e.strs[0]
+		}
+	}
+	return t, nil
+}
+
+func (e *poEntry) append(s string) {
+	switch e.lastWord {
+	case "msgctxt":
+		e.context += s
+	case "msgid":
+		e.id += s
+	case "msgid_plural":
+		e.plural += s
+	case "msgstr":
+		e.strs[e.lastIdx] += s
+	}
+}
+
+func poHeaderLanguage(header string) string {
+	for line := range strings.SplitSeq(header, "\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.TrimSpace(name) == "Language" {
+			if value = strings.TrimSpace(value); value != "" {
+				return normalizeLocalization(value)
+			}
+		}
+	}
+	return ""
+}
+
+// poPluralMessage turns the msgstr[n] forms into an ICU plural of the count
+// argument, %d in the forms is the number
+func poPluralMessage(lang string, strs map[int]string) string {
+	if len(strs) == 0 {
+		return ""
+	}
+	forms := gettextFormsFor(lang)
+	options := map[PluralCategory]string{}
+	for i, category := range forms {
+		if s := strs[i]; s != "" {
+			options[category] = strings.ReplaceAll(s, "%d", "#")
+		}
+	}
+	if _, ok := options[PluralOther]; !ok {
+		// Decimals use "other", which gettext doesn't have a form for in many
+		// languages, so the last form is used for it
+		last := slices.Max(slices.Collect(maps.Keys(strs)))
+		if s := strs[last]; s != "" {
+			options[PluralOther] = strings.ReplaceAll(s, "%d", "#")
+		}
+	}
+	if len(options) == 0 {
+		return ""
+	}
+	sb := strings.Builder{}
+	sb.WriteString("{count, plural,")
+	for c := PluralZero; c <= PluralOther; c++ {
+		if s, ok := options[c]; ok {
+			sb.WriteString(" " + c.String() + " {" + s + "}")
+		}
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
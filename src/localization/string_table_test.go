@@ -0,0 +1,118 @@
+/******************************************************************************/
+/* string_table_test.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package localization
+
+import "testing"
+
+func TestParseCSVStringTables(t *testing.T) {
+	csv := "key,en_US,fr,notes\n" +
+		"menu.play,Play,Jouer,The main menu button\n" +
+		"menu.quit,Quit,,\n" +
+		`items,"{n, plural, one {# item} other {# items}}","{n, plural, one {# objet} other {# objets}}",` + "\n"
+	tables, err := ParseStringTables("menu.csv", []byte(csv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 2 {
+		t.Fatalf("got %d tables, want 2", len(tables))
+	}
+	if tables[0].Language != "en-US" || tables[1].Language != "fr" {
+		t.Fatalf("languages = %q, %q", tables[0].Language, tables[1].Language)
+	}
+	if tables[1].Strings["menu.play"] != "Jouer" {
+		t.Errorf("fr menu.play = %q", tables[1].Strings["menu.play"])
+	}
+	if _, ok := tables[1].Strings["menu.quit"]; ok {
+		t.Error("a blank cell should be left out so the fallback is used")
+	}
+	if _, err := ParseStringTables("bad.csv", []byte("id,en\n")); err == nil {
+		t.Error("a csv without a key column should be an error")
+	}
+}
+
+func TestParseJSONStringTables(t *testing.T) {
+	src := `{"language": "de", "strings": {"menu": {"play": "Spielen", "options": {"audio": "Ton"}}, "title": "Spiel"}}`
+	tables, err := ParseStringTables("menu.json", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"menu.play": "Spielen", "menu.options.audio": "Ton", "title": "Spiel"}
+	for k, v := range want {
+		if tables[0].Strings[k] != v {
+			t.Errorf("%s = %q, want %q", k, tables[0].Strings[k], v)
+		}
+	}
+	stored, err := MarshalStringTables(tables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := ParseStringTables("imported", stored)
+	if err != nil || len(again) != 1 || again[0].Strings["menu.options.audio"] != "Ton" {
+		t.Fatalf("stored tables didn't read back: %v %+v", err, again)
+	}
+	if _, err := ParseStringTables("bad.json", []byte(`{"strings": {}}`)); err == nil {
+		t.Error("a table without a language should be an error")
+	}
+}
+
+func TestParsePOStringTable(t *testing.T) {
+	po := `msgid ""
+msgstr ""
+"Language: ru\n"
+"Plural-Forms: nplurals=3;\n"
+
+#: menu.html
+msgctxt "menu.play"
+msgid "Play"
+msgstr "Играть"
+
+msgid "Long "
+"line"
+msgstr "Длинная "
+"строка"
+
+#, fuzzy
+msgid "Unsure"
+msgstr "Не уверен"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d файл"
+msgstr[1] "%d файла"
+msgstr[2] "%d файлов"
+`
+	tables, err := ParseStringTables("ru.po", []byte(po))
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := tables[0]
+	if table.Language != "ru" {
+		t.Fatalf("language = %q", table.Language)
+	}
+	if table.Strings["menu.play"] != "Играть" {
+		t.Errorf("menu.play = %q", table.Strings["menu.play"])
+	}
+	if table.Strings["Long line"] != "Длинная строка" {
+		t.Errorf("continued line = %q", table.Strings["Long line"])
+	}
+	if _, ok := table.Strings["Unsure"]; ok {
+		t.Error("fuzzy entries should be skipped")
+	}
+	msg, err := ParseMessage(table.Strings["%d file"])
+	if err != nil {
+		t.Fatalf("plural entry %q: %v", table.Strings["%d file"], err)
+	}
+	if got := msg.Format("ru", map[string]any{"count": 5}); got != "5 файлов" {
+		t.Errorf("5 files = %q", got)
+	}
+	if got := msg.Format("ru", map[string]any{"count": 1.5}); got != "1,5 файлов" {
+		t.Errorf("1.5 files = %q", got)
+	}
+	if _, err := ParseStringTables("none.po", []byte("msgid \"a\"\nmsgstr \"b\"\n")); err == nil {
+		t.Error("a po file without a language should be an error")
+	}
+}
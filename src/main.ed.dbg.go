@@ -9,6 +9,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/fs"
 	"os"
@@ -53,5 +54,8 @@ func getGame() bootstrap.GameInterface {
 		fmt.Printf("Project (%s) successfully upgraded", engine.LaunchParams.UpgradeProject)
 		os.Exit(0)
 	}
+	if engine.LaunchParams.Project != "" {
+		os.Exit(editor.RunPipelineFromCLI(engine.LaunchParams.Project, flag.Args(), os.Stdout))
+	}
 	return editor.EditorGame{}
 }
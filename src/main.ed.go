@@ -10,6 +10,7 @@ package main
 
 import (
 	"embed"
+	"flag"
 	"fmt"
 	"os"
 
@@ -51,5 +52,8 @@ func getGame() bootstrap.GameInterface {
 		fmt.Printf("Project (%s) successfully upgraded", engine.LaunchParams.UpgradeProject)
 		os.Exit(0)
 	}
+	if engine.LaunchParams.Project != "" {
+		os.Exit(editor.RunPipelineFromCLI(engine.LaunchParams.Project, flag.Args(), os.Stdout))
+	}
 	return editor.EditorGame{}
 }
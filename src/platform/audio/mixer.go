@@ -0,0 +1,442 @@
+/******************************************************************************/
+/* mixer.go                                                                   */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package audio
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"kaijuengine.com/klib"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// SoLoud filter attribute ids, see the SOLOUD_ENUMS in soloud_c.h
+var effectAttributes = [...]map[string]int{
+	EffectLowPass:    {"wet": 0, "frequency": 2, "resonance": 3},
+	EffectHighPass:   {"wet": 0, "frequency": 2, "resonance": 3},
+	EffectReverb:     {"wet": 0, "room_size": 2, "damp": 3, "width": 4},
+	EffectEcho:       {"wet": 0, "decay": 2, "filter": 3},
+	EffectCompressor: {},
+}
+
+const (
+	biquadLowPass  = 0
+	biquadHighPass = 1
+)
+
+// mixerValue is a bus or effect value that can be faded by a snapshot
+type mixerValue struct {
+	from    float32
+	to      float32
+	current float32
+	applied float32
+}
+
+func newMixerValue(v float32) mixerValue {
+	return mixerValue{from: v, to: v, current: v, applied: float32(math.NaN())}
+}
+
+func (v *mixerValue) set(value float32) {
+	v.from, v.to, v.current = value, value, value
+}
+
+func (v *mixerValue) fadeTo(value float32) {
+	v.from, v.to = v.current, value
+}
+
+func (v *mixerValue) step(t float32) {
+	v.current = v.from + (v.to-v.from)*t
+}
+
+// changed returns true the first time it is called after the value moved
+func (v *mixerValue) changed() bool {
+	if v.current == v.applied {
+		return false
+	}
+	v.applied = v.current
+	return true
+}
+
+type mixerEffect struct {
+	config EffectConfig
+	slot   int
+	filter SoloudFilter
+	params map[string]*mixerValue
+	// reduction is how many dB the compressor is lowering the bus by
+	reduction float32
+}
+
+type mixerBus struct {
+	config  BusConfig
+	parent  *mixerBus
+	bus     SoloudBus
+	handle  VoiceHandle
+	volume  mixerValue
+	effects []*mixerEffect
+	// applied is the volume last given to SoLoud, after mute, solo and
+	// compression
+	applied float32
+}
+
+// Mixer is a tree of named buses under a master bus. Each bus has a volume,
+// can be muted or soloed and can have effects inserted on it. Snapshots
+// crossfade the volumes and effect params of every bus at once.
+type Mixer struct {
+	soloud    SoloudHandle
+	mutex     sync.Mutex
+	config    MixerConfig
+	buses     []*mixerBus
+	byName    map[string]*mixerBus
+	snapshot  string
+	fadeTime  float64
+	fadeTimer float64
+}
+
+func newMixer(soloud SoloudHandle) *Mixer {
+	return &Mixer{soloud: soloud, byName: map[string]*mixerBus{}}
+}
+
+// Configure rebuilds the mixer from the config. Anything playing on the old
+// buses is stopped.
+func (m *Mixer) Configure(config MixerConfig) error {
+	defer tracing.NewRegion("Mixer.Configure").End()
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	sorted, _ := config.sorted()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.destroy()
+	m.config = config
+	m.snapshot = ""
+	m.fadeTime, m.fadeTimer = 0, 0
+	for _, cfg := range sorted {
+		b := &mixerBus{
+			config:  cfg,
+			parent:  m.byName[cfg.Parent],
+			bus:     busCreate(),
+			volume:  newMixerValue(cfg.Volume),
+			applied: float32(math.NaN()),
+		}
+		slot := 0
+		for _, ec := range cfg.Effects {
+			e := &mixerEffect{config: ec, slot: -1, params: map[string]*mixerValue{}}
+			params := ec.params()
+			for name, v := range params {
+				value := newMixerValue(v)
+				e.params[name] = &value
+			}
+			switch ec.Type {
+			case EffectLowPass:
+				e.filter = biquadFilterCreate(biquadLowPass, params["frequency"], params["resonance"])
+			case EffectHighPass:
+				e.filter = biquadFilterCreate(biquadHighPass, params["frequency"], params["resonance"])
+			case EffectReverb:
+				e.filter = freeverbFilterCreate(params["freeze"], params["room_size"], params["damp"], params["width"])
+			case EffectEcho:
+				e.filter = echoFilterCreate(params["delay"], params["decay"], params["filter"])
+			case EffectCompressor:
+				busSetVisualization(b.bus, true)
+			}
+			if e.filter != nil {
+				e.slot = slot
+				busSetFilter(b.bus, slot, e.filter)
+				slot++
+			}
+			b.effects = append(b.effects, e)
+		}
+		var parent SoloudBus
+		if b.parent != nil {
+			parent = b.parent.bus
+		}
+		b.handle = busStart(m.soloud, parent, b.bus)
+		m.buses = append(m.buses, b)
+		m.byName[cfg.Name] = b
+	}
+	m.apply(0)
+	return nil
+}
+
+func (m *Mixer) destroy() {
+	// Children are stopped before their parents
+	for i := len(m.buses) - 1; i >= 0; i-- {
+		b := m.buses[i]
+		stopAudio(m.soloud, b.handle)
+		busDestroy(b.bus)
+		for _, e := range b.effects {
+			if e.filter != nil {
+				filterDestroy(e.config.Type, e.filter)
+			}
+		}
+	}
+	m.buses = m.buses[:0]
+	clear(m.byName)
+}
+
+// playBus returns the SoLoud bus a clip routed to the named bus plays on,
+// unknown buses play on the master bus
+func (m *Mixer) playBus(name string) SoloudBus {
+	if m == nil {
+		return nil
+	}
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if b, ok := m.byName[name]; ok {
+		return b.bus
+	}
+	if len(m.buses) > 0 {
+		return m.buses[0].bus
+	}
+	return nil
+}
+
+func (m *Mixer) findBus(name string) (*mixerBus, error) {
+	if b, ok := m.byName[name]; ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("unknown audio bus %q", name)
+}
+
+// Buses returns the names of the buses, parents come before their children
+func (m *Mixer) Buses() []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	out := make([]string, len(m.buses))
+	for i := range m.buses {
+		out[i] = m.buses[i].config.Name
+	}
+	return out
+}
+
+// BusVolume returns the current volume of the bus, not counting mute, solo
+// or compression
+func (m *Mixer) BusVolume(name string) float32 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if b, ok := m.byName[name]; ok {
+		return b.volume.current
+	}
+	return 0
+}
+
+// SetBusVolume sets the volume of the bus right away, cancelling a snapshot
+// fade of the volume
+func (m *Mixer) SetBusVolume(name string, volume float32) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	b, err := m.findBus(name)
+	if err != nil {
+		return err
+	}
+	b.volume.set(klib.Clamp(volume, 0, 1))
+	m.apply(0)
+	return nil
+}
+
+func (m *Mixer) IsBusMuted(name string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	b, ok := m.byName[name]
+	return ok && b.config.Muted
+}
+
+func (m *Mixer) SetBusMuted(name string, muted bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	b, err := m.findBus(name)
+	if err != nil {
+		return err
+	}
+	b.config.Muted = muted
+	m.apply(0)
+	return nil
+}
+
+func (m *Mixer) IsBusSolo(name string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	b, ok := m.byName[name]
+	return ok && b.config.Solo
+}
+
+// SetBusSolo solos the bus. While any bus is soloed only the soloed buses,
+// the buses they route into and the buses routed into them are heard.
+func (m *Mixer) SetBusSolo(name string, solo bool) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	b, err := m.findBus(name)
+	if err != nil {
+		return err
+	}
+	b.config.Solo = solo
+	m.apply(0)
+	return nil
+}
+
+// SetEffectParam sets a param of an effect on a bus right away, cancelling a
+// snapshot fade of the param
+func (m *Mixer) SetEffectParam(bus, effect, param string, value float32) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	b, err := m.findBus(bus)
+	if err != nil {
+		return err
+	}
+	for _, e := range b.effects {
+		if e.config.Name != effect {
+			continue
+		}
+		v, ok := e.params[param]
+		if !ok {
+			return fmt.Errorf("the %s effect %q has no param %q", e.config.Type, effect, param)
+		}
+		v.set(value)
+		m.apply(0)
+		return nil
+	}
+	return fmt.Errorf("the bus %q has no effect %q", bus, effect)
+}
+
+// Snapshot returns the name of the last applied snapshot, or an empty string
+// for the config values
+func (m *Mixer) Snapshot() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.snapshot
+}
+
+// ApplySnapshot crossfades every bus volume and effect param to the values
+// of the named snapshot over the given seconds. Values the snapshot leaves
+// out go back to the values of the config. An empty name goes back to the
+// config values.
+func (m *Mixer) ApplySnapshot(name string, seconds float64) error {
+	defer tracing.NewRegion("Mixer.ApplySnapshot").End()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var snapshot Snapshot
+	if name != "" {
+		i := slices.IndexFunc(m.config.Snapshots, func(s Snapshot) bool { return s.Name == name })
+		if i < 0 {
+			return fmt.Errorf("unknown audio mixer snapshot %q", name)
+		}
+		snapshot = m.config.Snapshots[i]
+	}
+	for _, b := range m.buses {
+		cfg, _ := m.config.bus(b.config.Name)
+		sb := snapshot.Buses[b.config.Name]
+		volume := cfg.Volume
+		if sb.Volume != nil {
+			volume = klib.Clamp(*sb.Volume, 0, 1)
+		}
+		b.volume.fadeTo(volume)
+		for _, e := range b.effects {
+			targets := e.config.params()
+			for k, v := range sb.Effects[e.config.Name] {
+				targets[k] = v
+			}
+			for k, v := range e.params {
+				v.fadeTo(targets[k])
+			}
+		}
+	}
+	m.snapshot = name
+	m.fadeTime = max(0, seconds)
+	m.fadeTimer = 0
+	m.apply(0)
+	return nil
+}
+
+// Update steps snapshot fades and compressors, the audio system calls this
+// each frame
+func (m *Mixer) Update(deltaTime float64) {
+	defer tracing.NewRegion("Mixer.Update").End()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.apply(deltaTime)
+}
+
+func (m *Mixer) apply(deltaTime float64) {
+	m.fadeTimer += deltaTime
+	t := float32(1)
+	if m.fadeTimer < m.fadeTime {
+		t = float32(m.fadeTimer / m.fadeTime)
+	}
+	anySolo := slices.ContainsFunc(m.buses, func(b *mixerBus) bool { return b.config.Solo })
+	for _, b := range m.buses {
+		b.volume.step(t)
+		gain := float32(1)
+		for _, e := range b.effects {
+			for name, v := range e.params {
+				v.step(t)
+				if attr, ok := effectAttributes[e.config.Type][name]; ok && e.slot >= 0 && v.changed() {
+					setFilterParameter(m.soloud, b.handle, e.slot, attr, v.current)
+				}
+			}
+			if e.config.Type == EffectCompressor {
+				level := max(busApproximateVolume(b.bus, 0), busApproximateVolume(b.bus, 1))
+				gain *= e.compress(level, deltaTime)
+			}
+		}
+		volume := b.volume.current * gain
+		if b.config.Muted || (anySolo && !m.audibleInSolo(b)) {
+			volume = 0
+		}
+		if volume != b.applied {
+			b.applied = volume
+			setVolume(m.soloud, b.handle, volume)
+		}
+	}
+}
+
+// audibleInSolo returns true if the bus, a bus it routes into or a bus
+// routed into it is soloed
+func (m *Mixer) audibleInSolo(b *mixerBus) bool {
+	if b.parent == nil {
+		return true
+	}
+	for p := b; p != nil; p = p.parent {
+		if p.config.Solo {
+			return true
+		}
+	}
+	for _, other := range m.buses {
+		if !other.config.Solo {
+			continue
+		}
+		for p := other.parent; p != nil; p = p.parent {
+			if p == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compress returns the gain of a compressor for this frame, level is the
+// loudest channel of the bus it is on
+func (e *mixerEffect) compress(level float32, deltaTime float64) float32 {
+	threshold := e.params["threshold"].current
+	ratio := max(1, e.params["ratio"].current)
+	target := float32(0)
+	if level > 0 {
+		db := 20 * float32(math.Log10(float64(level)))
+		target = max(0, (db-threshold)*(1-1/ratio))
+	}
+	timeConstant := e.params["release"].current
+	if target > e.reduction {
+		timeConstant = e.params["attack"].current
+	}
+	if timeConstant <= 0 {
+		e.reduction = target
+	} else if deltaTime > 0 {
+		k := 1 - float32(math.Exp(-deltaTime/float64(timeConstant)))
+		e.reduction += (target - e.reduction) * k
+	}
+	return float32(math.Pow(10, float64(e.params["makeup"].current-e.reduction)/20))
+}
@@ -0,0 +1,255 @@
+/******************************************************************************/
+/* mixer_config.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+
+	"kaijuengine.com/engine/assets"
+)
+
+// MixerAssetKey is the project asset the host loads the mixer routing from
+// when it exists, see [MixerConfig]
+const MixerAssetKey = "mixer.json"
+
+// The buses of [DefaultMixerConfig], sounds are routed to [BusSFX] and music
+// to [BusMusic] unless the clip is given another bus with [AudioClip.SetBus]
+const (
+	BusMaster   = "master"
+	BusMusic    = "music"
+	BusSFX      = "sfx"
+	BusUI       = "ui"
+	BusVoice    = "voice"
+	BusAmbience = "ambience"
+)
+
+// maxBusEffects is the number of filters SoLoud allows on a single source
+const maxBusEffects = 8
+
+// EffectType is the kind of effect inserted on a bus
+type EffectType uint8
+
+const (
+	EffectLowPass EffectType = iota
+	EffectHighPass
+	EffectReverb
+	EffectEcho
+	// EffectCompressor lowers the volume of the bus when it gets louder than
+	// the threshold. SoLoud doesn't have a compressor filter so it works on
+	// the bus volume once a frame, it is a leveler rather than a peak limiter.
+	EffectCompressor
+)
+
+var effectTypeNames = []string{"lowpass", "highpass", "reverb", "echo", "compressor"}
+
+func (t EffectType) String() string {
+	if int(t) < len(effectTypeNames) {
+		return effectTypeNames[t]
+	}
+	return fmt.Sprintf("EffectType(%d)", t)
+}
+
+func (t EffectType) MarshalText() ([]byte, error) { return []byte(t.String()), nil }
+
+func (t *EffectType) UnmarshalText(text []byte) error {
+	i := slices.Index(effectTypeNames, string(text))
+	if i < 0 {
+		return fmt.Errorf("unknown audio effect type %q", text)
+	}
+	*t = EffectType(i)
+	return nil
+}
+
+// EffectConfig is an effect on a bus. Params that are left out use the
+// defaults of the effect type, which are:
+//
+//	lowpass, highpass: wet, frequency (10 to 8000 Hz), resonance
+//	reverb: wet, room_size, damp, width, freeze
+//	echo: wet, delay (seconds), decay, filter
+//	compressor: threshold (dB), ratio, attack, release (seconds), makeup (dB)
+//
+// The echo delay and the reverb freeze can't be changed by a snapshot.
+type EffectConfig struct {
+	Name   string             `json:"name"`
+	Type   EffectType         `json:"type"`
+	Params map[string]float32 `json:"params,omitempty"`
+}
+
+// BusConfig is a bus of the mixer, a bus without a parent is routed to the
+// master bus
+type BusConfig struct {
+	Name    string         `json:"name"`
+	Parent  string         `json:"parent,omitempty"`
+	Volume  float32        `json:"volume"`
+	Muted   bool           `json:"muted,omitempty"`
+	Solo    bool           `json:"solo,omitempty"`
+	Effects []EffectConfig `json:"effects,omitempty"`
+}
+
+// SnapshotBus are the values a snapshot gives a bus, anything left out uses
+// the value from the [BusConfig]
+type SnapshotBus struct {
+	Volume *float32 `json:"volume,omitempty"`
+	// Effects are the params of effects by effect name
+	Effects map[string]map[string]float32 `json:"effects,omitempty"`
+}
+
+// Snapshot is a named state of the whole mixer, such as "paused" or
+// "underwater", that can be crossfaded to with [Mixer.ApplySnapshot]
+type Snapshot struct {
+	Name  string                 `json:"name"`
+	Buses map[string]SnapshotBus `json:"buses"`
+}
+
+// MixerConfig is the routing of the mixer and its snapshots
+type MixerConfig struct {
+	Buses     []BusConfig `json:"buses"`
+	Snapshots []Snapshot  `json:"snapshots,omitempty"`
+}
+
+// DefaultMixerConfig has a master bus with music, sfx, ui, voice and ambience
+// buses routed into it
+func DefaultMixerConfig() MixerConfig {
+	cfg := MixerConfig{Buses: []BusConfig{{Name: BusMaster, Volume: 1}}}
+	for _, name := range []string{BusMusic, BusSFX, BusUI, BusVoice, BusAmbience} {
+		cfg.Buses = append(cfg.Buses, BusConfig{Name: name, Parent: BusMaster, Volume: 1})
+	}
+	return cfg
+}
+
+// LoadMixerConfig reads a [MixerConfig] in JSON from the asset database
+func LoadMixerConfig(adb assets.Database, key string) (MixerConfig, error) {
+	cfg := MixerConfig{}
+	data, err := adb.Read(key)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("failed to read the audio mixer %q: %w", key, err)
+	}
+	return cfg, cfg.Validate()
+}
+
+func defaultEffectParams(t EffectType) map[string]float32 {
+	switch t {
+	case EffectLowPass:
+		return map[string]float32{"wet": 1, "frequency": 8000, "resonance": 0.7}
+	case EffectHighPass:
+		return map[string]float32{"wet": 1, "frequency": 10, "resonance": 0.7}
+	case EffectReverb:
+		return map[string]float32{"wet": 0.3, "room_size": 0.5, "damp": 0.5, "width": 1, "freeze": 0}
+	case EffectEcho:
+		return map[string]float32{"wet": 0.3, "delay": 0.3, "decay": 0.5, "filter": 0}
+	case EffectCompressor:
+		return map[string]float32{"threshold": -12, "ratio": 4, "attack": 0.01, "release": 0.2, "makeup": 0}
+	}
+	return map[string]float32{}
+}
+
+// params returns the params of the effect with the defaults filled in
+func (e EffectConfig) params() map[string]float32 {
+	out := defaultEffectParams(e.Type)
+	for k, v := range e.Params {
+		out[k] = v
+	}
+	return out
+}
+
+func (c *MixerConfig) bus(name string) (*BusConfig, bool) {
+	for i := range c.Buses {
+		if c.Buses[i].Name == name {
+			return &c.Buses[i], true
+		}
+	}
+	return nil, false
+}
+
+// sorted returns the buses with every parent before its children, the first
+// bus is the master bus. It fails for unknown parents and cycles.
+func (c *MixerConfig) sorted() ([]BusConfig, error) {
+	out := make([]BusConfig, 0, len(c.Buses))
+	placed := map[string]bool{}
+	master, ok := c.bus(BusMaster)
+	if !ok {
+		return nil, errors.New("the audio mixer needs a master bus")
+	}
+	out = append(out, *master)
+	placed[BusMaster] = true
+	for len(out) < len(c.Buses) {
+		progress := false
+		for _, b := range c.Buses {
+			if placed[b.Name] {
+				continue
+			}
+			parent := b.Parent
+			if parent == "" {
+				parent = BusMaster
+			}
+			if placed[parent] {
+				b.Parent = parent
+				out = append(out, b)
+				placed[b.Name] = true
+				progress = true
+			}
+		}
+		if !progress {
+			return nil, errors.New("the audio mixer has a bus with an unknown parent or a cycle")
+		}
+	}
+	return out, nil
+}
+
+// Validate checks the buses have unique names, the routing is a tree under
+// the master bus, and the snapshots only reference known buses and effects
+func (c *MixerConfig) Validate() error {
+	names := map[string]bool{}
+	for _, b := range c.Buses {
+		if b.Name == "" {
+			return errors.New("the audio mixer has a bus without a name")
+		}
+		if names[b.Name] {
+			return fmt.Errorf("the audio mixer has the bus %q more than once", b.Name)
+		}
+		names[b.Name] = true
+		if b.Name == BusMaster && b.Parent != "" {
+			return errors.New("the master bus can't have a parent")
+		}
+		filters := 0
+		for _, e := range b.Effects {
+			if e.Type > EffectCompressor {
+				return fmt.Errorf("the bus %q has an unknown effect type", b.Name)
+			}
+			if e.Type != EffectCompressor {
+				filters++
+			}
+		}
+		if filters > maxBusEffects {
+			return fmt.Errorf("the bus %q has more than %d effects", b.Name, maxBusEffects)
+		}
+	}
+	if _, err := c.sorted(); err != nil {
+		return err
+	}
+	for _, s := range c.Snapshots {
+		for busName, sb := range s.Buses {
+			b, ok := c.bus(busName)
+			if !ok {
+				return fmt.Errorf("the snapshot %q uses the unknown bus %q", s.Name, busName)
+			}
+			for effect := range sb.Effects {
+				if !slices.ContainsFunc(b.Effects, func(e EffectConfig) bool { return e.Name == effect }) {
+					return fmt.Errorf("the snapshot %q uses the unknown effect %q on the bus %q",
+						s.Name, effect, busName)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+/******************************************************************************/
+/* mixer_test.go                                                              */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package audio
+
+import (
+	"strings"
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+// testMixer routes music and sfx into master, and combat into music
+func testMixer(solo ...string) *Mixer {
+	master := &mixerBus{config: BusConfig{Name: BusMaster}}
+	music := &mixerBus{config: BusConfig{Name: BusMusic}, parent: master}
+	combat := &mixerBus{config: BusConfig{Name: "combat"}, parent: music}
+	sfx := &mixerBus{config: BusConfig{Name: BusSFX}, parent: master}
+	m := &Mixer{buses: []*mixerBus{master, music, combat, sfx}, byName: map[string]*mixerBus{}}
+	for _, b := range m.buses {
+		m.byName[b.config.Name] = b
+	}
+	for _, name := range solo {
+		m.byName[name].config.Solo = true
+	}
+	return m
+}
+
+func TestMixerAudibleInSolo(t *testing.T) {
+	tests := []struct {
+		name string
+		solo []string
+		bus  string
+		want bool
+	}{
+		{"master is always audible", []string{BusSFX}, BusMaster, true},
+		{"soloed bus", []string{BusSFX}, BusSFX, true},
+		{"sibling of soloed bus", []string{BusSFX}, BusMusic, false},
+		{"child of soloed bus", []string{BusMusic}, "combat", true},
+		{"parent of soloed bus", []string{"combat"}, BusMusic, true},
+		{"cousin of soloed bus", []string{"combat"}, BusSFX, false},
+		{"one of two soloed buses", []string{"combat", BusSFX}, BusSFX, true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := testMixer(test.solo...)
+			if got := m.audibleInSolo(m.byName[test.bus]); got != test.want {
+				t.Errorf("audibleInSolo(%s) = %t, want %t", test.bus, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMixerConfigValidate(t *testing.T) {
+	lowpass := EffectConfig{Name: "muffle", Type: EffectLowPass}
+	tooManyFilters := make([]EffectConfig, maxBusEffects+1)
+	for i := range tooManyFilters {
+		tooManyFilters[i] = lowpass
+	}
+	// Compressors don't use one of the SoLoud filter slots
+	compressors := append(make([]EffectConfig, 0, maxBusEffects+1), tooManyFilters[:maxBusEffects]...)
+	compressors = append(compressors, EffectConfig{Type: EffectCompressor})
+	withBus := func(b BusConfig) MixerConfig {
+		cfg := DefaultMixerConfig()
+		cfg.Buses = append(cfg.Buses, b)
+		return cfg
+	}
+	volume := float32(0.5)
+	tests := []struct {
+		name    string
+		config  MixerConfig
+		wantErr string
+	}{
+		{"default", DefaultMixerConfig(), ""},
+		{"missing master", MixerConfig{Buses: []BusConfig{{Name: BusMusic}}}, "master bus"},
+		{"unnamed bus", withBus(BusConfig{}), "without a name"},
+		{"duplicate bus", withBus(BusConfig{Name: BusMusic}), "more than once"},
+		{"master with a parent", MixerConfig{Buses: []BusConfig{{Name: BusMaster, Parent: BusMusic}}}, "can't have a parent"},
+		{"unknown effect type", withBus(BusConfig{Name: "x", Effects: []EffectConfig{{Type: EffectCompressor + 1}}}), "unknown effect type"},
+		{"too many filters", withBus(BusConfig{Name: "x", Effects: tooManyFilters}), "more than 8 effects"},
+		{"compressors are not filters", withBus(BusConfig{Name: "x", Effects: compressors}), ""},
+		{"unknown parent", withBus(BusConfig{Name: "x", Parent: "y"}), "unknown parent or a cycle"},
+		{"cycle", MixerConfig{Buses: []BusConfig{
+			{Name: BusMaster}, {Name: "a", Parent: "b"}, {Name: "b", Parent: "a"},
+		}}, "unknown parent or a cycle"},
+		{"snapshot", MixerConfig{
+			Buses: []BusConfig{{Name: BusMaster}, {Name: BusMusic, Effects: []EffectConfig{lowpass}}},
+			Snapshots: []Snapshot{{Name: "underwater", Buses: map[string]SnapshotBus{
+				BusMusic: {Volume: &volume, Effects: map[string]map[string]float32{"muffle": {"frequency": 500}}},
+			}}},
+		}, ""},
+		{"snapshot with an unknown bus", MixerConfig{
+			Buses:     []BusConfig{{Name: BusMaster}},
+			Snapshots: []Snapshot{{Name: "paused", Buses: map[string]SnapshotBus{BusMusic: {Volume: &volume}}}},
+		}, "unknown bus"},
+		{"snapshot with an unknown effect", MixerConfig{
+			Buses: []BusConfig{{Name: BusMaster}, {Name: BusMusic}},
+			Snapshots: []Snapshot{{Name: "underwater", Buses: map[string]SnapshotBus{
+				BusMusic: {Effects: map[string]map[string]float32{"muffle": {"frequency": 500}}},
+			}}},
+		}, "unknown effect"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.config.Validate()
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate returned error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Fatalf("Validate error = %v, want %q", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestMixerConfigSorted(t *testing.T) {
+	cfg := MixerConfig{Buses: []BusConfig{
+		{Name: "combat", Parent: BusMusic},
+		{Name: BusMusic},
+		{Name: "stingers", Parent: "combat"},
+		{Name: BusMaster},
+	}}
+	sorted, err := cfg.sorted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ name, parent string }{
+		{BusMaster, ""}, {BusMusic, BusMaster}, {"combat", BusMusic}, {"stingers", "combat"},
+	}
+	if len(sorted) != len(want) {
+		t.Fatalf("sorted %d buses, want %d", len(sorted), len(want))
+	}
+	for i := range want {
+		if sorted[i].Name != want[i].name || sorted[i].Parent != want[i].parent {
+			t.Errorf("bus %d is %s under %q, want %s under %q",
+				i, sorted[i].Name, sorted[i].Parent, want[i].name, want[i].parent)
+		}
+	}
+	if cfg.Buses[1].Parent != "" {
+		t.Error("expected sorting to leave the config unchanged")
+	}
+}
+
+func testCompressor(params map[string]float32, reduction float32) *mixerEffect {
+	e := &mixerEffect{
+		config:    EffectConfig{Type: EffectCompressor, Params: params},
+		params:    map[string]*mixerValue{},
+		reduction: reduction,
+	}
+	for name, v := range e.config.params() {
+		value := newMixerValue(v)
+		e.params[name] = &value
+	}
+	return e
+}
+
+func TestMixerEffectCompress(t *testing.T) {
+	// The defaults are a -12 dB threshold and a 4:1 ratio, so a full scale
+	// level is reduced by 9 dB once the compressor settles
+	instant := map[string]float32{"attack": 0, "release": 0}
+	tests := []struct {
+		name      string
+		params    map[string]float32
+		reduction float32
+		level     float32
+		deltaTime float64
+		want      float32
+	}{
+		{"silence", instant, 0, 0, 0.01, 1},
+		{"below threshold", instant, 0, 0.1, 0.01, 1},
+		{"instant attack", instant, 0, 1, 0.01, 0.35481339},
+		{"makeup gain", map[string]float32{"attack": 0, "makeup": 3}, 0, 1, 0.01, 0.50118723},
+		{"ratio below 1", map[string]float32{"attack": 0, "ratio": 0.5}, 0, 1, 0.01, 1},
+		{"attack", nil, 0, 1, 0.01, 0.51945239},
+		{"release", nil, 9, 0, 0.2, 0.68305276},
+		{"paused", nil, 9, 0, 0, 0.35481339},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			e := testCompressor(test.params, test.reduction)
+			if got := e.compress(test.level, test.deltaTime); matrix.Abs(got-test.want) > 0.0001 {
+				t.Errorf("compress = %f, want %f", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,409 @@
+/******************************************************************************/
+/* music.go                                                                   */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+
+	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// SyncMode is when a music transition happens relative to the music that is
+// already playing
+type SyncMode uint8
+
+const (
+	// SyncImmediate starts the transition right away
+	SyncImmediate SyncMode = iota
+	// SyncBeat waits for the next beat of the playing cue
+	SyncBeat
+	// SyncBar waits for the start of the next bar of the playing cue
+	SyncBar
+	// SyncEnd waits for the playing cue to reach the end of its track
+	SyncEnd
+)
+
+var syncModeNames = []string{"immediate", "beat", "bar", "end"}
+
+func (s SyncMode) String() string {
+	if int(s) < len(syncModeNames) {
+		return syncModeNames[s]
+	}
+	return fmt.Sprintf("SyncMode(%d)", s)
+}
+
+// ParseSyncMode reads the name of a [SyncMode], such as "bar"
+func ParseSyncMode(name string) (SyncMode, error) {
+	i := slices.Index(syncModeNames, name)
+	if i < 0 {
+		return SyncImmediate, fmt.Errorf("unknown music sync mode %q", name)
+	}
+	return SyncMode(i), nil
+}
+
+// MusicStem is one layer of a [MusicCue]. Stems of a cue play together and
+// stay sample aligned, a stem is heard while the music intensity is at or
+// above its intensity.
+type MusicStem struct {
+	Key       string
+	Intensity float32
+}
+
+// MusicCue is a piece of music made of one or more stems that are started
+// together. The tempo is only needed for beat and bar synced transitions,
+// bars are 4 beats when BeatsPerBar is left at 0.
+type MusicCue struct {
+	Name        string
+	Stems       []MusicStem
+	BPM         float32
+	BeatsPerBar int
+	// Offset is the seconds into the track where the first beat lands
+	Offset float64
+	Loop   bool
+}
+
+// Transition is how [Music.Play] moves from the playing cue to the next one,
+// the fade is the length in seconds of the crossfade that starts at the sync
+// point
+type Transition struct {
+	Fade float64
+	Sync SyncMode
+}
+
+// Music plays music cues on the music bus with crossfades, beat and bar
+// synced transitions and intensity driven stem layering. It is stepped by
+// [Audio.Update].
+type Music struct {
+	audio     *Audio
+	cues      map[string]*musicCue
+	current   *musicTrack
+	outgoing  []*musicTrack
+	intensity float32
+	mutex     sync.Mutex
+}
+
+type musicCue struct {
+	MusicCue
+	clips []*AudioClip
+}
+
+type musicTrack struct {
+	cue     *musicCue
+	handles []VoiceHandle
+	group   VoiceHandle
+	// startsIn counts down the seconds until the track is heard, the track
+	// is scheduled ahead of time so that it starts on the sync point
+	startsIn float64
+	started  bool
+	fadeIn   float64
+	// stopsIn counts down the seconds until an outgoing track fades out
+	stopsIn  float64
+	stopping bool
+	fadeOut  float64
+}
+
+func newMusic(a *Audio) *Music {
+	return &Music{audio: a, cues: map[string]*musicCue{}}
+}
+
+// RegisterCue loads the stems of the cue so that it can be played by name.
+// Registering a cue with a name that is already used replaces it.
+func (m *Music) RegisterCue(adb assets.Database, cue MusicCue) error {
+	defer tracing.NewRegion("Music.RegisterCue").End()
+	if cue.Name == "" {
+		return errors.New("the music cue needs a name")
+	}
+	if len(cue.Stems) == 0 {
+		return fmt.Errorf("the music cue %q has no stems", cue.Name)
+	}
+	c := &musicCue{MusicCue: cue, clips: make([]*AudioClip, len(cue.Stems))}
+	for i := range cue.Stems {
+		clip, err := m.audio.LoadMusic(adb, cue.Stems[i].Key)
+		if err != nil {
+			return fmt.Errorf("failed to load the stem %q of the music cue %q: %w",
+				cue.Stems[i].Key, cue.Name, err)
+		}
+		c.clips[i] = clip
+	}
+	m.mutex.Lock()
+	m.cues[cue.Name] = c
+	m.mutex.Unlock()
+	return nil
+}
+
+// HasCue returns true if a cue was registered with the name
+func (m *Music) HasCue(name string) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	_, ok := m.cues[name]
+	return ok
+}
+
+// Current is the name of the cue that is playing or about to play, it is
+// blank when no music is playing
+func (m *Music) Current() string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.current == nil {
+		return ""
+	}
+	return m.current.cue.Name
+}
+
+// Intensity is the level set by [Music.SetIntensity]
+func (m *Music) Intensity() float32 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.intensity
+}
+
+// Play transitions to the registered cue. The next cue is scheduled to start
+// on the sync point of the playing cue and crossfades with it from there.
+// Playing the cue that is already playing does nothing.
+func (m *Music) Play(name string, transition Transition) error {
+	defer tracing.NewRegion("Music.Play").End()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	cue, ok := m.cues[name]
+	if !ok {
+		return fmt.Errorf("unknown music cue %q", name)
+	}
+	wait := 0.0
+	if prev := m.current; prev != nil {
+		if prev.cue == cue {
+			return nil
+		}
+		if prev.started {
+			wait = prev.untilSync(m.audio.soloud, transition.Sync)
+			prev.stopAfter(m.audio.soloud, wait, transition.Fade)
+		} else {
+			// The previous cue was never heard, so the new one takes its place
+			// on the same sync point
+			wait = max(0, prev.startsIn)
+			prev.stopAfter(m.audio.soloud, 0, 0)
+		}
+		m.outgoing = append(m.outgoing, prev)
+	}
+	m.current = m.start(cue, wait, transition.Fade)
+	return nil
+}
+
+// Stop fades out the playing cue over the given seconds
+func (m *Music) Stop(fade float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.current == nil {
+		return
+	}
+	m.current.stopAfter(m.audio.soloud, 0, fade)
+	m.outgoing = append(m.outgoing, m.current)
+	m.current = nil
+}
+
+// SetIntensity changes which stems of the playing cue are heard, stems fade
+// in or out over the given seconds. Stems that are faded out keep playing
+// silently so they are still aligned when they fade back in.
+func (m *Music) SetIntensity(level float32, fade float64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.intensity = level
+	t := m.current
+	if t == nil || (!t.started && t.fadeIn > 0) {
+		// A track that fades in picks up the intensity when it starts
+		return
+	}
+	for i, h := range t.handles {
+		target := m.stemVolume(t.cue, i)
+		if fade > 0 && t.started {
+			fadeVolume(m.audio.soloud, h, target, fade)
+		} else {
+			setVolume(m.audio.soloud, h, target)
+		}
+	}
+}
+
+// Update starts the fades of transitions that reached their sync point and
+// releases tracks that have finished
+func (m *Music) Update(deltaTime float64) {
+	defer tracing.NewRegion("Music.Update").End()
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	s := m.audio.soloud
+	if t := m.current; t != nil {
+		if !t.started {
+			t.startsIn -= deltaTime
+			if t.startsIn <= 0 {
+				t.started = true
+				if t.fadeIn > 0 {
+					for i, h := range t.handles {
+						fadeVolume(s, h, m.stemVolume(t.cue, i), t.fadeIn)
+					}
+				}
+			}
+		} else if !t.isPlaying(s) {
+			destroyVoiceGroup(s, t.group)
+			m.current = nil
+		}
+	}
+	m.outgoing = slices.DeleteFunc(m.outgoing, func(t *musicTrack) bool {
+		if !t.stopping {
+			t.stopsIn -= deltaTime
+			if t.stopsIn <= 0 {
+				t.stopping = true
+				if t.fadeOut > 0 {
+					for _, h := range t.handles {
+						fadeVolume(s, h, 0, t.fadeOut)
+					}
+				}
+			}
+		}
+		if t.isPlaying(s) {
+			return false
+		}
+		destroyVoiceGroup(s, t.group)
+		return true
+	})
+}
+
+// applyVolume follows a change of the music volume
+func (m *Music) applyVolume() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if t := m.current; t != nil && (t.started || t.fadeIn <= 0) {
+		for i, h := range t.handles {
+			setVolume(m.audio.soloud, h, m.stemVolume(t.cue, i))
+		}
+	}
+}
+
+func (m *Music) stemVolume(cue *musicCue, stem int) float32 {
+	if m.intensity < cue.Stems[stem].Intensity {
+		return 0
+	}
+	return m.audio.bgmVolume
+}
+
+// start plays the stems of the cue paused in one voice group, delays them to
+// the sync point and then unpauses the group so they all begin on the same
+// sample
+func (m *Music) start(cue *musicCue, wait, fade float64) *musicTrack {
+	s := m.audio.soloud
+	t := &musicTrack{
+		cue:      cue,
+		handles:  make([]VoiceHandle, len(cue.clips)),
+		group:    createVoiceGroup(s),
+		startsIn: wait,
+		fadeIn:   fade,
+	}
+	delay := uint32(math.Round(wait * float64(backendSamplerate(s))))
+	for i, clip := range cue.clips {
+		volume := m.stemVolume(cue, i)
+		if fade > 0 {
+			volume = 0
+		}
+		h := playPaused(s, m.audio.mixer.playBus(clip.bus), clip.source(), volume)
+		setLooping(s, h, cue.Loop)
+		// Silent stems must keep their place so they can fade back in aligned
+		setInaudibleBehavior(s, h, true, false)
+		setProtectVoice(s, h, true)
+		if delay > 0 {
+			setDelaySamples(s, h, delay)
+		}
+		addVoiceToGroup(s, t.group, h)
+		t.handles[i] = h
+	}
+	setPause(s, t.group, false)
+	if wait <= 0 {
+		t.started = true
+		if fade > 0 {
+			for i, h := range t.handles {
+				fadeVolume(s, h, m.stemVolume(cue, i), fade)
+			}
+		}
+	}
+	return t
+}
+
+// stopAfter stops the track at the sync point, fading it out from there
+func (t *musicTrack) stopAfter(soloud SoloudHandle, wait, fade float64) {
+	t.stopsIn = wait
+	t.fadeOut = fade
+	for _, h := range t.handles {
+		if !isValidVoiceHandle(soloud, h) {
+			continue
+		}
+		if !t.started {
+			stopAudio(soloud, h)
+			continue
+		}
+		scheduleStop(soloud, h, wait+fade)
+	}
+	if wait <= 0 {
+		t.stopping = true
+		if fade > 0 {
+			for _, h := range t.handles {
+				fadeVolume(soloud, h, 0, fade)
+			}
+		}
+	}
+}
+
+func (t *musicTrack) isPlaying(soloud SoloudHandle) bool {
+	return slices.ContainsFunc(t.handles, func(h VoiceHandle) bool {
+		return isValidVoiceHandle(soloud, h)
+	})
+}
+
+// untilSync is the seconds until the track reaches the sync point
+func (t *musicTrack) untilSync(soloud SoloudHandle, sync SyncMode) float64 {
+	for i, h := range t.handles {
+		if isValidVoiceHandle(soloud, h) {
+			return syncWait(streamPosition(soloud, h), t.cue.clips[i].Length(), &t.cue.MusicCue, sync)
+		}
+	}
+	return 0
+}
+
+// syncWait is the seconds from the position in the track to the next sync
+// point of the cue. The end of a looping track is also a bar and beat line.
+func syncWait(position, length float64, cue *MusicCue, sync SyncMode) float64 {
+	if length > 0 {
+		position = math.Mod(position, length)
+	}
+	switch sync {
+	case SyncEnd:
+		return max(0, length-position)
+	case SyncBeat, SyncBar:
+		if cue.BPM <= 0 {
+			return 0
+		}
+		unit := 60 / float64(cue.BPM)
+		if sync == SyncBar {
+			beats := cue.BeatsPerBar
+			if beats <= 0 {
+				beats = 4
+			}
+			unit *= float64(beats)
+		}
+		if position < cue.Offset {
+			return cue.Offset - position
+		}
+		// A position a hair past a line still counts as being on the line
+		const onLine = 0.001
+		next := cue.Offset + math.Ceil((position-cue.Offset)/unit-onLine)*unit
+		if length > 0 && next > length {
+			next = length
+		}
+		return max(0, next-position)
+	}
+	return 0
+}
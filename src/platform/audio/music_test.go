@@ -0,0 +1,51 @@
+/******************************************************************************/
+/* music_test.go                                                              */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package audio
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSyncWait(t *testing.T) {
+	// 120 BPM is a beat every half second and a 4 beat bar every 2 seconds
+	cue := MusicCue{BPM: 120}
+	waltz := MusicCue{BPM: 120, BeatsPerBar: 3}
+	late := MusicCue{BPM: 120, Offset: 0.25}
+	tests := []struct {
+		name     string
+		cue      MusicCue
+		sync     SyncMode
+		position float64
+		length   float64
+		want     float64
+	}{
+		{"immediate", cue, SyncImmediate, 3.3, 10, 0},
+		{"end", cue, SyncEnd, 3, 10, 7},
+		{"end of a looped track", cue, SyncEnd, 13, 10, 7},
+		{"end of an unknown length", cue, SyncEnd, 3, 0, 0},
+		{"beat", cue, SyncBeat, 0.7, 10, 0.3},
+		{"on a beat", cue, SyncBeat, 1, 10, 0},
+		{"just past a beat", cue, SyncBeat, 1.0005, 10, 0},
+		{"beat of a looped track", cue, SyncBeat, 10.7, 10, 0.3},
+		{"beat without a tempo", MusicCue{}, SyncBeat, 0.7, 10, 0},
+		{"bar", cue, SyncBar, 0.7, 10, 1.3},
+		{"bar of 3 beats", waltz, SyncBar, 0.7, 10, 0.8},
+		{"bar past the end", cue, SyncBar, 8.5, 9, 0.5},
+		{"before the offset", late, SyncBeat, 0.1, 10, 0.15},
+		{"beat after the offset", late, SyncBeat, 0.8, 10, 0.45},
+		{"bar after the offset", late, SyncBar, 0.8, 10, 1.45},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := syncWait(test.position, test.length, &test.cue, test.sync)
+			if math.Abs(got-test.want) > 1e-9 {
+				t.Errorf("syncWait = %f, want %f", got, test.want)
+			}
+		})
+	}
+}
@@ -24,6 +24,10 @@ import (
 
 type SoloudHandle = *C.Soloud
 type SoloudWav = *C.Wav
+type SoloudWavStream = *C.WavStream
+type SoloudSource = *C.AudioSource
+type SoloudBus = *C.Bus
+type SoloudFilter = *C.Filter
 type VoiceHandle = uint32
 
 const InvalidVoiceHandle = VoiceHandle(0)
@@ -68,6 +72,43 @@ func wavSetVolume(wav SoloudWav, volume float32) {
 	C.Wav_setVolume(wav, C.float(volume))
 }
 
+func wavSource(wav SoloudWav) SoloudSource {
+	return (*C.AudioSource)(wav)
+}
+
+func wavStreamCreate() SoloudWavStream {
+	return C.WavStream_create()
+}
+
+func wavStreamLoadMem(stream SoloudWavStream, data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("there was no audio memory to stream")
+	}
+	// The stream copies the data and owns the copy, so the Go slice is free
+	// to be collected while the stream decodes from it
+	res := int(C.WavStream_loadMemEx(stream, (*C.uchar)(unsafe.Pointer(&data[0])), C.uint(len(data)), C.int(1), C.int(1)))
+	if res != 0 {
+		return fmt.Errorf("there was an error loading the audio stream memory: %d", res)
+	}
+	return nil
+}
+
+func wavStreamDestroy(stream SoloudWavStream) {
+	C.WavStream_destroy(stream)
+}
+
+func wavStreamSetVolume(stream SoloudWavStream, volume float32) {
+	C.WavStream_setVolume(stream, C.float(volume))
+}
+
+func wavStreamLength(stream SoloudWavStream) float64 {
+	return float64(C.WavStream_getLength(stream))
+}
+
+func wavStreamSource(stream SoloudWavStream) SoloudSource {
+	return (*C.AudioSource)(stream)
+}
+
 func setVolume(soloud SoloudHandle, handle uint32, volume float32) {
 	C.Soloud_setVolume(soloud, C.uint(handle), C.float(volume))
 }
@@ -76,16 +117,27 @@ func clipLength(wav SoloudWav) float64 {
 	return float64(C.Wav_getLength(wav))
 }
 
-func play(soloud SoloudHandle, wav SoloudWav) VoiceHandle {
-	return VoiceHandle(C.Soloud_play(soloud, (*C.AudioSource)(wav)))
+func play(soloud SoloudHandle, source SoloudSource) VoiceHandle {
+	return VoiceHandle(C.Soloud_play(soloud, source))
+}
+
+func busPlay(bus SoloudBus, source SoloudSource) VoiceHandle {
+	return VoiceHandle(C.Bus_play(bus, source))
+}
+
+func playPaused(soloud SoloudHandle, bus SoloudBus, source SoloudSource, volume float32) VoiceHandle {
+	if bus != nil {
+		return VoiceHandle(C.Bus_playEx(bus, source, C.float(volume), C.float(0), C.int(1)))
+	}
+	return VoiceHandle(C.Soloud_playEx(soloud, source, C.float(volume), C.float(0), C.int(1), C.uint(0)))
 }
 
 func stopAudio(soloud SoloudHandle, handle VoiceHandle) {
 	C.Soloud_stop(soloud, (C.uint)(handle))
 }
 
-func stopAudioSource(soloud SoloudHandle, wav SoloudWav) {
-	C.Soloud_stopAudioSource(soloud, (*C.AudioSource)(wav))
+func stopAudioSource(soloud SoloudHandle, source SoloudSource) {
+	C.Soloud_stopAudioSource(soloud, source)
 }
 
 func isValidVoiceHandle(soloud SoloudHandle, handle VoiceHandle) bool {
@@ -103,3 +155,179 @@ func setLooping(soloud SoloudHandle, handle uint32, loop bool) {
 		C.Soloud_setLooping(soloud, C.uint(handle), C.int(0))
 	}
 }
+
+func play3d(soloud SoloudHandle, bus SoloudBus, source SoloudSource, pos, vel [3]float32, volume float32, paused bool) VoiceHandle {
+	p := C.int(0)
+	if paused {
+		p = 1
+	}
+	if bus != nil {
+		return VoiceHandle(C.Bus_play3dEx(bus, source,
+			C.float(pos[0]), C.float(pos[1]), C.float(pos[2]),
+			C.float(vel[0]), C.float(vel[1]), C.float(vel[2]),
+			C.float(volume), p))
+	}
+	return VoiceHandle(C.Soloud_play3dEx(soloud, source,
+		C.float(pos[0]), C.float(pos[1]), C.float(pos[2]),
+		C.float(vel[0]), C.float(vel[1]), C.float(vel[2]),
+		C.float(volume), p, C.uint(0)))
+}
+
+func setPause(soloud SoloudHandle, handle VoiceHandle, pause bool) {
+	if pause {
+		C.Soloud_setPause(soloud, C.uint(handle), C.int(1))
+	} else {
+		C.Soloud_setPause(soloud, C.uint(handle), C.int(0))
+	}
+}
+
+func update3dAudio(soloud SoloudHandle) {
+	C.Soloud_update3dAudio(soloud)
+}
+
+func set3dSoundSpeed(soloud SoloudHandle, speed float32) {
+	C.Soloud_set3dSoundSpeed(soloud, C.float(speed))
+}
+
+func set3dListener(soloud SoloudHandle, pos, at, up, vel [3]float32) {
+	C.Soloud_set3dListenerParametersEx(soloud,
+		C.float(pos[0]), C.float(pos[1]), C.float(pos[2]),
+		C.float(at[0]), C.float(at[1]), C.float(at[2]),
+		C.float(up[0]), C.float(up[1]), C.float(up[2]),
+		C.float(vel[0]), C.float(vel[1]), C.float(vel[2]))
+}
+
+func set3dSource(soloud SoloudHandle, handle VoiceHandle, pos, vel [3]float32) {
+	C.Soloud_set3dSourceParametersEx(soloud, C.uint(handle),
+		C.float(pos[0]), C.float(pos[1]), C.float(pos[2]),
+		C.float(vel[0]), C.float(vel[1]), C.float(vel[2]))
+}
+
+func set3dSourceMinMaxDistance(soloud SoloudHandle, handle VoiceHandle, minDistance, maxDistance float32) {
+	C.Soloud_set3dSourceMinMaxDistance(soloud, C.uint(handle), C.float(minDistance), C.float(maxDistance))
+}
+
+func set3dSourceAttenuation(soloud SoloudHandle, handle VoiceHandle, model uint32, rolloff float32) {
+	C.Soloud_set3dSourceAttenuation(soloud, C.uint(handle), C.uint(model), C.float(rolloff))
+}
+
+func set3dSourceDopplerFactor(soloud SoloudHandle, handle VoiceHandle, factor float32) {
+	C.Soloud_set3dSourceDopplerFactor(soloud, C.uint(handle), C.float(factor))
+}
+
+func busCreate() SoloudBus {
+	return C.Bus_create()
+}
+
+func busDestroy(bus SoloudBus) {
+	C.Bus_destroy(bus)
+}
+
+// busStart plays the bus on its parent, or on the engine if it has no parent,
+// the filters of the bus have to be set before it is started
+func busStart(soloud SoloudHandle, parent, bus SoloudBus) VoiceHandle {
+	if parent != nil {
+		return VoiceHandle(C.Bus_play(parent, (*C.AudioSource)(unsafe.Pointer(bus))))
+	}
+	return VoiceHandle(C.Soloud_play(soloud, (*C.AudioSource)(unsafe.Pointer(bus))))
+}
+
+func busSetFilter(bus SoloudBus, slot int, filter SoloudFilter) {
+	C.Bus_setFilter(bus, C.uint(slot), filter)
+}
+
+func busSetVisualization(bus SoloudBus, enable bool) {
+	if enable {
+		C.Bus_setVisualizationEnable(bus, C.int(1))
+	} else {
+		C.Bus_setVisualizationEnable(bus, C.int(0))
+	}
+}
+
+func busApproximateVolume(bus SoloudBus, channel int) float32 {
+	return float32(C.Bus_getApproximateVolume(bus, C.uint(channel)))
+}
+
+func setFilterParameter(soloud SoloudHandle, handle VoiceHandle, slot int, attribute int, value float32) {
+	C.Soloud_setFilterParameter(soloud, C.uint(handle), C.uint(slot), C.uint(attribute), C.float(value))
+}
+
+func biquadFilterCreate(filterType int, frequency, resonance float32) SoloudFilter {
+	f := C.BiquadResonantFilter_create()
+	C.BiquadResonantFilter_setParams(f, C.int(filterType), C.float(frequency), C.float(resonance))
+	return (*C.Filter)(unsafe.Pointer(f))
+}
+
+func echoFilterCreate(delay, decay, filter float32) SoloudFilter {
+	f := C.EchoFilter_create()
+	C.EchoFilter_setParamsEx(f, C.float(delay), C.float(decay), C.float(filter))
+	return (*C.Filter)(unsafe.Pointer(f))
+}
+
+func freeverbFilterCreate(freeze, roomSize, damp, width float32) SoloudFilter {
+	f := C.FreeverbFilter_create()
+	C.FreeverbFilter_setParams(f, C.float(freeze), C.float(roomSize), C.float(damp), C.float(width))
+	return (*C.Filter)(unsafe.Pointer(f))
+}
+
+func filterDestroy(effect EffectType, filter SoloudFilter) {
+	switch effect {
+	case EffectLowPass, EffectHighPass:
+		C.BiquadResonantFilter_destroy((*C.BiquadResonantFilter)(unsafe.Pointer(filter)))
+	case EffectEcho:
+		C.EchoFilter_destroy((*C.EchoFilter)(unsafe.Pointer(filter)))
+	case EffectReverb:
+		C.FreeverbFilter_destroy((*C.FreeverbFilter)(unsafe.Pointer(filter)))
+	}
+}
+
+func fadeVolume(soloud SoloudHandle, handle VoiceHandle, to float32, seconds float64) {
+	C.Soloud_fadeVolume(soloud, C.uint(handle), C.float(to), C.double(seconds))
+}
+
+func scheduleStop(soloud SoloudHandle, handle VoiceHandle, seconds float64) {
+	C.Soloud_scheduleStop(soloud, C.uint(handle), C.double(seconds))
+}
+
+func streamPosition(soloud SoloudHandle, handle VoiceHandle) float64 {
+	return float64(C.Soloud_getStreamPosition(soloud, C.uint(handle)))
+}
+
+func backendSamplerate(soloud SoloudHandle) uint32 {
+	return uint32(C.Soloud_getBackendSamplerate(soloud))
+}
+
+func setDelaySamples(soloud SoloudHandle, handle VoiceHandle, samples uint32) {
+	C.Soloud_setDelaySamples(soloud, C.uint(handle), C.uint(samples))
+}
+
+func setInaudibleBehavior(soloud SoloudHandle, handle VoiceHandle, mustTick, kill bool) {
+	tick, k := C.int(0), C.int(0)
+	if mustTick {
+		tick = 1
+	}
+	if kill {
+		k = 1
+	}
+	C.Soloud_setInaudibleBehavior(soloud, C.uint(handle), tick, k)
+}
+
+func setProtectVoice(soloud SoloudHandle, handle VoiceHandle, protect bool) {
+	if protect {
+		C.Soloud_setProtectVoice(soloud, C.uint(handle), C.int(1))
+	} else {
+		C.Soloud_setProtectVoice(soloud, C.uint(handle), C.int(0))
+	}
+}
+
+func createVoiceGroup(soloud SoloudHandle) VoiceHandle {
+	return VoiceHandle(C.Soloud_createVoiceGroup(soloud))
+}
+
+func addVoiceToGroup(soloud SoloudHandle, group, handle VoiceHandle) {
+	C.Soloud_addVoiceToGroup(soloud, C.uint(group), C.uint(handle))
+}
+
+func destroyVoiceGroup(soloud SoloudHandle, group VoiceHandle) {
+	C.Soloud_destroyVoiceGroup(soloud, C.uint(group))
+}
@@ -11,14 +11,20 @@ import (
 	"fmt"
 	"math"
 	"runtime"
+	"sync"
 
 	"kaijuengine.com/engine/assets"
 	"kaijuengine.com/klib"
+	"kaijuengine.com/matrix"
 )
 
+// AudioClip is a loaded sound or piece of music. Sounds are decoded into
+// memory up front, music is streamed and decoded while it plays.
 type AudioClip struct {
 	wav     SoloudWav
+	stream  SoloudWavStream
 	key     string
+	bus     string
 	handles []VoiceHandle
 	isSFX   bool
 }
@@ -31,13 +37,19 @@ type Audio struct {
 	bgmUnmutedVolume float32
 	sfx              map[string]*AudioClip
 	bgm              map[string]*AudioClip
+	voices3d         map[VoiceHandle]*voice3d
+	voices3dMutex    sync.Mutex
+	listenerPosition matrix.Vec3
+	mixer            *Mixer
+	music            *Music
 }
 
 func New() (*Audio, error) {
 	audio := &Audio{
-		sfx:    make(map[string]*AudioClip),
-		bgm:    make(map[string]*AudioClip),
-		soloud: create(),
+		sfx:      make(map[string]*AudioClip),
+		bgm:      make(map[string]*AudioClip),
+		voices3d: make(map[VoiceHandle]*voice3d),
+		soloud:   create(),
 	}
 	if audio.soloud == nil {
 		return audio, errors.New("failed to create an instance of soloud")
@@ -47,15 +59,57 @@ func New() (*Audio, error) {
 		return audio, fmt.Errorf("failed to initialize soloud: (%d) %s",
 			errCode, errToString(audio.soloud, errCode))
 	}
+	audio.mixer = newMixer(audio.soloud)
+	audio.music = newMusic(audio)
+	if err := audio.mixer.Configure(DefaultMixerConfig()); err != nil {
+		return audio, err
+	}
 	audio.SetSoundVolume(0.5)
 	audio.SetMusicVolume(0.5)
-	runtime.AddCleanup(audio, func(soloud SoloudHandle) {
-		deinitialize(soloud)
-		destroy(soloud)
-	}, audio.soloud)
+	type AudioFreeState struct {
+		soloud SoloudHandle
+		mixer  *Mixer
+	}
+	runtime.AddCleanup(audio, func(s AudioFreeState) {
+		// The buses stop themselves on destroy, so they go before the engine
+		s.mixer.destroy()
+		deinitialize(s.soloud)
+		destroy(s.soloud)
+	}, AudioFreeState{audio.soloud, audio.mixer})
 	return audio, nil
 }
 
+// Mixer returns the bus mixer that every clip is played through
+func (a *Audio) Mixer() *Mixer {
+	return a.mixer
+}
+
+// LoadMixer configures the mixer from a [MixerConfig] asset
+func (a *Audio) LoadMixer(adb assets.Database, key string) error {
+	cfg, err := LoadMixerConfig(adb, key)
+	if err != nil {
+		return err
+	}
+	return a.mixer.Configure(cfg)
+}
+
+// Music returns the player for music cues, crossfades and stem layers
+func (a *Audio) Music() *Music {
+	return a.music
+}
+
+// Update steps the mixer and the music and applies the 3D changes of the
+// frame, the host calls this each frame after the late update
+func (a *Audio) Update(deltaTime float64) {
+	if a.mixer != nil {
+		a.mixer.Update(deltaTime)
+	}
+	if a.music != nil {
+		a.music.Update(deltaTime)
+	}
+	a.Update3D()
+}
+
 func (a *Audio) MusicById(id string) (*AudioClip, bool) {
 	c, ok := a.bgm[id]
 	return c, ok
@@ -119,9 +173,13 @@ func (a *Audio) LoadMusic(adb assets.Database, key string) (*AudioClip, error) {
 	if err != nil {
 		return nil, err
 	}
-	clip := newClip(a, key, data)
+	clip, err := newStreamClip(a, key, data)
+	if err != nil {
+		return nil, err
+	}
+	clip.bus = BusMusic
 	a.bgm[clip.key] = clip
-	wavSetVolume(clip.wav, a.bgmVolume)
+	clip.setVolume(a.bgmVolume)
 	return clip, nil
 }
 
@@ -138,13 +196,22 @@ func (a *Audio) LoadSound(adb assets.Database, key string) (*AudioClip, error) {
 	}
 	clip := newClip(a, key, data)
 	clip.isSFX = true
+	clip.bus = BusSFX
 	a.sfx[clip.key] = clip
-	wavSetVolume(clip.wav, a.sfxVolume)
+	clip.setVolume(a.sfxVolume)
 	return clip, nil
 }
 
+// playClip plays the clip on its bus of the mixer
+func (a *Audio) playClip(clip *AudioClip) VoiceHandle {
+	if bus := a.mixer.playBus(clip.bus); bus != nil {
+		return busPlay(bus, clip.source())
+	}
+	return play(a.soloud, clip.source())
+}
+
 func (a *Audio) Play(clip *AudioClip) VoiceHandle {
-	handle := play(a.soloud, clip.wav)
+	handle := a.playClip(clip)
 	if clip.isSFX {
 		if sfx, ok := a.sfx[clip.key]; ok {
 			sfx.handles = append(sfx.handles, handle)
@@ -159,11 +226,13 @@ func (a *Audio) Play(clip *AudioClip) VoiceHandle {
 
 func (a *Audio) Stop(handle VoiceHandle) {
 	stopAudio(a.soloud, handle)
+	a.forgetVoice3D(handle)
 }
 
 func (a *Audio) StopSource(clip *AudioClip) {
-	stopAudioSource(a.soloud, clip.wav)
+	stopAudioSource(a.soloud, clip.source())
 	clip.handles = clip.handles[:0]
+	a.forgetClipVoices3D(clip)
 }
 
 func (a *Audio) IsValidVoiceHandle(handle VoiceHandle) bool {
@@ -176,14 +245,14 @@ func (a *Audio) Seek(handle VoiceHandle, seconds float64) bool {
 
 func (a *Audio) PlaySound(key string) (*AudioClip, VoiceHandle) {
 	if sfx, ok := a.sfx[key]; ok {
-		return sfx, play(a.soloud, sfx.wav)
+		return sfx, a.playClip(sfx)
 	}
 	return nil, 0
 }
 
 func (a *Audio) PlayMusic(key string) (*AudioClip, VoiceHandle) {
 	if bgm, ok := a.bgm[key]; ok {
-		handle := play(a.soloud, bgm.wav)
+		handle := a.playClip(bgm)
 		setLooping(a.soloud, handle, true)
 		bgm.handles = append(bgm.handles, handle)
 		return bgm, handle
@@ -198,24 +267,56 @@ func (a *Audio) SetLooping(handle VoiceHandle, looping bool) {
 func (a *Audio) SetSoundVolume(volume float32) {
 	a.sfxVolume = klib.Clamp(volume, 0.0, 1.0)
 	for k := range a.sfx {
-		wavSetVolume(a.sfx[k].wav, volume)
+		a.sfx[k].setVolume(volume)
 	}
 }
 
 func (a *Audio) SetMusicVolume(volume float32) {
 	a.bgmVolume = klib.Clamp(volume, 0.0, 1.0)
 	for _, v := range a.bgm {
-		wavSetVolume(v.wav, volume)
+		v.setVolume(volume)
 		for i := range v.handles {
 			setVolume(a.soloud, v.handles[i], volume)
 		}
 	}
+	if a.music != nil {
+		a.music.applyVolume()
+	}
 }
 
+// Bus is the name of the mixer bus the clip plays on
+func (c *AudioClip) Bus() string { return c.bus }
+
+// SetBus routes the clip to the named mixer bus, voices that are already
+// playing stay on their bus
+func (c *AudioClip) SetBus(bus string) { c.bus = bus }
+
+// IsStreamed is true for clips that are decoded while they play rather than
+// up front, which is how music is loaded
+func (c *AudioClip) IsStreamed() bool { return c.stream != nil }
+
 func (c *AudioClip) Length() float64 {
+	if c.stream != nil {
+		return wavStreamLength(c.stream)
+	}
 	return clipLength(c.wav)
 }
 
+func (c *AudioClip) source() SoloudSource {
+	if c.stream != nil {
+		return wavStreamSource(c.stream)
+	}
+	return wavSource(c.wav)
+}
+
+func (c *AudioClip) setVolume(volume float32) {
+	if c.stream != nil {
+		wavStreamSetVolume(c.stream, volume)
+	} else {
+		wavSetVolume(c.wav, volume)
+	}
+}
+
 func newClip(a *Audio, key string, data []byte) *AudioClip {
 	// TODO:  This should use the asset database to load the wav rather than
 	// the file path to the audio file
@@ -233,3 +334,21 @@ func newClip(a *Audio, key string, data []byte) *AudioClip {
 	}, ClipFreeState{a, clip.wav})
 	return clip
 }
+
+func newStreamClip(a *Audio, key string, data []byte) (*AudioClip, error) {
+	clip := &AudioClip{
+		key:    key,
+		stream: wavStreamCreate(),
+	}
+	type ClipFreeState struct {
+		audio  *Audio // Hold the audio pointer so the system isn't cleaned up before the stream
+		stream SoloudWavStream
+	}
+	runtime.AddCleanup(clip, func(s ClipFreeState) {
+		wavStreamDestroy(s.stream)
+	}, ClipFreeState{a, clip.stream})
+	if err := wavStreamLoadMem(clip.stream, data); err != nil {
+		return nil, err
+	}
+	return clip, nil
+}
@@ -0,0 +1,201 @@
+/******************************************************************************/
+/* spatial.go                                                                 */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package audio
+
+import (
+	"math"
+
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// AttenuationModel is how the volume of a 3D voice falls off with distance
+// between its min and max distance. The values match the SoLoud models.
+type AttenuationModel uint32
+
+const (
+	AttenuationNone AttenuationModel = iota
+	AttenuationInverseDistance
+	AttenuationLinearDistance
+	AttenuationExponentialDistance
+)
+
+// Cone makes a 3D voice directional. Inside of the inner angle the voice
+// plays at full volume, outside of the outer angle it plays at the outer
+// volume and between the two it is blended. Angles are the full width of the
+// cone in degrees, an outer angle of 0 or an inner angle of 360 or more plays
+// the same in all directions.
+type Cone struct {
+	InnerAngle  float32
+	OuterAngle  float32
+	OuterVolume float32
+}
+
+// Spatial are the settings of a 3D voice
+type Spatial struct {
+	MinDistance   float32
+	MaxDistance   float32
+	Attenuation   AttenuationModel
+	Rolloff       float32
+	DopplerFactor float32
+	Cone          Cone
+}
+
+// DefaultSpatial is full volume up to 1 unit away, falling off to 100 units
+// with a realistic doppler
+func DefaultSpatial() Spatial {
+	return Spatial{
+		MinDistance:   1,
+		MaxDistance:   100,
+		Attenuation:   AttenuationInverseDistance,
+		Rolloff:       1,
+		DopplerFactor: 1,
+	}
+}
+
+// Voice3D is where a 3D voice is and which way it faces, velocity is in units
+// per second and is only used for doppler
+type Voice3D struct {
+	Position matrix.Vec3
+	Velocity matrix.Vec3
+	Forward  matrix.Vec3
+}
+
+type voice3d struct {
+	clip    *AudioClip
+	spatial Spatial
+	Voice3D
+}
+
+func (c Cone) isOmni() bool {
+	return c.OuterAngle <= 0 || c.InnerAngle >= 360
+}
+
+// Gain is the volume scale of the cone for a voice facing forward that is
+// heard from the direction toListener
+func (c Cone) Gain(forward, toListener matrix.Vec3) float32 {
+	if c.isOmni() {
+		return 1
+	}
+	if forward.Length() <= matrix.FloatSmallestNonzero ||
+		toListener.Length() <= matrix.FloatSmallestNonzero {
+		return 1
+	}
+	dot := matrix.Clamp(matrix.Vec3Dot(forward.Normal(), toListener.Normal()), -1, 1)
+	// The angles are the full width of the cone, so the listener's angle off
+	// of forward is doubled to compare against them
+	angle := float32(math.Acos(float64(dot))*180/math.Pi) * 2
+	inner := max(0, c.InnerAngle)
+	outer := max(inner, c.OuterAngle)
+	outerVolume := max(0, min(c.OuterVolume, 1))
+	switch {
+	case angle <= inner:
+		return 1
+	case angle >= outer:
+		return outerVolume
+	}
+	t := (angle - inner) / (outer - inner)
+	return 1 + (outerVolume-1)*t
+}
+
+func vec3Floats(v matrix.Vec3) [3]float32 {
+	return [3]float32{float32(v.X()), float32(v.Y()), float32(v.Z())}
+}
+
+// SetSoundSpeed sets the speed of sound used for doppler, in units per
+// second. The default of 343 is meters per second in air.
+func (a *Audio) SetSoundSpeed(speed float32) {
+	set3dSoundSpeed(a.soloud, max(speed, math.SmallestNonzeroFloat32))
+}
+
+// SetListener places the ear that 3D voices are heard from, typically the
+// primary camera which the host does each frame
+func (a *Audio) SetListener(position, forward, up, velocity matrix.Vec3) {
+	a.voices3dMutex.Lock()
+	defer a.voices3dMutex.Unlock()
+	a.listenerPosition = position
+	set3dListener(a.soloud, vec3Floats(position), vec3Floats(forward),
+		vec3Floats(up), vec3Floats(velocity))
+}
+
+// Play3D plays the sound from a point in the world. The voice is moved
+// with [Audio.SetVoice3D] and mixed the next time [Audio.Update3D] runs.
+func (a *Audio) Play3D(clip *AudioClip, voice Voice3D, spatial Spatial) VoiceHandle {
+	defer tracing.NewRegion("Audio.Play3D").End()
+	volume := a.clipVolume(clip)
+	// Start paused so the voice isn't heard before its 3D settings are applied
+	handle := play3d(a.soloud, a.mixer.playBus(clip.bus), clip.source(), vec3Floats(voice.Position),
+		vec3Floats(voice.Velocity), volume, true)
+	maxDistance := max(spatial.MaxDistance, spatial.MinDistance)
+	set3dSourceMinMaxDistance(a.soloud, handle, spatial.MinDistance, maxDistance)
+	set3dSourceAttenuation(a.soloud, handle, uint32(spatial.Attenuation), spatial.Rolloff)
+	set3dSourceDopplerFactor(a.soloud, handle, spatial.DopplerFactor)
+	a.voices3dMutex.Lock()
+	v := &voice3d{clip: clip, spatial: spatial, Voice3D: voice}
+	a.voices3d[handle] = v
+	setVolume(a.soloud, handle, volume*v.coneGain(a.listenerPosition))
+	clip.handles = append(clip.handles, handle)
+	a.voices3dMutex.Unlock()
+	update3dAudio(a.soloud)
+	setPause(a.soloud, handle, false)
+	return handle
+}
+
+// SetVoice3D moves a voice started with [Audio.Play3D]
+func (a *Audio) SetVoice3D(handle VoiceHandle, voice Voice3D) {
+	a.voices3dMutex.Lock()
+	defer a.voices3dMutex.Unlock()
+	v, ok := a.voices3d[handle]
+	if !ok {
+		return
+	}
+	v.Voice3D = voice
+	set3dSource(a.soloud, handle, vec3Floats(voice.Position), vec3Floats(voice.Velocity))
+}
+
+// Update3D applies the listener and voice changes of the frame, it is called
+// by [Audio.Update]
+func (a *Audio) Update3D() {
+	defer tracing.NewRegion("Audio.Update3D").End()
+	a.voices3dMutex.Lock()
+	for handle, v := range a.voices3d {
+		if !isValidVoiceHandle(a.soloud, handle) {
+			delete(a.voices3d, handle)
+			continue
+		}
+		setVolume(a.soloud, handle, a.clipVolume(v.clip)*v.coneGain(a.listenerPosition))
+	}
+	a.voices3dMutex.Unlock()
+	update3dAudio(a.soloud)
+}
+
+func (a *Audio) forgetVoice3D(handle VoiceHandle) {
+	a.voices3dMutex.Lock()
+	delete(a.voices3d, handle)
+	a.voices3dMutex.Unlock()
+}
+
+func (a *Audio) forgetClipVoices3D(clip *AudioClip) {
+	a.voices3dMutex.Lock()
+	for handle, v := range a.voices3d {
+		if v.clip == clip {
+			delete(a.voices3d, handle)
+		}
+	}
+	a.voices3dMutex.Unlock()
+}
+
+func (a *Audio) clipVolume(clip *AudioClip) float32 {
+	if clip.isSFX {
+		return a.sfxVolume
+	}
+	return a.bgmVolume
+}
+
+func (v *voice3d) coneGain(listener matrix.Vec3) float32 {
+	return v.spatial.Cone.Gain(v.Forward, listener.Subtract(v.Position))
+}
@@ -0,0 +1,45 @@
+/******************************************************************************/
+/* spatial_test.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package audio
+
+import (
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+func TestConeGain(t *testing.T) {
+	forward := matrix.Vec3Forward()
+	cone := Cone{InnerAngle: 90, OuterAngle: 180, OuterVolume: 0.25}
+	tests := []struct {
+		name       string
+		cone       Cone
+		forward    matrix.Vec3
+		toListener matrix.Vec3
+		want       float32
+	}{
+		{"omni without outer angle", Cone{InnerAngle: 90, OuterVolume: 0.25}, forward, matrix.Vec3Backward(), 1},
+		{"omni with full inner angle", Cone{InnerAngle: 360, OuterAngle: 360}, forward, matrix.Vec3Backward(), 1},
+		{"zero forward", cone, matrix.Vec3Zero(), matrix.Vec3Backward(), 1},
+		{"zero direction", cone, forward, matrix.Vec3Zero(), 1},
+		{"in front", cone, forward, forward, 1},
+		{"edge of inner", cone, forward, matrix.NewVec3(1, 0, -1), 1},
+		{"half way to outer", cone, forward, matrix.NewVec3(0.9238795, 0, -0.38268343), 0.625},
+		{"edge of outer", cone, forward, matrix.Vec3Right(), 0.25},
+		{"behind", cone, forward, matrix.Vec3Backward(), 0.25},
+		{"unnormalized", cone, forward.Scale(5), matrix.Vec3Right().Scale(0.1), 0.25},
+		{"outer volume clamped", Cone{InnerAngle: 0, OuterAngle: 90, OuterVolume: 2}, forward, matrix.Vec3Backward(), 1},
+		{"outer below inner", Cone{InnerAngle: 90, OuterAngle: 45, OuterVolume: 0}, forward, matrix.Vec3Right(), 0},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.cone.Gain(test.forward, test.toListener); !matrix.Approx(got, test.want) {
+				t.Errorf("Gain = %f, want %f", got, test.want)
+			}
+		})
+	}
+}
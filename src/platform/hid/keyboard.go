@@ -139,10 +139,15 @@ type keyCallback struct {
 }
 
 type Keyboard struct {
-	keyStates      [KeyboardKeyMaximum]KeyState
-	lastClicked    [KeyboardKeyMaximum]time.Time
-	nextCallbackId KeyCallbackId
-	keyCallbacks   []keyCallback
+	keyStates          [KeyboardKeyMaximum]KeyState
+	lastClicked        [KeyboardKeyMaximum]time.Time
+	nextCallbackId     KeyCallbackId
+	keyCallbacks       []keyCallback
+	nextTextCallbackId TextCallbackId
+	textCallbacks      []textCallback
+	composition        string
+	compositionCaret   int
+	textEvents         bool
 }
 
 func NewKeyboard() Keyboard {
@@ -302,6 +307,10 @@ func (k *Keyboard) Reset() {
 			k.keyStates[i] = KeyStateUp
 		}
 	}
+	// The input method drops its composition when the window loses focus
+	if k.IsComposing() {
+		k.SetComposition("", 0)
+	}
 }
 
 func (k *Keyboard) GetKeyLastClicked(keyId int) time.Time {
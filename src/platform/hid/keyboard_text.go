@@ -0,0 +1,110 @@
+/******************************************************************************/
+/* keyboard_text.go                                                           */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package hid
+
+import "unicode/utf8"
+
+type TextCallbackId int
+
+// TextEventType is the kind of text that a [TextEvent] carries
+type TextEventType uint8
+
+const (
+	// TextEventCommit is text that was typed, the keyboard layout and any
+	// input method have already turned the key presses into characters
+	TextEventCommit TextEventType = iota
+	// TextEventComposition is the pre-edit text of an input method that is
+	// still being composed, blank text means the composition ended
+	TextEventComposition
+)
+
+// TextEvent is text typed on the keyboard as the platform reports it, rather
+// than the keys that were pressed to type it
+type TextEvent struct {
+	Type TextEventType
+	Text string
+	// Caret is the rune offset of the input method caret within the text of
+	// a composition
+	Caret int
+}
+
+type textCallback struct {
+	id TextCallbackId
+	fn func(evt TextEvent)
+}
+
+// AddTextCallback adds a function that is called for text that is typed and
+// for changes to the input method composition
+func (k *Keyboard) AddTextCallback(cb func(evt TextEvent)) TextCallbackId {
+	k.nextTextCallbackId++
+	k.textCallbacks = append(k.textCallbacks, textCallback{
+		id: k.nextTextCallbackId,
+		fn: cb,
+	})
+	return k.nextTextCallbackId
+}
+
+func (k *Keyboard) RemoveTextCallback(id TextCallbackId) {
+	for i, cb := range k.textCallbacks {
+		if cb.id == id {
+			last := len(k.textCallbacks) - 1
+			k.textCallbacks[last], k.textCallbacks[i] = k.textCallbacks[i], k.textCallbacks[last]
+			k.textCallbacks = k.textCallbacks[:last]
+			return
+		}
+	}
+}
+
+// EnableTextEvents is called by platforms that report typed text. Text
+// should then be read from the text callbacks rather than by mapping key
+// codes to characters, which only knows of the American English layout.
+func (k *Keyboard) EnableTextEvents() { k.textEvents = true }
+
+// TextEventsEnabled returns true if typed text is reported through the text
+// callbacks, see [Keyboard.EnableTextEvents]
+func (k Keyboard) TextEventsEnabled() bool { return k.textEvents }
+
+// IsComposing returns true while an input method has pre-edit text, keys
+// pressed during a composition belong to the input method
+func (k Keyboard) IsComposing() bool { return k.composition != "" }
+
+// Composition returns the pre-edit text of the input method and the rune
+// offset of its caret
+func (k Keyboard) Composition() (string, int) {
+	return k.composition, k.compositionCaret
+}
+
+// SetTextCommitted reports text that was typed, committing text also ends
+// the composition that produced it
+func (k *Keyboard) SetTextCommitted(text string) {
+	k.textEvents = true
+	k.composition = ""
+	k.compositionCaret = 0
+	if text == "" {
+		return
+	}
+	k.doTextCallbacks(TextEvent{Type: TextEventCommit, Text: text})
+}
+
+// SetComposition reports the pre-edit text of the input method, blank text
+// ends the composition without committing anything
+func (k *Keyboard) SetComposition(text string, caret int) {
+	k.textEvents = true
+	caret = max(0, min(caret, utf8.RuneCountInString(text)))
+	if text == k.composition && caret == k.compositionCaret {
+		return
+	}
+	k.composition = text
+	k.compositionCaret = caret
+	k.doTextCallbacks(TextEvent{Type: TextEventComposition, Text: text, Caret: caret})
+}
+
+func (k *Keyboard) doTextCallbacks(evt TextEvent) {
+	for i := range k.textCallbacks {
+		k.textCallbacks[i].fn(evt)
+	}
+}
@@ -0,0 +1,67 @@
+/******************************************************************************/
+/* keyboard_text_test.go                                                      */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package hid
+
+import "testing"
+
+func TestKeyboardTextCommit(t *testing.T) {
+	kb := NewKeyboard()
+	if kb.TextEventsEnabled() {
+		t.Fatal("a new keyboard shouldn't report text events")
+	}
+	got := []TextEvent{}
+	kb.AddTextCallback(func(evt TextEvent) { got = append(got, evt) })
+	kb.SetTextCommitted("ü")
+	kb.SetTextCommitted("")
+	if !kb.TextEventsEnabled() {
+		t.Fatal("committed text should enable text events")
+	}
+	if len(got) != 1 || got[0].Type != TextEventCommit || got[0].Text != "ü" {
+		t.Fatalf("expected a single commit of ü, got %+v", got)
+	}
+}
+
+func TestKeyboardComposition(t *testing.T) {
+	kb := NewKeyboard()
+	got := []TextEvent{}
+	kb.AddTextCallback(func(evt TextEvent) { got = append(got, evt) })
+	kb.SetComposition("にほ", 2)
+	kb.SetComposition("にほ", 2)
+	kb.SetComposition("にほん", 10)
+	if !kb.IsComposing() {
+		t.Fatal("expected the keyboard to be composing")
+	}
+	if text, caret := kb.Composition(); text != "にほん" || caret != 3 {
+		t.Fatalf("expected にほん with the caret at 3, got %q at %d", text, caret)
+	}
+	if len(got) != 2 {
+		t.Fatalf("repeating the same composition shouldn't call back, got %+v", got)
+	}
+	kb.SetTextCommitted("日本")
+	if kb.IsComposing() {
+		t.Fatal("committing text should end the composition")
+	}
+	if last := got[len(got)-1]; last.Type != TextEventCommit || last.Text != "日本" {
+		t.Fatalf("expected the commit of 日本, got %+v", last)
+	}
+}
+
+func TestKeyboardResetEndsComposition(t *testing.T) {
+	kb := NewKeyboard()
+	var last TextEvent
+	id := kb.AddTextCallback(func(evt TextEvent) { last = evt })
+	kb.SetComposition("ㅎ", 1)
+	kb.Reset()
+	if kb.IsComposing() || last.Type != TextEventComposition || last.Text != "" {
+		t.Fatalf("expected reset to end the composition, got %+v", last)
+	}
+	kb.RemoveTextCallback(id)
+	kb.SetTextCommitted("a")
+	if last.Text != "" {
+		t.Fatal("a removed callback shouldn't be called")
+	}
+}
@@ -14,6 +14,7 @@ type WindowEventButtonType = uint32
 type WindowEventControllerConnectionType = uint32
 type TouchActionState = int32
 type StylusActionState = int32
+type WindowEventTextType = uint8
 
 const (
 	sharedMemWindowActivity  = 0xF9
@@ -46,6 +47,7 @@ const (
 	windowEventTypeTouchState      = WindowEventType(10)
 	windowEventTypeStylusState     = WindowEventType(11)
 	windowEventTypeFatal           = WindowEventType(12)
+	windowEventTypeText            = WindowEventType(13)
 )
 
 const (
@@ -66,6 +68,11 @@ const (
 	windowEventControllerConnectionTypeConnected    = WindowEventControllerConnectionType(2)
 )
 
+const (
+	windowEventTextTypeCommit      = WindowEventTextType(1)
+	windowEventTextTypeComposition = WindowEventTextType(2)
+)
+
 const (
 	touchActionStateUp     = TouchActionState(1)
 	touchActionStateMove   = TouchActionState(2)
@@ -160,6 +167,17 @@ type StylusStateWindowEvent struct {
 	actionState StylusActionState
 }
 
+const windowEventTextChunk = 16
+
+type TextWindowEvent struct {
+	textType WindowEventTextType
+	length   uint8
+	more     uint8
+	_        byte
+	caret    int32
+	text     [windowEventTextChunk]byte
+}
+
 const evtUnionSize = max(
 	unsafe.Sizeof(SetHandleEvent{}),
 	unsafe.Sizeof(WindowActivityEvent{}),
@@ -170,6 +188,7 @@ const evtUnionSize = max(
 	unsafe.Sizeof(MouseButtonWindowEvent{}),
 	unsafe.Sizeof(KeyboardButtonWindowEvent{}),
 	unsafe.Sizeof(ControllerStateWindowEvent{}),
+	unsafe.Sizeof(TextWindowEvent{}),
 )
 
 func readType(head unsafe.Pointer) (WindowEventType, unsafe.Pointer) {
@@ -220,3 +239,7 @@ func asTouchStateWindowEvent(data unsafe.Pointer) *TouchStateWindowEvent {
 func asStylusStateWindowEvent(data unsafe.Pointer) *StylusStateWindowEvent {
 	return (*StylusStateWindowEvent)(data)
 }
+
+func asTextWindowEvent(data unsafe.Pointer) *TextWindowEvent {
+	return (*TextWindowEvent)(data)
+}
@@ -62,6 +62,7 @@
 #include <windows.h>
 #include <windowsx.h>
 #include <dwmapi.h>
+#include <imm.h>
 
 #ifndef KAIJU_ENABLE_FILEDROP
 #define KAIJU_ENABLE_FILEDROP 0
@@ -83,6 +84,7 @@
 #define WINDOW_TITLE_BAR_MODE_DARK 2
 
 static void apply_title_bar_mode(HWND hwnd, int mode);
+static void apply_text_input_rect(HWND hwnd, int x, int y, int width, int height);
 static bool user_prefers_dark_mode(void);
 
 /*
@@ -92,6 +94,7 @@ static bool user_prefers_dark_mode(void);
 #define UWM_SET_CURSOR            (WM_USER + 0x0001)
 #define UWM_SET_TITLE_BAR_MODE    (WM_USER + 0x0002)
 #define UWM_SET_CURSOR_VISIBILITY (WM_USER + 0x0004)
+#define UWM_SET_TEXT_INPUT_RECT   (WM_USER + 0x0005)
 #if KAIJU_ENABLE_FILEDROP
 #define UWM_SET_FILE_DROP         (WM_USER + 0x0003)
 #endif
@@ -648,6 +651,13 @@ LRESULT CALLBACK window_proc(HWND hwnd, UINT uMsg, WPARAM wParam, LPARAM lParam)
 			apply_title_bar_mode(hwnd, sm->titleBarMode);
 			return 0;
 		}
+		case UWM_SET_TEXT_INPUT_RECT:
+		{
+			apply_text_input_rect(hwnd,
+				(short)LOWORD(wParam), (short)HIWORD(wParam),
+				(short)LOWORD(lParam), (short)HIWORD(lParam));
+			return 0;
+		}
 #if KAIJU_ENABLE_FILEDROP
 		case UWM_SET_FILE_DROP:
 		{
@@ -1240,6 +1250,46 @@ void window_set_title_bar_mode(void* hwnd, int mode) {
 	}
 }
 
+static void apply_text_input_rect(HWND hwnd, int x, int y, int width, int height) {
+	HIMC imc = ImmGetContext(hwnd);
+	if (imc == NULL) {
+		return;
+	}
+	COMPOSITIONFORM composition = { 0 };
+	composition.dwStyle = CFS_POINT;
+	composition.ptCurrentPos.x = x;
+	composition.ptCurrentPos.y = y;
+	ImmSetCompositionWindow(imc, &composition);
+	// Keep the candidate list from covering the text being composed
+	CANDIDATEFORM candidate = { 0 };
+	candidate.dwIndex = 0;
+	candidate.dwStyle = CFS_EXCLUDE;
+	candidate.ptCurrentPos.x = x;
+	candidate.ptCurrentPos.y = y + height;
+	candidate.rcArea.left = x;
+	candidate.rcArea.top = y;
+	candidate.rcArea.right = x + width;
+	candidate.rcArea.bottom = y + height;
+	ImmSetCandidateWindow(imc, &candidate);
+	ImmReleaseContext(hwnd, imc);
+}
+
+void window_set_text_input_rect(void* hwnd, int x, int y, int width, int height) {
+	if (hwnd == NULL) {
+		return;
+	}
+	HWND window = (HWND)hwnd;
+	// The input context belongs to the window's thread, see
+	// window_set_title_bar_mode for why this isn't called directly
+	DWORD windowThread = GetWindowThreadProcessId(window, NULL);
+	if (windowThread == GetCurrentThreadId()) {
+		apply_text_input_rect(window, x, y, width, height);
+	} else {
+		PostMessageA(window, UWM_SET_TEXT_INPUT_RECT,
+			MAKEWPARAM((WORD)x, (WORD)y), MAKELPARAM((WORD)width, (WORD)height));
+	}
+}
+
 void window_set_cursor_position(void* hwnd, int x, int y) {
 	SharedMem* sm = (SharedMem*)GetWindowLongPtrA(hwnd, GWLP_USERDATA);
 	set_cursor_position_relative_to_window(sm, x, y);
@@ -272,6 +272,10 @@ func (w *Window) setTitle(title string) {
 func (w *Window) setTitleBarMode(mode TitleBarMode) {}
 func (w *Window) getTitleBarMode() TitleBarMode     { return w.titleBarMode }
 
+// TODO:  Implement NSTextInputClient on the MetalView so the input method can
+// compose text and be positioned with firstRectForCharacterRange
+func (w *Window) setTextInputRect(x, y, width, height int) {}
+
 func (w *Window) setCursorPosition(x, y int) {
 	// C.cocoa_set_cursor_position(w.handle, C.int(x), C.int(y))
 }
@@ -28,6 +28,11 @@ import (
 	"kaijuengine.com/rendering"
 )
 
+// headlessDotsPerMillimeter is a standard 96 DPI display
+const headlessDotsPerMillimeter = 96 / 25.4
+
+var errHeadlessDialog = errors.New("file dialogs are not available on a headless window")
+
 var (
 	activeWindows []*Window
 	windowLookup  sync.Map
@@ -69,6 +74,9 @@ type Window struct {
 	fatalFromNativeAPI       bool
 	resizedFromNativeAPI     bool
 	isFullScreen             bool
+	headless                 bool
+	headlessClipboard        string
+	pendingText              []byte
 }
 
 type FileSearch struct {
@@ -151,6 +159,48 @@ func New(windowName string, width, height, x, y int, adb assets.Database, platfo
 	return w, nil
 }
 
+// NewHeadless creates a window that has no native window behind it. It is used
+// by the null rendering backend so a host can run on servers, CI and in tests
+// where there is no display. Input devices exist but are only driven by code.
+func NewHeadless(windowName string, width, height int) *Window {
+	defer tracing.NewRegion("windowing.NewHeadless").End()
+	debug.Assert(width > 0, "window width must be greater than zero")
+	debug.Assert(height > 0, "window height must be greater than zero")
+	w := &Window{
+		Keyboard:   hid.NewKeyboard(),
+		Mouse:      hid.NewMouse(),
+		Touch:      hid.NewTouch(),
+		Stylus:     hid.NewStylus(),
+		Controller: hid.NewController(),
+		width:      width,
+		height:     height,
+		right:      width,
+		bottom:     height,
+		title:      windowName,
+		windowSync: make(chan struct{}),
+		headless:   true,
+	}
+	w.Cursor = hid.NewCursor(&w.Mouse, &w.Touch, &w.Stylus)
+	return w
+}
+
+// IsHeadless will return true if the window was created with NewHeadless
+func (w *Window) IsHeadless() bool { return w.headless }
+
+// ResizeHeadless changes the size of a headless window and raises OnResize as
+// if the platform had resized it
+func (w *Window) ResizeHeadless(width, height int) {
+	if !w.headless {
+		return
+	}
+	w.processWindowResizeEvent(&WindowResizeEvent{
+		width: int32(width), height: int32(height),
+		left: int32(w.x), top: int32(w.y),
+		right: int32(w.x + width), bottom: int32(w.y + height),
+	})
+	w.OnResize.Execute()
+}
+
 func (w *Window) InitializeGPU(adb assets.Database) error {
 	defer tracing.NewRegion("Window.InitializeGPU").End()
 	if w.GpuInstance != nil && w.GpuInstance.IsValid() {
@@ -192,8 +242,19 @@ func (w *Window) ToLocalPosition(x, y int) (int, int) {
 	return x - (w.x + leftBorder), y - (w.y + topBorder)
 }
 
-func (w *Window) PlatformWindow() unsafe.Pointer   { return w.cHandle() }
-func (w *Window) PlatformInstance() unsafe.Pointer { return w.cInstance() }
+func (w *Window) PlatformWindow() unsafe.Pointer {
+	if w.headless {
+		return nil
+	}
+	return w.cHandle()
+}
+
+func (w *Window) PlatformInstance() unsafe.Pointer {
+	if w.headless {
+		return nil
+	}
+	return w.cInstance()
+}
 
 func (w *Window) IsClosed() bool  { return w.isClosed }
 func (w *Window) IsCrashed() bool { return w.isCrashed }
@@ -214,8 +275,10 @@ func (w *Window) Poll() {
 		<-w.windowSync
 		w.syncRequest = false
 	}
-	w.poll()
-	w.fileDrop.processQueuedFileDrops()
+	if !w.headless {
+		w.poll()
+		w.fileDrop.processQueuedFileDrops()
+	}
 	if w.resizedFromNativeAPI {
 		slog.Debug("window resize has been requested")
 		w.resizedFromNativeAPI = false
@@ -274,6 +337,9 @@ func (w *Window) DotsPerMillimeter() float64 {
 	if bits := w.dpmmCache.Load(); bits != 0 {
 		return math.Float64frombits(bits)
 	}
+	if w.headless {
+		return headlessDotsPerMillimeter
+	}
 	v := w.dotsPerMillimeter()
 	if v > 0 {
 		w.dpmmCache.Store(math.Float64bits(v))
@@ -282,6 +348,9 @@ func (w *Window) DotsPerMillimeter() float64 {
 }
 
 func (w *Window) MonitorCount() int {
+	if w.headless {
+		return 1
+	}
 	count := w.monitorCount()
 	if count < 1 {
 		return 1
@@ -295,15 +364,25 @@ func (w *Window) MonitorResolutions() []MonitorResolution {
 	if w == nil {
 		return nil
 	}
+	if w.headless {
+		return []MonitorResolution{{Width: w.width, Height: w.height}}
+	}
 	return w.monitorResolutions()
 }
 
 func (w *Window) SizeMM() (int, int, error) {
+	if w.headless {
+		return int(float64(w.width) / headlessDotsPerMillimeter),
+			int(float64(w.height) / headlessDotsPerMillimeter), nil
+	}
 	return w.sizeMM()
 }
 
 func (w *Window) ScreenSizeMM() (int, int, error) {
 	var err error
+	if w.headless {
+		return w.SizeMM()
+	}
 	if w.cachedScreenSizeWidthMM == 0 {
 		w.cachedScreenSizeWidthMM, w.cacheScreenSizeHeightMM, err = w.screenSizeMM()
 	}
@@ -336,7 +415,7 @@ func DPI2PXF(pixels, mm, targetMM float64) float64 {
 
 func (w *Window) CursorStandard() {
 	w.cursorChangeCount = max(0, w.cursorChangeCount-1)
-	if w.cursorChangeCount == 0 {
+	if w.canChangeCursor() {
 		w.cursorStandard()
 	}
 }
@@ -376,8 +455,20 @@ func (w *Window) CursorSizeWE() {
 	w.cursorChangeCount++
 }
 
-func (w *Window) CopyToClipboard(text string) { w.copyToClipboard(text) }
-func (w *Window) ClipboardContents() string   { return w.clipboardContents() }
+func (w *Window) CopyToClipboard(text string) {
+	if w.headless {
+		w.headlessClipboard = text
+		return
+	}
+	w.copyToClipboard(text)
+}
+
+func (w *Window) ClipboardContents() string {
+	if w.headless {
+		return w.headlessClipboard
+	}
+	return w.clipboardContents()
+}
 
 func (w *Window) Destroy() {
 	defer tracing.NewRegion("Window.Destroy").End()
@@ -388,6 +479,10 @@ func (w *Window) Destroy() {
 	w.isClosed = true
 	w.removeFromActiveWindows()
 	w.DestroyGPU()
+	if w.headless {
+		close(w.windowSync)
+		return
+	}
 	// TODO:  Pass both to not have this if statement
 	if runtime.GOOS == "darwin" {
 		destroyWindow(w.instance)
@@ -409,11 +504,17 @@ func (w *Window) DestroyGPU() {
 
 func (w *Window) Focus() {
 	defer tracing.NewRegion("Window.Focus").End()
+	if w.headless {
+		return
+	}
 	w.focus()
 	w.cursorStandard()
 }
 
 func (w *Window) Position() (x int, y int) {
+	if w.headless {
+		return w.x, w.y
+	}
 	x, y = w.position()
 	w.x = x
 	w.y = y
@@ -421,7 +522,9 @@ func (w *Window) Position() (x int, y int) {
 }
 
 func (w *Window) SetPosition(x, y int) {
-	w.setPosition(x, y)
+	if !w.headless {
+		w.setPosition(x, y)
+	}
 	w.x = x
 	w.y = y
 }
@@ -429,22 +532,53 @@ func (w *Window) SetPosition(x, y int) {
 func (w *Window) SetSize(width, height int) {
 	debug.Assert(width >= 0, "window width cannot be negative")
 	debug.Assert(height >= 0, "window height cannot be negative")
+	if w.headless {
+		w.ResizeHeadless(width, height)
+		return
+	}
 	w.setSize(width, height)
 	w.width = width
 	w.height = height
 }
 
-func (w *Window) RemoveBorder()      { w.removeBorder() }
-func (w *Window) AddBorder()         { w.addBorder() }
-func (w *Window) ShowCursor()        { w.showCursor() }
-func (w *Window) HideCursor()        { w.hideCursor() }
 func (w *Window) IsFullScreen() bool { return w.isFullScreen }
-func (w *Window) UnlockCursor()      { w.unlockCursor() }
+
+func (w *Window) RemoveBorder() {
+	if !w.headless {
+		w.removeBorder()
+	}
+}
+
+func (w *Window) AddBorder() {
+	if !w.headless {
+		w.addBorder()
+	}
+}
+
+func (w *Window) ShowCursor() {
+	if !w.headless {
+		w.showCursor()
+	}
+}
+
+func (w *Window) HideCursor() {
+	if !w.headless {
+		w.hideCursor()
+	}
+}
+
+func (w *Window) UnlockCursor() {
+	if !w.headless {
+		w.unlockCursor()
+	}
+}
 
 func (w *Window) LockCursor(x, y int) {
 	debug.Assert(w.width > 0, "cannot lock cursor: window width must be greater than zero")
 	debug.Assert(w.height > 0, "cannot lock cursor: window height must be greater than zero")
-	w.lockCursor(x, y)
+	if !w.headless {
+		w.lockCursor(x, y)
+	}
 	w.Mouse.SetPosition(float32(x), float32(y), float32(w.width), float32(w.height))
 }
 
@@ -452,14 +586,20 @@ func (w *Window) SetFullscreen() {
 	if w.isFullScreen {
 		return
 	}
-	w.setFullscreen()
+	if !w.headless {
+		w.setFullscreen()
+	}
 	w.isFullScreen = true
 }
 
 func (w *Window) SetWindowed(width, height int) {
 	debug.Assert(width > 0, "windowed mode width must be greater than zero")
 	debug.Assert(height > 0, "windowed mode height must be greater than zero")
-	w.setWindowed(width, height)
+	if w.headless {
+		w.ResizeHeadless(width, height)
+	} else {
+		w.setWindowed(width, height)
+	}
 	w.isFullScreen = false
 }
 
@@ -470,6 +610,9 @@ func (w *Window) Center() (x int, y int) {
 
 func (w *Window) OpenFileDialog(startPath string, extensions []filesystem.DialogExtension, ok func(path string), cancel func()) error {
 	debug.Assert(ok != nil, "OpenFileDialog requires a non-nil ok callback")
+	if w.headless {
+		return errHeadlessDialog
+	}
 	w.disableRawMouse()
 	return filesystem.OpenFileDialogWindow(startPath, extensions, func(path string) {
 		w.enableRawMouse()
@@ -484,6 +627,9 @@ func (w *Window) OpenFileDialog(startPath string, extensions []filesystem.Dialog
 
 func (w *Window) SaveFileDialog(startPath string, fileName string, extensions []filesystem.DialogExtension, ok func(path string), cancel func()) error {
 	debug.Assert(ok != nil, "SaveFileDialog requires a non-nil ok callback")
+	if w.headless {
+		return errHeadlessDialog
+	}
 	w.disableRawMouse()
 	return filesystem.OpenSaveFileDialogWindow(startPath, fileName, extensions, func(path string) {
 		w.enableRawMouse()
@@ -496,32 +642,73 @@ func (w *Window) SaveFileDialog(startPath string, fileName string, extensions []
 	}, w.handle)
 }
 
-func (w *Window) EnableRawMouseInput()  { w.enableRawMouse() }
-func (w *Window) DisableRawMouseInput() { w.disableRawMouse() }
+func (w *Window) EnableRawMouseInput() {
+	if !w.headless {
+		w.enableRawMouse()
+	}
+}
+
+func (w *Window) DisableRawMouseInput() {
+	if !w.headless {
+		w.disableRawMouse()
+	}
+}
 
-func (w *Window) SetTitle(name string) { w.setTitle(name) }
+// SetTextInputRect tells the input method where the text caret is, in pixels
+// from the top left of the window, so its candidate window opens next to it.
+// This is implemented on X11 and Windows, it is not implemented yet on macOS
+// and Android
+func (w *Window) SetTextInputRect(x, y, width, height int) {
+	if !w.headless {
+		w.setTextInputRect(x, y, width, height)
+	}
+}
+
+func (w *Window) SetTitle(name string) {
+	w.title = name
+	if !w.headless {
+		w.setTitle(name)
+	}
+}
 
 func (w *Window) SetTitleBarMode(mode TitleBarMode) {
 	debug.Assert(mode <= TitleBarModeDark, "Invalid TitleBarMode")
 	w.titleBarMode = mode
-	w.setTitleBarMode(mode)
+	if !w.headless {
+		w.setTitleBarMode(mode)
+	}
+}
+
+func (w *Window) TitleBarMode() TitleBarMode {
+	if w.headless {
+		return w.titleBarMode
+	}
+	return w.getTitleBarMode()
 }
-func (w *Window) TitleBarMode() TitleBarMode { return w.getTitleBarMode() }
 
 func (w *Window) SetIcon(img image.Image) {
 	if img == nil {
 		slog.Error("failed to set the window icon, a nil image was supplied")
 		return
 	}
-	w.setIcon(img)
+	if !w.headless {
+		w.setIcon(img)
+	}
 }
 
-func (w *Window) SetCursorPosition(x, y int) { w.setCursorPosition(x, y) }
+func (w *Window) SetCursorPosition(x, y int) {
+	if !w.headless {
+		w.setCursorPosition(x, y)
+	}
+}
 
 // ReadApplicationAsset will read an asset bound to the application. This is
 // typically only useful on mobile platforms like Android. Platforms like Linux,
 // Windows, and Mac will return an error, use #ReadFile instead
 func (w *Window) ReadApplicationAsset(path string) ([]byte, error) {
+	if w.headless {
+		return nil, errors.New("a headless window has no application assets")
+	}
 	return w.readApplicationAsset(path)
 }
 
@@ -529,7 +716,7 @@ func (w *Window) requestSync() {
 	w.syncRequest = true
 }
 
-func (w *Window) canChangeCursor() bool { return w.cursorChangeCount == 0 }
+func (w *Window) canChangeCursor() bool { return w.cursorChangeCount == 0 && !w.headless }
 
 func (w *Window) processWindowResizeEvent(evt *WindowResizeEvent) {
 	w.width = int(evt.width)
@@ -630,6 +817,24 @@ func (w *Window) processKeyboardButtonEvent(evt *KeyboardButtonWindowEvent) {
 	}
 }
 
+// processTextEvent joins the chunks of the text and passes it on to the
+// keyboard once the last chunk arrives
+func (w *Window) processTextEvent(evt *TextWindowEvent) {
+	defer tracing.NewRegion("Window.processTextEvent").End()
+	w.pendingText = append(w.pendingText, evt.text[:min(evt.length, windowEventTextChunk)]...)
+	if evt.more != 0 {
+		return
+	}
+	text := string(w.pendingText)
+	w.pendingText = w.pendingText[:0]
+	switch evt.textType {
+	case windowEventTextTypeCommit:
+		w.Keyboard.SetTextCommitted(text)
+	case windowEventTextTypeComposition:
+		w.Keyboard.SetComposition(text, int(evt.caret))
+	}
+}
+
 func (w *Window) processControllerStateEvent(evt *ControllerStateWindowEvent) {
 	defer tracing.NewRegion("Window.processControllerStateEvent").End()
 	if evt.connectionType == windowEventControllerConnectionTypeDisconnected {
@@ -766,6 +971,8 @@ func goProcessEventsCommon(goWindow uint64, events unsafe.Pointer, eventCount ui
 			win.processTouchStateEvent(asTouchStateWindowEvent(body))
 		case windowEventTypeStylusState:
 			win.processStylusStateEvent(asStylusStateWindowEvent(body))
+		case windowEventTypeText:
+			win.processTextEvent(asTextWindowEvent(body))
 		case windowEventTypeFatal:
 			events = body
 			win.fatalFromNativeAPI = true
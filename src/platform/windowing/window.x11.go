@@ -41,6 +41,7 @@ package windowing
 #cgo noescape window_invalidate_monitor_cache
 #cgo noescape screen_count
 #cgo noescape screen_resolutions
+#cgo noescape window_set_text_input_rect
 
 #include <stdlib.h>
 #include "windowing.h"
@@ -83,6 +84,9 @@ func (w *Window) createWindow(windowName string, x, y int, _ any) {
 	windowLookup.Store(w.lookupId, w)
 	C.window_main(title, C.int(w.width), C.int(w.height),
 		C.int(x), C.int(y), C.uint64_t(w.lookupId))
+	// Key presses are turned into text by the keyboard layout and the X input
+	// method, see lookup_text in x11.c
+	w.Keyboard.EnableTextEvents()
 }
 
 func (w *Window) showWindow() {
@@ -218,6 +222,10 @@ func (w Window) setTitle(name string) {
 func (w *Window) setTitleBarMode(mode TitleBarMode) {}
 func (w *Window) getTitleBarMode() TitleBarMode     { return w.titleBarMode }
 
+func (w *Window) setTextInputRect(x, y, width, height int) {
+	C.window_set_text_input_rect(w.handle, C.int(x), C.int(y), C.int(width), C.int(height))
+}
+
 func (w Window) setFullscreen() {
 	C.window_set_full_screen(w.handle)
 }
@@ -168,3 +168,7 @@ func (w *Window) getTitleBarMode() TitleBarMode     { return w.titleBarMode }
 func (w *Window) setCursorPosition(x, y int)        {}
 func (w *Window) setIcon(img image.Image)           {}
 func (w *Window) setFileDropEnabled(enabled bool)   {}
+
+// TODO:  Position the soft keyboard's composition with
+// InputMethodManager.updateCursorAnchorInfo
+func (w *Window) setTextInputRect(x, y, width, height int) {}
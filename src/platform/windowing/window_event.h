@@ -56,6 +56,7 @@ typedef enum {
 	WINDOW_EVENT_TYPE_TOUCH_STATE = 10,
 	WINDOW_EVENT_TYPE_STYLUS_STATE = 11,
 	WINDOW_EVENT_TYPE_FATAL = 12,
+	WINDOW_EVENT_TYPE_TEXT = 13,
 } WindowEventType;
 
 typedef enum {
@@ -76,6 +77,11 @@ typedef enum {
 	WINDOW_EVENT_CONTROLLER_CONNECTION_TYPE_CONNECTED = 2,
 } WindowEventControllerConnectionType;
 
+typedef enum {
+	WINDOW_EVENT_TEXT_TYPE_COMMIT = 1,
+	WINDOW_EVENT_TEXT_TYPE_COMPOSITION = 2,
+} WindowEventTextType;
+
 typedef enum {
 	TOUCH_ACTION_STATE_TYPE_UP = 1,
 	TOUCH_ACTION_STATE_TYPE_MOVE = 2,
@@ -140,6 +146,19 @@ typedef struct {
 	alignas(4) WindowEventButtonType action;
 } KeyboardButtonWindowEvent;
 
+// Text longer than the chunk is split across events at UTF-8 character
+// boundaries, every chunk but the last has more set
+#define WINDOW_EVENT_TEXT_CHUNK	16
+
+typedef struct {
+	alignas(1) uint8_t textType;
+	alignas(1) uint8_t length;
+	alignas(1) uint8_t more;
+	char _0[1];
+	alignas(4) int32_t caret;
+	char text[WINDOW_EVENT_TEXT_CHUNK];
+} TextWindowEvent;
+
 typedef struct {
 	alignas(1) uint8_t controllerId;
 	alignas(1) uint8_t leftTrigger;
@@ -186,6 +205,7 @@ typedef struct {
 		ControllerStateWindowEvent controllerState;
 		TouchStateWindowEvent touchState;
 		StylusStateWindowEvent stylusState;
+		TextWindowEvent text;
 	};
 } WindowEvent;
 
@@ -0,0 +1,56 @@
+/******************************************************************************/
+/* window_text_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package windowing
+
+import (
+	"testing"
+
+	"kaijuengine.com/platform/hid"
+)
+
+// textEvents splits the text into events the way the platforms do
+func textEvents(textType WindowEventTextType, text string, caret int) []TextWindowEvent {
+	events := []TextWindowEvent{}
+	for {
+		chunk := min(len(text), windowEventTextChunk)
+		for chunk < len(text) && chunk > 0 && text[chunk]&0xC0 == 0x80 {
+			chunk--
+		}
+		evt := TextWindowEvent{textType: textType, length: uint8(chunk), caret: int32(caret)}
+		copy(evt.text[:], text[:chunk])
+		text = text[chunk:]
+		if len(text) > 0 {
+			evt.more = 1
+		}
+		events = append(events, evt)
+		if len(text) == 0 {
+			return events
+		}
+	}
+}
+
+func TestWindowTextEventsJoinChunks(t *testing.T) {
+	w := NewHeadless("test", 640, 480)
+	got := []hid.TextEvent{}
+	w.Keyboard.AddTextCallback(func(evt hid.TextEvent) { got = append(got, evt) })
+	const long = "これは長い文章のテストです"
+	for _, evt := range textEvents(windowEventTextTypeComposition, long, 4) {
+		w.processTextEvent(&evt)
+	}
+	if len(got) != 1 || got[0].Type != hid.TextEventComposition || got[0].Text != long || got[0].Caret != 4 {
+		t.Fatalf("expected one composition of the whole text, got %+v", got)
+	}
+	for _, evt := range textEvents(windowEventTextTypeCommit, "ß", 0) {
+		w.processTextEvent(&evt)
+	}
+	if last := got[len(got)-1]; last.Type != hid.TextEventCommit || last.Text != "ß" {
+		t.Fatalf("expected the commit of ß, got %+v", last)
+	}
+	if w.Keyboard.IsComposing() {
+		t.Fatal("the commit should have ended the composition")
+	}
+}
@@ -20,7 +20,7 @@ import (
 )
 
 /*
-#cgo LDFLAGS: -lgdi32 -lXInput -ldwmapi
+#cgo LDFLAGS: -lgdi32 -lXInput -ldwmapi -limm32
 #cgo noescape get_toggle_key_state
 #cgo noescape window_main
 #cgo noescape window_show
@@ -50,6 +50,7 @@ import (
 #cgo noescape window_disable_raw_mouse
 #cgo noescape window_set_title
 #cgo noescape window_set_title_bar_mode
+#cgo noescape window_set_text_input_rect
 #cgo noescape window_set_cursor_position
 #cgo noescape window_set_icon
 
@@ -265,6 +266,10 @@ func (w *Window) getTitleBarMode() TitleBarMode {
 	return w.titleBarMode
 }
 
+func (w *Window) setTextInputRect(x, y, width, height int) {
+	C.window_set_text_input_rect(w.handle, C.int(x), C.int(y), C.int(width), C.int(height))
+}
+
 func (w *Window) setCursorPosition(x, y int) {
 	C.window_set_cursor_position(w.handle, C.int(x), C.int(y))
 }
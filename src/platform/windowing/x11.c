@@ -48,7 +48,10 @@
 #include <fcntl.h>
 #include <unistd.h>
 #include <errno.h>
+#include <locale.h>
+#include <wchar.h>
 #include <X11/Xlib.h>
+#include <X11/Xutil.h>
 #include <X11/Xcursor/Xcursor.h>
 #include <X11/XKBlib.h>
 #include <X11/Xatom.h>
@@ -100,6 +103,270 @@ static bool isExtensionSupported(const char* extList, const char* extension) {
 	return false;
 }
 
+static int utf8_encode(uint32_t cp, char* out) {
+	if (cp < 0x80) {
+		out[0] = (char)cp;
+		return 1;
+	} else if (cp < 0x800) {
+		out[0] = (char)(0xC0 | (cp >> 6));
+		out[1] = (char)(0x80 | (cp & 0x3F));
+		return 2;
+	} else if (cp < 0x10000) {
+		out[0] = (char)(0xE0 | (cp >> 12));
+		out[1] = (char)(0x80 | ((cp >> 6) & 0x3F));
+		out[2] = (char)(0x80 | (cp & 0x3F));
+		return 3;
+	}
+	out[0] = (char)(0xF0 | (cp >> 18));
+	out[1] = (char)(0x80 | ((cp >> 12) & 0x3F));
+	out[2] = (char)(0x80 | ((cp >> 6) & 0x3F));
+	out[3] = (char)(0x80 | (cp & 0x3F));
+	return 4;
+}
+
+static void send_text_event(X11State* s, WindowEventTextType type,
+	const char* text, int length, int caret)
+{
+	int offset = 0;
+	do {
+		int chunk = length - offset;
+		if (chunk > WINDOW_EVENT_TEXT_CHUNK) {
+			chunk = WINDOW_EVENT_TEXT_CHUNK;
+			// Don't split a UTF-8 sequence across events
+			while (chunk > 0 && (text[offset+chunk] & 0xC0) == 0x80) {
+				chunk--;
+			}
+		}
+		WindowEvent evt = {
+			.type = WINDOW_EVENT_TYPE_TEXT,
+			.text = {
+				.textType = type,
+				.length = chunk,
+				.more = offset+chunk < length,
+				.caret = caret,
+			}
+		};
+		memcpy(evt.text.text, text+offset, chunk);
+		shared_mem_add_event(&s->sm, evt);
+		offset += chunk;
+	} while (offset < length);
+}
+
+static void send_preedit(X11State* s) {
+	char text[X11_PREEDIT_CAPACITY*4];
+	int length = 0;
+	for (int i = 0; i < s->preeditLength; i++) {
+		length += utf8_encode(s->preedit[i], text+length);
+	}
+	send_text_event(s, WINDOW_EVENT_TEXT_TYPE_COMPOSITION, text, length, s->preeditCaret);
+}
+
+static int preedit_start(XIC ic, XPointer clientData, XPointer callData) {
+	X11State* s = (X11State*)clientData;
+	s->composing = true;
+	s->preeditLength = 0;
+	s->preeditCaret = 0;
+	return X11_PREEDIT_CAPACITY;
+}
+
+static void preedit_done(XIC ic, XPointer clientData, XPointer callData) {
+	X11State* s = (X11State*)clientData;
+	s->composing = false;
+	s->preeditLength = 0;
+	s->preeditCaret = 0;
+	send_text_event(s, WINDOW_EVENT_TEXT_TYPE_COMPOSITION, "", 0, 0);
+}
+
+// ximtext_chars reads the characters of the input method text, which is
+// either wide characters or multi-byte text in the locale encoding
+static int ximtext_chars(XIMText* text, uint32_t* out, int capacity) {
+	if (text == NULL) {
+		return 0;
+	}
+	int count = 0;
+	if (text->encoding_is_wchar) {
+		for (int i = 0; i < text->length && count < capacity; i++) {
+			out[count++] = (uint32_t)text->string.wide_char[i];
+		}
+		return count;
+	}
+	const char* mb = text->string.multi_byte;
+	if (mb == NULL) {
+		return 0;
+	}
+	mbstate_t state = { 0 };
+	size_t remaining = strlen(mb);
+	while (remaining > 0 && count < capacity) {
+		wchar_t wc;
+		size_t n = mbrtowc(&wc, mb, remaining, &state);
+		if (n == 0 || n == (size_t)-1 || n == (size_t)-2) {
+			break;
+		}
+		out[count++] = (uint32_t)wc;
+		mb += n;
+		remaining -= n;
+	}
+	return count;
+}
+
+static void preedit_draw(XIC ic, XPointer clientData, XIMPreeditDrawCallbackStruct* callData) {
+	X11State* s = (X11State*)clientData;
+	uint32_t inserted[X11_PREEDIT_CAPACITY];
+	int insertedCount = ximtext_chars(callData->text, inserted, X11_PREEDIT_CAPACITY);
+	int first = callData->chg_first;
+	int changed = callData->chg_length;
+	if (first < 0 || first > s->preeditLength) {
+		first = s->preeditLength;
+	}
+	if (changed < 0 || first+changed > s->preeditLength) {
+		changed = s->preeditLength - first;
+	}
+	int tail = s->preeditLength - first - changed;
+	if (first+insertedCount+tail > X11_PREEDIT_CAPACITY) {
+		insertedCount = X11_PREEDIT_CAPACITY - first - tail;
+	}
+	memmove(s->preedit+first+insertedCount, s->preedit+first+changed, tail*sizeof(uint32_t));
+	memcpy(s->preedit+first, inserted, insertedCount*sizeof(uint32_t));
+	s->preeditLength = first + insertedCount + tail;
+	s->preeditCaret = callData->caret;
+	if (s->preeditCaret > s->preeditLength) {
+		s->preeditCaret = s->preeditLength;
+	}
+	s->composing = true;
+	send_preedit(s);
+}
+
+static void preedit_caret(XIC ic, XPointer clientData, XIMPreeditCaretCallbackStruct* callData) {
+	X11State* s = (X11State*)clientData;
+	switch (callData->direction) {
+		case XIMAbsolutePosition:
+			s->preeditCaret = callData->position;
+			break;
+		case XIMForwardChar:
+			s->preeditCaret++;
+			break;
+		case XIMBackwardChar:
+			s->preeditCaret--;
+			break;
+		case XIMLineStart:
+			s->preeditCaret = 0;
+			break;
+		case XIMLineEnd:
+			s->preeditCaret = s->preeditLength;
+			break;
+		default:
+			break;
+	}
+	if (s->preeditCaret < 0) {
+		s->preeditCaret = 0;
+	} else if (s->preeditCaret > s->preeditLength) {
+		s->preeditCaret = s->preeditLength;
+	}
+	callData->position = s->preeditCaret;
+	send_preedit(s);
+}
+
+// open_input_method connects to the X input method of the user's locale, or
+// to the built in one that only does compose sequences and dead keys. The
+// pre-edit text is drawn by the engine when the input method lets us,
+// otherwise it draws it over the spot set by window_set_text_input_rect.
+static void open_input_method(X11State* s) {
+	// Go leaves the C locale unset, the input method needs the user's
+	setlocale(LC_CTYPE, "");
+	if (!XSupportsLocale()) {
+		setlocale(LC_CTYPE, "C.UTF-8");
+	}
+	XSetLocaleModifiers("");
+	s->im = XOpenIM(s->d, NULL, NULL, NULL);
+	if (s->im == NULL) {
+		XSetLocaleModifiers("@im=none");
+		s->im = XOpenIM(s->d, NULL, NULL, NULL);
+	}
+	if (s->im == NULL) {
+		return;
+	}
+	XIMStyles* styles = NULL;
+	bool callbacks = false;
+	bool position = false;
+	if (XGetIMValues(s->im, XNQueryInputStyle, &styles, NULL) == NULL && styles != NULL) {
+		for (int i = 0; i < styles->count_styles; i++) {
+			XIMStyle style = styles->supported_styles[i];
+			callbacks = callbacks || style == (XIMPreeditCallbacks | XIMStatusNothing);
+			position = position || style == (XIMPreeditPosition | XIMStatusNothing);
+		}
+		XFree(styles);
+	}
+	if (callbacks) {
+		s->preeditCallbacks[0] = (XIMCallback) { (XPointer)s, (XIMProc)preedit_start };
+		s->preeditCallbacks[1] = (XIMCallback) { (XPointer)s, (XIMProc)preedit_done };
+		s->preeditCallbacks[2] = (XIMCallback) { (XPointer)s, (XIMProc)preedit_draw };
+		s->preeditCallbacks[3] = (XIMCallback) { (XPointer)s, (XIMProc)preedit_caret };
+		XVaNestedList preedit = XVaCreateNestedList(0,
+			XNPreeditStartCallback, &s->preeditCallbacks[0],
+			XNPreeditDoneCallback, &s->preeditCallbacks[1],
+			XNPreeditDrawCallback, &s->preeditCallbacks[2],
+			XNPreeditCaretCallback, &s->preeditCallbacks[3],
+			NULL);
+		s->ic = XCreateIC(s->im, XNInputStyle, XIMPreeditCallbacks | XIMStatusNothing,
+			XNClientWindow, s->w, XNFocusWindow, s->w, XNPreeditAttributes, preedit, NULL);
+		XFree(preedit);
+	} else if (position) {
+		XPoint spot = { 0, 0 };
+		XVaNestedList preedit = XVaCreateNestedList(0, XNSpotLocation, &spot, NULL);
+		s->ic = XCreateIC(s->im, XNInputStyle, XIMPreeditPosition | XIMStatusNothing,
+			XNClientWindow, s->w, XNFocusWindow, s->w, XNPreeditAttributes, preedit, NULL);
+		XFree(preedit);
+	}
+	if (s->ic == NULL) {
+		s->ic = XCreateIC(s->im, XNInputStyle, XIMPreeditNothing | XIMStatusNothing,
+			XNClientWindow, s->w, XNFocusWindow, s->w, NULL);
+	}
+	if (s->ic == NULL) {
+		XCloseIM(s->im);
+		s->im = NULL;
+		return;
+	}
+	long filterEvents = 0;
+	XGetICValues(s->ic, XNFilterEvents, &filterEvents, NULL);
+	XSelectInput(s->d, s->w, EVT_MASK | filterEvents);
+}
+
+// lookup_text sends the text that the key press typed, which follows the
+// keyboard layout, dead keys and the input method rather than the key code.
+// Control characters are left to the keyboard button events.
+static void lookup_text(X11State* s, XKeyEvent* key) {
+	char buffer[64];
+	char* text = buffer;
+	int length = 0;
+	if (s->ic != NULL) {
+		Status status;
+		length = Xutf8LookupString(s->ic, key, buffer, sizeof(buffer), NULL, &status);
+		if (status == XBufferOverflow) {
+			text = malloc(length);
+			length = Xutf8LookupString(s->ic, key, text, length, NULL, &status);
+		}
+		if (status != XLookupChars && status != XLookupBoth) {
+			length = 0;
+		}
+	} else {
+		// Without an input method the text is Latin-1
+		char latin1[16];
+		int count = XLookupString(key, latin1, sizeof(latin1), NULL, NULL);
+		for (int i = 0; i < count; i++) {
+			length += utf8_encode((unsigned char)latin1[i], buffer+length);
+		}
+	}
+	if (length == 1 && ((unsigned char)text[0] < 0x20 || text[0] == 0x7F)) {
+		length = 0;
+	}
+	if (length > 0) {
+		send_text_event(s, WINDOW_EVENT_TEXT_TYPE_COMMIT, text, length, 0);
+	}
+	if (text != buffer) {
+		free(text);
+	}
+}
+
 void window_main(const char* windowTitle,
 	int width, int height, int x, int y, uint64_t goWindow)
 {
@@ -153,6 +420,7 @@ void window_main(const char* windowTitle,
 	}
 	x11State->CLIPBOARD = XInternAtom(d, "CLIPBOARD", 0);
 	XSetWMProtocols(d, w, &x11State->WM_DELETE_WINDOW, 1);
+	open_input_method(x11State);
 	// Initialize controller states
 	for (int i = 0; i < MAX_CONTROLLERS; i++) {
 		x11State->controllers[i].fd = -1;
@@ -388,6 +656,9 @@ void window_poll(void* x11State) {
 			case Expose:
 				break;
 			case FocusIn:
+				if (s->ic != NULL) {
+					XSetICFocus(s->ic);
+				}
 				shared_mem_add_event(&s->sm, (WindowEvent) {
 					.type = WINDOW_EVENT_TYPE_ACTIVITY,
 					.windowActivity = {
@@ -396,6 +667,9 @@ void window_poll(void* x11State) {
 				});
 				break;
 			case FocusOut:
+				if (s->ic != NULL) {
+					XUnsetICFocus(s->ic);
+				}
 				shared_mem_add_event(&s->sm, (WindowEvent) {
 					.type = WINDOW_EVENT_TYPE_ACTIVITY,
 					.windowActivity = {
@@ -438,14 +712,24 @@ void window_poll(void* x11State) {
 				break;
 			case KeyPress:
 			case KeyRelease:
-				shared_mem_add_event(&s->sm, (WindowEvent) {
-					.type = WINDOW_EVENT_TYPE_KEYBOARD_BUTTON,
-					.keyboardButton = {
-						.action = e.type == KeyPress
-							? WINDOW_EVENT_BUTTON_TYPE_DOWN : WINDOW_EVENT_BUTTON_TYPE_UP,
-						.buttonId = XLookupKeysym(&e.xkey, 0),
-					}
-				});
+				// Keys the input method consumed are part of its composition
+				if (filtered) {
+					break;
+				}
+				// The input method sends committed text as a key press without a key
+				if (e.xkey.keycode != 0) {
+					shared_mem_add_event(&s->sm, (WindowEvent) {
+						.type = WINDOW_EVENT_TYPE_KEYBOARD_BUTTON,
+						.keyboardButton = {
+							.action = e.type == KeyPress
+								? WINDOW_EVENT_BUTTON_TYPE_DOWN : WINDOW_EVENT_BUTTON_TYPE_UP,
+							.buttonId = XLookupKeysym(&e.xkey, 0),
+						}
+					});
+				}
+				if (e.type == KeyPress) {
+					lookup_text(s, &e.xkey);
+				}
 				break;
 			case ButtonPress:
 			{
@@ -554,6 +838,12 @@ void window_destroy(void* x11State) {
 			s->controllers[i].connected = false;
 		}
 	}
+	if (s->ic != NULL) {
+		XDestroyIC(s->ic);
+	}
+	if (s->im != NULL) {
+		XCloseIM(s->im);
+	}
 	if (s->w) {
 		XDestroyWindow(s->d, s->w);
 	}
@@ -745,6 +1035,18 @@ void window_set_cursor_position(void* state, int x, int y) {
 	set_cursor_position_relative_to_window(s, x, y);
 }
 
+void window_set_text_input_rect(void* state, int x, int y, int width, int height) {
+	X11State* s = state;
+	if (s->ic == NULL) {
+		return;
+	}
+	// The spot is the baseline the input method opens its windows below
+	XPoint spot = { .x = x, .y = y + height };
+	XVaNestedList preedit = XVaCreateNestedList(0, XNSpotLocation, &spot, NULL);
+	XSetICValues(s->ic, XNPreeditAttributes, preedit, NULL);
+	XFree(preedit);
+}
+
 float window_dpi(void* state) {
 	X11State* s = state;
 	MonitorInfo mi = find_monitor_info(s);
@@ -53,6 +53,10 @@
 #define EVIOCGABS(axis) _IOR('E', 0x20 + (axis), struct input_absinfo)
 #endif
 
+#ifndef X11_PREEDIT_CAPACITY
+#define X11_PREEDIT_CAPACITY 256
+#endif
+
 #ifndef EVIOCGKEY
 #define EVIOCGKEY(len) _IOR('E', 0x2f, unsigned char[len])
 #endif
@@ -95,6 +99,13 @@ typedef struct {
 	MonitorInfo monitorCache;
 	int monitorCacheDirty;
 	ControllerInfo controllers[MAX_CONTROLLERS];
+	XIM im;
+	XIC ic;
+	XIMCallback preeditCallbacks[4];
+	uint32_t preedit[X11_PREEDIT_CAPACITY];
+	int preeditLength;
+	int preeditCaret;
+	bool composing;
 } X11State;
 
 unsigned int get_toggle_key_state();
@@ -130,5 +141,6 @@ void window_lock_cursor(void* state, int x, int y);
 void window_unlock_cursor(void* state);
 void window_set_cursor_position(void* state, int x, int y);
 void window_set_icon(void* state, int width, int height, const unsigned char* rgba);
+void window_set_text_input_rect(void* state, int x, int y, int width, int height);
 
 #endif
@@ -347,6 +347,10 @@ func (l *State) RawGetI(idx, n int) {
 	C.lua_rawgeti(l.state, C.int(idx), C.lua_Integer(n))
 }
 
+func (l *State) RawLen(idx int) int {
+	return int(C.lua_rawlen(l.state, C.int(idx)))
+}
+
 func (l *State) SetGlobal(name string) {
 	cStr := C.CString(name)
 	defer C.free(unsafe.Pointer(cStr))
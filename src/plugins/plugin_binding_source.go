@@ -0,0 +1,65 @@
+/******************************************************************************/
+/* plugin_binding_source.go                                                   */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package plugins
+
+import (
+	"strconv"
+
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// LuaBindingSource reads values out of a global Lua table so that the table
+// can be bound to a markup document (it is a document.BindingSource). Paths
+// are field names, or 0 based indexes into the array part of a table.
+type LuaBindingSource struct {
+	vm     *LuaVM
+	global string
+}
+
+// LuaTableLength is what a table is read as, it has the length of the array
+// part of the table so it can be used with data-each
+type LuaTableLength int
+
+func (l LuaTableLength) Len() int { return int(l) }
+
+// BindingSource returns a binding source for the global Lua table with the
+// given name. The table is read each time the binding is refreshed, so it
+// can be replaced or changed freely from Lua.
+func (vm *LuaVM) BindingSource(global string) LuaBindingSource {
+	return LuaBindingSource{vm: vm, global: global}
+}
+
+func (s LuaBindingSource) BindingValue(path []string) (any, bool) {
+	defer tracing.NewRegion("LuaBindingSource.BindingValue").End()
+	state := &s.vm.runtime
+	top := state.Top()
+	defer func() { state.Pop(state.Top() - top) }()
+	state.Global(s.global)
+	for _, part := range path {
+		if !state.IsTable(-1) {
+			return nil, false
+		}
+		if i, err := strconv.Atoi(part); err == nil {
+			state.RawGetI(-1, i+1)
+		} else {
+			state.Field(-1, part)
+		}
+	}
+	switch {
+	case state.IsNil(-1):
+		return nil, false
+	case state.IsBoolean(-1):
+		return state.ToBoolean(-1), true
+	case state.IsNumber(-1):
+		return state.ToNumber(-1), true
+	case state.IsString(-1):
+		return state.ToString(-1), true
+	case state.IsTable(-1):
+		return LuaTableLength(state.RawLen(-1)), true
+	}
+	return nil, false
+}
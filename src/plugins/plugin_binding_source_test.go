@@ -0,0 +1,53 @@
+/******************************************************************************/
+/* plugin_binding_source_test.go                                              */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package plugins
+
+import "testing"
+
+func TestLuaBindingSource(t *testing.T) {
+	withTestRegistry(t)
+	entry := writePlugin(t, map[string]string{
+		"main.lua": `
+hud = {
+	player = { name = "Ada", health = 75, alive = true },
+	items = { { name = "Sword" }, { name = "Shield" } },
+}
+`,
+	})
+	vm, err := launchPlugin(testPluginDB(), entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Close()
+	src := vm.BindingSource("hud")
+	top := vm.runtime.Top()
+	if v, ok := src.BindingValue([]string{"player", "name"}); !ok || v != "Ada" {
+		t.Fatalf("player.name = %v, %v", v, ok)
+	}
+	if v, ok := src.BindingValue([]string{"player", "health"}); !ok || v != float64(75) {
+		t.Fatalf("player.health = %v, %v", v, ok)
+	}
+	if v, ok := src.BindingValue([]string{"player", "alive"}); !ok || v != true {
+		t.Fatalf("player.alive = %v, %v", v, ok)
+	}
+	if v, ok := src.BindingValue([]string{"items"}); !ok || v.(LuaTableLength).Len() != 2 {
+		t.Fatalf("items = %v, %v", v, ok)
+	}
+	// Indexes are 0 based like the Go side of the binding
+	if v, ok := src.BindingValue([]string{"items", "1", "name"}); !ok || v != "Shield" {
+		t.Fatalf("items.1.name = %v, %v", v, ok)
+	}
+	if _, ok := src.BindingValue([]string{"player", "name", "first"}); ok {
+		t.Fatal("a path through a string should not exist")
+	}
+	if _, ok := src.BindingValue([]string{"missing"}); ok {
+		t.Fatal("a missing field should not exist")
+	}
+	if vm.runtime.Top() != top {
+		t.Fatalf("reading values left %d values on the stack", vm.runtime.Top()-top)
+	}
+}
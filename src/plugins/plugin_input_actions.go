@@ -0,0 +1,88 @@
+/******************************************************************************/
+/* plugin_input_actions.go                                                    */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package plugins
+
+import (
+	"kaijuengine.com/engine/systems/input_actions"
+	"kaijuengine.com/plugins/lua"
+)
+
+// luaInputActionsGlobal is the name of the global table the input actions
+// are exposed to Lua through
+const luaInputActionsGlobal = "input_actions"
+
+// BindInputActions exposes the actions of the map to Lua scripts through the
+// global input_actions table:
+//
+//	if input_actions.pressed("jump") then ... end
+//	local x, y = input_actions.axis("move")
+//	local steer = input_actions.value("steer")
+//	local scheme = input_actions.scheme()
+//
+// The functions read the map's state for the current frame, so they should be
+// called after the map has been updated for the frame.
+func (vm *LuaVM) BindInputActions(actions *input_actions.Map) {
+	state := &vm.runtime
+	actionArg := func(state *lua.State) (string, bool) {
+		if state.Top() < 1 || !state.IsString(1) {
+			return "", false
+		}
+		return state.ToString(1), true
+	}
+	pushBool := func(read func(string) bool) func(*lua.State) int {
+		return func(state *lua.State) int {
+			name, ok := actionArg(state)
+			if !ok {
+				return state.ArgError(1, "expected action name")
+			}
+			state.PushBoolean(read(name))
+			return 1
+		}
+	}
+	state.NewTable()
+	state.PushGoFunction(pushBool(actions.Pressed))
+	state.SetField(-2, "pressed")
+	state.PushGoFunction(pushBool(actions.Released))
+	state.SetField(-2, "released")
+	state.PushGoFunction(pushBool(actions.Held))
+	state.SetField(-2, "held")
+	state.PushGoFunction(func(state *lua.State) int {
+		name, ok := actionArg(state)
+		if !ok {
+			return state.ArgError(1, "expected action name")
+		}
+		state.PushNumber(float64(actions.Value(name)))
+		return 1
+	})
+	state.SetField(-2, "value")
+	state.PushGoFunction(func(state *lua.State) int {
+		name, ok := actionArg(state)
+		if !ok {
+			return state.ArgError(1, "expected action name")
+		}
+		v := actions.Axis2D(name)
+		state.PushNumber(float64(v.X()))
+		state.PushNumber(float64(v.Y()))
+		return 2
+	})
+	state.SetField(-2, "axis")
+	state.PushGoFunction(func(state *lua.State) int {
+		name, ok := actionArg(state)
+		if !ok {
+			return state.ArgError(1, "expected action name")
+		}
+		state.PushNumber(actions.State(name).HeldTime)
+		return 1
+	})
+	state.SetField(-2, "held_time")
+	state.PushGoFunction(func(state *lua.State) int {
+		state.PushString(actions.ActiveScheme())
+		return 1
+	})
+	state.SetField(-2, "scheme")
+	state.SetGlobal(luaInputActionsGlobal)
+}
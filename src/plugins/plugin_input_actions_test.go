@@ -0,0 +1,68 @@
+/******************************************************************************/
+/* plugin_input_actions_test.go                                               */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package plugins
+
+import (
+	"testing"
+
+	"kaijuengine.com/engine/systems/input_actions"
+	"kaijuengine.com/platform/hid"
+)
+
+func TestLuaInputActions(t *testing.T) {
+	withTestRegistry(t)
+	entry := writePlugin(t, map[string]string{
+		"main.lua": `
+function read_input()
+	jumped = input_actions.pressed("jump")
+	holding = input_actions.held("jump")
+	move_x, move_y = input_actions.axis("move")
+	scheme = input_actions.scheme()
+end
+`,
+	})
+	vm, err := launchPlugin(testPluginDB(), entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Close()
+	actions := input_actions.New(input_actions.Profile{
+		Actions: []input_actions.Action{
+			{Name: "jump"},
+			{Name: "move", Type: input_actions.ActionTypeAxis2D},
+		},
+		Schemes: []input_actions.Scheme{{
+			Name: "keyboard",
+			Bindings: map[string][]input_actions.Binding{
+				"jump": {{Input: input_actions.Key(hid.KeyboardKeySpace)}},
+				"move": {{Input: input_actions.Key(hid.KeyboardKeyW), Component: input_actions.ComponentY}},
+			},
+		}},
+	})
+	vm.BindInputActions(actions)
+	kb := hid.NewKeyboard()
+	kb.SetKeyDown(hid.KeyboardKeySpace)
+	kb.SetKeyDown(hid.KeyboardKeyW)
+	actions.Update(1.0/60.0, input_actions.Devices{Keyboard: &kb})
+	vm.InvokeGlobalFunction("read_input")
+	src := vm.BindingSource("_G")
+	expect := map[string]any{
+		"jumped":  true,
+		"holding": true,
+		"move_x":  float64(0),
+		"move_y":  float64(1),
+		"scheme":  "keyboard",
+	}
+	for name, want := range expect {
+		if got, ok := src.BindingValue([]string{name}); !ok || got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	if err := vm.DoStringNamed(`input_actions.pressed({})`, "bad_arg"); err == nil {
+		t.Fatal("a table as the action name should be an error")
+	}
+}
@@ -51,14 +51,28 @@ func (vm *LuaVM) Close() {
 }
 
 func (vm *LuaVM) InvokeGlobalFunction(name string) {
-	// TODO:  Support arguments...
+	vm.InvokeGlobalFunctionArgs(name)
+}
+
+// InvokeGlobalFunctionArgs calls the global Lua function with the given name,
+// if it exists, passing the arguments through the same reflection used for
+// method return values. Missing functions are silently skipped.
+func (vm *LuaVM) InvokeGlobalFunctionArgs(name string, args ...any) {
 	vm.runtime.Global(name)
-	if vm.runtime.IsFunction(-1) {
-		if err := vm.runtime.Call(0, 0); err != nil {
-			slog.Error("failed to invoke lua function", "function", name, "error", err)
-		}
-	} else {
+	if !vm.runtime.IsFunction(-1) {
 		vm.runtime.Pop(1)
+		return
+	}
+	for i := range args {
+		if err := pushReflectValue(&vm.runtime, reflect.ValueOf(args[i])); err != nil {
+			vm.runtime.Pop(i + 1)
+			slog.Error("failed to push lua function argument",
+				"function", name, "argument", i+1, "error", err)
+			return
+		}
+	}
+	if err := vm.runtime.Call(len(args), 0); err != nil {
+		slog.Error("failed to invoke lua function", "function", name, "error", err)
 	}
 }
 
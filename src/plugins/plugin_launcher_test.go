@@ -165,3 +165,27 @@ thing:SetValue("not a number")
 		t.Fatalf("expected reflected argument error, got %v", err)
 	}
 }
+
+func TestInvokeGlobalFunctionArgs(t *testing.T) {
+	withTestRegistry(t)
+	entry := writePlugin(t, map[string]string{
+		"main.lua": `
+result = false
+function on_event(id, count, point)
+	result = id == "entity" and count == 3 and point:Y() == 2
+end
+`,
+	})
+	vm, err := launchPlugin(testPluginDB(), entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Close()
+	vm.InvokeGlobalFunctionArgs("missing_function", "ignored")
+	vm.InvokeGlobalFunctionArgs("on_event", "entity", 3, matrix.NewVec3(1, 2, 3))
+	vm.runtime.Global("result")
+	defer vm.runtime.Pop(1)
+	if !vm.runtime.IsBoolean(-1) || !vm.runtime.ToBoolean(-1) {
+		t.Fatal("expected global function to receive the pushed arguments")
+	}
+}
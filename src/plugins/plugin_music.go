@@ -0,0 +1,94 @@
+/******************************************************************************/
+/* plugin_music.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package plugins
+
+import (
+	"kaijuengine.com/platform/audio"
+	"kaijuengine.com/plugins/lua"
+)
+
+// luaMusicGlobal is the name of the global table the music player is exposed
+// to Lua through
+const luaMusicGlobal = "music"
+
+// BindMusic exposes the music cues to Lua scripts through the global music
+// table:
+//
+//	music.play("combat", 2, "bar") -- cue name, fade seconds, sync mode
+//	music.set_intensity(2, 1.5)    -- intensity, fade seconds
+//	music.stop(3)                  -- fade seconds
+//	local cue = music.current()
+//
+// The sync mode is one of "immediate", "beat", "bar" or "end", the fade and
+// sync can be left out to switch right away.
+func (vm *LuaVM) BindMusic(music *audio.Music) {
+	state := &vm.runtime
+	optNumber := func(state *lua.State, idx int) (float64, bool) {
+		if state.Top() < idx || state.IsNil(idx) {
+			return 0, true
+		}
+		if !state.IsNumber(idx) {
+			return 0, false
+		}
+		return state.ToNumber(idx), true
+	}
+	state.NewTable()
+	state.PushGoFunction(func(state *lua.State) int {
+		if state.Top() < 1 || !state.IsString(1) {
+			return state.ArgError(1, "expected cue name")
+		}
+		fade, ok := optNumber(state, 2)
+		if !ok {
+			return state.ArgError(2, "expected fade seconds")
+		}
+		transition := audio.Transition{Fade: fade}
+		if state.Top() >= 3 && !state.IsNil(3) {
+			sync, err := audio.ParseSyncMode(state.ToString(3))
+			if err != nil {
+				return state.ArgError(3, err.Error())
+			}
+			transition.Sync = sync
+		}
+		if err := music.Play(state.ToString(1), transition); err != nil {
+			return state.Error(err.Error())
+		}
+		return 0
+	})
+	state.SetField(-2, "play")
+	state.PushGoFunction(func(state *lua.State) int {
+		fade, ok := optNumber(state, 1)
+		if !ok {
+			return state.ArgError(1, "expected fade seconds")
+		}
+		music.Stop(fade)
+		return 0
+	})
+	state.SetField(-2, "stop")
+	state.PushGoFunction(func(state *lua.State) int {
+		if state.Top() < 1 || !state.IsNumber(1) {
+			return state.ArgError(1, "expected intensity")
+		}
+		fade, ok := optNumber(state, 2)
+		if !ok {
+			return state.ArgError(2, "expected fade seconds")
+		}
+		music.SetIntensity(float32(state.ToNumber(1)), fade)
+		return 0
+	})
+	state.SetField(-2, "set_intensity")
+	state.PushGoFunction(func(state *lua.State) int {
+		state.PushNumber(float64(music.Intensity()))
+		return 1
+	})
+	state.SetField(-2, "intensity")
+	state.PushGoFunction(func(state *lua.State) int {
+		state.PushString(music.Current())
+		return 1
+	})
+	state.SetField(-2, "current")
+	state.SetGlobal(luaMusicGlobal)
+}
@@ -0,0 +1,52 @@
+/******************************************************************************/
+/* plugin_music_test.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package plugins
+
+import (
+	"testing"
+
+	"kaijuengine.com/platform/audio"
+)
+
+func TestLuaMusic(t *testing.T) {
+	withTestRegistry(t)
+	entry := writePlugin(t, map[string]string{
+		"main.lua": `
+function raise_intensity()
+	music.set_intensity(2)
+	level = music.intensity()
+	cue = music.current()
+end
+`,
+	})
+	vm, err := launchPlugin(testPluginDB(), entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer vm.Close()
+	// Without a cue playing the music player doesn't touch the audio device
+	vm.BindMusic(&audio.Music{})
+	vm.InvokeGlobalFunction("raise_intensity")
+	src := vm.BindingSource("_G")
+	expect := map[string]any{"level": float64(2), "cue": ""}
+	for name, want := range expect {
+		if got, ok := src.BindingValue([]string{name}); !ok || got != want {
+			t.Errorf("%s = %v, want %v", name, got, want)
+		}
+	}
+	errs := map[string]string{
+		"unknown_cue": `music.play("missing")`,
+		"bad_name":    `music.play({})`,
+		"bad_fade":    `music.play("missing", {})`,
+		"bad_sync":    `music.play("missing", 1, "sometime")`,
+	}
+	for name, code := range errs {
+		if err := vm.DoStringNamed(code, name); err == nil {
+			t.Errorf("%s should be an error", name)
+		}
+	}
+}
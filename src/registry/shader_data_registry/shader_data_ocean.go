@@ -49,7 +49,7 @@ func (ShaderDataOcean) Size() int {
 }
 
 func (s *ShaderDataOcean) SelectLights(lights rendering.LightsForRender) {
-	selectPBRLights(&s.ShaderDataBase, &s.LightIds, lights)
+	selectInstanceLights(&s.ShaderDataBase, &s.LightIds, lights)
 }
 
 func (s *ShaderDataOcean) SetBrush(centerXZ matrix.Vec2, radius, ringWidth matrix.Float, color matrix.Color) {
@@ -19,7 +19,6 @@ func init() {
 			ShaderDataBase: rendering.NewShaderDataBase(),
 			VertColors:     matrix.ColorWhite(),
 			MeRoEmAo:       matrix.NewVec4(1, 1, 0, 1),
-			LightIds:       [...]int32{-1, -1, -1, -1},
 		}
 	}, "pbr_skinned")
 }
@@ -31,25 +30,19 @@ type ShaderDataPbrSkinned struct {
 	VertColors matrix.Color
 	MeRoEmAo   matrix.Vec4
 	Flags      StandardShaderDataFlags `visible:"false"`
-	LightIds   [4]int32                `visible:"false"`
 }
 
 func (ShaderDataPbrSkinned) Size() int {
 	return int(rendering.ShaderBaseDataSize +
 		unsafe.Sizeof(ShaderDataPbrSkinned{}.VertColors) +
 		unsafe.Sizeof(ShaderDataPbrSkinned{}.MeRoEmAo) +
-		unsafe.Sizeof(ShaderDataPbrSkinned{}.Flags) +
-		unsafe.Sizeof(ShaderDataPbrSkinned{}.LightIds))
+		unsafe.Sizeof(ShaderDataPbrSkinned{}.Flags))
 }
 
 func (t *ShaderDataPbrSkinned) SkinningHeader() *rendering.SkinnedShaderDataHeader {
 	return &t.SkinnedShaderDataHeader
 }
 
-func (s *ShaderDataPbrSkinned) SelectLights(lights rendering.LightsForRender) {
-	selectPBRLights(&s.ShaderDataBase, &s.LightIds, lights)
-}
-
 func (t *ShaderDataPbrSkinned) InstanceBoundDataSize() int {
 	return t.SkinNamedDataInstanceSize()
 }
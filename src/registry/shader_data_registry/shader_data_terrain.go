@@ -58,7 +58,30 @@ func (t ShaderDataTerrain) Size() int {
 }
 
 func (t *ShaderDataTerrain) SelectLights(lights rendering.LightsForRender) {
-	selectPBRLights(&t.ShaderDataBase, &t.LightIds, lights)
+	selectInstanceLights(&t.ShaderDataBase, &t.LightIds, lights)
+}
+
+// selectInstanceLights fills the fixed set of light ids of the terrain and
+// ocean shaders, unlike the PBR shaders they don't read the light clusters.
+func selectInstanceLights(base *rendering.ShaderDataBase, ids *[4]int32, lights rendering.LightsForRender) {
+	shouldUpdate := lights.HasChanges
+	t := base.Transform()
+	shouldUpdate = shouldUpdate || (t != nil && t.IsDirty())
+	if !shouldUpdate && len(lights.Lights) > 0 {
+		// A stage may finish attaching its lights before a deferred mesh drawing
+		// is added. The collection change has already been consumed in that case,
+		// but an all-disabled ID set shows that this instance has never selected
+		// from the existing lights.
+		hasSelectedLight := false
+		for i := range ids {
+			hasSelectedLight = hasSelectedLight || ids[i] >= 0
+		}
+		shouldUpdate = !hasSelectedLight
+	}
+	if !shouldUpdate {
+		return
+	}
+	lights.SelectLightsAt(ids[:])
 }
 
 func (t *ShaderDataTerrain) InstanceBoundDataSize() int {
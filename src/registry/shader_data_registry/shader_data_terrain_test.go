@@ -5,6 +5,7 @@ import (
 	"unsafe"
 
 	"kaijuengine.com/matrix"
+	"kaijuengine.com/rendering"
 )
 
 func TestShaderDataTerrainUsesBoundLayerParameters(t *testing.T) {
@@ -29,3 +30,32 @@ func TestShaderDataTerrainDefaultsToFourUnselectedLights(t *testing.T) {
 		t.Fatalf("terrain light IDs = %v", data.LightIds)
 	}
 }
+
+func TestTerrainSelectsPreexistingLightsOnFirstUpdate(t *testing.T) {
+	data := Create("terrain").(*ShaderDataTerrain)
+	light := rendering.NewLight(&rendering.GPUDevice{}, nil, nil, rendering.LightTypeDirectional)
+
+	data.SelectLights(rendering.LightsForRender{
+		Lights: []rendering.Light{light},
+		// This is deliberately false: stage reload can add a drawing after the
+		// light collection's change flag was consumed by an earlier frame.
+		HasChanges: false,
+	})
+
+	if data.LightIds[0] != 0 {
+		t.Fatalf("first terrain light id = %d, want 0", data.LightIds[0])
+	}
+}
+
+func TestTerrainRefreshesLightsWhenCollectionChanges(t *testing.T) {
+	data := Create("terrain").(*ShaderDataTerrain)
+	light := rendering.NewLight(&rendering.GPUDevice{}, nil, nil, rendering.LightTypeDirectional)
+	data.SelectLights(rendering.LightsForRender{Lights: []rendering.Light{light}})
+	data.SelectLights(rendering.LightsForRender{HasChanges: true})
+
+	for i, id := range data.LightIds {
+		if id != -1 {
+			t.Fatalf("light id %d after removal = %d, want -1", i, id)
+		}
+	}
+}
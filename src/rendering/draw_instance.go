@@ -130,10 +130,11 @@ type ShaderDataBase struct {
 	destroyed      bool
 	deactivated    bool
 	viewCulled     bool
-	_              [1]byte // Byte alignment
+	modelChanged   bool
 	viewCullStates map[*RenderView]bool
 	shadows        []DrawInstance
 	transform      *matrix.Transform
+	postModel      *matrix.Mat4
 	InitModel      matrix.Mat4
 	model          matrix.Mat4
 }
@@ -220,11 +221,44 @@ func (s *ShaderDataBase) SetModel(model matrix.Mat4) {
 	}
 }
 
+// SetPostModel sets a matrix that is applied after the world matrix of the
+// transform. It visually moves the instance without changing the transform,
+// which is how UI elements are drawn with their CSS transforms.
+func (s *ShaderDataBase) SetPostModel(postModel matrix.Mat4) {
+	if s.postModel != nil && s.postModel.Equals(postModel) {
+		return
+	}
+	s.postModel = &postModel
+	s.forceUpdateTransformModel()
+	s.modelChanged = true
+}
+
+// ClearPostModel removes the matrix set with [ShaderDataBase.SetPostModel]
+func (s *ShaderDataBase) ClearPostModel() {
+	if s.postModel == nil {
+		return
+	}
+	s.postModel = nil
+	s.forceUpdateTransformModel()
+	s.modelChanged = true
+}
+
+// PostModel returns the matrix set with [ShaderDataBase.SetPostModel]
+func (s *ShaderDataBase) PostModel() (matrix.Mat4, bool) {
+	if s.postModel == nil {
+		return matrix.Mat4Identity(), false
+	}
+	return *s.postModel, true
+}
+
 func (s *ShaderDataBase) forceUpdateTransformModel() {
 	if s.transform == nil {
 		return
 	}
 	s.model = matrix.Mat4Multiply(s.InitModel, s.transform.WorldMatrix())
+	if s.postModel != nil {
+		s.model.MultiplyAssign(*s.postModel)
+	}
 }
 
 func (s *ShaderDataBase) UpdateModel(viewCuller ViewCuller, container graviton.AABB) {
@@ -236,9 +270,10 @@ func (s *ShaderDataBase) UpdateModelForView(view *RenderView, viewCuller ViewCul
 	if viewCuller != nil {
 		recalcCulling = viewCuller.ViewChanged()
 	}
-	if s.transform != nil && s.transform.IsDirty() {
+	if s.transform != nil && (s.transform.IsDirty() || s.modelChanged) {
 		s.forceUpdateTransformModel()
 		s.aabb = container.Transform(s.model)
+		s.modelChanged = false
 		recalcCulling = true
 	} else if s.transform == nil {
 		// No Transform: the model matrix is authoritative — particles write their world
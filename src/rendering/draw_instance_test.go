@@ -142,6 +142,36 @@ func TestShaderDataBaseTransformBoundsUsesAllCorners(t *testing.T) {
 	}
 }
 
+func TestShaderDataBasePostModel(t *testing.T) {
+	base := NewShaderDataBase()
+	container := graviton.AABBFromMinMax(matrix.Vec3{-1, -1, -1}, matrix.Vec3{1, 1, 1})
+	var transform matrix.Transform
+	transform.SetupRawTransform()
+	transform.SetPosition(matrix.Vec3{5, 0, 0})
+	base.setTransform(&transform)
+	base.UpdateModel(&testViewCuller{inView: true}, container)
+	transform.ResetDirty()
+
+	post := matrix.Mat4Identity()
+	post.Translate(matrix.Vec3{0, 3, 0})
+	base.SetPostModel(post)
+	if got := base.Model().TransformPoint(matrix.Vec3Zero()); got != (matrix.Vec3{5, 3, 0}) {
+		t.Fatalf("post model translation = %v", got)
+	}
+	// The bounds follow the post model even though the transform is clean
+	base.UpdateModel(&testViewCuller{inView: true}, container)
+	if got := base.renderBounds().Center; got != (matrix.Vec3{5, 3, 0}) {
+		t.Fatalf("post model bounds center = %v", got)
+	}
+	base.ClearPostModel()
+	if _, ok := base.PostModel(); ok {
+		t.Fatal("post model should be cleared")
+	}
+	if got := base.Model().TransformPoint(matrix.Vec3Zero()); got != (matrix.Vec3{5, 0, 0}) {
+		t.Fatalf("cleared model translation = %v", got)
+	}
+}
+
 func TestShaderDataBaseCulling(t *testing.T) {
 	base := NewShaderDataBase()
 	culler := &testViewCuller{inView: false, viewChanged: true}
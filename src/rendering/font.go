@@ -13,13 +13,13 @@ import (
 	"log/slog"
 	"slices"
 	"sync"
+	"sync/atomic"
 	"unicode"
 	"unicode/utf8"
 	"unsafe"
 
 	"kaijuengine.com/engine/assets"
 	"kaijuengine.com/engine/cameras"
-	"kaijuengine.com/klib"
 	"kaijuengine.com/matrix"
 	"kaijuengine.com/platform/profiler/tracing"
 )
@@ -63,6 +63,9 @@ type fontBinChar struct {
 	letter                   rune
 	advance                  float32
 	planeBounds, atlasBounds [4]float32
+	// page is the dynamic atlas page the glyph was rasterized into, nil if it
+	// is in the baked atlas of the face
+	page *glyphAtlasPage
 }
 
 type fontBin struct {
@@ -70,7 +73,11 @@ type fontBin struct {
 	width, height                     int32
 	metrics                           fontBinMetrics
 	letters                           map[rune]fontBinChar
+	kerning                           map[fontKernPair]float32
 	cachedLetters, cachedOrthoLetters map[rune]*cachedLetterMesh
+	// dynamic rasterizes glyphs missing from the baked atlas, it is nil if
+	// the face has no font source
+	dynamic *dynamicFont
 }
 
 type cachedLetterMesh struct {
@@ -91,7 +98,12 @@ type FontCache struct {
 	renderCaches                 RenderCaches
 	assetDb                      assets.Database
 	fontFaces                    map[string]fontBin
+	fallbackFaces                []FontFace
+	missingFaces                 map[string]bool
+	deviceCall                   func(func(*GPUDevice))
 	instanceKey                  int64
+	layoutFrame                  uint64
+	glyphAtlasVersion            uint64
 	FaceMutex                    sync.RWMutex
 }
 
@@ -102,6 +114,10 @@ type TextShaderData struct {
 	BgColor matrix.Color
 	Scissor matrix.Vec4
 	PxRange matrix.Vec2
+	// Shadow is the x and y offset and the blur of the text shadow in pixels,
+	// the shadow is only drawn when ShadowColor isn't fully transparent
+	Shadow      matrix.Vec4
+	ShadowColor matrix.Color
 }
 
 func (s TextShaderData) Size() int {
@@ -110,7 +126,9 @@ func (s TextShaderData) Size() int {
 		unsafe.Sizeof(TextShaderData{}.FgColor) +
 		unsafe.Sizeof(TextShaderData{}.BgColor) +
 		unsafe.Sizeof(TextShaderData{}.Scissor) +
-		unsafe.Sizeof(TextShaderData{}.PxRange))
+		unsafe.Sizeof(TextShaderData{}.PxRange) +
+		unsafe.Sizeof(TextShaderData{}.Shadow) +
+		unsafe.Sizeof(TextShaderData{}.ShadowColor))
 }
 
 func msdfAtlasPxRange() matrix.Vec2 {
@@ -175,12 +193,17 @@ func (cache *FontCache) EMSize(face FontFace) float32 {
 	return cache.fontFaces[face.string()].metrics.EMSize * DefaultFontEMSize
 }
 
+// NewFontCache creates the cache with [FontEmoji] and [FontCJK] as fallback
+// faces, fallback faces are only loaded once text has a glyph that is missing
+// from the face it is drawn with
 func NewFontCache(device *GPUDevice, assetDb assets.Database) FontCache {
 	defer tracing.NewRegion("rendering.NewFontCache").End()
 	return FontCache{
-		device:    device,
-		assetDb:   assetDb,
-		fontFaces: make(map[string]fontBin),
+		device:        device,
+		assetDb:       assetDb,
+		fontFaces:     make(map[string]fontBin),
+		fallbackFaces: []FontFace{FontEmoji, FontCJK},
+		missingFaces:  make(map[string]bool),
 	}
 	// TODO:  Deal with the freeing of mesh/shaders/textures
 }
@@ -201,6 +224,33 @@ func (c fontBinChar) AtlasHeight() float32 {
 	return c.atlasBounds[3] - c.atlasBounds[1]
 }
 
+// findGlyph looks for the letter in the baked atlas and then rasterizes it
+// from the font source if the face has one
+func (font fontBin) findGlyph(letter rune, frame uint64) (fontBinChar, bool) {
+	if ch, ok := font.letters[letter]; ok {
+		return ch, true
+	}
+	if font.dynamic != nil {
+		return font.dynamic.glyph(letter, frame)
+	}
+	return fontBinChar{}, false
+}
+
+func (font fontBin) kern(left, right rune) float32 {
+	if k, ok := font.kerning[fontKernPair{left, right}]; ok || font.dynamic == nil {
+		return k
+	}
+	return font.dynamic.kern(left, right)
+}
+
+// atlas is the texture the glyph is drawn from and its size in pixels
+func (font fontBin) atlas(c fontBinChar) (*Texture, float32, float32) {
+	if c.page != nil {
+		return c.page.texture, glyphAtlasPageSize, glyphAtlasPageSize
+	}
+	return font.texture, float32(font.width), float32(font.height)
+}
+
 func findBinChar(font fontBin, letter rune) fontBinChar {
 	cached, ok := font.letters[letter]
 	if !ok {
@@ -209,54 +259,14 @@ func findBinChar(font fontBin, letter rune) fontBinChar {
 	return cached
 }
 
-func (cache *FontCache) charCountInWidth(font fontBin, runes []rune, maxWidth, scale, letterSpacing float32) int {
-	wrap := false
-	spaceIndex := 0
-	wx := float32(0.0)
-	textLen := len(runes)
-	for i, r := range runes {
-		if r == '\n' {
-			spaceIndex = i
-			wrap = true
-			break
-		} else if unicode.IsSpace(r) {
-			spaceIndex = i
-		}
-		// TODO:  Optimize this to use a linear array
-		ch := findBinChar(font, r)
-		wx += ch.advance * scale
-		if i < textLen-1 {
-			wx += letterSpacing
-		}
-		if wx >= maxWidth && spaceIndex != 0 {
-			wrap = true
-			break
-		}
-	}
-	if !wrap {
-		return textLen
-	} else {
-		return spaceIndex + 1
-	}
-}
-
 func (cache *FontCache) cachedMeshLetter(font fontBin, letter rune, isOrtho bool) *cachedLetterMesh {
 	defer tracing.NewRegion("FontCache.cachedMeshLetter").End()
-	var outLetter *cachedLetterMesh
-	var ok bool
+	// Missing letters were already swapped for the invalid rune proxy when
+	// the text was shaped, letters without a mesh yet return nil
 	if isOrtho {
-		outLetter, ok = font.cachedOrthoLetters[letter]
-	} else {
-		outLetter, ok = font.cachedLetters[letter]
-	}
-	if !ok {
-		if isOrtho {
-			outLetter = font.cachedOrthoLetters[invalidRuneProxy]
-		} else {
-			outLetter = font.cachedLetters[invalidRuneProxy]
-		}
+		return font.cachedOrthoLetters[letter]
 	}
-	return outLetter
+	return font.cachedLetters[letter]
 }
 
 func (cache *FontCache) createLetterMesh(font fontBin, key rune, c fontBinChar, meshCache *MeshCache) {
@@ -266,6 +276,7 @@ func (cache *FontCache) createLetterMesh(font fontBin, key rune, c fontBinChar,
 
 	w := c.Width()
 	h := -c.Height()
+	texture, width, height := font.atlas(c)
 
 	mesh := NewMeshScreenQuad(meshCache)
 	transformation := matrix.Mat4Identity()
@@ -273,21 +284,20 @@ func (cache *FontCache) createLetterMesh(font fontBin, key rune, c fontBinChar,
 	var clm cachedLetterMesh
 	clm.mesh = mesh
 	clm.material = mat
-	clm.texture = font.texture
+	clm.texture = texture
 	clm.transformation = transformation
 	uvx := c.atlasBounds[0]
 	uvy := c.atlasBounds[3]
 	uvw := c.atlasBounds[2] - c.atlasBounds[0]
 	uvh := c.atlasBounds[1] - c.atlasBounds[3]
 	clm.uvs = matrix.NewVec4(
-		uvx/float32(font.width), uvy/float32(font.height),
-		uvw/float32(font.width), uvh/float32(font.height))
+		uvx/width, uvy/height, uvw/width, uvh/height)
 	clm.pxRange = msdfAtlasPxRange()
 	font.cachedLetters[key] = &clm
 
 	clmCpy := clm
 	clmCpy.material = oMat
-	clmCpy.texture = font.texture
+	clmCpy.texture = texture
 	// TODO:  [PORT] Do we need to clone the mesh anymore?
 	//clmCpy.mesh = mesh.Clone()
 	clmCpy.mesh = mesh
@@ -297,7 +307,39 @@ func (cache *FontCache) createLetterMesh(font fontBin, key rune, c fontBinChar,
 func (cache *FontCache) initFont(face FontFace, adb assets.Database) bool {
 	defer tracing.NewRegion("FontCache.initFont").End()
 	bin := fontBin{}
+	bin.cachedLetters = make(map[rune]*cachedLetterMesh)
+	bin.cachedOrthoLetters = make(map[rune]*cachedLetterMesh)
+	source, gpos := readFontSource(face, adb)
+	if source != nil {
+		bin.dynamic = newDynamicFont(face.string(), source)
+		bin.dynamic.gpos = gpos
+		bin.dynamic.createTexture = cache.createGlyphAtlasTexture
+		bin.dynamic.evicted = func(r rune) {
+			delete(bin.cachedLetters, r)
+			delete(bin.cachedOrthoLetters, r)
+			atomic.AddUint64(&cache.glyphAtlasVersion, 1)
+		}
+	}
+	if !cache.readFontBin(face, adb, &bin) {
+		if bin.dynamic == nil {
+			return false
+		}
+		// There is no baked atlas, every glyph is rasterized from the source
+		bin.metrics = bin.dynamic.metrics()
+		bin.letters = make(map[rune]fontBinChar)
+		bin.kerning = make(map[fontKernPair]float32)
+	}
+	cache.fontFaces[face.string()] = bin
+	return true
+}
+
+// readFontBin reads the baked atlas of the face which was generated offline
+func (cache *FontCache) readFontBin(face FontFace, adb assets.Database, bin *fontBin) bool {
+	defer tracing.NewRegion("FontCache.readFontBin").End()
 	textureKey := face.string() + ".png"
+	if !adb.Exists(textureKey) {
+		return false
+	}
 	bin.texture, _ = cache.renderCaches.TextureCache().Texture(textureKey, TextureFilterLinear)
 	if bin.texture != nil {
 		needsReload := bin.texture.RenderId.IsValid() && bin.texture.RenderId.MipLevels != 1
@@ -308,33 +350,12 @@ func (cache *FontCache) initFont(face FontFace, adb assets.Database) bool {
 			}
 		}
 	}
-	bin.cachedLetters = make(map[rune]*cachedLetterMesh)
-	bin.cachedOrthoLetters = make(map[rune]*cachedLetterMesh)
 	out, _ := adb.Read(face.string() + ".bin")
 	if bin.texture == nil || out == nil || len(out) == 0 {
 		return false
 	}
-	read := bytes.NewReader(out)
-	// Create an int32 variable named count that is read from read
-	var count int32
-	binary.Read(read, binary.LittleEndian, &count)
-	binary.Read(read, binary.LittleEndian, &bin.width)
-	binary.Read(read, binary.LittleEndian, &bin.height)
-	// TODO:  Read the metrics into cache.[font]
-	binary.Read(read, binary.LittleEndian, &bin.metrics)
-	bin.letters = make(map[rune]fontBinChar, count)
-	for i := int32(0); i < count; i++ {
-		var fbc fontBinChar
-		var letter uint32
-		binary.Read(read, binary.LittleEndian, &letter)
-		fbc.letter = rune(letter)
-		//utf8_from_unicode(letter, &fbc.letter)
-		binary.Read(read, binary.LittleEndian, &fbc.advance)
-		binary.Read(read, binary.LittleEndian, &fbc.planeBounds)
-		binary.Read(read, binary.LittleEndian, &fbc.atlasBounds)
-		bin.letters[fbc.letter] = fbc
-	}
-	sample := findBinChar(bin, '-')
+	parseFontBin(out, bin)
+	sample := findBinChar(*bin, '-')
 	cSpace := fontBinChar{
 		letter:      ' ',
 		advance:     sample.advance,
@@ -364,12 +385,98 @@ func (cache *FontCache) initFont(face FontFace, adb assets.Database) bool {
 	// only build meshes for codepoints < count, leaving higher glyphs (symbols,
 	// arrows, geometric shapes) to fall back to the invalidRuneProxy.
 	for r, fbc := range bin.letters {
-		cache.createLetterMesh(bin, r, fbc, cache.renderCaches.MeshCache())
+		cache.createLetterMesh(*bin, r, fbc, cache.renderCaches.MeshCache())
 	}
-	cache.fontFaces[face.string()] = bin
 	return true
 }
 
+// parseFontBin reads the glyphs and kerning of a baked font .bin file, the
+// layout is written by generators/msdf
+func parseFontBin(out []byte, bin *fontBin) {
+	read := bytes.NewReader(out)
+	// Create an int32 variable named count that is read from read
+	var count int32
+	binary.Read(read, binary.LittleEndian, &count)
+	binary.Read(read, binary.LittleEndian, &bin.width)
+	binary.Read(read, binary.LittleEndian, &bin.height)
+	// TODO:  Read the metrics into cache.[font]
+	binary.Read(read, binary.LittleEndian, &bin.metrics)
+	bin.letters = make(map[rune]fontBinChar, count)
+	for i := int32(0); i < count; i++ {
+		var fbc fontBinChar
+		var letter uint32
+		binary.Read(read, binary.LittleEndian, &letter)
+		fbc.letter = rune(letter)
+		//utf8_from_unicode(letter, &fbc.letter)
+		binary.Read(read, binary.LittleEndian, &fbc.advance)
+		binary.Read(read, binary.LittleEndian, &fbc.planeBounds)
+		binary.Read(read, binary.LittleEndian, &fbc.atlasBounds)
+		bin.letters[fbc.letter] = fbc
+	}
+	// Kerning was added to the end of the file later, older fonts don't have it
+	var kernCount int32
+	if read.Len() >= 4 {
+		binary.Read(read, binary.LittleEndian, &kernCount)
+	}
+	bin.kerning = make(map[fontKernPair]float32, kernCount)
+	for i := int32(0); i < kernCount; i++ {
+		var left, right int32
+		var advance float32
+		binary.Read(read, binary.LittleEndian, &left)
+		binary.Read(read, binary.LittleEndian, &right)
+		binary.Read(read, binary.LittleEndian, &advance)
+		bin.kerning[fontKernPair{rune(left), rune(right)}] = advance
+	}
+}
+
+func (cache *FontCache) createGlyphAtlasTexture(key string, pixels []byte, size int) *Texture {
+	if cache.renderCaches == nil {
+		return nil
+	}
+	tex, err := cache.renderCaches.TextureCache().InsertRawTexture(key, pixels, size, size, TextureFilterLinear)
+	if err != nil {
+		slog.Error("failed to create the glyph atlas texture", "texture", key, "error", err)
+		return nil
+	}
+	tex.MipLevels = 1
+	return tex
+}
+
+// SetDeviceCall sets how the font cache gets to the GPU device to write glyphs
+// that were rasterized at runtime into their atlas, such as running it on the
+// render thread
+func (cache *FontCache) SetDeviceCall(call func(func(*GPUDevice))) {
+	cache.deviceCall = call
+}
+
+// GlyphAtlasVersion changes any time a glyph is evicted from a runtime glyph
+// atlas, text drawn before it changed may be drawing a glyph that was replaced
+// and should be rendered again
+func (cache *FontCache) GlyphAtlasVersion() uint64 {
+	return atomic.LoadUint64(&cache.glyphAtlasVersion)
+}
+
+// flushGlyphAtlases writes the glyphs rasterized at runtime to the GPU
+func (cache *FontCache) flushGlyphAtlases(faces []fontBin) {
+	defer tracing.NewRegion("FontCache.flushGlyphAtlases").End()
+	for i := range faces {
+		if faces[i].dynamic == nil {
+			continue
+		}
+		faces[i].dynamic.flush(func(texture *Texture, requests []GPUImageWriteRequest) {
+			if cache.deviceCall == nil {
+				if cache.device != nil {
+					texture.WritePixels(cache.device, requests)
+				}
+				return
+			}
+			cache.deviceCall(func(device *GPUDevice) {
+				texture.WritePixels(device, requests)
+			})
+		})
+	}
+}
+
 func (cache *FontCache) Init(caches RenderCaches) error {
 	defer tracing.NewRegion("FontCache.Init").End()
 	var err error
@@ -400,13 +507,14 @@ func (cache *FontCache) RenderMeshes(caches RenderCaches,
 	is3D bool, face FontFace, lineHeight float32, cam *cameras.Container) []Drawing {
 	return cache.RenderMeshesWithLetterSpacing(caches, text, x, y, z, scale, maxWidth,
 		fgColor, bgColor, justify, baseline, rootScale, instanced, is3D, face,
-		lineHeight, 0, cam)
+		lineHeight, 0, TextDirectionAuto, cam)
 }
 
 func (cache *FontCache) RenderMeshesWithLetterSpacing(caches RenderCaches,
 	text string, x, y, z, scale, maxWidth float32, fgColor, bgColor matrix.Color,
 	justify FontJustify, baseline FontBaseline, rootScale matrix.Vec3, instanced,
-	is3D bool, face FontFace, lineHeight, letterSpacing float32, cam *cameras.Container) []Drawing {
+	is3D bool, face FontFace, lineHeight, letterSpacing float32,
+	direction TextDirection, cam *cameras.Container) []Drawing {
 	defer tracing.NewRegion("FontCache.RenderMeshes").End()
 	cache.requireFace(face)
 	es := rootScale
@@ -430,35 +538,16 @@ func (cache *FontCache) RenderMeshesWithLetterSpacing(caches RenderCaches,
 			material = cache.textOrthoMaterial
 		}
 	}
-	// Iterate through all characters
-	runes := []rune(text)
-	textLen := len(runes)
-	charLen := textLen
-	//size_t lenLeft = textLen;
-	current := 0
-	fontMeshes := make([]Drawing, 0)
+	shaped := cache.layoutText(face, text, scale, maxWidth, letterSpacing, maxWidth > 0, direction)
+	fontMeshes := make([]Drawing, 0, len(shaped.glyphs))
 	lineAdvance := fontFace.metrics.LineHeight * scale
 	if lineHeight > 0 {
 		lineAdvance = lineHeight
 	}
 	lineAdvanceNormalized := lineAdvance * inverseHeight
 	maxHeight := -lineAdvance
-	for current < textLen {
-		if maxWidth > 0 {
-			charLen = cache.charCountInWidth(fontFace, runes[current:], maxWidth, scale, letterSpacing)
-		}
-		lineWidth := float32(0.0)
-		if charLen > 0 || unicode.IsSpace(runes[current]) {
-			for i, c := range runes[current : current+charLen] {
-				if c != '\n' {
-					ch := findBinChar(fontFace, c)
-					lineWidth += ch.advance * scale
-					if i < charLen-1 {
-						lineWidth += letterSpacing
-					}
-				}
-			}
-		}
+	for l, line := range shaped.lines {
+		lineWidth := line.width
 		var xOffset, yOffset float32
 		switch justify {
 		case FontJustifyRight:
@@ -484,113 +573,106 @@ func (cache *FontCache) RenderMeshesWithLetterSpacing(caches RenderCaches,
 		xOffset *= inverseWidth
 		yOffset -= fontFace.metrics.Descender * scale
 		yOffset *= inverseHeight
-		justifySpaceAdvance := float32(0)
-		if justify == FontJustifyJustify && current+charLen < textLen && maxWidth > lineWidth {
+		if justify == FontJustifyJustify && l < len(shaped.lines)-1 && maxWidth > lineWidth {
 			spaceCount := 0
-			for _, c := range runes[current : current+charLen] {
+			for i := line.start; i < line.end; i++ {
+				c := shaped.runes[shaped.glyphs[i].cluster]
 				if unicode.IsSpace(c) && c != '\n' {
 					spaceCount++
 				}
 			}
 			if spaceCount > 0 {
-				justifySpaceAdvance = (maxWidth - lineWidth) / float32(spaceCount)
+				shaped.positionLine(l, letterSpacing, (maxWidth-lineWidth)/float32(spaceCount))
 			}
 		}
-		if charLen > 0 || (unicode.IsSpace(runes[current]) && runes[current] != '\n') {
-			for i := current; i < current+charLen; i++ {
-				c := runes[i]
-				if c == '\n' {
-					continue
-				}
-				ch := findBinChar(fontFace, c)
-				xPos := cx + (ch.planeBounds[0] * scale * inverseWidth)
-				yPos := cy + (ch.planeBounds[1] * scale * inverseHeight)
-				xPos += xOffset
-				yPos += yOffset
-				w := ch.Width() * scale * inverseWidth
-				h := ch.Height() * scale * inverseHeight
-				pxRange := msdfAtlasPxRange()
-				var uvs matrix.Vec4
-				var clm *cachedLetterMesh = nil
-				if instanced {
-					clm = cache.cachedMeshLetter(fontFace, c, !is3D)
-					if clm == nil {
-						cache.createLetterMesh(fontFace, c, fontFace.letters[c], cache.renderCaches.MeshCache())
-						clm = cache.cachedMeshLetter(fontFace, c, !is3D)
-					}
-				}
-				var m *Mesh
-				model := matrix.Mat4Identity()
-				zPos := z
-				if slices.Contains(overlappingLetters, c) {
-					zPos -= 0.0001
-				}
+		// Glyphs are drawn in logical order so that the drawings line up with
+		// the runes of the text, each is placed at its visual position
+		for i := line.start; i < line.end; i++ {
+			g := &shaped.glyphs[i]
+			if shaped.isNewLine(g) {
+				continue
+			}
+			ch := g.char
+			c := ch.letter
+			bin := shaped.faces[g.face]
+			texture, atlasWidth, atlasHeight := bin.atlas(ch)
+			xPos := cx + ((g.x + ch.planeBounds[0]*scale) * inverseWidth)
+			yPos := cy + (ch.planeBounds[1] * scale * inverseHeight)
+			xPos += xOffset
+			yPos += yOffset
+			w := ch.Width() * scale * inverseWidth
+			h := ch.Height() * scale * inverseHeight
+			pxRange := msdfAtlasPxRange()
+			var uvs matrix.Vec4
+			var clm *cachedLetterMesh = nil
+			if instanced {
+				clm = cache.cachedMeshLetter(bin, c, !is3D)
 				if clm == nil {
-					var verts [4]Vertex
-					verts[0].Position = matrix.NewVec3(xPos, yPos, zPos)
-					verts[0].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
-					verts[0].UV0 = matrix.Vec2{0.0, 1.0}
-					verts[0].Color = matrix.ColorWhite()
-					verts[1].Position = matrix.NewVec3(xPos, yPos+h, zPos)
-					verts[1].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
-					verts[1].UV0 = matrix.Vec2{0.0, 0.0}
-					verts[1].Color = matrix.ColorWhite()
-					verts[2].Position = matrix.NewVec3(xPos+w, yPos+h, zPos)
-					verts[2].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
-					verts[2].UV0 = matrix.Vec2{1.0, 0.0}
-					verts[2].Color = matrix.ColorWhite()
-					verts[3].Position = matrix.NewVec3(xPos+w, yPos, zPos)
-					verts[3].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
-					verts[3].UV0 = matrix.Vec2{1.0, 1.0}
-					verts[3].Color = matrix.ColorWhite()
-					indexes := [6]uint32{0, 1, 2, 0, 2, 3}
-					m = caches.MeshCache().Mesh(cache.nextInstanceKey(c), verts[:], indexes[:])
-					uvx := ch.atlasBounds[0]
-					uvy := ch.atlasBounds[1]
-					uvw := ch.atlasBounds[2] - ch.atlasBounds[0]
-					uvh := ch.atlasBounds[3] - ch.atlasBounds[1]
-					uvs = matrix.NewVec4(
-						uvx/float32(fontFace.width), uvy/float32(fontFace.height),
-						uvw/float32(fontFace.width), uvh/float32(fontFace.height))
-				} else {
-					// TODO:  Scale and place the mesh based on justify, baseline, etc.
-					model.MultiplyAssign(clm.transformation)
-					model.Scale(matrix.Vec3{scale * inverseWidth, scale * inverseHeight, 1.0})
-					model.Translate(matrix.NewVec3(xPos, (yPos + h), zPos))
-					uvs = clm.uvs
-					m = clm.mesh
-				}
-				shaderData := &TextShaderData{
-					ShaderDataBase: NewShaderDataBase(),
-					FgColor:        fgColor,
-					BgColor:        bgColor,
-					PxRange:        pxRange,
-					UVs:            uvs,
-					Scissor:        matrix.Vec4{-matrix.FloatMax, -matrix.FloatMax, matrix.FloatMax, matrix.FloatMax},
-				}
-				shaderData.SetModel(model)
-				drawing := Drawing{
-					Material:   material.CreateInstance([]*Texture{fontFace.texture}),
-					Mesh:       m,
-					ShaderData: shaderData,
-					Transform:  nil,
-					ViewCuller: cam,
-				}
-				fontMeshes = append(fontMeshes, drawing)
-				cx += ch.advance * scale * inverseWidth
-				if i < current+charLen-1 {
-					cx += letterSpacing * inverseWidth
-				}
-				if justifySpaceAdvance > 0 && unicode.IsSpace(c) && c != '\n' {
-					cx += justifySpaceAdvance * inverseWidth
+					cache.createLetterMesh(bin, c, ch, cache.renderCaches.MeshCache())
+					clm = cache.cachedMeshLetter(bin, c, !is3D)
 				}
 			}
+			var m *Mesh
+			model := matrix.Mat4Identity()
+			zPos := z
+			if slices.Contains(overlappingLetters, c) {
+				zPos -= 0.0001
+			}
+			if clm == nil {
+				var verts [4]Vertex
+				verts[0].Position = matrix.NewVec3(xPos, yPos, zPos)
+				verts[0].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
+				verts[0].UV0 = matrix.Vec2{0.0, 1.0}
+				verts[0].Color = matrix.ColorWhite()
+				verts[1].Position = matrix.NewVec3(xPos, yPos+h, zPos)
+				verts[1].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
+				verts[1].UV0 = matrix.Vec2{0.0, 0.0}
+				verts[1].Color = matrix.ColorWhite()
+				verts[2].Position = matrix.NewVec3(xPos+w, yPos+h, zPos)
+				verts[2].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
+				verts[2].UV0 = matrix.Vec2{1.0, 0.0}
+				verts[2].Color = matrix.ColorWhite()
+				verts[3].Position = matrix.NewVec3(xPos+w, yPos, zPos)
+				verts[3].Normal = matrix.NewVec3(0.0, 0.0, 1.0)
+				verts[3].UV0 = matrix.Vec2{1.0, 1.0}
+				verts[3].Color = matrix.ColorWhite()
+				indexes := [6]uint32{0, 1, 2, 0, 2, 3}
+				m = caches.MeshCache().Mesh(cache.nextInstanceKey(c), verts[:], indexes[:])
+				uvx := ch.atlasBounds[0]
+				uvy := ch.atlasBounds[1]
+				uvw := ch.atlasBounds[2] - ch.atlasBounds[0]
+				uvh := ch.atlasBounds[3] - ch.atlasBounds[1]
+				uvs = matrix.NewVec4(uvx/atlasWidth, uvy/atlasHeight,
+					uvw/atlasWidth, uvh/atlasHeight)
+			} else {
+				// TODO:  Scale and place the mesh based on justify, baseline, etc.
+				model.MultiplyAssign(clm.transformation)
+				model.Scale(matrix.Vec3{scale * inverseWidth, scale * inverseHeight, 1.0})
+				model.Translate(matrix.NewVec3(xPos, (yPos + h), zPos))
+				uvs = clm.uvs
+				m = clm.mesh
+			}
+			shaderData := &TextShaderData{
+				ShaderDataBase: NewShaderDataBase(),
+				FgColor:        fgColor,
+				BgColor:        bgColor,
+				PxRange:        pxRange,
+				UVs:            uvs,
+				Scissor:        matrix.Vec4{-matrix.FloatMax, -matrix.FloatMax, matrix.FloatMax, matrix.FloatMax},
+			}
+			shaderData.SetModel(model)
+			drawing := Drawing{
+				Material:   material.CreateInstance([]*Texture{texture}),
+				Mesh:       m,
+				ShaderData: shaderData,
+				Transform:  nil,
+				ViewCuller: cam,
+			}
+			fontMeshes = append(fontMeshes, drawing)
 		}
-		cx = x
 		cy -= lineAdvanceNormalized
-		//lenLeft -= charLen;
-		current += charLen
 	}
+	cache.flushGlyphAtlases(shaped.faces)
 	return fontMeshes
 }
 
@@ -599,20 +681,10 @@ func (cache *FontCache) MeasureString(face FontFace, text string, scale float32)
 }
 
 func (cache *FontCache) MeasureStringWithLetterSpacing(face FontFace, text string, scale, letterSpacing float32) float32 {
-	cache.requireFace(face)
-	x, maxX := float32(0.0), float32(0.0)
-	runes := []rune(text)
-	for i, r := range runes {
-		if r == '\n' {
-			x = 0.0
-		} else {
-			ch := findBinChar(cache.fontFaces[face.string()], r)
-			x += ch.advance * scale
-			if i < len(runes)-1 && runes[i+1] != '\n' {
-				x += letterSpacing
-			}
-			maxX = max(maxX, x)
-		}
+	shaped := cache.layoutText(face, text, scale, matrix.FloatMax, letterSpacing, true, TextDirectionAuto)
+	maxX := float32(0.0)
+	for i := range shaped.lines {
+		maxX = max(maxX, shaped.lines[i].width)
 	}
 	return maxX
 }
@@ -628,54 +700,38 @@ func (cache *FontCache) MeasureStringWithinWithLetterSpacing(face FontFace, text
 	if lineHeight != 0 {
 		maxHeight = lineHeight
 	}
-	var x, y float32 = 0.0, 0.0
-	clip := []rune(text)
-	for len(clip) > 0 {
-		count := klib.Clamp(cache.charCountInWidth(fontFace, clip, maxWidth, scale, letterSpacing), 0, len(clip))
-		x = max(x, cache.MeasureStringWithLetterSpacing(face, string(clip[:count]), scale, letterSpacing))
-		y += maxHeight
-		clip = []rune(clip)[count:]
+	shaped := cache.layoutText(face, text, scale, maxWidth, letterSpacing, true, TextDirectionAuto)
+	var x float32
+	for i := range shaped.lines {
+		x = max(x, shaped.lines[i].width)
 	}
-	return matrix.NewVec2(x, y)
+	return matrix.NewVec2(x, maxHeight*float32(len(shaped.lines)))
 }
 
 func (cache *FontCache) StringRectsWithinNew(face FontFace, text string, scale, maxWidth float32) []matrix.Vec4 {
-	return cache.StringRectsWithinWithLetterSpacing(face, text, scale, maxWidth, 0, 0)
+	return cache.StringRectsWithinWithLetterSpacing(face, text, scale, maxWidth, 0, 0, TextDirectionAuto)
 }
 
-func (cache *FontCache) StringRectsWithinWithLetterSpacing(face FontFace, text string, scale, maxWidth, lineHeight, letterSpacing float32) []matrix.Vec4 {
+// StringRectsWithinWithLetterSpacing returns the rectangle of each rune of the
+// text, in the same order as the runes. Right to left text is positioned where
+// it is drawn, so the rectangles of neighbouring runes may not be in order.
+func (cache *FontCache) StringRectsWithinWithLetterSpacing(face FontFace, text string, scale, maxWidth, lineHeight, letterSpacing float32, direction TextDirection) []matrix.Vec4 {
 	defer tracing.NewRegion("FontCache.StringRectsWithinNew").End()
 	cache.requireFace(face)
 	fontFace := cache.fontFaces[face.string()]
-	rects := make([]matrix.Vec4, 0)
-	current := 0
-	var x, y float32 = 0.0, 0.0
 	height := fontFace.metrics.LineHeight * scale
 	if lineHeight > 0 {
 		height = lineHeight
 	}
-	runes := []rune(text)
-	for current < len(runes) {
-		offset := current
-		count := len(runes[current:])
-		if maxWidth > 0 {
-			count = cache.charCountInWidth(fontFace, runes[current:], maxWidth, scale, letterSpacing)
-		}
-		x = 0.0
-		for i, r := range runes[offset : offset+count] {
-			w := float32(0)
-			if r != '\n' {
-				ch := findBinChar(fontFace, r)
-				w = ch.advance * scale
-			}
-			rects = append(rects, matrix.NewVec4(x, y, w, height))
-			current++
-			if r == '\n' {
-				continue
-			}
-			x += w
-			if i < count-1 {
-				x += letterSpacing
+	shaped := cache.layoutText(face, text, scale, maxWidth, letterSpacing, maxWidth > 0, direction)
+	rects := make([]matrix.Vec4, 0, len(shaped.runes))
+	y := float32(0.0)
+	for _, line := range shaped.lines {
+		for i := line.start; i < line.end; i++ {
+			g := &shaped.glyphs[i]
+			for r := range g.runes {
+				x, w := shaped.runeRect(g, r)
+				rects = append(rects, matrix.NewVec4(x, y, w, height))
 			}
 		}
 		y += height
@@ -685,17 +741,8 @@ func (cache *FontCache) StringRectsWithinWithLetterSpacing(face FontFace, text s
 
 func (cache *FontCache) LineCountWithin(face FontFace, text string, scale, maxWidth float32) int {
 	defer tracing.NewRegion("FontCache.LineCountWithin").End()
-	cache.requireFace(face)
-	lines := 0
-	runes := []rune(text)
-	textLen := len(runes)
-	current := 0
-	fontFace := cache.fontFaces[face.string()]
-	for current < textLen {
-		current += cache.charCountInWidth(fontFace, runes[current:], maxWidth, scale, 0)
-		lines++
-	}
-	return max(1, lines)
+	shaped := cache.layoutText(face, text, scale, maxWidth, 0, true, TextDirectionAuto)
+	return max(1, len(shaped.lines))
 }
 
 func (cache *FontCache) MeasureCharacter(face string, r rune, pixelSize float32) matrix.Vec2 {
@@ -704,15 +751,15 @@ func (cache *FontCache) MeasureCharacter(face string, r rune, pixelSize float32)
 }
 
 func (cache *FontCache) PointOffsetWithin(face FontFace, text string, point matrix.Vec2, scale, maxWidth float32) int {
-	return cache.PointOffsetWithinWithLetterSpacing(face, text, point, scale, maxWidth, 0, 0)
+	return cache.PointOffsetWithinWithLetterSpacing(face, text, point, scale, maxWidth, 0, 0, TextDirectionAuto)
 }
 
-func (cache *FontCache) PointOffsetWithinWithLetterSpacing(face FontFace, text string, point matrix.Vec2, scale, maxWidth, lineHeight, letterSpacing float32) int {
+func (cache *FontCache) PointOffsetWithinWithLetterSpacing(face FontFace, text string, point matrix.Vec2, scale, maxWidth, lineHeight, letterSpacing float32, direction TextDirection) int {
 	defer tracing.NewRegion("FontCache.PointOffsetWithin").End()
 	cache.requireFace(face)
 	textLen := utf8.RuneCountInString(text)
 	idx := textLen
-	rects := cache.StringRectsWithinWithLetterSpacing(face, text, scale, maxWidth, lineHeight, letterSpacing)
+	rects := cache.StringRectsWithinWithLetterSpacing(face, text, scale, maxWidth, lineHeight, letterSpacing, direction)
 	for i := 0; i < textLen; i++ {
 		width := rects[i].Z()
 		height := rects[i].W()
@@ -730,4 +777,6 @@ func (cache *FontCache) Destroy() {
 	cache.textMaterialTransparent = nil
 	cache.textOrthoMaterialTransparent = nil
 	cache.fontFaces = make(map[string]fontBin)
+	cache.missingFaces = make(map[string]bool)
+	atomic.AddUint64(&cache.glyphAtlasVersion, 1)
 }
@@ -0,0 +1,154 @@
+/******************************************************************************/
+/* font_arabic.go                                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import "unicode"
+
+const (
+	arabicIsolated = iota
+	arabicFinal
+	arabicInitial
+	arabicMedial
+)
+
+const (
+	arabicLam     = 0x0644
+	arabicTatweel = 0x0640
+	zeroWidthJoin = 0x200D
+	// arabicLigated replaces the second rune of a ligature, the glyph of the
+	// first rune is drawn for both of them
+	arabicLigated = rune(-1)
+)
+
+// arabicJoining is how an Arabic letter connects to the letters around it and
+// its presentation forms (isolated, final, initial, medial). Letters that are
+// not dual joining only connect to the letter before them.
+type arabicJoining struct {
+	dual  bool
+	forms [4]rune
+}
+
+func arabicRight(isolated rune) arabicJoining {
+	return arabicJoining{forms: [4]rune{isolated, isolated + 1}}
+}
+
+func arabicDual(isolated rune) arabicJoining {
+	return arabicJoining{dual: true,
+		forms: [4]rune{isolated, isolated + 1, isolated + 2, isolated + 3}}
+}
+
+var arabicLetters = map[rune]arabicJoining{
+	0x0621: {forms: [4]rune{0xFE80}},
+	0x0622: arabicRight(0xFE81), 0x0623: arabicRight(0xFE83),
+	0x0624: arabicRight(0xFE85), 0x0625: arabicRight(0xFE87),
+	0x0626: arabicDual(0xFE89), 0x0627: arabicRight(0xFE8D),
+	0x0628: arabicDual(0xFE8F), 0x0629: arabicRight(0xFE93),
+	0x062A: arabicDual(0xFE95), 0x062B: arabicDual(0xFE99),
+	0x062C: arabicDual(0xFE9D), 0x062D: arabicDual(0xFEA1),
+	0x062E: arabicDual(0xFEA5), 0x062F: arabicRight(0xFEA9),
+	0x0630: arabicRight(0xFEAB), 0x0631: arabicRight(0xFEAD),
+	0x0632: arabicRight(0xFEAF), 0x0633: arabicDual(0xFEB1),
+	0x0634: arabicDual(0xFEB5), 0x0635: arabicDual(0xFEB9),
+	0x0636: arabicDual(0xFEBD), 0x0637: arabicDual(0xFEC1),
+	0x0638: arabicDual(0xFEC5), 0x0639: arabicDual(0xFEC9),
+	0x063A: arabicDual(0xFECD), 0x0641: arabicDual(0xFED1),
+	0x0642: arabicDual(0xFED5), 0x0643: arabicDual(0xFED9),
+	0x0644: arabicDual(0xFEDD), 0x0645: arabicDual(0xFEE1),
+	0x0646: arabicDual(0xFEE5), 0x0647: arabicDual(0xFEE9),
+	0x0648: arabicRight(0xFEED), 0x0649: arabicRight(0xFEEF),
+	0x064A: arabicDual(0xFEF1),
+	// Persian and Urdu letters from the presentation forms A block
+	0x067E: arabicDual(0xFB56), 0x0686: arabicDual(0xFB7A),
+	0x0698: arabicRight(0xFB8A), 0x06A9: arabicDual(0xFB8E),
+	0x06AF: arabicDual(0xFB92), 0x06CC: arabicDual(0xFBFC),
+}
+
+// arabicLamAlef is the isolated and final form of the ligature a lam makes
+// with the alef that follows it
+var arabicLamAlef = map[rune][2]rune{
+	0x0622: {0xFEF5, 0xFEF6},
+	0x0623: {0xFEF7, 0xFEF8},
+	0x0625: {0xFEF9, 0xFEFA},
+	0x0627: {0xFEFB, 0xFEFC},
+}
+
+func arabicJoinsNext(r rune) bool {
+	if r == arabicTatweel || r == zeroWidthJoin {
+		return true
+	}
+	j, ok := arabicLetters[r]
+	return ok && j.dual
+}
+
+func arabicJoinsPrevious(r rune) bool {
+	if r == arabicTatweel || r == zeroWidthJoin {
+		return true
+	}
+	j, ok := arabicLetters[r]
+	return ok && j.forms[arabicFinal] != 0
+}
+
+func arabicTransparent(r rune) bool { return unicode.Is(unicode.Mn, r) }
+
+// arabicForms replaces Arabic letters with the contextual form they take next
+// to the letters they join to, and a lam followed by an alef with their
+// ligature. A form is only used if has reports that a face can draw it. The
+// same slice is returned when there is nothing to shape.
+func arabicForms(runes []rune, has func(r rune) bool) []rune {
+	shaped := runes
+	for i, r := range runes {
+		if r < 0x0621 || r > 0x06CC || shaped[i] == arabicLigated {
+			continue
+		}
+		j, ok := arabicLetters[r]
+		if !ok {
+			continue
+		}
+		prev := i - 1
+		for prev >= 0 && arabicTransparent(runes[prev]) {
+			prev--
+		}
+		joinPrev := prev >= 0 && arabicJoinsNext(runes[prev]) && j.forms[arabicFinal] != 0
+		if r == arabicLam && i+1 < len(runes) {
+			if lig, ok := arabicLamAlef[runes[i+1]]; ok {
+				form := lig[0]
+				if joinPrev {
+					form = lig[1]
+				}
+				if has(form) {
+					if &shaped[0] == &runes[0] {
+						shaped = append([]rune(nil), runes...)
+					}
+					shaped[i] = form
+					shaped[i+1] = arabicLigated
+					continue
+				}
+			}
+		}
+		next := i + 1
+		for next < len(runes) && arabicTransparent(runes[next]) {
+			next++
+		}
+		joinNext := j.dual && next < len(runes) && arabicJoinsPrevious(runes[next])
+		form := arabicIsolated
+		switch {
+		case joinPrev && joinNext:
+			form = arabicMedial
+		case joinPrev:
+			form = arabicFinal
+		case joinNext:
+			form = arabicInitial
+		}
+		if f := j.forms[form]; f != 0 && f != r && has(f) {
+			if &shaped[0] == &runes[0] {
+				shaped = append([]rune(nil), runes...)
+			}
+			shaped[i] = f
+		}
+	}
+	return shaped
+}
@@ -0,0 +1,312 @@
+/******************************************************************************/
+/* font_bidi.go                                                               */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import "golang.org/x/text/unicode/bidi"
+
+// TextDirection is the base direction that a paragraph of text is laid out in,
+// characters with a strong direction of their own (such as Arabic or Hebrew
+// letters within English text) are still reordered by the bidi algorithm
+type TextDirection uint8
+
+const (
+	// TextDirectionAuto picks the direction of each paragraph from its first
+	// strongly directional character, left to right if it has none
+	TextDirectionAuto = TextDirection(iota)
+	TextDirectionLeftToRight
+	TextDirectionRightToLeft
+	// TextDirectionLeftToRightOverride lays out every character left to right
+	// regardless of its own direction
+	TextDirectionLeftToRightOverride
+	// TextDirectionRightToLeftOverride lays out every character right to left
+	// regardless of its own direction
+	TextDirectionRightToLeftOverride
+)
+
+// IsRightToLeft returns true if the direction is right to left, the direction
+// of [TextDirectionAuto] isn't known until the text is laid out
+func (d TextDirection) IsRightToLeft() bool {
+	return d == TextDirectionRightToLeft || d == TextDirectionRightToLeftOverride
+}
+
+// IsOverride returns true if the direction ignores the direction of the
+// characters within the text
+func (d TextDirection) IsOverride() bool {
+	return d == TextDirectionLeftToRightOverride || d == TextDirectionRightToLeftOverride
+}
+
+// WithOverride returns the same direction with the override turned on or off,
+// overriding [TextDirectionAuto] will override to left to right
+func (d TextDirection) WithOverride(override bool) TextDirection {
+	switch {
+	case override && d.IsRightToLeft():
+		return TextDirectionRightToLeftOverride
+	case override:
+		return TextDirectionLeftToRightOverride
+	case d == TextDirectionAuto:
+		return TextDirectionAuto
+	case d.IsRightToLeft():
+		return TextDirectionRightToLeft
+	default:
+		return TextDirectionLeftToRight
+	}
+}
+
+// bidiMirrors are the characters that are drawn with their mirror image when
+// they are within right to left text
+var bidiMirrors = map[rune]rune{
+	'(': ')', ')': '(', '[': ']', ']': '[', '{': '}', '}': '{', '<': '>',
+	'>': '<', '«': '»', '»': '«', '‹': '›', '›': '‹',
+}
+
+// bidiResolve resolves the embedding level of each rune following the implicit
+// rules of the Unicode bidirectional algorithm (UAX #9). Every paragraph, which
+// is split on new lines, gets its own base level which is returned per rune in
+// bases. Explicit embedding and isolate controls are not supported and are
+// dropped like boundary neutrals. Both slices are nil if all of the text is
+// left to right, which is by far the most common case.
+func bidiResolve(runes []rune, dir TextDirection) (levels, bases []uint8) {
+	classes := make([]bidi.Class, len(runes))
+	hasRTL := dir.IsRightToLeft()
+	for i, r := range runes {
+		p, _ := bidi.LookupRune(r)
+		classes[i] = p.Class()
+		switch classes[i] {
+		case bidi.R, bidi.AL, bidi.AN:
+			hasRTL = true
+		case bidi.Control, bidi.BN:
+			classes[i] = bidi.BN
+		}
+	}
+	if !hasRTL {
+		return nil, nil
+	}
+	levels = make([]uint8, len(runes))
+	bases = make([]uint8, len(runes))
+	for start := 0; start < len(runes); {
+		end := start
+		for end < len(runes) && classes[end] != bidi.B {
+			end++
+		}
+		base := bidiParagraphLevel(classes[start:end], dir)
+		if dir.IsOverride() {
+			for i := start; i < end; i++ {
+				levels[i] = base
+			}
+		} else {
+			bidiResolveParagraph(classes[start:end], levels[start:end], base)
+		}
+		if end < len(runes) {
+			levels[end] = base
+			end++
+		}
+		for i := start; i < end; i++ {
+			bases[i] = base
+		}
+		start = end
+	}
+	return levels, bases
+}
+
+func bidiParagraphLevel(classes []bidi.Class, dir TextDirection) uint8 {
+	if dir != TextDirectionAuto {
+		if dir.IsRightToLeft() {
+			return 1
+		}
+		return 0
+	}
+	for _, c := range classes {
+		switch c {
+		case bidi.L:
+			return 0
+		case bidi.R, bidi.AL:
+			return 1
+		}
+	}
+	return 0
+}
+
+// bidiResolveParagraph runs the weak type (W1-W7), neutral type (N1-N2) and
+// implicit level (I1-I2) rules over a single paragraph
+func bidiResolveParagraph(classes []bidi.Class, levels []uint8, base uint8) {
+	// X9, boundary neutrals are removed and take the level of what is before
+	idx := make([]int, 0, len(classes))
+	for i, c := range classes {
+		if c != bidi.BN {
+			idx = append(idx, i)
+		}
+	}
+	t := make([]bidi.Class, len(idx))
+	for i := range idx {
+		t[i] = classes[idx[i]]
+	}
+	sos := bidi.L
+	if base&1 != 0 {
+		sos = bidi.R
+	}
+	// W1
+	for i := range t {
+		if t[i] == bidi.NSM {
+			if i == 0 {
+				t[i] = sos
+			} else {
+				t[i] = t[i-1]
+			}
+		}
+	}
+	// W2 and W3
+	lastStrong := sos
+	for i := range t {
+		switch t[i] {
+		case bidi.L, bidi.R:
+			lastStrong = t[i]
+		case bidi.AL:
+			lastStrong = bidi.AL
+			t[i] = bidi.R
+		case bidi.EN:
+			if lastStrong == bidi.AL {
+				t[i] = bidi.AN
+			}
+		}
+	}
+	// W4
+	for i := 1; i < len(t)-1; i++ {
+		if t[i-1] != t[i+1] {
+			continue
+		}
+		if (t[i] == bidi.ES || t[i] == bidi.CS) && t[i-1] == bidi.EN {
+			t[i] = bidi.EN
+		} else if t[i] == bidi.CS && t[i-1] == bidi.AN {
+			t[i] = bidi.AN
+		}
+	}
+	// W5
+	for i := 0; i < len(t); i++ {
+		if t[i] != bidi.ET {
+			continue
+		}
+		end := i
+		for end < len(t) && t[end] == bidi.ET {
+			end++
+		}
+		if (i > 0 && t[i-1] == bidi.EN) || (end < len(t) && t[end] == bidi.EN) {
+			for j := i; j < end; j++ {
+				t[j] = bidi.EN
+			}
+		}
+		i = end - 1
+	}
+	// W6 and W7
+	lastStrong = sos
+	for i := range t {
+		switch t[i] {
+		case bidi.ES, bidi.ET, bidi.CS:
+			t[i] = bidi.ON
+		case bidi.L, bidi.R:
+			lastStrong = t[i]
+		case bidi.EN:
+			if lastStrong == bidi.L {
+				t[i] = bidi.L
+			}
+		}
+	}
+	// N1 and N2
+	strong := func(c bidi.Class) bidi.Class {
+		if c == bidi.L {
+			return bidi.L
+		}
+		return bidi.R
+	}
+	for i := 0; i < len(t); i++ {
+		if !bidiIsNeutral(t[i]) {
+			continue
+		}
+		end := i
+		for end < len(t) && bidiIsNeutral(t[end]) {
+			end++
+		}
+		before, after := sos, sos
+		if i > 0 {
+			before = strong(t[i-1])
+		}
+		if end < len(t) {
+			after = strong(t[end])
+		}
+		resolved := sos
+		if before == after {
+			resolved = before
+		}
+		for j := i; j < end; j++ {
+			t[j] = resolved
+		}
+		i = end - 1
+	}
+	// I1 and I2
+	for i := range t {
+		lvl := base
+		if base&1 == 0 {
+			switch t[i] {
+			case bidi.R:
+				lvl++
+			case bidi.AN, bidi.EN:
+				lvl += 2
+			}
+		} else if t[i] != bidi.R {
+			lvl++
+		}
+		levels[idx[i]] = lvl
+	}
+	prev := base
+	for i, c := range classes {
+		if c == bidi.BN {
+			levels[i] = prev
+		}
+		prev = levels[i]
+	}
+}
+
+func bidiIsNeutral(c bidi.Class) bool {
+	return c == bidi.B || c == bidi.S || c == bidi.WS || c == bidi.ON
+}
+
+// bidiVisualOrder reverses the runs of levels (L2) and returns the logical
+// index of each position from left to right, nil is returned if nothing is
+// reversed so callers can keep the logical order
+func bidiVisualOrder(levels []uint8) []int {
+	highest, lowest := uint8(0), uint8(0xFF)
+	for _, l := range levels {
+		highest = max(highest, l)
+		lowest = min(lowest, l)
+	}
+	if highest == 0 {
+		return nil
+	}
+	lowestOdd := lowest | 1
+	order := make([]int, len(levels))
+	lv := make([]uint8, len(levels))
+	for i := range order {
+		order[i] = i
+	}
+	copy(lv, levels)
+	for level := highest; level >= lowestOdd; level-- {
+		for i := 0; i < len(lv); i++ {
+			if lv[i] < level {
+				continue
+			}
+			end := i
+			for end < len(lv) && lv[end] >= level {
+				end++
+			}
+			for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+				order[a], order[b] = order[b], order[a]
+				lv[a], lv[b] = lv[b], lv[a]
+			}
+			i = end
+		}
+	}
+	return order
+}
@@ -0,0 +1,73 @@
+/******************************************************************************/
+/* font_bidi_test.go                                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestBidiResolveLeftToRightIsNil(t *testing.T) {
+	if levels, bases := bidiResolve([]rune("hello 123"), TextDirectionAuto); levels != nil || bases != nil {
+		t.Fatal("expected no levels for left to right text")
+	}
+}
+
+func TestBidiResolveLevels(t *testing.T) {
+	cases := []struct {
+		text string
+		dir  TextDirection
+		want []uint8
+	}{
+		{"ab אב", TextDirectionAuto, []uint8{0, 0, 0, 1, 1}},
+		{"אב ab", TextDirectionAuto, []uint8{1, 1, 1, 2, 2}},
+		{"אב 12", TextDirectionAuto, []uint8{1, 1, 1, 2, 2}},
+		{"ab אב", TextDirectionRightToLeft, []uint8{2, 2, 1, 1, 1}},
+		{"ab אב", TextDirectionLeftToRightOverride, []uint8{0, 0, 0, 0, 0}},
+		{"ab\nאב", TextDirectionAuto, []uint8{0, 0, 0, 1, 1}},
+	}
+	for _, c := range cases {
+		levels, _ := bidiResolve([]rune(c.text), c.dir)
+		if !slices.Equal(levels, c.want) {
+			t.Fatalf("levels of %q = %v, want %v", c.text, levels, c.want)
+		}
+	}
+}
+
+func TestBidiResolveParagraphBases(t *testing.T) {
+	_, bases := bidiResolve([]rune("ab\nאב"), TextDirectionAuto)
+	if want := []uint8{0, 0, 0, 1, 1}; !slices.Equal(bases, want) {
+		t.Fatalf("bases = %v, want %v", bases, want)
+	}
+}
+
+func TestBidiVisualOrder(t *testing.T) {
+	if order := bidiVisualOrder([]uint8{0, 0, 0}); order != nil {
+		t.Fatalf("expected no reordering, got %v", order)
+	}
+	if order, want := bidiVisualOrder([]uint8{0, 1, 1, 0}), []int{0, 2, 1, 3}; !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	if order, want := bidiVisualOrder([]uint8{1, 1, 2, 2}), []int{2, 3, 1, 0}; !slices.Equal(order, want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+}
+
+func TestTextDirectionWithOverride(t *testing.T) {
+	if got := TextDirectionRightToLeft.WithOverride(true); got != TextDirectionRightToLeftOverride {
+		t.Fatalf("got %v, want right to left override", got)
+	}
+	if got := TextDirectionAuto.WithOverride(true); got != TextDirectionLeftToRightOverride {
+		t.Fatalf("got %v, want left to right override", got)
+	}
+	if got := TextDirectionRightToLeftOverride.WithOverride(false); got != TextDirectionRightToLeft {
+		t.Fatalf("got %v, want right to left", got)
+	}
+	if got := TextDirectionAuto.WithOverride(false); got != TextDirectionAuto {
+		t.Fatalf("got %v, want auto", got)
+	}
+}
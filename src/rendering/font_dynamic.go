@@ -0,0 +1,359 @@
+/******************************************************************************/
+/* font_dynamic.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+const (
+	// dynamicGlyphPxPerEm is the size glyphs are rasterized at, this is the
+	// same size the offline font atlases are generated at
+	dynamicGlyphPxPerEm = 64
+	glyphAtlasPageSize  = 1024
+	glyphAtlasMaxPages  = 4
+)
+
+// fontSourceExtensions are the font files that glyphs can be rasterized from
+// when they are missing from the baked atlas of a face
+var fontSourceExtensions = []string{".ttf", ".otf"}
+
+// glyphAtlasPage is a single texture that dynamically rasterized glyphs are
+// packed into. The page is split into a grid of equally sized cells, each one
+// holds a single glyph.
+type glyphAtlasPage struct {
+	key     string
+	texture *Texture
+	// pixels are RGBA with the top row first, the texture is created from the
+	// same memory so it has any glyphs written before it was uploaded
+	pixels []byte
+	free   []int
+	dirty  map[int]bool
+}
+
+type dynamicGlyph struct {
+	char    fontBinChar
+	cell    int
+	page    *glyphAtlasPage
+	lastUse uint64
+	element *list.Element
+}
+
+// dynamicFont rasterizes the MSDF for glyphs of a TTF/OTF font when they are
+// first used. When all of the pages are full the glyph that was used least
+// recently is evicted to make room.
+type dynamicFont struct {
+	key        string
+	font       *sfnt.Font
+	buffer     sfnt.Buffer
+	cellWidth  int
+	cellHeight int
+	columns    int
+	pages      []*glyphAtlasPage
+	glyphs     map[rune]*dynamicGlyph
+	missing    map[rune]bool
+	kerning    map[fontKernPair]float32
+	gpos       fontGPOSKerning
+	lru        list.List
+	// createTexture creates the texture for a new page, it is nil when there
+	// is no renderer (such as in tests)
+	createTexture func(key string, pixels []byte, size int) *Texture
+	// evicted is called after a glyph has been removed from the atlas
+	evicted func(r rune)
+	mutex   sync.Mutex
+}
+
+// readFontSource reads the TTF/OTF of the face from the asset database, or
+// from the fonts installed with the operating system for faces that have
+// system fonts (see [FontCJK])
+func readFontSource(face FontFace, adb assets.Database) (*sfnt.Font, fontGPOSKerning) {
+	defer tracing.NewRegion("rendering.readFontSource").End()
+	for _, ext := range fontSourceExtensions {
+		key := face.string() + ext
+		if !adb.Exists(key) {
+			continue
+		}
+		data, err := adb.Read(key)
+		if err != nil {
+			slog.Error("failed to read the font source", "font", key, "error", err)
+			continue
+		}
+		f, err := sfnt.Parse(data)
+		if err != nil {
+			slog.Error("failed to parse the font source", "font", key, "error", err)
+			continue
+		}
+		return f, readFontGPOSKerning(data, 0)
+	}
+	return readSystemFontSource(face)
+}
+
+func newDynamicFont(key string, f *sfnt.Font) *dynamicFont {
+	defer tracing.NewRegion("rendering.newDynamicFont").End()
+	d := &dynamicFont{
+		key:     key,
+		font:    f,
+		glyphs:  make(map[rune]*dynamicGlyph),
+		missing: make(map[rune]bool),
+		kerning: make(map[fontKernPair]float32),
+	}
+	cellWidth, cellHeight := 1.0, 1.0
+	if b, err := f.Bounds(&d.buffer, fixed.I(msdfLoadPPEM), font.HintingNone); err == nil {
+		cellWidth = d.toEm(b.Max.X - b.Min.X)
+		cellHeight = d.toEm(b.Max.Y - b.Min.Y)
+	}
+	size := func(em float64) int {
+		px := int(math.Ceil(em*dynamicGlyphPxPerEm+distanceFieldRange)) + 1
+		return max(1, min(glyphAtlasPageSize, px))
+	}
+	d.cellWidth = size(cellWidth)
+	d.cellHeight = size(cellHeight)
+	d.columns = glyphAtlasPageSize / d.cellWidth
+	return d
+}
+
+func (d *dynamicFont) toEm(v fixed.Int26_6) float64 {
+	return float64(v) / (64 * msdfLoadPPEM)
+}
+
+// metrics reads the metrics of the font in em units, these are used for faces
+// that don't have a baked atlas at all
+func (d *dynamicFont) metrics() fontBinMetrics {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	m := fontBinMetrics{EMSize: 1, LineHeight: 1}
+	if fm, err := d.font.Metrics(&d.buffer, fixed.I(msdfLoadPPEM), font.HintingNone); err == nil {
+		m.LineHeight = float32(d.toEm(fm.Height))
+		m.Ascender = float32(d.toEm(fm.Ascent))
+		m.Descender = -float32(d.toEm(fm.Descent))
+	}
+	if post := d.font.PostTable(); post != nil {
+		upem := float32(d.font.UnitsPerEm())
+		m.UnderlineY = float32(post.UnderlinePosition) / upem
+		m.UnderlineThickness = float32(post.UnderlineThickness) / upem
+	}
+	return m
+}
+
+func (d *dynamicFont) cellsPerPage() int {
+	return d.columns * (glyphAtlasPageSize / d.cellHeight)
+}
+
+// glyph returns the glyph for the rune, rasterizing it into the atlas if this
+// is the first time it is used. Glyphs used within the same layout pass, which
+// is the frame, are never evicted to make room for another. False is returned
+// if the font doesn't have the glyph or there is no room left for it.
+func (d *dynamicFont) glyph(r rune, frame uint64) (fontBinChar, bool) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if g, ok := d.glyphs[r]; ok {
+		g.lastUse = frame
+		d.lru.MoveToFront(g.element)
+		return g.char, true
+	}
+	if d.missing[r] {
+		return fontBinChar{}, false
+	}
+	defer tracing.NewRegion("dynamicFont.glyph").End()
+	source := r
+	if r == '\t' || r == '\r' {
+		// Fonts rarely have glyphs for these, they are drawn as blank space
+		source = ' '
+	}
+	idx, err := d.font.GlyphIndex(&d.buffer, source)
+	if err != nil || idx == 0 {
+		d.missing[r] = true
+		return fontBinChar{}, false
+	}
+	ppem := fixed.I(msdfLoadPPEM)
+	advance, err := d.font.GlyphAdvance(&d.buffer, idx, ppem, font.HintingNone)
+	if err != nil {
+		d.missing[r] = true
+		return fontBinChar{}, false
+	}
+	segments, err := d.font.LoadGlyph(&d.buffer, idx, ppem, nil)
+	if err != nil {
+		if !errors.Is(err, sfnt.ErrColoredGlyph) {
+			slog.Warn("failed to load the font glyph", "font", d.key, "rune", r, "error", err)
+		}
+		d.missing[r] = true
+		return fontBinChar{}, false
+	}
+	page, cell, ok := d.allocate(frame)
+	if !ok {
+		return fontBinChar{}, false
+	}
+	g := &dynamicGlyph{page: page, cell: cell, lastUse: frame}
+	g.char = d.rasterize(msdfShapeFromSegments(segments), page, cell)
+	g.char.letter = r
+	g.char.advance = float32(d.toEm(advance))
+	switch r {
+	case '\t':
+		g.char.advance *= 4
+	case '\r':
+		g.char.advance = 0
+	}
+	g.char.page = page
+	g.element = d.lru.PushFront(g)
+	d.glyphs[r] = g
+	return g.char, true
+}
+
+// allocate finds a free cell for a new glyph, creating a new page when the
+// existing ones are full and evicting the least recently used glyph once the
+// maximum number of pages has been reached
+func (d *dynamicFont) allocate(frame uint64) (*glyphAtlasPage, int, bool) {
+	for _, p := range d.pages {
+		if n := len(p.free); n > 0 {
+			cell := p.free[n-1]
+			p.free = p.free[:n-1]
+			return p, cell, true
+		}
+	}
+	if len(d.pages) < glyphAtlasMaxPages {
+		p := d.newPage()
+		cell := p.free[len(p.free)-1]
+		p.free = p.free[:len(p.free)-1]
+		return p, cell, true
+	}
+	back := d.lru.Back()
+	if back == nil {
+		return nil, 0, false
+	}
+	g := back.Value.(*dynamicGlyph)
+	if g.lastUse == frame {
+		// Every glyph in the atlas is being used by the current text
+		return nil, 0, false
+	}
+	d.lru.Remove(back)
+	delete(d.glyphs, g.char.letter)
+	if d.evicted != nil {
+		d.evicted(g.char.letter)
+	}
+	return g.page, g.cell, true
+}
+
+func (d *dynamicFont) newPage() *glyphAtlasPage {
+	defer tracing.NewRegion("dynamicFont.newPage").End()
+	count := d.cellsPerPage()
+	p := &glyphAtlasPage{
+		key:    fmt.Sprintf("%s_glyphs_%d", d.key, len(d.pages)),
+		pixels: make([]byte, glyphAtlasPageSize*glyphAtlasPageSize*4),
+		free:   make([]int, count),
+		dirty:  make(map[int]bool),
+	}
+	// Cells are taken from the back, so the first cell is at the end
+	for i := range p.free {
+		p.free[i] = count - 1 - i
+	}
+	if d.createTexture != nil {
+		p.texture = d.createTexture(p.key, p.pixels, glyphAtlasPageSize)
+	}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// cellOrigin is the left and top pixel of the cell, the top is from the top
+// row of the page
+func (d *dynamicFont) cellOrigin(cell int) (x, y int) {
+	return (cell % d.columns) * d.cellWidth, (cell / d.columns) * d.cellHeight
+}
+
+// rasterize generates the MSDF of the shape into the top left of the cell and
+// returns the glyph with the bounds of where it was placed
+func (d *dynamicFont) rasterize(shape msdfShape, page *glyphAtlasPage, cell int) fontBinChar {
+	defer tracing.NewRegion("dynamicFont.rasterize").End()
+	cx, cy := d.cellOrigin(cell)
+	const stride = glyphAtlasPageSize * 4
+	for y := cy; y < cy+d.cellHeight; y++ {
+		clear(page.pixels[y*stride+cx*4 : y*stride+(cx+d.cellWidth)*4])
+	}
+	page.dirty[cell] = true
+	var c fontBinChar
+	if shape.isEmpty() {
+		return c
+	}
+	shape.colorEdges()
+	const pxPerEm = dynamicGlyphPxPerEm
+	const halfRange = distanceFieldRange * 0.5 / pxPerEm
+	l, b, r, t := shape.bounds()
+	origin := msdfPoint{l - halfRange, b - halfRange}
+	w := min(d.cellWidth, int(math.Ceil((r-l)*pxPerEm+distanceFieldRange)))
+	h := min(d.cellHeight, int(math.Ceil((t-b)*pxPerEm+distanceFieldRange)))
+	shape.generate(page.pixels[cy*stride+cx*4:], stride, w, h, origin,
+		pxPerEm, distanceFieldRange)
+	// The bounds are inset by half a pixel to match the offline atlas, the
+	// atlas bounds are in pixels from the bottom of the page
+	c.planeBounds = [4]float32{
+		float32(origin.x + 0.5/pxPerEm),
+		float32(origin.y + (float64(h)-0.5)/pxPerEm),
+		float32(origin.x + (float64(w)-0.5)/pxPerEm),
+		float32(origin.y + 0.5/pxPerEm),
+	}
+	top := float32(glyphAtlasPageSize - cy)
+	c.atlasBounds = [4]float32{
+		float32(cx) + 0.5,
+		top - 0.5,
+		float32(cx+w) - 0.5,
+		top - float32(h) + 0.5,
+	}
+	return c
+}
+
+// kern is the kerning between the two runes in em units
+func (d *dynamicFont) kern(left, right rune) float32 {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	pair := fontKernPair{left, right}
+	if k, ok := d.kerning[pair]; ok {
+		return k
+	}
+	k := sourceKern(d.font, &d.buffer, &d.gpos, left, right)
+	d.kerning[pair] = k
+	return k
+}
+
+// flush writes the cells that have changed since the last flush to the page
+// textures. Pages whose texture hasn't been uploaded yet are kept dirty, the
+// upload already reads the glyphs from the shared pixels.
+func (d *dynamicFont) flush(write func(texture *Texture, requests []GPUImageWriteRequest)) {
+	defer tracing.NewRegion("dynamicFont.flush").End()
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	const stride = glyphAtlasPageSize * 4
+	for _, p := range d.pages {
+		if len(p.dirty) == 0 || p.texture == nil || !p.texture.RenderId.IsValid() {
+			continue
+		}
+		requests := make([]GPUImageWriteRequest, 0, len(p.dirty))
+		for cell := range p.dirty {
+			cx, cy := d.cellOrigin(cell)
+			pixels := make([]byte, 0, d.cellWidth*d.cellHeight*4)
+			for y := cy; y < cy+d.cellHeight; y++ {
+				pixels = append(pixels, p.pixels[y*stride+cx*4:y*stride+(cx+d.cellWidth)*4]...)
+			}
+			requests = append(requests, GPUImageWriteRequest{
+				Region: matrix.Vec4i{int32(cx), int32(cy), int32(d.cellWidth), int32(d.cellHeight)},
+				Pixels: pixels,
+			})
+		}
+		clear(p.dirty)
+		write(p.texture, requests)
+	}
+}
@@ -0,0 +1,133 @@
+/******************************************************************************/
+/* font_dynamic_test.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+func testDynamicFont(t *testing.T) *dynamicFont {
+	t.Helper()
+	f, err := sfnt.Parse(goregular.TTF)
+	if err != nil {
+		t.Fatalf("failed to parse the test font: %v", err)
+	}
+	return newDynamicFont("goregular", f)
+}
+
+// testGlyphPixel reads the median of the channels at the pixel, which is above
+// 0.5 when the pixel is inside of the glyph
+func testGlyphPixel(page *glyphAtlasPage, x, y int) byte {
+	px := page.pixels[(y*glyphAtlasPageSize+x)*4:]
+	r, g, b := px[0], px[1], px[2]
+	return max(min(r, g), min(max(r, g), b))
+}
+
+func TestDynamicFontRasterizesGlyph(t *testing.T) {
+	d := testDynamicFont(t)
+	c, ok := d.glyph('I', 1)
+	if !ok {
+		t.Fatal("expected the glyph to be rasterized")
+	}
+	if c.letter != 'I' || c.page == nil || c.advance <= 0 {
+		t.Fatalf("unexpected glyph %+v", c)
+	}
+	if c.Width() <= 0 || c.Height() >= 0 {
+		t.Fatalf("expected the plane bounds to be left, top, right, bottom, got %v", c.planeBounds)
+	}
+	if len(c.page.dirty) != 1 {
+		t.Fatalf("expected the glyph cell to be dirty, got %d dirty cells", len(c.page.dirty))
+	}
+	// The atlas bounds are from the bottom of the page, the middle of the bar
+	// is inside of the glyph and the corner of its box is outside of it
+	left := int(c.atlasBounds[0])
+	top := glyphAtlasPageSize - int(c.atlasBounds[1]) - 1
+	right := int(c.atlasBounds[2])
+	bottom := glyphAtlasPageSize - int(c.atlasBounds[3]) - 1
+	if v := testGlyphPixel(c.page, (left+right)/2, (top+bottom)/2); v <= 127 {
+		t.Errorf("expected the middle of the glyph to be inside, got %d", v)
+	}
+	if v := testGlyphPixel(c.page, left, top); v >= 127 {
+		t.Errorf("expected the corner of the glyph to be outside, got %d", v)
+	}
+	again, _ := d.glyph('I', 2)
+	if again.page != c.page || again.atlasBounds != c.atlasBounds {
+		t.Error("expected the same glyph to be reused")
+	}
+}
+
+func TestDynamicFontMissingGlyph(t *testing.T) {
+	d := testDynamicFont(t)
+	if _, ok := d.glyph(0x10FFFD, 1); ok {
+		t.Error("expected a glyph the font doesn't have to be missing")
+	}
+	if len(d.pages) != 0 {
+		t.Error("expected no page to be created for a missing glyph")
+	}
+}
+
+func TestDynamicFontEvictsLeastRecentlyUsed(t *testing.T) {
+	d := testDynamicFont(t)
+	for range glyphAtlasMaxPages {
+		d.newPage().free = nil
+	}
+	for i, r := range []rune{'x', 'y', 'z'} {
+		g := &dynamicGlyph{char: fontBinChar{letter: r}, page: d.pages[0], cell: i, lastUse: 1}
+		g.element = d.lru.PushFront(g)
+		d.glyphs[r] = g
+	}
+	// y was used last, x is now the least recently used
+	d.glyph('x', 2)
+	d.glyph('y', 3)
+	var evicted []rune
+	d.evicted = func(r rune) { evicted = append(evicted, r) }
+	c, ok := d.glyph('A', 4)
+	if !ok {
+		t.Fatal("expected a glyph to be evicted to make room")
+	}
+	if len(evicted) != 1 || evicted[0] != 'z' {
+		t.Fatalf("expected z to be evicted, got %q", evicted)
+	}
+	if _, ok := d.glyphs['z']; ok {
+		t.Error("expected the evicted glyph to be removed")
+	}
+	if c.page != d.pages[0] || d.glyphs['A'].cell != 2 {
+		t.Error("expected the new glyph to take the cell of the evicted one")
+	}
+}
+
+func TestDynamicFontKeepsGlyphsOfCurrentLayout(t *testing.T) {
+	d := testDynamicFont(t)
+	for range glyphAtlasMaxPages {
+		d.newPage().free = nil
+	}
+	g := &dynamicGlyph{char: fontBinChar{letter: 'x'}, page: d.pages[0], lastUse: 5}
+	g.element = d.lru.PushFront(g)
+	d.glyphs['x'] = g
+	if _, ok := d.glyph('A', 5); ok {
+		t.Error("expected no room when every glyph is used by the current layout")
+	}
+	if _, ok := d.glyphs['x']; !ok {
+		t.Error("expected the glyph in use to be kept")
+	}
+}
+
+func TestShaperRasterizesMissingGlyphs(t *testing.T) {
+	bin := testFontBin('a')
+	bin.dynamic = testDynamicFont(t)
+	s := textShaper{faces: []fontBin{bin}, frame: 1}
+	glyphs := s.shape([]rune("aé"), nil, 1)
+	if glyphs[0].char.page != nil {
+		t.Error("expected the baked glyph to be used when it is in the atlas")
+	}
+	if glyphs[1].char.letter != 'é' || glyphs[1].char.page == nil {
+		t.Errorf("expected the missing glyph to be rasterized, got %+v", glyphs[1].char)
+	}
+}
@@ -22,6 +22,11 @@ const (
 	FontSemiBold        = FontFace("OpenSans-SemiBold")
 	FontSemiBoldItalic  = FontFace("OpenSans-SemiBoldItalic")
 	FontMono            = FontFace("JetBrainsMono-Regular")
+	FontEmoji           = FontFace("NotoEmoji-Regular")
+	// FontCJK is the fallback face for Chinese, Japanese and Korean text. It
+	// is read from the project's fonts when it has a NotoSansCJK-Regular font,
+	// otherwise the CJK font installed with the operating system is used
+	FontCJK = FontFace("NotoSansCJK-Regular")
 )
 
 func (f FontFace) IsBold() bool {
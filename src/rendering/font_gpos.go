@@ -0,0 +1,287 @@
+/******************************************************************************/
+/* font_gpos.go                                                               */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"math/bits"
+	"slices"
+	"sort"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	gposLookupPairAdjustment = 2
+	gposLookupExtension      = 9
+	gposValueXPlacement      = 0x0001
+	gposValueYPlacement      = 0x0002
+	gposValueXAdvance        = 0x0004
+)
+
+// fontGPOSKerning is the kerning of the "kern" feature in the GPOS table of a
+// font. Most fonts only keep their kerning here and not in the older kern
+// table, which is the only one that sfnt reads.
+type fontGPOSKerning struct {
+	table []byte
+	// lookups holds the offsets of the pair adjustment subtables of each of
+	// the lookups of the feature, the adjustments of the lookups add together
+	lookups [][]int
+}
+
+// FontKerning is the kerning between two letters in em units
+type FontKerning struct {
+	Left    rune
+	Right   rune
+	Advance float32
+}
+
+func gposU16(b []byte, offset int) int {
+	if offset < 0 || offset+2 > len(b) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint16(b[offset:]))
+}
+
+func gposU32(b []byte, offset int) int {
+	if offset < 0 || offset+4 > len(b) {
+		return 0
+	}
+	return int(binary.BigEndian.Uint32(b[offset:]))
+}
+
+// fontTable finds the table of the font in the data of a TTF/OTF file, index
+// selects the font when the file is a collection
+func fontTable(data []byte, index int, tag string) []byte {
+	start := 0
+	if len(data) >= 4 && string(data[:4]) == "ttcf" {
+		if index < 0 || index >= gposU32(data, 8) {
+			return nil
+		}
+		start = gposU32(data, 12+index*4)
+	}
+	count := gposU16(data, start+4)
+	for i := range count {
+		record := start + 12 + i*16
+		if record+16 > len(data) || string(data[record:record+4]) != tag {
+			continue
+		}
+		offset := gposU32(data, record+8)
+		length := gposU32(data, record+12)
+		if offset+length > len(data) {
+			return nil
+		}
+		return data[offset : offset+length]
+	}
+	return nil
+}
+
+// readFontGPOSKerning finds the pair adjustment lookups of every "kern"
+// feature in the GPOS table, the result is empty if the font has none
+func readFontGPOSKerning(data []byte, index int) fontGPOSKerning {
+	k := fontGPOSKerning{table: fontTable(data, index, "GPOS")}
+	if len(k.table) < 10 || gposU16(k.table, 0) != 1 {
+		return fontGPOSKerning{}
+	}
+	featureList := gposU16(k.table, 6)
+	lookupList := gposU16(k.table, 8)
+	var lookupIndices []int
+	for i := range gposU16(k.table, featureList) {
+		record := featureList + 2 + i*6
+		if record+6 > len(k.table) || string(k.table[record:record+4]) != "kern" {
+			continue
+		}
+		feature := featureList + gposU16(k.table, record+4)
+		for j := range gposU16(k.table, feature+2) {
+			lookupIndices = append(lookupIndices, gposU16(k.table, feature+4+j*2))
+		}
+	}
+	slices.Sort(lookupIndices)
+	lookupIndices = slices.Compact(lookupIndices)
+	lookupCount := gposU16(k.table, lookupList)
+	for _, li := range lookupIndices {
+		if li >= lookupCount {
+			continue
+		}
+		lookup := lookupList + gposU16(k.table, lookupList+2+li*2)
+		lookupType := gposU16(k.table, lookup)
+		var subtables []int
+		for i := range gposU16(k.table, lookup+4) {
+			sub := lookup + gposU16(k.table, lookup+6+i*2)
+			switch lookupType {
+			case gposLookupPairAdjustment:
+				subtables = append(subtables, sub)
+			case gposLookupExtension:
+				if gposU16(k.table, sub+2) == gposLookupPairAdjustment {
+					subtables = append(subtables, sub+gposU32(k.table, sub+4))
+				}
+			}
+		}
+		if len(subtables) > 0 {
+			k.lookups = append(k.lookups, subtables)
+		}
+	}
+	if len(k.lookups) == 0 {
+		return fontGPOSKerning{}
+	}
+	return k
+}
+
+func (k *fontGPOSKerning) valid() bool { return len(k.lookups) > 0 }
+
+// coverage is the index of the glyph in the coverage table
+func (k *fontGPOSKerning) coverage(offset int, glyph int) (int, bool) {
+	b := k.table
+	switch gposU16(b, offset) {
+	case 1:
+		count := gposU16(b, offset+2)
+		i := sort.Search(count, func(i int) bool { return gposU16(b, offset+4+i*2) >= glyph })
+		return i, i < count && gposU16(b, offset+4+i*2) == glyph
+	case 2:
+		count := gposU16(b, offset+2)
+		i := sort.Search(count, func(i int) bool { return gposU16(b, offset+4+i*6+2) >= glyph })
+		if i < count {
+			r := offset + 4 + i*6
+			if start := gposU16(b, r); glyph >= start {
+				return gposU16(b, r+4) + glyph - start, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// class is the class of the glyph in the class definition table, glyphs that
+// aren't in the table are class 0
+func (k *fontGPOSKerning) class(offset int, glyph int) int {
+	b := k.table
+	switch gposU16(b, offset) {
+	case 1:
+		start := gposU16(b, offset+2)
+		if glyph >= start && glyph-start < gposU16(b, offset+4) {
+			return gposU16(b, offset+6+(glyph-start)*2)
+		}
+	case 2:
+		count := gposU16(b, offset+2)
+		i := sort.Search(count, func(i int) bool { return gposU16(b, offset+4+i*6+2) >= glyph })
+		if i < count {
+			r := offset + 4 + i*6
+			if glyph >= gposU16(b, r) {
+				return gposU16(b, r+4)
+			}
+		}
+	}
+	return 0
+}
+
+func gposValueSize(format int) int {
+	return bits.OnesCount16(uint16(format&0xFF)) * 2
+}
+
+// xAdvance reads the horizontal advance out of a value record
+func (k *fontGPOSKerning) xAdvance(offset, format int) int {
+	if format&gposValueXAdvance == 0 {
+		return 0
+	}
+	skip := gposValueSize(format & (gposValueXPlacement | gposValueYPlacement))
+	return int(int16(gposU16(k.table, offset+skip)))
+}
+
+// pairAdjustment is the change to the advance of the left glyph from a single
+// subtable, found is false if the subtable doesn't have the pair
+func (k *fontGPOSKerning) pairAdjustment(sub, left, right int) (advance int, found bool) {
+	b := k.table
+	leftIndex, ok := k.coverage(sub+gposU16(b, sub+2), left)
+	if !ok {
+		return 0, false
+	}
+	format1 := gposU16(b, sub+4)
+	format2 := gposU16(b, sub+6)
+	size1 := gposValueSize(format1)
+	switch gposU16(b, sub) {
+	case 1:
+		if leftIndex >= gposU16(b, sub+8) {
+			return 0, false
+		}
+		pairSet := sub + gposU16(b, sub+10+leftIndex*2)
+		recordSize := 2 + size1 + gposValueSize(format2)
+		count := gposU16(b, pairSet)
+		i := sort.Search(count, func(i int) bool {
+			return gposU16(b, pairSet+2+i*recordSize) >= right
+		})
+		if i < count && gposU16(b, pairSet+2+i*recordSize) == right {
+			return k.xAdvance(pairSet+2+i*recordSize+2, format1), true
+		}
+	case 2:
+		class1 := k.class(sub+gposU16(b, sub+8), left)
+		class2 := k.class(sub+gposU16(b, sub+10), right)
+		class1Count := gposU16(b, sub+12)
+		class2Count := gposU16(b, sub+14)
+		if class1 >= class1Count || class2 >= class2Count {
+			return 0, false
+		}
+		record := sub + 16 + (class1*class2Count+class2)*(size1+gposValueSize(format2))
+		return k.xAdvance(record, format1), true
+	}
+	return 0, false
+}
+
+// kern is the kerning between the glyphs in font units
+func (k *fontGPOSKerning) kern(left, right sfnt.GlyphIndex) (int, bool) {
+	total, found := 0, false
+	for _, lookup := range k.lookups {
+		for _, sub := range lookup {
+			if v, ok := k.pairAdjustment(sub, int(left), int(right)); ok {
+				total += v
+				found = true
+				break
+			}
+		}
+	}
+	return total, found
+}
+
+// sourceKern is the kerning between the runes in em units, read from the GPOS
+// table when the font has one and otherwise from the kern table
+func sourceKern(f *sfnt.Font, buffer *sfnt.Buffer, gpos *fontGPOSKerning, left, right rune) float32 {
+	l, lErr := f.GlyphIndex(buffer, left)
+	r, rErr := f.GlyphIndex(buffer, right)
+	if lErr != nil || rErr != nil || l == 0 || r == 0 {
+		return 0
+	}
+	upem := float32(f.UnitsPerEm())
+	if gpos.valid() {
+		v, _ := gpos.kern(l, r)
+		return float32(v) / upem
+	}
+	ppem := fixed.I(int(f.UnitsPerEm()))
+	if v, err := f.Kern(buffer, l, r, ppem, font.HintingNone); err == nil {
+		return float32(v) / 64 / upem
+	}
+	return 0
+}
+
+// FontSourceKerning reads the kerning between every pair of the runes out of
+// the data of a TTF/OTF font. Pairs that aren't kerned are left out.
+func FontSourceKerning(data []byte, runes []rune) ([]FontKerning, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	var buffer sfnt.Buffer
+	gpos := readFontGPOSKerning(data, 0)
+	var kerning []FontKerning
+	for _, left := range runes {
+		for _, right := range runes {
+			if k := sourceKern(f, &buffer, &gpos, left, right); k != 0 {
+				kerning = append(kerning, FontKerning{left, right, k})
+			}
+		}
+	}
+	return kerning, nil
+}
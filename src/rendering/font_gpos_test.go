@@ -0,0 +1,138 @@
+/******************************************************************************/
+/* font_gpos_test.go                                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+)
+
+// testGPOSTable is a GPOS table with a "kern" feature that uses two lookups,
+// a pair adjustment of glyphs 1 and 2 (format 1) and an extension lookup that
+// wraps a class based pair adjustment (format 2)
+var testGPOSTable = []uint16{
+	// Header, the script list isn't read
+	1, 0, 0, 10, 26,
+	// Feature list with a single "kern" feature that uses lookups 0 and 1
+	1, 'k'<<8 | 'e', 'r'<<8 | 'n', 8,
+	0, 2, 0, 1,
+	// Lookup list
+	2, 6, 38,
+	// Lookup 0, pair adjustment
+	2, 0, 1, 8,
+	1, 12, gposValueXAdvance, 0, 1, 18,
+	1, 1, 1,
+	1, 2, 0xFFCE, // -50
+	// Lookup 1, extension of a class pair adjustment
+	9, 0, 1, 8,
+	1, 2, 0, 8,
+	2, 24, gposValueXAdvance, 0, 34, 42, 2, 2,
+	0, 0xFFEC, // -20
+	0, 0xFFE2, // -30
+	2, 1, 1, 3, 0,
+	1, 3, 1, 1,
+	2, 1, 2, 2, 1,
+}
+
+// testGPOSFont wraps the GPOS table in a font file that only has that table
+func testGPOSFont(collection bool) []byte {
+	table := make([]byte, len(testGPOSTable)*2)
+	for i, v := range testGPOSTable {
+		binary.BigEndian.PutUint16(table[i*2:], v)
+	}
+	start := 0
+	var data []byte
+	if collection {
+		data = append(data, "ttcf"...)
+		data = binary.BigEndian.AppendUint32(data, 0x00010000)
+		data = binary.BigEndian.AppendUint32(data, 1)
+		data = binary.BigEndian.AppendUint32(data, 16)
+		start = len(data)
+	}
+	data = binary.BigEndian.AppendUint32(data, 0x00010000)
+	data = binary.BigEndian.AppendUint16(data, 1)
+	data = append(data, make([]byte, 6)...)
+	data = append(data, "GPOS"...)
+	data = binary.BigEndian.AppendUint32(data, 0)
+	data = binary.BigEndian.AppendUint32(data, uint32(start+28))
+	data = binary.BigEndian.AppendUint32(data, uint32(len(table)))
+	return append(data, table...)
+}
+
+func TestFontGPOSKerning(t *testing.T) {
+	for _, collection := range []bool{false, true} {
+		k := readFontGPOSKerning(testGPOSFont(collection), 0)
+		if !k.valid() {
+			t.Fatalf("expected the kern lookups to be read, collection %v", collection)
+		}
+		tests := []struct {
+			left, right sfnt.GlyphIndex
+			want        int
+			found       bool
+		}{
+			{1, 2, -70, true},
+			{3, 2, -30, true},
+			{2, 1, 0, true},
+			{4, 2, 0, false},
+		}
+		for _, test := range tests {
+			got, found := k.kern(test.left, test.right)
+			if got != test.want || found != test.found {
+				t.Errorf("kern(%d, %d) = %d, %v, want %d, %v", test.left, test.right,
+					got, found, test.want, test.found)
+			}
+		}
+	}
+}
+
+func TestFontGPOSKerningWithoutTable(t *testing.T) {
+	if k := readFontGPOSKerning(goregular.TTF, 0); k.valid() {
+		t.Error("expected a font without a GPOS table to have no GPOS kerning")
+	}
+	if k := readFontGPOSKerning(testGPOSFont(true), 1); k.valid() {
+		t.Error("expected an index outside of the collection to have no GPOS kerning")
+	}
+}
+
+func TestFontSourceKerningWithoutKerning(t *testing.T) {
+	// The Go fonts have neither a kern table nor a GPOS table
+	kerning, err := FontSourceKerning(goregular.TTF, []rune("AVTo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kerning) != 0 {
+		t.Errorf("expected no kerning, got %v", kerning)
+	}
+	if _, err := FontSourceKerning([]byte("not a font"), []rune("AV")); err == nil {
+		t.Error("expected an error for data that isn't a font")
+	}
+}
+
+func TestBundledFontIsKerned(t *testing.T) {
+	data, err := os.ReadFile("../editor/editor_embedded_content/editor_content/fonts/OpenSans-Regular.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bin fontBin
+	parseFontBin(data, &bin)
+	if len(bin.letters) == 0 {
+		t.Fatal("expected the font to have glyphs")
+	}
+	for _, pair := range []string{"AV", "To", "Ty", "LT"} {
+		r := []rune(pair)
+		if k := bin.kern(r[0], r[1]); k >= 0 {
+			t.Errorf("expected %q to be kerned closer, got %v", pair, k)
+		}
+	}
+	if k := bin.kern('o', 'o'); k != 0 {
+		t.Errorf("expected \"oo\" to not be kerned, got %v", k)
+	}
+}
@@ -0,0 +1,345 @@
+/******************************************************************************/
+/* font_msdf.go                                                               */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"math"
+
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	msdfRed   = 1
+	msdfGreen = 2
+	msdfBlue  = 4
+	msdfWhite = msdfRed | msdfGreen | msdfBlue
+	// msdfCornerSin is the sine of the angle, in radians, between two edges
+	// that makes the point they meet at a corner, sin(3) is the threshold
+	// msdfgen uses by default
+	msdfCornerSin = 0.14112000806
+	msdfQuadSteps = 8
+	msdfCubeSteps = 12
+	// msdfLoadPPEM is the pixels per em glyph outlines are loaded at, it is
+	// only used for the precision of the loaded points which are then turned
+	// back into em units
+	msdfLoadPPEM = 1024
+)
+
+type msdfPoint struct{ x, y float64 }
+
+func (a msdfPoint) sub(b msdfPoint) msdfPoint { return msdfPoint{a.x - b.x, a.y - b.y} }
+func (a msdfPoint) dot(b msdfPoint) float64   { return a.x*b.x + a.y*b.y }
+func (a msdfPoint) cross(b msdfPoint) float64 { return a.x*b.y - a.y*b.x }
+func (a msdfPoint) length() float64           { return math.Sqrt(a.dot(a)) }
+func (a msdfPoint) scale(s float64) msdfPoint { return msdfPoint{a.x * s, a.y * s} }
+func (a msdfPoint) add(b msdfPoint) msdfPoint { return msdfPoint{a.x + b.x, a.y + b.y} }
+func (a msdfPoint) equals(b msdfPoint) bool   { return a.x == b.x && a.y == b.y }
+func (a msdfPoint) lerp(b msdfPoint, t float64) msdfPoint {
+	return msdfPoint{a.x + (b.x-a.x)*t, a.y + (b.y-a.y)*t}
+}
+
+func (a msdfPoint) normalized() msdfPoint {
+	if l := a.length(); l > 0 {
+		return a.scale(1 / l)
+	}
+	return msdfPoint{}
+}
+
+// msdfEdge is a single line or curve of a glyph outline, curves are flattened
+// into a polyline. The color is the set of channels the edge is drawn into.
+type msdfEdge struct {
+	points []msdfPoint
+	color  uint8
+}
+
+func (e *msdfEdge) startDirection() msdfPoint { return e.points[1].sub(e.points[0]).normalized() }
+
+func (e *msdfEdge) endDirection() msdfPoint {
+	n := len(e.points)
+	return e.points[n-1].sub(e.points[n-2]).normalized()
+}
+
+// msdfShape is the outline of a glyph in em units with Y up
+type msdfShape struct {
+	contours [][]msdfEdge
+}
+
+// msdfShapeFromSegments converts the outline loaded from a font, which has Y
+// going down, into a shape in em units with Y up
+func msdfShapeFromSegments(segments sfnt.Segments) msdfShape {
+	toEm := func(p fixed.Point26_6) msdfPoint {
+		return msdfPoint{float64(p.X) / (64 * msdfLoadPPEM), -float64(p.Y) / (64 * msdfLoadPPEM)}
+	}
+	var shape msdfShape
+	var contour []msdfEdge
+	var start, pen msdfPoint
+	addEdge := func(points ...msdfPoint) {
+		if !points[0].equals(points[len(points)-1]) || len(points) > 2 {
+			contour = append(contour, msdfEdge{points: points, color: msdfWhite})
+		}
+	}
+	closeContour := func() {
+		if len(contour) > 0 && !pen.equals(start) {
+			addEdge(pen, start)
+		}
+		if len(contour) > 0 {
+			shape.contours = append(shape.contours, contour)
+		}
+		contour = nil
+	}
+	for _, s := range segments {
+		switch s.Op {
+		case sfnt.SegmentOpMoveTo:
+			closeContour()
+			start = toEm(s.Args[0])
+			pen = start
+		case sfnt.SegmentOpLineTo:
+			p := toEm(s.Args[0])
+			addEdge(pen, p)
+			pen = p
+		case sfnt.SegmentOpQuadTo:
+			c, p := toEm(s.Args[0]), toEm(s.Args[1])
+			points := make([]msdfPoint, 0, msdfQuadSteps+1)
+			for i := 0; i <= msdfQuadSteps; i++ {
+				t := float64(i) / msdfQuadSteps
+				points = append(points, pen.lerp(c, t).lerp(c.lerp(p, t), t))
+			}
+			addEdge(points...)
+			pen = p
+		case sfnt.SegmentOpCubeTo:
+			c0, c1, p := toEm(s.Args[0]), toEm(s.Args[1]), toEm(s.Args[2])
+			points := make([]msdfPoint, 0, msdfCubeSteps+1)
+			for i := 0; i <= msdfCubeSteps; i++ {
+				t := float64(i) / msdfCubeSteps
+				a, b, c := pen.lerp(c0, t), c0.lerp(c1, t), c1.lerp(p, t)
+				points = append(points, a.lerp(b, t).lerp(b.lerp(c, t), t))
+			}
+			addEdge(points...)
+			pen = p
+		}
+	}
+	closeContour()
+	for i := range shape.contours {
+		for j := range shape.contours[i] {
+			shape.contours[i][j].points = msdfDedupe(shape.contours[i][j].points)
+		}
+	}
+	return shape
+}
+
+func msdfDedupe(points []msdfPoint) []msdfPoint {
+	out := points[:1]
+	for _, p := range points[1:] {
+		if !p.equals(out[len(out)-1]) {
+			out = append(out, p)
+		}
+	}
+	if len(out) == 1 {
+		// A degenerate edge, keep it as a zero length line so it has a direction
+		out = append(out, out[0])
+	}
+	return out
+}
+
+func (s *msdfShape) isEmpty() bool { return len(s.contours) == 0 }
+
+// bounds returns the left, bottom, right, and top of the shape
+func (s *msdfShape) bounds() (l, b, r, t float64) {
+	l, b = math.MaxFloat64, math.MaxFloat64
+	r, t = -math.MaxFloat64, -math.MaxFloat64
+	for i := range s.contours {
+		for j := range s.contours[i] {
+			for _, p := range s.contours[i][j].points {
+				l, b = min(l, p.x), min(b, p.y)
+				r, t = max(r, p.x), max(t, p.y)
+			}
+		}
+	}
+	return l, b, r, t
+}
+
+// orientation is 1 if the outer contours are clockwise, as they are in
+// TrueType fonts, or -1 if they are counter clockwise, as they are in CFF fonts
+func (s *msdfShape) orientation() float64 {
+	area := 0.0
+	for i := range s.contours {
+		for j := range s.contours[i] {
+			pts := s.contours[i][j].points
+			for k := 1; k < len(pts); k++ {
+				area += pts[k-1].cross(pts[k])
+			}
+		}
+	}
+	if area > 0 {
+		return -1
+	}
+	return 1
+}
+
+// colorEdges assigns the channels of each edge so that the edges on either
+// side of a corner never share all of their channels, this is what keeps the
+// corners sharp. Smooth contours are drawn into all channels.
+func (s *msdfShape) colorEdges() {
+	isCorner := func(a, b msdfPoint) bool {
+		return a.dot(b) <= 0 || math.Abs(a.cross(b)) > msdfCornerSin
+	}
+	for c := range s.contours {
+		edges := s.contours[c]
+		corners := make([]int, 0, len(edges))
+		for i := range edges {
+			prev := &edges[(i+len(edges)-1)%len(edges)]
+			if isCorner(prev.endDirection(), edges[i].startDirection()) {
+				corners = append(corners, i)
+			}
+		}
+		switch {
+		case len(corners) == 0:
+			for i := range edges {
+				edges[i].color = msdfWhite
+			}
+		case len(corners) == 1:
+			// A teardrop, the contour is split in three so the single corner
+			// still has different colors on either side
+			colors := [3]uint8{msdfRed | msdfBlue, msdfWhite, msdfRed | msdfGreen}
+			for i := range edges {
+				edges[(corners[0]+i)%len(edges)].color = colors[min(2, i*3/len(edges))]
+			}
+		default:
+			colors := [3]uint8{msdfGreen | msdfBlue, msdfRed | msdfBlue, msdfRed | msdfGreen}
+			for n := range corners {
+				color := colors[n%3]
+				if n == len(corners)-1 && n%3 == 0 {
+					// The last spline meets the first, they can't be the same
+					color = colors[1]
+				}
+				start, end := corners[n], corners[(n+1)%len(corners)]
+				for i := start; i != end; i = (i + 1) % len(edges) {
+					edges[i].color = color
+				}
+			}
+		}
+	}
+}
+
+// msdfDistance is the signed distance to an edge, dot is how parallel the
+// edge is to the direction to the point and is used to break ties between
+// edges that meet at the closest point
+type msdfDistance struct {
+	distance float64
+	dot      float64
+	// param is below 0 if the closest point is the start of the edge, above 1
+	// if it is the end, and otherwise between 0 and 1
+	param float64
+}
+
+func (a msdfDistance) closerThan(b msdfDistance) bool {
+	da, db := math.Abs(a.distance), math.Abs(b.distance)
+	if math.Abs(da-db) < 1e-12 {
+		return a.dot < b.dot
+	}
+	return da < db
+}
+
+func (e *msdfEdge) signedDistance(p msdfPoint) msdfDistance {
+	best := msdfDistance{distance: math.MaxFloat64, dot: 1}
+	last := len(e.points) - 2
+	for i := 0; i <= last; i++ {
+		a, b := e.points[i], e.points[i+1]
+		ab := b.sub(a)
+		aq := p.sub(a)
+		lenSq := ab.dot(ab)
+		t := 0.0
+		if lenSq > 0 {
+			t = aq.dot(ab) / lenSq
+		}
+		param := float64(i) + t
+		closest := a.lerp(b, max(0, min(1, t)))
+		eq := p.sub(closest)
+		dist := eq.length()
+		sign := 1.0
+		if aq.cross(ab) < 0 {
+			sign = -1
+		}
+		dot := 0.0
+		if (t < 0 || t > 1) && dist > 0 && lenSq > 0 {
+			dot = math.Abs(ab.normalized().dot(eq.scale(1 / dist)))
+		}
+		d := msdfDistance{distance: sign * dist, dot: dot, param: param / float64(last+1)}
+		if d.closerThan(best) {
+			best = d
+		}
+	}
+	return best
+}
+
+// pseudoDistance extends the ends of the edge into lines so that the
+// distance field stays sharp past corners
+func (e *msdfEdge) pseudoDistance(p msdfPoint, d msdfDistance) float64 {
+	if d.param < 0 {
+		dir := e.startDirection()
+		aq := p.sub(e.points[0])
+		if aq.dot(dir) < 0 {
+			if pd := aq.cross(dir); math.Abs(pd) <= math.Abs(d.distance) {
+				return pd
+			}
+		}
+	} else if d.param > 1 {
+		dir := e.endDirection()
+		bq := p.sub(e.points[len(e.points)-1])
+		if bq.dot(dir) > 0 {
+			if pd := bq.cross(dir); math.Abs(pd) <= math.Abs(d.distance) {
+				return pd
+			}
+		}
+	}
+	return d.distance
+}
+
+// generate writes the multi-channel signed distance field of the shape into
+// the RGBA pixels, which are stored top row first. Pixel x and y (from the
+// bottom) are at origin + (x + 0.5, y + 0.5) / pxPerEm in em units. The
+// distance is 0.5 on the outline and moves by 0.5 across the range in pixels.
+func (s *msdfShape) generate(pixels []byte, stride, width, height int, origin msdfPoint, pxPerEm, pxRange float64) {
+	sign := s.orientation()
+	emRange := pxRange / pxPerEm
+	type channel struct {
+		dist msdfDistance
+		edge *msdfEdge
+	}
+	for y := 0; y < height; y++ {
+		row := (height - 1 - y) * stride
+		for x := 0; x < width; x++ {
+			p := origin.add(msdfPoint{(float64(x) + 0.5) / pxPerEm, (float64(y) + 0.5) / pxPerEm})
+			var channels [3]channel
+			for i := range channels {
+				channels[i].dist = msdfDistance{distance: -math.MaxFloat64, dot: 1}
+			}
+			for c := range s.contours {
+				for e := range s.contours[c] {
+					edge := &s.contours[c][e]
+					d := edge.signedDistance(p)
+					for i := range channels {
+						if edge.color&(1<<i) != 0 && d.closerThan(channels[i].dist) {
+							channels[i] = channel{d, edge}
+						}
+					}
+				}
+			}
+			px := pixels[row+x*4 : row+x*4+4]
+			for i := range channels {
+				dist := 0.0
+				if channels[i].edge != nil {
+					dist = channels[i].edge.pseudoDistance(p, channels[i].dist)
+				}
+				v := sign*dist/emRange + 0.5
+				px[i] = byte(max(0, min(255, math.Round(v*255))))
+			}
+			px[3] = 255
+		}
+	}
+}
@@ -0,0 +1,85 @@
+/******************************************************************************/
+/* font_msdf_test.go                                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"testing"
+
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+func testMSDFSquare(clockwise bool) msdfShape {
+	pts := []msdfPoint{{0, 0}, {0, 1}, {1, 1}, {1, 0}}
+	if !clockwise {
+		pts = []msdfPoint{{0, 0}, {1, 0}, {1, 1}, {0, 1}}
+	}
+	var contour []msdfEdge
+	for i := range pts {
+		contour = append(contour, msdfEdge{
+			points: []msdfPoint{pts[i], pts[(i+1)%len(pts)]}, color: msdfWhite})
+	}
+	return msdfShape{contours: [][]msdfEdge{contour}}
+}
+
+func TestMSDFShapeFromSegments(t *testing.T) {
+	p := func(x, y float64) fixed.Point26_6 {
+		return fixed.Point26_6{X: fixed.Int26_6(x * 64 * msdfLoadPPEM), Y: fixed.Int26_6(y * 64 * msdfLoadPPEM)}
+	}
+	segments := sfnt.Segments{
+		{Op: sfnt.SegmentOpMoveTo, Args: [3]fixed.Point26_6{p(0, 0)}},
+		{Op: sfnt.SegmentOpLineTo, Args: [3]fixed.Point26_6{p(1, 0)}},
+		{Op: sfnt.SegmentOpQuadTo, Args: [3]fixed.Point26_6{p(1, -1), p(0, -1)}},
+	}
+	shape := msdfShapeFromSegments(segments)
+	if len(shape.contours) != 1 || len(shape.contours[0]) != 3 {
+		t.Fatalf("expected one closed contour of 3 edges, got %v", shape.contours)
+	}
+	l, b, r, top := shape.bounds()
+	if l != 0 || b != 0 || r <= 0.5 || top != 1 {
+		t.Errorf("expected the shape to be in em units with y up, got %v %v %v %v", l, b, r, top)
+	}
+}
+
+func TestMSDFColorEdgesCorners(t *testing.T) {
+	shape := testMSDFSquare(true)
+	shape.colorEdges()
+	edges := shape.contours[0]
+	for i := range edges {
+		next := edges[(i+1)%len(edges)]
+		if edges[i].color == next.color {
+			t.Errorf("expected the edges at corner %d to have different colors", i)
+		}
+		if edges[i].color&next.color == 0 {
+			t.Errorf("expected the edges at corner %d to share a channel", i)
+		}
+	}
+}
+
+func TestMSDFGenerateInsideOutside(t *testing.T) {
+	for _, clockwise := range []bool{true, false} {
+		shape := testMSDFSquare(clockwise)
+		shape.colorEdges()
+		const size = 8
+		pixels := make([]byte, size*size*4)
+		// The square fills the middle of the pixels with 2 pixels around it
+		shape.generate(pixels, size*4, size, size, msdfPoint{-0.5, -0.5}, 4, 2)
+		center := pixels[(4*size+4)*4:]
+		corner := pixels[0:]
+		for i := range 3 {
+			if center[i] <= 127 {
+				t.Errorf("clockwise %v: expected the center to be inside, got %v", clockwise, center[:4])
+			}
+			if corner[i] >= 127 {
+				t.Errorf("clockwise %v: expected the corner to be outside, got %v", clockwise, corner[:4])
+			}
+		}
+		if center[3] != 255 {
+			t.Errorf("expected the alpha to be opaque, got %d", center[3])
+		}
+	}
+}
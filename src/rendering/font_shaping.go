@@ -0,0 +1,305 @@
+/******************************************************************************/
+/* font_shaping.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"log/slog"
+	"slices"
+	"sync/atomic"
+	"unicode"
+
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// fontKernPair is a pair of letters, in the order they appear from left to
+// right, that have their spacing adjusted by the font
+type fontKernPair struct {
+	left, right rune
+}
+
+// shapedGlyph is a single glyph that is drawn for one or more runes of text,
+// more than one rune is drawn with the glyph when it is a ligature
+type shapedGlyph struct {
+	char fontBinChar
+	// face is the index of the face in [shapedText.faces] the glyph is from
+	face int
+	// cluster is the index of the first rune the glyph was shaped from
+	cluster int
+	runes   int
+	level   uint8
+	// advance is scaled and includes the kerning with the glyph to its right
+	advance float32
+	// x is the left of the glyph within its line, set when the line is laid out
+	x float32
+}
+
+type shapedLine struct {
+	// start and end are the range of glyphs in the line, in logical order
+	start, end int
+	width      float32
+}
+
+// shapedText is text that has been shaped into glyphs and broken into lines,
+// each glyph within a line is positioned in visual order
+type shapedText struct {
+	runes  []rune
+	glyphs []shapedGlyph
+	lines  []shapedLine
+	bases  []uint8
+	// faces is the face the text was shaped with followed by its fallbacks
+	faces []fontBin
+}
+
+type textShaper struct {
+	cache *FontCache
+	face  FontFace
+	faces []fontBin
+	// fallbacks are the fallback faces that haven't been loaded yet, they are
+	// loaded in order when a glyph is missing from all of the loaded faces so
+	// that large fonts, such as CJK system fonts, are only read when needed
+	fallbacks []FontFace
+	// frame is the layout pass the text is shaped in, glyphs rasterized at
+	// runtime that are used in the current pass are never evicted
+	frame uint64
+}
+
+// SetFallbackFaces sets the faces that are searched, in order, for any glyph
+// that is missing from the face text is drawn with. Typically these are faces
+// that cover other scripts, such as CJK, and emoji. Faces that can't be loaded
+// are skipped.
+func (cache *FontCache) SetFallbackFaces(faces ...FontFace) {
+	cache.FaceMutex.Lock()
+	defer cache.FaceMutex.Unlock()
+	cache.fallbackFaces = slices.Clone(faces)
+}
+
+// FallbackFaces returns the faces that are searched for missing glyphs, see
+// [FontCache.SetFallbackFaces]
+func (cache *FontCache) FallbackFaces() []FontFace {
+	cache.FaceMutex.RLock()
+	defer cache.FaceMutex.RUnlock()
+	return slices.Clone(cache.fallbackFaces)
+}
+
+func (cache *FontCache) shaper(face FontFace) textShaper {
+	cache.requireFace(face)
+	cache.FaceMutex.RLock()
+	fallbacks := cache.fallbackFaces
+	cache.FaceMutex.RUnlock()
+	s := textShaper{cache: cache, face: face, fallbacks: fallbacks,
+		faces: make([]fontBin, 0, 1+len(fallbacks)),
+		frame: atomic.AddUint64(&cache.layoutFrame, 1)}
+	s.faces = append(s.faces, cache.fontFaces[face.string()])
+	return s
+}
+
+// loadFallback loads the next fallback face, false is returned once there are
+// no fallback faces left
+func (s *textShaper) loadFallback() bool {
+	for len(s.fallbacks) > 0 {
+		f := s.fallbacks[0]
+		s.fallbacks = s.fallbacks[1:]
+		if f == s.face || s.cache.missingFaces[f.string()] {
+			continue
+		}
+		s.cache.requireFace(f)
+		if bin, ok := s.cache.fontFaces[f.string()]; ok {
+			s.faces = append(s.faces, bin)
+			return true
+		}
+		slog.Warn("failed to load the fallback font face, it will be skipped", "face", f)
+		s.cache.missingFaces[f.string()] = true
+	}
+	return false
+}
+
+// find looks for the rune in the faces, loading fallback faces until one of
+// them has it
+func (s *textShaper) find(r rune) (fontBinChar, int, bool) {
+	for i := 0; i < len(s.faces) || s.loadFallback(); i++ {
+		if ch, ok := s.faces[i].findGlyph(r, s.frame); ok {
+			return ch, i, true
+		}
+	}
+	return fontBinChar{}, 0, false
+}
+
+func (s *textShaper) has(r rune) bool {
+	_, _, ok := s.find(r)
+	return ok
+}
+
+// glyph finds the glyph for the rune in the first face that has it, if none
+// of the faces do then the invalid rune proxy of the main face is used
+func (s *textShaper) glyph(r rune) (fontBinChar, int) {
+	if ch, face, ok := s.find(r); ok {
+		return ch, face
+	}
+	ch, _ := s.faces[0].findGlyph(invalidRuneProxy, s.frame)
+	return ch, 0
+}
+
+// shape turns the runes into glyphs in logical order. Arabic letters take the
+// form for the letters they join with, characters in right to left text are
+// mirrored, and the kerning of the face is applied between glyphs of the same
+// face that are laid out in the same direction.
+func (s *textShaper) shape(runes []rune, levels []uint8, scale float32) []shapedGlyph {
+	defer tracing.NewRegion("textShaper.shape").End()
+	forms := arabicForms(runes, s.has)
+	glyphs := make([]shapedGlyph, 0, len(runes))
+	for i, r := range forms {
+		if r == arabicLigated {
+			glyphs[len(glyphs)-1].runes++
+			continue
+		}
+		var lvl uint8
+		if levels != nil {
+			lvl = levels[i]
+		}
+		if r == '\n' {
+			glyphs = append(glyphs, shapedGlyph{char: fontBinChar{letter: r},
+				cluster: i, runes: 1, level: lvl})
+			continue
+		}
+		if m, ok := bidiMirrors[r]; ok && lvl&1 != 0 && s.has(m) {
+			r = m
+		}
+		ch, face := s.glyph(r)
+		g := shapedGlyph{char: ch, face: face, cluster: i, runes: 1,
+			level: lvl, advance: ch.advance * scale}
+		if len(glyphs) > 0 {
+			prev := &glyphs[len(glyphs)-1]
+			if prev.face == face && prev.char.letter != '\n' && prev.level&1 == lvl&1 {
+				bin := &s.faces[face]
+				if lvl&1 == 0 {
+					prev.advance += bin.kern(prev.char.letter, ch.letter) * scale
+				} else {
+					g.advance += bin.kern(ch.letter, prev.char.letter) * scale
+				}
+			}
+		}
+		glyphs = append(glyphs, g)
+	}
+	return glyphs
+}
+
+// layoutText shapes the text and breaks it into lines. Lines only break on
+// new lines and white space once they reach the max width, if wrap is false
+// all of the text is placed on a single line.
+func (cache *FontCache) layoutText(face FontFace, text string, scale, maxWidth, letterSpacing float32, wrap bool, dir TextDirection) shapedText {
+	defer tracing.NewRegion("FontCache.layoutText").End()
+	s := cache.shaper(face)
+	t := shapedText{runes: []rune(text)}
+	var levels []uint8
+	levels, t.bases = bidiResolve(t.runes, dir)
+	t.glyphs = s.shape(t.runes, levels, scale)
+	t.faces = s.faces
+	for current := 0; current < len(t.glyphs); {
+		count := len(t.glyphs) - current
+		if wrap {
+			count = t.lineGlyphCount(current, maxWidth, letterSpacing)
+		}
+		t.lines = append(t.lines, shapedLine{start: current, end: current + count})
+		t.lines[len(t.lines)-1].width = t.positionLine(len(t.lines)-1, letterSpacing, 0)
+		current += count
+	}
+	return t
+}
+
+// lineGlyphCount is the number of glyphs from start that fit within the max
+// width, the line is broken after the last white space that fits
+func (t *shapedText) lineGlyphCount(start int, maxWidth, letterSpacing float32) int {
+	glyphs := t.glyphs[start:]
+	wrap := false
+	spaceIndex := 0
+	wx := float32(0.0)
+	for i := range glyphs {
+		r := t.runes[glyphs[i].cluster]
+		if r == '\n' {
+			spaceIndex = i
+			wrap = true
+			break
+		} else if unicode.IsSpace(r) {
+			spaceIndex = i
+		}
+		wx += glyphs[i].advance
+		if i < len(glyphs)-1 {
+			wx += letterSpacing
+		}
+		if wx >= maxWidth && spaceIndex != 0 {
+			wrap = true
+			break
+		}
+	}
+	if !wrap {
+		return len(glyphs)
+	}
+	return spaceIndex + 1
+}
+
+func (t *shapedText) isNewLine(g *shapedGlyph) bool { return t.runes[g.cluster] == '\n' }
+
+// positionLine places the glyphs of the line from left to right in visual
+// order and returns the width of the line. The extra space advance is added
+// after every white space, which is used to justify text.
+func (t *shapedText) positionLine(index int, letterSpacing, spaceAdvance float32) float32 {
+	line := t.lines[index]
+	glyphs := t.glyphs[line.start:line.end]
+	var order []int
+	var base uint8
+	if t.bases != nil && len(glyphs) > 0 {
+		levels := make([]uint8, len(glyphs))
+		for i := range glyphs {
+			levels[i] = glyphs[i].level
+		}
+		// White space at the end of a line takes the direction of the paragraph
+		base = t.bases[glyphs[len(glyphs)-1].cluster]
+		for i := len(glyphs) - 1; i >= 0 && unicode.IsSpace(t.runes[glyphs[i].cluster]); i-- {
+			levels[i] = base
+		}
+		order = bidiVisualOrder(levels)
+	}
+	x := float32(0)
+	first := true
+	for i := range glyphs {
+		g := &glyphs[i]
+		if order != nil {
+			g = &glyphs[order[i]]
+		}
+		if t.isNewLine(g) {
+			continue
+		}
+		if !first {
+			x += letterSpacing
+		}
+		first = false
+		g.x = x
+		x += g.advance
+		if spaceAdvance > 0 && unicode.IsSpace(t.runes[g.cluster]) {
+			x += spaceAdvance
+		}
+	}
+	for i := range glyphs {
+		if t.isNewLine(&glyphs[i]) {
+			glyphs[i].x = x
+			if base&1 != 0 {
+				glyphs[i].x = 0
+			}
+		}
+	}
+	return x
+}
+
+// runeRect is the left and width of a rune within its line, the runes of a
+// ligature share the width of their glyph
+func (t *shapedText) runeRect(g *shapedGlyph, rune int) (x, width float32) {
+	width = g.advance / float32(g.runes)
+	if g.level&1 != 0 {
+		return g.x + g.advance - float32(rune+1)*width, width
+	}
+	return g.x + float32(rune)*width, width
+}
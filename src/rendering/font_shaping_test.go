@@ -0,0 +1,150 @@
+/******************************************************************************/
+/* font_shaping_test.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+const (
+	testShapingFace    = FontFace("Test-Regular")
+	testFallbackFace   = FontFace("TestFallback-Regular")
+	testShapingScale   = 10
+	testShapingAdvance = 0.5
+)
+
+func testFontBin(letters ...rune) fontBin {
+	bin := fontBin{
+		letters: make(map[rune]fontBinChar),
+		kerning: make(map[fontKernPair]float32),
+	}
+	for _, r := range append(letters, ' ', invalidRuneProxy) {
+		bin.letters[r] = fontBinChar{letter: r, advance: testShapingAdvance}
+	}
+	return bin
+}
+
+func testShapingCache(main, fallback fontBin) *FontCache {
+	return &FontCache{
+		fontFaces: map[string]fontBin{
+			testShapingFace.string():  main,
+			testFallbackFace.string(): fallback,
+		},
+		fallbackFaces: []FontFace{testFallbackFace},
+		missingFaces:  make(map[string]bool),
+	}
+}
+
+func TestShapingAppliesKerning(t *testing.T) {
+	main := testFontBin('A', 'V')
+	main.kerning[fontKernPair{'A', 'V'}] = -0.1
+	cache := testShapingCache(main, testFontBin())
+	got := cache.MeasureString(testShapingFace, "AVA", testShapingScale)
+	// A and V are kerned, V and A are not
+	if !matrix.Approx(got, 14) {
+		t.Fatalf("MeasureString = %v, want 14", got)
+	}
+}
+
+func TestShapingFallsBackForMissingGlyphs(t *testing.T) {
+	fallback := testFontBin('漢')
+	fallback.letters['漢'] = fontBinChar{letter: '漢', advance: 1}
+	cache := testShapingCache(testFontBin('a'), fallback)
+	shaped := cache.layoutText(testShapingFace, "a漢?", testShapingScale, 0, 0, false, TextDirectionAuto)
+	if len(shaped.glyphs) != 3 {
+		t.Fatalf("expected 3 glyphs, got %d", len(shaped.glyphs))
+	}
+	if shaped.glyphs[0].face != 0 || shaped.glyphs[1].face != 1 {
+		t.Fatalf("expected the faces to be 0 and 1, got %d and %d",
+			shaped.glyphs[0].face, shaped.glyphs[1].face)
+	}
+	if shaped.glyphs[2].face != 0 || shaped.glyphs[2].char.letter != invalidRuneProxy {
+		t.Fatalf("expected a glyph missing from all faces to use the invalid rune proxy")
+	}
+	if !matrix.Approx(shaped.lines[0].width, 20) {
+		t.Fatalf("line width = %v, want 20", shaped.lines[0].width)
+	}
+}
+
+func TestShapingSkipsFallbacksThatFailToLoad(t *testing.T) {
+	cache := testShapingCache(testFontBin('a'), testFontBin())
+	cache.fallbackFaces = append(cache.fallbackFaces, "Missing-Regular")
+	cache.missingFaces["Missing-Regular"] = true
+	s := cache.shaper(testShapingFace)
+	if len(s.faces) != 1 {
+		t.Fatalf("expected the fallback faces to load when needed, got %d faces", len(s.faces))
+	}
+	if s.has('b') || len(s.faces) != 2 {
+		t.Fatalf("expected 2 faces, got %d", len(s.faces))
+	}
+}
+
+func TestShapingReordersRightToLeftText(t *testing.T) {
+	cache := testShapingCache(testFontBin('a', 'b', 'א', 'ב'), testFontBin())
+	rects := cache.StringRectsWithinWithLetterSpacing(testShapingFace, "ab אב",
+		testShapingScale, 0, 0, 0, TextDirectionAuto)
+	want := []float32{0, 5, 10, 20, 15}
+	for i := range want {
+		if !matrix.Approx(rects[i].X(), want[i]) {
+			t.Fatalf("rune %d is at %v, want %v", i, rects[i].X(), want[i])
+		}
+	}
+	rects = cache.StringRectsWithinWithLetterSpacing(testShapingFace, "ab אב",
+		testShapingScale, 0, 0, 0, TextDirectionRightToLeft)
+	want = []float32{15, 20, 10, 5, 0}
+	for i := range want {
+		if !matrix.Approx(rects[i].X(), want[i]) {
+			t.Fatalf("rtl rune %d is at %v, want %v", i, rects[i].X(), want[i])
+		}
+	}
+}
+
+func TestShapingMirrorsRightToLeftBrackets(t *testing.T) {
+	cache := testShapingCache(testFontBin('(', ')', 'א'), testFontBin())
+	shaped := cache.layoutText(testShapingFace, "(א)", testShapingScale, 0, 0, false, TextDirectionRightToLeft)
+	if shaped.glyphs[0].char.letter != ')' || shaped.glyphs[2].char.letter != '(' {
+		t.Fatalf("expected the brackets to be mirrored, got %c and %c",
+			shaped.glyphs[0].char.letter, shaped.glyphs[2].char.letter)
+	}
+}
+
+func TestShapingJoinsArabicLetters(t *testing.T) {
+	// Beh, lam and alef with the forms they take when joined together
+	cache := testShapingCache(testFontBin(0x0628, 0x0644, 0x0627, 0xFE91, 0xFEFC), testFontBin())
+	shaped := cache.layoutText(testShapingFace, "بلا", testShapingScale, 0, 0, false, TextDirectionAuto)
+	if len(shaped.glyphs) != 2 {
+		t.Fatalf("expected 2 glyphs, got %d", len(shaped.glyphs))
+	}
+	if shaped.glyphs[0].char.letter != 0xFE91 {
+		t.Fatalf("expected the initial form of beh, got %U", shaped.glyphs[0].char.letter)
+	}
+	if shaped.glyphs[1].char.letter != 0xFEFC || shaped.glyphs[1].runes != 2 {
+		t.Fatalf("expected the final lam alef ligature, got %U", shaped.glyphs[1].char.letter)
+	}
+	if shaped.glyphs[0].x < shaped.glyphs[1].x {
+		t.Fatal("expected the first letter to be on the right")
+	}
+	rects := cache.StringRectsWithinWithLetterSpacing(testShapingFace, "بلا",
+		testShapingScale, 0, 0, 0, TextDirectionAuto)
+	if len(rects) != 3 {
+		t.Fatalf("expected a rect for each rune, got %d", len(rects))
+	}
+}
+
+func TestShapingKeepsLeftToRightWrapping(t *testing.T) {
+	cache := testShapingCache(testFontBin('a'), testFontBin())
+	size := cache.MeasureStringWithin(testShapingFace, "aa aa aa", testShapingScale, 27, 0)
+	// Lines break after the white space that goes over the max width
+	if !matrix.Approx(size.X(), 30) {
+		t.Fatalf("width = %v, want 30", size.X())
+	}
+	if count := cache.LineCountWithin(testShapingFace, "aa aa aa\naa", testShapingScale, 27); count != 3 {
+		t.Fatalf("line count = %d, want 3", count)
+	}
+}
@@ -0,0 +1,59 @@
+/******************************************************************************/
+/* font_system.go                                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"log/slog"
+	"os"
+
+	"golang.org/x/image/font/sfnt"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// systemFontFile is a font that is installed with the operating system, index
+// is the font that is used when the file is a collection
+type systemFontFile struct {
+	path  string
+	index int
+}
+
+// systemFontFiles are the fonts of the operating system that are used for a
+// face when the asset database doesn't have a font for it, in the order they
+// are looked for. It is replaced in tests.
+var systemFontFiles = platformSystemFontFiles
+
+// readSystemFontSource reads the first of the operating system's fonts for the
+// face that is installed
+func readSystemFontSource(face FontFace) (*sfnt.Font, fontGPOSKerning) {
+	defer tracing.NewRegion("rendering.readSystemFontSource").End()
+	for _, file := range systemFontFiles(face) {
+		data, err := os.ReadFile(file.path)
+		if err != nil {
+			continue
+		}
+		f, err := parseFontSource(data, file.index)
+		if err != nil {
+			slog.Error("failed to parse the system font", "font", file.path, "error", err)
+			continue
+		}
+		return f, readFontGPOSKerning(data, file.index)
+	}
+	return nil, fontGPOSKerning{}
+}
+
+// parseFontSource parses a TTF/OTF file or the font at the index of a font
+// collection (TTC) file
+func parseFontSource(data []byte, index int) (*sfnt.Font, error) {
+	if len(data) < 4 || string(data[:4]) != "ttcf" {
+		return sfnt.Parse(data)
+	}
+	c, err := sfnt.ParseCollection(data)
+	if err != nil {
+		return nil, err
+	}
+	return c.Font(index)
+}
@@ -0,0 +1,20 @@
+/******************************************************************************/
+/* font_system_darwin.go                                                      */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+func platformSystemFontFiles(face FontFace) []systemFontFile {
+	if face != FontCJK {
+		return nil
+	}
+	return []systemFontFile{
+		{"/System/Library/Fonts/PingFang.ttc", 0},
+		{"/System/Library/Fonts/Hiragino Sans GB.ttc", 0},
+		{"/System/Library/Fonts/ヒラギノ角ゴシック W3.ttc", 0},
+		{"/System/Library/Fonts/AppleSDGothicNeo.ttc", 0},
+		{"/Library/Fonts/Arial Unicode.ttf", 0},
+	}
+}
@@ -0,0 +1,23 @@
+/******************************************************************************/
+/* font_system_linux.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+// The Android system fonts are also listed here as Android builds with the
+// linux build constraint
+func platformSystemFontFiles(face FontFace) []systemFontFile {
+	if face != FontCJK {
+		return nil
+	}
+	return []systemFontFile{
+		{"/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc", 0},
+		{"/usr/share/fonts/noto-cjk/NotoSansCJK-Regular.ttc", 0},
+		{"/usr/share/fonts/google-noto-cjk/NotoSansCJK-Regular.ttc", 0},
+		{"/usr/share/fonts/truetype/wqy/wqy-microhei.ttc", 0},
+		{"/usr/share/fonts/wenquanyi/wqy-microhei/wqy-microhei.ttc", 0},
+		{"/system/fonts/NotoSansCJK-Regular.ttc", 0},
+	}
+}
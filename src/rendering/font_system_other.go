@@ -0,0 +1,11 @@
+//go:build !windows && !darwin && !linux
+
+/******************************************************************************/
+/* font_system_other.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+func platformSystemFontFiles(face FontFace) []systemFontFile { return nil }
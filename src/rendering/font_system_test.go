@@ -0,0 +1,47 @@
+/******************************************************************************/
+/* font_system_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func testSystemFontFiles(t *testing.T, files ...systemFontFile) {
+	t.Helper()
+	restore := systemFontFiles
+	systemFontFiles = func(FontFace) []systemFontFile { return files }
+	t.Cleanup(func() { systemFontFiles = restore })
+}
+
+func TestSystemFontSourceUsesFirstInstalledFont(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "installed.ttf")
+	if err := os.WriteFile(path, goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+	testSystemFontFiles(t,
+		systemFontFile{path: filepath.Join(dir, "missing.ttc")},
+		systemFontFile{path: path})
+	f, _ := readSystemFontSource(FontCJK)
+	if f == nil {
+		t.Fatal("expected the installed font to be read")
+	}
+	if i, err := f.GlyphIndex(nil, 'A'); err != nil || i == 0 {
+		t.Errorf("expected the font to have a glyph for A, got %d, %v", i, err)
+	}
+}
+
+func TestSystemFontSourceWithoutInstalledFont(t *testing.T) {
+	testSystemFontFiles(t, systemFontFile{path: filepath.Join(t.TempDir(), "missing.ttc")})
+	if f, _ := readSystemFontSource(FontCJK); f != nil {
+		t.Error("expected no font when none of the system fonts are installed")
+	}
+}
@@ -0,0 +1,26 @@
+/******************************************************************************/
+/* font_system_windows.go                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"os"
+	"path/filepath"
+)
+
+func platformSystemFontFiles(face FontFace) []systemFontFile {
+	if face != FontCJK {
+		return nil
+	}
+	dir := filepath.Join(os.Getenv("WINDIR"), "Fonts")
+	return []systemFontFile{
+		{filepath.Join(dir, "msyh.ttc"), 0},     // Microsoft YaHei
+		{filepath.Join(dir, "YuGothR.ttc"), 0},  // Yu Gothic
+		{filepath.Join(dir, "msgothic.ttc"), 0}, // MS Gothic
+		{filepath.Join(dir, "malgun.ttf"), 0},   // Malgun Gothic
+		{filepath.Join(dir, "simsun.ttc"), 0},   // SimSun
+	}
+}
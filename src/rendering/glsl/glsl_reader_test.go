@@ -26,6 +26,7 @@ func TestParse(t *testing.T) {
 		"FRAGMENT_SHADER",
 		"HAS_GBUFFER",
 		"SHADOW_SAMPLERS",
+		"LIGHT_CLUSTERS",
 	}
 	for i := range bareDefines {
 		v, ok := src.defines[bareDefines[i]]
@@ -39,28 +40,22 @@ func TestParse(t *testing.T) {
 
 	// Verify defines with numeric values
 	numericDefines := map[string]any{
-		"SAMPLER_COUNT":                 float64(4),
-		"LAYOUT_FRAG_COLOR":             float64(0),
-		"LAYOUT_FRAG_FLAGS":             float64(1),
-		"LAYOUT_FRAG_POS":               float64(2),
-		"LAYOUT_FRAG_TEX_COORDS":        float64(3),
-		"LAYOUT_FRAG_NORMAL":            float64(4),
-		"LAYOUT_FRAG_METALLIC":          float64(5),
-		"LAYOUT_FRAG_ROUGHNESS":         float64(6),
-		"LAYOUT_FRAG_EMISSIVE":          float64(7),
-		"LAYOUT_ALL_LIGHT_REQUIREMENTS": float64(8),
-		"LAYOUT_FRAG_TANGENT_FRAG_POS":  float64(9),
-		"LAYOUT_FRAG_LIGHT_T_POS":       float64(10),
-		"LAYOUT_FRAG_LIGHT_T_DIR":       float64(14),
-		"LAYOUT_FRAG_POS_LIGHT_SPACE":   float64(18),
-		"LAYOUT_FRAG_LIGHT_COUNT":       float64(22),
-		"LAYOUT_FRAG_LIGHT_INDEXES":     float64(23),
-		"LOCATION_HEAD":                 float64(8),
-		"LOCATION_START":                float64(12),
-		"CUBEMAP_SIDES":                 float64(6),
-		"NR_LIGHTS":                     float64(4),
-		"MAX_JOINTS":                    float64(50),
-		"MAX_LIGHTS":                    float64(20),
+		"SAMPLER_COUNT":          float64(4),
+		"LAYOUT_FRAG_COLOR":      float64(0),
+		"LAYOUT_FRAG_FLAGS":      float64(1),
+		"LAYOUT_FRAG_POS":        float64(2),
+		"LAYOUT_FRAG_TEX_COORDS": float64(3),
+		"LAYOUT_FRAG_NORMAL":     float64(4),
+		"LAYOUT_FRAG_METALLIC":   float64(5),
+		"LAYOUT_FRAG_ROUGHNESS":  float64(6),
+		"LAYOUT_FRAG_EMISSIVE":   float64(7),
+		"LOCATION_HEAD":          float64(8),
+		"LOCATION_START":         float64(12),
+		"CUBEMAP_SIDES":          float64(6),
+		"NR_LIGHTS":              float64(4),
+		"MAX_JOINTS":             float64(50),
+		"MAX_LIGHTS":             float64(20),
+		"LIGHT_CLUSTER_COUNT":    float64(3456),
 	}
 	for name, expected := range numericDefines {
 		v, ok := src.defines[name]
@@ -112,13 +107,6 @@ func TestParse(t *testing.T) {
 		{"fragMetallic", 5},
 		{"fragRoughness", 6},
 		{"fragEmissive", 7},
-		{"fragTangentViewPos", 8},
-		{"fragTangentFragPos", 9},
-		{"fragLightTPos", 10},
-		{"fragLightTDir", 14},
-		{"fragPosLightSpace", 18},
-		{"fragLightCount", 22},
-		{"fragLightIndexes", 23},
 	}
 
 	found := make(map[string]bool)
@@ -102,20 +102,7 @@ func (g *GPUApplicationInstance) Initialize(window RenderingContainer, app *GPUA
 
 func (g *GPUApplicationInstance) SetupCaches(caches RenderCaches, width, height int32) error {
 	defer tracing.NewRegion("GPUApplicationInstance.SetupCaches").End()
-	device := g.PrimaryDevice()
-	device.Painter.caches = caches
-	var err error
-	device.Painter.fallbackShadowMap, err = caches.TextureCache().Texture(assets.TextureSquare, TextureFilterLinear)
-	if err != nil {
-		return err
-	}
-	device.Painter.fallbackCubeShadowMap, err = caches.TextureCache().Texture(assets.TextureCube, TextureFilterLinear)
-	if err != nil {
-		return err
-	}
-	device.Painter.fallbackCubeShadowMap.SetPendingDataDimensions(TextureDimensionsCube)
-	caches.TextureCache().ProcessPending()
-	return err
+	return g.PrimaryDevice().SetupCaches(caches)
 }
 
 func (g *GPUApplicationInstance) Destroy() {
@@ -0,0 +1,46 @@
+/******************************************************************************/
+/* gpu_backend.go                                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GPUBackend selects what the renderer is backed by. The null backend is used
+// for servers, CI and tests, it accepts all of the same calls as the Vulkan
+// backend but never touches a GPU.
+type GPUBackend uint8
+
+const (
+	GPUBackendVulkan GPUBackend = iota
+	GPUBackendNull
+)
+
+func (b GPUBackend) String() string {
+	switch b {
+	case GPUBackendVulkan:
+		return "vulkan"
+	case GPUBackendNull:
+		return "null"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseGPUBackend converts the name of a backend, as written on the command
+// line or in a config, into the backend. "headless" is accepted for null.
+func ParseGPUBackend(name string) (GPUBackend, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "vulkan", "vk":
+		return GPUBackendVulkan, nil
+	case "null", "headless", "none":
+		return GPUBackendNull, nil
+	default:
+		return GPUBackendVulkan, fmt.Errorf("unknown GPU backend %q", name)
+	}
+}
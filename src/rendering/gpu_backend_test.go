@@ -0,0 +1,33 @@
+/******************************************************************************/
+/* gpu_backend_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import "testing"
+
+func TestParseGPUBackend(t *testing.T) {
+	cases := []struct {
+		name string
+		want GPUBackend
+	}{
+		{"", GPUBackendVulkan},
+		{"vulkan", GPUBackendVulkan},
+		{"Null", GPUBackendNull},
+		{" headless ", GPUBackendNull},
+	}
+	for _, c := range cases {
+		got, err := ParseGPUBackend(c.name)
+		if err != nil || got != c.want {
+			t.Fatalf("ParseGPUBackend(%q) = %v, %v; want %v", c.name, got, err, c.want)
+		}
+		if back, _ := ParseGPUBackend(got.String()); back != got {
+			t.Fatalf("backend %v did not round trip through its name", got)
+		}
+	}
+	if _, err := ParseGPUBackend("metal"); err == nil {
+		t.Fatal("expected an error for an unknown backend")
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"log/slog"
 	"unsafe"
 
+	"kaijuengine.com/engine/assets"
 	"kaijuengine.com/engine/cameras"
 	"kaijuengine.com/engine/pooling"
 	"kaijuengine.com/klib"
@@ -26,11 +27,15 @@ type GPUDevice struct {
 	globalUniformBuffersMemory [maxFramesInFlight]GPUDeviceMemory
 	globalUniforms             map[*RenderView]*globalUniformBufferSet
 	singleTimeCommandPool      pooling.PoolGroup[CommandRecorder]
+	null                       *nullGPUDevice
 }
 
 type globalUniformBufferSet struct {
-	buffers [maxFramesInFlight]GPUBuffer
-	memory  [maxFramesInFlight]GPUDeviceMemory
+	buffers        [maxFramesInFlight]GPUBuffer
+	memory         [maxFramesInFlight]GPUDeviceMemory
+	clusters       [maxFramesInFlight]GPUBuffer
+	clustersMemory [maxFramesInFlight]GPUDeviceMemory
+	clusterData    []byte
 }
 
 func (g *GPUDevice) QueueCompute(buffer *ComputeShaderBuffer) {
@@ -45,6 +50,25 @@ func (g *GPUDevice) QueueCompute(buffer *ComputeShaderBuffer) {
 	})
 }
 
+// SetupCaches binds the render caches to the device and loads the fallback
+// textures that are sampled when a light has no shadow map yet
+func (g *GPUDevice) SetupCaches(caches RenderCaches) error {
+	defer tracing.NewRegion("GPUDevice.SetupCaches").End()
+	g.Painter.caches = caches
+	var err error
+	g.Painter.fallbackShadowMap, err = caches.TextureCache().Texture(assets.TextureSquare, TextureFilterLinear)
+	if err != nil {
+		return err
+	}
+	g.Painter.fallbackCubeShadowMap, err = caches.TextureCache().Texture(assets.TextureCube, TextureFilterLinear)
+	if err != nil {
+		return err
+	}
+	g.Painter.fallbackCubeShadowMap.SetPendingDataDimensions(TextureDimensionsCube)
+	caches.TextureCache().ProcessPending()
+	return err
+}
+
 func (g *GPUDevice) CreateSwapChain(window RenderingContainer, inst *GPUApplicationInstance) error {
 	defer tracing.NewRegion("GPUDevice.CreateSwapChain").End()
 	if g.LogicalDevice.SwapChain.IsValid() {
@@ -188,6 +212,13 @@ func (g *GPUDevice) createGlobalUniformBufferSet() (*globalUniformBufferSet, err
 		}
 		state.buffers[i] = b
 		state.memory[i] = m
+		b, m, err = g.CreateBuffer(uintptr(lightClusterBufferSize), GPUBufferUsageStorageBufferBit,
+			GPUMemoryPropertyHostVisibleBit|GPUMemoryPropertyHostCoherentBit)
+		if err != nil {
+			return nil, err
+		}
+		state.clusters[i] = b
+		state.clustersMemory[i] = m
 	}
 	return state, nil
 }
@@ -214,6 +245,14 @@ func (g *GPUDevice) destroyGlobalUniformBufferSet(state *globalUniformBufferSet)
 			g.FreeMemory(state.memory[i])
 			state.memory[i].Reset()
 		}
+		if state.clusters[i].IsValid() {
+			g.DestroyBuffer(state.clusters[i])
+			state.clusters[i].Reset()
+		}
+		if state.clustersMemory[i].IsValid() {
+			g.FreeMemory(state.clustersMemory[i])
+			state.clustersMemory[i].Reset()
+		}
 	}
 }
 
@@ -236,6 +275,8 @@ func (g *GPUDevice) deferGlobalUniformBufferSetDestroy(state *globalUniformBuffe
 	for i := range maxFramesInFlight {
 		pd.buffers[i] = state.buffers[i]
 		pd.memories[i] = state.memory[i]
+		pd.namedBuffers[i] = append(pd.namedBuffers[i], state.clusters[i])
+		pd.namedMemories[i] = append(pd.namedMemories[i], state.clustersMemory[i])
 	}
 	g.LogicalDevice.bufferTrash.Add(pd)
 }
@@ -268,6 +309,14 @@ func (g *GPUDevice) globalUniformBuffer(view *RenderView, frame int) (GPUBuffer,
 	return state.buffers[frame], nil
 }
 
+func (g *GPUDevice) lightClusterBuffer(view *RenderView, frame int) (GPUBuffer, error) {
+	state, err := g.ensureGlobalUniformsForView(view)
+	if err != nil {
+		return GPUBuffer{}, err
+	}
+	return state.clusters[frame], nil
+}
+
 func (g *GPUDevice) beginSingleTimeCommands() *CommandRecorder {
 	defer tracing.NewRegion("GPUDevice.beginSingleTimeCommands").End()
 	return g.beginSingleTimeCommandsImpl()
@@ -357,6 +406,15 @@ func (g *GPUDevice) updateGlobalUniformBufferForView(view RenderViewFrame, camer
 	}
 	g.Memcopy(data, klib.StructToByteArray(ubo))
 	g.UnmapMemory(state.memory[frame])
+	state.clusterData = lights.Clusters.appendGPUData(state.clusterData[:0], viewCamera, len(lights.Lights))
+	err = g.MapMemory(state.clustersMemory[frame],
+		0, uintptr(len(state.clusterData)), 0, &data)
+	if err != nil {
+		slog.Error("Failed to map light cluster buffer memory", "error", err)
+		return err
+	}
+	g.Memcopy(data, state.clusterData)
+	g.UnmapMemory(state.clustersMemory[frame])
 	return nil
 }
 
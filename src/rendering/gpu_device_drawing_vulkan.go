@@ -9,6 +9,7 @@ package rendering
 import (
 	"log/slog"
 	"runtime"
+	"slices"
 	"strconv"
 	"strings"
 	"unsafe"
@@ -79,7 +80,13 @@ func (g *GPUDevice) drawImpl(renderPass *RenderPass, drawings []ShaderDraw, ligh
 			clears[i].SetColor([]float32{c.R, c.G, c.B, c.A})
 		}
 	}
-	renderPass.beginNextSubpass(g.Painter.currentFrame, ext, clears)
+	sampled := sampledAttachments(renderPass, drawings, doDrawings)
+	renderPass.beginNextSubpass(g.Painter.currentFrame, ext, clears, func(cmd *CommandRecorder) {
+		for _, t := range sampled {
+			g.TransitionImageLayout(t, GPUImageLayoutShaderReadOnlyOptimal,
+				GPUImageAspectColorBit, GPUAccessShaderReadBit, cmd)
+		}
+	})
 	for i := range drawings {
 		d := &drawings[i]
 		if doDrawings[i] {
@@ -92,7 +99,7 @@ func (g *GPUDevice) drawImpl(renderPass *RenderPass, drawings []ShaderDraw, ligh
 	renderPass.ExecuteSecondaryCommands()
 	for i := range renderPass.subpasses {
 		s := &renderPass.subpasses[i]
-		renderPass.beginNextSubpass(g.Painter.currentFrame, ext, clears)
+		renderPass.beginNextSubpass(g.Painter.currentFrame, ext, clears, nil)
 		cmd := renderPass.activeSubpassCommand(i)
 		vk.CmdBindPipeline(cmd.buffer, vulkan_const.PipelineBindPointGraphics,
 			vk.Pipeline(s.shader.RenderId.graphicsPipeline.handle))
@@ -122,13 +129,45 @@ func (g *GPUDevice) drawImpl(renderPass *RenderPass, drawings []ShaderDraw, ligh
 		vk.CmdDrawIndexed(cmd.buffer, mid.indexCount, 1, 0, 0, 0)
 		renderPass.ExecuteSecondaryCommands()
 	}
-	renderPass.endSubpasses()
+	renderPass.endSubpasses(func(cmd *CommandRecorder) {
+		for _, t := range sampled {
+			g.TransitionImageLayout(t, GPUImageLayoutColorAttachmentOptimal, GPUImageAspectColorBit,
+				GPUAccessColorAttachmentReadBit|GPUAccessColorAttachmentWriteBit, cmd)
+		}
+	})
 	// TODO:  Make this more generic so that there can be a sequence of stages
 	// that require other stages to be done. For now I'm just adding the pre and
 	// post stages to make sure shadows go first
 	g.Painter.forceQueueCommand(*renderPass.activePrimaryCommand(), renderPass.IsShadowPass())
 }
 
+// sampledAttachments are the textures used by the drawings which are the
+// attachments of other render passes, such as the scene color read by the UI
+// backdrop filter. They are left in the color attachment layout after their
+// pass, so they are made readable by shaders only while this pass draws.
+func sampledAttachments(renderPass *RenderPass, drawings []ShaderDraw, doDrawings []bool) []*TextureId {
+	var sampled []*TextureId
+	for i := range drawings {
+		if !doDrawings[i] {
+			continue
+		}
+		for j := range drawings[i].instanceGroups {
+			mi := drawings[i].instanceGroups[j].MaterialInstance
+			if mi == nil {
+				continue
+			}
+			for _, t := range mi.Textures {
+				if t == nil || t.RenderId.Layout != GPUImageLayoutColorAttachmentOptimal ||
+					renderPass.ownsTexture(t) || slices.Contains(sampled, &t.RenderId) {
+					continue
+				}
+				sampled = append(sampled, &t.RenderId)
+			}
+		}
+	}
+	return sampled
+}
+
 func (g *GPUDevice) blitTargetsImpl(passes []*RenderPass) {
 	defer tracing.NewRegion("GPUDevice.blitTargetsImpl").End()
 	combined := g.prepCombinedTargets(passes)
@@ -234,6 +273,7 @@ func (g *GPUDevice) writeDrawingDescriptors(material *Material, groups []DrawIns
 		p.Pin(write.PTexelBufferView)
 		allWrites = append(allWrites, write)
 	}
+	clusterBinding := material.shaderInfo.lightClusterBinding()
 	for i := range groups {
 		group := &groups[i]
 		if !group.MatchesLayer(layerMask) {
@@ -261,6 +301,13 @@ func (g *GPUDevice) writeDrawingDescriptors(material *Material, groups []DrawIns
 			bufferInfo(vk.Buffer(globalBuffer.handle),
 				vk.DeviceSize(unsafe.Sizeof(*(*GlobalShaderData)(nil)))),
 		}
+		var clusterBuffer GPUBuffer
+		if clusterBinding >= 0 {
+			if clusterBuffer, err = g.lightClusterBuffer(view.Key(), g.Painter.currentFrame); err != nil {
+				slog.Error("failed to resolve light cluster buffer", "error", err)
+				continue
+			}
+		}
 		boundBufferInfos = boundBufferInfos[:0]
 		for k := range state.boundBuffers {
 			if state.boundBuffers[k].size > 0 {
@@ -315,12 +362,19 @@ func (g *GPUDevice) writeDrawingDescriptors(material *Material, groups []DrawIns
 		}
 		signature := descriptorSignatureForDrawGroup(material, group, state, g.Painter.currentFrame,
 			set, globalBuffer, boundBufferInfos, shadowMaps[:], shadowCubeMaps[:])
+		signature.AddHandle(clusterBuffer.GPUHandle)
 		shouldDraw = true
 		if !state.descriptorCache.ShouldWrite(g.Painter.currentFrame, signature) {
 			continue
 		}
 		addWrite(prepareSetWriteBuffer(vk.DescriptorSet(set.handle), globalInfo[:],
 			0, vulkan_const.DescriptorTypeUniformBuffer))
+		if clusterBinding >= 0 {
+			addWrite(prepareSetWriteBuffer(vk.DescriptorSet(set.handle),
+				[]vk.DescriptorBufferInfo{bufferInfo(vk.Buffer(clusterBuffer.handle),
+					vk.DeviceSize(lightClusterBufferSize))},
+				uint32(clusterBinding), vulkan_const.DescriptorTypeStorageBuffer))
+		}
 		if texCount > 0 {
 			vkImageInfos := make([]vk.DescriptorImageInfo, len(state.imageInfos))
 			for j := range state.imageInfos {
@@ -203,6 +203,28 @@ func (g *GPUDevice) copyBufferToImageWithCommand(cmd *CommandRecorder, buffer GP
 	}
 }
 
+// copyBufferToImageLevelsWithCommand copies each of the mip levels, which are
+// packed one after another in the buffer, into the matching level of the image
+func (g *GPUDevice) copyBufferToImageLevelsWithCommand(cmd *CommandRecorder, buffer GPUBuffer, image GPUImage, width, height uint32, levelSizes []uintptr) {
+	defer tracing.NewRegion("Vulkan.copyBufferToImageLevelsWithCommand").End()
+	regions := make([]vk.BufferImageCopy, len(levelSizes))
+	offset := vk.DeviceSize(0)
+	for i := range regions {
+		regions[i].BufferOffset = offset
+		regions[i].ImageSubresource.AspectMask = vk.ImageAspectFlags(vulkan_const.ImageAspectColorBit)
+		regions[i].ImageSubresource.MipLevel = uint32(i)
+		regions[i].ImageSubresource.LayerCount = 1
+		regions[i].ImageExtent = vk.Extent3D{
+			Width:  max(width>>i, 1),
+			Height: max(height>>i, 1),
+			Depth:  1,
+		}
+		offset += vk.DeviceSize(levelSizes[i])
+	}
+	vk.CmdCopyBufferToImage(cmd.buffer, vk.Buffer(buffer.handle), vk.Image(image.handle),
+		vulkan_const.ImageLayoutTransferDstOptimal, uint32(len(regions)), &regions[0])
+}
+
 func (g *GPUDevice) writeBufferToImageRegionImpl(image GPUImage, requests []GPUImageWriteRequest) error {
 	defer tracing.NewRegion("Vulkan.writeBufferToImageRegion").End()
 	// TODO:  Might need to match up the color here...
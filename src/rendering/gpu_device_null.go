@@ -0,0 +1,249 @@
+/******************************************************************************/
+/* gpu_device_null.go                                                         */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"sync"
+
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// NullGPUStats is a snapshot of what has been submitted to a null device. The
+// resource counts are the resources that are currently alive, the draw counts
+// are for the most recently submitted frame.
+type NullGPUStats struct {
+	Shaders     int
+	Textures    int
+	Meshes      int
+	Vertices    int
+	Indices     int
+	TextureSize int
+	Frames      uint64
+	Views       int
+	DrawCalls   int
+	Instances   int
+}
+
+type nullMeshRecord struct {
+	verts   []Vertex
+	indexes []uint32
+}
+
+type nullTextureRecord struct {
+	size int
+	data *TextureData
+}
+
+// nullGPUDevice keeps book of the resources and draws that would have been
+// sent to the GPU. Nothing here is ever executed, though the vertex and pixel
+// data is retained so a [SoftwareRasterizer] can draw what was submitted.
+type nullGPUDevice struct {
+	mutex    sync.Mutex
+	shaders  map[*Shader]struct{}
+	textures map[*Texture]nullTextureRecord
+	meshes   map[*Mesh]nullMeshRecord
+	frame    NullGPUStats
+}
+
+// NewNullGPUDevice creates a device for the null backend. It can be handed to
+// all of the render caches in place of a Vulkan device, they will track the
+// resources they are given without creating anything on a GPU.
+func NewNullGPUDevice() *GPUDevice {
+	return &GPUDevice{
+		LogicalDevice: GPULogicalDevice{
+			renderPassCache: make(map[string]*RenderPass),
+		},
+		null: &nullGPUDevice{
+			shaders:  make(map[*Shader]struct{}),
+			textures: make(map[*Texture]nullTextureRecord),
+			meshes:   make(map[*Mesh]nullMeshRecord),
+		},
+	}
+}
+
+// IsNull will return true if this device belongs to the null backend
+func (g *GPUDevice) IsNull() bool { return g != nil && g.null != nil }
+
+// NullStats returns what has been submitted to the null device so far. On a
+// device that isn't null, the stats will be zero.
+func (g *GPUDevice) NullStats() NullGPUStats {
+	if !g.IsNull() {
+		return NullGPUStats{}
+	}
+	n := g.null
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	stats := n.frame
+	stats.Shaders = len(n.shaders)
+	stats.Textures = len(n.textures)
+	stats.Meshes = len(n.meshes)
+	for _, t := range n.textures {
+		stats.TextureSize += t.size
+	}
+	for _, m := range n.meshes {
+		stats.Vertices += len(m.verts)
+		stats.Indices += len(m.indexes)
+	}
+	return stats
+}
+
+// SubmitNullFrame accepts a captured frame on the null device. The drawings
+// that would have been drawn for each view are counted, nothing is rendered.
+func (g *GPUDevice) SubmitNullFrame(drawings *Drawings, lights LightsForRender, views []RenderViewFrame) {
+	defer tracing.NewRegion("GPUDevice.SubmitNullFrame").End()
+	if !g.IsNull() {
+		return
+	}
+	drawings.mutex.Lock()
+	views = renderViewsForDraw(views)
+	shadowLightIndex := lights.directionalShadowLightIndex()
+	frame := NullGPUStats{Views: len(views)}
+	for i := range views {
+		layerMask := views[i].LayerMask()
+		for j := range drawings.renderPassGroups {
+			group := &drawings.renderPassGroups[j]
+			if group.renderPass.IsShadowPass() && shadowLightIndex < 0 {
+				continue
+			}
+			for k := range group.draws {
+				for l := range group.draws[k].instanceGroups {
+					ig := &group.draws[k].instanceGroups[l]
+					if !ig.MatchesLayer(layerMask) {
+						continue
+					}
+					state, ok := ig.viewStates[views[i].Key()]
+					if !ok || state.frameData.visibleCount == 0 {
+						continue
+					}
+					frame.DrawCalls++
+					frame.Instances += state.frameData.visibleCount
+				}
+			}
+		}
+	}
+	drawings.mutex.Unlock()
+	n := g.null
+	n.mutex.Lock()
+	frame.Frames = n.frame.Frames + 1
+	n.frame = frame
+	n.mutex.Unlock()
+}
+
+func (n *nullGPUDevice) createShader(shader *Shader) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.shaders[shader] = struct{}{}
+	for _, ss := range shader.subShaders {
+		n.shaders[ss] = struct{}{}
+	}
+}
+
+func (n *nullGPUDevice) destroyShaders() {
+	n.mutex.Lock()
+	clear(n.shaders)
+	n.mutex.Unlock()
+}
+
+func (n *nullGPUDevice) createTexture(texture *Texture) {
+	data := texture.takePendingData()
+	if data == nil {
+		return
+	}
+	if texture.Width == 0 && texture.Height == 0 {
+		texture.Width, texture.Height = data.Width, data.Height
+	}
+	if data.Width == 0 && data.Height == 0 {
+		// Raw memory doesn't carry a size, it lives on the texture instead
+		data.Width, data.Height = texture.Width, texture.Height
+	}
+	n.mutex.Lock()
+	n.textures[texture] = nullTextureRecord{size: len(data.Mem), data: data}
+	n.mutex.Unlock()
+}
+
+// textureData returns the pixels that were handed to the device for the
+// texture, these are kept so the software rasterizer is able to sample them
+func (n *nullGPUDevice) textureData(texture *Texture) *TextureData {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	return n.textures[texture].data
+}
+
+func (n *nullGPUDevice) destroyTexture(texture *Texture) {
+	n.mutex.Lock()
+	delete(n.textures, texture)
+	n.mutex.Unlock()
+}
+
+func (n *nullGPUDevice) destroyTextures() {
+	n.mutex.Lock()
+	clear(n.textures)
+	n.mutex.Unlock()
+}
+
+func (n *nullGPUDevice) createMesh(mesh *Mesh) {
+	if len(mesh.pendingVerts) == 0 {
+		return
+	}
+	record := nullMeshRecord{
+		verts:   mesh.pendingVerts,
+		indexes: mesh.pendingIndexes,
+	}
+	n.mutex.Lock()
+	// Vertex updates of an existing mesh do not carry the indexes again
+	if prev, ok := n.meshes[mesh]; ok && len(record.indexes) == 0 {
+		record.indexes = prev.indexes
+	}
+	n.meshes[mesh] = record
+	n.mutex.Unlock()
+	mesh.MeshId.vertexCount = uint32(len(record.verts))
+	mesh.MeshId.indexCount = uint32(len(record.indexes))
+	mesh.pendingVerts = make([]Vertex, 0)
+	mesh.pendingIndexes = make([]uint32, 0)
+}
+
+// meshData returns the vertices and indexes that were handed to the device
+// for the mesh, the second return is false if the mesh was never created
+func (n *nullGPUDevice) meshData(mesh *Mesh) (nullMeshRecord, bool) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	m, ok := n.meshes[mesh]
+	return m, ok
+}
+
+func (n *nullGPUDevice) destroyMesh(mesh *Mesh) {
+	n.mutex.Lock()
+	delete(n.meshes, mesh)
+	n.mutex.Unlock()
+}
+
+func (n *nullGPUDevice) destroyMeshes() {
+	n.mutex.Lock()
+	clear(n.meshes)
+	n.mutex.Unlock()
+}
+
+// nullRenderPass stands in for a render pass on the null device. It carries
+// the name and sort so drawings are grouped and ordered the same way as on a
+// real device, but it owns no GPU objects.
+func nullRenderPass(device *GPUDevice, data *RenderPassData) *RenderPass {
+	ld := &device.LogicalDevice
+	if pass, ok := ld.renderPassCache[data.Name]; ok {
+		return pass
+	}
+	pass := &RenderPass{
+		construction: RenderPassDataCompiled{
+			Name:        data.Name,
+			Sort:        data.Sort,
+			Width:       data.Width,
+			Height:      data.Height,
+			SkipCombine: data.SkipCombine,
+		},
+	}
+	ld.renderPassCache[data.Name] = pass
+	return pass
+}
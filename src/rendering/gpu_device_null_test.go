@@ -0,0 +1,92 @@
+/******************************************************************************/
+/* gpu_device_null_test.go                                                    */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"testing"
+)
+
+func TestNullGPUDeviceTracksCacheResources(t *testing.T) {
+	device := NewNullGPUDevice()
+	if !device.IsNull() {
+		t.Fatal("null device should report IsNull")
+	}
+	meshes := NewMeshCache(device, nil)
+	mesh := meshes.Mesh("mesh", testVerts(), []uint32{0, 1, 0})
+	textures := NewTextureCache(device, nil)
+	if _, err := textures.InsertRawTexture("tex", make([]byte, 4*4*4), 4, 4, TextureFilterLinear); err != nil {
+		t.Fatal(err)
+	}
+	shaders := NewShaderCache(device, nil)
+	shaders.Shader(ShaderDataCompiled{Name: "shader"})
+
+	meshes.ProcessPending()
+	textures.ProcessPending()
+	shaders.CreatePending()
+	stats := device.NullStats()
+	if stats.Meshes != 1 || stats.Vertices != 2 || stats.Indices != 3 {
+		t.Fatalf("mesh stats = %+v, want 1 mesh with 2 vertices and 3 indices", stats)
+	}
+	if stats.Textures != 1 || stats.TextureSize != 64 {
+		t.Fatalf("texture stats = %+v, want 1 texture of 64 bytes", stats)
+	}
+	if stats.Shaders != 1 {
+		t.Fatalf("shader stats = %+v, want 1 shader", stats)
+	}
+	if mesh.IsReady() {
+		t.Fatal("null meshes should never report a GPU handle")
+	}
+	if mesh.MeshId.IndexCount() != 3 || len(mesh.pendingVerts) != 0 {
+		t.Fatal("null mesh creation should consume the pending data and keep the counts")
+	}
+
+	meshes.RemoveMesh("mesh")
+	textures.ForceRemoveTexture("tex", TextureFilterLinear)
+	shaders.Destroy()
+	meshes.ProcessPending()
+	textures.ProcessPending()
+	stats = device.NullStats()
+	if stats.Meshes != 0 || stats.Textures != 0 || stats.Shaders != 0 {
+		t.Fatalf("stats after removal = %+v, want no resources", stats)
+	}
+}
+
+func TestNullGPUDeviceSubmitFrameCountsFrames(t *testing.T) {
+	device := NewNullGPUDevice()
+	drawings := NewDrawings()
+	device.SubmitNullFrame(&drawings, LightsForRender{}, nil)
+	device.SubmitNullFrame(&drawings, LightsForRender{}, nil)
+	stats := device.NullStats()
+	if stats.Frames != 2 {
+		t.Fatalf("frames = %d, want 2", stats.Frames)
+	}
+	if stats.DrawCalls != 0 || stats.Instances != 0 {
+		t.Fatalf("empty frame draws = %d/%d, want 0/0", stats.DrawCalls, stats.Instances)
+	}
+}
+
+func TestNullRenderPassIsShared(t *testing.T) {
+	device := NewNullGPUDevice()
+	a := nullRenderPass(device, &RenderPassData{Name: "light_offscreen", Sort: 2})
+	b := nullRenderPass(device, &RenderPassData{Name: "light_offscreen"})
+	if a != b {
+		t.Fatal("null render passes should be cached by name")
+	}
+	if !a.IsShadowPass() || a.construction.Sort != 2 {
+		t.Fatal("null render pass should keep the pass identity")
+	}
+}
+
+func TestNonNullDeviceHasNoNullStats(t *testing.T) {
+	var device *GPUDevice
+	if device.IsNull() {
+		t.Fatal("a nil device is not a null backend device")
+	}
+	if (&GPUDevice{}).NullStats() != (NullGPUStats{}) {
+		t.Fatal("a regular device should have empty null stats")
+	}
+}
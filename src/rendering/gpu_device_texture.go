@@ -8,6 +8,7 @@ package rendering
 
 import (
 	"errors"
+	"log/slog"
 
 	"kaijuengine.com/matrix"
 	"kaijuengine.com/platform/profiler/tracing"
@@ -50,6 +51,32 @@ func (b *TextureUploadBatch) End() {
 	b.cmd = nil
 }
 
+// RenderPassTexture finds an attachment of a render pass which has already
+// been created by the name of its image (such as "opaque.color") so that it
+// can be sampled by a material drawn in a later pass
+func (g *GPUDevice) RenderPassTexture(name string) (*Texture, bool) {
+	defer tracing.NewRegion("GPUDevice.RenderPassTexture").End()
+	if g == nil {
+		return nil, false
+	}
+	for _, p := range g.LogicalDevice.renderPassCache {
+		t, ok := p.findTextureByName(name)
+		if !ok {
+			continue
+		}
+		if !t.RenderId.Sampler.IsValid() {
+			sampler, err := g.CreateTextureSampler(t.RenderId.MipLevels, GPUFilterLinear)
+			if err != nil {
+				slog.Error("failed to create the sampler for the render pass texture", "texture", name, "error", err)
+				return nil, false
+			}
+			t.RenderId.Sampler = sampler
+		}
+		return t, true
+	}
+	return nil, false
+}
+
 func (g *GPUDevice) SetupTexture(texture *Texture, data *TextureData) error {
 	defer tracing.NewRegion("GPUDevice.SetupTexture").End()
 	return g.setupTextureImpl(texture, data, nil)
@@ -47,6 +47,9 @@ func (g *GPUDevice) setupTextureImpl(texture *Texture, data *TextureData, batch
 		}
 	case TextureInputTypeCompressedRgbaAstc4x4:
 		format = GPUFormatAstc4x4SrgbBlock
+		if data.InputType == TextureFileFormatKtx2 && data.Format == TextureColorFormatRgbaUnorm {
+			format = GPUFormatAstc4x4UnormBlock
+		}
 	case TextureInputTypeCompressedRgbaAstc5x4:
 		format = GPUFormatAstc5x4SrgbBlock
 	case TextureInputTypeCompressedRgbaAstc5x5:
@@ -73,9 +76,22 @@ func (g *GPUDevice) setupTextureImpl(texture *Texture, data *TextureData, batch
 		format = GPUFormatAstc12x10SrgbBlock
 	case TextureInputTypeCompressedRgbaAstc12x12:
 		format = GPUFormatAstc12x12SrgbBlock
+	case TextureInputTypeCompressedRgbBc1:
+		format = textureSrgbFormat(data, GPUFormatBc1RgbUnormBlock, GPUFormatBc1RgbSrgbBlock)
+	case TextureInputTypeCompressedRgbaBc1:
+		format = textureSrgbFormat(data, GPUFormatBc1RgbaUnormBlock, GPUFormatBc1RgbaSrgbBlock)
+	case TextureInputTypeCompressedRgbaBc3:
+		format = textureSrgbFormat(data, GPUFormatBc3UnormBlock, GPUFormatBc3SrgbBlock)
+	case TextureInputTypeCompressedRgBc5:
+		format = GPUFormatBc5UnormBlock
+	case TextureInputTypeCompressedRgbaBc7:
+		format = textureSrgbFormat(data, GPUFormatBc7UnormBlock, GPUFormatBc7SrgbBlock)
 	case TextureInputTypeLuminance:
 		panic("Luminance textures are not supported")
 	}
+	if err := g.textureFormatSupported(data); err != nil {
+		return err
+	}
 	filter := GPUFilterLinear
 	switch texture.Filter {
 	case TextureFilterLinear:
@@ -87,18 +103,27 @@ func (g *GPUDevice) setupTextureImpl(texture *Texture, data *TextureData, batch
 	use := GPUImageUsageTransferSrcBit | GPUImageUsageTransferDstBit | GPUImageUsageSampledBit
 	props := GPUMemoryPropertyDeviceLocalBit
 	mip := texture.MipLevels
-	if mip <= 0 {
+	// Mips that were created ahead of time are uploaded as they are, compressed
+	// textures can't have their mips generated by blitting. A KTX2 file without
+	// mips was packaged that way on purpose.
+	preMipped := len(data.Mips) > 0 || data.IsCompressed() || data.InputType == TextureFileFormatKtx2
+	if preMipped {
+		mip = 1 + len(data.Mips)
+	} else if mip <= 0 {
 		w, h := float32(width), float32(height)
 		mip = int(matrix.Floor(matrix.Log2(max(w, h)))) + 1
 	}
 	layerCount := uintptr(1)
 	flags := GPUImageCreateFlags(0)
 	// TODO:  Deal with cube maps the correct way
-	if data.Dimensions == TextureDimensionsCube {
+	if data.Dimensions == TextureDimensionsCube && !preMipped {
 		layerCount = 6
 		flags = GPUImageCreateCubeCompatibleBit
 	}
 	memLen := uintptr(len(data.Mem)) * layerCount
+	for i := range data.Mips {
+		memLen += uintptr(len(data.Mips[i]))
+	}
 	stagingBuffer, stagingBufferMemory, err := g.CreateBuffer(
 		memLen, GPUBufferUsageTransferSrcBit,
 		GPUMemoryPropertyHostVisibleBit|GPUMemoryPropertyHostCoherentBit)
@@ -118,11 +143,20 @@ func (g *GPUDevice) setupTextureImpl(texture *Texture, data *TextureData, batch
 		return err
 	}
 	offset := uintptr(0)
-	// TODO:  This is just copying the same texture over and over, it needs to be fixed
-	for range layerCount {
-		// TODO:  the /layerCount is due to the above todo for this just copying same image
-		g.Memcopy(unsafe.Pointer(uintptr(stageData)+offset), data.Mem[:memLen/layerCount])
-		offset += uintptr(memLen / layerCount)
+	if preMipped {
+		g.Memcopy(stageData, data.Mem)
+		offset += uintptr(len(data.Mem))
+		for i := range data.Mips {
+			g.Memcopy(unsafe.Pointer(uintptr(stageData)+offset), data.Mips[i])
+			offset += uintptr(len(data.Mips[i]))
+		}
+	} else {
+		// TODO:  This is just copying the same texture over and over, it needs to be fixed
+		for range layerCount {
+			// TODO:  the /layerCount is due to the above todo for this just copying same image
+			g.Memcopy(unsafe.Pointer(uintptr(stageData)+offset), data.Mem[:memLen/layerCount])
+			offset += uintptr(memLen / layerCount)
+		}
 	}
 	g.UnmapMemory(stagingBufferMemory)
 	// TODO:  Provide the desired sample as part of texture data?
@@ -155,10 +189,23 @@ func (g *GPUDevice) setupTextureImpl(texture *Texture, data *TextureData, batch
 	g.TransitionImageLayout(&texture.RenderId,
 		GPUImageLayoutTransferDstOptimal, GPUImageAspectColorBit,
 		texture.RenderId.Access, cmd)
-	g.copyBufferToImageWithCommand(cmd, stagingBuffer, texture.RenderId.Image,
-		uint32(width), uint32(height), int(layerCount))
-	err = g.generateMipMapsWithCommand(cmd, &texture.RenderId, format,
-		uint32(width), uint32(height), uint32(mip), filter)
+	if preMipped {
+		levelSizes := make([]uintptr, mip)
+		levelSizes[0] = uintptr(len(data.Mem))
+		for i := range data.Mips {
+			levelSizes[i+1] = uintptr(len(data.Mips[i]))
+		}
+		g.copyBufferToImageLevelsWithCommand(cmd, stagingBuffer, texture.RenderId.Image,
+			uint32(width), uint32(height), levelSizes)
+		g.TransitionImageLayout(&texture.RenderId,
+			GPUImageLayoutShaderReadOnlyOptimal, GPUImageAspectColorBit,
+			GPUAccessShaderReadBit, cmd)
+	} else {
+		g.copyBufferToImageWithCommand(cmd, stagingBuffer, texture.RenderId.Image,
+			uint32(width), uint32(height), int(layerCount))
+		err = g.generateMipMapsWithCommand(cmd, &texture.RenderId, format,
+			uint32(width), uint32(height), uint32(mip), filter)
+	}
 	if batch != nil {
 		batch.DeferCleanup(cleanupStaging)
 	} else {
@@ -190,6 +237,35 @@ func (g *GPUDevice) setupTextureImpl(texture *Texture, data *TextureData, batch
 	return nil
 }
 
+func textureSrgbFormat(data *TextureData, unorm, srgb GPUFormat) GPUFormat {
+	if data.Format == TextureColorFormatRgbaSrgb || data.Format == TextureColorFormatRgbSrgb {
+		return srgb
+	}
+	return unorm
+}
+
+// textureFormatSupported checks that the GPU is able to sample the block
+// compressed format of the texture data, these are optional device features
+func (g *GPUDevice) textureFormatSupported(data *TextureData) error {
+	features := &g.PhysicalDevice.Features
+	switch data.InternalFormat {
+	case TextureInputTypeCompressedRgbBc1, TextureInputTypeCompressedRgbaBc1,
+		TextureInputTypeCompressedRgbaBc3, TextureInputTypeCompressedRgBc5,
+		TextureInputTypeCompressedRgbaBc7:
+		if !features.TextureCompressionBC {
+			return errors.New("the GPU does not support BC compressed textures, package the content with a profile for this platform")
+		}
+	case TextureInputTypeRgba8, TextureInputTypeRgb8, TextureInputTypeLuminance:
+	default:
+		// The ASTC files that are read directly have always been uploaded
+		// without checking, only those from KTX2 are held to the feature
+		if data.InputType == TextureFileFormatKtx2 && !features.TextureCompressionASTC_LDR {
+			return errors.New("the GPU does not support ASTC compressed textures, package the content with a profile for this platform")
+		}
+	}
+	return nil
+}
+
 func (g *GPUDevice) generateMipMapsImpl(texId *TextureId, imageFormat GPUFormat, texWidth, texHeight, mipLevels uint32, filter GPUFilter) error {
 	defer tracing.NewRegion("GPUDevice.generateMipMapsImpl").End()
 	cmd := g.beginSingleTimeCommands()
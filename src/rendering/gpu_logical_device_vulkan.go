@@ -42,7 +42,14 @@ func (g *GPULogicalDevice) setupImpl(inst *GPUApplicationInstance, physicalDevic
 		GeometryShader:     vkGeometryShaderValid,
 		TessellationShader: vulkan_const.True,
 		IndependentBlend:   vulkan_const.True,
-		//TextureCompressionASTC_LDR: vk.True,
+	}
+	// Block compressed textures are packaged per platform, so only the formats
+	// that the device has are turned on
+	if physicalDevice.Features.TextureCompressionBC {
+		deviceFeatures.TextureCompressionBC = vulkan_const.True
+	}
+	if physicalDevice.Features.TextureCompressionASTC_LDR {
+		deviceFeatures.TextureCompressionASTC_LDR = vulkan_const.True
 	}
 	if physicalDevice.Features.FillModeNonSolid {
 		deviceFeatures.FillModeNonSolid = vulkan_const.True
@@ -75,6 +75,10 @@ type GPULightInfo struct {
 type LightsForRender struct {
 	Lights     []Light
 	HasChanges bool
+	// Clusters, when set, holds the clustered light assignment that Lights was
+	// selected from for the frame, see [LightClusters]. It is uploaded for the
+	// PBR shaders to find the lights of each fragment.
+	Clusters *LightClusters
 }
 
 type Light struct {
@@ -203,6 +207,40 @@ func (l LightsForRender) directionalShadowLightIndex() int32 {
 	return -1
 }
 
+// SelectLightsAt fills ids with the indexes of the first valid lights, this is
+// for shaders that take a fixed set of lights per draw instance rather than
+// reading the light clusters. Unused slots are set to -1.
+func (l LightsForRender) SelectLightsAt(ids []int32) {
+	for i := range ids {
+		ids[i] = -1
+	}
+	slot := 0
+	for i := range l.Lights {
+		if slot >= len(ids) {
+			break
+		}
+		if l.Lights[i].IsValid() {
+			ids[slot] = int32(i)
+			slot++
+		}
+	}
+}
+
+// AppendLightsAt appends the indexes of the lights that reach the world point
+// in a view of the camera, see [LightClusters.AppendLightsAt]. Every valid
+// light is used when the clusters were not built for the camera.
+func (l LightsForRender) AppendLightsAt(point matrix.Vec3, camera cameras.Camera, ids []int32) []int32 {
+	if l.Clusters != nil && l.Clusters.BuiltFor(camera) {
+		return l.Clusters.AppendLightsAt(point, ids)
+	}
+	for i := range l.Lights {
+		if l.Lights[i].IsValid() {
+			ids = append(ids, int32(i))
+		}
+	}
+	return ids
+}
+
 func (t *LightShadowShaderData) SelectLights(lights LightsForRender) {
 	t.LightIndex = lights.directionalShadowLightIndex()
 }
@@ -252,7 +290,9 @@ func (l *Light) recalculate(camera cameras.Camera) {
 			l.lightSpaceMatrix[i] = matrix.Mat4Multiply(lightView, lightProjection)
 		}
 	case LightTypePoint:
+		l.recalculatePointShadow()
 	case LightTypeSpot:
+		l.recalculateSpotShadow()
 	}
 	l.reset = false
 }
@@ -0,0 +1,421 @@
+/******************************************************************************/
+/* light_clusters.go                                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"slices"
+	"sort"
+	"unsafe"
+
+	"kaijuengine.com/engine/cameras"
+	"kaijuengine.com/engine/graviton"
+	"kaijuengine.com/klib"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+const (
+	LightClusterCountX = 16
+	LightClusterCountY = 9
+	LightClusterCountZ = 24
+	LightClusterCount  = LightClusterCountX * LightClusterCountY * LightClusterCountZ
+	// LightClusterBufferName is the storage buffer block that pbr_lighting.glsl
+	// reads the clusters from. It is shared by every draw in a view rather than
+	// being per instance bound data.
+	LightClusterBufferName = "LightClusterBuffer"
+	// lightClusterBufferSize fits every selected local light touching every
+	// cluster, so the index list can never be cut short.
+	lightClusterBufferSize = int(unsafe.Sizeof(gpuLightClusterHeader{})) +
+		LightClusterCount*int(unsafe.Sizeof(uint32(0))) +
+		LightClusterCount*MaxLocalLights*int(unsafe.Sizeof(int32(0)))
+	// lightClusterCountBits is how many of the low bits of a packed cluster
+	// hold the light count, the rest hold the offset into the index list.
+	lightClusterCountBits = 8
+	// lightAttenuationCutoff is the attenuated intensity at which a local
+	// light is considered to no longer contribute, this is roughly a single
+	// step in an 8-bit color channel.
+	lightAttenuationCutoff = 1.0 / 256.0
+	lightClusterMinDepth   = 0.01
+)
+
+// LightClusters splits a camera's view frustum into a 3D grid of clusters (a
+// screen space tiling with exponential depth slices) and assigns every local
+// light to the clusters its area of influence overlaps. Any number of lights
+// can be supplied to [LightClusters.Build], from those the most relevant
+// [MaxLocalLights] are selected to be uploaded to the GPU and each cluster
+// holds indexes into that selection. The cluster grid and index list are
+// uploaded to a storage buffer ([LightClusterBufferName]) and the PBR shaders
+// loop over the lights of the cluster each fragment is in.
+//
+// Building the clusters is entirely CPU side and does not require a GPU device.
+type LightClusters struct {
+	// Lights are the lights that were selected for the GPU, directional lights
+	// are always first followed by the local lights that cover the most
+	// clusters. This never has more than [MaxLocalLights] entries.
+	Lights      []Light
+	cells       []lightClusterCell
+	indices     []int32
+	sources     []lightClusterSource
+	cellWork    [][]int32
+	order       []int32
+	selected    []int32
+	view        matrix.Mat4
+	viewProj    matrix.Mat4
+	projection  matrix.Mat4
+	eye         matrix.Vec3
+	slices      [LightClusterCountZ + 1]matrix.Float
+	axisSign    matrix.Float
+	near        matrix.Float
+	far         matrix.Float
+	exponential bool
+	built       bool
+}
+
+// gpuLightClusterHeader mirrors the start of the LightClusterBuffer storage
+// buffer in pbr_lighting.glsl, it is followed by the packed clusters and then
+// the light index list.
+type gpuLightClusterHeader struct {
+	// Near, far, the sign of the view direction's Z and 1 for exponential
+	// depth slices
+	Depth            matrix.Vec4
+	DirectionalCount int32
+	Clustered        int32
+	LightCount       int32
+	_                int32
+}
+
+type lightClusterCell struct {
+	bounds matrix.Vec3MinMax
+	offset int32
+	count  int32
+}
+
+type lightClusterSource struct {
+	viewPos   matrix.Vec3
+	radius    matrix.Float
+	distance  matrix.Float
+	coverage  int32
+	global    bool
+	selectIdx int32
+}
+
+// Range returns the distance at which a point or spot light's attenuated
+// intensity falls below the visible threshold. Directional lights, and local
+// lights that never attenuate, report an infinite range.
+func (l *Light) Range() matrix.Float {
+	if l.lightType == LightTypeDirectional {
+		return matrix.Inf(1)
+	}
+	brightest := max(l.diffuse.X(), l.diffuse.Y(), l.diffuse.Z(), 1) * matrix.Float(max(l.intensity, 0))
+	if brightest <= 0 {
+		return 0
+	}
+	// Solves intensity / (c + l*d + q*d^2) = cutoff for d, matching the
+	// pbrDistanceAttenuation function in pbr_lighting.glsl
+	constant := matrix.Float(l.constant) - brightest/lightAttenuationCutoff
+	linear, quadratic := matrix.Float(l.linear), matrix.Float(l.quadratic)
+	if quadratic > 0 {
+		disc := linear*linear - 4*quadratic*constant
+		if disc < 0 {
+			return 0
+		}
+		return max(0, (-linear+matrix.Sqrt(disc))/(2*quadratic))
+	}
+	if linear > 0 {
+		return max(0, -constant/linear)
+	}
+	return matrix.Inf(1)
+}
+
+// Build assigns the lights to the clusters of the camera's view frustum. The
+// lights slice is not retained, the selected lights are copied into
+// [LightClusters.Lights].
+func (c *LightClusters) Build(camera cameras.Camera, lights []Light) {
+	defer tracing.NewRegion("LightClusters.Build").End()
+	c.buildCells(camera)
+	c.assignLights(lights)
+	c.selectLights(lights)
+	c.flatten()
+	c.built = true
+}
+
+// SameSelection reports if both clusters selected the same lights, in the
+// same order, from the lights they were built with. This is only meaningful
+// when both were built from the same light collection.
+func (c *LightClusters) SameSelection(other *LightClusters) bool {
+	return other != nil && slices.Equal(c.selected, other.selected)
+}
+
+// BuiltFor reports if the clusters were built for the camera's current view
+// and projection, the clusters can't be used to light any other view.
+func (c *LightClusters) BuiltFor(camera cameras.Camera) bool {
+	return c.built && camera != nil &&
+		c.view.Equals(camera.View()) && c.projection.Equals(camera.Projection())
+}
+
+// ClusterIndex returns the index of the cluster that contains the world space
+// point, the second return is false if the point is outside of the frustum.
+func (c *LightClusters) ClusterIndex(point matrix.Vec3) (int, bool) {
+	if !c.built {
+		return -1, false
+	}
+	depth := c.depth(c.view.TransformPoint(point))
+	if depth < c.slices[0] || depth > c.slices[LightClusterCountZ] {
+		return -1, false
+	}
+	// This matches the slice lookup of pbrClusterLights in pbr_lighting.glsl
+	z := sort.Search(LightClusterCountZ, func(i int) bool {
+		return c.slices[i+1] >= depth
+	})
+	clip := matrix.Mat4MultiplyVec4(c.viewProj,
+		matrix.NewVec4(point.X(), point.Y(), point.Z(), 1))
+	if matrix.Abs(clip.W()) <= matrix.FloatSmallestNonzero {
+		return -1, false
+	}
+	u := (clip.X()/clip.W() + 1) * 0.5
+	v := (clip.Y()/clip.W() + 1) * 0.5
+	if u < 0 || u > 1 || v < 0 || v > 1 {
+		return -1, false
+	}
+	x := min(int(u*LightClusterCountX), LightClusterCountX-1)
+	y := min(int(v*LightClusterCountY), LightClusterCountY-1)
+	return clusterIndex(x, y, z), true
+}
+
+// ClusterLights returns the indexes (into [LightClusters.Lights]) of the local
+// lights that influence the cluster. Directional lights are not listed as they
+// influence every cluster.
+func (c *LightClusters) ClusterLights(cluster int) []int32 {
+	if cluster < 0 || cluster >= len(c.cells) {
+		return nil
+	}
+	cell := &c.cells[cluster]
+	return c.indices[cell.offset : cell.offset+cell.count]
+}
+
+// AppendLightsAt appends the indexes (into [LightClusters.Lights]) of every
+// light that reaches the world space point, this is the CPU equivalent of the
+// loop over pbrClusterLights in pbr_lighting.glsl. Directional lights come
+// first, followed by the local lights of the point's cluster.
+func (c *LightClusters) AppendLightsAt(point matrix.Vec3, ids []int32) []int32 {
+	for i := range c.Lights {
+		if c.Lights[i].IsValid() && c.Lights[i].lightType == LightTypeDirectional {
+			ids = append(ids, int32(i))
+		}
+	}
+	if cluster, ok := c.ClusterIndex(point); ok {
+		ids = append(ids, c.ClusterLights(cluster)...)
+	}
+	return ids
+}
+
+func clusterIndex(x, y, z int) int {
+	return x + y*LightClusterCountX + z*LightClusterCountX*LightClusterCountY
+}
+
+// depth returns the distance of a view space position along the view
+// direction, this works for both left and right handed view matrices.
+func (c *LightClusters) depth(viewPos matrix.Vec3) matrix.Float {
+	return viewPos.Z() * c.axisSign
+}
+
+func (c *LightClusters) buildCells(camera cameras.Camera) {
+	view := camera.View()
+	projection := camera.Projection()
+	if c.built && view.Equals(c.view) && projection.Equals(c.projection) {
+		return
+	}
+	c.view = view
+	c.projection = projection
+	c.eye = view.Inverted().ExtractPosition()
+	c.viewProj = matrix.Mat4Multiply(view, projection)
+	corners := graviton.FrustumExtractCorners(view, projection)
+	var viewCorners [8]matrix.Vec3
+	for i := range corners {
+		viewCorners[i] = view.TransformPoint(corners[i].AsVec3())
+	}
+	nearCenter := viewCorners[0].Add(viewCorners[2]).Scale(0.5)
+	farCenter := viewCorners[4].Add(viewCorners[6]).Scale(0.5)
+	c.axisSign = 1
+	if farCenter.Z() < nearCenter.Z() {
+		c.axisSign = -1
+	}
+	c.near = c.depth(nearCenter)
+	c.far = c.depth(farCenter)
+	// Exponential slices keep the clusters close to the camera small, but they
+	// are not possible for orthographic cameras that start at (or behind) 0
+	c.exponential = !camera.IsOrthographic() && c.near >= lightClusterMinDepth
+	for i := range c.slices {
+		t := matrix.Float(i) / LightClusterCountZ
+		if c.exponential {
+			c.slices[i] = c.near * matrix.Pow(c.far/c.near, t)
+		} else {
+			c.slices[i] = matrix.Lerp(c.near, c.far, t)
+		}
+	}
+	if len(c.cells) != LightClusterCount {
+		c.cells = make([]lightClusterCell, LightClusterCount)
+	}
+	span := c.far - c.near
+	if span <= 0 {
+		span = 1
+	}
+	quadPoint := func(quad []matrix.Vec3, u, v matrix.Float) matrix.Vec3 {
+		bottom := matrix.Vec3Lerp(quad[0], quad[1], u)
+		top := matrix.Vec3Lerp(quad[3], quad[2], u)
+		return matrix.Vec3Lerp(bottom, top, v)
+	}
+	for z := range LightClusterCountZ {
+		s0 := (c.slices[z] - c.near) / span
+		s1 := (c.slices[z+1] - c.near) / span
+		for y := range LightClusterCountY {
+			v0 := matrix.Float(y) / LightClusterCountY
+			v1 := matrix.Float(y+1) / LightClusterCountY
+			for x := range LightClusterCountX {
+				u0 := matrix.Float(x) / LightClusterCountX
+				u1 := matrix.Float(x+1) / LightClusterCountX
+				bounds := matrix.NewVec3MinMax()
+				for _, uv := range [4][2]matrix.Float{{u0, v0}, {u1, v0}, {u1, v1}, {u0, v1}} {
+					n := quadPoint(viewCorners[:4], uv[0], uv[1])
+					f := quadPoint(viewCorners[4:], uv[0], uv[1])
+					for _, s := range [2]matrix.Float{s0, s1} {
+						p := matrix.Vec3Lerp(n, f, s)
+						bounds.Min = matrix.Vec3Min(bounds.Min, p)
+						bounds.Max = matrix.Vec3Max(bounds.Max, p)
+					}
+				}
+				c.cells[clusterIndex(x, y, z)].bounds = bounds
+			}
+		}
+	}
+}
+
+func (c *LightClusters) assignLights(lights []Light) {
+	if len(c.cellWork) != len(c.cells) {
+		c.cellWork = make([][]int32, len(c.cells))
+	}
+	for i := range c.cellWork {
+		c.cellWork[i] = c.cellWork[i][:0]
+	}
+	c.sources = klib.WipeSlice(c.sources)
+	for i := range lights {
+		l := &lights[i]
+		src := lightClusterSource{selectIdx: -1}
+		if !l.IsValid() || l.lightType == LightTypeDirectional {
+			src.global = l.IsValid()
+			c.sources = append(c.sources, src)
+			continue
+		}
+		src.viewPos = c.view.TransformPoint(l.position)
+		src.radius = l.Range()
+		src.distance = c.eye.Distance(l.position)
+		if src.radius > 0 {
+			c.assignLight(int32(i), &src)
+		}
+		c.sources = append(c.sources, src)
+	}
+}
+
+func (c *LightClusters) assignLight(lightIdx int32, src *lightClusterSource) {
+	// Lights are culled against whole depth slices first so only the clusters
+	// of the slices the light's sphere reaches are tested
+	depth := c.depth(src.viewPos)
+	minDepth := depth - src.radius
+	maxDepth := depth + src.radius
+	if maxDepth < c.slices[0] || minDepth > c.slices[LightClusterCountZ] {
+		return
+	}
+	r2 := src.radius * src.radius
+	for z := range LightClusterCountZ {
+		if c.slices[z+1] < minDepth || c.slices[z] > maxDepth {
+			continue
+		}
+		for y := range LightClusterCountY {
+			for x := range LightClusterCountX {
+				idx := clusterIndex(x, y, z)
+				b := &c.cells[idx].bounds
+				closest := matrix.Vec3Max(b.Min, matrix.Vec3Min(b.Max, src.viewPos))
+				if closest.Subtract(src.viewPos).LengthSquared() > r2 {
+					continue
+				}
+				c.cellWork[idx] = append(c.cellWork[idx], lightIdx)
+				src.coverage++
+			}
+		}
+	}
+}
+
+func (c *LightClusters) selectLights(lights []Light) {
+	c.order = c.order[:0]
+	for i := range c.sources {
+		if c.sources[i].global || c.sources[i].coverage > 0 {
+			c.order = append(c.order, int32(i))
+		}
+	}
+	sort.SliceStable(c.order, func(i, j int) bool {
+		a, b := &c.sources[c.order[i]], &c.sources[c.order[j]]
+		if a.global != b.global {
+			return a.global
+		}
+		if a.coverage != b.coverage {
+			return a.coverage > b.coverage
+		}
+		return a.distance < b.distance
+	})
+	c.order = c.order[:min(len(c.order), MaxLocalLights)]
+	c.selected = append(c.selected[:0], c.order...)
+	c.Lights = klib.WipeSlice(c.Lights)
+	for i := range c.order {
+		c.sources[c.order[i]].selectIdx = int32(len(c.Lights))
+		c.Lights = append(c.Lights, lights[c.order[i]])
+	}
+}
+
+func (c *LightClusters) flatten() {
+	c.indices = c.indices[:0]
+	for i := range c.cells {
+		cell := &c.cells[i]
+		cell.offset = int32(len(c.indices))
+		for _, lightIdx := range c.cellWork[i] {
+			if idx := c.sources[lightIdx].selectIdx; idx >= 0 {
+				c.indices = append(c.indices, idx)
+			}
+		}
+		cell.count = int32(len(c.indices)) - cell.offset
+	}
+}
+
+// appendGPUData appends the contents of the LightClusterBuffer storage buffer
+// for a view of the camera to data. When the clusters were not built for the
+// camera the header tells the shaders to consider each of the lightCount
+// lights instead, only the header is written in that case.
+func (c *LightClusters) appendGPUData(data []byte, camera cameras.Camera, lightCount int) []byte {
+	header := gpuLightClusterHeader{LightCount: int32(lightCount)}
+	if c == nil || !c.BuiltFor(camera) {
+		return append(data, klib.StructToByteArray(header)...)
+	}
+	header.Depth = matrix.NewVec4(c.near, c.far, c.axisSign, 0)
+	if c.exponential {
+		header.Depth.SetW(1)
+	}
+	for i := range c.Lights {
+		if c.Lights[i].IsValid() && c.Lights[i].lightType == LightTypeDirectional {
+			header.DirectionalCount++
+		}
+	}
+	header.Clustered = 1
+	header.LightCount = int32(len(c.Lights))
+	data = append(data, klib.StructToByteArray(header)...)
+	for i := range c.cells {
+		packed := uint32(c.cells[i].offset)<<lightClusterCountBits | uint32(c.cells[i].count)
+		data = append(data, klib.StructToByteArray(packed)...)
+	}
+	if len(c.indices) > 0 {
+		data = append(data, klib.StructSliceToByteArray(c.indices)...)
+	}
+	return data
+}
@@ -0,0 +1,202 @@
+/******************************************************************************/
+/* light_clusters_test.go                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"testing"
+	"unsafe"
+
+	"kaijuengine.com/engine/cameras"
+	"kaijuengine.com/matrix"
+)
+
+func testClusterCamera() cameras.Camera {
+	return cameras.NewStandardCamera(1280, 720, 1280, 720, matrix.Vec3Zero())
+}
+
+func testClusterPointLight(position matrix.Vec3) Light {
+	return Light{
+		device:    &GPUDevice{},
+		lightType: LightTypePoint,
+		position:  position,
+		diffuse:   matrix.Vec3One(),
+		intensity: 1,
+		constant:  1,
+		linear:    0.7,
+		quadratic: 1.8,
+	}
+}
+
+func TestLightRangeMatchesAttenuationCutoff(t *testing.T) {
+	light := testClusterPointLight(matrix.Vec3Zero())
+	r := light.Range()
+	if r <= 0 || matrix.IsInf(r, 1) {
+		t.Fatalf("point light range = %f, want finite positive", r)
+	}
+	attenuation := light.intensity / (light.constant + light.linear*r + light.quadratic*r*r)
+	if got := attenuation; !matrix.ApproxTo(got, lightAttenuationCutoff, 0.0001) {
+		t.Fatalf("contribution at range = %f, want %f", got, lightAttenuationCutoff)
+	}
+	directional := Light{device: &GPUDevice{}, lightType: LightTypeDirectional}
+	if !matrix.IsInf(directional.Range(), 1) {
+		t.Fatal("directional light range should be infinite")
+	}
+}
+
+func TestLightClustersAssignLocalLightToItsCluster(t *testing.T) {
+	cam := testClusterCamera()
+	near := cam.Position().Add(cam.Forward().Scale(5))
+	far := cam.Position().Add(cam.Forward().Scale(100))
+	var clusters LightClusters
+	clusters.Build(cam, []Light{testClusterPointLight(near)})
+	if len(clusters.Lights) != 1 {
+		t.Fatalf("selected %d lights, want 1", len(clusters.Lights))
+	}
+	nearCluster, ok := clusters.ClusterIndex(near)
+	if !ok {
+		t.Fatal("light position should be inside the frustum")
+	}
+	if got := clusters.ClusterLights(nearCluster); len(got) != 1 || got[0] != 0 {
+		t.Fatalf("near cluster lights = %v, want [0]", got)
+	}
+	farCluster, ok := clusters.ClusterIndex(far)
+	if !ok {
+		t.Fatal("far point should be inside the frustum")
+	}
+	if got := clusters.ClusterLights(farCluster); len(got) != 0 {
+		t.Fatalf("far cluster lights = %v, want none", got)
+	}
+	if _, ok := clusters.ClusterIndex(cam.Position().Subtract(cam.Forward())); ok {
+		t.Fatal("point behind the camera should not be inside a cluster")
+	}
+}
+
+func TestLightClustersSelectsVisibleLightsBeyondLocalLimit(t *testing.T) {
+	cam := testClusterCamera()
+	lights := make([]Light, 0, MaxLocalLights*3)
+	// Lights behind the camera don't touch any cluster and must be dropped
+	for i := range MaxLocalLights {
+		pos := cam.Position().Subtract(cam.Forward().Scale(50 + matrix.Float(i)))
+		lights = append(lights, testClusterPointLight(pos))
+	}
+	for i := range MaxLocalLights * 2 {
+		pos := cam.Position().Add(cam.Forward().Scale(4 + matrix.Float(i)*2))
+		lights = append(lights, testClusterPointLight(pos))
+	}
+	lights = append(lights, Light{device: &GPUDevice{}, lightType: LightTypeDirectional, intensity: 1})
+	var clusters LightClusters
+	clusters.Build(cam, lights)
+	if len(clusters.Lights) != MaxLocalLights {
+		t.Fatalf("selected %d lights, want %d", len(clusters.Lights), MaxLocalLights)
+	}
+	if clusters.Lights[0].Type() != LightTypeDirectional {
+		t.Fatal("directional lights should be selected first")
+	}
+	for i := 1; i < len(clusters.Lights); i++ {
+		if clusters.depth(clusters.view.TransformPoint(clusters.Lights[i].position)) <= 0 {
+			t.Fatalf("light %d behind the camera was selected", i)
+		}
+	}
+	for i := range LightClusterCountX * LightClusterCountY * LightClusterCountZ {
+		for _, idx := range clusters.ClusterLights(i) {
+			if idx < 0 || int(idx) >= len(clusters.Lights) {
+				t.Fatalf("cluster %d references light %d outside of the selection", i, idx)
+			}
+		}
+	}
+}
+
+func TestLightClustersAppendLightsAt(t *testing.T) {
+	cam := testClusterCamera()
+	near := cam.Position().Add(cam.Forward().Scale(5))
+	far := cam.Position().Add(cam.Forward().Scale(100))
+	var clusters LightClusters
+	clusters.Build(cam, []Light{
+		testClusterPointLight(near),
+		{device: &GPUDevice{}, lightType: LightTypeDirectional, diffuse: matrix.Vec3One(), intensity: 1},
+	})
+	ids := clusters.AppendLightsAt(near, nil)
+	if len(ids) != 2 || clusters.Lights[ids[0]].Type() != LightTypeDirectional ||
+		clusters.Lights[ids[1]].Type() != LightTypePoint {
+		t.Fatalf("lights at the point light = %v, want the directional then the point light", ids)
+	}
+	ids = clusters.AppendLightsAt(far, ids[:0])
+	if len(ids) != 1 || clusters.Lights[ids[0]].Type() != LightTypeDirectional {
+		t.Fatalf("lights far from the point light = %v, want only the directional light", ids)
+	}
+}
+
+func TestLightClustersSameSelection(t *testing.T) {
+	cam := testClusterCamera()
+	lights := []Light{testClusterPointLight(cam.Position().Add(cam.Forward().Scale(5)))}
+	var a, b LightClusters
+	a.Build(cam, lights)
+	b.Build(cam, lights)
+	if !a.SameSelection(&b) {
+		t.Fatal("identical builds should have the same selection")
+	}
+	lights[0].position = cam.Position().Subtract(cam.Forward().Scale(50))
+	b.Build(cam, lights)
+	if a.SameSelection(&b) {
+		t.Fatal("selection should change once the light leaves the frustum")
+	}
+}
+
+func TestLightClustersBuiltFor(t *testing.T) {
+	cam := testClusterCamera()
+	var clusters LightClusters
+	if clusters.BuiltFor(cam) {
+		t.Fatal("clusters that were never built should not match a camera")
+	}
+	clusters.Build(cam, nil)
+	if !clusters.BuiltFor(cam) {
+		t.Fatal("clusters should match the camera they were built for")
+	}
+	cam.SetPosition(cam.Position().Add(matrix.NewVec3(1, 0, 0)))
+	if clusters.BuiltFor(cam) {
+		t.Fatal("moving the camera should require a rebuild")
+	}
+}
+
+func TestLightClustersGPUData(t *testing.T) {
+	cam := testClusterCamera()
+	near := cam.Position().Add(cam.Forward().Scale(5))
+	headerSize := int(unsafe.Sizeof(gpuLightClusterHeader{}))
+	var unbuilt *LightClusters
+	data := unbuilt.appendGPUData(nil, cam, 3)
+	if len(data) != headerSize {
+		t.Fatalf("unclustered data is %d bytes, want only the %d byte header", len(data), headerSize)
+	}
+	header := *(*gpuLightClusterHeader)(unsafe.Pointer(&data[0]))
+	if header.Clustered != 0 || header.LightCount != 3 {
+		t.Fatalf("unclustered header = %+v, want every one of the 3 lights", header)
+	}
+	var clusters LightClusters
+	clusters.Build(cam, []Light{
+		testClusterPointLight(near),
+		{device: &GPUDevice{}, lightType: LightTypeDirectional, intensity: 1},
+	})
+	data = clusters.appendGPUData(data[:0], cam, 2)
+	if len(data) > lightClusterBufferSize {
+		t.Fatalf("cluster data is %d bytes, larger than the %d byte buffer", len(data), lightClusterBufferSize)
+	}
+	header = *(*gpuLightClusterHeader)(unsafe.Pointer(&data[0]))
+	if header.Clustered != 1 || header.DirectionalCount != 1 || header.LightCount != 2 {
+		t.Fatalf("clustered header = %+v", header)
+	}
+	cluster, ok := clusters.ClusterIndex(near)
+	if !ok {
+		t.Fatal("light position should be inside the frustum")
+	}
+	cells := unsafe.Slice((*uint32)(unsafe.Pointer(&data[headerSize])), LightClusterCount)
+	indices := unsafe.Slice((*int32)(unsafe.Pointer(&data[headerSize+LightClusterCount*4])),
+		(len(data)-headerSize-LightClusterCount*4)/4)
+	offset, count := cells[cluster]>>lightClusterCountBits, cells[cluster]&(1<<lightClusterCountBits-1)
+	if count != 1 || int(offset) >= len(indices) || clusters.Lights[indices[offset]].Type() != LightTypePoint {
+		t.Fatalf("cluster %d packs offset %d count %d, want the point light", cluster, offset, count)
+	}
+}
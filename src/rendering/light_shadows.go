@@ -0,0 +1,65 @@
+/******************************************************************************/
+/* light_shadows.go                                                           */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import "kaijuengine.com/matrix"
+
+// pointShadowFaces are the look directions and up vectors of the cube map
+// faces, in the order the depth_cube geometry shader writes its layers.
+var pointShadowFaces = [cubeMapSides]struct{ look, up matrix.Vec3 }{
+	{matrix.Vec3Right(), matrix.Vec3Down()},
+	{matrix.Vec3Left(), matrix.Vec3Down()},
+	{matrix.Vec3Up(), matrix.Vec3Backward()},
+	{matrix.Vec3Down(), matrix.Vec3Forward()},
+	{matrix.Vec3Backward(), matrix.Vec3Down()},
+	{matrix.Vec3Forward(), matrix.Vec3Down()},
+}
+
+// ShadowFaceCount returns the number of shadow map faces the light renders,
+// point lights render a full cube while every other light renders one face.
+func (l *Light) ShadowFaceCount() int {
+	if l.lightType == LightTypePoint {
+		return cubeMapSides
+	}
+	return 1
+}
+
+// ShadowMatrix returns the view projection matrix used to render and sample
+// the given shadow map face of the light.
+func (l *Light) ShadowMatrix(face int) matrix.Mat4 {
+	return l.lightSpaceMatrix[face]
+}
+
+// spotShadowFOV returns the vertical field of view (in radians) that exactly
+// contains the outer cone of the spot light.
+func (l *Light) spotShadowFOV() matrix.Float {
+	outer := matrix.Clamp(matrix.Float(l.outerCutoff), -1, 1)
+	fov := matrix.Acos(outer) * 2
+	return matrix.Clamp(fov, matrix.Deg2Rad(1), matrix.Deg2Rad(179))
+}
+
+func (l *Light) recalculateSpotShadow() {
+	dir := l.direction.Normal()
+	up := matrix.Vec3Up()
+	if matrix.Abs(matrix.Vec3Dot(dir, up)) > 0.999 {
+		up = matrix.Vec3Backward()
+	}
+	view := matrix.Mat4LookAt(l.position, l.position.Add(dir), up)
+	projection := matrix.Mat4Identity()
+	projection.Perspective(l.spotShadowFOV(), 1, l.camera.NearPlane(), l.camera.FarPlane())
+	l.lightSpaceMatrix[0] = matrix.Mat4Multiply(view, projection)
+}
+
+func (l *Light) recalculatePointShadow() {
+	projection := matrix.Mat4Identity()
+	projection.Perspective(matrix.Deg2Rad(90), 1, l.camera.NearPlane(), l.camera.FarPlane())
+	for i := range pointShadowFaces {
+		face := &pointShadowFaces[i]
+		view := matrix.Mat4LookAt(l.position, l.position.Add(face.look), face.up)
+		l.lightSpaceMatrix[i] = matrix.Mat4Multiply(view, projection)
+	}
+}
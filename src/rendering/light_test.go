@@ -184,3 +184,37 @@ func TestLightMinMaxFromCorners(t *testing.T) {
 		t.Fatalf("min/max = %+v", mm)
 	}
 }
+
+func TestSpotShadowMatrixContainsCone(t *testing.T) {
+	light := NewLight(&GPUDevice{}, nil, nil, LightTypeSpot)
+	light.SetPosition(matrix.NewVec3(1, 2, 3))
+	light.SetDirection(matrix.Vec3Right())
+	light.recalculate(cameras.NewStandardCamera(100, 100, 100, 100, matrix.Vec3Zero()))
+	m := light.ShadowMatrix(0)
+	inside := matrix.Mat4MultiplyVec4(m, matrix.NewVec4(3, 2, 3, 1))
+	ndc := inside.AsVec3().Scale(1 / inside.W())
+	if matrix.Abs(ndc.X()) > 0.001 || matrix.Abs(ndc.Y()) > 0.001 || inside.W() <= 0 {
+		t.Fatalf("point along the spot direction projected to %v, want the center", ndc)
+	}
+	behind := matrix.Mat4MultiplyVec4(m, matrix.NewVec4(-1, 2, 3, 1))
+	if behind.W() > 0 {
+		t.Fatal("point behind the spot light should not be in front of the shadow camera")
+	}
+}
+
+func TestPointShadowMatricesCoverEachFace(t *testing.T) {
+	light := NewLight(&GPUDevice{}, nil, nil, LightTypePoint)
+	light.SetPosition(matrix.NewVec3(0, 5, 0))
+	light.recalculate(cameras.NewStandardCamera(100, 100, 100, 100, matrix.Vec3Zero()))
+	if light.ShadowFaceCount() != cubeMapSides {
+		t.Fatalf("point light faces = %d, want %d", light.ShadowFaceCount(), cubeMapSides)
+	}
+	for i, face := range pointShadowFaces {
+		p := light.position.Add(face.look.Scale(2))
+		clip := matrix.Mat4MultiplyVec4(light.ShadowMatrix(i), matrix.NewVec4(p.X(), p.Y(), p.Z(), 1))
+		ndc := clip.AsVec3().Scale(1 / clip.W())
+		if clip.W() <= 0 || matrix.Abs(ndc.X()) > 0.001 || matrix.Abs(ndc.Y()) > 0.001 {
+			t.Fatalf("face %d did not center its look direction, ndc %v w %f", i, ndc, clip.W())
+		}
+	}
+}
@@ -140,7 +140,11 @@ func (d *MaterialData) CompileExt(assets assets.Database, device *GPUDevice, cop
 	}
 	c.shaderInfo = sd.Compile()
 	lp := device.LogicalDevice
-	if pass, ok := lp.renderPassCache[rp.Name]; !ok {
+	if device.IsNull() {
+		// The null device has no render passes or pipelines to build, only
+		// the pass identity is needed to group and sort the drawings
+		c.renderPass = nullRenderPass(device, &rp)
+	} else if pass, ok := lp.renderPassCache[rp.Name]; !ok {
 		rpc := rp.Compile(device)
 		if p, err := rpc.ConstructRenderPass(device); err == nil {
 			lp.renderPassCache[rp.Name] = p
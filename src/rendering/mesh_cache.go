@@ -73,6 +73,9 @@ func (m *MeshCache) RemoveMesh(key string) {
 	if mesh.MeshId.IsValid() {
 		m.pendingFree = append(m.pendingFree, mesh.MeshId)
 	}
+	if m.device.IsNull() {
+		m.device.null.destroyMesh(mesh)
+	}
 	m.removePendingMeshLocked(mesh)
 	delete(m.meshes, key)
 }
@@ -137,6 +140,14 @@ func (m *MeshCache) ProcessPending() {
 	defer tracing.NewRegion("MeshCache.CreatePending").End()
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
+	if m.device.IsNull() {
+		for _, mesh := range m.pendingMeshes {
+			m.device.null.createMesh(mesh)
+		}
+		m.pendingFree = klib.WipeSlice(m.pendingFree)
+		m.pendingMeshes = klib.WipeSlice(m.pendingMeshes)
+		return
+	}
 	for i := range m.pendingFree {
 		m.device.destroyMeshHandle(m.pendingFree[i])
 	}
@@ -149,6 +160,9 @@ func (m *MeshCache) ProcessPending() {
 
 func (m *MeshCache) Destroy() {
 	m.pendingMeshes = klib.WipeSlice(m.pendingMeshes)
+	if m.device.IsNull() {
+		m.device.null.destroyMeshes()
+	}
 	for _, mesh := range m.meshes {
 		if mesh.MeshId.IsValid() {
 			m.device.destroyMeshHandle(mesh.MeshId)
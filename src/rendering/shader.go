@@ -123,6 +123,19 @@ func (sd *ShaderDataCompiled) Stride() uint32 {
 	return stride
 }
 
+// lightClusterBinding returns the binding of the light cluster storage buffer
+// ([LightClusterBufferName]) or -1 if the shader doesn't read the clusters.
+func (sd *ShaderDataCompiled) lightClusterBinding() int {
+	for i := range sd.LayoutGroups {
+		for j := range sd.LayoutGroups[i].Layouts {
+			if sd.LayoutGroups[i].Layouts[j].IsLightClusters() {
+				return sd.LayoutGroups[i].Layouts[j].Binding
+			}
+		}
+	}
+	return -1
+}
+
 func (sd *ShaderDataCompiled) ToDescriptorSetLayoutStructure() DescriptorSetLayoutStructure {
 	defer tracing.NewRegion("Shader.ToDescriptorSetLayoutStructure").End()
 	structure := DescriptorSetLayoutStructure{}
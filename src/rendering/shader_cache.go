@@ -96,6 +96,14 @@ func (s *ShaderCache) CreatePending() {
 	defer tracing.NewRegion("ShaderCache.CreatePending").End()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
+	if s.device.IsNull() {
+		for _, shader := range s.pendingShaders {
+			s.device.null.createShader(shader)
+		}
+		s.pendingDestroy = klib.WipeSlice(s.pendingDestroy)
+		s.pendingShaders = klib.WipeSlice(s.pendingShaders)
+		return
+	}
 	for i := range s.pendingDestroy {
 		s.device.DestroyShaderHandle(s.pendingDestroy[i])
 	}
@@ -110,6 +118,9 @@ func (s *ShaderCache) Destroy() {
 	defer tracing.NewRegion("ShaderCache.Destroy").End()
 	s.pendingDestroy = klib.WipeSlice(s.pendingDestroy)
 	s.pendingShaders = klib.WipeSlice(s.pendingShaders)
+	if s.device.IsNull() {
+		s.device.null.destroyShaders()
+	}
 	for _, shader := range s.shaders {
 		s.destroyShaderTree(shader)
 	}
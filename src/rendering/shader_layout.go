@@ -70,7 +70,7 @@ func fieldSize(fieldType, fieldName string) int {
 		size = mat4Size
 	case "vec4":
 		size = vec4Size
-	case "ivec4":
+	case "ivec4", "uvec4":
 		size = vec4Size
 	case "vec3":
 		size = vec4Size // Using vec4Size for alignment
@@ -106,6 +106,10 @@ func (l *ShaderLayout) FullName() string {
 
 func (l *ShaderLayout) IsBuffer() bool {
 	// Ignore the global uniform buffer for now
+	if l.IsLightClusters() {
+		// Shared by every draw in the view, not bound per instance
+		return false
+	}
 	if l.Type == "StorageBuffer" || l.Source == "buffer" {
 		return true
 	}
@@ -115,6 +119,12 @@ func (l *ShaderLayout) IsBuffer() bool {
 	return len(l.Fields) > 0
 }
 
+// IsLightClusters reports if the layout is the light cluster storage buffer
+// that is written once for each view, see [LightClusters].
+func (l *ShaderLayout) IsLightClusters() bool {
+	return l.Source == "buffer" && l.Type == LightClusterBufferName
+}
+
 func (l *ShaderLayout) Stride() int {
 	stride := 0
 	for i := range l.Fields {
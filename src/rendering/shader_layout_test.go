@@ -22,6 +22,7 @@ func TestShaderLayoutFieldSize(t *testing.T) {
 		{"mat3", "normal", vec4Size},
 		{"vec4", "color", vec4Size},
 		{"ivec4", "indexes", vec4Size},
+		{"uvec4", "packed", vec4Size},
 		{"vec3", "position", vec4Size},
 		{"vec2", "uv", vec2Size},
 		{"float", "weight", floatSize},
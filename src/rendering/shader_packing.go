@@ -0,0 +1,97 @@
+/******************************************************************************/
+/* shader_packing.go                                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"math"
+
+	"kaijuengine.com/matrix"
+)
+
+// PackUnorm4x8 packs a color into a single uint32 the same way the GLSL
+// packUnorm4x8 function does (red in the lowest byte). It is used for
+// instance data that has to fit in fewer vertex attributes than plain floats
+// would need, the shader reads it back with unpackUnorm4x8.
+func PackUnorm4x8(c matrix.Color) uint32 {
+	pack := func(v float32) uint32 {
+		return uint32(math.Round(float64(min(max(v, 0), 1)) * 255))
+	}
+	return pack(c.R()) | pack(c.G())<<8 | pack(c.B())<<16 | pack(c.A())<<24
+}
+
+// UnpackUnorm4x8 is the inverse of [PackUnorm4x8]
+func UnpackUnorm4x8(v uint32) matrix.Color {
+	return matrix.Color{
+		float32(v&0xff) / 255,
+		float32((v>>8)&0xff) / 255,
+		float32((v>>16)&0xff) / 255,
+		float32((v>>24)&0xff) / 255,
+	}
+}
+
+// PackHalf2x16 packs two floats as half precision floats into a single uint32
+// the same way the GLSL packHalf2x16 function does (a in the low 16 bits)
+func PackHalf2x16(a, b float32) uint32 {
+	return uint32(float32ToHalf(a)) | uint32(float32ToHalf(b))<<16
+}
+
+// UnpackHalf2x16 is the inverse of [PackHalf2x16]
+func UnpackHalf2x16(v uint32) (float32, float32) {
+	return halfToFloat32(uint16(v)), halfToFloat32(uint16(v >> 16))
+}
+
+// float32ToHalf converts to IEEE 754 half precision, rounding to the nearest
+// value. Values beyond the half range saturate to infinity.
+func float32ToHalf(f float32) uint16 {
+	b := math.Float32bits(f)
+	sign := uint16(b>>16) & 0x8000
+	exp := int32(b>>23)&0xff - 127
+	mant := b & 0x7fffff
+	switch {
+	case exp == 128:
+		if mant != 0 {
+			return sign | 0x7e00
+		}
+		return sign | 0x7c00
+	case exp > 15:
+		return sign | 0x7c00
+	case exp >= -14:
+		half := uint32(exp+15)<<10 | mant>>13
+		if mant&0x1fff >= 0x1000 {
+			// Carrying into the exponent is correct, it rounds up to the
+			// next power of two (or infinity)
+			half++
+		}
+		return sign | uint16(half)
+	case exp >= -25:
+		mant |= 0x800000
+		shift := uint32(-exp - 14 + 13)
+		half := mant >> shift
+		if mant&(1<<(shift-1)) != 0 {
+			half++
+		}
+		return sign | uint16(half)
+	}
+	return sign
+}
+
+func halfToFloat32(h uint16) float32 {
+	sign := uint32(h&0x8000) << 16
+	exp := uint32(h>>10) & 0x1f
+	mant := uint32(h & 0x3ff)
+	switch exp {
+	case 0:
+		v := float32(mant) / (1 << 24)
+		if sign != 0 {
+			return -v
+		}
+		return v
+	case 0x1f:
+		return math.Float32frombits(sign | 0x7f800000 | mant<<13)
+	}
+	return math.Float32frombits(sign | (exp+127-15)<<23 | mant<<13)
+}
@@ -0,0 +1,50 @@
+/******************************************************************************/
+/* shader_packing_test.go                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"math"
+	"testing"
+
+	"kaijuengine.com/matrix"
+)
+
+func TestPackUnorm4x8(t *testing.T) {
+	c := matrix.Color{1, 0.5, 0, 2}
+	packed := PackUnorm4x8(c)
+	if packed != 0xff0080ff {
+		t.Fatalf("PackUnorm4x8(%v) = %#x, want %#x", c, packed, uint32(0xff0080ff))
+	}
+	got := UnpackUnorm4x8(packed)
+	want := matrix.Color{1, 128.0 / 255.0, 0, 1}
+	if !got.Equals(want) {
+		t.Fatalf("UnpackUnorm4x8(%#x) = %v, want %v", packed, got, want)
+	}
+}
+
+func TestPackHalf2x16(t *testing.T) {
+	cases := []struct {
+		in, want float32
+	}{
+		{0, 0},
+		{1, 1},
+		{-2.5, -2.5},
+		{0.1, 0.099975586},
+		{65504, 65504},
+		{1e-6, 1.013279e-06},
+		{1e9, float32(math.Inf(1))},
+	}
+	for _, c := range cases {
+		a, b := UnpackHalf2x16(PackHalf2x16(c.in, -c.in))
+		if a != c.want || b != -c.want {
+			t.Fatalf("half round trip of %g = (%g, %g), want (%g, %g)", c.in, a, b, c.want, -c.want)
+		}
+	}
+	if lo, hi := PackHalf2x16(1, 0)&0xffff, PackHalf2x16(1, 0)>>16; lo != 0x3c00 || hi != 0 {
+		t.Fatalf("the first value should be in the low bits, got %#x %#x", lo, hi)
+	}
+}
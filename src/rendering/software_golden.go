@@ -0,0 +1,138 @@
+/******************************************************************************/
+/* software_golden.go                                                         */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GoldenUpdateEnv is the environment variable that, when set, makes
+// [CompareGolden] write the rendered image over the golden instead of
+// comparing against it. Run the tests once with it set to accept a change.
+const GoldenUpdateEnv = "KAIJU_UPDATE_GOLDENS"
+
+// GoldenTolerance is how far a rendered image may drift from its golden
+type GoldenTolerance struct {
+	// Channel is the largest difference (0-255) any channel of a pixel may
+	// have before the pixel is considered to not match
+	Channel uint8
+	// Pixels is the fraction (0-1) of the pixels that are allowed to not match
+	Pixels float64
+}
+
+// GoldenDiff is the result of comparing two images pixel by pixel
+type GoldenDiff struct {
+	Pixels     int
+	Mismatched int
+	MaxDelta   uint8
+}
+
+// Within will return true if the differences are inside of the tolerance
+func (d GoldenDiff) Within(tolerance GoldenTolerance) bool {
+	if d.Pixels == 0 {
+		return true
+	}
+	return float64(d.Mismatched)/float64(d.Pixels) <= tolerance.Pixels
+}
+
+func (d GoldenDiff) String() string {
+	return fmt.Sprintf("%d of %d pixels differ (max channel delta %d)",
+		d.Mismatched, d.Pixels, d.MaxDelta)
+}
+
+// DiffImages compares two images of the same size, a pixel is mismatched if
+// any of its channels differ by more than the channel tolerance
+func DiffImages(got, want image.Image, channelTolerance uint8) (GoldenDiff, error) {
+	gb, wb := got.Bounds(), want.Bounds()
+	if gb.Dx() != wb.Dx() || gb.Dy() != wb.Dy() {
+		return GoldenDiff{}, fmt.Errorf("image size %dx%d does not match the expected %dx%d",
+			gb.Dx(), gb.Dy(), wb.Dx(), wb.Dy())
+	}
+	diff := GoldenDiff{Pixels: gb.Dx() * gb.Dy()}
+	for y := range gb.Dy() {
+		for x := range gb.Dx() {
+			r0, g0, b0, a0 := got.At(gb.Min.X+x, gb.Min.Y+y).RGBA()
+			r1, g1, b1, a1 := want.At(wb.Min.X+x, wb.Min.Y+y).RGBA()
+			delta := max(goldenDelta(r0, r1), goldenDelta(g0, g1),
+				goldenDelta(b0, b1), goldenDelta(a0, a1))
+			diff.MaxDelta = max(diff.MaxDelta, delta)
+			if delta > channelTolerance {
+				diff.Mismatched++
+			}
+		}
+	}
+	return diff, nil
+}
+
+func goldenDelta(a, b uint32) uint8 {
+	a, b = a>>8, b>>8
+	if a > b {
+		return uint8(a - b)
+	}
+	return uint8(b - a)
+}
+
+// ReadGoldenPNG reads a golden image from disk
+func ReadGoldenPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+// WriteGoldenPNG writes an image to disk as a PNG, creating the folder for it
+// if needed
+func WriteGoldenPNG(path string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err = png.Encode(f, img); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// CompareGolden compares the image against the golden PNG at the path. When
+// the images differ beyond the tolerance, the rendered image is written to the
+// temp folder and the returned error says where. If [GoldenUpdateEnv] is set
+// the golden is replaced by the image instead.
+func CompareGolden(path string, img image.Image, tolerance GoldenTolerance) (GoldenDiff, error) {
+	if os.Getenv(GoldenUpdateEnv) != "" {
+		return GoldenDiff{}, WriteGoldenPNG(path, img)
+	}
+	want, err := ReadGoldenPNG(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			err = fmt.Errorf("%w, run with %s=1 to create it", err, GoldenUpdateEnv)
+		}
+		return GoldenDiff{}, err
+	}
+	diff, err := DiffImages(img, want, tolerance.Channel)
+	if err != nil || diff.Within(tolerance) {
+		return diff, err
+	}
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	actual := filepath.Join(os.TempDir(), name+".actual.png")
+	if werr := WriteGoldenPNG(actual, img); werr != nil {
+		actual = werr.Error()
+	}
+	return diff, fmt.Errorf("image does not match golden %s: %s, rendered image: %s",
+		path, diff, actual)
+}
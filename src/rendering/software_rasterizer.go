@@ -0,0 +1,1105 @@
+/******************************************************************************/
+/* software_rasterizer.go                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"image"
+	"image/color"
+	"math"
+	"sort"
+	"strings"
+	"unsafe"
+
+	"kaijuengine.com/engine/cameras"
+	"kaijuengine.com/matrix"
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+type softwareShading int
+
+const (
+	softwareShadingUnlit softwareShading = iota
+	softwareShadingBasic
+	softwareShadingPBR
+	softwareShadingUI
+	softwareShadingUIBackdrop
+	softwareShadingText
+)
+
+// softwareProgram is the subset of a built in shader that the software
+// rasterizer mirrors. UI space programs are drawn with the UI camera the same
+// way their vertex shaders use the uiView and uiProjection matrices.
+type softwareProgram struct {
+	shading softwareShading
+	uiSpace bool
+}
+
+// softwarePrograms maps the built in shaders (by their shader name, without
+// the transparent suffix) to the behaviour that is mirrored for them. Drawings
+// that use any other shader are skipped by the software rasterizer.
+var softwarePrograms = map[string]softwareProgram{
+	"unlit":       {shading: softwareShadingUnlit},
+	"sprite":      {shading: softwareShadingUnlit, uiSpace: true},
+	"basic":       {shading: softwareShadingBasic},
+	"pbr":         {shading: softwareShadingPBR},
+	"ui":          {shading: softwareShadingUI, uiSpace: true},
+	"ui_backdrop": {shading: softwareShadingUIBackdrop, uiSpace: true},
+	"text":        {shading: softwareShadingText, uiSpace: true},
+	"text3d":      {shading: softwareShadingText},
+}
+
+// uiQuad reports if the program draws UI panels, their quads are grown for
+// the outline and the outer shadow
+func (p softwareProgram) uiQuad() bool {
+	return p.shading == softwareShadingUI || p.shading == softwareShadingUIBackdrop
+}
+
+const (
+	softwareEdgeSoftness    = 0.4
+	softwarePBRMinRoughness = 0.045
+	softwarePBRAmbient      = 0.03
+)
+
+// SoftwareRasterizer draws captured frames on the CPU. It consumes the same
+// [Drawings] and instance data that would be sent to the GPU and mirrors a
+// small subset of the built in shaders: unlit (and sprite), basic, PBR, UI
+// quads and MSDF text. It is a reference for golden image tests, it is not a
+// replacement for the GPU renderer, so there are no shadows, normal maps,
+// post-processing or order independent transparency.
+//
+// Only meshes and textures that were created on a null device can be drawn,
+// the null device retains the vertex and pixel data that the rasterizer reads.
+type SoftwareRasterizer struct {
+	// ClearColor is the color the image is cleared to before drawing
+	ClearColor matrix.Color
+	width      int
+	height     int
+	color      []matrix.Color
+	depth      []float32
+	layouts    map[string]softwareLayout
+	// scene is a copy of the image before the current render pass, it is what
+	// the UI backdrop filters read
+	scene []matrix.Color
+}
+
+// softwareField is a per-instance field, either in the instance data or, when
+// buffer is set, in the entry of the instance in the storage buffer bound at
+// binding
+type softwareField struct {
+	offset  int
+	size    int
+	buffer  bool
+	binding int
+}
+
+type softwareLayout map[string]softwareField
+
+type softwareInstance struct {
+	raw []byte
+	// bound holds the entry of the instance in each storage buffer, indexed
+	// by the binding of the buffer
+	bound  [][]byte
+	layout softwareLayout
+}
+
+type softwareTexture struct {
+	data   *TextureData
+	filter TextureFilter
+}
+
+type softwareVertex struct {
+	screen matrix.Vec3
+	invW   matrix.Float
+	world  matrix.Vec3
+	normal matrix.Vec3
+	uv     matrix.Vec2
+	color  matrix.Color
+}
+
+type softwareFragment struct {
+	world   matrix.Vec3
+	normal  matrix.Vec3
+	uv      matrix.Vec2
+	uvWidth matrix.Vec2
+	uvDx    matrix.Vec2
+	uvDy    matrix.Vec2
+	color   matrix.Color
+	// index is the pixel of the image the fragment is drawn to
+	index int
+}
+
+type softwareDraw struct {
+	program     softwareProgram
+	instance    softwareInstance
+	textures    []softwareTexture
+	lights      *LightsForRender
+	camera      cameras.Camera
+	raster      ShaderPipelinePipelineRasterizationCompiled
+	depth       ShaderPipelineDepthStencilCompiled
+	transparent bool
+	// backdrop is the blurred scene behind a UI panel while its
+	// backdrop-filter is drawn, nil for every other draw
+	backdrop []matrix.Color
+	// lightIds is reused for the lights of each lit fragment
+	lightIds []int32
+}
+
+// NewSoftwareRasterizer creates a rasterizer that draws into an image of the
+// given size, the image is cleared to opaque black unless
+// [SoftwareRasterizer.ClearColor] is changed.
+func NewSoftwareRasterizer(width, height int) *SoftwareRasterizer {
+	r := &SoftwareRasterizer{
+		ClearColor: matrix.ColorBlack(),
+		layouts:    make(map[string]softwareLayout),
+	}
+	r.Resize(width, height)
+	return r
+}
+
+// Width returns the width of the images that are rasterized
+func (r *SoftwareRasterizer) Width() int { return r.width }
+
+// Height returns the height of the images that are rasterized
+func (r *SoftwareRasterizer) Height() int { return r.height }
+
+// Resize changes the size of the images that are rasterized
+func (r *SoftwareRasterizer) Resize(width, height int) {
+	r.width, r.height = max(1, width), max(1, height)
+	r.color = make([]matrix.Color, r.width*r.height)
+	r.depth = make([]float32, r.width*r.height)
+}
+
+// Render draws the frame data that was captured for the views into a new
+// image. The views, lights and cameras should be the same ones that were given
+// to [Drawings.CaptureFrameData], only views that present to the screen (have
+// no render target) are drawn. Render passes are drawn in their sort order
+// each with a fresh depth buffer, the same way they are composited on the GPU.
+func (r *SoftwareRasterizer) Render(device *GPUDevice, drawings *Drawings, camera, uiCamera cameras.Camera, lights LightsForRender, views []RenderViewFrame) *image.RGBA {
+	defer tracing.NewRegion("SoftwareRasterizer.Render").End()
+	for i := range r.color {
+		r.color[i] = r.ClearColor
+	}
+	if device.IsNull() && drawings != nil {
+		drawings.mutex.RLock()
+		groups := softwareRenderPassGroups(drawings)
+		views = renderViewsForDraw(views)
+		for i := range views {
+			if views[i].Target() != nil {
+				continue
+			}
+			viewCamera := renderViewCameraForGlobals(views[i], camera)
+			layerMask := views[i].LayerMask()
+			for _, group := range groups {
+				r.clearDepth()
+				r.scene = append(r.scene[:0], r.color...)
+				// Backdrop filters are drawn under everything else in the pass
+				for _, backdrops := range []bool{true, false} {
+					for j := range group.draws {
+						for k := range group.draws[j].instanceGroups {
+							ig := &group.draws[j].instanceGroups[k]
+							if ig.MatchesLayer(layerMask) && softwareIsBackdrop(ig) == backdrops {
+								r.drawGroup(device, ig, views[i], viewCamera, uiCamera, &lights)
+							}
+						}
+					}
+				}
+			}
+		}
+		drawings.mutex.RUnlock()
+	}
+	return r.image()
+}
+
+func softwareRenderPassGroups(drawings *Drawings) []*RenderPassGroup {
+	groups := make([]*RenderPassGroup, 0, len(drawings.renderPassGroups))
+	for i := range drawings.renderPassGroups {
+		group := &drawings.renderPassGroups[i]
+		if group.renderPass == nil || group.renderPass.IsShadowPass() {
+			continue
+		}
+		groups = append(groups, group)
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].renderPass.construction.Sort < groups[j].renderPass.construction.Sort
+	})
+	return groups
+}
+
+func softwareIsBackdrop(ig *DrawInstanceGroup) bool {
+	if ig.MaterialInstance == nil {
+		return false
+	}
+	program := softwarePrograms[ig.MaterialInstance.shaderInfo.Name]
+	return program.shading == softwareShadingUIBackdrop
+}
+
+func (r *SoftwareRasterizer) clearDepth() {
+	for i := range r.depth {
+		r.depth[i] = 1
+	}
+}
+
+func (r *SoftwareRasterizer) image() *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, r.width, r.height))
+	for i, c := range r.color {
+		img.SetRGBA(i%r.width, i/r.width, color.RGBA{
+			R: softwareUnorm(c.R()),
+			G: softwareUnorm(c.G()),
+			B: softwareUnorm(c.B()),
+			A: softwareUnorm(c.A()),
+		})
+	}
+	return img
+}
+
+func (r *SoftwareRasterizer) layout(info *ShaderDataCompiled) softwareLayout {
+	if l, ok := r.layouts[info.Name]; ok {
+		return l
+	}
+	l := newSoftwareLayout(info)
+	r.layouts[info.Name] = l
+	return l
+}
+
+// newSoftwareLayout finds the byte offsets of the per-instance fields of a
+// shader, these are packed in the order of the vertex stage inputs starting
+// with the model matrix, see [ShaderDataCompiled.ToAttributeDescription].
+// The fields of the storage buffers with an entry for each instance are
+// found as well, keyed by their name without the array size.
+func newSoftwareLayout(info *ShaderDataCompiled) softwareLayout {
+	layout := softwareLayout{}
+	for i := range info.LayoutGroups {
+		for j := range info.LayoutGroups[i].Layouts {
+			l := &info.LayoutGroups[i].Layouts[j]
+			if l.Source != "buffer" {
+				continue
+			}
+			offset := 0
+			for _, f := range l.Fields {
+				size := fieldSize(f.Type, f.Name)
+				name, _, _ := strings.Cut(f.Name, "[")
+				layout[name] = softwareField{offset: offset, size: size, buffer: true, binding: l.Binding}
+				offset += size
+			}
+		}
+	}
+	g := info.SelectLayout("Vertex")
+	if g == nil {
+		return layout
+	}
+	offset := 0
+	for i := range g.Layouts {
+		l := &g.Layouts[i]
+		if l.Source != "in" || l.Location < baseVertexAttributeCount {
+			continue
+		}
+		dt, ok := defTypes[l.Type]
+		if !ok {
+			continue
+		}
+		size := int(dt.size) * dt.repeat * max(1, l.Count)
+		if f, ok := layout[l.Name]; ok {
+			// Arrays (like the light ids) are declared once per element
+			f.size += size
+			layout[l.Name] = f
+		} else {
+			layout[l.Name] = softwareField{offset: offset, size: size}
+		}
+		offset += size
+	}
+	return layout
+}
+
+func (r *SoftwareRasterizer) drawGroup(device *GPUDevice, ig *DrawInstanceGroup, view RenderViewFrame, camera, uiCamera cameras.Camera, lights *LightsForRender) {
+	state, ok := ig.viewStates[view.Key()]
+	if !ok || !state.frameData.ready || state.frameData.visibleCount == 0 {
+		return
+	}
+	material := ig.MaterialInstance
+	if material == nil || ig.Mesh == nil {
+		return
+	}
+	program, ok := softwarePrograms[strings.TrimSuffix(material.shaderInfo.Name, "_transparent")]
+	if !ok {
+		return
+	}
+	mesh, ok := device.null.meshData(ig.Mesh)
+	if !ok || len(mesh.indexes) < 3 {
+		return
+	}
+	draw := softwareDraw{
+		program:     program,
+		lights:      lights,
+		camera:      camera,
+		raster:      material.pipelineInfo.Rasterization,
+		depth:       material.pipelineInfo.DepthStencil,
+		transparent: material.HasTransparentSuffix(),
+		textures:    make([]softwareTexture, len(material.Textures)),
+	}
+	if program.uiSpace {
+		draw.camera = uiCamera
+	}
+	if draw.camera == nil {
+		return
+	}
+	for i, t := range material.Textures {
+		if t != nil {
+			draw.textures[i] = softwareTexture{data: device.null.textureData(t), filter: t.Filter}
+		}
+	}
+	layout := r.layout(&material.shaderInfo)
+	stride := ig.instanceSize + state.rawData.padding
+	bound := make([][]byte, len(state.frameData.bound))
+	for i := range state.frameData.visibleCount {
+		start := i * stride
+		if start+ig.instanceSize > len(state.frameData.raw) {
+			break
+		}
+		for b := range bound {
+			bound[b] = nil
+			if boundStride := ig.captureBoundStride(state, b); boundStride > 0 &&
+				(i+1)*boundStride <= len(state.frameData.bound[b]) {
+				bound[b] = state.frameData.bound[b][i*boundStride : (i+1)*boundStride]
+			}
+		}
+		draw.instance = softwareInstance{
+			raw:    state.frameData.raw[start : start+ig.instanceSize],
+			bound:  bound,
+			layout: layout,
+		}
+		r.drawInstance(&draw, mesh)
+	}
+}
+
+func (r *SoftwareRasterizer) drawInstance(draw *softwareDraw, mesh nullMeshRecord) {
+	inst := &draw.instance
+	model, ok := inst.mat4("model")
+	if !ok {
+		return
+	}
+	viewProjection := matrix.Mat4Multiply(draw.camera.View(), draw.camera.Projection())
+	uvs, hasUVs := inst.vec4("uvs", matrix.Vec4{0, 0, 1, 1})
+	outlineSize, _ := inst.vec2("outlineSize", matrix.Vec2{})
+	effects := inst.uiEffects()
+	outset := effects.quadOutset(outlineSize)
+	verts := make([]softwareVertex, len(mesh.verts))
+	visible := make([]bool, len(mesh.verts))
+	for i := range mesh.verts {
+		v := &mesh.verts[i]
+		wp := matrix.Mat4MultiplyVec4(model, v.Position.AsVec4WithW(1))
+		if draw.program.uiQuad() {
+			wp[matrix.Vx] = matrix.Round(wp.X() + softwareSign(v.Position.X())*outset)
+			wp[matrix.Vy] = matrix.Round(wp.Y() + softwareSign(v.Position.Y())*outset)
+		}
+		clip := matrix.Mat4MultiplyVec4(viewProjection, wp)
+		uv := v.UV0
+		if hasUVs {
+			uv = uv.Multiply(matrix.Vec2{uvs.Z(), uvs.W()})
+			uv[matrix.Vy] += (1.0 - uvs.W()) - uvs.Y()
+			uv[matrix.Vx] += uvs.X()
+		}
+		normal := matrix.Mat4MultiplyVec4(model, v.Normal.AsVec4WithW(0))
+		visible[i] = clip.W() > 1e-6
+		if !visible[i] {
+			continue
+		}
+		invW := 1 / clip.W()
+		verts[i] = softwareVertex{
+			screen: matrix.Vec3{
+				(clip.X()*invW + 1) * 0.5 * matrix.Float(r.width),
+				(clip.Y()*invW + 1) * 0.5 * matrix.Float(r.height),
+				clip.Z() * invW,
+			},
+			invW:   invW,
+			world:  wp.AsVec3(),
+			normal: normal.AsVec3(),
+			uv:     uv,
+			color:  v.Color,
+		}
+	}
+	if draw.program.shading == softwareShadingUIBackdrop {
+		// The GPU samples the scene color in ui_backdrop_blur.frag, here the
+		// copy of the image before the pass is blurred up front with a
+		// separable gaussian
+		sigma, _ := UnpackHalf2x16(effects.effects[2])
+		draw.backdrop = softwareGaussianBlur(r.scene, r.width, r.height, sigma)
+	}
+	for i := 0; i+2 < len(mesh.indexes); i += 3 {
+		a, b, c := mesh.indexes[i], mesh.indexes[i+1], mesh.indexes[i+2]
+		if int(max(a, b, c)) >= len(verts) {
+			continue
+		}
+		// Triangles are not clipped, ones that cross the camera plane are skipped
+		if !visible[a] || !visible[b] || !visible[c] {
+			continue
+		}
+		r.drawTriangle(draw, &verts[a], &verts[b], &verts[c])
+	}
+}
+
+func (r *SoftwareRasterizer) drawTriangle(draw *softwareDraw, a, b, c *softwareVertex) {
+	area := softwareEdge(a.screen, b.screen, c.screen.X(), c.screen.Y())
+	if matrix.Abs(area) < 1e-9 {
+		return
+	}
+	// The projection flips Y, so a counter clockwise triangle has a negative
+	// area in image space where Y points down
+	front := area < 0
+	if draw.raster.FrontFace == GPUFrontFaceClockwise {
+		front = !front
+	}
+	if (front && draw.raster.CullMode&GPUCullModeFrontBit != 0) ||
+		(!front && draw.raster.CullMode&GPUCullModeBackBit != 0) {
+		return
+	}
+	if area < 0 {
+		b, c = c, b
+		area = -area
+	}
+	uvDx, uvDy := softwareUVDerivatives(a, b, c, area)
+	uvWidth := matrix.Vec2{
+		matrix.Abs(uvDx.X()) + matrix.Abs(uvDy.X()),
+		matrix.Abs(uvDx.Y()) + matrix.Abs(uvDy.Y()),
+	}
+	minX := max(0, int(math.Floor(float64(min(a.screen.X(), b.screen.X(), c.screen.X())))))
+	maxX := min(r.width-1, int(math.Ceil(float64(max(a.screen.X(), b.screen.X(), c.screen.X())))))
+	minY := max(0, int(math.Floor(float64(min(a.screen.Y(), b.screen.Y(), c.screen.Y())))))
+	maxY := min(r.height-1, int(math.Ceil(float64(max(a.screen.Y(), b.screen.Y(), c.screen.Y())))))
+	for y := minY; y <= maxY; y++ {
+		py := matrix.Float(y) + 0.5
+		for x := minX; x <= maxX; x++ {
+			px := matrix.Float(x) + 0.5
+			w0 := softwareSharedEdge(b.screen, c.screen, px, py)
+			w1 := softwareSharedEdge(c.screen, a.screen, px, py)
+			w2 := softwareSharedEdge(a.screen, b.screen, px, py)
+			if !softwareCovers(w0, b.screen, c.screen) ||
+				!softwareCovers(w1, c.screen, a.screen) ||
+				!softwareCovers(w2, a.screen, b.screen) {
+				continue
+			}
+			w0, w1, w2 = w0/area, w1/area, w2/area
+			z := w0*a.screen.Z() + w1*b.screen.Z() + w2*c.screen.Z()
+			idx := y*r.width + x
+			if z < 0 || z > 1 || (draw.depth.DepthTestEnable &&
+				!softwareDepthTest(draw.depth.DepthCompareOp, float32(z), r.depth[idx])) {
+				continue
+			}
+			// Perspective correct weights for the interpolated attributes
+			p0, p1, p2 := w0*a.invW, w1*b.invW, w2*c.invW
+			sum := p0 + p1 + p2
+			p0, p1, p2 = p0/sum, p1/sum, p2/sum
+			frag := softwareFragment{
+				world:   softwareLerp3(a.world, b.world, c.world, p0, p1, p2),
+				normal:  softwareLerp3(a.normal, b.normal, c.normal, p0, p1, p2),
+				uv:      softwareLerp2(a.uv, b.uv, c.uv, p0, p1, p2),
+				uvWidth: uvWidth,
+				uvDx:    uvDx,
+				uvDy:    uvDy,
+				color:   softwareLerpColor(a.color, b.color, c.color, float32(p0), float32(p1), float32(p2)),
+				index:   idx,
+			}
+			out, keep := draw.shade(&frag)
+			if !keep {
+				continue
+			}
+			r.blend(idx, out)
+			if draw.depth.DepthTestEnable && draw.depth.DepthWriteEnable {
+				r.depth[idx] = float32(z)
+			}
+		}
+	}
+}
+
+func softwareDepthTest(op GPUCompareOp, z, stored float32) bool {
+	switch op {
+	case GPUCompareOpNever:
+		return false
+	case GPUCompareOpLess:
+		return z < stored
+	case GPUCompareOpEqual:
+		return z == stored
+	case GPUCompareOpLessOrEqual:
+		return z <= stored
+	case GPUCompareOpGreater:
+		return z > stored
+	case GPUCompareOpNotEqual:
+		return z != stored
+	case GPUCompareOpGreaterOrEqual:
+		return z >= stored
+	}
+	return true
+}
+
+// blend mixes the fragment over the image with the standard source alpha
+// blending that the built in pipelines use
+func (r *SoftwareRasterizer) blend(idx int, src matrix.Color) {
+	dst := &r.color[idx]
+	a := softwareClamp01(src.A())
+	for i := range 3 {
+		dst[i] = src[i]*a + dst[i]*(1-a)
+	}
+	dst[3] = a + dst[3]*(1-a)
+}
+
+func (d *softwareDraw) texture(index int) softwareTexture {
+	if index < len(d.textures) {
+		return d.textures[index]
+	}
+	return softwareTexture{}
+}
+
+func (d *softwareDraw) shade(f *softwareFragment) (matrix.Color, bool) {
+	if d.program.uiSpace && !d.instance.inScissor(f.world) {
+		return matrix.Color{}, false
+	}
+	switch d.program.shading {
+	case softwareShadingUnlit:
+		return d.shadeUnlit(f)
+	case softwareShadingBasic:
+		return d.shadeBasic(f)
+	case softwareShadingPBR:
+		return d.shadePBR(f)
+	case softwareShadingUI:
+		return d.shadeUI(f)
+	case softwareShadingUIBackdrop:
+		return d.shadeUIBackdrop(f)
+	case softwareShadingText:
+		return d.shadeText(f)
+	}
+	return matrix.Color{}, false
+}
+
+// finalColor mirrors processFinalColor, opaque passes discard anything that
+// isn't fully opaque and transparent passes discard what is fully clear
+func (d *softwareDraw) finalColor(c matrix.Color) (matrix.Color, bool) {
+	if d.transparent {
+		return c, c.A() >= 0.001
+	}
+	return c, c.A() >= 1.0-0.001
+}
+
+func (d *softwareDraw) instanceColor(f *softwareFragment) matrix.Color {
+	c, _ := d.instance.vec4("color", matrix.Vec4One())
+	return softwareMulColor(f.color, softwareVec4Color(c))
+}
+
+func (d *softwareDraw) shadeUnlit(f *softwareFragment) (matrix.Color, bool) {
+	c := softwareMulColor(d.texture(0).sample(f.uv), d.instanceColor(f))
+	return d.finalColor(c)
+}
+
+func (d *softwareDraw) shadeBasic(f *softwareFragment) (matrix.Color, bool) {
+	// Mirrors the hard coded sun light of basic.frag
+	sunDir := matrix.Vec3{-0.5, -0.7, -0.5}
+	sunColor := matrix.Vec3{1.0, 0.95, 0.85}
+	const ambientStrength = 0.5
+	tex := softwareMulColor(d.texture(0).sample(f.uv), d.instanceColor(f))
+	albedo := matrix.Vec3{matrix.Float(tex.R()), matrix.Float(tex.G()), matrix.Float(tex.B())}
+	normal := f.normal.Normal()
+	diff := max(normal.Dot(sunDir.Negative()), 0)
+	lit := sunColor.Multiply(albedo).Scale(ambientStrength + diff)
+	return d.finalColor(softwareVec3Color(lit, tex.A()))
+}
+
+func (d *softwareDraw) shadePBR(f *softwareFragment) (matrix.Color, bool) {
+	base := d.texture(0).sample(f.uv)
+	tint := d.instanceColor(f)
+	albedo := softwareSrgbToLinear(softwareColorVec3(base)).Multiply(matrix.Vec3{
+		max(0, matrix.Float(tint.R())), max(0, matrix.Float(tint.G())), max(0, matrix.Float(tint.B())),
+	})
+	alpha := base.A() * tint.A()
+	props, _ := d.instance.vec4("meRoEmAo", matrix.Vec4{0, 1, 0, 1})
+	mr := d.texture(2).sample(f.uv)
+	metallic := softwareClamp01(matrix.Float(mr.B()) * max(props.X(), 0))
+	roughness := matrix.Clamp(matrix.Float(mr.G())*max(props.Y(), softwarePBRMinRoughness), softwarePBRMinRoughness, 1)
+	occlusion := softwareClamp01(matrix.Float(mr.R()))
+	emission := softwareSrgbToLinear(softwareColorVec3(d.texture(3).sample(f.uv))).Scale(max(props.Z(), 0))
+	n := softwareSafeNormal(f.normal, matrix.Vec3Up())
+	v := softwareSafeNormal(d.camera.Position().Subtract(f.world), n)
+	ambient := albedo.Scale(softwarePBRAmbient * occlusion)
+	direct := matrix.Vec3{}
+	d.lightIds = d.lights.AppendLightsAt(f.world, d.camera, d.lightIds[:0])
+	for _, idx := range d.lightIds {
+		if int(idx) >= min(len(d.lights.Lights), MaxLocalLights) || !d.lights.Lights[idx].IsValid() {
+			continue
+		}
+		light := &d.lights.Lights[idx]
+		ambient.AddAssign(softwareMaxVec3(light.ambient).Multiply(albedo).Scale(occlusion))
+		direct.AddAssign(softwarePBRLight(light, f.world, albedo, n, v, metallic, roughness))
+	}
+	lit := softwareLinearToSrgb(softwareAces(ambient.Add(direct).Add(emission)))
+	return d.finalColor(softwareVec3Color(lit, alpha))
+}
+
+// softwarePBRLight mirrors pbrAccumulateLight without the shadow visibility
+func softwarePBRLight(light *Light, pos, albedo, n, v matrix.Vec3, metallic, roughness matrix.Float) matrix.Vec3 {
+	var l matrix.Vec3
+	var attenuation matrix.Float
+	attenuate := func(distance matrix.Float) matrix.Float {
+		den := matrix.Float(light.constant) + matrix.Float(light.linear)*distance +
+			matrix.Float(light.quadratic)*distance*distance
+		return max(matrix.Float(light.intensity), 0) / max(den, 0.0001)
+	}
+	switch light.lightType {
+	case LightTypeDirectional:
+		l = softwareSafeNormal(light.direction.Negative(), n)
+		attenuation = max(matrix.Float(light.intensity), 0)
+	case LightTypePoint, LightTypeSpot:
+		toLight := light.position.Subtract(pos)
+		l = softwareSafeNormal(toLight, n)
+		attenuation = attenuate(toLight.Length())
+		if light.lightType == LightTypeSpot {
+			lightToFrag := softwareSafeNormal(pos.Subtract(light.position), l.Negative())
+			theta := softwareSafeNormal(light.direction, l.Negative()).Dot(lightToFrag)
+			epsilon := max(matrix.Float(light.cutoff-light.outerCutoff), 0.0001)
+			attenuation *= softwareClamp01((theta - matrix.Float(light.outerCutoff)) / epsilon)
+		}
+	default:
+		return matrix.Vec3{}
+	}
+	nDotL := max(n.Dot(l), 0)
+	if attenuation <= 0 || nDotL <= 0 {
+		return matrix.Vec3{}
+	}
+	nDotV := max(n.Dot(v), 0)
+	h := softwareSafeNormal(v.Add(l), n)
+	f0 := matrix.Vec3{0.04, 0.04, 0.04}
+	f0 = f0.Add(albedo.Subtract(f0).Scale(metallic))
+	// GGX distribution, Smith geometry and Schlick fresnel from kaiju.glsl
+	a2 := roughness * roughness * roughness * roughness
+	nDotH := max(n.Dot(h), 0)
+	den := nDotH*nDotH*(a2-1) + 1
+	distribution := a2 / (math.Pi * den * den)
+	k := (roughness + 1) * (roughness + 1) / 8
+	geometry := (nDotV / (nDotV*(1-k) + k)) * (nDotL / (nDotL*(1-k) + k))
+	fw := matrix.Pow(max(1-max(h.Dot(v), 0), 0), 5)
+	fresnel := f0.Add(matrix.Vec3One().Subtract(f0).Scale(fw))
+	diffuse := matrix.Vec3One().Subtract(fresnel).Scale(1 - metallic)
+	specular := fresnel.Scale(distribution * geometry / max(4*nDotV*nDotL, 0.001))
+	radiance := softwareMaxVec3(light.diffuse).Scale(attenuation)
+	return diffuse.Multiply(albedo).Scale(1 / math.Pi).Add(specular).Multiply(radiance).Scale(nDotL)
+}
+
+// shadeUI mirrors ui_nine.frag, the nine slice, border, rounded corner,
+// outline, gradient, box shadow and color filter behaviour of UI panels
+func (d *softwareDraw) shadeUI(f *softwareFragment) (matrix.Color, bool) {
+	inst := &d.instance
+	effects := inst.uiEffects()
+	uvs, _ := inst.vec4("uvs", matrix.Vec4{0, 0, 1, 1})
+	size2D, _ := inst.vec4("size2D", matrix.Vec4{})
+	radius, _ := inst.vec4("borderRadius", matrix.Vec4{})
+	border, _ := inst.vec4("borderSize", matrix.Vec4{})
+	borderColors, _ := inst.mat4("borderColor")
+	borderLen, _ := inst.vec2("borderLen", matrix.Vec2{})
+	outlineColor, _ := inst.vec4("outlineColor", matrix.Vec4{})
+	outlineSize, _ := inst.vec2("outlineSize", matrix.Vec2{})
+	fg, _ := inst.vec4("fgColor", matrix.Vec4One())
+	fragColor := softwareMulColor(f.color, softwareVec4Color(fg))
+	fragUvs := uvs
+	fragUvs[matrix.Vy] = (1.0 - uvs.W()) - uvs.Y()
+	outlineWidth := max(0, outlineSize.X())
+	outlineOffset := max(0, outlineSize.Y())
+	pix, dims, pixelScale := d.uiPixel(f, &effects)
+	flags := effects.flags()
+	shadowColor := effects.shadowColor()
+	shadow := effects.shadow
+	size := dims.Scale(0.5)
+	center := size.Subtract(pix)
+	shapeAlpha := matrix.Float(0)
+	var out matrix.Color
+	inside := pix.X() >= 0 && pix.Y() >= 0 && pix.X() <= dims.X() && pix.Y() <= dims.Y()
+	if inside {
+		panelUV := matrix.Vec2{pix.X() / dims.X(), pix.Y() / dims.Y()}
+		scaled := matrix.Vec2{
+			softwareProcessAxis(panelUV.X(), borderLen.X()/size2D.Z(), size2D.Z()/size2D.X()),
+			softwareProcessAxis(panelUV.Y(), borderLen.Y()/size2D.W(), size2D.W()/size2D.Y()),
+		}
+		newUV := matrix.Vec2{
+			fragUvs.X() + scaled.X()*fragUvs.Z(),
+			fragUvs.Y() + scaled.Y()*fragUvs.W(),
+		}
+		out = softwareMulColor(d.texture(0).sample(newUV), fragColor)
+		out = softwareBlendOver(effects.gradientColor(pix, dims), out)
+		sideIdx := 0
+		closest := softwareEdgeProximity(pix.X(), border.X())
+		sides := [3]matrix.Float{
+			softwareEdgeProximity(pix.Y(), border.Y()),
+			softwareEdgeProximity(dims.X()-pix.X(), border.Z()),
+			softwareEdgeProximity(dims.Y()-pix.Y(), border.W()),
+		}
+		for i, side := range sides {
+			if side < closest {
+				sideIdx, closest = i+1, side
+			}
+		}
+		// Each column of the border color matrix is the color of a side (LTRB)
+		borderColor := matrix.Color{
+			float32(borderColors[sideIdx*4]), float32(borderColors[sideIdx*4+1]),
+			float32(borderColors[sideIdx*4+2]), float32(borderColors[sideIdx*4+3]),
+		}
+		rounded := radius.X() > 0.001 || radius.Y() > 0.001 || radius.Z() > 0.001 || radius.W() > 0.001
+		smoothed := matrix.Float(1)
+		if rounded {
+			smoothed = 1 - softwareSmoothstep(-pixelScale*softwareEdgeSoftness,
+				pixelScale*softwareEdgeSoftness, softwareRoundedBoxSDF(center, size, radius))
+		}
+		innerSize := size.Subtract(matrix.Vec2{
+			(border.X() + border.Z()) * 0.5,
+			(border.Y() + border.W()) * 0.5,
+		})
+		innerCenter := center.Add(matrix.Vec2{
+			(border.X() - border.Z()) * 0.5,
+			(border.Y() - border.W()) * 0.5,
+		})
+		innerRadius := matrix.Vec4{
+			max(radius.X()-max(border.X(), border.W()), 0),
+			max(radius.Y()-max(border.Z(), border.W()), 0),
+			max(radius.Z()-max(border.Z(), border.Y()), 0),
+			max(radius.W()-max(border.X(), border.Y()), 0),
+		}
+		borderAlpha := matrix.Float(0)
+		if border.X()+border.Y()+border.Z()+border.W() > 0 {
+			if rounded {
+				borderAlpha = smoothed * softwareSmoothstep(-pixelScale*softwareEdgeSoftness,
+					pixelScale*softwareEdgeSoftness, softwareRoundedBoxSDF(innerCenter, innerSize, innerRadius))
+			} else if closest <= 1 {
+				borderAlpha = 1
+			}
+		}
+		if flags&softwareEffectInsetShadow != 0 && shadowColor.A() > 0 {
+			spread := shadow.W()
+			insetDist := softwareRoundedBoxSDF(
+				innerCenter.Add(matrix.Vec2{shadow.X(), shadow.Y()}),
+				matrix.Vec2{max(innerSize.X()-spread, 0), max(innerSize.Y()-spread, 0)},
+				matrix.Vec4{
+					max(innerRadius.X()-spread, 0), max(innerRadius.Y()-spread, 0),
+					max(innerRadius.Z()-spread, 0), max(innerRadius.W()-spread, 0),
+				})
+			insetAlpha := 1 - softwareShadowCoverage(insetDist, shadow.Z(), pixelScale)
+			sc := shadowColor
+			sc[3] *= float32(insetAlpha)
+			out = softwareBlendOver(sc, out)
+		}
+		out = softwareMixColor(out, borderColor, float32(borderAlpha))
+		out[3] *= float32(smoothed)
+		shapeAlpha = smoothed
+	} else if outlineWidth > 0 && outlineColor.W() > 0 {
+		outside := max(-pix.X(), pix.X()-dims.X(), -pix.Y(), pix.Y()-dims.Y(), 0)
+		outer := 1 - softwareSmoothstep(outlineOffset+outlineWidth,
+			outlineOffset+outlineWidth+softwareEdgeSoftness*pixelScale, outside)
+		inner := softwareSmoothstep(outlineOffset, outlineOffset+softwareEdgeSoftness*pixelScale, outside)
+		out = softwareVec4Color(outlineColor)
+		out[3] *= float32(outer * inner)
+	}
+	if effects.hasOuterShadow() {
+		spread := shadow.W()
+		shadowRadius := radius
+		for i := range shadowRadius {
+			if shadowRadius[i] > 0 {
+				shadowRadius[i] = max(shadowRadius[i]+spread, 0)
+			}
+		}
+		dist := softwareRoundedBoxSDF(center.Add(matrix.Vec2{shadow.X(), shadow.Y()}),
+			matrix.Vec2{max(size.X()+spread, 0), max(size.Y()+spread, 0)}, shadowRadius)
+		sc := shadowColor
+		sc[3] *= float32(softwareShadowCoverage(dist, shadow.Z(), pixelScale) * (1 - shapeAlpha))
+		out = softwareBlendOver(out, sc)
+	}
+	if flags&softwareEffectFilter != 0 {
+		out = effects.applyFilter(out)
+	}
+	return d.finalColor(out)
+}
+
+// shadeText mirrors text.frag, multi-channel signed distance field glyphs with
+// an optional background, text shadow and opaque cutout text
+func (d *softwareDraw) shadeText(f *softwareFragment) (matrix.Color, bool) {
+	fg, _ := d.instance.vec4("fgColor", matrix.Vec4One())
+	bg, _ := d.instance.vec4("bgColor", matrix.Vec4{})
+	pxRange, _ := d.instance.vec2("pxRange", matrix.Vec2{})
+	fragColor := softwareMulColor(f.color, softwareVec4Color(fg))
+	tex := d.texture(0)
+	msdf := tex.sample(f.uv)
+	dist := matrix.Float(max(min(msdf.R(), msdf.G()), min(max(msdf.R(), msdf.G()), msdf.B()))) - 0.5
+	w, h := tex.size()
+	screenPxRange := max(0.5*(pxRange.X()/matrix.Float(w)/max(f.uvWidth.X(), 0.000001)+
+		pxRange.Y()/matrix.Float(h)/max(f.uvWidth.Y(), 0.000001)), 1)
+	opacity := softwareClamp01(dist*screenPxRange + 0.5)
+	shadowColor, _ := d.instance.vec4("shadowColor", matrix.Vec4{})
+	shadow := matrix.Float(0)
+	if shadowColor.W() > 0 {
+		shadow = d.textShadowOpacity(f, tex, screenPxRange) * shadowColor.W()
+	}
+	if bg.W() < 0 {
+		if opacity < 0.5 {
+			if shadow < 0.5 {
+				return matrix.Color{}, false
+			}
+			return matrix.Color{float32(shadowColor.X()), float32(shadowColor.Y()), float32(shadowColor.Z()), 1}, true
+		}
+		return matrix.Color{fragColor.R(), fragColor.G(), fragColor.B(), 1}, true
+	}
+	background := softwareVec4Color(bg)
+	if shadow > 0 {
+		background = softwareMixColor(background, matrix.Color{
+			float32(shadowColor.X()), float32(shadowColor.Y()), float32(shadowColor.Z()),
+			max(background.A(), float32(shadowColor.W())),
+		}, float32(shadow))
+	}
+	return d.finalColor(softwareMixColor(background, fragColor, float32(opacity)))
+}
+
+func (s *softwareInstance) field(name string, size int) (unsafe.Pointer, bool) {
+	f, ok := s.layout[name]
+	if !ok || f.size < size {
+		return nil, false
+	}
+	data := s.raw
+	if f.buffer {
+		if f.binding >= len(s.bound) {
+			return nil, false
+		}
+		data = s.bound[f.binding]
+	}
+	if f.offset+size > len(data) {
+		return nil, false
+	}
+	return unsafe.Pointer(&data[f.offset]), true
+}
+
+func (s *softwareInstance) mat4(name string) (matrix.Mat4, bool) {
+	if p, ok := s.field(name, int(unsafe.Sizeof(matrix.Mat4{}))); ok {
+		return *(*matrix.Mat4)(p), true
+	}
+	return matrix.Mat4Identity(), false
+}
+
+func (s *softwareInstance) vec4(name string, fallback matrix.Vec4) (matrix.Vec4, bool) {
+	if p, ok := s.field(name, vec4Size); ok {
+		return *(*matrix.Vec4)(p), true
+	}
+	return fallback, false
+}
+
+func (s *softwareInstance) vec2(name string, fallback matrix.Vec2) (matrix.Vec2, bool) {
+	if p, ok := s.field(name, floatSize*2); ok {
+		return *(*matrix.Vec2)(p), true
+	}
+	return fallback, false
+}
+
+// inScissor mirrors the clip distances of the UI vertex shaders, the scissor
+// is in the same space as the model transformed vertex positions
+func (s *softwareInstance) inScissor(pos matrix.Vec3) bool {
+	scissor, ok := s.vec4("scissor", matrix.Vec4{})
+	if !ok {
+		return true
+	}
+	return pos.X() >= scissor.X() && pos.Y() >= scissor.Y() &&
+		pos.X() <= scissor.Z() && pos.Y() <= scissor.W()
+}
+
+func (t softwareTexture) size() (int, int) {
+	if t.data == nil || t.data.Width <= 0 || t.data.Height <= 0 {
+		return 1, 1
+	}
+	return t.data.Width, t.data.Height
+}
+
+func (t softwareTexture) texel(x, y int) matrix.Color {
+	w, h := t.size()
+	// Repeat addressing, the same as the samplers on the GPU
+	x, y = ((x%w)+w)%w, ((y%h)+h)%h
+	i := (y*w + x) * bytesInPixel
+	p := t.data.Mem[i : i+bytesInPixel]
+	return matrix.Color{float32(p[0]) / 255, float32(p[1]) / 255, float32(p[2]) / 255, float32(p[3]) / 255}
+}
+
+// sample reads the texture at the given coordinates, textures that are not
+// available (or aren't RGBA8 in memory, like ASTC) sample as white
+func (t softwareTexture) sample(uv matrix.Vec2) matrix.Color {
+	w, h := t.size()
+	if t.data == nil || t.data.InternalFormat != TextureInputTypeRgba8 ||
+		len(t.data.Mem) < w*h*bytesInPixel {
+		return matrix.ColorWhite()
+	}
+	u, v := float64(uv.X()), float64(uv.Y())
+	if math.IsNaN(u) || math.IsInf(u, 0) {
+		u = 0
+	}
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		v = 0
+	}
+	u, v = u*float64(w), v*float64(h)
+	if t.filter == TextureFilterNearest {
+		return t.texel(int(math.Floor(u)), int(math.Floor(v)))
+	}
+	u, v = u-0.5, v-0.5
+	x0, y0 := math.Floor(u), math.Floor(v)
+	fx, fy := float32(u-x0), float32(v-y0)
+	ix, iy := int(x0), int(y0)
+	top := softwareMixColor(t.texel(ix, iy), t.texel(ix+1, iy), fx)
+	bottom := softwareMixColor(t.texel(ix, iy+1), t.texel(ix+1, iy+1), fx)
+	return softwareMixColor(top, bottom, fy)
+}
+
+func softwareEdge(a, b matrix.Vec3, x, y matrix.Float) matrix.Float {
+	return (b.X()-a.X())*(y-a.Y()) - (b.Y()-a.Y())*(x-a.X())
+}
+
+// softwareSharedEdge is [softwareEdge] evaluated with the end points in the
+// same order no matter which way around the triangle the edge is walked. Two
+// triangles that share the edge then get exactly opposite weights, otherwise
+// rounding can leave pixels along the edge outside of both triangles.
+func softwareSharedEdge(a, b matrix.Vec3, x, y matrix.Float) matrix.Float {
+	if b.Y() < a.Y() || (b.Y() == a.Y() && b.X() < a.X()) {
+		return -softwareEdge(b, a, x, y)
+	}
+	return softwareEdge(a, b, x, y)
+}
+
+// softwareCovers applies a fill rule so pixels that land exactly on an edge
+// shared by two triangles are only drawn once
+func softwareCovers(w matrix.Float, a, b matrix.Vec3) bool {
+	if w != 0 {
+		return w > 0
+	}
+	dy := b.Y() - a.Y()
+	return dy > 0 || (dy == 0 && b.X() < a.X())
+}
+
+func softwareLerp3(a, b, c matrix.Vec3, w0, w1, w2 matrix.Float) matrix.Vec3 {
+	return a.Scale(w0).Add(b.Scale(w1)).Add(c.Scale(w2))
+}
+
+func softwareLerp2(a, b, c matrix.Vec2, w0, w1, w2 matrix.Float) matrix.Vec2 {
+	return a.Scale(w0).Add(b.Scale(w1)).Add(c.Scale(w2))
+}
+
+func softwareLerpColor(a, b, c matrix.Color, w0, w1, w2 float32) matrix.Color {
+	var out matrix.Color
+	for i := range out {
+		out[i] = a[i]*w0 + b[i]*w1 + c[i]*w2
+	}
+	return out
+}
+
+func softwareMixColor(a, b matrix.Color, t float32) matrix.Color {
+	var out matrix.Color
+	for i := range out {
+		out[i] = a[i] + (b[i]-a[i])*t
+	}
+	return out
+}
+
+func softwareMulColor(a, b matrix.Color) matrix.Color {
+	return matrix.Color{a[0] * b[0], a[1] * b[1], a[2] * b[2], a[3] * b[3]}
+}
+
+func softwareVec4Color(v matrix.Vec4) matrix.Color {
+	return matrix.Color{float32(v[0]), float32(v[1]), float32(v[2]), float32(v[3])}
+}
+
+func softwareVec3Color(v matrix.Vec3, alpha float32) matrix.Color {
+	return matrix.Color{float32(v[0]), float32(v[1]), float32(v[2]), alpha}
+}
+
+func softwareColorVec3(c matrix.Color) matrix.Vec3 {
+	return matrix.Vec3{matrix.Float(c[0]), matrix.Float(c[1]), matrix.Float(c[2])}
+}
+
+func softwareMaxVec3(v matrix.Vec3) matrix.Vec3 {
+	return matrix.Vec3{max(v[0], 0), max(v[1], 0), max(v[2], 0)}
+}
+
+func softwareSafeNormal(v, fallback matrix.Vec3) matrix.Vec3 {
+	if v.LengthSquared() <= 0.00000001 {
+		return fallback
+	}
+	return v.Normal()
+}
+
+func softwareSrgbToLinear(c matrix.Vec3) matrix.Vec3 {
+	return matrix.Vec3{
+		matrix.Pow(max(c[0], 0), 2.2),
+		matrix.Pow(max(c[1], 0), 2.2),
+		matrix.Pow(max(c[2], 0), 2.2),
+	}
+}
+
+func softwareLinearToSrgb(c matrix.Vec3) matrix.Vec3 {
+	return matrix.Vec3{
+		matrix.Pow(max(c[0], 0), 1/2.2),
+		matrix.Pow(max(c[1], 0), 1/2.2),
+		matrix.Pow(max(c[2], 0), 1/2.2),
+	}
+}
+
+func softwareAces(c matrix.Vec3) matrix.Vec3 {
+	var out matrix.Vec3
+	for i := range out {
+		x := c[i]
+		out[i] = softwareClamp01((x * (2.51*x + 0.03)) / (x*(2.43*x+0.59) + 0.14))
+	}
+	return out
+}
+
+func softwareProcessAxis(coord, border, ratio matrix.Float) matrix.Float {
+	l := border * ratio
+	lScale := 1 - l*2
+	bScale := 1 - border*2
+	if coord < l {
+		return coord / ratio
+	} else if coord > 1-l {
+		return 1 - ((1 - coord) / ratio)
+	}
+	return (coord-l)*(bScale/lScale) + border
+}
+
+func softwareRoundedBoxSDF(center, size matrix.Vec2, radius matrix.Vec4) matrix.Float {
+	rx, ry := radius.Y(), radius.Z()
+	if center.X() > 0 {
+		rx, ry = radius.X(), radius.W()
+	}
+	r := ry
+	if center.Y() < 0 {
+		r = rx
+	}
+	qx := matrix.Abs(center.X()) - size.X() + r
+	qy := matrix.Abs(center.Y()) - size.Y() + r
+	outside := matrix.Vec2{max(qx, 0), max(qy, 0)}
+	return min(max(qx, qy), 0) + outside.Length() - r
+}
+
+func softwareEdgeProximity(distance, width matrix.Float) matrix.Float {
+	if width <= 0 {
+		return 100000
+	}
+	return distance / width
+}
+
+func softwareSmoothstep(edge0, edge1, x matrix.Float) matrix.Float {
+	t := softwareClamp01((x - edge0) / (edge1 - edge0))
+	return t * t * (3 - 2*t)
+}
+
+func softwareClamp01[T float32 | float64](v T) T {
+	return min(max(v, 0), 1)
+}
+
+func softwareSign(v matrix.Float) matrix.Float {
+	if v > 0 {
+		return 1
+	} else if v < 0 {
+		return -1
+	}
+	return 0
+}
+
+func softwareUnorm(v float32) uint8 {
+	return uint8(math.Round(float64(softwareClamp01(v)) * 255))
+}
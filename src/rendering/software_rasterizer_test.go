@@ -0,0 +1,617 @@
+/******************************************************************************/
+/* software_rasterizer_test.go                                                */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"image"
+	"image/color"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"unsafe"
+
+	"kaijuengine.com/engine/assets"
+	"kaijuengine.com/engine/cameras"
+	"kaijuengine.com/matrix"
+)
+
+const softwareTestSize = 64
+
+var softwareTestTolerance = GoldenTolerance{Channel: 2, Pixels: 0.002}
+
+type softwareTestCaches struct {
+	db        assets.Database
+	shaders   ShaderCache
+	textures  TextureCache
+	meshes    MeshCache
+	fonts     FontCache
+	materials MaterialCache
+}
+
+func (c *softwareTestCaches) ShaderCache() *ShaderCache     { return &c.shaders }
+func (c *softwareTestCaches) TextureCache() *TextureCache   { return &c.textures }
+func (c *softwareTestCaches) MeshCache() *MeshCache         { return &c.meshes }
+func (c *softwareTestCaches) FontCache() *FontCache         { return &c.fonts }
+func (c *softwareTestCaches) MaterialCache() *MaterialCache { return &c.materials }
+func (c *softwareTestCaches) AssetDatabase() assets.Database {
+	return c.db
+}
+
+type softwareTestScene struct {
+	t        *testing.T
+	device   *GPUDevice
+	caches   *softwareTestCaches
+	drawings Drawings
+	camera   *cameras.StandardCamera
+	uiCamera *cameras.StandardCamera
+	lights   LightsForRender
+}
+
+type softwareTestStandard struct {
+	ShaderDataBase
+	Color matrix.Color
+	UVs   matrix.Vec4
+	Flags uint32
+}
+
+func (s softwareTestStandard) Size() int {
+	return int(ShaderBaseDataSize + unsafe.Sizeof(s.Color) + unsafe.Sizeof(s.UVs) + unsafe.Sizeof(s.Flags))
+}
+
+type softwareTestPBR struct {
+	ShaderDataBase
+	Color    matrix.Color
+	MeRoEmAo matrix.Vec4
+	Flags    uint32
+}
+
+func (s softwareTestPBR) Size() int {
+	return int(ShaderBaseDataSize + unsafe.Sizeof(s.Color) + unsafe.Sizeof(s.MeRoEmAo) +
+		unsafe.Sizeof(s.Flags))
+}
+
+type softwareTestUI struct {
+	ShaderDataBase
+	UVs          matrix.Vec4
+	FgColor      matrix.Color
+	BgColor      matrix.Color
+	Scissor      matrix.Vec4
+	Size2D       matrix.Vec4
+	BorderRadius matrix.Vec4
+	BorderSize   matrix.Vec4
+	BorderColor  [4]matrix.Color
+	BorderLen    matrix.Vec2
+	OutlineColor matrix.Color
+	OutlineSize  matrix.Vec2
+	softwareTestUIEffects
+}
+
+// softwareTestUIEffects mirrors ui.ShaderEffectData, it is bound as a storage
+// buffer rather than being part of the instance data
+type softwareTestUIEffects struct {
+	GradientColors [4]uint32
+	Gradient       [4]uint32
+	Shadow         matrix.Vec4
+	Effects        [4]uint32
+	FilterMatrix   [4]uint32
+	FilterParams   [4]uint32
+}
+
+func (s softwareTestUI) Size() int {
+	return int(ShaderBaseDataSize + unsafe.Sizeof(s.UVs) + unsafe.Sizeof(s.FgColor) +
+		unsafe.Sizeof(s.BgColor) + unsafe.Sizeof(s.Scissor) + unsafe.Sizeof(s.Size2D) +
+		unsafe.Sizeof(s.BorderRadius) + unsafe.Sizeof(s.BorderSize) +
+		unsafe.Sizeof(s.BorderColor) + unsafe.Sizeof(s.BorderLen) +
+		unsafe.Sizeof(s.OutlineColor) + unsafe.Sizeof(s.OutlineSize))
+}
+
+func (s *softwareTestUI) UpdateBoundData() bool { return true }
+
+func (s *softwareTestUI) InstanceBoundDataSize() int {
+	return int(unsafe.Sizeof(s.softwareTestUIEffects))
+}
+
+func (s *softwareTestUI) BoundDataPointer() unsafe.Pointer {
+	return unsafe.Pointer(&s.softwareTestUIEffects)
+}
+
+// softwareTestAssets flattens the built in renderer content the same way the
+// embedded database does so the standard materials can be loaded
+func softwareTestAssets(t *testing.T) assets.Database {
+	t.Helper()
+	files := map[string][]byte{}
+	root := filepath.FromSlash("../editor/editor_embedded_content/editor_content")
+	for _, dir := range []string{"renderer", "textures"} {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			data, err := os.ReadFile(path)
+			files[filepath.Base(path)] = data
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return assets.NewMockDB(files)
+}
+
+func newSoftwareTestScene(t *testing.T) *softwareTestScene {
+	t.Helper()
+	db := softwareTestAssets(t)
+	device := NewNullGPUDevice()
+	caches := &softwareTestCaches{
+		db:        db,
+		shaders:   NewShaderCache(device, db),
+		textures:  NewTextureCache(device, db),
+		meshes:    NewMeshCache(device, db),
+		fonts:     NewFontCache(device, db),
+		materials: NewMaterialCache(device, db),
+	}
+	if err := device.SetupCaches(caches); err != nil {
+		t.Fatal(err)
+	}
+	const size = softwareTestSize
+	s := &softwareTestScene{
+		t:        t,
+		device:   device,
+		caches:   caches,
+		drawings: NewDrawings(),
+		camera:   cameras.NewStandardCamera(size, size, size, size, matrix.Vec3{0, 0, 3}),
+		uiCamera: cameras.NewStandardCameraOrthographic(size, size, size, size, matrix.Vec3{0, 0, 250}),
+	}
+	s.camera.SetPositionAndLookAt(matrix.Vec3{0, 0, 3}, matrix.Vec3Zero())
+	return s
+}
+
+func (s *softwareTestScene) material(key string, textures ...*Texture) *Material {
+	s.t.Helper()
+	material, err := s.caches.materials.Material(key)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	if len(textures) > 0 {
+		material = material.CreateInstance(textures)
+	}
+	return material
+}
+
+func (s *softwareTestScene) texture(key string, width, height int, pixel func(x, y int) color.RGBA, filter TextureFilter) *Texture {
+	s.t.Helper()
+	mem := make([]byte, 0, width*height*bytesInPixel)
+	for y := range height {
+		for x := range width {
+			p := pixel(x, y)
+			mem = append(mem, p.R, p.G, p.B, p.A)
+		}
+	}
+	tex, err := s.caches.textures.InsertRawTexture(key, mem, width, height, filter)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return tex
+}
+
+func (s *softwareTestScene) add(material *Material, mesh *Mesh, sd DrawInstance, layer RenderLayerMask) {
+	s.drawings.AddDrawing(Drawing{
+		Material:   material,
+		Mesh:       mesh,
+		ShaderData: sd,
+		Layer:      layer,
+	})
+}
+
+func (s *softwareTestScene) render() *image.RGBA {
+	s.t.Helper()
+	s.caches.shaders.CreatePending()
+	s.caches.textures.ProcessPending()
+	s.caches.meshes.ProcessPending()
+	s.drawings.PreparePending(0)
+	views := []RenderViewFrame{newRenderViewFrame(newRenderView(RenderViewOptions{
+		Name:      DefaultRenderViewName,
+		Camera:    s.camera,
+		LayerMask: RenderLayerAll,
+		Clear:     true,
+	}, 0))}
+	s.drawings.CaptureFrameData(s.lights, views)
+	r := NewSoftwareRasterizer(softwareTestSize, softwareTestSize)
+	return r.Render(s.device, &s.drawings, s.camera, s.uiCamera, s.lights, views)
+}
+
+func softwareTestScale(scale matrix.Vec3) matrix.Mat4 {
+	m := matrix.Mat4Identity()
+	m.Scale(scale)
+	return m
+}
+
+func softwareTestTranslation(translation matrix.Vec3) matrix.Mat4 {
+	m := matrix.Mat4Identity()
+	m.SetTranslation(translation)
+	return m
+}
+
+// softwareTestUIModel places a UI quad of the given size in front of the UI
+// camera's far plane, the same as UI elements that are laid out
+func softwareTestUIModel(width, height matrix.Float) matrix.Mat4 {
+	m := softwareTestScale(matrix.Vec3{width, height, 1})
+	m.SetTranslation(matrix.Vec3{0, 0, 1})
+	return m
+}
+
+func softwareTestGolden(t *testing.T, name string, img image.Image) {
+	t.Helper()
+	path := filepath.Join("testdata", "software", name+".png")
+	if _, err := CompareGolden(path, img, softwareTestTolerance); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func softwareTestPixel(t *testing.T, img *image.RGBA, x, y int, want color.RGBA) {
+	t.Helper()
+	got := img.RGBAAt(x, y)
+	d := func(a, b uint8) int { return max(int(a)-int(b), int(b)-int(a)) }
+	if d(got.R, want.R) > 3 || d(got.G, want.G) > 3 || d(got.B, want.B) > 3 || d(got.A, want.A) > 3 {
+		t.Fatalf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+	}
+}
+
+func TestSoftwareRasterizerUnlit(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	checker := s.texture("checker", 2, 2, func(x, y int) color.RGBA {
+		if (x+y)%2 == 0 {
+			return color.RGBA{255, 255, 255, 255}
+		}
+		return color.RGBA{0, 0, 255, 255}
+	}, TextureFilterNearest)
+	sd := &softwareTestStandard{
+		ShaderDataBase: NewShaderDataBase(),
+		Color:          matrix.Color{1, 0.5, 0.5, 1},
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+	}
+	s.add(s.material(assets.MaterialDefinitionUnlit, checker), NewMeshQuad(&s.caches.meshes), sd, RenderLayerWorld)
+	img := s.render()
+	softwareTestPixel(t, img, 0, 0, color.RGBA{0, 0, 0, 255})
+	softwareTestPixel(t, img, softwareTestSize/2-4, softwareTestSize/2-4, color.RGBA{255, 128, 128, 255})
+	softwareTestPixel(t, img, softwareTestSize/2+4, softwareTestSize/2-4, color.RGBA{0, 0, 128, 255})
+	softwareTestGolden(t, "unlit", img)
+}
+
+func TestSoftwareRasterizerUIPanel(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	white := s.texture("white", 1, 1, func(x, y int) color.RGBA {
+		return color.RGBA{255, 255, 255, 255}
+	}, TextureFilterLinear)
+	red := matrix.ColorRed()
+	sd := &softwareTestUI{
+		ShaderDataBase: NewShaderDataBase(),
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+		FgColor:        matrix.ColorBlue(),
+		Scissor:        matrix.Vec4{-1000, -1000, 1000, 1000},
+		Size2D:         matrix.Vec4{48, 32, 1, 1},
+		BorderRadius:   matrix.Vec4{8, 8, 8, 8},
+		BorderSize:     matrix.Vec4{2, 2, 2, 2},
+		BorderColor:    [4]matrix.Color{red, red, red, red},
+	}
+	sd.SetModel(softwareTestUIModel(48, 32))
+	s.add(s.material(assets.MaterialDefinitionUITransparent, white), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	const cx, cy = softwareTestSize / 2, softwareTestSize / 2
+	softwareTestPixel(t, img, cx, cy, color.RGBA{0, 0, 255, 255})
+	softwareTestPixel(t, img, cx, cy-15, color.RGBA{255, 0, 0, 255})
+	softwareTestPixel(t, img, cx-23, cy-15, color.RGBA{0, 0, 0, 255})
+	softwareTestGolden(t, "ui_panel", img)
+}
+
+func TestSoftwareRasterizerUIScissor(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	sd := &softwareTestUI{
+		ShaderDataBase: NewShaderDataBase(),
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+		FgColor:        matrix.ColorWhite(),
+		Scissor:        matrix.Vec4{0, -1000, 1000, 1000},
+		Size2D:         matrix.Vec4{32, 32, 1, 1},
+	}
+	sd.SetModel(softwareTestUIModel(32, 32))
+	s.add(s.material(assets.MaterialDefinitionUI), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	softwareTestPixel(t, img, softwareTestSize/2+8, softwareTestSize/2, color.RGBA{255, 255, 255, 255})
+	softwareTestPixel(t, img, softwareTestSize/2-8, softwareTestSize/2, color.RGBA{0, 0, 0, 255})
+}
+
+func newSoftwareTestUIPanel(s *softwareTestScene, width, height matrix.Float, fg matrix.Color) *softwareTestUI {
+	sd := &softwareTestUI{
+		ShaderDataBase: NewShaderDataBase(),
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+		FgColor:        fg,
+		Scissor:        matrix.Vec4{-1000, -1000, 1000, 1000},
+		Size2D:         matrix.Vec4{width, height, 1, 1},
+	}
+	sd.SetModel(softwareTestUIModel(width, height))
+	return sd
+}
+
+func TestSoftwareRasterizerUIGradient(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	sd := newSoftwareTestUIPanel(s, 48, 32, matrix.ColorWhite())
+	red, blue := PackUnorm4x8(matrix.ColorRed()), PackUnorm4x8(matrix.ColorBlue())
+	// A linear gradient to the right with a hard stop in the middle
+	sd.GradientColors = [4]uint32{red, red, blue, blue}
+	sd.Gradient = [4]uint32{
+		PackHalf2x16(0, 0.5), PackHalf2x16(0.5, 1),
+		PackHalf2x16(math.Pi/2, softwareGradientLinear), PackHalf2x16(0.5, 0.5),
+	}
+	s.add(s.material(assets.MaterialDefinitionUI), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	const cx, cy = softwareTestSize / 2, softwareTestSize / 2
+	softwareTestPixel(t, img, cx-20, cy, color.RGBA{255, 0, 0, 255})
+	softwareTestPixel(t, img, cx-4, cy, color.RGBA{255, 0, 0, 255})
+	softwareTestPixel(t, img, cx+4, cy, color.RGBA{0, 0, 255, 255})
+	softwareTestPixel(t, img, cx+20, cy, color.RGBA{0, 0, 255, 255})
+}
+
+func TestSoftwareRasterizerUIBoxShadow(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	sd := newSoftwareTestUIPanel(s, 24, 24, matrix.ColorBlue())
+	sd.Shadow = matrix.Vec4{8, 8, 0, 0}
+	sd.Effects[0] = PackUnorm4x8(matrix.ColorRed())
+	s.add(s.material(assets.MaterialDefinitionUITransparent), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	const cx, cy = softwareTestSize / 2, softwareTestSize / 2
+	softwareTestPixel(t, img, cx, cy, color.RGBA{0, 0, 255, 255})
+	softwareTestPixel(t, img, cx+16, cy+16, color.RGBA{255, 0, 0, 255})
+	softwareTestPixel(t, img, cx-16, cy-16, color.RGBA{0, 0, 0, 255})
+	softwareTestPixel(t, img, cx+16, cy-10, color.RGBA{0, 0, 0, 255})
+}
+
+func TestSoftwareRasterizerUIInsetShadow(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	sd := newSoftwareTestUIPanel(s, 32, 32, matrix.ColorBlue())
+	sd.Shadow = matrix.Vec4{0, 0, 0, 4}
+	sd.Effects[0] = PackUnorm4x8(matrix.ColorRed())
+	sd.FilterParams[3] = softwareEffectInsetShadow
+	s.add(s.material(assets.MaterialDefinitionUI), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	const cx, cy = softwareTestSize / 2, softwareTestSize / 2
+	softwareTestPixel(t, img, cx, cy, color.RGBA{0, 0, 255, 255})
+	softwareTestPixel(t, img, cx-14, cy, color.RGBA{255, 0, 0, 255})
+	softwareTestPixel(t, img, cx, cy+14, color.RGBA{255, 0, 0, 255})
+}
+
+func TestSoftwareRasterizerUIColorFilter(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	sd := newSoftwareTestUIPanel(s, 32, 32, matrix.ColorRed())
+	// Swaps the red and blue channels
+	sd.FilterMatrix = [4]uint32{PackHalf2x16(0, 0), PackHalf2x16(1, 0), PackHalf2x16(1, 0), PackHalf2x16(1, 0)}
+	sd.FilterParams = [4]uint32{PackHalf2x16(0, 0), PackHalf2x16(0, 0), PackHalf2x16(1, 0), softwareEffectFilter}
+	s.add(s.material(assets.MaterialDefinitionUI), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	softwareTestPixel(t, img, softwareTestSize/2, softwareTestSize/2, color.RGBA{0, 0, 255, 255})
+}
+
+func TestSoftwareRasterizerUIBackdropFilter(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	quad := NewMeshQuad(&s.caches.meshes)
+	scene := &softwareTestStandard{ShaderDataBase: NewShaderDataBase(), Color: matrix.ColorRed(), UVs: matrix.Vec4{0, 0, 1, 1}}
+	scene.SetModel(softwareTestScale(matrix.Vec3{4, 4, 1}))
+	s.add(s.material(assets.MaterialDefinitionUnlit), quad, scene, RenderLayerWorld)
+	sd := newSoftwareTestUIPanel(s, 32, 32, matrix.ColorTransparent())
+	// No blur and invert(1), alpha = -1, beta = 0 and offset = 1
+	sd.Effects[2] = PackHalf2x16(0, -1)
+	sd.Effects[3] = PackHalf2x16(0, 1)
+	sd.FilterParams[3] = softwareEffectBackdrop
+	// The panel is drawn over its backdrop, the backdrop is drawn first even
+	// though its drawing was added last
+	s.add(s.material(assets.MaterialDefinitionUITransparent), quad, sd, RenderLayerUI)
+	backdrop := *sd
+	backdrop.ShaderDataBase = NewShaderDataBase()
+	backdrop.SetModel(softwareTestUIModel(32, 32))
+	s.add(s.material(assets.MaterialDefinitionUIBackdrop), quad, &backdrop, RenderLayerUI)
+	img := s.render()
+	softwareTestPixel(t, img, softwareTestSize/2, softwareTestSize/2, color.RGBA{0, 255, 255, 255})
+	softwareTestPixel(t, img, 4, 4, color.RGBA{255, 0, 0, 255})
+}
+
+func TestSoftwareGaussianBlur(t *testing.T) {
+	const w, h = 9, 1
+	src := make([]matrix.Color, w*h)
+	src[4] = matrix.ColorWhite()
+	out := softwareGaussianBlur(src, w, h, 1)
+	var sum float32
+	for i := range out {
+		sum += out[i].R()
+	}
+	if matrix.Abs(sum-1) > 0.0001 {
+		t.Fatalf("blur should preserve energy, got %f", sum)
+	}
+	if out[4].R() <= out[3].R() || out[3].R() <= out[2].R() || !matrix.Approx(out[3].R(), out[5].R()) {
+		t.Fatalf("blur should fall off evenly from the center, got %v", out)
+	}
+}
+
+func TestSoftwareRasterizerMSDFText(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	const size, pxRange = 32, 4
+	// A signed distance field of a ring, the same in every channel
+	glyph := s.texture("glyph", size, size, func(x, y int) color.RGBA {
+		d := matrix.Vec2{matrix.Float(x) + 0.5 - size/2, matrix.Float(y) + 0.5 - size/2}.Length()
+		dist := min(d-6, 12-d)
+		v := uint8(softwareClamp01(dist/(2*pxRange)+0.5) * 255)
+		return color.RGBA{v, v, v, 255}
+	}, TextureFilterLinear)
+	sd := &TextShaderData{
+		ShaderDataBase: NewShaderDataBase(),
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+		FgColor:        matrix.ColorYellow(),
+		BgColor:        matrix.ColorTransparent(),
+		Scissor:        matrix.Vec4{-1000, -1000, 1000, 1000},
+		PxRange:        matrix.Vec2{pxRange, pxRange},
+	}
+	sd.SetModel(softwareTestUIModel(48, 48))
+	s.add(s.material(assets.MaterialDefinitionTextTransparent, glyph), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	const cx, cy = softwareTestSize / 2, softwareTestSize / 2
+	softwareTestPixel(t, img, cx, cy, color.RGBA{0, 0, 0, 255})
+	softwareTestPixel(t, img, cx+13, cy, color.RGBA{255, 255, 0, 255})
+	softwareTestPixel(t, img, 2, 2, color.RGBA{0, 0, 0, 255})
+	softwareTestGolden(t, "msdf_text", img)
+}
+
+func TestSoftwareRasterizerMSDFTextShadow(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	const size, pxRange = 32, 4
+	glyph := s.texture("glyph", size, size, func(x, y int) color.RGBA {
+		d := matrix.Vec2{matrix.Float(x) + 0.5 - size/2, matrix.Float(y) + 0.5 - size/2}.Length()
+		dist := min(d-6, 12-d)
+		v := uint8(softwareClamp01(dist/(2*pxRange)+0.5) * 255)
+		return color.RGBA{v, v, v, 255}
+	}, TextureFilterLinear)
+	sd := &TextShaderData{
+		ShaderDataBase: NewShaderDataBase(),
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+		FgColor:        matrix.ColorYellow(),
+		BgColor:        matrix.ColorTransparent(),
+		Scissor:        matrix.Vec4{-1000, -1000, 1000, 1000},
+		PxRange:        matrix.Vec2{pxRange, pxRange},
+		Shadow:         matrix.Vec4{4, 0, 0, 0},
+		ShadowColor:    matrix.ColorRed(),
+	}
+	sd.SetModel(softwareTestUIModel(48, 48))
+	s.add(s.material(assets.MaterialDefinitionTextTransparent, glyph), NewMeshQuad(&s.caches.meshes), sd, RenderLayerUI)
+	img := s.render()
+	const cx, cy = softwareTestSize / 2, softwareTestSize / 2
+	softwareTestPixel(t, img, cx+13, cy, color.RGBA{255, 255, 0, 255})
+	// Just past the right edge of the ring is covered by the shadow, the
+	// left edge isn't
+	softwareTestPixel(t, img, cx+20, cy, color.RGBA{255, 0, 0, 255})
+	softwareTestPixel(t, img, cx-21, cy, color.RGBA{0, 0, 0, 255})
+}
+
+func TestSoftwareRasterizerPBR(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	light := NewLight(s.device, nil, nil, LightTypeDirectional)
+	light.SetDirection(matrix.Vec3{-0.3, -0.5, -1}.Normal())
+	light.SetIntensity(3)
+	s.lights.Lights = []Light{light}
+	sd := &softwareTestPBR{
+		ShaderDataBase: NewShaderDataBase(),
+		Color:          matrix.Color{0.8, 0.3, 0.2, 1},
+		MeRoEmAo:       matrix.Vec4{0, 0.5, 0, 1},
+	}
+	model := matrix.Mat4Identity()
+	model.Rotate(matrix.Vec3{25, 40, 0})
+	model.Scale(matrix.Vec3{1.2, 1.2, 1.2})
+	sd.SetModel(model)
+	material := s.material(assets.MaterialDefinitionPBR)
+	s.add(material, NewMeshTexturableCube(&s.caches.meshes), sd, RenderLayerWorld)
+	img := s.render()
+	softwareTestPixel(t, img, 0, 0, color.RGBA{0, 0, 0, 255})
+	center := img.RGBAAt(softwareTestSize/2, softwareTestSize/2)
+	if center.R <= center.G || center.R <= center.B || center.A != 255 {
+		t.Fatalf("lit cube center = %v, want an opaque red tinted surface", center)
+	}
+	softwareTestGolden(t, "pbr_cube", img)
+}
+
+func TestSoftwareRasterizerDepthAndPassOrder(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	near := &softwareTestStandard{ShaderDataBase: NewShaderDataBase(), Color: matrix.ColorGreen(), UVs: matrix.Vec4{0, 0, 1, 1}}
+	far := &softwareTestStandard{ShaderDataBase: NewShaderDataBase(), Color: matrix.ColorRed(), UVs: matrix.Vec4{0, 0, 1, 1}}
+	near.SetModel(softwareTestTranslation(matrix.Vec3{0, 0, 0.5}))
+	far.SetModel(softwareTestScale(matrix.Vec3{2, 2, 1}))
+	quad := NewMeshQuad(&s.caches.meshes)
+	unlit := s.material(assets.MaterialDefinitionUnlit)
+	s.add(unlit, quad, near, RenderLayerWorld)
+	s.add(unlit, quad, far, RenderLayerWorld)
+	panel := &softwareTestUI{
+		ShaderDataBase: NewShaderDataBase(),
+		UVs:            matrix.Vec4{0, 0, 1, 1},
+		FgColor:        matrix.ColorWhite(),
+		Scissor:        matrix.Vec4{-1000, -1000, 1000, 1000},
+		Size2D:         matrix.Vec4{4, 4, 1, 1},
+	}
+	panel.SetModel(softwareTestUIModel(4, 4))
+	s.add(s.material(assets.MaterialDefinitionUI), quad, panel, RenderLayerUI)
+	img := s.render()
+	const c = softwareTestSize / 2
+	// The UI pass is drawn after the world with its own depth buffer
+	softwareTestPixel(t, img, c, c, color.RGBA{255, 255, 255, 255})
+	softwareTestPixel(t, img, c+6, c, color.RGBA{0, 255, 0, 255})
+	softwareTestPixel(t, img, c+14, c, color.RGBA{255, 0, 0, 255})
+}
+
+func TestSoftwareRasterizerSkipsUncapturedViews(t *testing.T) {
+	s := newSoftwareTestScene(t)
+	sd := &softwareTestStandard{ShaderDataBase: NewShaderDataBase(), Color: matrix.ColorWhite(), UVs: matrix.Vec4{0, 0, 1, 1}}
+	s.add(s.material(assets.MaterialDefinitionUnlit), NewMeshQuad(&s.caches.meshes), sd, RenderLayerWorld)
+	s.caches.meshes.ProcessPending()
+	s.drawings.PreparePending(0)
+	r := NewSoftwareRasterizer(8, 8)
+	r.ClearColor = matrix.ColorBlue()
+	img := r.Render(s.device, &s.drawings, s.camera, s.uiCamera, s.lights, nil)
+	softwareTestPixel(t, img, 4, 4, color.RGBA{0, 0, 255, 255})
+	if r.Render(&GPUDevice{}, &s.drawings, s.camera, s.uiCamera, s.lights, nil).Bounds().Dx() != 8 {
+		t.Fatal("a non null device should still produce a cleared image")
+	}
+}
+
+func TestSoftwareSharedEdgeIsExactlyOpposite(t *testing.T) {
+	a := matrix.Vec3{3.1, 97.3, 0}
+	b := matrix.Vec3{151.7, 8.9, 0}
+	for y := range 96 {
+		for x := range 160 {
+			px, py := matrix.Float(x)+0.5, matrix.Float(y)+0.5
+			if softwareSharedEdge(a, b, px, py) != -softwareSharedEdge(b, a, px, py) {
+				t.Fatalf("the edge weights at (%d, %d) are not opposite", x, y)
+			}
+		}
+	}
+}
+
+func TestDiffImagesTolerance(t *testing.T) {
+	a := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	b := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	b.SetRGBA(1, 1, color.RGBA{3, 0, 0, 0})
+	b.SetRGBA(2, 2, color.RGBA{0, 40, 0, 0})
+	diff, err := DiffImages(a, b, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff.Mismatched != 1 || diff.MaxDelta != 40 || diff.Pixels != 16 {
+		t.Fatalf("diff = %+v, want 1 mismatched of 16 with a max delta of 40", diff)
+	}
+	if diff.Within(GoldenTolerance{Channel: 4, Pixels: 0.05}) {
+		t.Fatal("1 of 16 pixels is more than 5%")
+	}
+	if !diff.Within(GoldenTolerance{Channel: 4, Pixels: 0.1}) {
+		t.Fatal("1 of 16 pixels is less than 10%")
+	}
+	if _, err := DiffImages(a, image.NewRGBA(image.Rect(0, 0, 2, 2)), 0); err == nil {
+		t.Fatal("expected an error for images of different sizes")
+	}
+}
+
+func TestCompareGoldenRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.png")
+	img := image.NewRGBA(image.Rect(0, 0, 2, 2))
+	img.SetRGBA(0, 0, color.RGBA{255, 0, 0, 255})
+	if _, err := CompareGolden(path, img, GoldenTolerance{}); err == nil {
+		t.Fatal("expected an error for a missing golden")
+	}
+	if err := WriteGoldenPNG(path, img); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CompareGolden(path, img, GoldenTolerance{}); err != nil {
+		t.Fatal(err)
+	}
+	img.SetRGBA(1, 1, color.RGBA{0, 255, 0, 255})
+	if _, err := CompareGolden(path, img, GoldenTolerance{}); err == nil {
+		t.Fatal("expected a changed image to not match the golden")
+	}
+}
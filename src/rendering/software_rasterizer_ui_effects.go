@@ -0,0 +1,314 @@
+/******************************************************************************/
+/* software_rasterizer_ui_effects.go                                          */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"math"
+	"unsafe"
+
+	"kaijuengine.com/matrix"
+)
+
+// These mirror ui_effects.glsl, the gradients, box shadows and color filters
+// of UI panels that are packed into the UI effect storage buffer
+const (
+	softwareGradientNone      = 0
+	softwareGradientLinear    = 1
+	softwareGradientRadial    = 2
+	softwareGradientConic     = 3
+	softwareGradientRepeating = 4
+
+	softwareEffectFilter      = 1
+	softwareEffectInsetShadow = 2
+	softwareEffectBackdrop    = 4
+)
+
+// softwareUIEffects is the entry of an instance in the UI effect storage
+// buffer, it has the same layout as ui.ShaderEffectData
+type softwareUIEffects struct {
+	gradientColors [4]uint32
+	gradient       [4]uint32
+	shadow         matrix.Vec4
+	effects        [4]uint32
+	filterMatrix   [4]uint32
+	filterParams   [4]uint32
+}
+
+func (s *softwareInstance) uiEffects() softwareUIEffects {
+	var e softwareUIEffects
+	if p, ok := s.field("uiEffectData", int(unsafe.Sizeof(e))); ok {
+		e = *(*softwareUIEffects)(p)
+	}
+	return e
+}
+
+func (e *softwareUIEffects) flags() uint32 { return e.filterParams[3] }
+
+func (e *softwareUIEffects) shadowColor() matrix.Color {
+	return UnpackUnorm4x8(e.effects[0])
+}
+
+func (e *softwareUIEffects) hasOuterShadow() bool {
+	return e.effects[0]>>24 != 0 && e.flags()&softwareEffectInsetShadow == 0
+}
+
+// quadOutset mirrors uiQuadOutset, how far the quad grows for the outline and
+// the outer shadow
+func (e *softwareUIEffects) quadOutset(outlineSize matrix.Vec2) matrix.Float {
+	outset := max(0, outlineSize.X()) + max(0, outlineSize.Y())
+	if e.hasOuterShadow() {
+		s := e.shadow
+		outset = max(outset, max(matrix.Abs(s.X()), matrix.Abs(s.Y()))+s.Z()+max(s.W(), 0))
+	}
+	return outset
+}
+
+// softwareErf is the same approximation of the error function as uiErf
+func softwareErf(x matrix.Float) matrix.Float {
+	s := softwareSign(x)
+	a := matrix.Abs(x)
+	x = 1 + (0.278393+(0.230389+0.078108*(a*a))*a)*a
+	x *= x
+	return s - s/(x*x)
+}
+
+func softwareShadowCoverage(dist, blur, pixelScale matrix.Float) matrix.Float {
+	sigma := blur * 0.5
+	if sigma < 0.001 {
+		aa := pixelScale * softwareEdgeSoftness
+		return 1 - softwareSmoothstep(-aa, aa, dist)
+	}
+	return 0.5 - 0.5*softwareErf(dist/(sigma*math.Sqrt2))
+}
+
+func softwareBlendOver(top, bottom matrix.Color) matrix.Color {
+	a := top.A() + bottom.A()*(1-top.A())
+	if a <= 0 {
+		return matrix.Color{}
+	}
+	var out matrix.Color
+	for i := range 3 {
+		out[i] = (top[i]*top.A() + bottom[i]*bottom.A()*(1-top.A())) / a
+	}
+	out[3] = a
+	return out
+}
+
+// gradientColor mirrors uiGradientColor
+func (e *softwareUIEffects) gradientColor(pix, dims matrix.Vec2) matrix.Color {
+	angle, kindF := UnpackHalf2x16(e.gradient[2])
+	kind := int(kindF + 0.5)
+	kindType := kind & 3
+	if kindType == softwareGradientNone {
+		return matrix.Color{}
+	}
+	cx, cy := UnpackHalf2x16(e.gradient[3])
+	p := pix.Subtract(matrix.Vec2{matrix.Float(cx), matrix.Float(cy)}.Multiply(dims))
+	var t matrix.Float
+	switch kindType {
+	case softwareGradientLinear:
+		sin, cos := math.Sincos(float64(angle))
+		dir := matrix.Vec2{matrix.Float(sin), matrix.Float(-cos)}
+		length := matrix.Abs(dims.X()*dir.X()) + matrix.Abs(dims.Y()*dir.Y())
+		t = (p.X()*dir.X()+p.Y()*dir.Y())/max(length, 0.0001) + 0.5
+	case softwareGradientRadial:
+		rx, ry := UnpackHalf2x16(e.effects[1])
+		t = matrix.Vec2{
+			p.X() / max(matrix.Float(rx), 0.0001),
+			p.Y() / max(matrix.Float(ry), 0.0001),
+		}.Length()
+	default:
+		a := math.Atan2(float64(p.X()), float64(-p.Y())) - float64(angle)
+		a /= 2 * math.Pi
+		t = matrix.Float(a - math.Floor(a))
+	}
+	var stops [4]matrix.Float
+	s0, s1 := UnpackHalf2x16(e.gradient[0])
+	s2, s3 := UnpackHalf2x16(e.gradient[1])
+	stops[0], stops[1], stops[2], stops[3] = matrix.Float(s0), matrix.Float(s1), matrix.Float(s2), matrix.Float(s3)
+	if kind&softwareGradientRepeating != 0 {
+		period := stops[3] - stops[0]
+		if period > 0.0001 {
+			m := matrix.Float(math.Mod(float64(t-stops[0]), float64(period)))
+			if m < 0 {
+				m += period
+			}
+			t = stops[0] + m
+		}
+	}
+	premul := func(c matrix.Color) matrix.Color {
+		return matrix.Color{c.R() * c.A(), c.G() * c.A(), c.B() * c.A(), c.A()}
+	}
+	color := premul(UnpackUnorm4x8(e.gradientColors[0]))
+	for i := 1; i < 4; i++ {
+		w := stops[i] - stops[i-1]
+		var f matrix.Float
+		if w > 0.0001 {
+			f = softwareClamp01((t - stops[i-1]) / w)
+		} else if t >= stops[i] {
+			f = 1
+		}
+		color = softwareMixColor(color, premul(UnpackUnorm4x8(e.gradientColors[i])), float32(f))
+	}
+	if color.A() <= 0 {
+		return matrix.Color{}
+	}
+	return matrix.Color{color.R() / color.A(), color.G() / color.A(), color.B() / color.A(), color.A()}
+}
+
+// applyFilter mirrors uiApplyFilter
+func (e *softwareUIEffects) applyFilter(c matrix.Color) matrix.Color {
+	var m [9]float32
+	m[0], m[1] = UnpackHalf2x16(e.filterMatrix[0])
+	m[2], m[3] = UnpackHalf2x16(e.filterMatrix[1])
+	m[4], m[5] = UnpackHalf2x16(e.filterMatrix[2])
+	m[6], m[7] = UnpackHalf2x16(e.filterMatrix[3])
+	var o [3]float32
+	m[8], o[0] = UnpackHalf2x16(e.filterParams[0])
+	o[1], o[2] = UnpackHalf2x16(e.filterParams[1])
+	alpha, _ := UnpackHalf2x16(e.filterParams[2])
+	var out matrix.Color
+	for r := range 3 {
+		out[r] = softwareClamp01(m[r*3]*c.R() + m[r*3+1]*c.G() + m[r*3+2]*c.B() + o[r])
+	}
+	out[3] = softwareClamp01(c.A() * alpha)
+	return out
+}
+
+// backdropColor mirrors ui_backdrop_blur.frag for a single pixel of the
+// blurred copy of what was drawn behind the panel
+func (e *softwareUIEffects) backdropColor(blurred matrix.Color) matrix.Color {
+	_, alpha := UnpackHalf2x16(e.effects[2])
+	beta, offset := UnpackHalf2x16(e.effects[3])
+	luma := 0.2126*blurred.R() + 0.7152*blurred.G() + 0.0722*blurred.B()
+	var out matrix.Color
+	for i := range 3 {
+		out[i] = softwareClamp01(alpha*blurred[i] + beta*luma + offset)
+	}
+	out[3] = 1
+	return out
+}
+
+// shadeUIBackdrop mirrors ui_backdrop_blur.frag, the backdrop is masked by
+// the rounded shape of the panel
+func (d *softwareDraw) shadeUIBackdrop(f *softwareFragment) (matrix.Color, bool) {
+	inst := &d.instance
+	e := inst.uiEffects()
+	if e.flags()&softwareEffectBackdrop == 0 || d.backdrop == nil {
+		return matrix.Color{}, false
+	}
+	pix, dims, pixelScale := d.uiPixel(f, &e)
+	radius, _ := inst.vec4("borderRadius", matrix.Vec4{})
+	size := dims.Scale(0.5)
+	dist := softwareRoundedBoxSDF(size.Subtract(pix), size, radius)
+	shape := 1 - softwareSmoothstep(-pixelScale*softwareEdgeSoftness, pixelScale*softwareEdgeSoftness, dist)
+	if shape <= 0 {
+		return matrix.Color{}, false
+	}
+	out := e.backdropColor(d.backdrop[f.index])
+	out[3] = float32(shape)
+	return out, true
+}
+
+// uiPixel finds the position of the fragment within the panel in pixels (the
+// quad may be grown past the panel for the outline and shadow), the panel
+// dimensions and the screen space size of a pixel
+func (d *softwareDraw) uiPixel(f *softwareFragment, e *softwareUIEffects) (pix, dims matrix.Vec2, pixelScale matrix.Float) {
+	inst := &d.instance
+	uvs, _ := inst.vec4("uvs", matrix.Vec4{0, 0, 1, 1})
+	size2D, _ := inst.vec4("size2D", matrix.Vec4{})
+	outlineSize, _ := inst.vec2("outlineSize", matrix.Vec2{})
+	fragUvs := uvs
+	fragUvs[matrix.Vy] = (1.0 - uvs.W()) - uvs.Y()
+	normUV := matrix.Vec2{
+		(f.uv.X() - fragUvs.X()) / fragUvs.Z(),
+		(f.uv.Y() - fragUvs.Y()) / fragUvs.W(),
+	}
+	outset := e.quadOutset(outlineSize)
+	dims = matrix.Vec2{size2D.X(), size2D.Y()}
+	expanded := dims.Add(matrix.Vec2{outset * 2, outset * 2})
+	pix = matrix.Vec2{
+		normUV.X()*expanded.X() - outset,
+		normUV.Y()*expanded.Y() - outset,
+	}
+	pixelScale = max(
+		matrix.Abs(f.uvWidth.X()/fragUvs.Z()*expanded.X()),
+		matrix.Abs(f.uvWidth.Y()/fragUvs.W()*expanded.Y()),
+		0.001)
+	return pix, dims, pixelScale
+}
+
+// softwareGaussianBlur blurs the image with a separable gaussian kernel, the
+// sigma is in pixels
+func softwareGaussianBlur(src []matrix.Color, width, height int, sigma float32) []matrix.Color {
+	out := make([]matrix.Color, len(src))
+	copy(out, src)
+	if sigma < 0.5 {
+		return out
+	}
+	radius := int(math.Ceil(float64(sigma) * 3))
+	kernel := make([]float32, radius*2+1)
+	var sum float32
+	for i := range kernel {
+		x := float32(i - radius)
+		kernel[i] = float32(math.Exp(float64(-x * x / (2 * sigma * sigma))))
+		sum += kernel[i]
+	}
+	for i := range kernel {
+		kernel[i] /= sum
+	}
+	tmp := make([]matrix.Color, len(src))
+	pass := func(dst, from []matrix.Color, dx, dy int) {
+		for y := range height {
+			for x := range width {
+				var c matrix.Color
+				for k, w := range kernel {
+					// Clamp to edge, the same as the sampler of the copy
+					sx := min(max(x+(k-radius)*dx, 0), width-1)
+					sy := min(max(y+(k-radius)*dy, 0), height-1)
+					s := from[sy*width+sx]
+					for i := range 4 {
+						c[i] += s[i] * w
+					}
+				}
+				dst[y*width+x] = c
+			}
+		}
+	}
+	pass(tmp, src, 1, 0)
+	pass(out, tmp, 0, 1)
+	return out
+}
+
+// softwareUVDerivatives is the screen space rate of change of the texture
+// coordinates over the triangle along x and y, what dFdx and dFdy return
+func softwareUVDerivatives(a, b, c *softwareVertex, area matrix.Float) (dx, dy matrix.Vec2) {
+	x1, y1 := b.screen.X()-a.screen.X(), b.screen.Y()-a.screen.Y()
+	x2, y2 := c.screen.X()-a.screen.X(), c.screen.Y()-a.screen.Y()
+	for i := range 2 {
+		d1, d2 := b.uv[i]-a.uv[i], c.uv[i]-a.uv[i]
+		dx[i] = (d1*y2 - d2*y1) / area
+		dy[i] = (d2*x1 - d1*x2) / area
+	}
+	return dx, dy
+}
+
+// textShadowOpacity mirrors shadowOpacity in text.frag
+func (d *softwareDraw) textShadowOpacity(f *softwareFragment, tex softwareTexture, screenPxRange matrix.Float) matrix.Float {
+	shadow, _ := d.instance.vec4("shadow", matrix.Vec4{})
+	uvs, _ := d.instance.vec4("uvs", matrix.Vec4{0, 0, 1, 1})
+	rectMin := matrix.Vec2{uvs.X(), (1 - uvs.W()) - uvs.Y()}
+	rectMax := rectMin.Add(matrix.Vec2{uvs.Z(), uvs.W()})
+	uv := f.uv.Subtract(f.uvDx.Scale(shadow.X())).Subtract(f.uvDy.Scale(shadow.Y()))
+	uv = matrix.Vec2{
+		min(max(uv.X(), rectMin.X()), rectMax.X()),
+		min(max(uv.Y(), rectMin.Y()), rectMax.Y()),
+	}
+	msdf := tex.sample(uv)
+	dist := matrix.Float(max(min(msdf.R(), msdf.G()), min(max(msdf.R(), msdf.G()), msdf.B()))) - 0.5
+	return softwareClamp01(dist*screenPxRange/max(1, shadow.Z()) + 0.5)
+}
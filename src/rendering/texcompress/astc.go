@@ -0,0 +1,68 @@
+/******************************************************************************/
+/* astc.go                                                                    */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+/*
+	ASTC notes:
+	Blocks are 4x4 texels written with a single partition, the LDR RGBA direct
+	endpoint mode (12) at 8 bits per channel and a 4x4 grid of 2 bit weights.
+	The block mode bits 0x042 select that weight grid and range. The endpoints
+	start at bit 17 in the order r0 r1 g0 g1 b0 b1 a0 a1 and the weights are
+	written backwards from the last bit of the block. The decoder swaps the
+	endpoints when the second is darker than the first, so they are written in
+	the order it expects.
+*/
+
+const (
+	astcBlockBytes     = 16
+	astcBlockMode4x4Q4 = 0x042
+	astcEndpointRGBA   = 12
+)
+
+var astcWeights2 = [4]int32{0, 21, 43, 64}
+
+func encodeASTCBlock(b *block, out []byte) {
+	use := [16]bool{true, true, true, true, true, true, true, true,
+		true, true, true, true, true, true, true, true}
+	lo, hi := blockEndpoints(b, &use, 4)
+	var e0, e1 [4]uint32
+	for c := range 4 {
+		e0[c] = uint32(lo[c] + 0.5)
+		e1[c] = uint32(hi[c] + 0.5)
+	}
+	if e1[0]+e1[1]+e1[2] < e0[0]+e0[1]+e0[2] {
+		e0, e1 = e1, e0
+	}
+	var palette [4][4]int32
+	for i := range palette {
+		for c := range 4 {
+			// Endpoints are expanded to 16 bits before interpolating
+			a, z := int32(e0[c])*257, int32(e1[c])*257
+			palette[i][c] = (((64-astcWeights2[i])*a + astcWeights2[i]*z + 32) >> 6) >> 8
+		}
+	}
+	for i := range out[:astcBlockBytes] {
+		out[i] = 0
+	}
+	w := bitWriter{out: out}
+	w.write(astcBlockMode4x4Q4, 11)
+	w.write(0, 2)
+	w.write(astcEndpointRGBA, 4)
+	for c := range 4 {
+		w.write(e0[c], 8)
+		w.write(e1[c], 8)
+	}
+	for i := range b {
+		weight := nearestIndex(b[i], palette[:], 4)
+		for bit := range 2 {
+			if weight>>bit&1 != 0 {
+				pos := 127 - (i*2 + bit)
+				out[pos>>3] |= 1 << (pos & 7)
+			}
+		}
+	}
+}
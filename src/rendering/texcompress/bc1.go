@@ -0,0 +1,80 @@
+/******************************************************************************/
+/* bc1.go                                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+import "encoding/binary"
+
+/*
+	BC1 notes:
+	A block is two RGB565 endpoints followed by 2 bit indices for each texel.
+	When the first endpoint is greater than the second the palette is the two
+	endpoints and two colors between them, otherwise it is the endpoints, their
+	midpoint and transparent black. The second mode is only used when the block
+	has texels with an alpha below half.
+*/
+
+const bc1BlockBytes = 8
+
+func to565(c [4]float32) uint16 {
+	r := uint16(c[0]*31/255 + 0.5)
+	g := uint16(c[1]*63/255 + 0.5)
+	b := uint16(c[2]*31/255 + 0.5)
+	return r<<11 | g<<5 | b
+}
+
+func from565(c uint16) [4]int32 {
+	r, g, b := int32(c>>11&31), int32(c>>5&63), int32(c&31)
+	return [4]int32{r<<3 | r>>2, g<<2 | g>>4, b<<3 | b>>2, 255}
+}
+
+func encodeBC1Opaque(b *block, out []byte) { encodeBC1Block(b, out, false) }
+func encodeBC1Alpha(b *block, out []byte)  { encodeBC1Block(b, out, true) }
+
+// encodeBC1Block writes the color of the block, when cutout is set texels with
+// an alpha below half are written as transparent
+func encodeBC1Block(b *block, out []byte, cutout bool) {
+	var use [16]bool
+	transparent := false
+	for i := range b {
+		use[i] = !cutout || b[i][3] >= 128
+		transparent = transparent || !use[i]
+	}
+	lo, hi := blockEndpoints(b, &use, 3)
+	c0, c1 := to565(hi), to565(lo)
+	var palette [][4]int32
+	if transparent {
+		if c0 > c1 {
+			c0, c1 = c1, c0
+		}
+		p0, p1 := from565(c0), from565(c1)
+		palette = [][4]int32{p0, p1, {(p0[0] + p1[0]) / 2, (p0[1] + p1[1]) / 2, (p0[2] + p1[2]) / 2, 255}}
+	} else {
+		if c0 < c1 {
+			c0, c1 = c1, c0
+		}
+		p0, p1 := from565(c0), from565(c1)
+		palette = [][4]int32{p0, p1,
+			{(2*p0[0] + p1[0]) / 3, (2*p0[1] + p1[1]) / 3, (2*p0[2] + p1[2]) / 3, 255},
+			{(p0[0] + 2*p1[0]) / 3, (p0[1] + 2*p1[1]) / 3, (p0[2] + 2*p1[2]) / 3, 255},
+		}
+		if c0 == c1 {
+			// Equal endpoints decode as the 3 color mode, index 0 is still the color
+			palette = palette[:1]
+		}
+	}
+	indices := uint32(0)
+	for i := range b {
+		idx := 3
+		if use[i] {
+			idx = nearestIndex(b[i], palette, 3)
+		}
+		indices |= uint32(idx) << (i * 2)
+	}
+	binary.LittleEndian.PutUint16(out[0:], c0)
+	binary.LittleEndian.PutUint16(out[2:], c1)
+	binary.LittleEndian.PutUint32(out[4:], indices)
+}
@@ -0,0 +1,51 @@
+/******************************************************************************/
+/* bc4.go                                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+/*
+	BC4 notes:
+	A single channel block is two 8 bit endpoints followed by 3 bit indices for
+	each texel. With the first endpoint greater than the second there are 6
+	values evenly spaced between them. BC3 uses this block for alpha and BC5
+	uses one for each of the red and green channels.
+*/
+
+const bc4BlockBytes = 8
+
+func encodeBC4Block(b *block, channel int, out []byte) {
+	lo, hi := b[0][channel], b[0][channel]
+	for i := range b {
+		lo, hi = min(lo, b[i][channel]), max(hi, b[i][channel])
+	}
+	out[0], out[1] = hi, lo
+	for i := 2; i < bc4BlockBytes; i++ {
+		out[i] = 0
+	}
+	if hi == lo {
+		return
+	}
+	var palette [8][4]int32
+	palette[0][0], palette[1][0] = int32(hi), int32(lo)
+	for i := int32(2); i < 8; i++ {
+		palette[i][0] = ((8-i)*int32(hi) + (i-1)*int32(lo)) / 7
+	}
+	w := bitWriter{out: out[2:]}
+	for i := range b {
+		w.write(uint32(nearestIndex([4]byte{b[i][channel]}, palette[:], 1)), 3)
+	}
+}
+
+func encodeBC3Block(b *block, out []byte) {
+	encodeBC4Block(b, 3, out[:bc4BlockBytes])
+	// The color half of BC3 always decodes with 4 colors
+	encodeBC1Block(b, out[bc4BlockBytes:], false)
+}
+
+func encodeBC5Block(b *block, out []byte) {
+	encodeBC4Block(b, 0, out[:bc4BlockBytes])
+	encodeBC4Block(b, 1, out[bc4BlockBytes:])
+}
@@ -0,0 +1,81 @@
+/******************************************************************************/
+/* bc7.go                                                                     */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+/*
+	BC7 notes:
+	Only mode 6 is written, it is a single subset with 7 bit RGBA endpoints, a
+	unique p-bit for each endpoint and 4 bit indices. The index of the first
+	texel drops its high bit, so the endpoints are swapped when needed to keep
+	it below 8.
+*/
+
+const bc7BlockBytes = 16
+
+var bc7Weights4 = [16]int32{0, 4, 9, 13, 17, 21, 26, 30, 34, 38, 43, 47, 51, 55, 60, 64}
+
+// bc7QuantizeEndpoint picks the p-bit with the least error for the endpoint
+// and returns the 7 bit channels
+func bc7QuantizeEndpoint(c [4]float32) ([4]uint32, uint32) {
+	var best [4]uint32
+	bestP, bestErr := uint32(0), float32(-1)
+	for p := range uint32(2) {
+		var q [4]uint32
+		e := float32(0)
+		for i := range 4 {
+			v := min(max(int((c[i]-float32(p))/2+0.5), 0), 127)
+			q[i] = uint32(v)
+			d := float32(v<<1|int(p)) - c[i]
+			e += d * d
+		}
+		if bestErr < 0 || e < bestErr {
+			best, bestP, bestErr = q, p, e
+		}
+	}
+	return best, bestP
+}
+
+func encodeBC7Block(b *block, out []byte) {
+	use := [16]bool{true, true, true, true, true, true, true, true,
+		true, true, true, true, true, true, true, true}
+	lo, hi := blockEndpoints(b, &use, 4)
+	e0, p0 := bc7QuantizeEndpoint(lo)
+	e1, p1 := bc7QuantizeEndpoint(hi)
+	var palette [16][4]int32
+	for i := range palette {
+		for c := range 4 {
+			a, z := int32(e0[c]<<1|p0), int32(e1[c]<<1|p1)
+			palette[i][c] = ((64-bc7Weights4[i])*a + bc7Weights4[i]*z + 32) >> 6
+		}
+	}
+	var indices [16]uint32
+	for i := range b {
+		indices[i] = uint32(nearestIndex(b[i], palette[:], 4))
+	}
+	if indices[0] >= 8 {
+		e0, e1 = e1, e0
+		p0, p1 = p1, p0
+		for i := range indices {
+			indices[i] = 15 - indices[i]
+		}
+	}
+	for i := range out[:bc7BlockBytes] {
+		out[i] = 0
+	}
+	w := bitWriter{out: out}
+	w.write(1<<6, 7)
+	for c := range 4 {
+		w.write(e0[c], 7)
+		w.write(e1[c], 7)
+	}
+	w.write(p0, 1)
+	w.write(p1, 1)
+	w.write(indices[0], 3)
+	for i := 1; i < len(indices); i++ {
+		w.write(indices[i], 4)
+	}
+}
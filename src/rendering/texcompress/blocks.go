@@ -0,0 +1,150 @@
+/******************************************************************************/
+/* blocks.go                                                                  */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+import (
+	"math"
+	"sync"
+)
+
+// block is the 4x4 texels of a compressed block in row major order
+type block [16][4]byte
+
+type blockEncoder func(b *block, out []byte)
+
+// encodeBlocks splits the RGBA8 level into 4x4 blocks and encodes each one,
+// texels past the edge of the image repeat the last row and column
+func encodeBlocks(level MipLevel, blockBytes int, encode blockEncoder) []byte {
+	bw, bh := (level.Width+3)/4, (level.Height+3)/4
+	out := make([]byte, bw*bh*blockBytes)
+	wg := sync.WaitGroup{}
+	for by := range bh {
+		wg.Add(1)
+		go func(by int) {
+			defer wg.Done()
+			var b block
+			for bx := range bw {
+				for i := range 16 {
+					x := min(bx*4+i%4, level.Width-1)
+					y := min(by*4+i/4, level.Height-1)
+					s := (y*level.Width + x) * 4
+					copy(b[i][:], level.Pix[s:s+4])
+				}
+				o := (by*bw + bx) * blockBytes
+				encode(&b, out[o:o+blockBytes])
+			}
+		}(by)
+	}
+	wg.Wait()
+	return out
+}
+
+// blockEndpoints finds the two colors at the ends of the line that best fits
+// the used texels of the block, only the first channels are considered. The
+// line is the principal axis of the texels found through power iteration.
+func blockEndpoints(b *block, use *[16]bool, channels int) (lo, hi [4]float32) {
+	var mean [4]float32
+	count := float32(0)
+	for i := range b {
+		if use[i] {
+			for c := range channels {
+				mean[c] += float32(b[i][c])
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		return lo, hi
+	}
+	for c := range channels {
+		mean[c] /= count
+	}
+	var cov [4][4]float32
+	for i := range b {
+		if !use[i] {
+			continue
+		}
+		var d [4]float32
+		for c := range channels {
+			d[c] = float32(b[i][c]) - mean[c]
+		}
+		for r := range channels {
+			for c := range channels {
+				cov[r][c] += d[r] * d[c]
+			}
+		}
+	}
+	axis := [4]float32{1, 1, 1, 1}
+	for range 8 {
+		var next [4]float32
+		for r := range channels {
+			for c := range channels {
+				next[r] += cov[r][c] * axis[c]
+			}
+		}
+		l := float32(0)
+		for c := range channels {
+			l += next[c] * next[c]
+		}
+		if l < 1e-12 {
+			break
+		}
+		l = float32(math.Sqrt(float64(l)))
+		for c := range channels {
+			axis[c] = next[c] / l
+		}
+	}
+	tMin, tMax := float32(math.MaxFloat32), float32(-math.MaxFloat32)
+	for i := range b {
+		if !use[i] {
+			continue
+		}
+		t := float32(0)
+		for c := range channels {
+			t += (float32(b[i][c]) - mean[c]) * axis[c]
+		}
+		tMin, tMax = min(tMin, t), max(tMax, t)
+	}
+	for c := range channels {
+		lo[c] = min(max(mean[c]+axis[c]*tMin, 0), 255)
+		hi[c] = min(max(mean[c]+axis[c]*tMax, 0), 255)
+	}
+	return lo, hi
+}
+
+// nearestIndex returns the index of the palette entry closest to the texel
+// over the first channels
+func nearestIndex(texel [4]byte, palette [][4]int32, channels int) int {
+	best, bestErr := 0, int32(math.MaxInt32)
+	for i := range palette {
+		e := int32(0)
+		for c := range channels {
+			d := int32(texel[c]) - palette[i][c]
+			e += d * d
+		}
+		if e < bestErr {
+			best, bestErr = i, e
+		}
+	}
+	return best
+}
+
+// bitWriter writes values into a block least significant bit first, the
+// block is expected to be zeroed
+type bitWriter struct {
+	out []byte
+	pos int
+}
+
+func (w *bitWriter) write(value uint32, bits int) {
+	for i := range bits {
+		if value>>i&1 != 0 {
+			w.out[w.pos>>3] |= 1 << (w.pos & 7)
+		}
+		w.pos++
+	}
+}
@@ -0,0 +1,178 @@
+/******************************************************************************/
+/* mipmaps.go                                                                 */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+import (
+	"math"
+	"sync"
+
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+// MipLevel is a single RGBA8 image within a mip chain
+type MipLevel struct {
+	Width  int
+	Height int
+	Pix    []byte
+}
+
+// The separable filter used to halve a level, it is a box filter widened by a
+// texel on each side which keeps thin details from flickering between levels
+var mipFilter = [4]float32{1.0 / 8.0, 3.0 / 8.0, 3.0 / 8.0, 1.0 / 8.0}
+
+var srgbToLinearTable = func() (lut [256]float32) {
+	for i := range lut {
+		c := float64(i) / 255.0
+		if c <= 0.04045 {
+			lut[i] = float32(c / 12.92)
+		} else {
+			lut[i] = float32(math.Pow((c+0.055)/1.055, 2.4))
+		}
+	}
+	return lut
+}()
+
+func linearToSrgb(v float32) byte {
+	c := float64(min(max(v, 0), 1))
+	if c <= 0.0031308 {
+		c *= 12.92
+	} else {
+		c = 1.055*math.Pow(c, 1.0/2.4) - 0.055
+	}
+	return byte(c*255.0 + 0.5)
+}
+
+func unitToByte(v float32) byte {
+	return byte(min(max(v, 0), 1)*255.0 + 0.5)
+}
+
+// GenerateMipmaps creates the full mip chain for the RGBA8 pixels down to a
+// single texel, the first level is the image itself. Each level is filtered
+// from the one above it in the space that suits the usage: color is averaged
+// in linear space (weighted by alpha) and converted back to sRGB, normals are
+// decoded, averaged and renormalized, and linear data is averaged as it is.
+func GenerateMipmaps(pix []byte, width, height int, usage Usage) []MipLevel {
+	defer tracing.NewRegion("texcompress.GenerateMipmaps").End()
+	levels := []MipLevel{{width, height, pix}}
+	src := decodeLevel(pix, usage)
+	w, h := width, height
+	for w > 1 || h > 1 {
+		dw, dh := max(w/2, 1), max(h/2, 1)
+		dst := downsample(src, w, h, dw, dh, usage)
+		levels = append(levels, MipLevel{dw, dh, encodeLevel(dst, usage)})
+		src, w, h = dst, dw, dh
+	}
+	return levels
+}
+
+// MipCount returns the number of levels in a full mip chain for the size
+func MipCount(width, height int) int {
+	count := 1
+	for width > 1 || height > 1 {
+		width, height = max(width/2, 1), max(height/2, 1)
+		count++
+	}
+	return count
+}
+
+func decodeLevel(pix []byte, usage Usage) []float32 {
+	out := make([]float32, len(pix))
+	for i := 0; i+3 < len(pix); i += 4 {
+		switch usage {
+		case UsageNormal:
+			x := float32(pix[i])/127.5 - 1
+			y := float32(pix[i+1])/127.5 - 1
+			z := float32(pix[i+2])/127.5 - 1
+			out[i], out[i+1], out[i+2] = normalize(x, y, z)
+		case UsageLinear:
+			for c := range 3 {
+				out[i+c] = float32(pix[i+c]) / 255.0
+			}
+		default:
+			// Color is stored premultiplied so that transparent texels don't
+			// bleed their color into the lower levels
+			a := float32(pix[i+3]) / 255.0
+			for c := range 3 {
+				out[i+c] = srgbToLinearTable[pix[i+c]] * a
+			}
+		}
+		out[i+3] = float32(pix[i+3]) / 255.0
+	}
+	return out
+}
+
+func encodeLevel(src []float32, usage Usage) []byte {
+	out := make([]byte, len(src))
+	for i := 0; i+3 < len(src); i += 4 {
+		a := src[i+3]
+		switch usage {
+		case UsageNormal:
+			for c := range 3 {
+				out[i+c] = unitToByte(src[i+c]*0.5 + 0.5)
+			}
+		case UsageLinear:
+			for c := range 3 {
+				out[i+c] = unitToByte(src[i+c])
+			}
+		default:
+			for c := range 3 {
+				if a > 0 {
+					out[i+c] = linearToSrgb(src[i+c] / a)
+				}
+			}
+		}
+		out[i+3] = unitToByte(a)
+	}
+	return out
+}
+
+func normalize(x, y, z float32) (float32, float32, float32) {
+	l := float32(math.Sqrt(float64(x*x + y*y + z*z)))
+	if l < 1e-6 {
+		return 0, 0, 1
+	}
+	return x / l, y / l, z / l
+}
+
+func downsample(src []float32, w, h, dw, dh int, usage Usage) []float32 {
+	dst := make([]float32, dw*dh*4)
+	wg := sync.WaitGroup{}
+	for y := range dh {
+		wg.Add(1)
+		go func(y int) {
+			defer wg.Done()
+			for x := range dw {
+				var sum [4]float32
+				for j := range 4 {
+					// A dimension that is already 1 texel is not halved
+					sy := y
+					if h > 1 {
+						sy = min(max(2*y-1+j, 0), h-1)
+					}
+					for i := range 4 {
+						sx := x
+						if w > 1 {
+							sx = min(max(2*x-1+i, 0), w-1)
+						}
+						weight := mipFilter[i] * mipFilter[j]
+						s := (sy*w + sx) * 4
+						for c := range 4 {
+							sum[c] += src[s+c] * weight
+						}
+					}
+				}
+				d := (y*dw + x) * 4
+				if usage == UsageNormal {
+					sum[0], sum[1], sum[2] = normalize(sum[0], sum[1], sum[2])
+				}
+				copy(dst[d:d+4], sum[:])
+			}
+		}(y)
+	}
+	wg.Wait()
+	return dst
+}
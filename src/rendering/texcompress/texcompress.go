@@ -0,0 +1,169 @@
+/******************************************************************************/
+/* texcompress.go                                                             */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+
+	"kaijuengine.com/platform/profiler/tracing"
+	"kaijuengine.com/rendering"
+)
+
+// Format is the GPU format a texture is compressed into
+type Format string
+
+const (
+	// FormatNone keeps the texture as uncompressed RGBA8
+	FormatNone    Format = "none"
+	FormatBC1     Format = "bc1"
+	FormatBC3     Format = "bc3"
+	FormatBC5     Format = "bc5"
+	FormatBC7     Format = "bc7"
+	FormatASTC4x4 Format = "astc4x4"
+)
+
+// Usage describes what the texels of a texture mean, which changes how the mip
+// levels are filtered and which format a [Profile] picks for it
+type Usage string
+
+const (
+	// UsageColor is sRGB color, this is the default
+	UsageColor Usage = "color"
+	// UsageNormal is a tangent space normal map with XYZ encoded as RGB
+	UsageNormal Usage = "normal"
+	// UsageLinear is any other data, such as metallic/roughness or masks
+	UsageLinear Usage = "linear"
+)
+
+// Profile is the set of formats a platform uses for each kind of texture
+type Profile struct {
+	Name       string
+	Color      Format
+	ColorAlpha Format
+	Normal     Format
+	Linear     Format
+}
+
+var (
+	// ProfileDesktop targets GPUs with BCn support. Normal maps use BC7 rather
+	// than BC5 because BC5 only stores X and Y, and the engine's shaders read
+	// all 3 channels of the normal map.
+	ProfileDesktop = Profile{
+		Name:       "desktop",
+		Color:      FormatBC1,
+		ColorAlpha: FormatBC3,
+		Normal:     FormatBC7,
+		Linear:     FormatBC7,
+	}
+	// ProfileAndroid targets mobile GPUs, which support ASTC rather than BCn
+	ProfileAndroid = Profile{
+		Name:       "android",
+		Color:      FormatASTC4x4,
+		ColorAlpha: FormatASTC4x4,
+		Normal:     FormatASTC4x4,
+		Linear:     FormatASTC4x4,
+	}
+)
+
+// ProfileByName returns the profile with the name, the second return value is
+// false if there is no profile with that name
+func ProfileByName(name string) (Profile, bool) {
+	switch name {
+	case ProfileDesktop.Name:
+		return ProfileDesktop, true
+	case ProfileAndroid.Name:
+		return ProfileAndroid, true
+	}
+	return Profile{}, false
+}
+
+// Settings are the per texture options for [Compress]
+type Settings struct {
+	Usage Usage
+	// Format overrides the format the profile would pick when it isn't empty
+	Format    Format
+	NoMipmaps bool
+}
+
+// FormatFor returns the format the profile uses for the usage
+func (p Profile) FormatFor(usage Usage, hasAlpha bool) Format {
+	switch usage {
+	case UsageNormal:
+		return p.Normal
+	case UsageLinear:
+		return p.Linear
+	default:
+		if hasAlpha {
+			return p.ColorAlpha
+		}
+		return p.Color
+	}
+}
+
+// Compress generates the mip levels for the image, encodes each of them in
+// the format picked by the settings and profile, and returns the KTX2 file.
+// All formats are written as UNORM, the shaders convert sRGB color to linear
+// themselves just as they do for uncompressed textures.
+func Compress(img image.Image, settings Settings, profile Profile) ([]byte, error) {
+	defer tracing.NewRegion("texcompress.Compress").End()
+	bounds := img.Bounds()
+	if bounds.Empty() {
+		return nil, fmt.Errorf("the image to compress is empty")
+	}
+	// Drawn the same way the renderer reads PNG files, so the texels match
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	w, h := bounds.Dx(), bounds.Dy()
+	usage := settings.Usage
+	if usage == "" {
+		usage = UsageColor
+	}
+	hasAlpha := false
+	for i := 3; i < len(rgba.Pix) && !hasAlpha; i += 4 {
+		hasAlpha = rgba.Pix[i] < 255
+	}
+	format := settings.Format
+	if format == "" {
+		format = profile.FormatFor(usage, hasAlpha)
+	}
+	levels := []MipLevel{{w, h, rgba.Pix}}
+	if !settings.NoMipmaps {
+		levels = GenerateMipmaps(rgba.Pix, w, h, usage)
+	}
+	tex := rendering.KTX2Texture{Width: w, Height: h, Levels: make([][]byte, len(levels))}
+	var encode blockEncoder
+	blockBytes := 16
+	switch format {
+	case FormatNone:
+		tex.Format = rendering.GPUFormatR8g8b8a8Unorm
+	case FormatBC1:
+		tex.Format, encode, blockBytes = rendering.GPUFormatBc1RgbUnormBlock, encodeBC1Opaque, bc1BlockBytes
+		if hasAlpha {
+			tex.Format, encode = rendering.GPUFormatBc1RgbaUnormBlock, encodeBC1Alpha
+		}
+	case FormatBC3:
+		tex.Format, encode = rendering.GPUFormatBc3UnormBlock, encodeBC3Block
+	case FormatBC5:
+		tex.Format, encode = rendering.GPUFormatBc5UnormBlock, encodeBC5Block
+	case FormatBC7:
+		tex.Format, encode = rendering.GPUFormatBc7UnormBlock, encodeBC7Block
+	case FormatASTC4x4:
+		tex.Format, encode = rendering.GPUFormatAstc4x4UnormBlock, encodeASTCBlock
+	default:
+		return nil, fmt.Errorf("unknown texture compression format '%s'", format)
+	}
+	for i := range levels {
+		if encode == nil {
+			tex.Levels[i] = levels[i].Pix
+		} else {
+			tex.Levels[i] = encodeBlocks(levels[i], blockBytes, encode)
+		}
+	}
+	return rendering.EncodeKTX2(tex)
+}
@@ -0,0 +1,314 @@
+/******************************************************************************/
+/* texcompress_test.go                                                        */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package texcompress
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"math"
+	"testing"
+
+	"kaijuengine.com/rendering"
+)
+
+func testBlock() block {
+	// Texels along a gradient with a little noise, as most blocks are
+	from, to := [4]float32{30, 200, 90, 255}, [4]float32{220, 60, 140, 40}
+	var b block
+	for i := range b {
+		f := float32(i*7%16) / 15
+		for c := range 4 {
+			noise := float32(i*5%7) - 3
+			b[i][c] = byte(min(max(from[c]+(to[c]-from[c])*f+noise, 0), 255))
+		}
+	}
+	return b
+}
+
+func blockError(t *testing.T, want, got *block, channels int) float64 {
+	t.Helper()
+	e := 0.0
+	for i := range want {
+		for c := range channels {
+			d := float64(want[i][c]) - float64(got[i][c])
+			e += d * d
+		}
+	}
+	return math.Sqrt(e / float64(16*channels))
+}
+
+func readBits(data []byte, pos, bits int) uint32 {
+	v := uint32(0)
+	for i := range bits {
+		p := pos + i
+		v |= uint32(data[p>>3]>>(p&7)&1) << i
+	}
+	return v
+}
+
+func decodeBC1(data []byte, fourColor bool) (b block) {
+	c0 := binary.LittleEndian.Uint16(data)
+	c1 := binary.LittleEndian.Uint16(data[2:])
+	p0, p1 := from565(c0), from565(c1)
+	var palette [4][4]int32
+	palette[0], palette[1] = p0, p1
+	if c0 > c1 || fourColor {
+		for c := range 3 {
+			palette[2][c] = (2*p0[c] + p1[c]) / 3
+			palette[3][c] = (p0[c] + 2*p1[c]) / 3
+		}
+		palette[2][3], palette[3][3] = 255, 255
+	} else {
+		for c := range 3 {
+			palette[2][c] = (p0[c] + p1[c]) / 2
+		}
+		palette[2][3] = 255
+	}
+	indices := binary.LittleEndian.Uint32(data[4:])
+	for i := range b {
+		p := palette[indices>>(i*2)&3]
+		b[i] = [4]byte{byte(p[0]), byte(p[1]), byte(p[2]), byte(p[3])}
+	}
+	return b
+}
+
+func decodeBC4(data []byte, channel int, b *block) {
+	a0, a1 := int32(data[0]), int32(data[1])
+	var palette [8]int32
+	palette[0], palette[1] = a0, a1
+	for i := int32(2); i < 8; i++ {
+		palette[i] = ((8-i)*a0 + (i-1)*a1) / 7
+	}
+	for i := range b {
+		b[i][channel] = byte(palette[readBits(data[2:], i*3, 3)])
+	}
+}
+
+func decodeBC7Mode6(t *testing.T, data []byte) (b block) {
+	if data[0]&0x7F != 0x40 {
+		t.Fatalf("expected a mode 6 block, got 0x%02x", data[0])
+	}
+	pos := 7
+	var e [2][4]int32
+	for c := range 4 {
+		for s := range 2 {
+			e[s][c] = int32(readBits(data, pos, 7)) << 1
+			pos += 7
+		}
+	}
+	for s := range 2 {
+		p := int32(readBits(data, pos, 1))
+		pos++
+		for c := range 4 {
+			e[s][c] |= p
+		}
+	}
+	for i := range b {
+		bits := 4
+		if i == 0 {
+			bits = 3
+		}
+		w := bc7Weights4[readBits(data, pos, bits)]
+		pos += bits
+		for c := range 4 {
+			b[i][c] = byte(((64-w)*e[0][c] + w*e[1][c] + 32) >> 6)
+		}
+	}
+	return b
+}
+
+func decodeASTC(t *testing.T, data []byte) (b block) {
+	if mode := readBits(data, 0, 11); mode != astcBlockMode4x4Q4 {
+		t.Fatalf("unexpected block mode 0x%03x", mode)
+	}
+	if cem := readBits(data, 13, 4); cem != astcEndpointRGBA {
+		t.Fatalf("unexpected endpoint mode %d", cem)
+	}
+	var v [8]int32
+	for i := range v {
+		v[i] = int32(readBits(data, 17+i*8, 8))
+	}
+	e0 := [4]int32{v[0], v[2], v[4], v[6]}
+	e1 := [4]int32{v[1], v[3], v[5], v[7]}
+	if v[1]+v[3]+v[5] < v[0]+v[2]+v[4] {
+		t.Fatal("the endpoints would be blue contracted by the decoder")
+	}
+	for i := range b {
+		w := int32(0)
+		for bit := range 2 {
+			pos := 127 - (i*2 + bit)
+			w |= int32(data[pos>>3]>>(pos&7)&1) << bit
+		}
+		for c := range 4 {
+			a, z := e0[c]*257, e1[c]*257
+			b[i][c] = byte((((64-astcWeights2[w])*a + astcWeights2[w]*z + 32) >> 6) >> 8)
+		}
+	}
+	return b
+}
+
+func TestBC1Block(t *testing.T) {
+	src := testBlock()
+	out := make([]byte, bc1BlockBytes)
+	encodeBC1Opaque(&src, out)
+	if binary.LittleEndian.Uint16(out) <= binary.LittleEndian.Uint16(out[2:]) {
+		t.Fatal("expected an opaque block to use the 4 color mode")
+	}
+	got := decodeBC1(out, false)
+	if e := blockError(t, &src, &got, 3); e > 16 {
+		t.Errorf("BC1 error too high: %.2f", e)
+	}
+}
+
+func TestBC1CutoutBlock(t *testing.T) {
+	src := testBlock()
+	src[5][3], src[10][3] = 0, 20
+	out := make([]byte, bc1BlockBytes)
+	encodeBC1Alpha(&src, out)
+	indices := binary.LittleEndian.Uint32(out[4:])
+	for i := range src {
+		transparent := indices>>(i*2)&3 == 3
+		if transparent != (src[i][3] < 128) {
+			t.Errorf("texel %d transparent = %t, alpha is %d", i, transparent, src[i][3])
+		}
+	}
+}
+
+func TestBC3AndBC5Blocks(t *testing.T) {
+	src := testBlock()
+	out := make([]byte, 16)
+	encodeBC3Block(&src, out)
+	got := decodeBC1(out[8:], true)
+	decodeBC4(out, 3, &got)
+	if e := blockError(t, &src, &got, 4); e > 16 {
+		t.Errorf("BC3 error too high: %.2f", e)
+	}
+	encodeBC5Block(&src, out)
+	decodeBC4(out, 0, &got)
+	decodeBC4(out[8:], 1, &got)
+	if e := blockError(t, &src, &got, 2); e > 8 {
+		t.Errorf("BC5 error too high: %.2f", e)
+	}
+}
+
+func TestBC7Block(t *testing.T) {
+	src := testBlock()
+	out := make([]byte, bc7BlockBytes)
+	encodeBC7Block(&src, out)
+	got := decodeBC7Mode6(t, out)
+	if e := blockError(t, &src, &got, 4); e > 10 {
+		t.Errorf("BC7 error too high: %.2f", e)
+	}
+}
+
+func TestASTCBlock(t *testing.T) {
+	src := testBlock()
+	out := make([]byte, astcBlockBytes)
+	encodeASTCBlock(&src, out)
+	got := decodeASTC(t, out)
+	if e := blockError(t, &src, &got, 4); e > 20 {
+		t.Errorf("ASTC error too high: %.2f", e)
+	}
+}
+
+func TestGenerateMipmapsColorIsFilteredInLinearSpace(t *testing.T) {
+	// A black and white checker averages to a linear 0.5, which is ~188 in sRGB
+	w, h := 8, 4
+	pix := make([]byte, w*h*4)
+	for i := range w * h {
+		v := byte(0)
+		if (i%w+i/w)%2 == 0 {
+			v = 255
+		}
+		pix[i*4], pix[i*4+1], pix[i*4+2], pix[i*4+3] = v, v, v, 255
+	}
+	levels := GenerateMipmaps(pix, w, h, UsageColor)
+	if len(levels) != MipCount(w, h) || len(levels) != 4 {
+		t.Fatalf("expected 4 levels, got %d", len(levels))
+	}
+	wantSizes := [][2]int{{8, 4}, {4, 2}, {2, 1}, {1, 1}}
+	for i, l := range levels {
+		if l.Width != wantSizes[i][0] || l.Height != wantSizes[i][1] || len(l.Pix) != l.Width*l.Height*4 {
+			t.Errorf("level %d is %dx%d with %d bytes", i, l.Width, l.Height, len(l.Pix))
+		}
+	}
+	last := levels[len(levels)-1].Pix
+	if last[0] < 180 || last[0] > 195 {
+		t.Errorf("expected the last level to be ~188, got %d", last[0])
+	}
+	linear := GenerateMipmaps(pix, w, h, UsageLinear)
+	if v := linear[len(linear)-1].Pix[0]; v < 120 || v > 135 {
+		t.Errorf("expected linear data to average to ~128, got %d", v)
+	}
+}
+
+func TestGenerateMipmapsRenormalizesNormals(t *testing.T) {
+	// Normals tilted in opposite directions average to a short vector
+	pix := []byte{
+		218, 128, 218, 255, 38, 128, 218, 255,
+		218, 128, 218, 255, 38, 128, 218, 255,
+	}
+	levels := GenerateMipmaps(pix, 2, 2, UsageNormal)
+	n := levels[1].Pix
+	x, y, z := float64(n[0])/127.5-1, float64(n[1])/127.5-1, float64(n[2])/127.5-1
+	if l := math.Sqrt(x*x + y*y + z*z); math.Abs(l-1) > 0.02 {
+		t.Errorf("expected a unit length normal, got length %.3f (%v)", l, n[:3])
+	}
+}
+
+func TestCompressWritesKTX2(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 6))
+	for y := range 6 {
+		for x := range 10 {
+			img.Set(x, y, color.NRGBA{byte(x * 25), byte(y * 40), 128, 255})
+		}
+	}
+	tests := []struct {
+		settings Settings
+		profile  Profile
+		format   rendering.GPUFormat
+		levels   int
+	}{
+		{Settings{}, ProfileDesktop, rendering.GPUFormatBc1RgbUnormBlock, 4},
+		{Settings{Usage: UsageNormal}, ProfileDesktop, rendering.GPUFormatBc7UnormBlock, 4},
+		{Settings{Format: FormatBC5}, ProfileDesktop, rendering.GPUFormatBc5UnormBlock, 4},
+		{Settings{NoMipmaps: true}, ProfileAndroid, rendering.GPUFormatAstc4x4UnormBlock, 1},
+		{Settings{Format: FormatNone}, ProfileAndroid, rendering.GPUFormatR8g8b8a8Unorm, 4},
+	}
+	for _, test := range tests {
+		data, err := Compress(img, test.settings, test.profile)
+		if err != nil {
+			t.Fatalf("Compress(%+v) returned error: %v", test.settings, err)
+		}
+		tex, err := rendering.DecodeKTX2(data)
+		if err != nil {
+			t.Fatalf("failed to decode the KTX2 for %+v: %v", test.settings, err)
+		}
+		if tex.Format != test.format || tex.Width != 10 || tex.Height != 6 || len(tex.Levels) != test.levels {
+			t.Errorf("unexpected texture for %+v: format %d, %dx%d, %d levels",
+				test.settings, tex.Format, tex.Width, tex.Height, len(tex.Levels))
+		}
+	}
+	img.Set(0, 0, color.NRGBA{255, 0, 0, 100})
+	data, _ := Compress(img, Settings{}, ProfileDesktop)
+	if tex, _ := rendering.DecodeKTX2(data); tex.Format != rendering.GPUFormatBc3UnormBlock {
+		t.Errorf("expected color with alpha to use BC3, got format %d", tex.Format)
+	}
+	if _, err := Compress(img, Settings{Format: "pvrtc"}, ProfileDesktop); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestProfileByName(t *testing.T) {
+	if p, ok := ProfileByName("android"); !ok || p.Color != FormatASTC4x4 {
+		t.Errorf("unexpected android profile %+v", p)
+	}
+	if _, ok := ProfileByName("console"); ok {
+		t.Error("expected an unknown profile to not be found")
+	}
+}
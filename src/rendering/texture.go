@@ -66,6 +66,11 @@ const (
 	TextureInputTypeRgba8
 	TextureInputTypeRgb8
 	TextureInputTypeLuminance
+	TextureInputTypeCompressedRgbBc1
+	TextureInputTypeCompressedRgbaBc1
+	TextureInputTypeCompressedRgbaBc3
+	TextureInputTypeCompressedRgBc5
+	TextureInputTypeCompressedRgbaBc7
 )
 
 const (
@@ -91,6 +96,7 @@ const (
 	TextureFileFormatPng
 	TextureFileFormatJpeg
 	TextureFileFormatRaw
+	TextureFileFormatKtx2
 )
 
 const (
@@ -123,6 +129,20 @@ type TextureData struct {
 	Height         int
 	InputType      TextureFileFormat
 	Dimensions     TextureDimensions
+	// Mips holds the mip levels after the first (which is in Mem) when they
+	// were created ahead of time. When set, the mips are uploaded as they are
+	// rather than being generated on the GPU.
+	Mips [][]byte
+}
+
+// IsCompressed returns true if the memory is block compressed rather than
+// being individual pixels
+func (d *TextureData) IsCompressed() bool {
+	switch d.InternalFormat {
+	case TextureInputTypeRgba8, TextureInputTypeRgb8, TextureInputTypeLuminance:
+		return false
+	}
+	return true
 }
 
 type transparencyReadState int
@@ -157,7 +177,7 @@ func TextureKeys(textures []*Texture) []string {
 	return keys
 }
 
-// ReadRawTextureData reads raw texture data from a byte slice based on the specified input type (ASTC, PNG, or RAW).
+// ReadRawTextureData reads raw texture data from a byte slice based on the specified input type (ASTC, KTX2, PNG, or RAW).
 // It returns a TextureData struct containing the decoded pixel data, dimensions, and format information.
 func ReadRawTextureData(mem []byte, inputType TextureFileFormat) TextureData {
 	defer tracing.NewRegion("rendering.ReadRawTextureData").End()
@@ -202,6 +222,9 @@ func ReadRawTextureData(mem []byte, inputType TextureFileFormat) TextureData {
 	case TextureFileFormatJpeg:
 		return readImageTextureData(mem, inputType, jpeg.Decode)
 
+	case TextureFileFormatKtx2:
+		return readKTX2TextureData(mem)
+
 	case TextureFileFormatRaw:
 		res.Mem = mem
 		res.Width = 0
@@ -238,6 +261,11 @@ func readImageTextureData(mem []byte, inputType TextureFileFormat, decode func(i
 }
 
 func textureFileFormat(key string, mem []byte) TextureFileFormat {
+	// Packaged textures keep the key of the image they were imported from, so
+	// the contents are checked for KTX2 before the name
+	if IsKTX2(mem) {
+		return TextureFileFormatKtx2
+	}
 	lowerKey := strings.ToLower(key)
 	if strings.HasSuffix(lowerKey, ".astc") {
 		return TextureFileFormatAstc
@@ -318,6 +346,10 @@ func (t *Texture) ReadPendingDataForTransparency() bool {
 		return false
 	}
 	t.hasTransparency = transparencyReadStateRead
+	// Blocks of packaged textures can't be scanned texel by texel
+	if t.pendingData.InputType == TextureFileFormatKtx2 && t.pendingData.IsCompressed() {
+		return false
+	}
 	for i := 0; i < len(t.pendingData.Mem); i += 4 {
 		if t.pendingData.Mem[i] != 255 {
 			t.hasTransparency = transparencyReadStateFound
@@ -366,7 +398,11 @@ func (t *Texture) pendingDataSize() uintptr {
 	if t.pendingData == nil {
 		return 0
 	}
-	return uintptr(len(t.pendingData.Mem))
+	size := uintptr(len(t.pendingData.Mem))
+	for i := range t.pendingData.Mips {
+		size += uintptr(len(t.pendingData.Mips[i]))
+	}
+	return size
 }
 
 func NewTextureFromImage(key string, data []byte, filter TextureFilter) (*Texture, error) {
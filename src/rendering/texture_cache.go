@@ -257,6 +257,9 @@ func (t *TextureCache) ForceRemoveTexture(key string, filter TextureFilter) {
 	if texture.RenderId.IsValid() {
 		t.pendingFree = append(t.pendingFree, texture.RenderId)
 	}
+	if t.device.IsNull() {
+		t.device.null.destroyTexture(texture)
+	}
 	t.removePendingUploadLocked(texture)
 	delete(t.textures[filter], key)
 }
@@ -268,6 +271,12 @@ func (t *TextureCache) ProcessPending() {
 	t.pendingFree = klib.WipeSlice(t.pendingFree)
 	pendingTextures := t.takePendingUploadsLocked()
 	t.mutex.Unlock()
+	if t.device.IsNull() {
+		for _, pending := range pendingTextures {
+			t.device.null.createTexture(pending.texture)
+		}
+		return
+	}
 	for i := range pendingFree {
 		t.device.LogicalDevice.FreeTexture(&pendingFree[i])
 	}
@@ -293,9 +302,14 @@ func (t *TextureCache) Destroy() {
 	defer tracing.NewRegion("TextureCache.Destroy").End()
 	t.pendingFree = klib.WipeSlice(t.pendingFree)
 	t.pendingTextures = klib.WipeSlice(t.pendingTextures)
+	if t.device.IsNull() {
+		t.device.null.destroyTextures()
+	}
 	for i := range t.textures {
-		for _, tex := range t.textures[i] {
-			t.device.LogicalDevice.FreeTexture(&tex.RenderId)
+		if !t.device.IsNull() {
+			for _, tex := range t.textures[i] {
+				t.device.LogicalDevice.FreeTexture(&tex.RenderId)
+			}
 		}
 		t.textures[i] = make(map[string]*Texture)
 	}
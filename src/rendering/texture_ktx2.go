@@ -0,0 +1,275 @@
+/******************************************************************************/
+/* texture_ktx2.go                                                            */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"kaijuengine.com/platform/profiler/tracing"
+)
+
+/*
+	KTX2 notes:
+	The container is described here: https://registry.khronos.org/KTX/specs/2.0/ktxspec.v2.html
+
+	Only the parts needed for 2D textures are supported, there are no array
+	layers, cube faces, or supercompression. The levels are listed largest first
+	in the level index, but are stored in the file smallest first.
+*/
+
+var ktx2Identifier = [12]byte{0xAB, 'K', 'T', 'X', ' ', '2', '0', 0xBB, '\r', '\n', 0x1A, '\n'}
+
+const (
+	ktx2HeaderSize     = 80
+	ktx2LevelIndexSize = 24
+	ktx2Writer         = "Kaiju Engine"
+)
+
+// Values from the Khronos data format specification for the descriptor block
+const (
+	ktx2ModelRGBSDA    = 1
+	ktx2ModelBC1A      = 128
+	ktx2ModelBC3       = 130
+	ktx2ModelBC5       = 132
+	ktx2ModelBC7       = 134
+	ktx2ModelASTC      = 162
+	ktx2PrimariesBT709 = 1
+	ktx2TransferLinear = 1
+	ktx2TransferSRGB   = 2
+	ktx2ChannelLinear  = 0x10
+)
+
+type ktx2Sample struct {
+	bitOffset, bitLength int
+	channel              byte
+	upper                uint32
+}
+
+type ktx2FormatInfo struct {
+	vkFormat   uint32
+	blockSize  int
+	blockBytes int
+	srgb       bool
+	model      byte
+	samples    []ktx2Sample
+	inputType  TextureInputType
+}
+
+func ktx2CompressedSamples(channels ...byte) []ktx2Sample {
+	samples := make([]ktx2Sample, len(channels))
+	bits := 128 / len(channels)
+	for i := range channels {
+		samples[i] = ktx2Sample{i * bits, bits, channels[i], 0xFFFFFFFF}
+	}
+	return samples
+}
+
+var ktx2RGBA8Samples = []ktx2Sample{
+	{0, 8, 0, 255}, {8, 8, 1, 255}, {16, 8, 2, 255}, {24, 8, 15, 255},
+}
+
+var ktx2Formats = map[GPUFormat]ktx2FormatInfo{
+	GPUFormatR8g8b8a8Unorm:     {37, 1, 4, false, ktx2ModelRGBSDA, ktx2RGBA8Samples, TextureInputTypeRgba8},
+	GPUFormatR8g8b8a8Srgb:      {43, 1, 4, true, ktx2ModelRGBSDA, ktx2RGBA8Samples, TextureInputTypeRgba8},
+	GPUFormatBc1RgbUnormBlock:  {131, 4, 8, false, ktx2ModelBC1A, []ktx2Sample{{0, 64, 0, 0xFFFFFFFF}}, TextureInputTypeCompressedRgbBc1},
+	GPUFormatBc1RgbSrgbBlock:   {132, 4, 8, true, ktx2ModelBC1A, []ktx2Sample{{0, 64, 0, 0xFFFFFFFF}}, TextureInputTypeCompressedRgbBc1},
+	GPUFormatBc1RgbaUnormBlock: {133, 4, 8, false, ktx2ModelBC1A, []ktx2Sample{{0, 64, 1, 0xFFFFFFFF}}, TextureInputTypeCompressedRgbaBc1},
+	GPUFormatBc1RgbaSrgbBlock:  {134, 4, 8, true, ktx2ModelBC1A, []ktx2Sample{{0, 64, 1, 0xFFFFFFFF}}, TextureInputTypeCompressedRgbaBc1},
+	GPUFormatBc3UnormBlock:     {137, 4, 16, false, ktx2ModelBC3, ktx2CompressedSamples(15, 0), TextureInputTypeCompressedRgbaBc3},
+	GPUFormatBc3SrgbBlock:      {138, 4, 16, true, ktx2ModelBC3, ktx2CompressedSamples(15, 0), TextureInputTypeCompressedRgbaBc3},
+	GPUFormatBc5UnormBlock:     {141, 4, 16, false, ktx2ModelBC5, ktx2CompressedSamples(0, 1), TextureInputTypeCompressedRgBc5},
+	GPUFormatBc7UnormBlock:     {145, 4, 16, false, ktx2ModelBC7, ktx2CompressedSamples(0), TextureInputTypeCompressedRgbaBc7},
+	GPUFormatBc7SrgbBlock:      {146, 4, 16, true, ktx2ModelBC7, ktx2CompressedSamples(0), TextureInputTypeCompressedRgbaBc7},
+	GPUFormatAstc4x4UnormBlock: {157, 4, 16, false, ktx2ModelASTC, ktx2CompressedSamples(0), TextureInputTypeCompressedRgbaAstc4x4},
+	GPUFormatAstc4x4SrgbBlock:  {158, 4, 16, true, ktx2ModelASTC, ktx2CompressedSamples(0), TextureInputTypeCompressedRgbaAstc4x4},
+}
+
+// KTX2Texture is a 2D texture and all of its mip levels as they are stored
+// within a KTX2 container
+type KTX2Texture struct {
+	Format GPUFormat
+	Width  int
+	Height int
+	// Levels holds the data for each of the mip levels, starting with the full
+	// size image. Block compressed levels are padded out to whole blocks.
+	Levels [][]byte
+}
+
+// IsKTX2 returns true if the data starts with the KTX2 file identifier
+func IsKTX2(data []byte) bool {
+	return len(data) >= len(ktx2Identifier) && bytes.Equal(data[:len(ktx2Identifier)], ktx2Identifier[:])
+}
+
+// KTX2LevelSize returns the number of bytes for a single mip level of the
+// given size in the format, or 0 if the format can't be stored in KTX2
+func KTX2LevelSize(format GPUFormat, width, height int) int {
+	info, ok := ktx2Formats[format]
+	if !ok {
+		return 0
+	}
+	bw := (max(width, 1) + info.blockSize - 1) / info.blockSize
+	bh := (max(height, 1) + info.blockSize - 1) / info.blockSize
+	return bw * bh * info.blockBytes
+}
+
+// EncodeKTX2 writes the texture into a KTX2 container
+func EncodeKTX2(tex KTX2Texture) ([]byte, error) {
+	defer tracing.NewRegion("rendering.EncodeKTX2").End()
+	info, ok := ktx2Formats[tex.Format]
+	if !ok {
+		return nil, fmt.Errorf("the texture format %d can't be written to KTX2", tex.Format)
+	}
+	if tex.Width <= 0 || tex.Height <= 0 || len(tex.Levels) == 0 {
+		return nil, errors.New("a KTX2 texture needs a size and at least one level")
+	}
+	for i := range tex.Levels {
+		want := KTX2LevelSize(tex.Format, tex.Width>>i, tex.Height>>i)
+		if len(tex.Levels[i]) != want {
+			return nil, fmt.Errorf("mip level %d is %d bytes, expected %d", i, len(tex.Levels[i]), want)
+		}
+	}
+	dfd := ktx2DataFormatDescriptor(&info)
+	kvd := ktx2KeyValueData()
+	levelCount := len(tex.Levels)
+	dfdOffset := ktx2HeaderSize + ktx2LevelIndexSize*levelCount
+	kvdOffset := dfdOffset + len(dfd)
+	out := make([]byte, kvdOffset+len(kvd))
+	copy(out, ktx2Identifier[:])
+	le := binary.LittleEndian
+	header := []uint32{info.vkFormat, 1, uint32(tex.Width), uint32(tex.Height),
+		0, 0, 1, uint32(levelCount), 0,
+		uint32(dfdOffset), uint32(len(dfd)), uint32(kvdOffset), uint32(len(kvd))}
+	for i, v := range header {
+		le.PutUint32(out[12+i*4:], v)
+	}
+	copy(out[dfdOffset:], dfd)
+	copy(out[kvdOffset:], kvd)
+	// Levels must start on a multiple of the block size and of 4, all of the
+	// supported block sizes are already a multiple of 4
+	align := info.blockBytes
+	for i := levelCount - 1; i >= 0; i-- {
+		for len(out)%align != 0 {
+			out = append(out, 0)
+		}
+		entry := out[ktx2HeaderSize+ktx2LevelIndexSize*i:]
+		le.PutUint64(entry[0:], uint64(len(out)))
+		le.PutUint64(entry[8:], uint64(len(tex.Levels[i])))
+		le.PutUint64(entry[16:], uint64(len(tex.Levels[i])))
+		out = append(out, tex.Levels[i]...)
+	}
+	return out, nil
+}
+
+// DecodeKTX2 reads a 2D texture from a KTX2 container. The level data is not
+// copied and refers to the input data.
+func DecodeKTX2(data []byte) (KTX2Texture, error) {
+	defer tracing.NewRegion("rendering.DecodeKTX2").End()
+	var tex KTX2Texture
+	if !IsKTX2(data) || len(data) < ktx2HeaderSize {
+		return tex, errors.New("the data is not a KTX2 file")
+	}
+	le := binary.LittleEndian
+	vkFormat := le.Uint32(data[12:])
+	width, height := le.Uint32(data[20:]), le.Uint32(data[24:])
+	depth, layers, faces := le.Uint32(data[28:]), le.Uint32(data[32:]), le.Uint32(data[36:])
+	levelCount := max(le.Uint32(data[40:]), 1)
+	if scheme := le.Uint32(data[44:]); scheme != 0 {
+		return tex, fmt.Errorf("KTX2 supercompression scheme %d is not supported", scheme)
+	}
+	if depth > 1 || layers > 1 || faces != 1 || height == 0 {
+		return tex, errors.New("only 2D KTX2 textures are supported")
+	}
+	found := false
+	for format, info := range ktx2Formats {
+		if info.vkFormat == vkFormat {
+			tex.Format, found = format, true
+			break
+		}
+	}
+	if !found {
+		return tex, fmt.Errorf("the KTX2 format %d is not supported", vkFormat)
+	}
+	if uint64(len(data)) < uint64(ktx2HeaderSize)+uint64(ktx2LevelIndexSize)*uint64(levelCount) {
+		return tex, errors.New("the KTX2 level index is truncated")
+	}
+	tex.Width, tex.Height = int(width), int(height)
+	tex.Levels = make([][]byte, levelCount)
+	for i := range tex.Levels {
+		entry := data[ktx2HeaderSize+ktx2LevelIndexSize*i:]
+		offset, length := le.Uint64(entry[0:]), le.Uint64(entry[8:])
+		if offset > uint64(len(data)) || length > uint64(len(data))-offset {
+			return tex, fmt.Errorf("KTX2 mip level %d is outside of the file", i)
+		}
+		if int(length) < KTX2LevelSize(tex.Format, tex.Width>>i, tex.Height>>i) {
+			return tex, fmt.Errorf("KTX2 mip level %d is too small", i)
+		}
+		tex.Levels[i] = data[offset : offset+length]
+	}
+	return tex, nil
+}
+
+func ktx2DataFormatDescriptor(info *ktx2FormatInfo) []byte {
+	blockSize := 24 + 16*len(info.samples)
+	dfd := make([]byte, 4+blockSize)
+	le := binary.LittleEndian
+	le.PutUint32(dfd[0:], uint32(len(dfd)))
+	// Vendor and descriptor type are both 0 for the basic descriptor block
+	le.PutUint32(dfd[4:], 0)
+	le.PutUint32(dfd[8:], 2|uint32(blockSize)<<16)
+	transfer := byte(ktx2TransferLinear)
+	if info.srgb {
+		transfer = ktx2TransferSRGB
+	}
+	dfd[12], dfd[13], dfd[14], dfd[15] = info.model, ktx2PrimariesBT709, transfer, 0
+	dim := byte(info.blockSize - 1)
+	dfd[16], dfd[17] = dim, dim
+	dfd[20] = byte(info.blockBytes)
+	for i, s := range info.samples {
+		sample := dfd[28+16*i:]
+		channel := s.channel
+		if info.srgb && info.model == ktx2ModelRGBSDA && channel == 15 {
+			channel |= ktx2ChannelLinear
+		}
+		le.PutUint32(sample[0:], uint32(s.bitOffset)|uint32(s.bitLength-1)<<16|uint32(channel)<<24)
+		le.PutUint32(sample[8:], 0)
+		le.PutUint32(sample[12:], s.upper)
+	}
+	return dfd
+}
+
+func ktx2KeyValueData() []byte {
+	kv := append([]byte("KTXwriter\x00"), ktx2Writer+"\x00"...)
+	out := binary.LittleEndian.AppendUint32(nil, uint32(len(kv)))
+	out = append(out, kv...)
+	for len(out)%4 != 0 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+// readKTX2TextureData fills out the texture data from a KTX2 file, the mip
+// levels are uploaded as they are rather than being generated on the GPU
+func readKTX2TextureData(mem []byte) TextureData {
+	res := TextureData{InputType: TextureFileFormatKtx2, Type: TextureMemTypeUnsignedByte}
+	tex, err := DecodeKTX2(mem)
+	if err != nil {
+		return res
+	}
+	info := ktx2Formats[tex.Format]
+	res.InternalFormat = info.inputType
+	res.Format = TextureColorFormatRgbaUnorm
+	if info.srgb {
+		res.Format = TextureColorFormatRgbaSrgb
+	}
+	res.Width, res.Height = tex.Width, tex.Height
+	res.Mem = tex.Levels[0]
+	res.Mips = tex.Levels[1:]
+	return res
+}
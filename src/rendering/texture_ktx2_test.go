@@ -0,0 +1,102 @@
+/******************************************************************************/
+/* texture_ktx2_test.go                                                       */
+/******************************************************************************/
+/* MIT License, Copyright (c) 2015-present Brent Farris, (John 4:13-14)       */
+/******************************************************************************/
+
+package rendering
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func testKTX2Levels(format GPUFormat, width, height, count int) [][]byte {
+	levels := make([][]byte, count)
+	for i := range levels {
+		levels[i] = bytes.Repeat([]byte{byte(i + 1)}, KTX2LevelSize(format, width>>i, height>>i))
+	}
+	return levels
+}
+
+func TestKTX2RoundTrip(t *testing.T) {
+	tex := KTX2Texture{
+		Format: GPUFormatBc7UnormBlock,
+		Width:  10,
+		Height: 6,
+		Levels: testKTX2Levels(GPUFormatBc7UnormBlock, 10, 6, 4),
+	}
+	data, err := EncodeKTX2(tex)
+	if err != nil {
+		t.Fatalf("EncodeKTX2 returned error: %v", err)
+	}
+	if !IsKTX2(data) {
+		t.Fatal("expected the encoded data to start with the KTX2 identifier")
+	}
+	if vk := binary.LittleEndian.Uint32(data[12:]); vk != 145 {
+		t.Errorf("expected the VkFormat to be BC7 UNORM (145), got %d", vk)
+	}
+	got, err := DecodeKTX2(data)
+	if err != nil {
+		t.Fatalf("DecodeKTX2 returned error: %v", err)
+	}
+	if got.Format != tex.Format || got.Width != tex.Width || got.Height != tex.Height {
+		t.Fatalf("decoded %+v, want %+v", got, tex)
+	}
+	if len(got.Levels) != len(tex.Levels) {
+		t.Fatalf("decoded %d levels, want %d", len(got.Levels), len(tex.Levels))
+	}
+	for i := range tex.Levels {
+		if !bytes.Equal(got.Levels[i], tex.Levels[i]) {
+			t.Errorf("level %d does not match", i)
+		}
+		entry := data[ktx2HeaderSize+ktx2LevelIndexSize*i:]
+		if offset := binary.LittleEndian.Uint64(entry); offset%16 != 0 {
+			t.Errorf("level %d starts at %d which is not aligned to the block", i, offset)
+		}
+	}
+}
+
+func TestKTX2Errors(t *testing.T) {
+	levels := testKTX2Levels(GPUFormatBc1RgbUnormBlock, 8, 8, 2)
+	levels[1] = levels[1][:4]
+	if _, err := EncodeKTX2(KTX2Texture{GPUFormatBc1RgbUnormBlock, 8, 8, levels}); err == nil {
+		t.Error("expected an error for a level with the wrong size")
+	}
+	if _, err := EncodeKTX2(KTX2Texture{GPUFormatR16Sfloat, 8, 8, [][]byte{{}}}); err == nil {
+		t.Error("expected an error for a format that can't be written")
+	}
+	data, _ := EncodeKTX2(KTX2Texture{GPUFormatBc1RgbUnormBlock, 8, 8,
+		testKTX2Levels(GPUFormatBc1RgbUnormBlock, 8, 8, 2)})
+	if _, err := DecodeKTX2(data[:len(data)-4]); err == nil {
+		t.Error("expected an error for a truncated level")
+	}
+	if _, err := DecodeKTX2([]byte("not a texture")); err == nil {
+		t.Error("expected an error for data that isn't KTX2")
+	}
+}
+
+func TestReadRawTextureDataKTX2(t *testing.T) {
+	data, err := EncodeKTX2(KTX2Texture{
+		Format: GPUFormatBc3SrgbBlock,
+		Width:  16,
+		Height: 16,
+		Levels: testKTX2Levels(GPUFormatBc3SrgbBlock, 16, 16, 5),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The key is the name of the source image, the contents decide the format
+	res := ReadRawTextureData(data, textureFileFormat("brick.png", data))
+	if res.InputType != TextureFileFormatKtx2 || res.InternalFormat != TextureInputTypeCompressedRgbaBc3 {
+		t.Fatalf("unexpected texture data %+v", res)
+	}
+	if !res.IsCompressed() || res.Format != TextureColorFormatRgbaSrgb {
+		t.Error("expected sRGB compressed texture data")
+	}
+	if res.Width != 16 || res.Height != 16 || len(res.Mem) != 256 || len(res.Mips) != 4 {
+		t.Errorf("unexpected size %dx%d with %d bytes and %d mips",
+			res.Width, res.Height, len(res.Mem), len(res.Mips))
+	}
+}
@@ -220,13 +220,30 @@ func (r *RenderPass) setupSubpass(c *RenderPassSubpassDataCompiled, device *GPUD
 	return nil
 }
 
-func (r *RenderPass) endSubpasses() {
+func (r *RenderPass) ownsTexture(texture *Texture) bool {
+	for i := range r.textures {
+		if &r.textures[i] == texture {
+			return true
+		}
+	}
+	return false
+}
+
+// endSubpasses ends the render pass, finish (when not nil) records into the
+// primary command after the render pass has ended
+func (r *RenderPass) endSubpasses(finish func(cmd *CommandRecorder)) {
 	cmd := r.activePrimaryCommand()
 	vk.CmdEndRenderPass(cmd.buffer)
+	if finish != nil {
+		finish(cmd)
+	}
 	cmd.End()
 }
 
-func (r *RenderPass) beginNextSubpass(currentFrame int, extent vk.Extent2D, clearColors []vk.ClearValue) {
+// beginNextSubpass starts the render pass when it is on the first subpass,
+// prepare (when not nil) records into the primary command before the render
+// pass begins. It is ignored for the other subpasses.
+func (r *RenderPass) beginNextSubpass(currentFrame int, extent vk.Extent2D, clearColors []vk.ClearValue, prepare func(cmd *CommandRecorder)) {
 	r.frame = currentFrame
 	viewport := vk.Viewport{
 		X:        0,
@@ -256,6 +273,9 @@ func (r *RenderPass) beginNextSubpass(currentFrame int, extent vk.Extent2D, clea
 		}
 		cmd := r.activePrimaryCommand()
 		cmd.Begin()
+		if prepare != nil {
+			prepare(cmd)
+		}
 		vk.CmdBeginRenderPass(cmd.buffer, &renderPassInfo, vulkan_const.SubpassContentsSecondaryCommandBuffers)
 		r.activeSecondaryCommand().Begin(viewport, scissor)
 	} else {